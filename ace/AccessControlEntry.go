@@ -3,13 +3,16 @@ package ace
 import (
 	"encoding/hex"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/ace/compound"
 	"github.com/TheManticoreProject/winacl/ace/header"
 	"github.com/TheManticoreProject/winacl/ace/mask"
 	"github.com/TheManticoreProject/winacl/identity"
 	"github.com/TheManticoreProject/winacl/object"
+	"github.com/TheManticoreProject/winacl/parsing"
 )
 
 // AccessControlEntry represents an entry in an access control list (ACL).
@@ -20,12 +23,24 @@ type AccessControlEntry struct {
 	Identity                identity.Identity
 	AccessControlObjectType object.AccessControlObjectType
 
+	// Compound holds the compound type and the server identity of ACCESS_ALLOWED_COMPOUND
+	// ACEs, whose Identity is the client. It is unused for the other ACE types.
+	Compound compound.AccessControlEntryCompound
+
+	// Opaque is set when the body of a compound or alarm ACE does not follow the expected
+	// layout, when the ACE has a custom type registered with RegisterAccessControlEntryType,
+	// or when a lenient parser could not parse the body, see UnmarshalWithContext.
+	// The whole body is then kept in ApplicationData and re-emitted verbatim.
+	Opaque bool
+
 	// ApplicationData holds the optional, variable-length trailing bytes of an
 	// ACE that follow the fixed fields. For callback ACE types
 	// (ACCESS_ALLOWED_CALLBACK, ACCESS_DENIED_CALLBACK and their object/audit
-	// variants) this is the conditional expression; for
+	// variants) and SYSTEM_ACCESS_FILTER ACEs this is the conditional
+	// expression, see Condition(); for
 	// SYSTEM_RESOURCE_ATTRIBUTE and SYSTEM_SCOPED_POLICY_ID ACEs it is the
-	// attribute or policy data. The length is derived from the ACE Header.Size.
+	// attribute or policy data; for Opaque compound and alarm ACEs it is the
+	// whole body. The length is derived from the ACE Header.Size.
 	// These bytes are preserved verbatim so that Marshal(Unmarshal(x)) == x.
 	ApplicationData []byte
 
@@ -41,20 +56,21 @@ type AccessControlEntry struct {
 //   - rawBytes ([]byte): The raw byte slice to be parsed.
 func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 	ace.RawBytesSize = 0
+	ace.Opaque = false
 
 	// Parse Header
 	rawBytesSize, err := ace.Header.Unmarshal(marshalledData)
 	if err != nil {
-		return 0, fmt.Errorf("failed to unmarshal Header: %w", err)
+		return 0, parsing.WrapParseError(err, 0, "Header", "failed to unmarshal Header")
 	}
 	ace.RawBytesSize += uint32(rawBytesSize)
 
 	if ace.Header.Size < 4 {
-		return 0, fmt.Errorf("failed to unmarshal ACE: ace.Header.Size (%d) is less than the minimum ACE header size (4)", ace.Header.Size)
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_OUT_OF_RANGE, 2, "Header.Size", "failed to unmarshal ACE: ace.Header.Size (%d) is less than the minimum ACE header size (4)", ace.Header.Size)
 	}
 
 	if int(ace.Header.Size) > len(marshalledData) {
-		return 0, fmt.Errorf("failed to unmarshal ACE: ace.Header.Size (%d) is greater than the maximum length of marshalledData (%d)", ace.Header.Size, len(marshalledData))
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 2, "Header.Size", "failed to unmarshal ACE: ace.Header.Size (%d) is greater than the maximum length of marshalledData (%d)", ace.Header.Size, len(marshalledData))
 	}
 
 	// Update rawBytes to only contain the ACE data
@@ -69,7 +85,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Mask (4 bytes): An ACCESS_MASK that specifies the user rights allowed by this ACE.
 		rawBytesSize, err := ace.Mask.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Mask: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Mask", "failed to unmarshal Mask")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
@@ -77,7 +93,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Sid (variable): The SID of a trustee. The length of the SID MUST be a multiple of 4.
 		rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Identity: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Identity", "failed to unmarshal Identity")
 		}
 		ace.RawBytesSize += uint32(rawBytesSize)
 
@@ -88,7 +104,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Mask (4 bytes): An ACCESS_MASK that specifies the user rights allowed by this ACE.
 		rawBytesSize, err = ace.Mask.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Mask: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Mask", "failed to unmarshal Mask")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
@@ -96,7 +112,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Sid (variable): The SID of a trustee. The length of the SID MUST be a multiple of 4.
 		rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Identity: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Identity", "failed to unmarshal Identity")
 		}
 		ace.RawBytesSize += uint32(rawBytesSize)
 
@@ -107,7 +123,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Mask (4 bytes): An ACCESS_MASK that specifies the user rights allowed by this ACE.
 		rawBytesSize, err = ace.Mask.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Mask: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Mask", "failed to unmarshal Mask")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
@@ -115,23 +131,28 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Sid (variable): The SID of a trustee. The length of the SID MUST be a multiple of 4.
 		rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Identity: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Identity", "failed to unmarshal Identity")
 		}
 		ace.RawBytesSize += uint32(rawBytesSize)
 
 	case acetype.ACE_TYPE_SYSTEM_ALARM:
 		// Parsing ACE of type SYSTEM_ALARM_ACE_TYPE
-		// Source: ?
+		// Source: https://learn.microsoft.com/en-us/windows/win32/api/winnt/ns-winnt-system_alarm_ace
 
-		// Reserved for future use.
+		// Reserved for future use in MS-DTYP, the layout is the one of SYSTEM_AUDIT_ACE:
+		// Mask (4 bytes) and Sid (variable). Malformed bodies are kept opaque.
 		// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/628ebb1d-c509-4ea0-a10f-77ef97ca4586
+		ace.RawBytesSize += uint32(ace.unmarshalLegacyBody(marshalledData))
 
 	case acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND:
 		// Parsing ACE of type ACCESS_ALLOWED_COMPOUND_ACE_TYPE
-		// Source: ?
+		// Source: COMPOUND_ACCESS_ALLOWED_ACE in winnt.h
 
-		// Reserved for future use.
+		// Reserved for future use in MS-DTYP, the layout is Mask (4 bytes), CompoundAceType
+		// (2 bytes), Reserved (2 bytes), the server Sid and the client Sid. Malformed bodies
+		// are kept opaque.
 		// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/628ebb1d-c509-4ea0-a10f-77ef97ca4586
+		ace.RawBytesSize += uint32(ace.unmarshalLegacyBody(marshalledData))
 
 	case acetype.ACE_TYPE_ACCESS_ALLOWED_OBJECT:
 		// Parsing ACE of type ACCESS_ALLOWED_OBJECT_ACE_TYPE
@@ -140,14 +161,14 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Mask (4 bytes): An ACCESS_MASK that specifies the user rights allowed by this ACE.
 		rawBytesSize, err = ace.Mask.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Mask: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Mask", "failed to unmarshal Mask")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
 
 		rawBytesSize, err = ace.AccessControlObjectType.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal AccessControlObjectType: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "AccessControlObjectType", "failed to unmarshal AccessControlObjectType")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
@@ -170,7 +191,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Sid (variable): The SID of a trustee. The length of the SID MUST be a multiple of 4.
 		rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Identity: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Identity", "failed to unmarshal Identity")
 		}
 		ace.RawBytesSize += uint32(rawBytesSize)
 
@@ -181,14 +202,14 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Mask (4 bytes): An ACCESS_MASK that specifies the user rights allowed by this ACE.
 		rawBytesSize, err = ace.Mask.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Mask: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Mask", "failed to unmarshal Mask")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
 
 		rawBytesSize, err = ace.AccessControlObjectType.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal AccessControlObjectType: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "AccessControlObjectType", "failed to unmarshal AccessControlObjectType")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
@@ -211,7 +232,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Sid (variable): The SID of a trustee. The length of the SID MUST be a multiple of 4.
 		rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Identity: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Identity", "failed to unmarshal Identity")
 		}
 		ace.RawBytesSize += uint32(rawBytesSize)
 
@@ -222,14 +243,14 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Mask (4 bytes): An ACCESS_MASK that specifies the user rights allowed by this ACE.
 		rawBytesSize, err = ace.Mask.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Mask: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Mask", "failed to unmarshal Mask")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
 
 		rawBytesSize, err = ace.AccessControlObjectType.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal AccessControlObjectType: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "AccessControlObjectType", "failed to unmarshal AccessControlObjectType")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
@@ -252,7 +273,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Sid (variable): The SID of a trustee. The length of the SID MUST be a multiple of 4.
 		rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Identity: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Identity", "failed to unmarshal Identity")
 		}
 		ace.RawBytesSize += uint32(rawBytesSize)
 
@@ -261,10 +282,12 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// TODO: Parse ApplicationData if necessary
 	case acetype.ACE_TYPE_SYSTEM_ALARM_OBJECT:
 		// Parsing ACE of type SYSTEM_ALARM_OBJECT_ACE_TYPE
-		// Source: ?
+		// Source: https://learn.microsoft.com/en-us/windows/win32/api/winnt/ns-winnt-system_alarm_object_ace
 
-		// Reserved for future use.
+		// Reserved for future use in MS-DTYP, the layout is the one of SYSTEM_AUDIT_OBJECT_ACE.
+		// Malformed bodies are kept opaque.
 		// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/628ebb1d-c509-4ea0-a10f-77ef97ca4586
+		ace.RawBytesSize += uint32(ace.unmarshalLegacyBody(marshalledData))
 
 	case acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK:
 		// Parsing ACE of type ACCESS_ALLOWED_CALLBACK_ACE_TYPE
@@ -273,7 +296,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Mask (4 bytes): An ACCESS_MASK that specifies the user rights allowed by this ACE.
 		rawBytesSize, err = ace.Mask.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Mask: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Mask", "failed to unmarshal Mask")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
@@ -281,7 +304,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Sid (variable): The SID of a trustee. The length of the SID MUST be a multiple of 4.
 		rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Identity: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Identity", "failed to unmarshal Identity")
 		}
 		ace.RawBytesSize += uint32(rawBytesSize)
 
@@ -296,7 +319,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Mask (4 bytes): An ACCESS_MASK that specifies the user rights allowed by this ACE.
 		rawBytesSize, err = ace.Mask.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Mask: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Mask", "failed to unmarshal Mask")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
@@ -304,7 +327,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Sid (variable): The SID of a trustee. The length of the SID MUST be a multiple of 4.
 		rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Identity: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Identity", "failed to unmarshal Identity")
 		}
 		ace.RawBytesSize += uint32(rawBytesSize)
 
@@ -319,14 +342,14 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Mask (4 bytes): An ACCESS_MASK that specifies the user rights allowed by this ACE.
 		rawBytesSize, err = ace.Mask.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Mask: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Mask", "failed to unmarshal Mask")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
 
 		rawBytesSize, err = ace.AccessControlObjectType.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal AccessControlObjectType: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "AccessControlObjectType", "failed to unmarshal AccessControlObjectType")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
@@ -349,7 +372,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Sid (variable): The SID of a trustee. The length of the SID MUST be a multiple of 4.
 		rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Identity: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Identity", "failed to unmarshal Identity")
 		}
 		ace.RawBytesSize += uint32(rawBytesSize)
 
@@ -364,14 +387,14 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Mask (4 bytes): An ACCESS_MASK that specifies the user rights allowed by this ACE.
 		rawBytesSize, err = ace.Mask.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Mask: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Mask", "failed to unmarshal Mask")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
 
 		rawBytesSize, err = ace.AccessControlObjectType.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal AccessControlObjectType: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "AccessControlObjectType", "failed to unmarshal AccessControlObjectType")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
@@ -394,7 +417,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Sid (variable): The SID of a trustee. The length of the SID MUST be a multiple of 4.
 		rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Identity: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Identity", "failed to unmarshal Identity")
 		}
 		ace.RawBytesSize += uint32(rawBytesSize)
 
@@ -409,7 +432,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Mask (4 bytes): An ACCESS_MASK that specifies the user rights allowed by this ACE.
 		rawBytesSize, err = ace.Mask.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Mask: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Mask", "failed to unmarshal Mask")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
@@ -417,7 +440,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Sid (variable): The SID of a trustee. The length of the SID MUST be a multiple of 4.
 		rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Identity: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Identity", "failed to unmarshal Identity")
 		}
 		ace.RawBytesSize += uint32(rawBytesSize)
 
@@ -427,11 +450,12 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 
 	case acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK:
 		// Parsing ACE of type SYSTEM_ALARM_CALLBACK_ACE_TYPE
-		// Source: ?
+		// Source: https://learn.microsoft.com/en-us/windows/win32/api/winnt/ns-winnt-system_alarm_callback_ace
 
-		// Reserved for future use.
+		// Reserved for future use in MS-DTYP, the layout is the one of SYSTEM_AUDIT_CALLBACK_ACE.
+		// Malformed bodies are kept opaque.
 		// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/628ebb1d-c509-4ea0-a10f-77ef97ca4586
-		// No parsing required as it is reserved for future use.
+		ace.RawBytesSize += uint32(ace.unmarshalLegacyBody(marshalledData))
 
 	case acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK_OBJECT:
 		// Parsing ACE of type SYSTEM_AUDIT_CALLBACK_OBJECT_ACE_TYPE
@@ -440,14 +464,14 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Mask (4 bytes): An ACCESS_MASK that specifies the user rights allowed by this ACE.
 		rawBytesSize, err = ace.Mask.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Mask: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Mask", "failed to unmarshal Mask")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
 
 		rawBytesSize, err = ace.AccessControlObjectType.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal AccessControlObjectType: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "AccessControlObjectType", "failed to unmarshal AccessControlObjectType")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
@@ -470,7 +494,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Sid (variable): The SID of a trustee. The length of the SID MUST be a multiple of 4.
 		rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Identity: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Identity", "failed to unmarshal Identity")
 		}
 		ace.RawBytesSize += uint32(rawBytesSize)
 
@@ -480,11 +504,12 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 
 	case acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK_OBJECT:
 		// Parsing ACE of type SYSTEM_ALARM_CALLBACK_OBJECT_ACE_TYPE
-		// Source: ?
+		// Source: https://learn.microsoft.com/en-us/windows/win32/api/winnt/ns-winnt-system_alarm_callback_object_ace
 
-		// Reserved for future use.
+		// Reserved for future use in MS-DTYP, the layout is the one of SYSTEM_AUDIT_CALLBACK_OBJECT_ACE.
+		// Malformed bodies are kept opaque.
 		// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/628ebb1d-c509-4ea0-a10f-77ef97ca4586
-		// No parsing required as it is reserved for future use.
+		ace.RawBytesSize += uint32(ace.unmarshalLegacyBody(marshalledData))
 
 	case acetype.ACE_TYPE_SYSTEM_MANDATORY_LABEL:
 		// Parsing ACE of type SYSTEM_MANDATORY_LABEL_ACE_TYPE
@@ -493,7 +518,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Mask (4 bytes): An ACCESS_MASK that specifies the user rights allowed by this ACE.
 		rawBytesSize, err = ace.Mask.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Mask: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Mask", "failed to unmarshal Mask")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
@@ -501,7 +526,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Sid (variable): The SID of a trustee. The length of the SID MUST be a multiple of 4.
 		rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Identity: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Identity", "failed to unmarshal Identity")
 		}
 		ace.RawBytesSize += uint32(rawBytesSize)
 
@@ -512,7 +537,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Mask (4 bytes): An ACCESS_MASK that specifies the user rights allowed by this ACE.
 		rawBytesSize, err = ace.Mask.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Mask: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Mask", "failed to unmarshal Mask")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
@@ -520,7 +545,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Sid (variable): The SID of a trustee. The length of the SID MUST be a multiple of 4.
 		rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Identity: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Identity", "failed to unmarshal Identity")
 		}
 		ace.RawBytesSize += uint32(rawBytesSize)
 
@@ -535,7 +560,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Mask (4 bytes): An ACCESS_MASK that specifies the user rights allowed by this ACE.
 		rawBytesSize, err = ace.Mask.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Mask: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Mask", "failed to unmarshal Mask")
 		}
 		marshalledData = marshalledData[rawBytesSize:]
 		ace.RawBytesSize += uint32(rawBytesSize)
@@ -543,7 +568,7 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// Sid (variable): The SID of a trustee. The length of the SID MUST be a multiple of 4.
 		rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
 		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal Identity: %w", err)
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Identity", "failed to unmarshal Identity")
 		}
 		ace.RawBytesSize += uint32(rawBytesSize)
 
@@ -551,9 +576,58 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 		// data is determined by the AceSize field of the ACE_HEADER.
 		// TODO: Parse ApplicationData if necessary
 
+	case acetype.ACE_TYPE_SYSTEM_PROCESS_TRUST_LABEL:
+		// Parsing ACE of type SYSTEM_PROCESS_TRUST_LABEL_ACE_TYPE
+		// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/628ebb1d-c509-4ea0-a10f-77ef97ca4586
+
+		// Mask (4 bytes): An ACCESS_MASK that specifies the access granted to processes that are
+		// not protected at the level of the trust SID.
+		rawBytesSize, err = ace.Mask.Unmarshal(marshalledData)
+		if err != nil {
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Mask", "failed to unmarshal Mask")
+		}
+		marshalledData = marshalledData[rawBytesSize:]
+		ace.RawBytesSize += uint32(rawBytesSize)
+
+		// Sid (variable): The process trust label SID (S-1-19-<protection type>-<protection level>).
+		rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
+		if err != nil {
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Identity", "failed to unmarshal Identity")
+		}
+		ace.RawBytesSize += uint32(rawBytesSize)
+
+	case acetype.ACE_TYPE_SYSTEM_ACCESS_FILTER:
+		// Parsing ACE of type SYSTEM_ACCESS_FILTER_ACE_TYPE
+		// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/628ebb1d-c509-4ea0-a10f-77ef97ca4586
+
+		// Mask (4 bytes): An ACCESS_MASK that specifies the access granted when the conditional
+		// expression is not satisfied.
+		rawBytesSize, err = ace.Mask.Unmarshal(marshalledData)
+		if err != nil {
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Mask", "failed to unmarshal Mask")
+		}
+		marshalledData = marshalledData[rawBytesSize:]
+		ace.RawBytesSize += uint32(rawBytesSize)
+
+		// Sid (variable): The SID of a trustee, usually Everyone (S-1-1-0).
+		rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
+		if err != nil {
+			return 0, parsing.WrapParseError(err, int(ace.RawBytesSize), "Identity", "failed to unmarshal Identity")
+		}
+		ace.RawBytesSize += uint32(rawBytesSize)
+
+		// ApplicationData (variable): The conditional expression of the filter, see Condition().
+
 	default:
+		// Custom ACE types registered with RegisterAccessControlEntryType are kept opaque,
+		// their typed view is created from the body by the factory of the type.
+		if _, registered := LookupAccessControlEntryType(ace.Header.Type.Value); registered {
+			ace.Opaque = true
+			break
+		}
+
 		// Unknown ACE type
-		return 0, fmt.Errorf("unknown ACE type: %d", ace.Header.Type.Value)
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_UNKNOWN_TYPE, 0, "Header.Type", "unknown ACE type: %d", ace.Header.Type.Value)
 	}
 
 	// Capture any trailing bytes that follow the fixed ACE fields as
@@ -580,283 +654,232 @@ func (ace *AccessControlEntry) Unmarshal(marshalledData []byte) (int, error) {
 // Returns:
 //   - []byte: The serialized byte slice representing the ACE.
 func (ace *AccessControlEntry) Marshal() ([]byte, error) {
-	marshalledData := make([]byte, 0)
-
-	var err error
-	var bytesStream []byte
-
-	switch ace.Header.Type.Value {
-
-	case acetype.ACE_TYPE_ACCESS_ALLOWED:
-		bytesStream, err = ace.Mask.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Mask: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
-
-		bytesStream, err = ace.Identity.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Identity: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
-
-	case acetype.ACE_TYPE_ACCESS_DENIED:
-		bytesStream, err = ace.Mask.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Mask: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
-
-		bytesStream, err = ace.Identity.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Identity: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
-
-	case acetype.ACE_TYPE_SYSTEM_AUDIT:
-		bytesStream, err = ace.Mask.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Mask: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
-
-		bytesStream, err = ace.Identity.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Identity: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
-
-	case acetype.ACE_TYPE_SYSTEM_ALARM:
-	case acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND:
-	case acetype.ACE_TYPE_ACCESS_ALLOWED_OBJECT:
-		bytesStream, err = ace.Mask.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Mask: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
-
-		bytesStream, err = ace.AccessControlObjectType.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal AccessControlObjectType: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
-
-		bytesStream, err = ace.Identity.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Identity: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
-
-	case acetype.ACE_TYPE_ACCESS_DENIED_OBJECT:
-		bytesStream, err = ace.Mask.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Mask: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+	marshalledData, err := ace.AppendBinary(make([]byte, 0, ace.Size()))
+	if err != nil {
+		return nil, err
+	}
+	ace.RefreshRawBytes(marshalledData)
+	return marshalledData, nil
+}
 
-		bytesStream, err = ace.AccessControlObjectType.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal AccessControlObjectType: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+// RefreshRawBytes points RawBytes and RawBytesSize at the marshalled bytes of the ACE, and
+// refreshes its header and the fields of its type. Like after Unmarshal, RawBytesSize counts
+// the header and the fields, not the ApplicationData nor the padding.
+//
+// Parameters:
+//   - marshalledData ([]byte): The bytes produced by AppendBinary for the ACE.
+func (ace *AccessControlEntry) RefreshRawBytes(marshalledData []byte) {
+	ace.RawBytes = marshalledData[:ace.Size()]
+	ace.Header.RefreshRawBytes(marshalledData)
+
+	offset := ace.Header.MarshalledSize()
+	if ace.bodySize() > 0 {
+		ace.Mask.RefreshRawBytes(marshalledData[offset:])
+		offset += ace.Mask.Size()
+
+		if ace.Header.Type.Value == acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND {
+			ace.Compound.RefreshRawBytes(marshalledData[offset:])
+			offset += ace.Compound.Size()
+		} else if ace.IsObjectAce() {
+			ace.AccessControlObjectType.RefreshRawBytes(marshalledData[offset:])
+			offset += ace.AccessControlObjectType.Size()
+		}
+
+		ace.Identity.RefreshRawBytes(marshalledData[offset:])
+		offset += ace.Identity.Size()
+	}
+	ace.RawBytesSize = uint32(offset)
+}
 
-		bytesStream, err = ace.Identity.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Identity: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+// Size returns the size of the binary representation of the ACE, as produced by Marshal. It
+// is the size of the header, the fields of the ACE type and the ApplicationData, or
+// Header.Size if the ACE is padded to a larger size.
+//
+// Returns:
+//   - int: The size of the ACE, in bytes.
+func (ace *AccessControlEntry) Size() int {
+	size := ace.Header.MarshalledSize() + ace.bodySize() + len(ace.ApplicationData)
+	if int(ace.Header.Size) > size {
+		return int(ace.Header.Size)
+	}
+	return size
+}
 
-	case acetype.ACE_TYPE_SYSTEM_AUDIT_OBJECT:
-		bytesStream, err = ace.Mask.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Mask: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+// AppendBinary appends the binary representation of the ACE to a buffer. When the buffer has
+// Size() bytes of spare capacity, nothing is allocated. Like Marshal, it updates Header.Size
+// when the fields of the ACE do not fit in it.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: An error if the ACE is too large or one of its fields cannot be serialized.
+func (ace *AccessControlEntry) AppendBinary(buffer []byte) ([]byte, error) {
+	// The ACE Header.Size field is a uint16, so the body plus the 4-byte header
+	// cannot exceed 65535 bytes. Guard the conversion below so an oversized
+	// ApplicationData payload errors instead of silently wrapping into a tiny,
+	// corrupt Header.Size.
+	totalSize := ace.Header.MarshalledSize() + ace.bodySize() + len(ace.ApplicationData)
+	if totalSize > 0xFFFF {
+		return nil, fmt.Errorf("ACE too large to marshal: size %d exceeds the uint16 Header.Size maximum (65535)", totalSize)
+	}
+	if int(ace.Header.Size) <= totalSize {
+		ace.Header.Size = uint16(totalSize)
+	}
+	start := len(buffer)
 
-		bytesStream, err = ace.AccessControlObjectType.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal AccessControlObjectType: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+	buffer, err := ace.Header.AppendBinary(buffer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Header: %w", err)
+	}
 
-		bytesStream, err = ace.Identity.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Identity: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+	buffer, err = ace.appendBody(buffer)
+	if err != nil {
+		return nil, err
+	}
 
-	case acetype.ACE_TYPE_SYSTEM_ALARM_OBJECT:
-	case acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK:
-		bytesStream, err = ace.Mask.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Mask: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+	// Append any preserved ApplicationData (conditional expression for callback
+	// ACEs, attribute data for resource-attribute ACEs, policy id for
+	// scoped-policy ACEs) so it survives the round-trip instead of being lost to
+	// zero padding below.
+	buffer = append(buffer, ace.ApplicationData...)
 
-		bytesStream, err = ace.Identity.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Identity: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+	// Pad the marshalled data to the size specified in the ACE header
+	for len(buffer)-start < int(ace.Header.Size) {
+		buffer = append(buffer, 0)
+	}
 
-	case acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK_OBJECT:
-		bytesStream, err = ace.Mask.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Mask: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+	return buffer, nil
+}
 
-		bytesStream, err = ace.AccessControlObjectType.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal AccessControlObjectType: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+// MarshalTo writes the binary representation of the ACE at the start of a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to write to, at least Size() bytes long.
+//
+// Returns:
+//   - int: The number of bytes written.
+//   - error: An error if the buffer is too short or the ACE cannot be serialized.
+func (ace *AccessControlEntry) MarshalTo(buffer []byte) (int, error) {
+	size := ace.Size()
+	if len(buffer) < size {
+		return 0, fmt.Errorf("ACE requires a buffer of %d bytes, got %d: %w", size, len(buffer), io.ErrShortBuffer)
+	}
+	marshalledData, err := ace.AppendBinary(buffer[:0:size])
+	if err != nil {
+		return 0, err
+	}
+	return len(marshalledData), nil
+}
 
-		bytesStream, err = ace.Identity.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Identity: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+// bodySize returns the size of the fields of the ACE type, between the header and the
+// ApplicationData, see appendBody.
+//
+// Returns:
+//   - int: The size of the fields, 0 for an Opaque ACE.
+func (ace *AccessControlEntry) bodySize() int {
+	// The whole body of an Opaque ACE is held by ApplicationData
+	if ace.Opaque {
+		return 0
+	}
 
-	case acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK:
-		bytesStream, err = ace.Mask.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Mask: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+	if ace.IsLegacy() {
+		return ace.legacyBodySize()
+	}
 
-		bytesStream, err = ace.Identity.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Identity: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+	switch ace.Header.Type.Value {
+	case acetype.ACE_TYPE_ACCESS_ALLOWED,
+		acetype.ACE_TYPE_ACCESS_DENIED,
+		acetype.ACE_TYPE_SYSTEM_AUDIT,
+		acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK,
+		acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK,
+		acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK,
+		acetype.ACE_TYPE_SYSTEM_MANDATORY_LABEL,
+		acetype.ACE_TYPE_SYSTEM_RESOURCE_ATTRIBUTE,
+		acetype.ACE_TYPE_SYSTEM_SCOPED_POLICY_ID,
+		acetype.ACE_TYPE_SYSTEM_PROCESS_TRUST_LABEL,
+		acetype.ACE_TYPE_SYSTEM_ACCESS_FILTER:
+		return ace.Mask.Size() + ace.Identity.Size()
+
+	case acetype.ACE_TYPE_ACCESS_ALLOWED_OBJECT,
+		acetype.ACE_TYPE_ACCESS_DENIED_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_AUDIT_OBJECT,
+		acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK_OBJECT:
+		return ace.Mask.Size() + ace.AccessControlObjectType.Size() + ace.Identity.Size()
+	}
 
-	case acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK_OBJECT:
-		bytesStream, err = ace.Mask.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Mask: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+	return 0
+}
 
-		bytesStream, err = ace.AccessControlObjectType.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal AccessControlObjectType: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+// appendBody serializes the fields of the ACE type: the mask, the object type fields for
+// the object types, and the SID. The compound and alarm types are handled by
+// appendLegacyBody.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append the fields to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: An error if one of the fields cannot be serialized.
+func (ace *AccessControlEntry) appendBody(buffer []byte) ([]byte, error) {
+	// The whole body of an Opaque ACE is held by ApplicationData
+	if ace.Opaque {
+		return buffer, nil
+	}
 
-		bytesStream, err = ace.Identity.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Identity: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+	if ace.IsLegacy() {
+		return ace.appendLegacyBody(buffer)
+	}
 
-	case acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK:
-		bytesStream, err = ace.Mask.Marshal()
+	var err error
+	switch ace.Header.Type.Value {
+	case acetype.ACE_TYPE_ACCESS_ALLOWED,
+		acetype.ACE_TYPE_ACCESS_DENIED,
+		acetype.ACE_TYPE_SYSTEM_AUDIT,
+		acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK,
+		acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK,
+		acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK,
+		acetype.ACE_TYPE_SYSTEM_MANDATORY_LABEL,
+		acetype.ACE_TYPE_SYSTEM_RESOURCE_ATTRIBUTE,
+		acetype.ACE_TYPE_SYSTEM_SCOPED_POLICY_ID,
+		acetype.ACE_TYPE_SYSTEM_PROCESS_TRUST_LABEL,
+		acetype.ACE_TYPE_SYSTEM_ACCESS_FILTER:
+		buffer, err = ace.Mask.AppendBinary(buffer)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal Mask: %w", err)
 		}
-		marshalledData = append(marshalledData, bytesStream...)
 
-		bytesStream, err = ace.Identity.Marshal()
+		buffer, err = ace.Identity.AppendBinary(buffer)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal Identity: %w", err)
 		}
-		marshalledData = append(marshalledData, bytesStream...)
 
-	case acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK:
-	case acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK_OBJECT:
-		bytesStream, err = ace.Mask.Marshal()
+	case acetype.ACE_TYPE_ACCESS_ALLOWED_OBJECT,
+		acetype.ACE_TYPE_ACCESS_DENIED_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_AUDIT_OBJECT,
+		acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK_OBJECT:
+		buffer, err = ace.Mask.AppendBinary(buffer)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal Mask: %w", err)
 		}
-		marshalledData = append(marshalledData, bytesStream...)
 
-		bytesStream, err = ace.AccessControlObjectType.Marshal()
+		buffer, err = ace.AccessControlObjectType.AppendBinary(buffer)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal AccessControlObjectType: %w", err)
 		}
-		marshalledData = append(marshalledData, bytesStream...)
 
-		bytesStream, err = ace.Identity.Marshal()
+		buffer, err = ace.Identity.AppendBinary(buffer)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal Identity: %w", err)
 		}
-		marshalledData = append(marshalledData, bytesStream...)
-
-	case acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK_OBJECT:
-	case acetype.ACE_TYPE_SYSTEM_MANDATORY_LABEL:
-		bytesStream, err = ace.Mask.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Mask: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
-
-		bytesStream, err = ace.Identity.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Identity: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
-
-	case acetype.ACE_TYPE_SYSTEM_RESOURCE_ATTRIBUTE:
-		bytesStream, err = ace.Mask.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Mask: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
-
-		bytesStream, err = ace.Identity.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Identity: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
-
-	case acetype.ACE_TYPE_SYSTEM_SCOPED_POLICY_ID:
-		bytesStream, err = ace.Mask.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Mask: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
-
-		bytesStream, err = ace.Identity.Marshal()
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal Identity: %w", err)
-		}
-		marshalledData = append(marshalledData, bytesStream...)
 	}
 
-	// Append any preserved ApplicationData (conditional expression for callback
-	// ACEs, attribute data for resource-attribute ACEs, policy id for
-	// scoped-policy ACEs) so it survives the round-trip instead of being lost to
-	// zero padding below.
-	marshalledData = append(marshalledData, ace.ApplicationData...)
-
-	// Pad the marshalled data to the size specified in the ACE header
-	headerSize := 4
-	// The ACE Header.Size field is a uint16, so the body plus the 4-byte header
-	// cannot exceed 65535 bytes. Guard the conversion below so an oversized
-	// ApplicationData payload errors instead of silently wrapping into a tiny,
-	// corrupt Header.Size.
-	totalSize := len(marshalledData) + headerSize
-	if totalSize > 0xFFFF {
-		return nil, fmt.Errorf("ACE too large to marshal: size %d exceeds the uint16 Header.Size maximum (65535)", totalSize)
-	}
-	if ace.Header.Size > uint16(totalSize) {
-		// Pad the marshalled data to the size specified in the ACE header
-		for uint32(len(marshalledData)+headerSize) < uint32(ace.Header.Size) {
-			marshalledData = append(marshalledData, 0)
-		}
-	} else {
-		ace.Header.Size = uint16(totalSize)
-	}
-
-	// Marshal Header and append at start of marshalled data
-	bytesStream, err = ace.Header.Marshal()
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal Header: %w", err)
-	}
-	marshalledData = append(bytesStream, marshalledData...)
-
-	return marshalledData, nil
+	return buffer, nil
 }
 
 // Describe prints a detailed description of the AccessControlEntry struct,
@@ -871,6 +894,15 @@ func (ace *AccessControlEntry) Describe(indent int) {
 	fmt.Printf("%s<AccessControlEntry #%d>\n", indentPrompt, ace.Index)
 	ace.Header.Describe(indent + 1)
 
+	// The whole body of an Opaque ACE is held by ApplicationData
+	if ace.Opaque {
+		if len(ace.ApplicationData) > 0 {
+			fmt.Printf("%s │ \x1b[93mApplicationData\x1b[0m : \x1b[96m%s\x1b[0m\n", indentPrompt, hex.EncodeToString(ace.ApplicationData))
+		}
+		fmt.Printf("%s └─\n", indentPrompt)
+		return
+	}
+
 	switch ace.Header.Type.Value {
 
 	case acetype.ACE_TYPE_ACCESS_ALLOWED:
@@ -886,23 +918,29 @@ func (ace *AccessControlEntry) Describe(indent int) {
 		ace.Identity.Describe(indent + 1)
 
 	case acetype.ACE_TYPE_SYSTEM_ALARM:
+		ace.describeLegacyBody(indent + 1)
+
 	case acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND:
+		ace.describeLegacyBody(indent + 1)
+
 	case acetype.ACE_TYPE_ACCESS_ALLOWED_OBJECT:
 		ace.Mask.Describe(indent + 1)
-		ace.AccessControlObjectType.Describe(indent + 1)
+		ace.AccessControlObjectType.DescribeWithNames(indent+1, ace.InterpretObjectType().String(), ace.InterpretInheritedObjectType().String())
 		ace.Identity.Describe(indent + 1)
 
 	case acetype.ACE_TYPE_ACCESS_DENIED_OBJECT:
 		ace.Mask.Describe(indent + 1)
-		ace.AccessControlObjectType.Describe(indent + 1)
+		ace.AccessControlObjectType.DescribeWithNames(indent+1, ace.InterpretObjectType().String(), ace.InterpretInheritedObjectType().String())
 		ace.Identity.Describe(indent + 1)
 
 	case acetype.ACE_TYPE_SYSTEM_AUDIT_OBJECT:
 		ace.Mask.Describe(indent + 1)
-		ace.AccessControlObjectType.Describe(indent + 1)
+		ace.AccessControlObjectType.DescribeWithNames(indent+1, ace.InterpretObjectType().String(), ace.InterpretInheritedObjectType().String())
 		ace.Identity.Describe(indent + 1)
 
 	case acetype.ACE_TYPE_SYSTEM_ALARM_OBJECT:
+		ace.describeLegacyBody(indent + 1)
+
 	case acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK:
 		ace.Mask.Describe(indent + 1)
 		ace.Identity.Describe(indent + 1)
@@ -913,12 +951,12 @@ func (ace *AccessControlEntry) Describe(indent int) {
 
 	case acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK_OBJECT:
 		ace.Mask.Describe(indent + 1)
-		ace.AccessControlObjectType.Describe(indent + 1)
+		ace.AccessControlObjectType.DescribeWithNames(indent+1, ace.InterpretObjectType().String(), ace.InterpretInheritedObjectType().String())
 		ace.Identity.Describe(indent + 1)
 
 	case acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK_OBJECT:
 		ace.Mask.Describe(indent + 1)
-		ace.AccessControlObjectType.Describe(indent + 1)
+		ace.AccessControlObjectType.DescribeWithNames(indent+1, ace.InterpretObjectType().String(), ace.InterpretInheritedObjectType().String())
 		ace.Identity.Describe(indent + 1)
 
 	case acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK:
@@ -926,12 +964,16 @@ func (ace *AccessControlEntry) Describe(indent int) {
 		ace.Identity.Describe(indent + 1)
 
 	case acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK:
+		ace.describeLegacyBody(indent + 1)
+
 	case acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK_OBJECT:
 		ace.Mask.Describe(indent + 1)
-		ace.AccessControlObjectType.Describe(indent + 1)
+		ace.AccessControlObjectType.DescribeWithNames(indent+1, ace.InterpretObjectType().String(), ace.InterpretInheritedObjectType().String())
 		ace.Identity.Describe(indent + 1)
 
 	case acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK_OBJECT:
+		ace.describeLegacyBody(indent + 1)
+
 	case acetype.ACE_TYPE_SYSTEM_MANDATORY_LABEL:
 		ace.Mask.Describe(indent + 1)
 		ace.Identity.Describe(indent + 1)
@@ -943,9 +985,19 @@ func (ace *AccessControlEntry) Describe(indent int) {
 	case acetype.ACE_TYPE_SYSTEM_SCOPED_POLICY_ID:
 		ace.Mask.Describe(indent + 1)
 		ace.Identity.Describe(indent + 1)
+
+	case acetype.ACE_TYPE_SYSTEM_PROCESS_TRUST_LABEL:
+		ace.Mask.Describe(indent + 1)
+		ace.Identity.Describe(indent + 1)
+
+	case acetype.ACE_TYPE_SYSTEM_ACCESS_FILTER:
+		ace.Mask.Describe(indent + 1)
+		ace.Identity.Describe(indent + 1)
 	}
 
-	if len(ace.ApplicationData) > 0 {
+	if condition, err := ace.Condition(); err == nil && condition != nil {
+		condition.Describe(indent + 1)
+	} else if len(ace.ApplicationData) > 0 {
 		fmt.Printf("%s │ \x1b[93mApplicationData\x1b[0m : \x1b[96m%s\x1b[0m\n", indentPrompt, hex.EncodeToString(ace.ApplicationData))
 	}
 
@@ -0,0 +1,90 @@
+package ace
+
+import (
+	"fmt"
+
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/ace/conditional"
+)
+
+// IsConditional checks if the type of the ACE can carry a conditional expression in its
+// ApplicationData, i.e. if it is a callback ACE or an access filter ACE.
+//
+// Returns:
+//   - bool: true if the ACE type supports a conditional expression, false otherwise.
+func (ace *AccessControlEntry) IsConditional() bool {
+	switch ace.Header.Type.Value {
+	case acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK,
+		acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK,
+		acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK,
+		acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK,
+		acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_ACCESS_FILTER:
+		return true
+	}
+	return false
+}
+
+// Condition decodes the conditional expression stored in the ApplicationData of the ACE.
+//
+// Returns:
+//   - *conditional.ConditionalExpression: The conditional expression, or nil if the ACE type does not
+//     support conditions or its ApplicationData does not start with the "artx" signature.
+//   - error: An error if the conditional expression is malformed.
+func (ace *AccessControlEntry) Condition() (*conditional.ConditionalExpression, error) {
+	if !ace.IsConditional() || !conditional.IsConditionalExpression(ace.ApplicationData) {
+		return nil, nil
+	}
+	condition := &conditional.ConditionalExpression{}
+	if _, err := condition.Unmarshal(ace.ApplicationData); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal conditional expression: %w", err)
+	}
+	return condition, nil
+}
+
+// SetCondition stores a conditional expression in the ApplicationData of the ACE. A nil
+// condition removes the ApplicationData.
+//
+// Parameters:
+//   - condition (*conditional.ConditionalExpression): The conditional expression.
+//
+// Returns:
+//   - error: An error if the ACE type does not support conditions or the expression cannot be marshalled.
+func (ace *AccessControlEntry) SetCondition(condition *conditional.ConditionalExpression) error {
+	if !ace.IsConditional() {
+		return fmt.Errorf("ACE type %s does not support a conditional expression", ace.Header.Type.String())
+	}
+	if condition == nil {
+		ace.ApplicationData = nil
+		return nil
+	}
+	applicationData, err := condition.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal conditional expression: %w", err)
+	}
+	ace.ApplicationData = applicationData
+	return nil
+}
+
+// EvaluateCondition evaluates the conditional expression of the ACE against a security context.
+// An ACE without a condition evaluates to TRUE.
+//
+// Parameters:
+//   - context (*conditional.EvaluationContext): The SIDs and attributes of the security context.
+//
+// Returns:
+//   - conditional.EvaluationResult: TRUE, FALSE or UNKNOWN.
+//   - error: An error if the conditional expression is malformed.
+func (ace *AccessControlEntry) EvaluateCondition(context *conditional.EvaluationContext) (conditional.EvaluationResult, error) {
+	condition, err := ace.Condition()
+	if err != nil {
+		return conditional.EVALUATION_RESULT_UNKNOWN, err
+	}
+	if condition == nil {
+		return conditional.EVALUATION_RESULT_TRUE, nil
+	}
+	return condition.Evaluate(context)
+}
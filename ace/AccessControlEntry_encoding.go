@@ -0,0 +1,222 @@
+package ace
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TheManticoreProject/winacl/ace/aceflags"
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/ace/compound"
+	"github.com/TheManticoreProject/winacl/guid"
+	"github.com/TheManticoreProject/winacl/object/flags"
+	"github.com/TheManticoreProject/winacl/sid"
+)
+
+// jsonAccessControlEntry is the JSON representation of an ACE, see MarshalJSON.
+type jsonAccessControlEntry struct {
+	Type                uint8                           `json:"type"`
+	TypeName            string                          `json:"typeName"`
+	Flags               uint8                           `json:"flags"`
+	FlagNames           []string                        `json:"flagNames"`
+	Size                uint16                          `json:"size"`
+	Mask                *uint32                         `json:"mask,omitempty"`
+	Rights              []string                        `json:"rights,omitempty"`
+	SID                 *sid.SID                        `json:"sid,omitempty"`
+	ObjectFlags         *uint32                         `json:"objectFlags,omitempty"`
+	ObjectType          *guid.GUID                      `json:"objectType,omitempty"`
+	InheritedObjectType *guid.GUID                      `json:"inheritedObjectType,omitempty"`
+	Compound            *jsonAccessControlEntryCompound `json:"compound,omitempty"`
+	ApplicationData     []byte                          `json:"applicationData,omitempty"`
+	Opaque              bool                            `json:"opaque,omitempty"`
+}
+
+// jsonAccessControlEntryCompound is the JSON representation of the compound fields of an
+// ACCESS_ALLOWED_COMPOUND ACE.
+type jsonAccessControlEntryCompound struct {
+	Type      uint16  `json:"type"`
+	Reserved  uint16  `json:"reserved,omitempty"`
+	ServerSID sid.SID `json:"serverSid"`
+}
+
+// MarshalBinary returns the binary representation of the ACE, implementing
+// encoding.BinaryMarshaler. The ACE is left untouched, unlike with Marshal.
+//
+// Returns:
+//   - []byte: The binary representation of the ACE.
+//   - error: An error if the ACE cannot be serialized.
+func (ace *AccessControlEntry) MarshalBinary() ([]byte, error) {
+	// AppendBinary only writes the Size of the header, which is copied
+	layout := *ace
+	return layout.AppendBinary(make([]byte, 0, ace.Size()))
+}
+
+// UnmarshalBinary parses the binary representation of an ACE, implementing
+// encoding.BinaryUnmarshaler. The data is copied, so that the ACE does not reference it.
+//
+// Parameters:
+//   - data ([]byte): The binary representation of the ACE, without trailing bytes.
+//
+// Returns:
+//   - error: An error if the data is not exactly one ACE.
+func (ace *AccessControlEntry) UnmarshalBinary(data []byte) error {
+	*ace = AccessControlEntry{}
+	size, err := ace.Unmarshal(bytes.Clone(data))
+	if err != nil {
+		return err
+	}
+	if size != len(data) {
+		return fmt.Errorf("invalid binary ACE: %d trailing bytes after the ACE", len(data)-size)
+	}
+	return nil
+}
+
+// MarshalJSON returns the JSON representation of the ACE, implementing json.Marshaler. The
+// representation is described by the JSON schema documented with
+// securitydescriptor.JSON_SCHEMA_VERSION.
+//
+// Returns:
+//   - []byte: The JSON object representing the ACE.
+//   - error: An error if the ACE cannot be serialized.
+func (ace *AccessControlEntry) MarshalJSON() ([]byte, error) {
+	document := jsonAccessControlEntry{
+		Type:            ace.Header.Type.Value,
+		TypeName:        ace.Header.Type.String(),
+		Flags:           ace.Header.Flags.RawValue,
+		FlagNames:       []string{},
+		Size:            uint16(ace.Size()),
+		ApplicationData: ace.ApplicationData,
+		Opaque:          ace.Opaque,
+	}
+	for bit := 0; bit < 8; bit++ {
+		flag := uint8(1) << bit
+		if document.Flags&flag == 0 {
+			continue
+		}
+		name, exists := aceflags.AccessControlEntryFlagToName[flag]
+		if !exists {
+			name = fmt.Sprintf("0x%02x", flag)
+		}
+		document.FlagNames = append(document.FlagNames, name)
+	}
+	if !ace.Opaque {
+		document.Mask = &ace.Mask.RawValue
+		document.Rights = ace.Mask.Flags
+		document.SID = &ace.Identity.SID
+		if ace.Header.Type.Value == acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND {
+			document.Compound = &jsonAccessControlEntryCompound{
+				Type:      ace.Compound.Type,
+				Reserved:  ace.Compound.Reserved,
+				ServerSID: ace.Compound.ServerIdentity.SID,
+			}
+		} else if ace.IsObjectAce() {
+			document.ObjectFlags = &ace.AccessControlObjectType.Flags.Value
+			if ace.AccessControlObjectType.Flags.Value&flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT != 0 {
+				document.ObjectType = &ace.AccessControlObjectType.ObjectType.GUID
+			}
+			if ace.AccessControlObjectType.Flags.Value&flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT != 0 {
+				document.InheritedObjectType = &ace.AccessControlObjectType.InheritedObjectType.GUID
+			}
+		}
+	}
+	return json.Marshal(document)
+}
+
+// UnmarshalJSON parses the JSON representation of an ACE, implementing json.Unmarshaler. The
+// informative fields (typeName, flagNames and rights) are ignored, and RawBytes are refreshed
+// like after Marshal.
+//
+// Parameters:
+//   - data ([]byte): The JSON object representing the ACE.
+//
+// Returns:
+//   - error: An error if the data is not a valid JSON ACE.
+func (ace *AccessControlEntry) UnmarshalJSON(data []byte) error {
+	document := jsonAccessControlEntry{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("invalid JSON ACE: %w", err)
+	}
+
+	entry := AccessControlEntry{}
+	entry.Header.Type.Value = document.Type
+	entry.Header.Flags.Unmarshal([]byte{document.Flags})
+	entry.Header.Size = document.Size
+	entry.Opaque = document.Opaque
+	entry.ApplicationData = document.ApplicationData
+
+	if !document.Opaque {
+		if document.Mask == nil || document.SID == nil {
+			return fmt.Errorf("invalid JSON ACE: the mask and sid of the %s ACE are required", entry.Header.Type.String())
+		}
+		entry.Mask.RawValue = *document.Mask
+		entry.Mask.SetNamespace(entry.Mask.Namespace)
+		entry.Identity.SID = *document.SID
+		entry.Identity.Name = entry.Identity.SID.LookupName()
+
+		if entry.Header.Type.Value == acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND {
+			if document.Compound == nil {
+				return fmt.Errorf("invalid JSON ACE: the compound fields of the %s ACE are required", entry.Header.Type.String())
+			}
+			entry.Compound = compound.AccessControlEntryCompound{Type: document.Compound.Type, Reserved: document.Compound.Reserved}
+			entry.Compound.ServerIdentity.SID = document.Compound.ServerSID
+			entry.Compound.ServerIdentity.Name = document.Compound.ServerSID.LookupName()
+		} else if document.Compound != nil {
+			return fmt.Errorf("invalid JSON ACE: compound fields in the %s ACE", entry.Header.Type.String())
+		}
+
+		if document.ObjectFlags != nil || document.ObjectType != nil || document.InheritedObjectType != nil {
+			if !entry.IsObjectAce() {
+				return fmt.Errorf("invalid JSON ACE: object types in the %s ACE", entry.Header.Type.String())
+			}
+			if err := entry.unmarshalJSONObjectType(&document); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := entry.Marshal(); err != nil {
+		return fmt.Errorf("invalid JSON ACE: %w", err)
+	}
+	*ace = entry
+	return nil
+}
+
+// unmarshalJSONObjectType sets the object type fields of an object ACE from its JSON
+// representation. The flags are taken from objectFlags, which keeps the bits other than the
+// presence bits, or computed from the GUIDs present when it is omitted.
+//
+// Parameters:
+//   - document (*jsonAccessControlEntry): The JSON representation of the ACE.
+//
+// Returns:
+//   - error: An error if objectFlags does not match the GUIDs present.
+func (ace *AccessControlEntry) unmarshalJSONObjectType(document *jsonAccessControlEntry) error {
+	objectFlags := uint32(0)
+	if document.ObjectFlags != nil {
+		objectFlags = *document.ObjectFlags
+	} else {
+		if document.ObjectType != nil {
+			objectFlags |= flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT
+		}
+		if document.InheritedObjectType != nil {
+			objectFlags |= flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT
+		}
+	}
+
+	if (objectFlags&flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT != 0) != (document.ObjectType != nil) {
+		return fmt.Errorf("invalid JSON ACE: objectFlags 0x%08x of the %s ACE do not match the presence of its objectType", objectFlags, ace.Header.Type.String())
+	}
+	if (objectFlags&flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT != 0) != (document.InheritedObjectType != nil) {
+		return fmt.Errorf("invalid JSON ACE: objectFlags 0x%08x of the %s ACE do not match the presence of its inheritedObjectType", objectFlags, ace.Header.Type.String())
+	}
+
+	ace.AccessControlObjectType.Flags.Unmarshal(binary.LittleEndian.AppendUint32(nil, objectFlags))
+	if document.ObjectType != nil {
+		ace.AccessControlObjectType.ObjectType.GUID = *document.ObjectType
+	}
+	if document.InheritedObjectType != nil {
+		ace.AccessControlObjectType.InheritedObjectType.GUID = *document.InheritedObjectType
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package ace_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/ace"
+)
+
+func TestAccessControlEntry_Encoding(t *testing.T) {
+	const (
+		sid28   = "01050000000000051500000028bb82279261b9fe2474aa5d00020000"
+		appData = "61727478deadbeefcafe0000"
+	)
+
+	tests := []struct {
+		name string
+		hex  string
+		want string
+	}{
+		{"AccessAllowed", "00002400ff010f00" + sid28, `"sid":"S-1-5-21-662879016-4273562002-1571451940-512"`},
+		{"Padded", "00002800ff010f00" + sid28 + "00000000", `"size":40`},
+		{"ObjectTypes", "075a38002000000003000000be3b0ef3f09fd111b6030000f80367c1a57a96bfe60dd011a28500aa003049e2010100000000000100000000", `"objectType":"f30e3bbe-9ff0-11d1-b603-0000f80367c1","inheritedObjectType":"bf967aa5-0de6-11d0-a285-00aa003049e2"`},
+		{"NonstandardObjectFlags", "075a38002000000003000010be3b0ef3f09fd111b6030000f80367c1a57a96bfe60dd011a28500aa003049e2010100000000000100000000", `"objectFlags":268435459,`},
+		{"ObjectFlagsWithoutTypes", "05002800ff010f0004000000" + sid28, `"objectFlags":4}`},
+		{"Callback", "09003000ff010f00" + sid28 + appData, `"applicationData":"YXJ0eN6tvu/K/gAA"`},
+		{"Compound", "04003400ff010f00" + "01000000" + "010100000000000100000000" + sid28, `"compound":{"type":1,"serverSid":"S-1-1-0"}`},
+		{"Opaque", "03000800deadbeef", `"applicationData":"3q2+7w==","opaque":true`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawBytes, err := hex.DecodeString(tt.hex)
+			if err != nil {
+				t.Fatalf("Failed to decode hex string: %v", err)
+			}
+			original := &ace.AccessControlEntry{}
+			if err := original.UnmarshalBinary(rawBytes); err != nil {
+				t.Fatalf("UnmarshalBinary() error = %v", err)
+			}
+
+			jsonData, err := json.Marshal(original)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if !strings.Contains(string(jsonData), tt.want) {
+				t.Errorf("json.Marshal() = %s, want it to contain %s", jsonData, tt.want)
+			}
+
+			fromJSON := &ace.AccessControlEntry{}
+			if err := json.Unmarshal(jsonData, fromJSON); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if !bytes.Equal(fromJSON.RawBytes, rawBytes) {
+				t.Errorf("json.Unmarshal().RawBytes = %x, want %x", fromJSON.RawBytes, rawBytes)
+			}
+			binaryData, err := fromJSON.MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary() error = %v", err)
+			}
+			if !bytes.Equal(binaryData, rawBytes) {
+				t.Errorf("MarshalBinary() = %x, want %x", binaryData, rawBytes)
+			}
+		})
+	}
+}
+
+func TestAccessControlEntry_UnmarshalJSON_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     string
+	}{
+		{"MissingSID", `{"type":0,"flags":0,"size":0,"mask":1}`, "the mask and sid of the ACCESS_ALLOWED ACE are required"},
+		{"ObjectTypeOnBasicACE", `{"type":0,"mask":1,"sid":"S-1-1-0","objectType":"bf967aba-0de6-11d0-a285-00aa003049e2"}`, "object types in the ACCESS_ALLOWED ACE"},
+		{"ObjectFlagsWithoutObjectType", `{"type":5,"mask":1,"sid":"S-1-1-0","objectFlags":1}`, "objectFlags 0x00000001 of the ACCESS_ALLOWED_OBJECT ACE do not match the presence of its objectType"},
+		{"InheritedObjectTypeWithoutFlag", `{"type":5,"mask":1,"sid":"S-1-1-0","objectFlags":0,"inheritedObjectType":"bf967aba-0de6-11d0-a285-00aa003049e2"}`, "do not match the presence of its inheritedObjectType"},
+		{"MissingCompound", `{"type":4,"mask":1,"sid":"S-1-1-0"}`, "the compound fields of the ACCESS_ALLOWED_COMPOUND ACE are required"},
+		{"InvalidSID", `{"type":0,"mask":1,"sid":"S-1-X"}`, "invalid JSON ACE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := &ace.AccessControlEntry{}
+			err := json.Unmarshal([]byte(tt.document), entry)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("json.Unmarshal() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+
+	entry := &ace.AccessControlEntry{}
+	if err := entry.UnmarshalBinary(append(make([]byte, 0), 0x00, 0x00, 0x08, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00)); err == nil {
+		t.Errorf("UnmarshalBinary() with a trailing byte succeeded, want an error")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"slices"
 
 	"github.com/TheManticoreProject/winacl/ace/aceflags"
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/rights"
 )
 
 // IsInherited checks whether the Access Control Entry (ACE) is inherited
@@ -60,6 +62,11 @@ func (ace *AccessControlEntry) Equal(other *AccessControlEntry) bool {
 		return false
 	}
 
+	// Compare the compound fields of ACCESS_ALLOWED_COMPOUND ACEs
+	if !ace.Compound.Equal(&other.Compound) {
+		return false
+	}
+
 	// Compare ApplicationData (conditional expression / attribute data carried by
 	// callback, resource-attribute and scoped-policy ACE types). bytes.Equal
 	// treats nil and empty slices as equal, which is the desired behavior here.
@@ -69,3 +76,123 @@ func (ace *AccessControlEntry) Equal(other *AccessControlEntry) bool {
 
 	return true
 }
+
+// SetRightsNamespace sets the rights namespace used to name the bits of the ACE's Mask, e.g.
+// rights.RIGHTS_NAMESPACE_FILE for an ACE of a file's DACL. Mandatory label ACEs are left
+// unchanged, since their mask holds the label policy rather than access rights.
+//
+// Parameters:
+// - namespace: The rights namespace of the object the ACE applies to.
+func (ace *AccessControlEntry) SetRightsNamespace(namespace rights.RightsNamespace) {
+	if ace.Header.Type.Value == acetype.ACE_TYPE_SYSTEM_MANDATORY_LABEL {
+		return
+	}
+	ace.Mask.SetNamespace(namespace)
+}
+
+// MapGenericRights rewrites the ACE's Mask into its specific-rights form, replacing the generic
+// rights with the rights they grant according to the generic mapping of the mask's namespace.
+// Mandatory label ACEs are left unchanged.
+func (ace *AccessControlEntry) MapGenericRights() {
+	if ace.Header.Type.Value == acetype.ACE_TYPE_SYSTEM_MANDATORY_LABEL {
+		return
+	}
+	ace.Mask.MapGenericRights(ace.Mask.Namespace.GenericMapping())
+}
+
+// IsObjectAce checks if the type of the ACE is an object-specific type, whose body holds
+// the object type fields. These types require an ACL of revision ACL_REVISION_DS.
+//
+// Returns:
+// - bool: true if the ACE type is an object-specific type, false otherwise.
+func (ace *AccessControlEntry) IsObjectAce() bool {
+	switch ace.Header.Type.Value {
+	case acetype.ACE_TYPE_ACCESS_ALLOWED_OBJECT,
+		acetype.ACE_TYPE_ACCESS_DENIED_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_AUDIT_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_ALARM_OBJECT,
+		acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK_OBJECT:
+		return true
+	}
+	return false
+}
+
+// IsAuditOrAlarm checks if the type of the ACE is one of the audit or alarm types, which
+// belong in a SACL and use the SUCCESSFUL_ACCESS and FAILED_ACCESS flags.
+//
+// Returns:
+// - bool: true if the ACE type is an audit or alarm type, false otherwise.
+func (ace *AccessControlEntry) IsAuditOrAlarm() bool {
+	switch ace.Header.Type.Value {
+	case acetype.ACE_TYPE_SYSTEM_AUDIT,
+		acetype.ACE_TYPE_SYSTEM_ALARM,
+		acetype.ACE_TYPE_SYSTEM_AUDIT_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_ALARM_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK,
+		acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK,
+		acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK_OBJECT:
+		return true
+	}
+	return false
+}
+
+// IsAccessAllowedOrDenied checks if the type of the ACE is one of the access allowed or
+// access denied types, which belong in a DACL.
+//
+// Returns:
+// - bool: true if the ACE type grants or denies access, false otherwise.
+func (ace *AccessControlEntry) IsAccessAllowedOrDenied() bool {
+	switch ace.Header.Type.Value {
+	case acetype.ACE_TYPE_ACCESS_ALLOWED,
+		acetype.ACE_TYPE_ACCESS_DENIED,
+		acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND,
+		acetype.ACE_TYPE_ACCESS_ALLOWED_OBJECT,
+		acetype.ACE_TYPE_ACCESS_DENIED_OBJECT,
+		acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK,
+		acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK,
+		acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK_OBJECT:
+		return true
+	}
+	return false
+}
+
+// IsAccessDenied checks if the type of the ACE is one of the access denied types.
+//
+// Returns:
+// - bool: true if the ACE type denies access, false otherwise.
+func (ace *AccessControlEntry) IsAccessDenied() bool {
+	switch ace.Header.Type.Value {
+	case acetype.ACE_TYPE_ACCESS_DENIED,
+		acetype.ACE_TYPE_ACCESS_DENIED_OBJECT,
+		acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK,
+		acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK_OBJECT:
+		return true
+	}
+	return false
+}
+
+// Clone returns a deep copy of the ACE, sharing no memory with the original: the fields, the
+// ApplicationData and the raw bytes are copied, so that the copy can be modified or outlive
+// the buffer the ACE was parsed from.
+//
+// Returns:
+//   - *AccessControlEntry: The copy of the ACE, nil if the ACE is nil.
+func (ace *AccessControlEntry) Clone() *AccessControlEntry {
+	if ace == nil {
+		return nil
+	}
+	clone := *ace
+	clone.Header = *ace.Header.Clone()
+	clone.Mask = *ace.Mask.Clone()
+	clone.Identity = *ace.Identity.Clone()
+	clone.AccessControlObjectType = *ace.AccessControlObjectType.Clone()
+	clone.Compound = *ace.Compound.Clone()
+	clone.ApplicationData = bytes.Clone(ace.ApplicationData)
+	clone.RawBytes = bytes.Clone(ace.RawBytes)
+	return &clone
+}
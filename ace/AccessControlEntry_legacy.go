@@ -0,0 +1,177 @@
+package ace
+
+import (
+	"fmt"
+
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/ace/compound"
+	"github.com/TheManticoreProject/winacl/ace/mask"
+	"github.com/TheManticoreProject/winacl/identity"
+	"github.com/TheManticoreProject/winacl/object"
+)
+
+// IsLegacy checks if the type of the ACE is one of the types marked as reserved by MS-DTYP,
+// i.e. ACCESS_ALLOWED_COMPOUND or one of the four SYSTEM_ALARM types. These ACEs are not
+// produced by current versions of Windows but can still be found in descriptors of legacy
+// systems.
+//
+// Returns:
+//   - bool: true if the ACE type is a compound or alarm type, false otherwise.
+func (ace *AccessControlEntry) IsLegacy() bool {
+	switch ace.Header.Type.Value {
+	case acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND,
+		acetype.ACE_TYPE_SYSTEM_ALARM,
+		acetype.ACE_TYPE_SYSTEM_ALARM_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK,
+		acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK_OBJECT:
+		return true
+	}
+	return false
+}
+
+// unmarshalLegacyBody parses the body of a compound or alarm ACE. Compound ACEs contain the
+// mask, the compound fields and the client SID. Alarm ACEs share the layout of the audit ACE
+// of the same kind: the mask, the object type fields for the object variants, and the SID.
+// If the body is malformed, the parsed fields are reset and the ACE is marked as Opaque so
+// that the whole body ends up in ApplicationData and is re-emitted verbatim by Marshal.
+//
+// Parameters:
+//   - marshalledData ([]byte): The body of the ACE, following its header.
+//
+// Returns:
+//   - int: The number of bytes parsed, 0 if the body was kept opaque.
+func (ace *AccessControlEntry) unmarshalLegacyBody(marshalledData []byte) int {
+	rawBytesSize, err := ace.tryUnmarshalLegacyBody(marshalledData)
+	if err != nil {
+		ace.Mask = mask.AccessControlMask{}
+		ace.Identity = identity.Identity{}
+		ace.AccessControlObjectType = object.AccessControlObjectType{}
+		ace.Compound = compound.AccessControlEntryCompound{}
+		ace.Opaque = true
+		return 0
+	}
+	return rawBytesSize
+}
+
+// tryUnmarshalLegacyBody parses the body of a compound or alarm ACE, see unmarshalLegacyBody.
+func (ace *AccessControlEntry) tryUnmarshalLegacyBody(marshalledData []byte) (int, error) {
+	parsedSize := 0
+
+	// Mask (4 bytes): An ACCESS_MASK that specifies the user rights of this ACE.
+	rawBytesSize, err := ace.Mask.Unmarshal(marshalledData)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unmarshal Mask: %w", err)
+	}
+	marshalledData = marshalledData[rawBytesSize:]
+	parsedSize += rawBytesSize
+
+	switch ace.Header.Type.Value {
+	case acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND:
+		// CompoundAceType (2 bytes), Reserved (2 bytes) and the SID of the server.
+		rawBytesSize, err = ace.Compound.Unmarshal(marshalledData)
+		if err != nil {
+			return 0, fmt.Errorf("failed to unmarshal Compound: %w", err)
+		}
+		marshalledData = marshalledData[rawBytesSize:]
+		parsedSize += rawBytesSize
+
+	case acetype.ACE_TYPE_SYSTEM_ALARM_OBJECT, acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK_OBJECT:
+		// Flags, ObjectType and InheritedObjectType, as in SYSTEM_AUDIT_OBJECT_ACE.
+		rawBytesSize, err = ace.AccessControlObjectType.Unmarshal(marshalledData)
+		if err != nil {
+			return 0, fmt.Errorf("failed to unmarshal AccessControlObjectType: %w", err)
+		}
+		marshalledData = marshalledData[rawBytesSize:]
+		parsedSize += rawBytesSize
+	}
+
+	// Sid (variable): The SID of the trustee, or of the client for compound ACEs.
+	rawBytesSize, err = ace.Identity.Unmarshal(marshalledData)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unmarshal Identity: %w", err)
+	}
+	parsedSize += rawBytesSize
+
+	return parsedSize, nil
+}
+
+// legacyBodySize returns the size of the body of a compound or alarm ACE, without
+// ApplicationData, see appendLegacyBody.
+//
+// Returns:
+//   - int: The size of the body, 0 for an Opaque ACE.
+func (ace *AccessControlEntry) legacyBodySize() int {
+	if ace.Opaque {
+		return 0
+	}
+
+	size := ace.Mask.Size() + ace.Identity.Size()
+	switch ace.Header.Type.Value {
+	case acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND:
+		size += ace.Compound.Size()
+	case acetype.ACE_TYPE_SYSTEM_ALARM_OBJECT, acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK_OBJECT:
+		size += ace.AccessControlObjectType.Size()
+	}
+	return size
+}
+
+// appendLegacyBody serializes the body of a compound or alarm ACE, see unmarshalLegacyBody.
+// The body of an Opaque ACE is entirely held by ApplicationData, so nothing is emitted here.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append the body to.
+//
+// Returns:
+//   - []byte: The extended buffer, without ApplicationData.
+//   - error: An error if one of the fields cannot be serialized.
+func (ace *AccessControlEntry) appendLegacyBody(buffer []byte) ([]byte, error) {
+	if ace.Opaque {
+		return buffer, nil
+	}
+
+	buffer, err := ace.Mask.AppendBinary(buffer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Mask: %w", err)
+	}
+
+	switch ace.Header.Type.Value {
+	case acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND:
+		buffer, err = ace.Compound.AppendBinary(buffer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal Compound: %w", err)
+		}
+
+	case acetype.ACE_TYPE_SYSTEM_ALARM_OBJECT, acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK_OBJECT:
+		buffer, err = ace.AccessControlObjectType.AppendBinary(buffer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal AccessControlObjectType: %w", err)
+		}
+	}
+
+	buffer, err = ace.Identity.AppendBinary(buffer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Identity: %w", err)
+	}
+
+	return buffer, nil
+}
+
+// describeLegacyBody prints the body of a compound or alarm ACE, see unmarshalLegacyBody.
+// Nothing is printed for an Opaque ACE, whose body is described as ApplicationData.
+//
+// Parameters:
+//   - indent (int): The indentation level for formatting the output.
+func (ace *AccessControlEntry) describeLegacyBody(indent int) {
+	if ace.Opaque {
+		return
+	}
+
+	ace.Mask.Describe(indent)
+	switch ace.Header.Type.Value {
+	case acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND:
+		ace.Compound.Describe(indent)
+	case acetype.ACE_TYPE_SYSTEM_ALARM_OBJECT, acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK_OBJECT:
+		ace.AccessControlObjectType.DescribeWithNames(indent, ace.InterpretObjectType().String(), ace.InterpretInheritedObjectType().String())
+	}
+	ace.Identity.Describe(indent)
+}
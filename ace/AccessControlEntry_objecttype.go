@@ -0,0 +1,203 @@
+package ace
+
+import (
+	"fmt"
+
+	"github.com/TheManticoreProject/winacl/rights"
+	"github.com/TheManticoreProject/winacl/schema"
+)
+
+// ObjectTypeKind is the meaning of the ObjectType GUID of an object ACE, which depends on the
+// access mask bits of the ACE.
+type ObjectTypeKind int
+
+const (
+	// OBJECT_TYPE_KIND_NONE is used when the ACE has no ObjectType.
+	OBJECT_TYPE_KIND_NONE ObjectTypeKind = iota
+	// OBJECT_TYPE_KIND_UNKNOWN is used when the GUID could not be resolved for the mask bits of the ACE.
+	OBJECT_TYPE_KIND_UNKNOWN
+	// OBJECT_TYPE_KIND_CLASS is a schema class, under DS_CREATE_CHILD or DS_DELETE_CHILD.
+	OBJECT_TYPE_KIND_CLASS
+	// OBJECT_TYPE_KIND_PROPERTY is a schema attribute, under DS_READ_PROPERTY or DS_WRITE_PROPERTY.
+	OBJECT_TYPE_KIND_PROPERTY
+	// OBJECT_TYPE_KIND_PROPERTY_SET is a property set, under DS_READ_PROPERTY or DS_WRITE_PROPERTY.
+	OBJECT_TYPE_KIND_PROPERTY_SET
+	// OBJECT_TYPE_KIND_EXTENDED_RIGHT is a control access right, under DS_CONTROL_ACCESS.
+	OBJECT_TYPE_KIND_EXTENDED_RIGHT
+	// OBJECT_TYPE_KIND_VALIDATED_WRITE is a validated write, under DS_WRITE_PROPERTY_EXTENDED (DS_SELF).
+	OBJECT_TYPE_KIND_VALIDATED_WRITE
+)
+
+// String returns the name of the ObjectTypeKind.
+//
+// Returns:
+//   - string: A human readable name of the kind, e.g. "Extended right".
+func (kind ObjectTypeKind) String() string {
+	switch kind {
+	case OBJECT_TYPE_KIND_NONE:
+		return "None"
+	case OBJECT_TYPE_KIND_CLASS:
+		return "Class"
+	case OBJECT_TYPE_KIND_PROPERTY:
+		return "Property"
+	case OBJECT_TYPE_KIND_PROPERTY_SET:
+		return "Property set"
+	case OBJECT_TYPE_KIND_EXTENDED_RIGHT:
+		return "Extended right"
+	case OBJECT_TYPE_KIND_VALIDATED_WRITE:
+		return "Validated write"
+	default:
+		return "Unknown"
+	}
+}
+
+// ObjectTypeInterpretation is the resolved meaning of an ObjectType GUID.
+//
+// Fields:
+//   - GUID: The GUID, in lowercase D format. Empty when Kind is OBJECT_TYPE_KIND_NONE.
+//   - Kind: What the GUID designates given the mask bits of the ACE.
+//   - Name: The lDAPDisplayName of the class or attribute, or the common name of the
+//     controlAccessRight. Empty when the GUID could not be resolved.
+//   - DisplayName: The displayName of the controlAccessRight, when known.
+type ObjectTypeInterpretation struct {
+	GUID        string
+	Kind        ObjectTypeKind
+	Name        string
+	DisplayName string
+}
+
+// String returns a one-line description of the interpretation, e.g.
+// "Extended right User-Force-Change-Password (Reset Password)".
+//
+// Returns:
+//   - string: The description of the interpretation.
+func (interpretation ObjectTypeInterpretation) String() string {
+	switch {
+	case interpretation.Kind == OBJECT_TYPE_KIND_NONE:
+		return "None"
+	case interpretation.Name == "":
+		return fmt.Sprintf("%s %s", interpretation.Kind, interpretation.GUID)
+	case interpretation.DisplayName != "" && interpretation.DisplayName != interpretation.Name:
+		return fmt.Sprintf("%s %s (%s)", interpretation.Kind, interpretation.Name, interpretation.DisplayName)
+	default:
+		return fmt.Sprintf("%s %s", interpretation.Kind, interpretation.Name)
+	}
+}
+
+// InterpretObjectType resolves the ObjectType GUID of the ACE according to its mask bits, using
+// the default schema registry. Under DS_CONTROL_ACCESS the GUID is an extended right, under
+// DS_WRITE_PROPERTY_EXTENDED a validated write, under DS_READ_PROPERTY/DS_WRITE_PROPERTY a property
+// or property set, and under DS_CREATE_CHILD/DS_DELETE_CHILD a class.
+//
+// Returns:
+//   - ObjectTypeInterpretation: The kind and name of the ObjectType.
+func (ace *AccessControlEntry) InterpretObjectType() ObjectTypeInterpretation {
+	return ace.InterpretObjectTypeInRegistry(schema.GetDefaultRegistry())
+}
+
+// InterpretObjectTypeInRegistry is InterpretObjectType resolving the GUID with a specific schema registry.
+//
+// Parameters:
+//   - registry (*schema.Registry): The schema registry used to resolve the GUID. nil means the default registry.
+//
+// Returns:
+//   - ObjectTypeInterpretation: The kind and name of the ObjectType.
+func (ace *AccessControlEntry) InterpretObjectTypeInRegistry(registry *schema.Registry) ObjectTypeInterpretation {
+	if !ace.AccessControlObjectType.Flags.IsObjectTypePresent() {
+		return ObjectTypeInterpretation{Kind: OBJECT_TYPE_KIND_NONE}
+	}
+	if registry == nil {
+		registry = schema.GetDefaultRegistry()
+	}
+
+	objectTypeGUID := ace.AccessControlObjectType.ObjectType.GUID.ToFormatD()
+	accessMask := rights.RIGHTS_NAMESPACE_DIRECTORY_SERVICE.MapGenericMask(ace.Mask.RawValue)
+
+	if accessMask&rights.RIGHT_DS_CONTROL_ACCESS != 0 {
+		if interpretation, ok := interpretExtendedRight(registry, objectTypeGUID, rights.RIGHT_DS_CONTROL_ACCESS); ok {
+			return interpretation
+		}
+	}
+	if accessMask&rights.RIGHT_DS_WRITE_PROPERTY_EXTENDED != 0 {
+		if interpretation, ok := interpretExtendedRight(registry, objectTypeGUID, rights.RIGHT_DS_WRITE_PROPERTY_EXTENDED); ok {
+			return interpretation
+		}
+	}
+	if propertyRights := accessMask & (rights.RIGHT_DS_READ_PROPERTY | rights.RIGHT_DS_WRITE_PROPERTY); propertyRights != 0 {
+		if interpretation, ok := interpretExtendedRight(registry, objectTypeGUID, propertyRights); ok {
+			return interpretation
+		}
+		if schemaAttribute, exists := registry.LookupAttribute(objectTypeGUID); exists {
+			name := schemaAttribute.LDAPDisplayName
+			if name == "" {
+				name = schemaAttribute.Name
+			}
+			return ObjectTypeInterpretation{GUID: objectTypeGUID, Kind: OBJECT_TYPE_KIND_PROPERTY, Name: name}
+		}
+	}
+	if accessMask&(rights.RIGHT_DS_CREATE_CHILD|rights.RIGHT_DS_DELETE_CHILD) != 0 {
+		if schemaClass, exists := registry.LookupClass(objectTypeGUID); exists {
+			return ObjectTypeInterpretation{GUID: objectTypeGUID, Kind: OBJECT_TYPE_KIND_CLASS, Name: schemaClass.LDAPDisplayName}
+		}
+	}
+
+	return ObjectTypeInterpretation{GUID: objectTypeGUID, Kind: OBJECT_TYPE_KIND_UNKNOWN}
+}
+
+// InterpretInheritedObjectType resolves the InheritedObjectType GUID of the ACE, which is always
+// the schemaIDGUID of the class of the child objects inheriting the ACE.
+//
+// Returns:
+//   - ObjectTypeInterpretation: The class, OBJECT_TYPE_KIND_NONE if the ACE has no
+//     InheritedObjectType, or OBJECT_TYPE_KIND_UNKNOWN if the class is unknown.
+func (ace *AccessControlEntry) InterpretInheritedObjectType() ObjectTypeInterpretation {
+	return ace.InterpretInheritedObjectTypeInRegistry(schema.GetDefaultRegistry())
+}
+
+// InterpretInheritedObjectTypeInRegistry is InterpretInheritedObjectType resolving the class with a
+// specific schema registry.
+//
+// Parameters:
+//   - registry (*schema.Registry): The schema registry used to resolve the class. nil means the default registry.
+//
+// Returns:
+//   - ObjectTypeInterpretation: The class, OBJECT_TYPE_KIND_NONE if the ACE has no
+//     InheritedObjectType, or OBJECT_TYPE_KIND_UNKNOWN if the class is unknown.
+func (ace *AccessControlEntry) InterpretInheritedObjectTypeInRegistry(registry *schema.Registry) ObjectTypeInterpretation {
+	if !ace.AccessControlObjectType.Flags.IsInheritedObjectTypePresent() {
+		return ObjectTypeInterpretation{Kind: OBJECT_TYPE_KIND_NONE}
+	}
+	if registry == nil {
+		registry = schema.GetDefaultRegistry()
+	}
+
+	inheritedObjectTypeGUID := ace.AccessControlObjectType.InheritedObjectType.GUID.ToFormatD()
+	if schemaClass, exists := registry.LookupClass(inheritedObjectTypeGUID); exists {
+		return ObjectTypeInterpretation{GUID: inheritedObjectTypeGUID, Kind: OBJECT_TYPE_KIND_CLASS, Name: schemaClass.LDAPDisplayName}
+	}
+	return ObjectTypeInterpretation{GUID: inheritedObjectTypeGUID, Kind: OBJECT_TYPE_KIND_UNKNOWN}
+}
+
+// interpretExtendedRight resolves a GUID as a controlAccessRight valid for the given access bits.
+func interpretExtendedRight(registry *schema.Registry, objectTypeGUID string, accessMask uint32) (ObjectTypeInterpretation, bool) {
+	extendedRight, exists := registry.LookupExtendedRightForAccess(objectTypeGUID, accessMask)
+	if !exists || !extendedRight.IsValidFor(accessMask) {
+		return ObjectTypeInterpretation{}, false
+	}
+
+	kind := OBJECT_TYPE_KIND_UNKNOWN
+	switch extendedRight.Kind() {
+	case rights.EXTENDED_RIGHT_KIND_CONTROL_ACCESS:
+		kind = OBJECT_TYPE_KIND_EXTENDED_RIGHT
+	case rights.EXTENDED_RIGHT_KIND_VALIDATED_WRITE:
+		kind = OBJECT_TYPE_KIND_VALIDATED_WRITE
+	case rights.EXTENDED_RIGHT_KIND_PROPERTY_SET:
+		kind = OBJECT_TYPE_KIND_PROPERTY_SET
+	}
+	return ObjectTypeInterpretation{
+		GUID:        objectTypeGUID,
+		Kind:        kind,
+		Name:        extendedRight.Name,
+		DisplayName: extendedRight.DisplayName,
+	}, true
+}
@@ -0,0 +1,108 @@
+package ace_test
+
+import (
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/guid"
+	"github.com/TheManticoreProject/winacl/object/flags"
+	"github.com/TheManticoreProject/winacl/rights"
+	"github.com/TheManticoreProject/winacl/schema"
+)
+
+// newObjectACE builds an object ACE with the given mask and ObjectType (and InheritedObjectType if not empty).
+func newObjectACE(t *testing.T, accessMask uint32, objectType, inheritedObjectType string) *ace.AccessControlEntry {
+	t.Helper()
+
+	entry := &ace.AccessControlEntry{}
+	entry.Mask.RawValue = accessMask
+
+	objectTypeGUID, err := guid.FromString(objectType)
+	if err != nil {
+		t.Fatalf("guid.FromString(%q) error = %v", objectType, err)
+	}
+	entry.AccessControlObjectType.Flags.Value = flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT
+	entry.AccessControlObjectType.ObjectType.GUID = *objectTypeGUID
+
+	if inheritedObjectType != "" {
+		inheritedObjectTypeGUID, err := guid.FromString(inheritedObjectType)
+		if err != nil {
+			t.Fatalf("guid.FromString(%q) error = %v", inheritedObjectType, err)
+		}
+		entry.AccessControlObjectType.Flags.Value |= flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT
+		entry.AccessControlObjectType.InheritedObjectType.GUID = *inheritedObjectTypeGUID
+	}
+	return entry
+}
+
+func TestAccessControlEntry_InterpretObjectType(t *testing.T) {
+	testCases := []struct {
+		name         string
+		accessMask   uint32
+		objectType   string
+		expectedKind ace.ObjectTypeKind
+		expectedName string
+	}{
+		{"Extended right", rights.RIGHT_DS_CONTROL_ACCESS, rights.EXTENDED_RIGHT_USER_FORCE_CHANGE_PASSWORD, ace.OBJECT_TYPE_KIND_EXTENDED_RIGHT, "User-Force-Change-Password"},
+		{"Validated write", rights.RIGHT_DS_WRITE_PROPERTY_EXTENDED, rights.VALIDATED_WRITE_SELF_MEMBERSHIP, ace.OBJECT_TYPE_KIND_VALIDATED_WRITE, "Self-Membership"},
+		{"Same GUID as a property", rights.RIGHT_DS_WRITE_PROPERTY, rights.VALIDATED_WRITE_SELF_MEMBERSHIP, ace.OBJECT_TYPE_KIND_PROPERTY, "member"},
+		{"Validated write sharing its GUID with a property set", rights.RIGHT_DS_WRITE_PROPERTY_EXTENDED, rights.VALIDATED_WRITE_DNS_HOST_NAME, ace.OBJECT_TYPE_KIND_VALIDATED_WRITE, "Validated-DNS-Host-Name"},
+		{"Property set sharing its GUID with a validated write", rights.RIGHT_DS_READ_PROPERTY, schema.PROPERTY_SET_DNS_HOST_NAME_ATTRIBUTES, ace.OBJECT_TYPE_KIND_PROPERTY_SET, "DNS-Host-Name-Attributes"},
+		{"Property", rights.RIGHT_DS_READ_PROPERTY | rights.RIGHT_DS_WRITE_PROPERTY, schema.SCHEMA_ATTRIBUTE_USER_ACCOUNT_CONTROL, ace.OBJECT_TYPE_KIND_PROPERTY, "userAccountControl"},
+		{"Class", rights.RIGHT_DS_CREATE_CHILD | rights.RIGHT_DS_DELETE_CHILD, schema.SCHEMA_CLASS_COMPUTER, ace.OBJECT_TYPE_KIND_CLASS, "computer"},
+		{"Generic write", rights.RIGHT_GENERIC_WRITE, rights.VALIDATED_WRITE_SPN, ace.OBJECT_TYPE_KIND_VALIDATED_WRITE, "Validated-SPN"},
+		{"Class under a property right", rights.RIGHT_DS_READ_PROPERTY, schema.SCHEMA_CLASS_PRINT_QUEUE, ace.OBJECT_TYPE_KIND_UNKNOWN, ""},
+		{"Extended right under a child right", rights.RIGHT_DS_CREATE_CHILD, rights.EXTENDED_RIGHT_SEND_AS, ace.OBJECT_TYPE_KIND_UNKNOWN, ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			interpretation := newObjectACE(t, testCase.accessMask, testCase.objectType, "").InterpretObjectType()
+			if interpretation.Kind != testCase.expectedKind || interpretation.Name != testCase.expectedName {
+				t.Errorf("InterpretObjectType() = %+v, want kind %s and name %q", interpretation, testCase.expectedKind, testCase.expectedName)
+			}
+			if interpretation.GUID != testCase.objectType {
+				t.Errorf("InterpretObjectType().GUID = %q, want %q", interpretation.GUID, testCase.objectType)
+			}
+		})
+	}
+}
+
+func TestAccessControlEntry_InterpretObjectType_None(t *testing.T) {
+	entry := &ace.AccessControlEntry{}
+	entry.Mask.RawValue = rights.RIGHT_DS_CONTROL_ACCESS
+	if interpretation := entry.InterpretObjectType(); interpretation.Kind != ace.OBJECT_TYPE_KIND_NONE {
+		t.Errorf("InterpretObjectType() = %+v, want OBJECT_TYPE_KIND_NONE", interpretation)
+	}
+	if interpretation := entry.InterpretInheritedObjectType(); interpretation.Kind != ace.OBJECT_TYPE_KIND_NONE {
+		t.Errorf("InterpretInheritedObjectType() = %+v, want OBJECT_TYPE_KIND_NONE", interpretation)
+	}
+}
+
+func TestAccessControlEntry_InterpretInheritedObjectType(t *testing.T) {
+	entry := newObjectACE(t, rights.RIGHT_DS_READ_PROPERTY, schema.PROPERTY_SET_PERSONAL_INFORMATION, schema.SCHEMA_CLASS_INET_ORG_PERSON)
+
+	interpretation := entry.InterpretInheritedObjectType()
+	if interpretation.Kind != ace.OBJECT_TYPE_KIND_CLASS || interpretation.Name != "inetOrgPerson" {
+		t.Errorf("InterpretInheritedObjectType() = %+v", interpretation)
+	}
+	if str := entry.InterpretObjectType().String(); str != "Property set Personal-Information (Personal Information)" {
+		t.Errorf("InterpretObjectType().String() = %q", str)
+	}
+}
+
+func TestAccessControlEntry_InterpretObjectTypeInRegistry(t *testing.T) {
+	registry := schema.NewRegistry()
+	customRight := "0a1b2c3d-4e5f-6071-8293-a4b5c6d7e8f9"
+	if err := registry.AddExtendedRight(rights.ExtendedRight{Name: "Contoso-Approve", DisplayName: "Approve", RightsGUID: customRight, ValidAccesses: rights.RIGHT_DS_CONTROL_ACCESS}); err != nil {
+		t.Fatalf("AddExtendedRight() error = %v", err)
+	}
+
+	entry := newObjectACE(t, rights.RIGHT_DS_CONTROL_ACCESS, customRight, "")
+	if interpretation := entry.InterpretObjectTypeInRegistry(registry); interpretation.Kind != ace.OBJECT_TYPE_KIND_EXTENDED_RIGHT || interpretation.Name != "Contoso-Approve" {
+		t.Errorf("InterpretObjectTypeInRegistry() = %+v", interpretation)
+	}
+	if interpretation := entry.InterpretObjectType(); interpretation.Kind != ace.OBJECT_TYPE_KIND_UNKNOWN {
+		t.Errorf("InterpretObjectType() = %+v, want OBJECT_TYPE_KIND_UNKNOWN with the default registry", interpretation)
+	}
+}
@@ -0,0 +1,87 @@
+package ace
+
+import (
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/ace/compound"
+	"github.com/TheManticoreProject/winacl/ace/mask"
+	"github.com/TheManticoreProject/winacl/identity"
+	"github.com/TheManticoreProject/winacl/object"
+	"github.com/TheManticoreProject/winacl/parsing"
+)
+
+// UnmarshalWithContext parses the ACE like Unmarshal, following the options of the parse
+// context. In lenient mode, an ACE whose header is valid but whose body cannot be parsed is
+// kept as an Opaque entry holding its whole body in ApplicationData, and the recovery is
+// recorded as an anomaly of the context. Trailing bytes after the fields of a fixed layout
+// ACE are recorded as anomalies in both modes.
+//
+// Parameters:
+//   - marshalledData ([]byte): The raw byte slice to be parsed.
+//   - ctx (*parsing.Context): The parse context, located at the start of the ACE.
+//
+// Returns:
+//   - int: The size of the ACE, taken from its header.
+//   - error: An error if the ACE cannot be parsed, or in lenient mode if its header is
+//     truncated or its size does not fit in the data, since the ACE boundaries are unknown.
+func (ace *AccessControlEntry) UnmarshalWithContext(marshalledData []byte, ctx *parsing.Context) (int, error) {
+	marshalledData = ctx.Detach(marshalledData)
+
+	rawBytesSize, err := ace.Unmarshal(marshalledData)
+	if err != nil {
+		if !ctx.Lenient() {
+			return 0, err
+		}
+		if _, headerErr := ace.Header.Unmarshal(marshalledData); headerErr != nil {
+			return 0, err
+		}
+		if ace.Header.Size < 4 || int(ace.Header.Size) > len(marshalledData) {
+			return 0, err
+		}
+
+		ace.setOpaqueBody(marshalledData[:ace.Header.Size])
+		ctx.Record(4, parsing.ANOMALY_CODE_ACE_BODY_OPAQUE, "body of the %s ACE kept opaque: %s", ace.Header.Type.String(), err.Error())
+
+		return int(ace.Header.Size), nil
+	}
+
+	if len(ace.ApplicationData) > 0 && ace.hasFixedLayout() {
+		ctx.Record(int(ace.RawBytesSize), parsing.ANOMALY_CODE_ACE_TRAILING_SLACK, "%d bytes of slack after the fields of the %s ACE", len(ace.ApplicationData), ace.Header.Type.String())
+	}
+
+	return rawBytesSize, nil
+}
+
+// setOpaqueBody resets the parsed fields of the ACE and keeps its whole body in
+// ApplicationData.
+//
+// Parameters:
+//   - rawBytes ([]byte): The raw bytes of the ACE, including its header.
+func (ace *AccessControlEntry) setOpaqueBody(rawBytes []byte) {
+	ace.Mask = mask.AccessControlMask{}
+	ace.Identity = identity.Identity{}
+	ace.AccessControlObjectType = object.AccessControlObjectType{}
+	ace.Compound = compound.AccessControlEntryCompound{}
+	ace.Opaque = true
+
+	ace.RawBytes = rawBytes
+	ace.RawBytesSize = 4
+	ace.ApplicationData = rawBytes[4:]
+}
+
+// hasFixedLayout checks if the ACE body ends after its fields, i.e. if the ACE is decoded
+// and its type does not carry a conditional expression, attribute data or policy data in its
+// ApplicationData.
+//
+// Returns:
+//   - bool: true if any trailing byte of the ACE is slack, false otherwise.
+func (ace *AccessControlEntry) hasFixedLayout() bool {
+	if ace.Opaque || ace.IsConditional() {
+		return false
+	}
+	switch ace.Header.Type.Value {
+	case acetype.ACE_TYPE_SYSTEM_RESOURCE_ATTRIBUTE, acetype.ACE_TYPE_SYSTEM_SCOPED_POLICY_ID:
+		return false
+	}
+	_, known := acetype.AccessControlEntryTypeValueToName[ace.Header.Type.Value]
+	return known
+}
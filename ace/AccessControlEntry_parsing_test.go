@@ -0,0 +1,69 @@
+package ace_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/parsing"
+)
+
+func TestAccessControlEntry_UnmarshalWithContext_Lenient(t *testing.T) {
+	// ACCESS_ALLOWED ACE whose SID claims 5 sub-authorities but only holds one
+	rawBytes, _ := hex.DecodeString("00001400ff010f000105000000000005" + "15000000")
+
+	strict := ace.AccessControlEntry{}
+	if _, err := strict.UnmarshalWithContext(rawBytes, parsing.NewContext(parsing.Options{})); err == nil {
+		t.Fatalf("UnmarshalWithContext() expected an error in strict mode")
+	}
+
+	ctx := parsing.NewContext(parsing.Options{Lenient: true})
+	entry := ace.AccessControlEntry{}
+	rawBytesSize, err := entry.UnmarshalWithContext(rawBytes, ctx.Child(0x20, "Entries[1]"))
+	if err != nil {
+		t.Fatalf("UnmarshalWithContext() error = %v", err)
+	}
+	if rawBytesSize != len(rawBytes) || !entry.Opaque {
+		t.Errorf("UnmarshalWithContext() = %d, Opaque = %v, want %d, true", rawBytesSize, entry.Opaque, len(rawBytes))
+	}
+
+	anomalies := ctx.Anomalies()
+	if len(anomalies) != 1 || anomalies[0].Code != parsing.ANOMALY_CODE_ACE_BODY_OPAQUE || anomalies[0].Offset != 0x24 || anomalies[0].Path != "Entries[1]" {
+		t.Errorf("Anomalies() = %v", anomalies)
+	}
+
+	serializedBytes, err := entry.Marshal()
+	if err != nil || !bytes.Equal(serializedBytes, rawBytes) {
+		t.Errorf("Marshal() = %x, %v, want %x", serializedBytes, err, rawBytes)
+	}
+
+	// The boundaries of an ACE whose size does not fit in the data are unknown
+	if _, err := entry.UnmarshalWithContext(rawBytes[:12], ctx); err == nil {
+		t.Errorf("UnmarshalWithContext() expected an error for a truncated ACE")
+	}
+}
+
+func TestAccessControlEntry_UnmarshalWithContext_TrailingSlack(t *testing.T) {
+	rawBytes, _ := hex.DecodeString("00002800ff010f00" + typedTestSID + "00000000")
+
+	ctx := parsing.NewContext(parsing.Options{})
+	entry := ace.AccessControlEntry{}
+	if _, err := entry.UnmarshalWithContext(rawBytes, ctx); err != nil {
+		t.Fatalf("UnmarshalWithContext() error = %v", err)
+	}
+	anomalies := ctx.Anomalies()
+	if len(anomalies) != 1 || anomalies[0].Code != parsing.ANOMALY_CODE_ACE_TRAILING_SLACK || anomalies[0].Offset != 0x24 {
+		t.Errorf("Anomalies() = %v", anomalies)
+	}
+
+	// The ApplicationData of callback ACEs is not slack
+	rawBytes, _ = hex.DecodeString("09003000ff010f00" + typedTestSID + typedTestApp)
+	ctx = parsing.NewContext(parsing.Options{})
+	if _, err := entry.UnmarshalWithContext(rawBytes, ctx); err != nil {
+		t.Fatalf("UnmarshalWithContext() error = %v", err)
+	}
+	if anomalies := ctx.Anomalies(); len(anomalies) != 0 {
+		t.Errorf("Anomalies() = %v, want none", anomalies)
+	}
+}
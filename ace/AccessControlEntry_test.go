@@ -223,3 +223,139 @@ func TestAccessControlEntry_Involution_CallbackObjectAndAuditCallback(t *testing
 		})
 	}
 }
+
+// TestAccessControlEntry_Involution_TrustLabelAndAccessFilter verifies that the
+// SYSTEM_PROCESS_TRUST_LABEL (0x14) and SYSTEM_ACCESS_FILTER (0x15) ACE types are
+// parsed and survive a Marshal(Unmarshal(x)) round-trip.
+func TestAccessControlEntry_Involution_TrustLabelAndAccessFilter(t *testing.T) {
+	const (
+		// S-1-19-512-8192 (ProtectedLight-WinTcb)
+		trustSID = "01020000000000130002000000200000"
+		// S-1-1-0 (Everyone)
+		everyoneSID = "010100000000000100000000"
+		// (@User.Title == "PM")
+		condition = "61727478f90a0000005400690074006c006500100400000050004d0080000000"
+	)
+
+	cases := []struct {
+		name      string
+		hex       string
+		sid       string
+		condition string
+	}{
+		// SYSTEM_PROCESS_TRUST_LABEL: header + mask + sid. Size = 4 + 4 + 16 = 24 = 0x18.
+		{"system_process_trust_label", "14001800" + "01000200" + trustSID, "S-1-19-512-8192", ""},
+		// SYSTEM_ACCESS_FILTER: header + mask + sid + condition. Size = 4 + 4 + 12 + 32 = 52 = 0x34.
+		{"system_access_filter", "15003400" + "01000000" + everyoneSID + condition, "S-1-1-0", `(@User.Title == "PM")`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rawBytes, err := hex.DecodeString(tc.hex)
+			if err != nil {
+				t.Fatalf("Failed to decode hex string: %v", err)
+			}
+
+			var ace AccessControlEntry
+			if _, err = ace.Unmarshal(rawBytes); err != nil {
+				t.Fatalf("Failed to unmarshal AccessControlEntry: %v", err)
+			}
+			if ace.Identity.SID.ToString() != tc.sid {
+				t.Errorf("Identity.SID = %s, want %s", ace.Identity.SID.ToString(), tc.sid)
+			}
+
+			expression, err := ace.Condition()
+			if err != nil {
+				t.Fatalf("Condition() error = %v", err)
+			}
+			if tc.condition == "" && expression != nil {
+				t.Errorf("Condition() = %v, want nil", expression)
+			}
+			if tc.condition != "" {
+				if expression == nil {
+					t.Fatalf("Condition() = nil, want %s", tc.condition)
+				}
+				if got, _ := expression.ToSDDLString(); got != tc.condition {
+					t.Errorf("Condition() = %s, want %s", got, tc.condition)
+				}
+			}
+
+			serializedBytes, err := ace.Marshal()
+			if err != nil {
+				t.Fatalf("Failed to marshal AccessControlEntry: %v", err)
+			}
+			if !bytes.Equal(rawBytes, serializedBytes) {
+				t.Errorf("Involution test failed: expected %s, got %s",
+					hex.EncodeToString(rawBytes), hex.EncodeToString(serializedBytes))
+			}
+		})
+	}
+}
+
+// TestAccessControlEntry_Involution_CompoundAndAlarm verifies that the
+// ACCESS_ALLOWED_COMPOUND (0x04) and SYSTEM_ALARM ACE types are parsed and
+// survive a Marshal(Unmarshal(x)) round-trip, and that malformed bodies are
+// kept opaque instead of being dropped.
+func TestAccessControlEntry_Involution_CompoundAndAlarm(t *testing.T) {
+	const (
+		sid28       = "01050000000000051500000028bb82279261b9fe2474aa5d00020000"
+		everyoneSID = "010100000000000100000000"
+		appData     = "61727478deadbeefcafe0000"
+	)
+
+	cases := []struct {
+		name   string
+		hex    string
+		sid    string
+		opaque bool
+	}{
+		// ACCESS_ALLOWED_COMPOUND: header + mask + type(2) + reserved(2) + server sid + client sid.
+		// Size = 4 + 4 + 4 + 12 + 28 = 52 = 0x34.
+		{"access_allowed_compound", "04003400ff010f00" + "01000000" + everyoneSID + sid28, "S-1-5-21-662879016-4273562002-1571451940-512", false},
+		// SYSTEM_ALARM: header + mask + sid. Size = 4 + 4 + 28 = 36 = 0x24.
+		{"system_alarm", "03002400ff010f00" + sid28, "S-1-5-21-662879016-4273562002-1571451940-512", false},
+		// SYSTEM_ALARM_OBJECT with object-type Flags = 0. Size = 4 + 4 + 4 + 28 = 40 = 0x28.
+		{"system_alarm_object", "08002800ff010f0000000000" + sid28, "S-1-5-21-662879016-4273562002-1571451940-512", false},
+		// SYSTEM_ALARM_CALLBACK: header + mask + sid + ApplicationData. Size = 4 + 4 + 28 + 12 = 48 = 0x30.
+		{"system_alarm_callback", "0e003000ff010f00" + sid28 + appData, "S-1-5-21-662879016-4273562002-1571451940-512", false},
+		// SYSTEM_ALARM_CALLBACK_OBJECT. Size = 4 + 4 + 4 + 28 + 12 = 52 = 0x34.
+		{"system_alarm_callback_object", "10003400ff010f0000000000" + sid28 + appData, "S-1-5-21-662879016-4273562002-1571451940-512", false},
+		// SYSTEM_ALARM with a mask but no SID.
+		{"system_alarm_malformed", "03000800deadbeef", "", true},
+		// ACCESS_ALLOWED_COMPOUND with a truncated server SID.
+		{"access_allowed_compound_malformed", "04000e00ff010f00010000000101", "", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rawBytes, err := hex.DecodeString(tc.hex)
+			if err != nil {
+				t.Fatalf("Failed to decode hex string: %v", err)
+			}
+
+			var ace AccessControlEntry
+			if _, err = ace.Unmarshal(rawBytes); err != nil {
+				t.Fatalf("Failed to unmarshal AccessControlEntry: %v", err)
+			}
+			if ace.Opaque != tc.opaque {
+				t.Fatalf("Opaque = %v, want %v", ace.Opaque, tc.opaque)
+			}
+			if tc.opaque {
+				if !bytes.Equal(ace.ApplicationData, rawBytes[4:]) {
+					t.Errorf("ApplicationData = %x, want the whole body %x", ace.ApplicationData, rawBytes[4:])
+				}
+			} else if ace.Identity.SID.ToString() != tc.sid {
+				t.Errorf("Identity.SID = %s, want %s", ace.Identity.SID.ToString(), tc.sid)
+			}
+
+			serializedBytes, err := ace.Marshal()
+			if err != nil {
+				t.Fatalf("Failed to marshal AccessControlEntry: %v", err)
+			}
+			if !bytes.Equal(rawBytes, serializedBytes) {
+				t.Errorf("Involution test failed: expected %s, got %s",
+					hex.EncodeToString(rawBytes), hex.EncodeToString(serializedBytes))
+			}
+		})
+	}
+}
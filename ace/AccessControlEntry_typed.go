@@ -0,0 +1,181 @@
+package ace
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+)
+
+// TypedAccessControlEntry is implemented by the typed views of the ACEs. Each concrete type
+// only holds the fields of its ACE structure, so that callers can use type switches instead
+// of inspecting Header.Type, and invalid field combinations (such as an object GUID on an
+// ACCESS_ALLOWED ACE) cannot be represented.
+//
+// The typed views are a thin accessor layer over the AccessControlEntry struct, which does the
+// parsing, the serialization and the description of every ACE type. They are created by
+// AccessControlEntry.Typed() and UnmarshalTypedAccessControlEntry(), and converted back by
+// ToAccessControlEntry() to be marshalled or added to an ACL.
+type TypedAccessControlEntry interface {
+	// AceType returns the type of the ACE, one of the acetype.ACE_TYPE_* values.
+	AceType() uint8
+
+	// ToAccessControlEntry converts the typed view into an AccessControlEntry.
+	ToAccessControlEntry() (*AccessControlEntry, error)
+}
+
+// TypedAccessControlEntryFactory creates the typed view of an ACE of a custom type. The ACE
+// is Opaque, its body following the header is held by its ApplicationData.
+type TypedAccessControlEntryFactory func(ace *AccessControlEntry) (TypedAccessControlEntry, error)
+
+var (
+	customAccessControlEntryTypesLock sync.RWMutex
+	customAccessControlEntryTypes     = map[uint8]TypedAccessControlEntryFactory{}
+)
+
+// RegisterAccessControlEntryType registers a custom ACE type. Once registered, ACEs of this
+// type are parsed by AccessControlEntry.Unmarshal as Opaque entries instead of being rejected,
+// and their typed view is created from the Opaque entry by the factory.
+//
+// Parameters:
+//   - aceType (uint8): The value of the custom ACE type.
+//   - factory (TypedAccessControlEntryFactory): The function creating the typed views of this type.
+//
+// Returns:
+//   - error: An error if the type is one of the types defined by Windows or the factory is nil.
+func RegisterAccessControlEntryType(aceType uint8, factory TypedAccessControlEntryFactory) error {
+	if _, defined := acetype.AccessControlEntryTypeValueToName[aceType]; defined {
+		return fmt.Errorf("cannot register ACE type 0x%02x: it is already defined as %s", aceType, acetype.AccessControlEntryTypeValueToName[aceType])
+	}
+	if factory == nil {
+		return fmt.Errorf("cannot register ACE type 0x%02x: factory is nil", aceType)
+	}
+
+	customAccessControlEntryTypesLock.Lock()
+	defer customAccessControlEntryTypesLock.Unlock()
+	customAccessControlEntryTypes[aceType] = factory
+
+	return nil
+}
+
+// UnregisterAccessControlEntryType removes a custom ACE type registered by
+// RegisterAccessControlEntryType.
+//
+// Parameters:
+//   - aceType (uint8): The value of the custom ACE type.
+func UnregisterAccessControlEntryType(aceType uint8) {
+	customAccessControlEntryTypesLock.Lock()
+	defer customAccessControlEntryTypesLock.Unlock()
+	delete(customAccessControlEntryTypes, aceType)
+}
+
+// LookupAccessControlEntryType returns the factory of a custom ACE type.
+//
+// Parameters:
+//   - aceType (uint8): The value of the custom ACE type.
+//
+// Returns:
+//   - TypedAccessControlEntryFactory: The factory of the type, or nil if it is not registered.
+//   - bool: true if the type is registered, false otherwise.
+func LookupAccessControlEntryType(aceType uint8) (TypedAccessControlEntryFactory, bool) {
+	customAccessControlEntryTypesLock.RLock()
+	defer customAccessControlEntryTypesLock.RUnlock()
+	factory, registered := customAccessControlEntryTypes[aceType]
+	return factory, registered
+}
+
+// UnmarshalTypedAccessControlEntry parses an ACE from the raw bytes into its typed view.
+//
+// Parameters:
+//   - marshalledData ([]byte): The raw bytes of the ACE, header included.
+//
+// Returns:
+//   - TypedAccessControlEntry: The typed view of the ACE.
+//   - int: The number of bytes read.
+//   - error: An error if the ACE cannot be parsed.
+func UnmarshalTypedAccessControlEntry(marshalledData []byte) (TypedAccessControlEntry, int, error) {
+	entry := AccessControlEntry{}
+	rawBytesSize, err := entry.Unmarshal(marshalledData)
+	if err != nil {
+		return nil, 0, err
+	}
+	typed, err := entry.Typed()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return typed, rawBytesSize, nil
+}
+
+// Typed converts the AccessControlEntry into the typed view matching its type.
+// Compound and alarm ACEs kept Opaque are converted into an OpaqueAce.
+//
+// Returns:
+//   - TypedAccessControlEntry: The typed view of the ACE.
+//   - error: An error if the type is unknown, or if an ACE of a fixed layout type carries
+//     trailing data that is not zero padding, as its typed view cannot hold it.
+func (ace *AccessControlEntry) Typed() (TypedAccessControlEntry, error) {
+	if factory, registered := LookupAccessControlEntryType(ace.Header.Type.Value); registered {
+		return factory(ace)
+	}
+
+	if ace.Opaque {
+		return &OpaqueAce{Header: ace.Header, Data: append([]byte{}, ace.ApplicationData...)}, nil
+	}
+
+	switch ace.Header.Type.Value {
+	case acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK,
+		acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK,
+		acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK,
+		acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK:
+		return &CallbackAce{Header: ace.Header, Mask: ace.Mask, Identity: ace.Identity, ApplicationData: append([]byte{}, ace.ApplicationData...)}, nil
+
+	case acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK_OBJECT:
+		return &CallbackObjectAce{Header: ace.Header, Mask: ace.Mask, ObjectType: ace.AccessControlObjectType, Identity: ace.Identity, ApplicationData: append([]byte{}, ace.ApplicationData...)}, nil
+
+	case acetype.ACE_TYPE_SYSTEM_RESOURCE_ATTRIBUTE:
+		return &ResourceAttributeAce{Header: ace.Header, Mask: ace.Mask, Identity: ace.Identity, AttributeData: append([]byte{}, ace.ApplicationData...)}, nil
+
+	case acetype.ACE_TYPE_SYSTEM_ACCESS_FILTER:
+		return &AccessFilterAce{Header: ace.Header, Mask: ace.Mask, Identity: ace.Identity, ApplicationData: append([]byte{}, ace.ApplicationData...)}, nil
+	}
+
+	// The remaining types have a fixed layout, anything after their fields must be padding
+	for _, b := range ace.ApplicationData {
+		if b != 0 {
+			return nil, fmt.Errorf("ACE #%d of type %s carries %d bytes of trailing data that its typed view cannot hold", ace.Index, ace.Header.Type.String(), len(ace.ApplicationData))
+		}
+	}
+
+	switch ace.Header.Type.Value {
+	case acetype.ACE_TYPE_ACCESS_ALLOWED:
+		return &AccessAllowedAce{Header: ace.Header, Mask: ace.Mask, Identity: ace.Identity}, nil
+	case acetype.ACE_TYPE_ACCESS_DENIED:
+		return &AccessDeniedAce{Header: ace.Header, Mask: ace.Mask, Identity: ace.Identity}, nil
+	case acetype.ACE_TYPE_SYSTEM_AUDIT:
+		return &SystemAuditAce{Header: ace.Header, Mask: ace.Mask, Identity: ace.Identity}, nil
+	case acetype.ACE_TYPE_SYSTEM_ALARM:
+		return &SystemAlarmAce{Header: ace.Header, Mask: ace.Mask, Identity: ace.Identity}, nil
+	case acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND:
+		return &CompoundAce{Header: ace.Header, Mask: ace.Mask, Compound: ace.Compound, Identity: ace.Identity}, nil
+	case acetype.ACE_TYPE_ACCESS_ALLOWED_OBJECT:
+		return &AccessAllowedObjectAce{Header: ace.Header, Mask: ace.Mask, ObjectType: ace.AccessControlObjectType, Identity: ace.Identity}, nil
+	case acetype.ACE_TYPE_ACCESS_DENIED_OBJECT:
+		return &AccessDeniedObjectAce{Header: ace.Header, Mask: ace.Mask, ObjectType: ace.AccessControlObjectType, Identity: ace.Identity}, nil
+	case acetype.ACE_TYPE_SYSTEM_AUDIT_OBJECT:
+		return &SystemAuditObjectAce{Header: ace.Header, Mask: ace.Mask, ObjectType: ace.AccessControlObjectType, Identity: ace.Identity}, nil
+	case acetype.ACE_TYPE_SYSTEM_ALARM_OBJECT:
+		return &SystemAlarmObjectAce{Header: ace.Header, Mask: ace.Mask, ObjectType: ace.AccessControlObjectType, Identity: ace.Identity}, nil
+	case acetype.ACE_TYPE_SYSTEM_MANDATORY_LABEL:
+		return &MandatoryLabelAce{Header: ace.Header, Mask: ace.Mask, Identity: ace.Identity}, nil
+	case acetype.ACE_TYPE_SYSTEM_SCOPED_POLICY_ID:
+		return &ScopedPolicyIdAce{Header: ace.Header, Mask: ace.Mask, Identity: ace.Identity}, nil
+	case acetype.ACE_TYPE_SYSTEM_PROCESS_TRUST_LABEL:
+		return &ProcessTrustLabelAce{Header: ace.Header, Mask: ace.Mask, Identity: ace.Identity}, nil
+	}
+
+	return nil, fmt.Errorf("unknown ACE type: %d", ace.Header.Type.Value)
+}
@@ -0,0 +1,237 @@
+package ace_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/ace/header"
+)
+
+const (
+	typedTestSID = "01050000000000051500000028bb82279261b9fe2474aa5d00020000"
+	typedTestApp = "61727478deadbeefcafe0000"
+)
+
+func TestUnmarshalTypedAccessControlEntry(t *testing.T) {
+	tests := []struct {
+		name     string
+		hex      string
+		expected string
+	}{
+		{"access_allowed", "00002400ff010f00" + typedTestSID, "*ace.AccessAllowedAce"},
+		{"access_denied", "01002400ff010f00" + typedTestSID, "*ace.AccessDeniedAce"},
+		{"system_audit", "02002400ff010f00" + typedTestSID, "*ace.SystemAuditAce"},
+		{"system_alarm", "03002400ff010f00" + typedTestSID, "*ace.SystemAlarmAce"},
+		{"access_allowed_compound", "04003400ff010f0001000000010100000000000100000000" + typedTestSID, "*ace.CompoundAce"},
+		{"access_allowed_object", "05002800ff010f0000000000" + typedTestSID, "*ace.AccessAllowedObjectAce"},
+		{"access_denied_object", "06002800ff010f0000000000" + typedTestSID, "*ace.AccessDeniedObjectAce"},
+		{"system_audit_object", "07002800ff010f0000000000" + typedTestSID, "*ace.SystemAuditObjectAce"},
+		{"system_alarm_object", "08002800ff010f0000000000" + typedTestSID, "*ace.SystemAlarmObjectAce"},
+		{"access_allowed_callback", "09003000ff010f00" + typedTestSID + typedTestApp, "*ace.CallbackAce"},
+		{"system_audit_callback", "0d003000ff010f00" + typedTestSID + typedTestApp, "*ace.CallbackAce"},
+		{"access_denied_callback_object", "0c003400ff010f0000000000" + typedTestSID + typedTestApp, "*ace.CallbackObjectAce"},
+		{"mandatory_label", "11001400010000000101000000000010" + "00300000", "*ace.MandatoryLabelAce"},
+		{"resource_attribute", "12003000ff010f00" + typedTestSID + typedTestApp, "*ace.ResourceAttributeAce"},
+		{"process_trust_label", "14001800010002000102000000000013" + "0002000000200000", "*ace.ProcessTrustLabelAce"},
+		{"access_filter", "15003000ff010f00" + typedTestSID + typedTestApp, "*ace.AccessFilterAce"},
+		{"system_alarm_malformed", "03000800deadbeef", "*ace.OpaqueAce"},
+		{"access_allowed_padded", "00002800ff010f00" + typedTestSID + "00000000", "*ace.AccessAllowedAce"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawBytes, err := hex.DecodeString(tt.hex)
+			if err != nil {
+				t.Fatalf("Failed to decode hex string: %v", err)
+			}
+
+			typed, size, err := ace.UnmarshalTypedAccessControlEntry(rawBytes)
+			if err != nil {
+				t.Fatalf("UnmarshalTypedAccessControlEntry() error = %v", err)
+			}
+			if size != len(rawBytes) {
+				t.Errorf("UnmarshalTypedAccessControlEntry() read %d bytes, want %d", size, len(rawBytes))
+			}
+			if got := fmt.Sprintf("%T", typed); got != tt.expected {
+				t.Errorf("UnmarshalTypedAccessControlEntry() = %s, want %s", got, tt.expected)
+			}
+			if typed.AceType() != rawBytes[0] {
+				t.Errorf("AceType() = 0x%02x, want 0x%02x", typed.AceType(), rawBytes[0])
+			}
+
+			entry, err := typed.ToAccessControlEntry()
+			if err != nil {
+				t.Fatalf("ToAccessControlEntry() error = %v", err)
+			}
+			serializedBytes, err := entry.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !bytes.Equal(serializedBytes, rawBytes) {
+				t.Errorf("Marshal() = %x, want %x", serializedBytes, rawBytes)
+			}
+		})
+	}
+}
+
+func TestTypedAccessControlEntry_TypeSwitch(t *testing.T) {
+	rawBytes, _ := hex.DecodeString("09003000ff010f00" + typedTestSID + "61727478f9080000004e0061006d00650000000000")
+	rawBytes[2] = byte(len(rawBytes))
+
+	typed, _, err := ace.UnmarshalTypedAccessControlEntry(rawBytes)
+	if err != nil {
+		t.Fatalf("UnmarshalTypedAccessControlEntry() error = %v", err)
+	}
+
+	switch entry := typed.(type) {
+	case *ace.CallbackAce:
+		condition, err := entry.Condition()
+		if err != nil || condition == nil {
+			t.Fatalf("Condition() = %v, %v", condition, err)
+		}
+		if got, _ := condition.ToSDDLString(); got != "(@User.Name)" {
+			t.Errorf("Condition() = %s, want (@User.Name)", got)
+		}
+	default:
+		t.Fatalf("unexpected typed view %T", typed)
+	}
+}
+
+func TestTypedAccessControlEntry_Typed(t *testing.T) {
+	allowed, _ := hex.DecodeString("00002400ff010f00" + typedTestSID)
+
+	entry := ace.AccessControlEntry{}
+	if _, err := entry.Unmarshal(allowed); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	typed, err := entry.Typed()
+	if err != nil {
+		t.Fatalf("Typed() error = %v", err)
+	}
+	allowedAce, ok := typed.(*ace.AccessAllowedAce)
+	if !ok {
+		t.Fatalf("Typed() = %T, want *ace.AccessAllowedAce", typed)
+	}
+	if allowedAce.Mask.RawValue != 0x000f01ff || allowedAce.Identity.SID.ToString() != entry.Identity.SID.ToString() {
+		t.Errorf("Typed() = %+v, want the mask 0x000f01ff and the SID %s", allowedAce, entry.Identity.SID.ToString())
+	}
+
+	// The typed view does not share its fields with the ACE
+	allowedAce.Mask.RawValue = 0x1
+	if entry.Mask.RawValue != 0x000f01ff {
+		t.Errorf("Mask.RawValue = 0x%08x after editing the typed view, want 0x000f01ff", entry.Mask.RawValue)
+	}
+}
+
+func TestTypedAccessControlEntry_ToAccessControlEntry(t *testing.T) {
+	// The type of a fixed type view does not depend on its header
+	allowed := &ace.AccessAllowedAce{Header: header.AccessControlEntryHeader{}}
+	allowed.Header.Type.Value = acetype.ACE_TYPE_ACCESS_DENIED
+	entry, err := allowed.ToAccessControlEntry()
+	if err != nil {
+		t.Fatalf("ToAccessControlEntry() error = %v", err)
+	}
+	if entry.Header.Type.Value != acetype.ACE_TYPE_ACCESS_ALLOWED {
+		t.Errorf("Header.Type = 0x%02x, want ACCESS_ALLOWED", entry.Header.Type.Value)
+	}
+
+	// Views shared by several types only accept the types they represent
+	callback := &ace.CallbackAce{}
+	callback.Header.Type.Value = acetype.ACE_TYPE_ACCESS_ALLOWED
+	if _, err := callback.ToAccessControlEntry(); err == nil {
+		t.Errorf("CallbackAce.ToAccessControlEntry() expected an error for an ACCESS_ALLOWED header")
+	}
+
+	// Trailing data that is not padding cannot be held by a fixed type view
+	rawBytes, _ := hex.DecodeString("00002800ff010f00" + typedTestSID + "01020304")
+	trailing := ace.AccessControlEntry{}
+	if _, err := trailing.Unmarshal(rawBytes); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if _, err := trailing.Typed(); err == nil {
+		t.Errorf("Typed() expected an error for an ACCESS_ALLOWED ACE with trailing data")
+	}
+}
+
+// customAce is a custom ACE type holding a 32-bit value.
+type customAce struct {
+	Header header.AccessControlEntryHeader
+	Value  []byte
+}
+
+func newCustomAce(entry *ace.AccessControlEntry) (ace.TypedAccessControlEntry, error) {
+	if len(entry.ApplicationData) != 4 {
+		return nil, fmt.Errorf("invalid custom ACE")
+	}
+	return &customAce{Header: entry.Header, Value: append([]byte{}, entry.ApplicationData...)}, nil
+}
+
+func (c *customAce) AceType() uint8 { return 0x42 }
+
+func (c *customAce) ToAccessControlEntry() (*ace.AccessControlEntry, error) {
+	entry := &ace.AccessControlEntry{Header: c.Header, Opaque: true, ApplicationData: c.Value}
+	entry.Header.Type.Value = 0x42
+	return entry, nil
+}
+
+func TestRegisterAccessControlEntryType(t *testing.T) {
+	rawBytes, _ := hex.DecodeString("42000800cafebabe")
+
+	entry := ace.AccessControlEntry{}
+	if _, err := entry.Unmarshal(rawBytes); err == nil {
+		t.Fatalf("Unmarshal() expected an error for an unregistered ACE type")
+	}
+
+	if err := ace.RegisterAccessControlEntryType(acetype.ACE_TYPE_ACCESS_ALLOWED, newCustomAce); err == nil {
+		t.Errorf("RegisterAccessControlEntryType() expected an error for a type defined by Windows")
+	}
+	if err := ace.RegisterAccessControlEntryType(0x42, newCustomAce); err != nil {
+		t.Fatalf("RegisterAccessControlEntryType() error = %v", err)
+	}
+	defer ace.UnregisterAccessControlEntryType(0x42)
+
+	// The bridge keeps the custom ACE opaque
+	if _, err := entry.Unmarshal(rawBytes); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !entry.Opaque {
+		t.Errorf("Opaque = false for a custom ACE type")
+	}
+	serializedBytes, err := entry.Marshal()
+	if err != nil || !bytes.Equal(serializedBytes, rawBytes) {
+		t.Errorf("Marshal() = %x, %v, want %x", serializedBytes, err, rawBytes)
+	}
+
+	typed, _, err := ace.UnmarshalTypedAccessControlEntry(rawBytes)
+	if err != nil {
+		t.Fatalf("UnmarshalTypedAccessControlEntry() error = %v", err)
+	}
+	custom, ok := typed.(*customAce)
+	if !ok {
+		t.Fatalf("UnmarshalTypedAccessControlEntry() = %T, want *customAce", typed)
+	}
+	if !bytes.Equal(custom.Value, []byte{0xca, 0xfe, 0xba, 0xbe}) {
+		t.Errorf("Value = %x, want cafebabe", custom.Value)
+	}
+
+	typed, err = entry.Typed()
+	if err != nil {
+		t.Fatalf("Typed() error = %v", err)
+	}
+	if _, ok := typed.(*customAce); !ok {
+		t.Errorf("Typed() = %T, want *customAce", typed)
+	}
+
+	converted, err := custom.ToAccessControlEntry()
+	if err != nil {
+		t.Fatalf("ToAccessControlEntry() error = %v", err)
+	}
+	serializedBytes, err = converted.Marshal()
+	if err != nil || !bytes.Equal(serializedBytes, rawBytes) {
+		t.Errorf("Marshal() = %x, %v, want %x", serializedBytes, err, rawBytes)
+	}
+}
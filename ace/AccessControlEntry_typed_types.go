@@ -0,0 +1,570 @@
+package ace
+
+import (
+	"fmt"
+
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/ace/compound"
+	"github.com/TheManticoreProject/winacl/ace/conditional"
+	"github.com/TheManticoreProject/winacl/ace/header"
+	"github.com/TheManticoreProject/winacl/ace/mask"
+	"github.com/TheManticoreProject/winacl/identity"
+	"github.com/TheManticoreProject/winacl/object"
+)
+
+// AccessAllowedAce is the typed view of an ACCESS_ALLOWED ACE.
+// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/72e7c7ea-bc02-4c74-a619-818a16bf6adb
+//
+// Attributes:
+//   - Header (header.AccessControlEntryHeader): The header of the ACE, its type is always ACCESS_ALLOWED.
+//   - Mask (mask.AccessControlMask): The access mask of the ACE.
+//   - Identity (identity.Identity): The trustee of the ACE.
+type AccessAllowedAce struct {
+	Header   header.AccessControlEntryHeader
+	Mask     mask.AccessControlMask
+	Identity identity.Identity
+}
+
+// AceType returns acetype.ACE_TYPE_ACCESS_ALLOWED.
+func (typed *AccessAllowedAce) AceType() uint8 {
+	return acetype.ACE_TYPE_ACCESS_ALLOWED
+}
+
+// ToAccessControlEntry converts the typed view into an AccessControlEntry.
+//
+// Returns:
+//   - *AccessControlEntry: The ACE.
+//   - error: Always nil, all the field combinations of this view are valid.
+func (typed *AccessAllowedAce) ToAccessControlEntry() (*AccessControlEntry, error) {
+	entry := &AccessControlEntry{Header: typed.Header, Mask: typed.Mask, Identity: typed.Identity}
+	entry.Header.Type.Value = acetype.ACE_TYPE_ACCESS_ALLOWED
+	return entry, nil
+}
+
+// AccessDeniedAce is the typed view of an ACCESS_DENIED ACE.
+// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/b1e1321d-5816-4513-be67-b65d8ae52fe8
+//
+// Attributes:
+//   - Header (header.AccessControlEntryHeader): The header of the ACE, its type is always ACCESS_DENIED.
+//   - Mask (mask.AccessControlMask): The access mask of the ACE.
+//   - Identity (identity.Identity): The trustee of the ACE.
+type AccessDeniedAce struct {
+	Header   header.AccessControlEntryHeader
+	Mask     mask.AccessControlMask
+	Identity identity.Identity
+}
+
+// AceType returns acetype.ACE_TYPE_ACCESS_DENIED.
+func (typed *AccessDeniedAce) AceType() uint8 {
+	return acetype.ACE_TYPE_ACCESS_DENIED
+}
+
+// ToAccessControlEntry converts the typed view into an AccessControlEntry.
+//
+// Returns:
+//   - *AccessControlEntry: The ACE.
+//   - error: Always nil, all the field combinations of this view are valid.
+func (typed *AccessDeniedAce) ToAccessControlEntry() (*AccessControlEntry, error) {
+	entry := &AccessControlEntry{Header: typed.Header, Mask: typed.Mask, Identity: typed.Identity}
+	entry.Header.Type.Value = acetype.ACE_TYPE_ACCESS_DENIED
+	return entry, nil
+}
+
+// SystemAuditAce is the typed view of a SYSTEM_AUDIT ACE.
+// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/9431fd0f-5b9a-47f0-b3f0-3015e2d0d4f9
+//
+// Attributes:
+//   - Header (header.AccessControlEntryHeader): The header of the ACE, its type is always SYSTEM_AUDIT.
+//   - Mask (mask.AccessControlMask): The access mask of the ACE.
+//   - Identity (identity.Identity): The trustee of the ACE.
+type SystemAuditAce struct {
+	Header   header.AccessControlEntryHeader
+	Mask     mask.AccessControlMask
+	Identity identity.Identity
+}
+
+// AceType returns acetype.ACE_TYPE_SYSTEM_AUDIT.
+func (typed *SystemAuditAce) AceType() uint8 {
+	return acetype.ACE_TYPE_SYSTEM_AUDIT
+}
+
+// ToAccessControlEntry converts the typed view into an AccessControlEntry.
+//
+// Returns:
+//   - *AccessControlEntry: The ACE.
+//   - error: Always nil, all the field combinations of this view are valid.
+func (typed *SystemAuditAce) ToAccessControlEntry() (*AccessControlEntry, error) {
+	entry := &AccessControlEntry{Header: typed.Header, Mask: typed.Mask, Identity: typed.Identity}
+	entry.Header.Type.Value = acetype.ACE_TYPE_SYSTEM_AUDIT
+	return entry, nil
+}
+
+// SystemAlarmAce is the typed view of a SYSTEM_ALARM ACE.
+// The body has the layout of the audit ACE, see SystemAuditAce.
+//
+// Attributes:
+//   - Header (header.AccessControlEntryHeader): The header of the ACE, its type is always SYSTEM_ALARM.
+//   - Mask (mask.AccessControlMask): The access mask of the ACE.
+//   - Identity (identity.Identity): The trustee of the ACE.
+type SystemAlarmAce struct {
+	Header   header.AccessControlEntryHeader
+	Mask     mask.AccessControlMask
+	Identity identity.Identity
+}
+
+// AceType returns acetype.ACE_TYPE_SYSTEM_ALARM.
+func (typed *SystemAlarmAce) AceType() uint8 {
+	return acetype.ACE_TYPE_SYSTEM_ALARM
+}
+
+// ToAccessControlEntry converts the typed view into an AccessControlEntry.
+//
+// Returns:
+//   - *AccessControlEntry: The ACE.
+//   - error: Always nil, all the field combinations of this view are valid.
+func (typed *SystemAlarmAce) ToAccessControlEntry() (*AccessControlEntry, error) {
+	entry := &AccessControlEntry{Header: typed.Header, Mask: typed.Mask, Identity: typed.Identity}
+	entry.Header.Type.Value = acetype.ACE_TYPE_SYSTEM_ALARM
+	return entry, nil
+}
+
+// MandatoryLabelAce is the typed view of a SYSTEM_MANDATORY_LABEL ACE.
+// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/25fa6565-6cb0-46ab-a30a-016b32c4939a
+//
+// Attributes:
+//   - Header (header.AccessControlEntryHeader): The header of the ACE, its type is always SYSTEM_MANDATORY_LABEL.
+//   - Mask (mask.AccessControlMask): The access mask of the ACE.
+//   - Identity (identity.Identity): The trustee of the ACE.
+type MandatoryLabelAce struct {
+	Header   header.AccessControlEntryHeader
+	Mask     mask.AccessControlMask
+	Identity identity.Identity
+}
+
+// AceType returns acetype.ACE_TYPE_SYSTEM_MANDATORY_LABEL.
+func (typed *MandatoryLabelAce) AceType() uint8 {
+	return acetype.ACE_TYPE_SYSTEM_MANDATORY_LABEL
+}
+
+// ToAccessControlEntry converts the typed view into an AccessControlEntry.
+//
+// Returns:
+//   - *AccessControlEntry: The ACE.
+//   - error: Always nil, all the field combinations of this view are valid.
+func (typed *MandatoryLabelAce) ToAccessControlEntry() (*AccessControlEntry, error) {
+	entry := &AccessControlEntry{Header: typed.Header, Mask: typed.Mask, Identity: typed.Identity}
+	entry.Header.Type.Value = acetype.ACE_TYPE_SYSTEM_MANDATORY_LABEL
+	return entry, nil
+}
+
+// ScopedPolicyIdAce is the typed view of a SYSTEM_SCOPED_POLICY_ID ACE.
+// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/aa0c0f62-4b4c-44f0-9718-c266a6accd9f
+//
+// Attributes:
+//   - Header (header.AccessControlEntryHeader): The header of the ACE, its type is always SYSTEM_SCOPED_POLICY_ID.
+//   - Mask (mask.AccessControlMask): The access mask of the ACE.
+//   - Identity (identity.Identity): The trustee of the ACE.
+type ScopedPolicyIdAce struct {
+	Header   header.AccessControlEntryHeader
+	Mask     mask.AccessControlMask
+	Identity identity.Identity
+}
+
+// AceType returns acetype.ACE_TYPE_SYSTEM_SCOPED_POLICY_ID.
+func (typed *ScopedPolicyIdAce) AceType() uint8 {
+	return acetype.ACE_TYPE_SYSTEM_SCOPED_POLICY_ID
+}
+
+// ToAccessControlEntry converts the typed view into an AccessControlEntry.
+//
+// Returns:
+//   - *AccessControlEntry: The ACE.
+//   - error: Always nil, all the field combinations of this view are valid.
+func (typed *ScopedPolicyIdAce) ToAccessControlEntry() (*AccessControlEntry, error) {
+	entry := &AccessControlEntry{Header: typed.Header, Mask: typed.Mask, Identity: typed.Identity}
+	entry.Header.Type.Value = acetype.ACE_TYPE_SYSTEM_SCOPED_POLICY_ID
+	return entry, nil
+}
+
+// ProcessTrustLabelAce is the typed view of a SYSTEM_PROCESS_TRUST_LABEL ACE.
+// The body is an access mask and the trust level SID.
+//
+// Attributes:
+//   - Header (header.AccessControlEntryHeader): The header of the ACE, its type is always SYSTEM_PROCESS_TRUST_LABEL.
+//   - Mask (mask.AccessControlMask): The access mask of the ACE.
+//   - Identity (identity.Identity): The trustee of the ACE.
+type ProcessTrustLabelAce struct {
+	Header   header.AccessControlEntryHeader
+	Mask     mask.AccessControlMask
+	Identity identity.Identity
+}
+
+// AceType returns acetype.ACE_TYPE_SYSTEM_PROCESS_TRUST_LABEL.
+func (typed *ProcessTrustLabelAce) AceType() uint8 {
+	return acetype.ACE_TYPE_SYSTEM_PROCESS_TRUST_LABEL
+}
+
+// ToAccessControlEntry converts the typed view into an AccessControlEntry.
+//
+// Returns:
+//   - *AccessControlEntry: The ACE.
+//   - error: Always nil, all the field combinations of this view are valid.
+func (typed *ProcessTrustLabelAce) ToAccessControlEntry() (*AccessControlEntry, error) {
+	entry := &AccessControlEntry{Header: typed.Header, Mask: typed.Mask, Identity: typed.Identity}
+	entry.Header.Type.Value = acetype.ACE_TYPE_SYSTEM_PROCESS_TRUST_LABEL
+	return entry, nil
+}
+
+// AccessAllowedObjectAce is the typed view of an ACCESS_ALLOWED_OBJECT ACE.
+// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/c79a383c-2b3f-4655-abe7-dcbb7ce0cfbe
+//
+// Attributes:
+//   - Header (header.AccessControlEntryHeader): The header of the ACE, its type is always ACCESS_ALLOWED_OBJECT.
+//   - Mask (mask.AccessControlMask): The access mask of the ACE.
+//   - ObjectType (object.AccessControlObjectType): The object type and inherited object type GUIDs.
+//   - Identity (identity.Identity): The trustee of the ACE.
+type AccessAllowedObjectAce struct {
+	Header     header.AccessControlEntryHeader
+	Mask       mask.AccessControlMask
+	ObjectType object.AccessControlObjectType
+	Identity   identity.Identity
+}
+
+// AceType returns acetype.ACE_TYPE_ACCESS_ALLOWED_OBJECT.
+func (typed *AccessAllowedObjectAce) AceType() uint8 {
+	return acetype.ACE_TYPE_ACCESS_ALLOWED_OBJECT
+}
+
+// ToAccessControlEntry converts the typed view into an AccessControlEntry.
+//
+// Returns:
+//   - *AccessControlEntry: The ACE.
+//   - error: Always nil, all the field combinations of this view are valid.
+func (typed *AccessAllowedObjectAce) ToAccessControlEntry() (*AccessControlEntry, error) {
+	entry := &AccessControlEntry{Header: typed.Header, Mask: typed.Mask, AccessControlObjectType: typed.ObjectType, Identity: typed.Identity}
+	entry.Header.Type.Value = acetype.ACE_TYPE_ACCESS_ALLOWED_OBJECT
+	return entry, nil
+}
+
+// AccessDeniedObjectAce is the typed view of an ACCESS_DENIED_OBJECT ACE.
+// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/8720fcf3-865c-4557-97b1-0b3489a6c270
+//
+// Attributes:
+//   - Header (header.AccessControlEntryHeader): The header of the ACE, its type is always ACCESS_DENIED_OBJECT.
+//   - Mask (mask.AccessControlMask): The access mask of the ACE.
+//   - ObjectType (object.AccessControlObjectType): The object type and inherited object type GUIDs.
+//   - Identity (identity.Identity): The trustee of the ACE.
+type AccessDeniedObjectAce struct {
+	Header     header.AccessControlEntryHeader
+	Mask       mask.AccessControlMask
+	ObjectType object.AccessControlObjectType
+	Identity   identity.Identity
+}
+
+// AceType returns acetype.ACE_TYPE_ACCESS_DENIED_OBJECT.
+func (typed *AccessDeniedObjectAce) AceType() uint8 {
+	return acetype.ACE_TYPE_ACCESS_DENIED_OBJECT
+}
+
+// ToAccessControlEntry converts the typed view into an AccessControlEntry.
+//
+// Returns:
+//   - *AccessControlEntry: The ACE.
+//   - error: Always nil, all the field combinations of this view are valid.
+func (typed *AccessDeniedObjectAce) ToAccessControlEntry() (*AccessControlEntry, error) {
+	entry := &AccessControlEntry{Header: typed.Header, Mask: typed.Mask, AccessControlObjectType: typed.ObjectType, Identity: typed.Identity}
+	entry.Header.Type.Value = acetype.ACE_TYPE_ACCESS_DENIED_OBJECT
+	return entry, nil
+}
+
+// SystemAuditObjectAce is the typed view of a SYSTEM_AUDIT_OBJECT ACE.
+// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/c8da72ae-6b54-4a05-85f4-e2594936d3d5
+//
+// Attributes:
+//   - Header (header.AccessControlEntryHeader): The header of the ACE, its type is always SYSTEM_AUDIT_OBJECT.
+//   - Mask (mask.AccessControlMask): The access mask of the ACE.
+//   - ObjectType (object.AccessControlObjectType): The object type and inherited object type GUIDs.
+//   - Identity (identity.Identity): The trustee of the ACE.
+type SystemAuditObjectAce struct {
+	Header     header.AccessControlEntryHeader
+	Mask       mask.AccessControlMask
+	ObjectType object.AccessControlObjectType
+	Identity   identity.Identity
+}
+
+// AceType returns acetype.ACE_TYPE_SYSTEM_AUDIT_OBJECT.
+func (typed *SystemAuditObjectAce) AceType() uint8 {
+	return acetype.ACE_TYPE_SYSTEM_AUDIT_OBJECT
+}
+
+// ToAccessControlEntry converts the typed view into an AccessControlEntry.
+//
+// Returns:
+//   - *AccessControlEntry: The ACE.
+//   - error: Always nil, all the field combinations of this view are valid.
+func (typed *SystemAuditObjectAce) ToAccessControlEntry() (*AccessControlEntry, error) {
+	entry := &AccessControlEntry{Header: typed.Header, Mask: typed.Mask, AccessControlObjectType: typed.ObjectType, Identity: typed.Identity}
+	entry.Header.Type.Value = acetype.ACE_TYPE_SYSTEM_AUDIT_OBJECT
+	return entry, nil
+}
+
+// SystemAlarmObjectAce is the typed view of a SYSTEM_ALARM_OBJECT ACE.
+// The body has the layout of the object audit ACE, see SystemAuditObjectAce.
+//
+// Attributes:
+//   - Header (header.AccessControlEntryHeader): The header of the ACE, its type is always SYSTEM_ALARM_OBJECT.
+//   - Mask (mask.AccessControlMask): The access mask of the ACE.
+//   - ObjectType (object.AccessControlObjectType): The object type and inherited object type GUIDs.
+//   - Identity (identity.Identity): The trustee of the ACE.
+type SystemAlarmObjectAce struct {
+	Header     header.AccessControlEntryHeader
+	Mask       mask.AccessControlMask
+	ObjectType object.AccessControlObjectType
+	Identity   identity.Identity
+}
+
+// AceType returns acetype.ACE_TYPE_SYSTEM_ALARM_OBJECT.
+func (typed *SystemAlarmObjectAce) AceType() uint8 {
+	return acetype.ACE_TYPE_SYSTEM_ALARM_OBJECT
+}
+
+// ToAccessControlEntry converts the typed view into an AccessControlEntry.
+//
+// Returns:
+//   - *AccessControlEntry: The ACE.
+//   - error: Always nil, all the field combinations of this view are valid.
+func (typed *SystemAlarmObjectAce) ToAccessControlEntry() (*AccessControlEntry, error) {
+	entry := &AccessControlEntry{Header: typed.Header, Mask: typed.Mask, AccessControlObjectType: typed.ObjectType, Identity: typed.Identity}
+	entry.Header.Type.Value = acetype.ACE_TYPE_SYSTEM_ALARM_OBJECT
+	return entry, nil
+}
+
+// CompoundAce is the typed view of an ACCESS_ALLOWED_COMPOUND ACE.
+// Source: COMPOUND_ACCESS_ALLOWED_ACE in winnt.h
+//
+// Attributes:
+//   - Header (header.AccessControlEntryHeader): The header of the ACE, its type is always ACCESS_ALLOWED_COMPOUND.
+//   - Mask (mask.AccessControlMask): The access mask of the ACE.
+//   - Compound (compound.AccessControlEntryCompound): The compound type and the server identity.
+//   - Identity (identity.Identity): The client identity.
+type CompoundAce struct {
+	Header   header.AccessControlEntryHeader
+	Mask     mask.AccessControlMask
+	Compound compound.AccessControlEntryCompound
+	Identity identity.Identity
+}
+
+// AceType returns acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND.
+func (typed *CompoundAce) AceType() uint8 {
+	return acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND
+}
+
+// ToAccessControlEntry converts the typed view into an AccessControlEntry.
+//
+// Returns:
+//   - *AccessControlEntry: The ACE.
+//   - error: Always nil, all the field combinations of this view are valid.
+func (typed *CompoundAce) ToAccessControlEntry() (*AccessControlEntry, error) {
+	entry := &AccessControlEntry{Header: typed.Header, Mask: typed.Mask, Compound: typed.Compound, Identity: typed.Identity}
+	entry.Header.Type.Value = acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND
+	return entry, nil
+}
+
+// callbackAceTypes are the types of the ACEs represented by CallbackAce.
+var callbackAceTypes = map[uint8]bool{
+	acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK: true,
+	acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK:  true,
+	acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK:   true,
+	acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK:   true,
+}
+
+// callbackObjectAceTypes are the types of the ACEs represented by CallbackObjectAce.
+var callbackObjectAceTypes = map[uint8]bool{
+	acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK_OBJECT: true,
+	acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK_OBJECT:  true,
+	acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK_OBJECT:   true,
+	acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK_OBJECT:   true,
+}
+
+// CallbackAce is the typed view of the ACCESS_ALLOWED_CALLBACK, ACCESS_DENIED_CALLBACK,
+// SYSTEM_AUDIT_CALLBACK and SYSTEM_ALARM_CALLBACK ACEs, the type being held by the header.
+// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/c9579cf4-0f4a-44f1-9444-422dfb10557a
+//
+// Attributes:
+//   - Header (header.AccessControlEntryHeader): The header of the ACE.
+//   - Mask (mask.AccessControlMask): The access mask of the ACE.
+//   - Identity (identity.Identity): The trustee of the ACE.
+//   - ApplicationData ([]byte): The application data, usually a conditional expression.
+type CallbackAce struct {
+	Header          header.AccessControlEntryHeader
+	Mask            mask.AccessControlMask
+	Identity        identity.Identity
+	ApplicationData []byte
+}
+
+// AceType returns the type of the ACE held by the header.
+func (typed *CallbackAce) AceType() uint8 {
+	return typed.Header.Type.Value
+}
+
+// ToAccessControlEntry converts the typed view into an AccessControlEntry.
+//
+// Returns:
+//   - *AccessControlEntry: The ACE.
+//   - error: An error if the type held by the header is not a callback ACE type.
+func (typed *CallbackAce) ToAccessControlEntry() (*AccessControlEntry, error) {
+	if !callbackAceTypes[typed.Header.Type.Value] {
+		return nil, fmt.Errorf("ACE type %s is not a callback ACE type", typed.Header.Type.String())
+	}
+	return &AccessControlEntry{Header: typed.Header, Mask: typed.Mask, Identity: typed.Identity, ApplicationData: typed.ApplicationData}, nil
+}
+
+// Condition decodes the conditional expression stored in the ApplicationData of the ACE.
+//
+// Returns:
+//   - *conditional.ConditionalExpression: The conditional expression, or nil if there is none.
+//   - error: An error if the conditional expression is malformed.
+func (typed *CallbackAce) Condition() (*conditional.ConditionalExpression, error) {
+	return (&AccessControlEntry{Header: typed.Header, ApplicationData: typed.ApplicationData}).Condition()
+}
+
+// CallbackObjectAce is the typed view of the ACCESS_ALLOWED_CALLBACK_OBJECT,
+// ACCESS_DENIED_CALLBACK_OBJECT, SYSTEM_AUDIT_CALLBACK_OBJECT and SYSTEM_ALARM_CALLBACK_OBJECT
+// ACEs, the type being held by the header.
+// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/fe1838ea-ea34-4a5e-b40e-eb870f8322ae
+//
+// Attributes:
+//   - Header (header.AccessControlEntryHeader): The header of the ACE.
+//   - Mask (mask.AccessControlMask): The access mask of the ACE.
+//   - ObjectType (object.AccessControlObjectType): The object type and inherited object type GUIDs.
+//   - Identity (identity.Identity): The trustee of the ACE.
+//   - ApplicationData ([]byte): The application data, usually a conditional expression.
+type CallbackObjectAce struct {
+	Header          header.AccessControlEntryHeader
+	Mask            mask.AccessControlMask
+	ObjectType      object.AccessControlObjectType
+	Identity        identity.Identity
+	ApplicationData []byte
+}
+
+// AceType returns the type of the ACE held by the header.
+func (typed *CallbackObjectAce) AceType() uint8 {
+	return typed.Header.Type.Value
+}
+
+// ToAccessControlEntry converts the typed view into an AccessControlEntry.
+//
+// Returns:
+//   - *AccessControlEntry: The ACE.
+//   - error: An error if the type held by the header is not a callback object ACE type.
+func (typed *CallbackObjectAce) ToAccessControlEntry() (*AccessControlEntry, error) {
+	if !callbackObjectAceTypes[typed.Header.Type.Value] {
+		return nil, fmt.Errorf("ACE type %s is not a callback object ACE type", typed.Header.Type.String())
+	}
+	return &AccessControlEntry{Header: typed.Header, Mask: typed.Mask, AccessControlObjectType: typed.ObjectType, Identity: typed.Identity, ApplicationData: typed.ApplicationData}, nil
+}
+
+// Condition decodes the conditional expression stored in the ApplicationData of the ACE.
+//
+// Returns:
+//   - *conditional.ConditionalExpression: The conditional expression, or nil if there is none.
+//   - error: An error if the conditional expression is malformed.
+func (typed *CallbackObjectAce) Condition() (*conditional.ConditionalExpression, error) {
+	return (&AccessControlEntry{Header: typed.Header, ApplicationData: typed.ApplicationData}).Condition()
+}
+
+// ResourceAttributeAce is the typed view of a SYSTEM_RESOURCE_ATTRIBUTE ACE.
+// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/352944c7-4fb6-4988-8036-0a25dcedc730
+//
+// Attributes:
+//   - Header (header.AccessControlEntryHeader): The header of the ACE, its type is always SYSTEM_RESOURCE_ATTRIBUTE.
+//   - Mask (mask.AccessControlMask): The access mask of the ACE.
+//   - Identity (identity.Identity): The trustee of the ACE, usually Everyone.
+//   - AttributeData ([]byte): The CLAIM_SECURITY_ATTRIBUTE_RELATIVE_V1 structure of the attribute.
+type ResourceAttributeAce struct {
+	Header        header.AccessControlEntryHeader
+	Mask          mask.AccessControlMask
+	Identity      identity.Identity
+	AttributeData []byte
+}
+
+// AceType returns acetype.ACE_TYPE_SYSTEM_RESOURCE_ATTRIBUTE.
+func (typed *ResourceAttributeAce) AceType() uint8 {
+	return acetype.ACE_TYPE_SYSTEM_RESOURCE_ATTRIBUTE
+}
+
+// ToAccessControlEntry converts the typed view into an AccessControlEntry.
+//
+// Returns:
+//   - *AccessControlEntry: The ACE.
+//   - error: Always nil, all the field combinations of this view are valid.
+func (typed *ResourceAttributeAce) ToAccessControlEntry() (*AccessControlEntry, error) {
+	entry := &AccessControlEntry{Header: typed.Header, Mask: typed.Mask, Identity: typed.Identity, ApplicationData: typed.AttributeData}
+	entry.Header.Type.Value = acetype.ACE_TYPE_SYSTEM_RESOURCE_ATTRIBUTE
+	return entry, nil
+}
+
+// AccessFilterAce is the typed view of a SYSTEM_ACCESS_FILTER ACE.
+//
+// Attributes:
+//   - Header (header.AccessControlEntryHeader): The header of the ACE, its type is always SYSTEM_ACCESS_FILTER.
+//   - Mask (mask.AccessControlMask): The rights left when the condition is not satisfied.
+//   - Identity (identity.Identity): The trustee of the ACE, usually Everyone.
+//   - ApplicationData ([]byte): The conditional expression of the filter.
+type AccessFilterAce struct {
+	Header          header.AccessControlEntryHeader
+	Mask            mask.AccessControlMask
+	Identity        identity.Identity
+	ApplicationData []byte
+}
+
+// AceType returns acetype.ACE_TYPE_SYSTEM_ACCESS_FILTER.
+func (typed *AccessFilterAce) AceType() uint8 {
+	return acetype.ACE_TYPE_SYSTEM_ACCESS_FILTER
+}
+
+// ToAccessControlEntry converts the typed view into an AccessControlEntry.
+//
+// Returns:
+//   - *AccessControlEntry: The ACE.
+//   - error: Always nil, all the field combinations of this view are valid.
+func (typed *AccessFilterAce) ToAccessControlEntry() (*AccessControlEntry, error) {
+	entry := &AccessControlEntry{Header: typed.Header, Mask: typed.Mask, Identity: typed.Identity, ApplicationData: typed.ApplicationData}
+	entry.Header.Type.Value = acetype.ACE_TYPE_SYSTEM_ACCESS_FILTER
+	return entry, nil
+}
+
+// Condition decodes the conditional expression stored in the ApplicationData of the ACE.
+//
+// Returns:
+//   - *conditional.ConditionalExpression: The conditional expression, or nil if there is none.
+//   - error: An error if the conditional expression is malformed.
+func (typed *AccessFilterAce) Condition() (*conditional.ConditionalExpression, error) {
+	entry, _ := typed.ToAccessControlEntry()
+	return entry.Condition()
+}
+
+// OpaqueAce is the typed view of an ACE whose body could not be decoded, such as a malformed
+// compound or alarm ACE. Its body is kept verbatim.
+//
+// Attributes:
+//   - Header (header.AccessControlEntryHeader): The header of the ACE.
+//   - Data ([]byte): The body of the ACE, following the header.
+type OpaqueAce struct {
+	Header header.AccessControlEntryHeader
+	Data   []byte
+}
+
+// AceType returns the type of the ACE held by the header.
+func (typed *OpaqueAce) AceType() uint8 {
+	return typed.Header.Type.Value
+}
+
+// ToAccessControlEntry converts the typed view into an Opaque AccessControlEntry.
+//
+// Returns:
+//   - *AccessControlEntry: The ACE.
+//   - error: Always nil.
+func (typed *OpaqueAce) ToAccessControlEntry() (*AccessControlEntry, error) {
+	return &AccessControlEntry{Header: typed.Header, Opaque: true, ApplicationData: typed.Data}, nil
+}
@@ -0,0 +1,110 @@
+package ace
+
+import (
+	"github.com/TheManticoreProject/winacl/ace/aceflags"
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/object/flags"
+	"github.com/TheManticoreProject/winacl/sid/authority"
+	"github.com/TheManticoreProject/winacl/validation"
+)
+
+// ACE_FLAG_RESERVED is the bit of the ACE flags that is not defined by MS-DTYP.
+const ACE_FLAG_RESERVED uint8 = 0x20
+
+// Validate checks the structure of the ACE, in the spirit of the checks performed by the
+// IsValidAcl function of the Windows API, and reports the constructs that are rejected by
+// Windows or that are likely mistakes. The checks that depend on the ACL holding the ACE,
+// like the placement of the ACE type, are performed by the Validate method of the ACL.
+//
+// Returns:
+//   - validation.Findings: The problems found in the ACE, with paths relative to the ACE.
+func (ace *AccessControlEntry) Validate() validation.Findings {
+	findings := validation.Findings{}
+	aceType := ace.Header.Type.Value
+
+	// Type
+	_, known := acetype.AccessControlEntryTypeValueToName[aceType]
+	if !known {
+		if _, registered := LookupAccessControlEntryType(aceType); registered {
+			findings.Add(validation.SEVERITY_INFO, validation.FINDING_CODE_ACE_TYPE_UNKNOWN, "Header.Type", "ACE type 0x%02x is a custom type that Windows does not understand", aceType)
+		} else {
+			findings.Add(validation.SEVERITY_ERROR, validation.FINDING_CODE_ACE_TYPE_UNKNOWN, "Header.Type", "ACE type 0x%02x is unknown", aceType)
+		}
+	} else if ace.IsLegacy() {
+		findings.Add(validation.SEVERITY_WARNING, validation.FINDING_CODE_ACE_TYPE_RESERVED, "Header.Type", "ACE type %s is reserved and ignored by current versions of Windows", ace.Header.Type.String())
+	}
+	if known && ace.Opaque {
+		findings.Add(validation.SEVERITY_WARNING, validation.FINDING_CODE_ACE_BODY_OPAQUE, "", "body of the %s ACE does not follow the expected layout", ace.Header.Type.String())
+	}
+
+	// Size
+	if ace.Header.Size%4 != 0 {
+		findings.Add(validation.SEVERITY_ERROR, validation.FINDING_CODE_ACE_SIZE_UNALIGNED, "Header.Size", "ACE size %d is not a multiple of 4", ace.Header.Size)
+	}
+
+	// Flags
+	aceFlags := ace.Header.Flags.RawValue
+	if aceFlags&ACE_FLAG_RESERVED != 0 {
+		findings.Add(validation.SEVERITY_WARNING, validation.FINDING_CODE_ACE_FLAGS_RESERVED, "Header.Flags", "reserved flag 0x%02x is set", ACE_FLAG_RESERVED)
+	}
+	if known {
+		if ace.IsAuditOrAlarm() {
+			if aceFlags&aceflags.ACE_FLAG_AUDIT_FLAGS == 0 {
+				findings.Add(validation.SEVERITY_WARNING, validation.FINDING_CODE_ACE_AUDIT_FLAGS_MISSING, "Header.Flags", "%s ACE has neither SUCCESSFUL_ACCESS nor FAILED_ACCESS set and never generates an audit", ace.Header.Type.String())
+			}
+		} else if aceFlags&aceflags.ACE_FLAG_AUDIT_FLAGS != 0 {
+			findings.Add(validation.SEVERITY_WARNING, validation.FINDING_CODE_ACE_AUDIT_FLAGS_MISPLACED, "Header.Flags", "audit flags are set on a %s ACE", ace.Header.Type.String())
+		}
+	}
+	inheritable := aceFlags&(aceflags.ACE_FLAG_OBJECT_INHERIT|aceflags.ACE_FLAG_CONTAINER_INHERIT) != 0
+	if !inheritable && aceFlags&aceflags.ACE_FLAG_INHERIT_ONLY != 0 {
+		findings.Add(validation.SEVERITY_WARNING, validation.FINDING_CODE_ACE_INHERIT_ONLY_NO_EFFECT, "Header.Flags", "INHERIT_ONLY is set without OBJECT_INHERIT or CONTAINER_INHERIT, the ACE applies to no object")
+	}
+	if !inheritable && aceFlags&aceflags.ACE_FLAG_NO_PROPAGATE_INHERIT != 0 {
+		findings.Add(validation.SEVERITY_INFO, validation.FINDING_CODE_ACE_NO_PROPAGATE_NO_EFFECT, "Header.Flags", "NO_PROPAGATE_INHERIT is set without OBJECT_INHERIT or CONTAINER_INHERIT and has no effect")
+	}
+
+	// The body of unknown and opaque ACEs is not decoded
+	if !known || ace.Opaque {
+		return findings
+	}
+
+	// Mask
+	if ace.Mask.RawValue == 0 && (ace.IsAccessAllowedOrDenied() || ace.IsAuditOrAlarm()) {
+		findings.Add(validation.SEVERITY_WARNING, validation.FINDING_CODE_ACE_MASK_EMPTY, "Mask", "%s ACE has an empty access mask and has no effect", ace.Header.Type.String())
+	}
+
+	// Object type
+	if ace.IsObjectAce() {
+		objectFlags := ace.AccessControlObjectType.Flags.Value
+		validFlags := uint32(flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT | flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT)
+		if objectFlags&^validFlags != 0 {
+			findings.Add(validation.SEVERITY_WARNING, validation.FINDING_CODE_ACE_OBJECT_FLAGS_RESERVED, "AccessControlObjectType.Flags", "object type flags 0x%08x have reserved bits set", objectFlags)
+		}
+	}
+
+	// Identities
+	findings.Extend("Identity.SID", validation.ValidateSID(&ace.Identity.SID))
+	if aceType == acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND {
+		findings.Extend("Compound.ServerIdentity.SID", validation.ValidateSID(&ace.Compound.ServerIdentity.SID))
+	}
+
+	switch aceType {
+	case acetype.ACE_TYPE_SYSTEM_MANDATORY_LABEL:
+		labelSID := &ace.Identity.SID
+		if labelSID.IdentifierAuthority.Value != authority.SID_AUTHORITY_SECURITY_MANDATORY_LABEL || labelSID.SubAuthorityCount != 1 {
+			findings.Add(validation.SEVERITY_ERROR, validation.FINDING_CODE_ACE_LABEL_SID_INVALID, "Identity.SID", "%s is not a mandatory integrity level SID", labelSID.ToString())
+		}
+	case acetype.ACE_TYPE_SYSTEM_PROCESS_TRUST_LABEL:
+		if !ace.Identity.SID.IsProcessTrustSID() {
+			findings.Add(validation.SEVERITY_ERROR, validation.FINDING_CODE_ACE_TRUST_LABEL_SID_INVALID, "Identity.SID", "%s is not a process trust label SID", ace.Identity.SID.ToString())
+		}
+	}
+
+	// Condition
+	if _, err := ace.Condition(); err != nil {
+		findings.Add(validation.SEVERITY_ERROR, validation.FINDING_CODE_ACE_CONDITION_MALFORMED, "ApplicationData", "%s", err.Error())
+	}
+
+	return findings
+}
@@ -0,0 +1,83 @@
+package ace_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/validation"
+)
+
+func TestAccessControlEntry_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		hex      string
+		expected []validation.FindingCode
+	}{
+		{"valid", "00002400ff010f00" + typedTestSID, nil},
+		{"valid_audit", "02402400ff010f00" + typedTestSID, nil},
+		{"reserved_flag", "00202400ff010f00" + typedTestSID, []validation.FindingCode{validation.FINDING_CODE_ACE_FLAGS_RESERVED}},
+		{"audit_flags_misplaced", "00802400ff010f00" + typedTestSID, []validation.FindingCode{validation.FINDING_CODE_ACE_AUDIT_FLAGS_MISPLACED}},
+		{"audit_flags_missing", "02002400ff010f00" + typedTestSID, []validation.FindingCode{validation.FINDING_CODE_ACE_AUDIT_FLAGS_MISSING}},
+		{"inherit_only", "00082400ff010f00" + typedTestSID, []validation.FindingCode{validation.FINDING_CODE_ACE_INHERIT_ONLY_NO_EFFECT}},
+		{"no_propagate", "00042400ff010f00" + typedTestSID, []validation.FindingCode{validation.FINDING_CODE_ACE_NO_PROPAGATE_NO_EFFECT}},
+		{"inheritable", "000e2400ff010f00" + typedTestSID, nil},
+		{"empty_mask", "0000240000000000" + typedTestSID, []validation.FindingCode{validation.FINDING_CODE_ACE_MASK_EMPTY}},
+		{"object_flags_reserved", "05002800ff010f0004000000" + typedTestSID, []validation.FindingCode{validation.FINDING_CODE_ACE_OBJECT_FLAGS_RESERVED}},
+		{"label", "11001400010000000101000000000010" + "00300000", nil},
+		{"label_sid_invalid", "1100240001000000" + typedTestSID, []validation.FindingCode{validation.FINDING_CODE_ACE_LABEL_SID_INVALID}},
+		{"trust_label", "14001800010002000102000000000013" + "0002000000200000", nil},
+		{"trust_label_sid_invalid", "1400240001000200" + typedTestSID, []validation.FindingCode{validation.FINDING_CODE_ACE_TRUST_LABEL_SID_INVALID}},
+		{"condition_malformed", "09003000ff010f00" + typedTestSID + typedTestApp, []validation.FindingCode{validation.FINDING_CODE_ACE_CONDITION_MALFORMED}},
+		{"alarm", "03402400ff010f00" + typedTestSID, []validation.FindingCode{validation.FINDING_CODE_ACE_TYPE_RESERVED}},
+		{"alarm_opaque", "03400800deadbeef", []validation.FindingCode{validation.FINDING_CODE_ACE_TYPE_RESERVED, validation.FINDING_CODE_ACE_BODY_OPAQUE}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawBytes, err := hex.DecodeString(tt.hex)
+			if err != nil {
+				t.Fatalf("Failed to decode hex string: %v", err)
+			}
+			entry := ace.AccessControlEntry{}
+			if _, err := entry.Unmarshal(rawBytes); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			findings := entry.Validate()
+			if len(findings) != len(tt.expected) {
+				t.Fatalf("Validate() = %v, want %v", findings, tt.expected)
+			}
+			for i, code := range tt.expected {
+				if findings[i].Code != code {
+					t.Errorf("Validate()[%d].Code = %s, want %s", i, findings[i].Code, code)
+				}
+			}
+		})
+	}
+}
+
+func TestAccessControlEntry_Validate_Structure(t *testing.T) {
+	rawBytes, _ := hex.DecodeString("00002400ff010f00" + typedTestSID)
+	entry := ace.AccessControlEntry{}
+	if _, err := entry.Unmarshal(rawBytes); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	entry.Header.Size = 0x26
+	entry.Identity.SID.SubAuthorityCount = 16
+	entry.Identity.SID.SubAuthorities = make([]uint32, 15)
+
+	findings := entry.Validate()
+	if got := findings.WithCode(validation.FINDING_CODE_ACE_SIZE_UNALIGNED); len(got) != 1 || got[0].Severity != validation.SEVERITY_ERROR {
+		t.Errorf("Validate() = %v, want ACE_SIZE_UNALIGNED", findings)
+	}
+	if got := findings.WithCode(validation.FINDING_CODE_SID_TOO_MANY_SUBAUTHORITIES); len(got) != 1 || got[0].Path != "Identity.SID" {
+		t.Errorf("Validate() = %v, want SID_TOO_MANY_SUBAUTHORITIES on Identity.SID", findings)
+	}
+
+	entry.Header.Type.Value = 0x42
+	if got := entry.Validate().WithCode(validation.FINDING_CODE_ACE_TYPE_UNKNOWN); len(got) != 1 || got[0].Severity != validation.SEVERITY_ERROR {
+		t.Errorf("Validate() = %v, want ACE_TYPE_UNKNOWN", got)
+	}
+}
@@ -1,8 +1,9 @@
 package aceflags
 
 import (
-	"fmt"
 	"strings"
+
+	"github.com/TheManticoreProject/winacl/parsing"
 )
 
 // https://learn.microsoft.com/en-us/dotnet/api/system.security.accesscontrol.aceflags?view=net-8.0
@@ -80,7 +81,7 @@ var AccessControlEntryFlagToName = map[uint8]string{
 //     flag.
 func (aceflag *AccessControlEntryFlag) Unmarshal(marshalledData []byte) (int, error) {
 	if len(marshalledData) < 1 {
-		return 0, fmt.Errorf("AccessControlEntryFlag unmarshal requires at least 1 byte, got %d", len(marshalledData))
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "AccessControlEntryFlag unmarshal requires at least 1 byte, got %d", len(marshalledData))
 	}
 	aceflag.RawValue = uint8(marshalledData[0])
 	aceflag.Values = []uint8{}
@@ -101,7 +102,27 @@ func (aceflag *AccessControlEntryFlag) Unmarshal(marshalledData []byte) (int, er
 // Returns:
 //   - []byte: The serialized byte slice representing the ACE flag.
 func (aceflag *AccessControlEntryFlag) Marshal() ([]byte, error) {
-	return []byte{aceflag.RawValue}, nil
+	return aceflag.AppendBinary(make([]byte, 0, aceflag.Size()))
+}
+
+// Size returns the size of the binary representation of the AccessControlEntryFlag.
+//
+// Returns:
+//   - int: Always 1.
+func (aceflag *AccessControlEntryFlag) Size() int {
+	return 1
+}
+
+// AppendBinary appends the binary representation of the AccessControlEntryFlag to a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: Always nil.
+func (aceflag *AccessControlEntryFlag) AppendBinary(buffer []byte) ([]byte, error) {
+	return append(buffer, aceflag.RawValue), nil
 }
 
 // String returns a string representation of the AccessControlEntryFlag.
@@ -1,5 +1,7 @@
 package aceflags
 
+import "slices"
+
 // Equal checks if two AccessControlEntryFlag objects are equal by comparing all their fields.
 //
 // Parameters:
@@ -29,3 +31,17 @@ func (aceflag *AccessControlEntryFlag) Equal(other *AccessControlEntryFlag) bool
 
 	return true
 }
+
+// Clone returns a deep copy of the AccessControlEntryFlag, sharing no memory with the original.
+//
+// Returns:
+//   - *AccessControlEntryFlag: The copy of the flags, nil if the flags are nil.
+func (aceflag *AccessControlEntryFlag) Clone() *AccessControlEntryFlag {
+	if aceflag == nil {
+		return nil
+	}
+	clone := *aceflag
+	clone.Values = slices.Clone(aceflag.Values)
+	clone.Flags = slices.Clone(aceflag.Flags)
+	return &clone
+}
@@ -1,6 +1,6 @@
 package acetype
 
-import "fmt"
+import "github.com/TheManticoreProject/winacl/parsing"
 
 const (
 	ACE_TYPE_ACCESS_ALLOWED                 uint8 = 0x00 // Access-allowed ACE that uses the ACCESS_ALLOWED_ACE (section 2.4.4.2) structure.
@@ -23,7 +23,8 @@ const (
 	ACE_TYPE_SYSTEM_MANDATORY_LABEL         uint8 = 0x11 // Mandatory label ACE that uses the SYSTEM_MANDATORY_LABEL_ACE (section 2.4.4.13) structure.
 	ACE_TYPE_SYSTEM_RESOURCE_ATTRIBUTE      uint8 = 0x12 // Resource attribute ACE that uses the SYSTEM_RESOURCE_ATTRIBUTE_ACE (section 2.4.4.15).
 	ACE_TYPE_SYSTEM_SCOPED_POLICY_ID        uint8 = 0x13 // A central policy ID ACE that uses the SYSTEM_SCOPED_POLICY_ID_ACE (section 2.4.4.16).
-
+	ACE_TYPE_SYSTEM_PROCESS_TRUST_LABEL     uint8 = 0x14 // Process trust label ACE, restricting the access of processes that are not protected at the level of its SID.
+	ACE_TYPE_SYSTEM_ACCESS_FILTER           uint8 = 0x15 // Access filter ACE, restricting access to its mask when its conditional expression is not satisfied.
 )
 
 // AccessControlEntryType represents the type of an Access Control Entry (ACE)
@@ -66,6 +67,8 @@ var AccessControlEntryTypeValueToName = map[uint8]string{
 	ACE_TYPE_SYSTEM_MANDATORY_LABEL:         "SYSTEM_MANDATORY_LABEL",
 	ACE_TYPE_SYSTEM_RESOURCE_ATTRIBUTE:      "SYSTEM_RESOURCE_ATTRIBUTE",
 	ACE_TYPE_SYSTEM_SCOPED_POLICY_ID:        "SYSTEM_SCOPED_POLICY_ID",
+	ACE_TYPE_SYSTEM_PROCESS_TRUST_LABEL:     "SYSTEM_PROCESS_TRUST_LABEL",
+	ACE_TYPE_SYSTEM_ACCESS_FILTER:           "SYSTEM_ACCESS_FILTER",
 }
 
 // Unmarshal deserializes the AccessControlEntryType struct from a byte slice.
@@ -80,7 +83,7 @@ var AccessControlEntryTypeValueToName = map[uint8]string{
 //     model and indicates the type of access control entry.
 func (acetype *AccessControlEntryType) Unmarshal(marshalledData []byte) (int, error) {
 	if len(marshalledData) < 1 {
-		return 0, fmt.Errorf("AccessControlEntryType unmarshal requires at least 1 byte, got %d", len(marshalledData))
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "AccessControlEntryType unmarshal requires at least 1 byte, got %d", len(marshalledData))
 	}
 
 	// Set the value of the ACE type
@@ -94,7 +97,27 @@ func (acetype *AccessControlEntryType) Unmarshal(marshalledData []byte) (int, er
 // Returns:
 //   - []byte: The serialized byte slice representing the ACE type.
 func (acetype *AccessControlEntryType) Marshal() ([]byte, error) {
-	return []byte{acetype.Value}, nil
+	return acetype.AppendBinary(make([]byte, 0, acetype.Size()))
+}
+
+// Size returns the size of the binary representation of the AccessControlEntryType.
+//
+// Returns:
+//   - int: Always 1.
+func (acetype *AccessControlEntryType) Size() int {
+	return 1
+}
+
+// AppendBinary appends the binary representation of the AccessControlEntryType to a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: Always nil.
+func (acetype *AccessControlEntryType) AppendBinary(buffer []byte) ([]byte, error) {
+	return append(buffer, acetype.Value), nil
 }
 
 // String returns the string representation of the AccessControlEntryType.
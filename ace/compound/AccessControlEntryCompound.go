@@ -0,0 +1,150 @@
+package compound
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/TheManticoreProject/winacl/identity"
+	"github.com/TheManticoreProject/winacl/parsing"
+)
+
+const (
+	// COMPOUND_ACE_TYPE_IMPERSONATION is the only compound ACE type defined by Windows. The
+	// ACE applies to the client when it is impersonated by the server.
+	COMPOUND_ACE_TYPE_IMPERSONATION uint16 = 0x0001
+)
+
+// CompoundTypeNames maps the known compound ACE types to their names.
+var CompoundTypeNames = map[uint16]string{
+	COMPOUND_ACE_TYPE_IMPERSONATION: "COMPOUND_ACE_IMPERSONATION",
+}
+
+// AccessControlEntryCompound represents the fields specific to an ACCESS_ALLOWED_COMPOUND_ACE,
+// located between the access mask and the client SID of the ACE.
+// Source: COMPOUND_ACCESS_ALLOWED_ACE in winnt.h
+//
+// Attributes:
+//   - Type (uint16): The type of the compound ACE, see COMPOUND_ACE_TYPE_IMPERSONATION.
+//   - Reserved (uint16): Reserved bytes, kept so that they survive a round-trip.
+//   - ServerIdentity (identity.Identity): The identity of the server impersonating the client.
+//
+// Internal attributes:
+//   - RawBytes ([]byte): The raw bytes of the compound fields.
+//   - RawBytesSize (uint32): The number of bytes of the compound fields.
+type AccessControlEntryCompound struct {
+	Type           uint16
+	Reserved       uint16
+	ServerIdentity identity.Identity
+
+	// Internal
+	RawBytes     []byte
+	RawBytesSize uint32
+}
+
+// Unmarshal parses the compound type, the reserved field and the server SID from the raw bytes.
+//
+// Parameters:
+//   - marshalledData ([]byte): The raw bytes, starting right after the access mask of the ACE.
+//
+// Returns:
+//   - int: The number of bytes read.
+//   - error: An error if the data is too short to contain the compound fields.
+func (compound *AccessControlEntryCompound) Unmarshal(marshalledData []byte) (int, error) {
+	compound.RawBytesSize = 0
+
+	if len(marshalledData) < 4 {
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "compound ACE fields require at least 4 bytes, got %d", len(marshalledData))
+	}
+	compound.Type = binary.LittleEndian.Uint16(marshalledData[0:2])
+	compound.Reserved = binary.LittleEndian.Uint16(marshalledData[2:4])
+	compound.RawBytesSize += 4
+
+	rawBytesSize, err := compound.ServerIdentity.Unmarshal(marshalledData[compound.RawBytesSize:])
+	if err != nil {
+		return 0, parsing.WrapParseError(err, 4, "ServerIdentity", "failed to unmarshal ServerIdentity")
+	}
+	compound.RawBytesSize += uint32(rawBytesSize)
+
+	compound.RawBytes = marshalledData[:compound.RawBytesSize]
+
+	return int(compound.RawBytesSize), nil
+}
+
+// Marshal serializes the compound type, the reserved field and the server SID.
+//
+// Returns:
+//   - []byte: The serialized compound fields.
+//   - error: An error if the server SID cannot be serialized.
+func (compound *AccessControlEntryCompound) Marshal() ([]byte, error) {
+	marshalledData, err := compound.AppendBinary(make([]byte, 0, compound.Size()))
+	if err != nil {
+		return nil, err
+	}
+	compound.RefreshRawBytes(marshalledData)
+	return marshalledData, nil
+}
+
+// RefreshRawBytes points RawBytes and RawBytesSize at the marshalled compound fields, and
+// refreshes the server identity.
+//
+// Parameters:
+//   - marshalledData ([]byte): The bytes produced by AppendBinary for the compound fields.
+func (compound *AccessControlEntryCompound) RefreshRawBytes(marshalledData []byte) {
+	compound.RawBytesSize = uint32(compound.Size())
+	compound.RawBytes = marshalledData[:compound.RawBytesSize]
+	compound.ServerIdentity.RefreshRawBytes(marshalledData[4:])
+}
+
+// Size returns the size of the binary representation of the AccessControlEntryCompound.
+//
+// Returns:
+//   - int: The size of the compound fields, server SID included, in bytes.
+func (compound *AccessControlEntryCompound) Size() int {
+	return 4 + compound.ServerIdentity.Size()
+}
+
+// AppendBinary appends the binary representation of the AccessControlEntryCompound to a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: An error if the server SID cannot be serialized.
+func (compound *AccessControlEntryCompound) AppendBinary(buffer []byte) ([]byte, error) {
+	buffer = binary.LittleEndian.AppendUint16(buffer, compound.Type)
+	buffer = binary.LittleEndian.AppendUint16(buffer, compound.Reserved)
+
+	buffer, err := compound.ServerIdentity.AppendBinary(buffer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ServerIdentity: %w", err)
+	}
+	return buffer, nil
+}
+
+// TypeName returns the name of the compound ACE type.
+//
+// Returns:
+//   - string: The name of the type, or "?" if the type is unknown.
+func (compound *AccessControlEntryCompound) TypeName() string {
+	if name, exists := CompoundTypeNames[compound.Type]; exists {
+		return name
+	}
+	return "?"
+}
+
+// Describe prints a detailed description of the compound fields, formatted with indentation
+// for clarity.
+//
+// Parameters:
+//   - indent (int): The indentation level for formatting the output.
+func (compound *AccessControlEntryCompound) Describe(indent int) {
+	indentPrompt := strings.Repeat(" │ ", indent)
+
+	fmt.Printf("%s<Compound>\n", indentPrompt)
+	fmt.Printf("%s │ \x1b[93mType\x1b[0m : \x1b[96m0x%04x\x1b[0m (\x1b[94m%s\x1b[0m)\n", indentPrompt, compound.Type, compound.TypeName())
+	fmt.Printf("%s │ \x1b[93mServer\x1b[0m :\n", indentPrompt)
+	compound.ServerIdentity.Describe(indent + 2)
+	fmt.Printf("%s └─\n", indentPrompt)
+}
@@ -0,0 +1,36 @@
+package compound
+
+import "bytes"
+
+// Equal checks if two AccessControlEntryCompound objects are equal by comparing all their fields.
+//
+// Parameters:
+// - other: The other AccessControlEntryCompound to compare with
+//
+// Returns:
+// - bool: true if the compound fields are equal, false otherwise
+func (compound *AccessControlEntryCompound) Equal(other *AccessControlEntryCompound) bool {
+	if compound == nil || other == nil {
+		return compound == other
+	}
+
+	if compound.Type != other.Type || compound.Reserved != other.Reserved {
+		return false
+	}
+
+	return compound.ServerIdentity.SID.Equal(&other.ServerIdentity.SID)
+}
+
+// Clone returns a deep copy of the AccessControlEntryCompound, sharing no memory with the original.
+//
+// Returns:
+//   - *AccessControlEntryCompound: The copy of the compound fields, nil if they are nil.
+func (compound *AccessControlEntryCompound) Clone() *AccessControlEntryCompound {
+	if compound == nil {
+		return nil
+	}
+	clone := *compound
+	clone.ServerIdentity = *compound.ServerIdentity.Clone()
+	clone.RawBytes = bytes.Clone(compound.RawBytes)
+	return &clone
+}
@@ -0,0 +1,73 @@
+package compound_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/ace/compound"
+)
+
+func TestAccessControlEntryCompound_Involution(t *testing.T) {
+	// COMPOUND_ACE_IMPERSONATION, reserved 0xabcd, server S-1-5-18
+	hexData := "0100cdab" + "010100000000000512000000"
+	rawBytes, err := hex.DecodeString(hexData)
+	if err != nil {
+		t.Fatalf("Failed to decode hexData: %v", err)
+	}
+
+	c := &compound.AccessControlEntryCompound{}
+	size, err := c.Unmarshal(rawBytes)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal AccessControlEntryCompound: %v", err)
+	}
+	if size != len(rawBytes) {
+		t.Errorf("Unmarshal() read %d bytes, want %d", size, len(rawBytes))
+	}
+	if c.Type != compound.COMPOUND_ACE_TYPE_IMPERSONATION || c.TypeName() != "COMPOUND_ACE_IMPERSONATION" {
+		t.Errorf("Type = 0x%04x (%s), want COMPOUND_ACE_IMPERSONATION", c.Type, c.TypeName())
+	}
+	if c.Reserved != 0xabcd {
+		t.Errorf("Reserved = 0x%04x, want 0xabcd", c.Reserved)
+	}
+	if c.ServerIdentity.SID.ToString() != "S-1-5-18" {
+		t.Errorf("ServerIdentity.SID = %s, want S-1-5-18", c.ServerIdentity.SID.ToString())
+	}
+
+	serializedBytes, err := c.Marshal()
+	if err != nil {
+		t.Fatalf("Failed to marshal AccessControlEntryCompound: %v", err)
+	}
+	if !bytes.Equal(serializedBytes, rawBytes) {
+		t.Errorf("Marshal() = %x, want %x", serializedBytes, rawBytes)
+	}
+}
+
+func TestAccessControlEntryCompound_UnmarshalErrors(t *testing.T) {
+	for _, hexData := range []string{"", "0100", "01000000", "010000000101"} {
+		rawBytes, _ := hex.DecodeString(hexData)
+		c := &compound.AccessControlEntryCompound{}
+		if _, err := c.Unmarshal(rawBytes); err == nil {
+			t.Errorf("Unmarshal(%s) expected an error", hexData)
+		}
+	}
+}
+
+func TestAccessControlEntryCompound_Equal(t *testing.T) {
+	rawBytes, _ := hex.DecodeString("01000000010100000000000512000000")
+	c1 := &compound.AccessControlEntryCompound{}
+	c1.Unmarshal(rawBytes)
+	c2 := &compound.AccessControlEntryCompound{}
+	c2.Unmarshal(rawBytes)
+
+	if !c1.Equal(c2) {
+		t.Errorf("Equal() = false for identical compound fields")
+	}
+	c2.Type = 2
+	if c1.Equal(c2) {
+		t.Errorf("Equal() = true for different compound types")
+	}
+	if c1.Equal(nil) {
+		t.Errorf("Equal(nil) = true")
+	}
+}
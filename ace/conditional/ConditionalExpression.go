@@ -0,0 +1,459 @@
+package conditional
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/TheManticoreProject/winacl/sid"
+)
+
+// CONDITIONAL_EXPRESSION_SIGNATURE is the 4-byte signature ("artx") that starts the binary
+// form of a conditional expression.
+var CONDITIONAL_EXPRESSION_SIGNATURE = []byte{0x61, 0x72, 0x74, 0x78}
+
+// Token types of the binary form of a conditional expression.
+// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/7ba0da7e-b6f4-4a33-8e58-2fb2c9ba4a9d
+const (
+	TOKEN_PADDING = 0x00
+
+	// Literal tokens
+	TOKEN_INT8           = 0x01
+	TOKEN_INT16          = 0x02
+	TOKEN_INT32          = 0x03
+	TOKEN_INT64          = 0x04
+	TOKEN_UNICODE_STRING = 0x10
+	TOKEN_OCTET_STRING   = 0x18
+	TOKEN_COMPOSITE      = 0x50
+	TOKEN_SID            = 0x51
+
+	// Relational operators
+	TOKEN_EQUALS                   = 0x80
+	TOKEN_NOT_EQUALS               = 0x81
+	TOKEN_LESS_THAN                = 0x82
+	TOKEN_LESS_THAN_OR_EQUAL       = 0x83
+	TOKEN_GREATER_THAN             = 0x84
+	TOKEN_GREATER_THAN_OR_EQUAL    = 0x85
+	TOKEN_CONTAINS                 = 0x86
+	TOKEN_EXISTS                   = 0x87
+	TOKEN_ANY_OF                   = 0x88
+	TOKEN_MEMBER_OF                = 0x89
+	TOKEN_DEVICE_MEMBER_OF         = 0x8a
+	TOKEN_MEMBER_OF_ANY            = 0x8b
+	TOKEN_DEVICE_MEMBER_OF_ANY     = 0x8c
+	TOKEN_NOT_EXISTS               = 0x8d
+	TOKEN_NOT_CONTAINS             = 0x8e
+	TOKEN_NOT_ANY_OF               = 0x8f
+	TOKEN_NOT_MEMBER_OF            = 0x90
+	TOKEN_NOT_DEVICE_MEMBER_OF     = 0x91
+	TOKEN_NOT_MEMBER_OF_ANY        = 0x92
+	TOKEN_NOT_DEVICE_MEMBER_OF_ANY = 0x93
+
+	// Logical operators
+	TOKEN_AND = 0xa0
+	TOKEN_OR  = 0xa1
+	TOKEN_NOT = 0xa2
+
+	// Attribute tokens
+	TOKEN_LOCAL_ATTRIBUTE    = 0xf8
+	TOKEN_USER_ATTRIBUTE     = 0xf9
+	TOKEN_RESOURCE_ATTRIBUTE = 0xfa
+	TOKEN_DEVICE_ATTRIBUTE   = 0xfb
+)
+
+// Sign and base of integer literal tokens.
+const (
+	INTEGER_SIGN_POSITIVE = 0x01
+	INTEGER_SIGN_NEGATIVE = 0x02
+	INTEGER_SIGN_NONE     = 0x03
+
+	INTEGER_BASE_OCTAL       = 0x01
+	INTEGER_BASE_DECIMAL     = 0x02
+	INTEGER_BASE_HEXADECIMAL = 0x03
+)
+
+// TokenTypeToSDDL maps the operator tokens to their SDDL keyword or symbol.
+var TokenTypeToSDDL = map[uint8]string{
+	TOKEN_EQUALS:                   "==",
+	TOKEN_NOT_EQUALS:               "!=",
+	TOKEN_LESS_THAN:                "<",
+	TOKEN_LESS_THAN_OR_EQUAL:       "<=",
+	TOKEN_GREATER_THAN:             ">",
+	TOKEN_GREATER_THAN_OR_EQUAL:    ">=",
+	TOKEN_CONTAINS:                 "Contains",
+	TOKEN_EXISTS:                   "Exists",
+	TOKEN_ANY_OF:                   "Any_of",
+	TOKEN_MEMBER_OF:                "Member_of",
+	TOKEN_DEVICE_MEMBER_OF:         "Device_Member_of",
+	TOKEN_MEMBER_OF_ANY:            "Member_of_Any",
+	TOKEN_DEVICE_MEMBER_OF_ANY:     "Device_Member_of_Any",
+	TOKEN_NOT_EXISTS:               "Not_Exists",
+	TOKEN_NOT_CONTAINS:             "Not_Contains",
+	TOKEN_NOT_ANY_OF:               "Not_Any_of",
+	TOKEN_NOT_MEMBER_OF:            "Not_Member_of",
+	TOKEN_NOT_DEVICE_MEMBER_OF:     "Not_Device_Member_of",
+	TOKEN_NOT_MEMBER_OF_ANY:        "Not_Member_of_Any",
+	TOKEN_NOT_DEVICE_MEMBER_OF_ANY: "Not_Device_Member_of_Any",
+	TOKEN_AND:                      "&&",
+	TOKEN_OR:                       "||",
+	TOKEN_NOT:                      "!",
+}
+
+// attributePrefixes maps the attribute tokens to the prefix of their name in SDDL.
+var attributePrefixes = map[uint8]string{
+	TOKEN_LOCAL_ATTRIBUTE:    "",
+	TOKEN_USER_ATTRIBUTE:     "@User.",
+	TOKEN_RESOURCE_ATTRIBUTE: "@Resource.",
+	TOKEN_DEVICE_ATTRIBUTE:   "@Device.",
+}
+
+// Token is a single token of a conditional expression. Depending on Type, only some fields are set.
+//
+// Fields:
+//   - Type: The token type (one of the TOKEN_* constants).
+//   - Integer, Sign, Base: The value, sign and base of an integer literal.
+//   - Text: The value of a Unicode string literal or the name of an attribute.
+//   - Octets: The value of an octet string literal.
+//   - SID: The value of a SID literal.
+//   - Composite: The elements of a composite literal.
+type Token struct {
+	Type      uint8
+	Integer   int64
+	Sign      uint8
+	Base      uint8
+	Text      string
+	Octets    []byte
+	SID       sid.SID
+	Composite []Token
+}
+
+// IsLiteral returns true if the token is a literal value.
+func (token *Token) IsLiteral() bool {
+	switch token.Type {
+	case TOKEN_INT8, TOKEN_INT16, TOKEN_INT32, TOKEN_INT64, TOKEN_UNICODE_STRING, TOKEN_OCTET_STRING, TOKEN_COMPOSITE, TOKEN_SID:
+		return true
+	}
+	return false
+}
+
+// IsAttribute returns true if the token is a local, user, resource or device attribute.
+func (token *Token) IsAttribute() bool {
+	_, isAttribute := attributePrefixes[token.Type]
+	return isAttribute
+}
+
+// IsOperator returns true if the token is a relational or logical operator.
+func (token *Token) IsOperator() bool {
+	_, isOperator := TokenTypeToSDDL[token.Type]
+	return isOperator
+}
+
+// Arity returns the number of operands of an operator token, or 0 for literals and attributes.
+func (token *Token) Arity() int {
+	switch token.Type {
+	case TOKEN_EXISTS, TOKEN_NOT_EXISTS, TOKEN_NOT,
+		TOKEN_MEMBER_OF, TOKEN_DEVICE_MEMBER_OF, TOKEN_MEMBER_OF_ANY, TOKEN_DEVICE_MEMBER_OF_ANY,
+		TOKEN_NOT_MEMBER_OF, TOKEN_NOT_DEVICE_MEMBER_OF, TOKEN_NOT_MEMBER_OF_ANY, TOKEN_NOT_DEVICE_MEMBER_OF_ANY:
+		return 1
+	}
+	if token.IsOperator() {
+		return 2
+	}
+	return 0
+}
+
+// ConditionalExpression is a conditional expression of a callback or access filter ACE, stored
+// in the ApplicationData of the ACE in postfix (reverse Polish) order.
+// Source: https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-dtyp/4b19e4b5-7ba2-4a6c-adca-7c1ed4b0b5cd
+type ConditionalExpression struct {
+	Tokens []Token
+
+	// Internal
+	RawBytes     []byte
+	RawBytesSize uint32
+}
+
+// IsConditionalExpression checks if the data starts with the conditional expression signature.
+//
+// Parameters:
+//   - data ([]byte): The ApplicationData of an ACE.
+//
+// Returns:
+//   - bool: true if the data holds a conditional expression, false otherwise.
+func IsConditionalExpression(data []byte) bool {
+	return bytes.HasPrefix(data, CONDITIONAL_EXPRESSION_SIGNATURE)
+}
+
+// Unmarshal parses the binary form of a conditional expression. Trailing zero padding is consumed.
+//
+// Parameters:
+//   - marshalledData ([]byte): The binary form of the conditional expression, starting with the "artx" signature.
+//
+// Returns:
+//   - int: The number of bytes read.
+//   - error: An error if the data is not a valid conditional expression.
+func (expression *ConditionalExpression) Unmarshal(marshalledData []byte) (int, error) {
+	if !IsConditionalExpression(marshalledData) {
+		return 0, fmt.Errorf("invalid conditional expression: missing the \"artx\" signature")
+	}
+
+	expression.Tokens = make([]Token, 0)
+	offset := len(CONDITIONAL_EXPRESSION_SIGNATURE)
+	for offset < len(marshalledData) {
+		if marshalledData[offset] == TOKEN_PADDING {
+			for _, padding := range marshalledData[offset:] {
+				if padding != TOKEN_PADDING {
+					return 0, fmt.Errorf("invalid conditional expression: unexpected data after padding at offset %d", offset)
+				}
+			}
+			offset = len(marshalledData)
+			break
+		}
+		token, size, err := unmarshalToken(marshalledData[offset:])
+		if err != nil {
+			return 0, fmt.Errorf("failed to unmarshal token at offset %d: %w", offset, err)
+		}
+		expression.Tokens = append(expression.Tokens, token)
+		offset += size
+	}
+
+	expression.RawBytes = marshalledData[:offset]
+	expression.RawBytesSize = uint32(offset)
+
+	return offset, nil
+}
+
+// unmarshalToken parses a single token from the start of the data.
+func unmarshalToken(data []byte) (Token, int, error) {
+	token := Token{Type: data[0]}
+	switch {
+	case token.Type == TOKEN_INT8 || token.Type == TOKEN_INT16 || token.Type == TOKEN_INT32 || token.Type == TOKEN_INT64:
+		// Value (8 bytes), Sign (1 byte), Base (1 byte)
+		if len(data) < 11 {
+			return token, 0, fmt.Errorf("integer token requires 11 bytes, got %d", len(data))
+		}
+		token.Integer = int64(binary.LittleEndian.Uint64(data[1:9]))
+		token.Sign = data[9]
+		token.Base = data[10]
+		return token, 11, nil
+
+	case token.Type == TOKEN_UNICODE_STRING || token.IsAttribute():
+		value, size, err := unmarshalLengthPrefixed(data)
+		if err != nil {
+			return token, 0, err
+		}
+		if len(value)%2 != 0 {
+			return token, 0, fmt.Errorf("unicode string length (%d) is not a multiple of 2", len(value))
+		}
+		token.Text = decodeUTF16(value)
+		return token, size, nil
+
+	case token.Type == TOKEN_OCTET_STRING:
+		value, size, err := unmarshalLengthPrefixed(data)
+		if err != nil {
+			return token, 0, err
+		}
+		token.Octets = append([]byte{}, value...)
+		return token, size, nil
+
+	case token.Type == TOKEN_SID:
+		value, size, err := unmarshalLengthPrefixed(data)
+		if err != nil {
+			return token, 0, err
+		}
+		if _, err := token.SID.Unmarshal(value); err != nil {
+			return token, 0, fmt.Errorf("failed to unmarshal SID: %w", err)
+		}
+		return token, size, nil
+
+	case token.Type == TOKEN_COMPOSITE:
+		value, size, err := unmarshalLengthPrefixed(data)
+		if err != nil {
+			return token, 0, err
+		}
+		token.Composite = make([]Token, 0)
+		for offset := 0; offset < len(value); {
+			element, elementSize, err := unmarshalToken(value[offset:])
+			if err != nil {
+				return token, 0, fmt.Errorf("failed to unmarshal composite element: %w", err)
+			}
+			if !element.IsLiteral() {
+				return token, 0, fmt.Errorf("composite element of type 0x%02x is not a literal", element.Type)
+			}
+			token.Composite = append(token.Composite, element)
+			offset += elementSize
+		}
+		return token, size, nil
+
+	case token.IsOperator():
+		return token, 1, nil
+	}
+
+	return token, 0, fmt.Errorf("unknown token type 0x%02x", token.Type)
+}
+
+// unmarshalLengthPrefixed reads a 4-byte length followed by that many bytes, after the token type byte.
+func unmarshalLengthPrefixed(data []byte) ([]byte, int, error) {
+	if len(data) < 5 {
+		return nil, 0, fmt.Errorf("token of type 0x%02x requires at least 5 bytes, got %d", data[0], len(data))
+	}
+	length := binary.LittleEndian.Uint32(data[1:5])
+	if uint64(length) > uint64(len(data)-5) {
+		return nil, 0, fmt.Errorf("token of type 0x%02x has length %d, only %d bytes available", data[0], length, len(data)-5)
+	}
+	return data[5 : 5+length], 5 + int(length), nil
+}
+
+// Marshal serializes the conditional expression into its binary form, padded with zeros to a
+// multiple of 4 bytes.
+//
+// Returns:
+//   - []byte: The binary form of the conditional expression.
+//   - error: An error if a token cannot be serialized.
+func (expression *ConditionalExpression) Marshal() ([]byte, error) {
+	marshalledData := append([]byte{}, CONDITIONAL_EXPRESSION_SIGNATURE...)
+	for index := range expression.Tokens {
+		tokenBytes, err := expression.Tokens[index].Marshal()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal token #%d: %w", index, err)
+		}
+		marshalledData = append(marshalledData, tokenBytes...)
+	}
+	for len(marshalledData)%4 != 0 {
+		marshalledData = append(marshalledData, TOKEN_PADDING)
+	}
+	expression.RawBytes = marshalledData
+	expression.RawBytesSize = uint32(len(marshalledData))
+	return marshalledData, nil
+}
+
+// Marshal serializes the token into its binary form.
+//
+// Returns:
+//   - []byte: The binary form of the token.
+//   - error: An error if the token type is unknown.
+func (token *Token) Marshal() ([]byte, error) {
+	switch {
+	case token.Type == TOKEN_INT8 || token.Type == TOKEN_INT16 || token.Type == TOKEN_INT32 || token.Type == TOKEN_INT64:
+		marshalledData := make([]byte, 11)
+		marshalledData[0] = token.Type
+		binary.LittleEndian.PutUint64(marshalledData[1:9], uint64(token.Integer))
+		marshalledData[9] = token.Sign
+		marshalledData[10] = token.Base
+		return marshalledData, nil
+
+	case token.Type == TOKEN_UNICODE_STRING || token.IsAttribute():
+		return marshalLengthPrefixed(token.Type, encodeUTF16(token.Text)), nil
+
+	case token.Type == TOKEN_OCTET_STRING:
+		return marshalLengthPrefixed(token.Type, token.Octets), nil
+
+	case token.Type == TOKEN_SID:
+		sidBytes, err := token.SID.Marshal()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal SID: %w", err)
+		}
+		return marshalLengthPrefixed(token.Type, sidBytes), nil
+
+	case token.Type == TOKEN_COMPOSITE:
+		elements := make([]byte, 0)
+		for index := range token.Composite {
+			elementBytes, err := token.Composite[index].Marshal()
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal composite element #%d: %w", index, err)
+			}
+			elements = append(elements, elementBytes...)
+		}
+		return marshalLengthPrefixed(token.Type, elements), nil
+
+	case token.IsOperator():
+		return []byte{token.Type}, nil
+	}
+
+	return nil, fmt.Errorf("unknown token type 0x%02x", token.Type)
+}
+
+// marshalLengthPrefixed serializes a token type byte, a 4-byte length and the value.
+func marshalLengthPrefixed(tokenType uint8, value []byte) []byte {
+	marshalledData := make([]byte, 5, 5+len(value))
+	marshalledData[0] = tokenType
+	binary.LittleEndian.PutUint32(marshalledData[1:5], uint32(len(value)))
+	return append(marshalledData, value...)
+}
+
+// decodeUTF16 decodes a UTF-16LE byte slice.
+func decodeUTF16(data []byte) string {
+	codeUnits := make([]uint16, len(data)/2)
+	for index := range codeUnits {
+		codeUnits[index] = binary.LittleEndian.Uint16(data[2*index:])
+	}
+	return string(utf16.Decode(codeUnits))
+}
+
+// encodeUTF16 encodes a string as UTF-16LE.
+func encodeUTF16(value string) []byte {
+	codeUnits := utf16.Encode([]rune(value))
+	data := make([]byte, 2*len(codeUnits))
+	for index, codeUnit := range codeUnits {
+		binary.LittleEndian.PutUint16(data[2*index:], codeUnit)
+	}
+	return data
+}
+
+// Describe prints the conditional expression in SDDL form, with its raw bytes.
+//
+// Parameters:
+//   - indent (int): The indentation level for formatting the output.
+func (expression *ConditionalExpression) Describe(indent int) {
+	indentPrompt := strings.Repeat(" │ ", indent)
+
+	fmt.Printf("%s<ConditionalExpression>\n", indentPrompt)
+	condition, err := expression.ToSDDLString()
+	if err != nil {
+		condition = fmt.Sprintf("<invalid: %s>", err)
+	}
+	fmt.Printf("%s │ \x1b[93mCondition\x1b[0m : \x1b[96m%s\x1b[0m\n", indentPrompt, condition)
+	if len(expression.RawBytes) > 0 {
+		fmt.Printf("%s │ \x1b[93mRawBytes\x1b[0m  : \x1b[96m%s\x1b[0m\n", indentPrompt, hex.EncodeToString(expression.RawBytes))
+	}
+	fmt.Printf("%s └─\n", indentPrompt)
+}
+
+// Clone returns a deep copy of the token, its SID and composite elements included.
+//
+// Returns:
+//   - Token: The copy of the token.
+func (token *Token) Clone() Token {
+	clone := *token
+	clone.Octets = bytes.Clone(token.Octets)
+	clone.SID = *token.SID.Clone()
+	if token.Composite != nil {
+		clone.Composite = make([]Token, len(token.Composite))
+		for index := range token.Composite {
+			clone.Composite[index] = token.Composite[index].Clone()
+		}
+	}
+	return clone
+}
+
+// Clone returns a deep copy of the conditional expression, sharing no memory with the original.
+//
+// Returns:
+//   - *ConditionalExpression: The copy of the expression, nil if the expression is nil.
+func (expression *ConditionalExpression) Clone() *ConditionalExpression {
+	if expression == nil {
+		return nil
+	}
+	clone := *expression
+	if expression.Tokens != nil {
+		clone.Tokens = make([]Token, len(expression.Tokens))
+		for index := range expression.Tokens {
+			clone.Tokens[index] = expression.Tokens[index].Clone()
+		}
+	}
+	clone.RawBytes = bytes.Clone(expression.RawBytes)
+	return &clone
+}
@@ -0,0 +1,483 @@
+package conditional
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/TheManticoreProject/winacl/sid"
+)
+
+// EvaluationResult is the three-valued result of a conditional expression.
+type EvaluationResult int
+
+const (
+	EVALUATION_RESULT_FALSE EvaluationResult = iota
+	EVALUATION_RESULT_TRUE
+	// EVALUATION_RESULT_UNKNOWN is the result of an expression that references a missing
+	// attribute or compares values of incompatible types. An allow ACE applies only when its
+	// condition is TRUE, while a deny ACE applies when its condition is TRUE or UNKNOWN.
+	EVALUATION_RESULT_UNKNOWN
+)
+
+// String returns the name of the result: "TRUE", "FALSE" or "UNKNOWN".
+func (result EvaluationResult) String() string {
+	switch result {
+	case EVALUATION_RESULT_TRUE:
+		return "TRUE"
+	case EVALUATION_RESULT_FALSE:
+		return "FALSE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ValueKind is the type of an attribute or literal value.
+type ValueKind int
+
+const (
+	VALUE_KIND_INTEGER ValueKind = iota
+	VALUE_KIND_BOOLEAN
+	VALUE_KIND_STRING
+	VALUE_KIND_OCTET_STRING
+	VALUE_KIND_SID
+	VALUE_KIND_COMPOSITE
+)
+
+// Value is the value of a claim, a resource attribute or a literal of a conditional expression.
+//
+// Fields:
+//   - Kind: The type of the value.
+//   - Integer: The value of an integer, or 0/1 for a boolean.
+//   - String: The value of a string.
+//   - Octets: The value of an octet string.
+//   - SID: The value of a SID.
+//   - Composite: The elements of a multi-valued attribute or composite literal.
+type Value struct {
+	Kind      ValueKind
+	Integer   int64
+	String    string
+	Octets    []byte
+	SID       sid.SID
+	Composite []Value
+}
+
+// IntegerValue returns an integer Value.
+func IntegerValue(integer int64) Value {
+	return Value{Kind: VALUE_KIND_INTEGER, Integer: integer}
+}
+
+// BooleanValue returns a boolean Value.
+func BooleanValue(boolean bool) Value {
+	if boolean {
+		return Value{Kind: VALUE_KIND_BOOLEAN, Integer: 1}
+	}
+	return Value{Kind: VALUE_KIND_BOOLEAN, Integer: 0}
+}
+
+// StringValue returns a string Value.
+func StringValue(text string) Value {
+	return Value{Kind: VALUE_KIND_STRING, String: text}
+}
+
+// SIDValue returns a SID Value.
+func SIDValue(value *sid.SID) Value {
+	return Value{Kind: VALUE_KIND_SID, SID: *value}
+}
+
+// CompositeValue returns a multi-valued Value.
+func CompositeValue(values ...Value) Value {
+	return Value{Kind: VALUE_KIND_COMPOSITE, Composite: values}
+}
+
+// elements returns the values of a composite, or the value itself for a single value.
+func (value *Value) elements() []Value {
+	if value.Kind == VALUE_KIND_COMPOSITE {
+		return value.Composite
+	}
+	return []Value{*value}
+}
+
+// EvaluationContext holds the security context a conditional expression is evaluated against.
+//
+// Fields:
+//   - UserSIDs: The user and group SIDs of the token, used by Member_of and Member_of_Any.
+//   - DeviceSIDs: The device group SIDs of the token, used by Device_Member_of and Device_Member_of_Any.
+//   - LocalAttributes: The local claims, referenced without prefix (e.g. WIN://SYSAPPID).
+//   - UserAttributes: The user claims, referenced as @User.name.
+//   - DeviceAttributes: The device claims, referenced as @Device.name.
+//   - ResourceAttributes: The resource attributes of the object, referenced as @Resource.name.
+//
+// Attribute names are matched case-insensitively.
+type EvaluationContext struct {
+	UserSIDs           []sid.SID
+	DeviceSIDs         []sid.SID
+	LocalAttributes    map[string]Value
+	UserAttributes     map[string]Value
+	DeviceAttributes   map[string]Value
+	ResourceAttributes map[string]Value
+}
+
+// lookupAttribute returns the value of the attribute referenced by an attribute token.
+func (context *EvaluationContext) lookupAttribute(token *Token) (*Value, bool) {
+	var attributes map[string]Value
+	switch token.Type {
+	case TOKEN_LOCAL_ATTRIBUTE:
+		attributes = context.LocalAttributes
+	case TOKEN_USER_ATTRIBUTE:
+		attributes = context.UserAttributes
+	case TOKEN_DEVICE_ATTRIBUTE:
+		attributes = context.DeviceAttributes
+	case TOKEN_RESOURCE_ATTRIBUTE:
+		attributes = context.ResourceAttributes
+	}
+	for name, value := range attributes {
+		if strings.EqualFold(name, token.Text) {
+			return &value, true
+		}
+	}
+	return nil, false
+}
+
+// operand is an element of the evaluation stack: a value (nil for a missing attribute) or the
+// result of a sub-expression.
+type operand struct {
+	value    *Value
+	isResult bool
+	result   EvaluationResult
+}
+
+// toResult converts an operand into a logical result. Non-zero integers and booleans are TRUE,
+// zero is FALSE and anything else is UNKNOWN.
+func (op *operand) toResult() EvaluationResult {
+	if op.isResult {
+		return op.result
+	}
+	if op.value != nil && (op.value.Kind == VALUE_KIND_INTEGER || op.value.Kind == VALUE_KIND_BOOLEAN) {
+		if op.value.Integer != 0 {
+			return EVALUATION_RESULT_TRUE
+		}
+		return EVALUATION_RESULT_FALSE
+	}
+	return EVALUATION_RESULT_UNKNOWN
+}
+
+// Evaluate evaluates the conditional expression against a security context.
+//
+// Parameters:
+//   - context (*EvaluationContext): The SIDs and attributes of the security context. nil is an empty context.
+//
+// Returns:
+//   - EvaluationResult: TRUE, FALSE or UNKNOWN.
+//   - error: An error if the tokens do not form a valid postfix expression.
+func (expression *ConditionalExpression) Evaluate(context *EvaluationContext) (EvaluationResult, error) {
+	if context == nil {
+		context = &EvaluationContext{}
+	}
+
+	stack := make([]operand, 0)
+	for index := range expression.Tokens {
+		token := &expression.Tokens[index]
+		arity := token.Arity()
+		if len(stack) < arity {
+			return EVALUATION_RESULT_UNKNOWN, fmt.Errorf("operator %s at token #%d requires %d operands, got %d", TokenTypeToSDDL[token.Type], index, arity, len(stack))
+		}
+
+		switch {
+		case token.IsLiteral():
+			value := tokenToValue(token)
+			stack = append(stack, operand{value: &value})
+
+		case token.IsAttribute():
+			value, _ := context.lookupAttribute(token)
+			stack = append(stack, operand{value: value})
+
+		case arity == 1:
+			op := stack[len(stack)-1]
+			stack[len(stack)-1] = operand{isResult: true, result: context.evaluateUnary(token.Type, &op)}
+
+		default:
+			left, right := stack[len(stack)-2], stack[len(stack)-1]
+			stack = stack[:len(stack)-2]
+			stack = append(stack, operand{isResult: true, result: evaluateBinary(token.Type, &left, &right)})
+		}
+	}
+
+	if len(stack) != 1 {
+		return EVALUATION_RESULT_UNKNOWN, fmt.Errorf("conditional expression evaluates to %d values instead of 1", len(stack))
+	}
+	return stack[0].toResult(), nil
+}
+
+// tokenToValue converts a literal token into a Value.
+func tokenToValue(token *Token) Value {
+	switch token.Type {
+	case TOKEN_UNICODE_STRING:
+		return StringValue(token.Text)
+	case TOKEN_OCTET_STRING:
+		return Value{Kind: VALUE_KIND_OCTET_STRING, Octets: token.Octets}
+	case TOKEN_SID:
+		return SIDValue(&token.SID)
+	case TOKEN_COMPOSITE:
+		elements := make([]Value, 0, len(token.Composite))
+		for index := range token.Composite {
+			elements = append(elements, tokenToValue(&token.Composite[index]))
+		}
+		return CompositeValue(elements...)
+	default:
+		return IntegerValue(token.Integer)
+	}
+}
+
+// negate negates a result, UNKNOWN staying UNKNOWN.
+func negate(result EvaluationResult) EvaluationResult {
+	switch result {
+	case EVALUATION_RESULT_TRUE:
+		return EVALUATION_RESULT_FALSE
+	case EVALUATION_RESULT_FALSE:
+		return EVALUATION_RESULT_TRUE
+	}
+	return EVALUATION_RESULT_UNKNOWN
+}
+
+// fromBool converts a boolean into a result.
+func fromBool(boolean bool) EvaluationResult {
+	if boolean {
+		return EVALUATION_RESULT_TRUE
+	}
+	return EVALUATION_RESULT_FALSE
+}
+
+// evaluateUnary evaluates the unary operators.
+func (context *EvaluationContext) evaluateUnary(operator uint8, op *operand) EvaluationResult {
+	switch operator {
+	case TOKEN_NOT:
+		return negate(op.toResult())
+	case TOKEN_EXISTS:
+		return fromBool(op.value != nil)
+	case TOKEN_NOT_EXISTS:
+		return fromBool(op.value == nil)
+	}
+
+	if op.value == nil {
+		return EVALUATION_RESULT_UNKNOWN
+	}
+	sids := make([]sid.SID, 0)
+	for _, element := range op.value.elements() {
+		if element.Kind != VALUE_KIND_SID {
+			return EVALUATION_RESULT_UNKNOWN
+		}
+		sids = append(sids, element.SID)
+	}
+
+	switch operator {
+	case TOKEN_MEMBER_OF:
+		return fromBool(containsAllSIDs(context.UserSIDs, sids))
+	case TOKEN_NOT_MEMBER_OF:
+		return negate(fromBool(containsAllSIDs(context.UserSIDs, sids)))
+	case TOKEN_MEMBER_OF_ANY:
+		return fromBool(containsAnySID(context.UserSIDs, sids))
+	case TOKEN_NOT_MEMBER_OF_ANY:
+		return negate(fromBool(containsAnySID(context.UserSIDs, sids)))
+	case TOKEN_DEVICE_MEMBER_OF:
+		return fromBool(containsAllSIDs(context.DeviceSIDs, sids))
+	case TOKEN_NOT_DEVICE_MEMBER_OF:
+		return negate(fromBool(containsAllSIDs(context.DeviceSIDs, sids)))
+	case TOKEN_DEVICE_MEMBER_OF_ANY:
+		return fromBool(containsAnySID(context.DeviceSIDs, sids))
+	case TOKEN_NOT_DEVICE_MEMBER_OF_ANY:
+		return negate(fromBool(containsAnySID(context.DeviceSIDs, sids)))
+	}
+	return EVALUATION_RESULT_UNKNOWN
+}
+
+// containsSID checks if a SID is in a list of SIDs.
+func containsSID(sids []sid.SID, target *sid.SID) bool {
+	targetString := target.ToString()
+	for index := range sids {
+		if sids[index].ToString() == targetString {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAllSIDs checks if every SID of targets is in sids.
+func containsAllSIDs(sids []sid.SID, targets []sid.SID) bool {
+	for index := range targets {
+		if !containsSID(sids, &targets[index]) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsAnySID checks if at least one SID of targets is in sids.
+func containsAnySID(sids []sid.SID, targets []sid.SID) bool {
+	for index := range targets {
+		if containsSID(sids, &targets[index]) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateBinary evaluates the binary relational and logical operators.
+func evaluateBinary(operator uint8, left *operand, right *operand) EvaluationResult {
+	switch operator {
+	case TOKEN_AND:
+		leftResult, rightResult := left.toResult(), right.toResult()
+		if leftResult == EVALUATION_RESULT_FALSE || rightResult == EVALUATION_RESULT_FALSE {
+			return EVALUATION_RESULT_FALSE
+		}
+		if leftResult == EVALUATION_RESULT_UNKNOWN || rightResult == EVALUATION_RESULT_UNKNOWN {
+			return EVALUATION_RESULT_UNKNOWN
+		}
+		return EVALUATION_RESULT_TRUE
+	case TOKEN_OR:
+		leftResult, rightResult := left.toResult(), right.toResult()
+		if leftResult == EVALUATION_RESULT_TRUE || rightResult == EVALUATION_RESULT_TRUE {
+			return EVALUATION_RESULT_TRUE
+		}
+		if leftResult == EVALUATION_RESULT_UNKNOWN || rightResult == EVALUATION_RESULT_UNKNOWN {
+			return EVALUATION_RESULT_UNKNOWN
+		}
+		return EVALUATION_RESULT_FALSE
+	}
+
+	if left.value == nil || right.value == nil {
+		return EVALUATION_RESULT_UNKNOWN
+	}
+
+	switch operator {
+	case TOKEN_CONTAINS, TOKEN_NOT_CONTAINS:
+		// Every value of the right operand is one of the values of the left operand
+		result := EVALUATION_RESULT_TRUE
+		for _, element := range right.value.elements() {
+			if found := containsValue(left.value.elements(), &element); found != EVALUATION_RESULT_TRUE {
+				result = found
+				break
+			}
+		}
+		if operator == TOKEN_NOT_CONTAINS {
+			return negate(result)
+		}
+		return result
+
+	case TOKEN_ANY_OF, TOKEN_NOT_ANY_OF:
+		// At least one value of the left operand is one of the values of the right operand
+		result := EVALUATION_RESULT_FALSE
+		for _, element := range left.value.elements() {
+			if found := containsValue(right.value.elements(), &element); found != EVALUATION_RESULT_FALSE {
+				result = found
+				if found == EVALUATION_RESULT_TRUE {
+					break
+				}
+			}
+		}
+		if operator == TOKEN_NOT_ANY_OF {
+			return negate(result)
+		}
+		return result
+	}
+
+	if left.value.Kind == VALUE_KIND_COMPOSITE || right.value.Kind == VALUE_KIND_COMPOSITE {
+		if operator != TOKEN_EQUALS && operator != TOKEN_NOT_EQUALS {
+			return EVALUATION_RESULT_UNKNOWN
+		}
+		result := equalSets(left.value.elements(), right.value.elements())
+		if operator == TOKEN_NOT_EQUALS {
+			return negate(result)
+		}
+		return result
+	}
+
+	comparison, comparable := compareValues(left.value, right.value)
+	if !comparable {
+		return EVALUATION_RESULT_UNKNOWN
+	}
+	isOrdered := left.value.Kind != VALUE_KIND_OCTET_STRING && left.value.Kind != VALUE_KIND_SID
+	if !isOrdered && operator != TOKEN_EQUALS && operator != TOKEN_NOT_EQUALS {
+		return EVALUATION_RESULT_UNKNOWN
+	}
+	switch operator {
+	case TOKEN_EQUALS:
+		return fromBool(comparison == 0)
+	case TOKEN_NOT_EQUALS:
+		return fromBool(comparison != 0)
+	case TOKEN_LESS_THAN:
+		return fromBool(comparison < 0)
+	case TOKEN_LESS_THAN_OR_EQUAL:
+		return fromBool(comparison <= 0)
+	case TOKEN_GREATER_THAN:
+		return fromBool(comparison > 0)
+	case TOKEN_GREATER_THAN_OR_EQUAL:
+		return fromBool(comparison >= 0)
+	}
+	return EVALUATION_RESULT_UNKNOWN
+}
+
+// containsValue checks if a value is one of values. The result is UNKNOWN when the value cannot
+// be compared with any of the values.
+func containsValue(values []Value, target *Value) EvaluationResult {
+	result := EVALUATION_RESULT_UNKNOWN
+	for index := range values {
+		comparison, comparable := compareValues(&values[index], target)
+		if !comparable {
+			continue
+		}
+		if comparison == 0 {
+			return EVALUATION_RESULT_TRUE
+		}
+		result = EVALUATION_RESULT_FALSE
+	}
+	return result
+}
+
+// equalSets checks if two lists of values hold the same values, in any order.
+func equalSets(left []Value, right []Value) EvaluationResult {
+	if len(left) != len(right) {
+		return EVALUATION_RESULT_FALSE
+	}
+	for index := range right {
+		if found := containsValue(left, &right[index]); found != EVALUATION_RESULT_TRUE {
+			return found
+		}
+	}
+	return EVALUATION_RESULT_TRUE
+}
+
+// compareValues compares two single values. Integers and booleans compare numerically, strings
+// case-insensitively, and octet strings and SIDs only for equality.
+//
+// Returns:
+//   - int: -1, 0 or 1.
+//   - bool: false if the values have incompatible types.
+func compareValues(left *Value, right *Value) (int, bool) {
+	isNumeric := func(value *Value) bool {
+		return value.Kind == VALUE_KIND_INTEGER || value.Kind == VALUE_KIND_BOOLEAN
+	}
+	switch {
+	case isNumeric(left) && isNumeric(right):
+		switch {
+		case left.Integer < right.Integer:
+			return -1, true
+		case left.Integer > right.Integer:
+			return 1, true
+		}
+		return 0, true
+	case left.Kind == VALUE_KIND_STRING && right.Kind == VALUE_KIND_STRING:
+		return strings.Compare(strings.ToLower(left.String), strings.ToLower(right.String)), true
+	case left.Kind == VALUE_KIND_OCTET_STRING && right.Kind == VALUE_KIND_OCTET_STRING:
+		if bytes.Equal(left.Octets, right.Octets) {
+			return 0, true
+		}
+		return 1, true
+	case left.Kind == VALUE_KIND_SID && right.Kind == VALUE_KIND_SID:
+		if left.SID.ToString() == right.SID.ToString() {
+			return 0, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
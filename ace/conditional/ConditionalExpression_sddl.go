@@ -0,0 +1,536 @@
+package conditional
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/TheManticoreProject/winacl/sddl/sid"
+)
+
+// ToSDDLString converts the conditional expression to its SDDL form, as found in the last field
+// of a callback or access filter ACE string, e.g. "((@User.Title == \"PM\") && (Member_of {SID(BA)}))".
+//
+// Returns:
+//   - string: The SDDL form of the expression, enclosed in parentheses.
+//   - error: An error if the tokens do not form a valid postfix expression.
+func (expression *ConditionalExpression) ToSDDLString() (string, error) {
+	stack := make([]string, 0)
+	for index := range expression.Tokens {
+		token := &expression.Tokens[index]
+		arity := token.Arity()
+		if len(stack) < arity {
+			return "", fmt.Errorf("operator %s at token #%d requires %d operands, got %d", TokenTypeToSDDL[token.Type], index, arity, len(stack))
+		}
+
+		switch {
+		case arity == 0:
+			text, err := token.ToSDDLString()
+			if err != nil {
+				return "", fmt.Errorf("failed to convert token #%d: %w", index, err)
+			}
+			stack = append(stack, text)
+
+		case arity == 1:
+			operand := stack[len(stack)-1]
+			if token.Type == TOKEN_NOT {
+				stack[len(stack)-1] = fmt.Sprintf("(!%s)", operand)
+			} else {
+				stack[len(stack)-1] = fmt.Sprintf("(%s %s)", TokenTypeToSDDL[token.Type], operand)
+			}
+
+		default:
+			left, right := stack[len(stack)-2], stack[len(stack)-1]
+			stack = stack[:len(stack)-2]
+			stack = append(stack, fmt.Sprintf("(%s %s %s)", left, TokenTypeToSDDL[token.Type], right))
+		}
+	}
+
+	if len(stack) != 1 {
+		return "", fmt.Errorf("conditional expression evaluates to %d values instead of 1", len(stack))
+	}
+	if !strings.HasPrefix(stack[0], "(") {
+		return "(" + stack[0] + ")", nil
+	}
+	return stack[0], nil
+}
+
+// ToSDDLString converts a literal or attribute token to its SDDL form.
+//
+// Returns:
+//   - string: The SDDL form of the token.
+//   - error: An error if the token is an operator or has an unknown type.
+func (token *Token) ToSDDLString() (string, error) {
+	switch {
+	case token.Type == TOKEN_INT8 || token.Type == TOKEN_INT16 || token.Type == TOKEN_INT32 || token.Type == TOKEN_INT64:
+		magnitude := uint64(token.Integer)
+		sign := ""
+		if token.Integer < 0 {
+			magnitude = uint64(-token.Integer)
+			sign = "-"
+		} else if token.Sign == INTEGER_SIGN_POSITIVE {
+			sign = "+"
+		}
+		switch token.Base {
+		case INTEGER_BASE_OCTAL:
+			return sign + "0" + strconv.FormatUint(magnitude, 8), nil
+		case INTEGER_BASE_HEXADECIMAL:
+			return sign + "0x" + strconv.FormatUint(magnitude, 16), nil
+		default:
+			return sign + strconv.FormatUint(magnitude, 10), nil
+		}
+
+	case token.Type == TOKEN_UNICODE_STRING:
+		return "\"" + token.Text + "\"", nil
+
+	case token.Type == TOKEN_OCTET_STRING:
+		return "#" + hex.EncodeToString(token.Octets), nil
+
+	case token.Type == TOKEN_SID:
+		sidString := token.SID.ToString()
+		if alias, exists := sid.SIDToSDDL[sidString]; exists {
+			sidString = alias
+		}
+		return "SID(" + sidString + ")", nil
+
+	case token.Type == TOKEN_COMPOSITE:
+		elements := make([]string, 0, len(token.Composite))
+		for index := range token.Composite {
+			element, err := token.Composite[index].ToSDDLString()
+			if err != nil {
+				return "", err
+			}
+			elements = append(elements, element)
+		}
+		return "{" + strings.Join(elements, ", ") + "}", nil
+
+	case token.IsAttribute():
+		return attributePrefixes[token.Type] + encodeAttributeName(token.Text), nil
+	}
+
+	return "", fmt.Errorf("token of type 0x%02x has no SDDL operand form", token.Type)
+}
+
+// encodeAttributeName escapes the characters of an attribute name that are not allowed in SDDL
+// with the %XXXX notation.
+func encodeAttributeName(name string) string {
+	var sb strings.Builder
+	for _, char := range name {
+		if isAttributeNameChar(char) && char != '%' {
+			sb.WriteRune(char)
+		} else {
+			sb.WriteString(fmt.Sprintf("%%%04x", char))
+		}
+	}
+	return sb.String()
+}
+
+// isAttributeNameChar returns true if the character may appear unescaped in an SDDL attribute name.
+func isAttributeNameChar(char rune) bool {
+	return char < unicode.MaxASCII && (unicode.IsLetter(char) || unicode.IsDigit(char) || strings.ContainsRune(":./_%", char))
+}
+
+// FromSDDLString parses the SDDL form of a conditional expression into its tokens.
+//
+// Parameters:
+//   - condition (string): The SDDL form of the expression, e.g. "(Member_of {SID(BA)})".
+//
+// Returns:
+//   - int: The number of characters parsed.
+//   - error: An error if the expression is malformed.
+func (expression *ConditionalExpression) FromSDDLString(condition string) (int, error) {
+	lexemes, err := lexCondition(condition)
+	if err != nil {
+		return 0, err
+	}
+
+	parser := &conditionParser{lexemes: lexemes}
+	if err := parser.parseOr(); err != nil {
+		return 0, err
+	}
+	if parser.position != len(parser.lexemes) {
+		return 0, fmt.Errorf("unexpected %q after the end of the conditional expression", parser.lexemes[parser.position].text)
+	}
+
+	expression.Tokens = parser.tokens
+	expression.RawBytes = nil
+	expression.RawBytesSize = 0
+
+	return len(condition), nil
+}
+
+// lexeme is a lexical unit of the SDDL form of a conditional expression.
+type lexeme struct {
+	text     string
+	isString bool
+}
+
+// lexCondition splits the SDDL form of a conditional expression into lexemes.
+func lexCondition(condition string) ([]lexeme, error) {
+	lexemes := make([]lexeme, 0)
+	runes := []rune(condition)
+	for index := 0; index < len(runes); {
+		char := runes[index]
+		switch {
+		case unicode.IsSpace(char):
+			index++
+
+		case strings.ContainsRune("(){},", char):
+			lexemes = append(lexemes, lexeme{text: string(char)})
+			index++
+
+		case char == '"':
+			end := index + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated string literal at offset %d", index)
+			}
+			lexemes = append(lexemes, lexeme{text: string(runes[index+1 : end]), isString: true})
+			index = end + 1
+
+		case strings.ContainsRune("=!<>&|", char):
+			operator := string(char)
+			if index+1 < len(runes) {
+				if pair := string(runes[index : index+2]); pair == "==" || pair == "!=" || pair == "<=" || pair == ">=" || pair == "&&" || pair == "||" {
+					operator = pair
+				}
+			}
+			if operator == "=" || operator == "&" || operator == "|" {
+				return nil, fmt.Errorf("unknown operator %q at offset %d", operator, index)
+			}
+			lexemes = append(lexemes, lexeme{text: operator})
+			index += len(operator)
+
+		default:
+			end := index
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("(){},\"=!<>&|", runes[end]) {
+				end++
+			}
+			// SID(...) is a single literal
+			if strings.EqualFold(string(runes[index:end]), "SID") && end < len(runes) && runes[end] == '(' {
+				closing := end
+				for closing < len(runes) && runes[closing] != ')' {
+					closing++
+				}
+				if closing == len(runes) {
+					return nil, fmt.Errorf("unterminated SID literal at offset %d", index)
+				}
+				end = closing + 1
+			}
+			lexemes = append(lexemes, lexeme{text: string(runes[index:end])})
+			index = end
+		}
+	}
+	return lexemes, nil
+}
+
+// conditionParser is a recursive descent parser emitting the tokens of a conditional
+// expression in postfix order.
+type conditionParser struct {
+	lexemes  []lexeme
+	position int
+	tokens   []Token
+}
+
+// peek returns the text of the next lexeme, or "" at the end of the input.
+func (parser *conditionParser) peek() string {
+	if parser.position >= len(parser.lexemes) || parser.lexemes[parser.position].isString {
+		return ""
+	}
+	return parser.lexemes[parser.position].text
+}
+
+// expect consumes the next lexeme if it has the given text.
+func (parser *conditionParser) expect(text string) error {
+	if parser.peek() != text {
+		if parser.position >= len(parser.lexemes) {
+			return fmt.Errorf("expected %q, got the end of the expression", text)
+		}
+		return fmt.Errorf("expected %q, got %q", text, parser.lexemes[parser.position].text)
+	}
+	parser.position++
+	return nil
+}
+
+// lookupOperator returns the operator token matching a lexeme, ignoring the case of keywords.
+func lookupOperator(text string) (uint8, bool) {
+	for tokenType, operator := range TokenTypeToSDDL {
+		if strings.EqualFold(operator, text) {
+			return tokenType, true
+		}
+	}
+	return 0, false
+}
+
+// parseOr parses: and-expression { "||" and-expression }
+func (parser *conditionParser) parseOr() error {
+	if err := parser.parseAnd(); err != nil {
+		return err
+	}
+	for parser.peek() == "||" {
+		parser.position++
+		if err := parser.parseAnd(); err != nil {
+			return err
+		}
+		parser.tokens = append(parser.tokens, Token{Type: TOKEN_OR})
+	}
+	return nil
+}
+
+// parseAnd parses: unary-expression { "&&" unary-expression }
+func (parser *conditionParser) parseAnd() error {
+	if err := parser.parseUnary(); err != nil {
+		return err
+	}
+	for parser.peek() == "&&" {
+		parser.position++
+		if err := parser.parseUnary(); err != nil {
+			return err
+		}
+		parser.tokens = append(parser.tokens, Token{Type: TOKEN_AND})
+	}
+	return nil
+}
+
+// parseUnary parses: "!" unary-expression | "(" or-expression ")" | relational-expression
+func (parser *conditionParser) parseUnary() error {
+	switch parser.peek() {
+	case "!":
+		parser.position++
+		if err := parser.parseUnary(); err != nil {
+			return err
+		}
+		parser.tokens = append(parser.tokens, Token{Type: TOKEN_NOT})
+		return nil
+	case "(":
+		parser.position++
+		if err := parser.parseOr(); err != nil {
+			return err
+		}
+		return parser.expect(")")
+	}
+	return parser.parseRelational()
+}
+
+// parseRelational parses the relational expressions:
+//   - ("Exists" | "Not_Exists") attribute
+//   - member-of-operator (SID literal | composite of SID literals)
+//   - attribute relational-operator value
+//   - attribute, evaluated as a boolean
+func (parser *conditionParser) parseRelational() error {
+	if operator, isOperator := lookupOperator(parser.peek()); isOperator {
+		token := Token{Type: operator}
+		switch token.Arity() {
+		case 1:
+			parser.position++
+			if operator == TOKEN_EXISTS || operator == TOKEN_NOT_EXISTS {
+				if err := parser.parseAttribute(); err != nil {
+					return err
+				}
+			} else if err := parser.parseValue(); err != nil {
+				return err
+			}
+			parser.tokens = append(parser.tokens, token)
+			return nil
+		default:
+			return fmt.Errorf("unexpected operator %q", parser.peek())
+		}
+	}
+
+	if err := parser.parseAttribute(); err != nil {
+		return err
+	}
+	if operator, isOperator := lookupOperator(parser.peek()); isOperator && operator != TOKEN_AND && operator != TOKEN_OR {
+		token := Token{Type: operator}
+		if token.Arity() != 2 {
+			return fmt.Errorf("unexpected operator %q after an attribute", parser.peek())
+		}
+		parser.position++
+		if err := parser.parseValue(); err != nil {
+			return err
+		}
+		parser.tokens = append(parser.tokens, token)
+	}
+	return nil
+}
+
+// parseAttribute parses an attribute name: @User.x, @Device.x, @Resource.x or a local attribute.
+func (parser *conditionParser) parseAttribute() error {
+	if parser.position >= len(parser.lexemes) {
+		return fmt.Errorf("expected an attribute, got the end of the expression")
+	}
+	current := parser.lexemes[parser.position]
+	if current.isString || strings.ContainsAny(current.text, "(){},") {
+		return fmt.Errorf("expected an attribute, got %q", current.text)
+	}
+	token, err := parseAttributeName(current.text)
+	if err != nil {
+		return err
+	}
+	parser.position++
+	parser.tokens = append(parser.tokens, token)
+	return nil
+}
+
+// parseAttributeName converts the SDDL name of an attribute into an attribute token.
+func parseAttributeName(text string) (Token, error) {
+	token := Token{Type: TOKEN_LOCAL_ATTRIBUTE}
+	name := text
+	if strings.HasPrefix(text, "@") {
+		matched := false
+		for tokenType, prefix := range attributePrefixes {
+			if prefix != "" && len(text) > len(prefix) && strings.EqualFold(text[:len(prefix)], prefix) {
+				token.Type = tokenType
+				name = text[len(prefix):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return token, fmt.Errorf("unknown attribute prefix in %q", text)
+		}
+	}
+
+	var sb strings.Builder
+	for index := 0; index < len(name); index++ {
+		if name[index] == '%' {
+			if index+5 > len(name) {
+				return token, fmt.Errorf("invalid escape sequence in attribute name %q", text)
+			}
+			codePoint, err := strconv.ParseUint(name[index+1:index+5], 16, 16)
+			if err != nil {
+				return token, fmt.Errorf("invalid escape sequence in attribute name %q", text)
+			}
+			sb.WriteRune(rune(codePoint))
+			index += 4
+			continue
+		}
+		if !isAttributeNameChar(rune(name[index])) {
+			return token, fmt.Errorf("invalid character %q in attribute name %q", name[index], text)
+		}
+		sb.WriteByte(name[index])
+	}
+	token.Text = sb.String()
+	if token.Text == "" {
+		return token, fmt.Errorf("empty attribute name")
+	}
+	return token, nil
+}
+
+// parseValue parses the right-hand side of a relational operator: a literal, a composite
+// literal or an attribute.
+func (parser *conditionParser) parseValue() error {
+	if parser.peek() == "{" {
+		parser.position++
+		composite := Token{Type: TOKEN_COMPOSITE, Composite: make([]Token, 0)}
+		for parser.peek() != "}" {
+			if len(composite.Composite) > 0 {
+				if err := parser.expect(","); err != nil {
+					return err
+				}
+			}
+			element, err := parser.parseLiteral()
+			if err != nil {
+				return err
+			}
+			composite.Composite = append(composite.Composite, element)
+		}
+		parser.position++
+		parser.tokens = append(parser.tokens, composite)
+		return nil
+	}
+
+	if literal, err := parser.parseLiteral(); err == nil {
+		parser.tokens = append(parser.tokens, literal)
+		return nil
+	}
+	return parser.parseAttribute()
+}
+
+// parseLiteral parses a string, integer, octet string or SID literal.
+func (parser *conditionParser) parseLiteral() (Token, error) {
+	if parser.position >= len(parser.lexemes) {
+		return Token{}, fmt.Errorf("expected a literal, got the end of the expression")
+	}
+	current := parser.lexemes[parser.position]
+
+	if current.isString {
+		parser.position++
+		return Token{Type: TOKEN_UNICODE_STRING, Text: current.text}, nil
+	}
+
+	text := current.text
+	switch {
+	case strings.HasPrefix(text, "#"):
+		octets, err := hex.DecodeString(text[1:])
+		if err != nil {
+			return Token{}, fmt.Errorf("invalid octet string literal %q: %w", text, err)
+		}
+		parser.position++
+		return Token{Type: TOKEN_OCTET_STRING, Octets: octets}, nil
+
+	case len(text) > 5 && strings.EqualFold(text[:4], "SID(") && strings.HasSuffix(text, ")"):
+		sidString := strings.TrimSpace(text[4 : len(text)-1])
+		if fullSID, exists := sid.SDDLToSID[sidString]; exists {
+			sidString = fullSID
+		}
+		token := Token{Type: TOKEN_SID}
+		if err := token.SID.FromString(sidString); err != nil {
+			return Token{}, fmt.Errorf("invalid SID literal %q: %w", text, err)
+		}
+		parser.position++
+		return token, nil
+
+	case len(text) > 0 && (unicode.IsDigit(rune(text[0])) || ((text[0] == '+' || text[0] == '-') && len(text) > 1)):
+		token, err := parseIntegerLiteral(text)
+		if err != nil {
+			return Token{}, err
+		}
+		parser.position++
+		return token, nil
+	}
+
+	return Token{}, fmt.Errorf("expected a literal, got %q", text)
+}
+
+// parseIntegerLiteral parses a signed decimal, hexadecimal (0x) or octal (leading 0) integer.
+func parseIntegerLiteral(text string) (Token, error) {
+	token := Token{Type: TOKEN_INT64, Sign: INTEGER_SIGN_NONE, Base: INTEGER_BASE_DECIMAL}
+	digits := text
+	negative := false
+	switch digits[0] {
+	case '+':
+		token.Sign = INTEGER_SIGN_POSITIVE
+		digits = digits[1:]
+	case '-':
+		token.Sign = INTEGER_SIGN_NEGATIVE
+		negative = true
+		digits = digits[1:]
+	}
+
+	base := 10
+	switch {
+	case len(digits) > 2 && (digits[:2] == "0x" || digits[:2] == "0X"):
+		token.Base = INTEGER_BASE_HEXADECIMAL
+		base = 16
+		digits = digits[2:]
+	case len(digits) > 1 && digits[0] == '0':
+		token.Base = INTEGER_BASE_OCTAL
+		base = 8
+		digits = digits[1:]
+	}
+
+	magnitude, err := strconv.ParseUint(digits, base, 64)
+	if err != nil {
+		return token, fmt.Errorf("invalid integer literal %q: %w", text, err)
+	}
+	token.Integer = int64(magnitude)
+	if negative {
+		token.Integer = -token.Integer
+	}
+	return token, nil
+}
@@ -0,0 +1,195 @@
+package conditional_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/ace/conditional"
+	"github.com/TheManticoreProject/winacl/sid"
+)
+
+func TestConditionalExpression_Unmarshal(t *testing.T) {
+	// (@User.Title == "PM")
+	marshalledData := []byte{
+		0x61, 0x72, 0x74, 0x78, // artx
+		0xf9, 0x0a, 0x00, 0x00, 0x00, 'T', 0x00, 'i', 0x00, 't', 0x00, 'l', 0x00, 'e', 0x00,
+		0x10, 0x04, 0x00, 0x00, 0x00, 'P', 0x00, 'M', 0x00,
+		0x80,
+		0x00, 0x00, 0x00, // padding
+	}
+
+	expression := conditional.ConditionalExpression{}
+	size, err := expression.Unmarshal(marshalledData)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if size != len(marshalledData) {
+		t.Errorf("Unmarshal() read %d bytes, want %d", size, len(marshalledData))
+	}
+	if len(expression.Tokens) != 3 {
+		t.Fatalf("Unmarshal() returned %d tokens, want 3", len(expression.Tokens))
+	}
+
+	condition, err := expression.ToSDDLString()
+	if err != nil {
+		t.Fatalf("ToSDDLString() error = %v", err)
+	}
+	if condition != `(@User.Title == "PM")` {
+		t.Errorf("ToSDDLString() = %s", condition)
+	}
+
+	remarshalled, err := expression.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !bytes.Equal(remarshalled, marshalledData) {
+		t.Errorf("Marshal() = %x, want %x", remarshalled, marshalledData)
+	}
+}
+
+func TestConditionalExpression_UnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		marshalledData []byte
+	}{
+		{"Missing signature", []byte{0x01, 0x02, 0x03, 0x04}},
+		{"Truncated string", []byte{0x61, 0x72, 0x74, 0x78, 0x10, 0x08, 0x00, 0x00, 0x00, 'P', 0x00}},
+		{"Unknown token", []byte{0x61, 0x72, 0x74, 0x78, 0x7f}},
+		{"Data after padding", []byte{0x61, 0x72, 0x74, 0x78, 0x00, 0xa2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression := conditional.ConditionalExpression{}
+			if _, err := expression.Unmarshal(tt.marshalledData); err == nil {
+				t.Errorf("Unmarshal(%x) expected an error", tt.marshalledData)
+			}
+		})
+	}
+}
+
+func TestConditionalExpression_SDDLRoundTrip(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"(Member_of {SID(BA)})", "(Member_of {SID(BA)})"},
+		{`(@User.Title == "PM" && Member_of_Any {SID(BA), SID(S-1-5-21-1-2-3-1001)})`, `((@User.Title == "PM") && (Member_of_Any {SID(BA), SID(S-1-5-21-1-2-3-1001)}))`},
+		{"(!(Exists @Resource.Project) || @Device.Managed)", "((!(Exists @Resource.Project)) || @Device.Managed)"},
+		{"(@User.clearance >= 0x10)", "(@User.clearance >= 0x10)"},
+		{"(@Resource.Level < -3 || @Resource.Mode == 017)", "((@Resource.Level < -3) || (@Resource.Mode == 017))"},
+		{`(@Resource.Dept Any_of {"Sales", "HR"})`, `(@Resource.Dept Any_of {"Sales", "HR"})`},
+		{"(@Device.Hash == #0a0b)", "(@Device.Hash == #0a0b)"},
+		{"(WIN://SYSAPPID Contains \"Microsoft.App\")", "(WIN://SYSAPPID Contains \"Microsoft.App\")"},
+		{"(member_of {SID(BA)})", "(Member_of {SID(BA)})"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expression := conditional.ConditionalExpression{}
+			if _, err := expression.FromSDDLString(tt.input); err != nil {
+				t.Fatalf("FromSDDLString() error = %v", err)
+			}
+
+			marshalledData, err := expression.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if len(marshalledData)%4 != 0 {
+				t.Errorf("Marshal() returned %d bytes, not a multiple of 4", len(marshalledData))
+			}
+
+			decoded := conditional.ConditionalExpression{}
+			if _, err := decoded.Unmarshal(marshalledData); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			condition, err := decoded.ToSDDLString()
+			if err != nil {
+				t.Fatalf("ToSDDLString() error = %v", err)
+			}
+			if condition != tt.expected {
+				t.Errorf("ToSDDLString() = %s, want %s", condition, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConditionalExpression_FromSDDLStringErrors(t *testing.T) {
+	for _, input := range []string{
+		"(Member_of {SID(BA)}",
+		`(@User.Title == "PM)`,
+		"(@User.Title ==)",
+		"(@Unknown.Title == 1)",
+		"(@User.Title = 1)",
+		"(Contains @User.Title)",
+		"(@User.Title == 1) extra",
+	} {
+		expression := conditional.ConditionalExpression{}
+		if _, err := expression.FromSDDLString(input); err == nil {
+			t.Errorf("FromSDDLString(%q) expected an error", input)
+		}
+	}
+}
+
+func TestConditionalExpression_Evaluate(t *testing.T) {
+	administrators := sid.SID{}
+	administrators.FromString("S-1-5-32-544")
+	users := sid.SID{}
+	users.FromString("S-1-5-32-545")
+
+	context := &conditional.EvaluationContext{
+		UserSIDs: []sid.SID{administrators, users},
+		UserAttributes: map[string]conditional.Value{
+			"Title":     conditional.StringValue("PM"),
+			"clearance": conditional.IntegerValue(5),
+			"projects":  conditional.CompositeValue(conditional.StringValue("Alpha"), conditional.StringValue("Beta")),
+		},
+		DeviceAttributes: map[string]conditional.Value{
+			"Managed": conditional.BooleanValue(true),
+		},
+	}
+
+	tests := []struct {
+		condition string
+		expected  conditional.EvaluationResult
+	}{
+		{"(Member_of {SID(BA)})", conditional.EVALUATION_RESULT_TRUE},
+		{"(Member_of {SID(BA), SID(SY)})", conditional.EVALUATION_RESULT_FALSE},
+		{"(Member_of_Any {SID(BA), SID(SY)})", conditional.EVALUATION_RESULT_TRUE},
+		{"(Not_Member_of {SID(SY)})", conditional.EVALUATION_RESULT_TRUE},
+		{"(Device_Member_of {SID(BA)})", conditional.EVALUATION_RESULT_FALSE},
+		{`(@User.title == "pm")`, conditional.EVALUATION_RESULT_TRUE},
+		{"(@User.clearance >= 3)", conditional.EVALUATION_RESULT_TRUE},
+		{"(@User.clearance < 3)", conditional.EVALUATION_RESULT_FALSE},
+		{"(@User.missing == 3)", conditional.EVALUATION_RESULT_UNKNOWN},
+		{"(!(@User.missing == 3))", conditional.EVALUATION_RESULT_UNKNOWN},
+		{`(@User.clearance == "5")`, conditional.EVALUATION_RESULT_UNKNOWN},
+		{"(Exists @User.missing)", conditional.EVALUATION_RESULT_FALSE},
+		{"(Not_Exists @User.missing)", conditional.EVALUATION_RESULT_TRUE},
+		{`(@User.projects Contains "alpha")`, conditional.EVALUATION_RESULT_TRUE},
+		{`(@User.projects Contains {"Alpha", "Gamma"})`, conditional.EVALUATION_RESULT_FALSE},
+		{`(@User.projects Any_of {"Gamma", "Beta"})`, conditional.EVALUATION_RESULT_TRUE},
+		{`(@User.projects Not_Any_of {"Gamma"})`, conditional.EVALUATION_RESULT_TRUE},
+		{`(@User.projects == {"Beta", "Alpha"})`, conditional.EVALUATION_RESULT_TRUE},
+		{"(@Device.Managed)", conditional.EVALUATION_RESULT_TRUE},
+		{"(@User.missing || Member_of {SID(BA)})", conditional.EVALUATION_RESULT_TRUE},
+		{"(@User.missing && Member_of {SID(SY)})", conditional.EVALUATION_RESULT_FALSE},
+		{"(@User.missing && Member_of {SID(BA)})", conditional.EVALUATION_RESULT_UNKNOWN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			expression := conditional.ConditionalExpression{}
+			if _, err := expression.FromSDDLString(tt.condition); err != nil {
+				t.Fatalf("FromSDDLString() error = %v", err)
+			}
+			result, err := expression.Evaluate(context)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Evaluate() = %s, want %s", result, tt.expected)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/TheManticoreProject/winacl/ace/aceflags"
 	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/parsing"
 )
 
 // AccessControlEntryHeader represents the header of an Access Control Entry (ACE)
@@ -53,7 +54,7 @@ type AccessControlEntryHeader struct {
 func (aceheader *AccessControlEntryHeader) Unmarshal(marshalledData []byte) (int, error) {
 	// Ensure that RawBytes has sufficient length
 	if len(marshalledData) < 4 {
-		return 0, fmt.Errorf("rawBytes is too short to contain an ACE header")
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "rawBytes is too short to contain an ACE header")
 	}
 
 	// Initialize RawBytesSize
@@ -63,14 +64,14 @@ func (aceheader *AccessControlEntryHeader) Unmarshal(marshalledData []byte) (int
 	// Parse the ACE type from the first byte
 	rawBytesSize, err := aceheader.Type.Unmarshal(marshalledData[:1])
 	if err != nil {
-		return 0, fmt.Errorf("failed to unmarshal Type: %w", err)
+		return 0, parsing.WrapParseError(err, 0, "Type", "failed to unmarshal Type")
 	}
 	aceheader.RawBytesSize += uint32(rawBytesSize)
 
 	// Parse the ACE flags from the second byte
 	rawBytesSize, err = aceheader.Flags.Unmarshal(marshalledData[1:2])
 	if err != nil {
-		return 0, fmt.Errorf("failed to unmarshal Flags: %w", err)
+		return 0, parsing.WrapParseError(err, 1, "Flags", "failed to unmarshal Flags")
 	}
 	aceheader.RawBytesSize += uint32(rawBytesSize)
 
@@ -89,25 +90,53 @@ func (aceheader *AccessControlEntryHeader) Unmarshal(marshalledData []byte) (int
 // Returns:
 //   - []byte: The serialized byte slice representing the ACE header.
 func (aceheader *AccessControlEntryHeader) Marshal() ([]byte, error) {
-	marshalledData := make([]byte, 0)
+	marshalledData, err := aceheader.AppendBinary(make([]byte, 0, aceheader.MarshalledSize()))
+	if err != nil {
+		return nil, err
+	}
+	aceheader.RefreshRawBytes(marshalledData)
+	return marshalledData, nil
+}
+
+// RefreshRawBytes points RawBytes and RawBytesSize at the 4 marshalled bytes of the header.
+//
+// Parameters:
+//   - marshalledData ([]byte): The bytes produced by AppendBinary for the header.
+func (aceheader *AccessControlEntryHeader) RefreshRawBytes(marshalledData []byte) {
+	aceheader.RawBytesSize = uint32(aceheader.MarshalledSize())
+	aceheader.RawBytes = marshalledData[:aceheader.RawBytesSize]
+}
 
-	bytesStream, err := aceheader.Type.Marshal()
+// MarshalledSize returns the size of the binary representation of the
+// AccessControlEntryHeader. It is not named Size like on the other components since the
+// header has a Size field, holding the size of the whole ACE.
+//
+// Returns:
+//   - int: Always 4.
+func (aceheader *AccessControlEntryHeader) MarshalledSize() int {
+	return 4
+}
+
+// AppendBinary appends the binary representation of the AccessControlEntryHeader to a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: An error if the type or the flags cannot be serialized.
+func (aceheader *AccessControlEntryHeader) AppendBinary(buffer []byte) ([]byte, error) {
+	buffer, err := aceheader.Type.AppendBinary(buffer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal Type: %w", err)
 	}
-	marshalledData = append(marshalledData, bytesStream...)
 
-	bytesStream, err = aceheader.Flags.Marshal()
+	buffer, err = aceheader.Flags.AppendBinary(buffer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal Flags: %w", err)
 	}
-	marshalledData = append(marshalledData, bytesStream...)
 
-	buffer := make([]byte, 2)
-	binary.LittleEndian.PutUint16(buffer, aceheader.Size)
-	marshalledData = append(marshalledData, buffer...)
-
-	return marshalledData, nil
+	return binary.LittleEndian.AppendUint16(buffer, aceheader.Size), nil
 }
 
 // Describe prints a human-readable representation of the AccessControlEntryHeader struct.
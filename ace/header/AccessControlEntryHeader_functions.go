@@ -1,5 +1,7 @@
 package header
 
+import "bytes"
+
 // Equal checks if two AccessControlEntryHeader objects are equal by comparing all their fields.
 //
 // Parameters:
@@ -29,3 +31,17 @@ func (header *AccessControlEntryHeader) Equal(other *AccessControlEntryHeader) b
 
 	return true
 }
+
+// Clone returns a deep copy of the AccessControlEntryHeader, sharing no memory with the original.
+//
+// Returns:
+//   - *AccessControlEntryHeader: The copy of the header, nil if the header is nil.
+func (header *AccessControlEntryHeader) Clone() *AccessControlEntryHeader {
+	if header == nil {
+		return nil
+	}
+	clone := *header
+	clone.Flags = *header.Flags.Clone()
+	clone.RawBytes = bytes.Clone(header.RawBytes)
+	return &clone
+}
@@ -3,9 +3,9 @@ package mask
 import (
 	"encoding/binary"
 	"fmt"
-	"sort"
 	"strings"
 
+	"github.com/TheManticoreProject/winacl/parsing"
 	"github.com/TheManticoreProject/winacl/rights"
 )
 
@@ -17,6 +17,10 @@ type AccessControlMask struct {
 	Values   []uint32 // Individual flag values extracted from the mask
 	Flags    []string // Names of the flags corresponding to their values
 
+	// Namespace selects the rights table used to name the bits of the mask. The zero value
+	// decodes the mask with the directory service rights.
+	Namespace rights.RightsNamespace
+
 	// Internal fields
 	RawBytes     []byte // Raw byte representation of the mask
 	RawBytesSize uint32 // Size of the raw bytes
@@ -26,36 +30,18 @@ type AccessControlMask struct {
 // It extracts the RawValue and determines the corresponding flags and their names.
 func (acm *AccessControlMask) Unmarshal(marshalledData []byte) (int, error) {
 	if len(marshalledData) < 4 {
-		return 0, fmt.Errorf("AccessControlMask unmarshal requires at least 4 bytes, got %d", len(marshalledData))
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "AccessControlMask unmarshal requires at least 4 bytes, got %d", len(marshalledData))
 	}
 
 	// Store the raw bytes and set the size
-	acm.RawBytes = marshalledData
+	acm.RawBytes = marshalledData[:4]
 	acm.RawBytesSize = 4
 
 	// Convert raw bytes to a uint32 value using little-endian format
 	acm.RawValue = binary.LittleEndian.Uint32(marshalledData[:4])
 
-	// Prepare a list of right names and sort them for consistent ordering
-	listOfRightNames := make([]string, 0, len(rights.RightValueToRightName))
-	for _, RightName := range rights.RightValueToRightName {
-		listOfRightNames = append(listOfRightNames, RightName)
-	}
-	sort.Strings(listOfRightNames)
-
-	// Initialize slices for flags and values
-	acm.Flags = make([]string, 0)
-	acm.Values = make([]uint32, 0)
-
-	// Parse flags based on the sorted right names
-	for _, RightName := range listOfRightNames {
-		RightValue := rights.RightNameToRightValue[RightName]
-		// Check if the corresponding right is set in the RawValue
-		if (acm.RawValue & RightValue) == RightValue {
-			acm.Flags = append(acm.Flags, RightName)    // Add the name of the right
-			acm.Values = append(acm.Values, RightValue) // Add the value of the right
-		}
-	}
+	// Decode the flags with the rights table of the namespace
+	acm.Flags, acm.Values = acm.Namespace.Decode(acm.RawValue)
 
 	return 4, nil
 }
@@ -65,11 +51,41 @@ func (acm *AccessControlMask) Unmarshal(marshalledData []byte) (int, error) {
 // Returns:
 //   - []byte: The serialized byte slice representing the AccessControlMask.
 func (acm *AccessControlMask) Marshal() ([]byte, error) {
-	marshalledData := make([]byte, 4)
+	marshalledData, err := acm.AppendBinary(make([]byte, 0, acm.Size()))
+	if err != nil {
+		return nil, err
+	}
+	acm.RefreshRawBytes(marshalledData)
+	return marshalledData, nil
+}
 
-	binary.LittleEndian.PutUint32(marshalledData, acm.RawValue)
+// RefreshRawBytes points RawBytes and RawBytesSize at the 4 marshalled bytes of the mask.
+//
+// Parameters:
+//   - marshalledData ([]byte): The bytes produced by AppendBinary for the mask.
+func (acm *AccessControlMask) RefreshRawBytes(marshalledData []byte) {
+	acm.RawBytesSize = uint32(acm.Size())
+	acm.RawBytes = marshalledData[:acm.RawBytesSize]
+}
 
-	return marshalledData, nil
+// Size returns the size of the binary representation of the AccessControlMask.
+//
+// Returns:
+//   - int: Always 4.
+func (acm *AccessControlMask) Size() int {
+	return 4
+}
+
+// AppendBinary appends the binary representation of the AccessControlMask to a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: Always nil.
+func (acm *AccessControlMask) AppendBinary(buffer []byte) ([]byte, error) {
+	return binary.LittleEndian.AppendUint32(buffer, acm.RawValue), nil
 }
 
 // String returns a string representation of the AccessControlMask.
@@ -85,6 +101,9 @@ func (acm *AccessControlMask) String() string {
 func (acm *AccessControlMask) Describe(indent int) {
 	indentPrompt := strings.Repeat(" │ ", indent)
 	fmt.Printf("%s<AccessControlMask>\n", indentPrompt)
+	if acm.Namespace != rights.RIGHTS_NAMESPACE_DIRECTORY_SERVICE {
+		fmt.Printf("%s │ \x1b[93mNamespace\x1b[0m : \x1b[96m%s\x1b[0m\n", indentPrompt, acm.Namespace.String())
+	}
 	fmt.Printf("%s │ \x1b[93mMask\x1b[0m : \x1b[96m0x%08x\x1b[0m (\x1b[94m%s\x1b[0m)\n", indentPrompt, acm.RawValue, acm.String())
 	fmt.Printf("%s └─\n", indentPrompt)
 }
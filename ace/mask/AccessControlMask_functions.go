@@ -28,7 +28,7 @@ func (acm *AccessControlMask) AddRight(right uint32) {
 	if !acm.HasRight(right) {
 		acm.RawValue |= right
 		acm.Values = append(acm.Values, right)
-		if rightName, ok := rights.RightValueToRightName[right]; ok {
+		if rightName, ok := acm.Namespace.RightName(right); ok {
 			acm.Flags = append(acm.Flags, rightName)
 			// Keep flags sorted for consistent output
 			sort.Strings(acm.Flags)
@@ -51,7 +51,7 @@ func (acm *AccessControlMask) RemoveRight(right uint32) {
 			}
 		}
 		// Remove from Flags slice if name exists
-		if rightName, ok := rights.RightValueToRightName[right]; ok {
+		if rightName, ok := acm.Namespace.RightName(right); ok {
 			for i, f := range acm.Flags {
 				if f == rightName {
 					acm.Flags = append(acm.Flags[:i], acm.Flags[i+1:]...)
@@ -62,6 +62,16 @@ func (acm *AccessControlMask) RemoveRight(right uint32) {
 	}
 }
 
+// SetNamespace sets the rights namespace of the mask and decodes its flags again from the
+// RawValue, so that Flags, Values and String() use the names of the namespace.
+//
+// Parameters:
+// - namespace: The rights namespace of the object the mask applies to.
+func (acm *AccessControlMask) SetNamespace(namespace rights.RightsNamespace) {
+	acm.Namespace = namespace
+	acm.Flags, acm.Values = namespace.Decode(acm.RawValue)
+}
+
 // ClearRights removes all rights from the ACE's Mask.
 func (acm *AccessControlMask) ClearRights() {
 	acm.RawValue = 0
@@ -80,7 +90,9 @@ func (acm *AccessControlMask) SetRights(rights []uint32) {
 	}
 }
 
-// Equal checks if two AccessControlMask objects are equal by comparing all their fields.
+// Equal checks if two AccessControlMask objects are equal by comparing their value and the
+// rights derived from it. RawBytes and RawBytesSize, which only locate the mask in the buffer
+// it was parsed from, are not compared.
 //
 // Parameters:
 // - other: The other AccessControlMask to compare with
@@ -97,16 +109,6 @@ func (acm *AccessControlMask) Equal(other *AccessControlMask) bool {
 		return false
 	}
 
-	// Compare RawBytes
-	if !bytes.Equal(acm.RawBytes, other.RawBytes) {
-		return false
-	}
-
-	// Compare RawBytesSize
-	if acm.RawBytesSize != other.RawBytesSize {
-		return false
-	}
-
 	// Compare Values
 	if !slices.Equal(acm.Values, other.Values) {
 		return false
@@ -119,3 +121,37 @@ func (acm *AccessControlMask) Equal(other *AccessControlMask) bool {
 
 	return true
 }
+
+// SpecificRights returns the RawValue of the mask with its generic rights replaced by the rights
+// they grant in the namespace of the mask. The mask itself is not modified.
+//
+// Returns:
+// - uint32: The access mask in its specific-rights form.
+func (acm *AccessControlMask) SpecificRights() uint32 {
+	return acm.Namespace.MapGenericMask(acm.RawValue)
+}
+
+// MapGenericRights replaces the generic rights of the mask with the rights they grant according
+// to a generic mapping, and decodes its flags again.
+//
+// Parameters:
+// - mapping: The generic mapping of the object type the mask applies to.
+func (acm *AccessControlMask) MapGenericRights(mapping rights.GenericMapping) {
+	acm.RawValue = rights.MapGenericMask(acm.RawValue, mapping)
+	acm.Flags, acm.Values = acm.Namespace.Decode(acm.RawValue)
+}
+
+// Clone returns a deep copy of the AccessControlMask, sharing no memory with the original.
+//
+// Returns:
+//   - *AccessControlMask: The copy of the mask, nil if the mask is nil.
+func (acm *AccessControlMask) Clone() *AccessControlMask {
+	if acm == nil {
+		return nil
+	}
+	clone := *acm
+	clone.Values = slices.Clone(acm.Values)
+	clone.Flags = slices.Clone(acm.Flags)
+	clone.RawBytes = bytes.Clone(acm.RawBytes)
+	return &clone
+}
@@ -135,3 +135,36 @@ func TestAccessControlMask_Equal(t *testing.T) {
 		t.Error("Expected nil mask to equal nil")
 	}
 }
+
+func TestAccessControlMask_SetNamespace(t *testing.T) {
+	acm := &mask.AccessControlMask{}
+	if _, err := acm.Unmarshal([]byte{0xa9, 0x00, 0x12, 0x00}); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if acm.String() != "DS_CREATE_CHILD|DS_LIST_OBJECT|DS_WRITE_PROPERTY|DS_WRITE_PROPERTY_EXTENDED|READ_CONTROL" {
+		t.Errorf("String() = %q with the default namespace", acm.String())
+	}
+
+	acm.SetNamespace(rights.RIGHTS_NAMESPACE_FILE)
+	if acm.String() != "FILE_GENERIC_EXECUTE|FILE_GENERIC_READ" {
+		t.Errorf("String() = %q with the file namespace", acm.String())
+	}
+	if acm.RawValue != 0x001200a9 {
+		t.Errorf("SetNamespace() changed RawValue to 0x%08x", acm.RawValue)
+	}
+
+	// Unmarshal uses the namespace already set on the mask
+	registryMask := &mask.AccessControlMask{Namespace: rights.RIGHTS_NAMESPACE_REGISTRY_KEY}
+	if _, err := registryMask.Unmarshal([]byte{0x3f, 0x00, 0x0f, 0x00}); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if registryMask.String() != "KEY_ALL_ACCESS" {
+		t.Errorf("String() = %q with the registry key namespace", registryMask.String())
+	}
+
+	registryMask.ClearRights()
+	registryMask.AddRight(0x00000001)
+	if registryMask.String() != "KEY_QUERY_VALUE" {
+		t.Errorf("AddRight() named the right %q", registryMask.String())
+	}
+}
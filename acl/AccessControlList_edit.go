@@ -0,0 +1,649 @@
+package acl
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/ace/aceflags"
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/acl/revision"
+	"github.com/TheManticoreProject/winacl/guid"
+	"github.com/TheManticoreProject/winacl/object/flags"
+	"github.com/TheManticoreProject/winacl/sid"
+)
+
+// AccessControlType is whether an access rule of the high-level edit operations of the DACL
+// allows or denies access, like the AccessControlType enumeration of .NET.
+type AccessControlType uint8
+
+const (
+	// ACCESS_CONTROL_TYPE_ALLOW edits the access allowed ACEs.
+	ACCESS_CONTROL_TYPE_ALLOW AccessControlType = iota
+	// ACCESS_CONTROL_TYPE_DENY edits the access denied ACEs.
+	ACCESS_CONTROL_TYPE_DENY
+)
+
+// AccessControlTypeNames maps the access control types to their names.
+var AccessControlTypeNames = map[AccessControlType]string{
+	ACCESS_CONTROL_TYPE_ALLOW: "ALLOW",
+	ACCESS_CONTROL_TYPE_DENY:  "DENY",
+}
+
+// String returns the name of the access control type.
+//
+// Returns:
+//   - string: The name of the access control type, or "?" if it is unknown.
+func (accessType AccessControlType) String() string {
+	if name, exists := AccessControlTypeNames[accessType]; exists {
+		return name
+	}
+	return "?"
+}
+
+// INHERITANCE_FLAGS are the ACE flags an AccessRule can hold.
+const INHERITANCE_FLAGS = aceflags.ACE_FLAG_OBJECT_INHERIT | aceflags.ACE_FLAG_CONTAINER_INHERIT | aceflags.ACE_FLAG_NO_PROPAGATE_INHERIT | aceflags.ACE_FLAG_INHERIT_ONLY
+
+// AccessRule is the access of a trustee edited by the high-level edit operations of the ACLs,
+// like AddAccess or AddAudit. It holds the parameters of the methods of the DiscretionaryAcl and
+// SystemAcl classes of .NET.
+//
+// Attributes:
+//   - SID (sid.SID): The trustee.
+//   - Mask (uint32): The access mask. Generic rights are kept as they are.
+//   - Flags (uint8): The inheritance flags, a combination of ACE_FLAG_OBJECT_INHERIT,
+//     ACE_FLAG_CONTAINER_INHERIT, ACE_FLAG_NO_PROPAGATE_INHERIT and ACE_FLAG_INHERIT_ONLY.
+//     ACE_FLAG_NO_PROPAGATE_INHERIT is ignored without inheritance, like in .NET.
+//   - ObjectType (*guid.GUID): The object type of an object ACE, nil for none.
+//   - InheritedObjectType (*guid.GUID): The inherited object type of an object ACE, nil for none.
+type AccessRule struct {
+	SID                 sid.SID
+	Mask                uint32
+	Flags               uint8
+	ObjectType          *guid.GUID
+	InheritedObjectType *guid.GUID
+}
+
+// The parts of the objects an ACE applies to, depending on its inheritance flags. Together with
+// the audit flags of audit ACEs, they allow splitting an ACE when removing access from some of
+// them only.
+const (
+	inheritanceTargetSelf       uint8 = 1 << 0
+	inheritanceTargetContainers uint8 = 1 << 1
+	inheritanceTargetObjects    uint8 = 1 << 2
+)
+
+// editRule is an AccessRule resolved for an ACL: the ACE type it edits, and where it applies.
+type editRule struct {
+	aceType     uint8
+	rule        AccessRule
+	trustee     string
+	targets     uint8
+	noPropagate bool
+	auditFlags  uint8
+}
+
+// AddAccess allows or denies access to a trustee, like DiscretionaryAcl.AddAccess in .NET. The
+// rights are merged into an explicit ACE of the same type and trustee applying to the same
+// objects, or the objects are merged into an ACE with the same rights. Otherwise a new ACE is
+// inserted at its canonical position: after the explicit deny ACEs for a deny ACE, after the
+// explicit allow ACEs for an allow ACE, and before the inherited ACEs.
+//
+// Parameters:
+//   - accessType (AccessControlType): Whether the access is allowed or denied.
+//   - rule (AccessRule): The trustee, rights, inheritance flags and object types.
+//
+// Returns:
+//   - error: An error if the rule is invalid or the DACL is not in canonical order.
+func (dacl *DiscretionaryAccessControlList) AddAccess(accessType AccessControlType, rule AccessRule) error {
+	resolved, err := newAccessEditRule(accessType, rule, true)
+	if err != nil {
+		return err
+	}
+	if err := dacl.checkCanonical(); err != nil {
+		return err
+	}
+	dacl.setEntries(addEditRule(dacl.Entries, resolved, daclInsertIndex))
+	dacl.upgradeRevision(resolved)
+	return nil
+}
+
+// SetAccess replaces the access allowed or denied to a trustee, like DiscretionaryAcl.SetAccess
+// in .NET: the explicit ACEs of the same type, trustee and object types are removed whatever
+// their inheritance flags, then the access is added like with AddAccess.
+//
+// Parameters:
+//   - accessType (AccessControlType): Whether the access is allowed or denied.
+//   - rule (AccessRule): The trustee, rights, inheritance flags and object types.
+//
+// Returns:
+//   - error: An error if the rule is invalid or the DACL is not in canonical order.
+func (dacl *DiscretionaryAccessControlList) SetAccess(accessType AccessControlType, rule AccessRule) error {
+	resolved, err := newAccessEditRule(accessType, rule, true)
+	if err != nil {
+		return err
+	}
+	if err := dacl.checkCanonical(); err != nil {
+		return err
+	}
+	entries := slices.DeleteFunc(slices.Clone(dacl.Entries), func(entry ace.AccessControlEntry) bool {
+		return resolved.matches(&entry)
+	})
+	dacl.setEntries(addEditRule(entries, resolved, daclInsertIndex))
+	dacl.upgradeRevision(resolved)
+	return nil
+}
+
+// RemoveAccess removes rights from the access allowed or denied to a trustee, like
+// DiscretionaryAcl.RemoveAccess in .NET. The rights are removed from the explicit ACEs of the same
+// type, trustee and object types, for the objects selected by the inheritance flags of the rule
+// only: an ACE that also applies to other objects is split, so that their access is kept. The
+// ACEs left without rights are removed.
+//
+// Parameters:
+//   - accessType (AccessControlType): Whether allowed or denied access is removed.
+//   - rule (AccessRule): The trustee, rights, inheritance flags and object types.
+//
+// Returns:
+//   - error: An error if the rule is invalid.
+func (dacl *DiscretionaryAccessControlList) RemoveAccess(accessType AccessControlType, rule AccessRule) error {
+	resolved, err := newAccessEditRule(accessType, rule, false)
+	if err != nil {
+		return err
+	}
+	dacl.setEntries(removeEditRule(dacl.Entries, resolved))
+	return nil
+}
+
+// RemoveAccessSpecific removes the explicit ACEs matching exactly an access rule, like
+// DiscretionaryAcl.RemoveAccessSpecific in .NET: same type, trustee, rights, inheritance flags and
+// object types. ACEs that only partially match are left untouched.
+//
+// Parameters:
+//   - accessType (AccessControlType): Whether an allow or a deny ACE is removed.
+//   - rule (AccessRule): The trustee, rights, inheritance flags and object types.
+//
+// Returns:
+//   - error: An error if the rule is invalid.
+func (dacl *DiscretionaryAccessControlList) RemoveAccessSpecific(accessType AccessControlType, rule AccessRule) error {
+	resolved, err := newAccessEditRule(accessType, rule, false)
+	if err != nil {
+		return err
+	}
+	dacl.setEntries(removeEditRuleSpecific(dacl.Entries, resolved))
+	return nil
+}
+
+// PurgeAccessControl removes all the explicit ACEs of a trustee from the DACL, like
+// DiscretionaryAcl.Purge in .NET. Inherited ACEs are kept.
+//
+// Parameters:
+//   - trustee (*sid.SID): The trustee whose ACEs are removed.
+func (dacl *DiscretionaryAccessControlList) PurgeAccessControl(trustee *sid.SID) {
+	dacl.setEntries(purgeTrustee(dacl.Entries, trustee))
+}
+
+// AddAudit audits the access of a trustee, like SystemAcl.AddAudit in .NET. The rights are merged
+// into an explicit audit ACE of the same trustee with the same audit flags applying to the same
+// objects, or the objects or the audit flags are merged into an ACE with the same rights.
+// Otherwise a new ACE is inserted after the explicit ACEs, before the inherited ACEs.
+//
+// Parameters:
+//   - auditFlags (uint8): The accesses to audit, a combination of aceflags.ACE_FLAG_SUCCESSFUL_ACCESS
+//     and aceflags.ACE_FLAG_FAILED_ACCESS.
+//   - rule (AccessRule): The trustee, rights, inheritance flags and object types.
+//
+// Returns:
+//   - error: An error if the audit flags or the rule are invalid.
+func (sacl *SystemAccessControlList) AddAudit(auditFlags uint8, rule AccessRule) error {
+	resolved, err := newAuditEditRule(auditFlags, rule, true)
+	if err != nil {
+		return err
+	}
+	sacl.setEntries(addEditRule(sacl.Entries, resolved, saclInsertIndex))
+	sacl.upgradeRevision(resolved)
+	return nil
+}
+
+// SetAudit replaces the auditing of the access of a trustee, like SystemAcl.SetAudit in .NET: the
+// explicit audit ACEs of the same trustee and object types are removed, then the auditing is added
+// like with AddAudit.
+//
+// Parameters:
+//   - auditFlags (uint8): The accesses to audit, a combination of aceflags.ACE_FLAG_SUCCESSFUL_ACCESS
+//     and aceflags.ACE_FLAG_FAILED_ACCESS.
+//   - rule (AccessRule): The trustee, rights, inheritance flags and object types.
+//
+// Returns:
+//   - error: An error if the audit flags or the rule are invalid.
+func (sacl *SystemAccessControlList) SetAudit(auditFlags uint8, rule AccessRule) error {
+	resolved, err := newAuditEditRule(auditFlags, rule, true)
+	if err != nil {
+		return err
+	}
+	entries := slices.DeleteFunc(slices.Clone(sacl.Entries), func(entry ace.AccessControlEntry) bool {
+		return resolved.matches(&entry)
+	})
+	sacl.setEntries(addEditRule(entries, resolved, saclInsertIndex))
+	sacl.upgradeRevision(resolved)
+	return nil
+}
+
+// RemoveAudit stops auditing rights of a trustee, like SystemAcl.RemoveAudit in .NET. The rights
+// are removed from the explicit audit ACEs of the same trustee and object types, for the audit
+// flags and the objects selected by the rule only, splitting the ACEs that also apply to others.
+//
+// Parameters:
+//   - auditFlags (uint8): The accesses to stop auditing, a combination of
+//     aceflags.ACE_FLAG_SUCCESSFUL_ACCESS and aceflags.ACE_FLAG_FAILED_ACCESS.
+//   - rule (AccessRule): The trustee, rights, inheritance flags and object types.
+//
+// Returns:
+//   - error: An error if the audit flags or the rule are invalid.
+func (sacl *SystemAccessControlList) RemoveAudit(auditFlags uint8, rule AccessRule) error {
+	resolved, err := newAuditEditRule(auditFlags, rule, false)
+	if err != nil {
+		return err
+	}
+	sacl.setEntries(removeEditRule(sacl.Entries, resolved))
+	return nil
+}
+
+// RemoveAuditSpecific removes the explicit audit ACEs matching exactly an audit rule, like
+// SystemAcl.RemoveAuditSpecific in .NET.
+//
+// Parameters:
+//   - auditFlags (uint8): The audit flags of the ACE, a combination of
+//     aceflags.ACE_FLAG_SUCCESSFUL_ACCESS and aceflags.ACE_FLAG_FAILED_ACCESS.
+//   - rule (AccessRule): The trustee, rights, inheritance flags and object types.
+//
+// Returns:
+//   - error: An error if the audit flags or the rule are invalid.
+func (sacl *SystemAccessControlList) RemoveAuditSpecific(auditFlags uint8, rule AccessRule) error {
+	resolved, err := newAuditEditRule(auditFlags, rule, false)
+	if err != nil {
+		return err
+	}
+	sacl.setEntries(removeEditRuleSpecific(sacl.Entries, resolved))
+	return nil
+}
+
+// PurgeAudit removes all the explicit ACEs of a trustee from the SACL, like SystemAcl.Purge in
+// .NET. Inherited ACEs are kept.
+//
+// Parameters:
+//   - trustee (*sid.SID): The trustee whose ACEs are removed.
+func (sacl *SystemAccessControlList) PurgeAudit(trustee *sid.SID) {
+	sacl.setEntries(purgeTrustee(sacl.Entries, trustee))
+}
+
+// checkCanonical checks that the DACL is in canonical order, which the high-level edit operations
+// adding ACEs need to find where to insert them.
+//
+// Returns:
+//   - error: An error if an allow or deny ACE is out of canonical order.
+func (dacl *DiscretionaryAccessControlList) checkCanonical() error {
+	maxRank := 0
+	for index := range dacl.Entries {
+		entry := &dacl.Entries[index]
+		if !entry.IsAccessAllowedOrDenied() {
+			continue
+		}
+		rank := canonicalRank(entry)
+		if rank < maxRank {
+			return fmt.Errorf("DACL is not in canonical order: %s ACE #%d comes after the %s entries", canonicalRankNames[rank], index, canonicalRankNames[maxRank])
+		}
+		maxRank = rank
+	}
+	return nil
+}
+
+// setEntries replaces the entries of the DACL, updating their indexes and the ACE count.
+func (dacl *DiscretionaryAccessControlList) setEntries(entries []ace.AccessControlEntry) {
+	for index := range entries {
+		entries[index].Index = uint16(index + 1)
+	}
+	dacl.Entries = entries
+	dacl.Header.AceCount = uint16(len(entries))
+}
+
+// upgradeRevision sets the revision of the DACL to ACL_REVISION_DS when an object ACE was
+// added, and to ACL_REVISION if the DACL had none.
+func (dacl *DiscretionaryAccessControlList) upgradeRevision(resolved *editRule) {
+	dacl.Header.Revision.Value = editedRevision(dacl.Header.Revision.Value, resolved)
+}
+
+// setEntries replaces the entries of the SACL, updating their indexes and the ACE count.
+func (sacl *SystemAccessControlList) setEntries(entries []ace.AccessControlEntry) {
+	for index := range entries {
+		entries[index].Index = uint16(index + 1)
+	}
+	sacl.Entries = entries
+	sacl.Header.AceCount = uint16(len(entries))
+}
+
+// upgradeRevision sets the revision of the SACL to ACL_REVISION_DS when an object ACE was
+// added, and to ACL_REVISION if the SACL had none.
+func (sacl *SystemAccessControlList) upgradeRevision(resolved *editRule) {
+	sacl.Header.Revision.Value = editedRevision(sacl.Header.Revision.Value, resolved)
+}
+
+// editedRevision returns the revision of an ACL after an ACE was added for a rule.
+func editedRevision(current uint8, resolved *editRule) uint8 {
+	if resolved.isObject() {
+		return revision.ACL_REVISION_DS
+	}
+	if current == 0 {
+		return revision.ACL_REVISION
+	}
+	return current
+}
+
+// newAccessEditRule resolves an access rule for the DACL edit operations.
+//
+// Parameters:
+//   - accessType (AccessControlType): Whether the access is allowed or denied.
+//   - rule (AccessRule): The access rule.
+//   - adding (bool): Whether the rule adds access, which requires a non-empty mask.
+//
+// Returns:
+//   - *editRule: The resolved rule.
+//   - error: An error if the access type or the rule is invalid.
+func newAccessEditRule(accessType AccessControlType, rule AccessRule, adding bool) (*editRule, error) {
+	objectRule := rule.ObjectType != nil || rule.InheritedObjectType != nil
+	resolved := &editRule{rule: rule}
+	switch {
+	case accessType == ACCESS_CONTROL_TYPE_ALLOW && objectRule:
+		resolved.aceType = acetype.ACE_TYPE_ACCESS_ALLOWED_OBJECT
+	case accessType == ACCESS_CONTROL_TYPE_ALLOW:
+		resolved.aceType = acetype.ACE_TYPE_ACCESS_ALLOWED
+	case accessType == ACCESS_CONTROL_TYPE_DENY && objectRule:
+		resolved.aceType = acetype.ACE_TYPE_ACCESS_DENIED_OBJECT
+	case accessType == ACCESS_CONTROL_TYPE_DENY:
+		resolved.aceType = acetype.ACE_TYPE_ACCESS_DENIED
+	default:
+		return nil, fmt.Errorf("invalid access control type %d", accessType)
+	}
+	return resolved, resolved.resolveRule(adding)
+}
+
+// newAuditEditRule resolves an access rule for the SACL edit operations.
+//
+// Parameters:
+//   - auditFlags (uint8): The audit flags of the rule.
+//   - rule (AccessRule): The access rule.
+//   - adding (bool): Whether the rule adds auditing, which requires a non-empty mask.
+//
+// Returns:
+//   - *editRule: The resolved rule.
+//   - error: An error if the audit flags or the rule are invalid.
+func newAuditEditRule(auditFlags uint8, rule AccessRule, adding bool) (*editRule, error) {
+	if auditFlags == 0 || auditFlags&^aceflags.ACE_FLAG_AUDIT_FLAGS != 0 {
+		return nil, fmt.Errorf("invalid audit flags 0x%02x, expected a combination of SUCCESSFUL_ACCESS and FAILED_ACCESS", auditFlags)
+	}
+	resolved := &editRule{rule: rule, auditFlags: auditFlags, aceType: acetype.ACE_TYPE_SYSTEM_AUDIT}
+	if rule.ObjectType != nil || rule.InheritedObjectType != nil {
+		resolved.aceType = acetype.ACE_TYPE_SYSTEM_AUDIT_OBJECT
+	}
+	return resolved, resolved.resolveRule(adding)
+}
+
+// resolveRule checks the access rule and computes the objects it applies to.
+func (resolved *editRule) resolveRule(adding bool) error {
+	rule := resolved.rule
+	if rule.SID.RevisionLevel == 0 {
+		return fmt.Errorf("invalid access rule: the SID of the trustee is not set")
+	}
+	if adding && rule.Mask == 0 {
+		return fmt.Errorf("invalid access rule: the access mask is empty")
+	}
+	if rule.Flags&^INHERITANCE_FLAGS != 0 {
+		return fmt.Errorf("invalid access rule: flags 0x%02x are not inheritance flags", rule.Flags&^INHERITANCE_FLAGS)
+	}
+	resolved.trustee = rule.SID.ToString()
+	resolved.targets, resolved.noPropagate = inheritanceTargets(rule.Flags)
+	if resolved.targets == 0 {
+		return fmt.Errorf("invalid access rule: INHERIT_ONLY requires OBJECT_INHERIT or CONTAINER_INHERIT")
+	}
+	return nil
+}
+
+// isObject checks if the rule edits object ACEs.
+func (resolved *editRule) isObject() bool {
+	return resolved.rule.ObjectType != nil || resolved.rule.InheritedObjectType != nil
+}
+
+// matches checks if an ACE is an explicit ACE edited by the rule: same type, trustee and object
+// types. Inherited ACEs and ACEs holding a condition or application data are never edited.
+func (resolved *editRule) matches(entry *ace.AccessControlEntry) bool {
+	if entry.Header.Type.Value != resolved.aceType || entry.Opaque || len(entry.ApplicationData) != 0 {
+		return false
+	}
+	if entry.HasFlag(aceflags.ACE_FLAG_INHERITED) || entry.Identity.SID.ToString() != resolved.trustee {
+		return false
+	}
+	if !resolved.isObject() {
+		return true
+	}
+	objectType := &entry.AccessControlObjectType
+	return sameObjectType(objectType.Flags.IsObjectTypePresent(), &objectType.ObjectType.GUID, resolved.rule.ObjectType) &&
+		sameObjectType(objectType.Flags.IsInheritedObjectTypePresent(), &objectType.InheritedObjectType.GUID, resolved.rule.InheritedObjectType)
+}
+
+// sameObjectType compares an object type of an ACE with the one of a rule, nil for none.
+func sameObjectType(present bool, entryGUID *guid.GUID, ruleGUID *guid.GUID) bool {
+	if !present || ruleGUID == nil {
+		return !present && ruleGUID == nil
+	}
+	return entryGUID.Equal(ruleGUID)
+}
+
+// newEntry creates the ACE of the rule for a mask, objects and audit flags.
+func (resolved *editRule) newEntry(mask uint32, targets uint8, auditFlags uint8) ace.AccessControlEntry {
+	entry := ace.AccessControlEntry{}
+	entry.Header.Type.Value = resolved.aceType
+	setEntryRegion(&entry, targets, resolved.noPropagate, auditFlags)
+	setEntryMask(&entry, mask)
+	entry.Identity.SID = *resolved.rule.SID.Clone()
+	entry.Identity.Name = entry.Identity.SID.LookupName()
+	if resolved.rule.ObjectType != nil {
+		entry.AccessControlObjectType.ObjectType.GUID = *resolved.rule.ObjectType
+		entry.AccessControlObjectType.Flags.Value |= flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT
+	}
+	if resolved.rule.InheritedObjectType != nil {
+		entry.AccessControlObjectType.InheritedObjectType.GUID = *resolved.rule.InheritedObjectType
+		entry.AccessControlObjectType.Flags.Value |= flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT
+	}
+	return entry
+}
+
+// inheritanceTargets returns the objects an ACE with the given flags applies to, and whether its
+// inheritance is limited to the direct children. NO_PROPAGATE_INHERIT is ignored without
+// inheritance.
+func inheritanceTargets(aceFlags uint8) (uint8, bool) {
+	targets := uint8(0)
+	if aceFlags&aceflags.ACE_FLAG_INHERIT_ONLY == 0 {
+		targets |= inheritanceTargetSelf
+	}
+	if aceFlags&aceflags.ACE_FLAG_CONTAINER_INHERIT != 0 {
+		targets |= inheritanceTargetContainers
+	}
+	if aceFlags&aceflags.ACE_FLAG_OBJECT_INHERIT != 0 {
+		targets |= inheritanceTargetObjects
+	}
+	noPropagate := aceFlags&aceflags.ACE_FLAG_NO_PROPAGATE_INHERIT != 0 && targets&^inheritanceTargetSelf != 0
+	return targets, noPropagate
+}
+
+// setEntryRegion sets the flags of an ACE so that it applies to the given objects, with the
+// given audit flags.
+func setEntryRegion(entry *ace.AccessControlEntry, targets uint8, noPropagate bool, auditFlags uint8) {
+	aceFlags := auditFlags
+	if targets&inheritanceTargetSelf == 0 {
+		aceFlags |= aceflags.ACE_FLAG_INHERIT_ONLY
+	}
+	if targets&inheritanceTargetContainers != 0 {
+		aceFlags |= aceflags.ACE_FLAG_CONTAINER_INHERIT
+	}
+	if targets&inheritanceTargetObjects != 0 {
+		aceFlags |= aceflags.ACE_FLAG_OBJECT_INHERIT
+	}
+	if noPropagate && targets&^inheritanceTargetSelf != 0 {
+		aceFlags |= aceflags.ACE_FLAG_NO_PROPAGATE_INHERIT
+	}
+	entry.Header.Flags.Unmarshal([]byte{aceFlags})
+}
+
+// setEntryMask sets the access mask of an ACE, decoding its rights in the namespace of the mask.
+func setEntryMask(entry *ace.AccessControlEntry, mask uint32) {
+	entry.Mask.RawValue = mask
+	entry.Mask.SetNamespace(entry.Mask.Namespace)
+}
+
+// entryRegion returns the objects an ACE applies to, whether its inheritance is limited to the
+// direct children, and its audit flags.
+func entryRegion(entry *ace.AccessControlEntry) (uint8, bool, uint8) {
+	targets, noPropagate := inheritanceTargets(entry.Header.Flags.RawValue)
+	return targets, noPropagate, entry.Header.Flags.RawValue & aceflags.ACE_FLAG_AUDIT_FLAGS
+}
+
+// addEditRule adds the access of a rule to a list of ACEs, merging it into a compatible ACE when
+// possible.
+//
+// Parameters:
+//   - entries ([]ace.AccessControlEntry): The ACEs of the ACL.
+//   - resolved (*editRule): The rule to add.
+//   - insertIndex (func([]ace.AccessControlEntry, uint8) int): Where to insert a new ACE of a type.
+//
+// Returns:
+//   - []ace.AccessControlEntry: The new ACEs of the ACL.
+func addEditRule(entries []ace.AccessControlEntry, resolved *editRule, insertIndex func([]ace.AccessControlEntry, uint8) int) []ace.AccessControlEntry {
+	entries = slices.Clone(entries)
+
+	for index := range entries {
+		entry := &entries[index]
+		if !resolved.matches(entry) {
+			continue
+		}
+		targets, noPropagate, auditFlags := entryRegion(entry)
+		if noPropagate != resolved.noPropagate {
+			continue
+		}
+		switch {
+		case targets == resolved.targets && auditFlags == resolved.auditFlags:
+			setEntryMask(entry, entry.Mask.RawValue|resolved.rule.Mask)
+			return entries
+		case entry.Mask.RawValue == resolved.rule.Mask && auditFlags == resolved.auditFlags:
+			setEntryRegion(entry, targets|resolved.targets, noPropagate, auditFlags)
+			return entries
+		case entry.Mask.RawValue == resolved.rule.Mask && targets == resolved.targets:
+			setEntryRegion(entry, targets, noPropagate, auditFlags|resolved.auditFlags)
+			return entries
+		}
+	}
+
+	newEntry := resolved.newEntry(resolved.rule.Mask, resolved.targets, resolved.auditFlags)
+	return slices.Insert(entries, insertIndex(entries, resolved.aceType), newEntry)
+}
+
+// removeEditRule removes the access of a rule from a list of ACEs, splitting the ACEs that apply
+// to other objects or audit flags than the rule.
+//
+// Parameters:
+//   - entries ([]ace.AccessControlEntry): The ACEs of the ACL.
+//   - resolved (*editRule): The rule to remove.
+//
+// Returns:
+//   - []ace.AccessControlEntry: The new ACEs of the ACL.
+func removeEditRule(entries []ace.AccessControlEntry, resolved *editRule) []ace.AccessControlEntry {
+	newEntries := make([]ace.AccessControlEntry, 0, len(entries))
+
+	for index := range entries {
+		entry := &entries[index]
+		if !resolved.matches(entry) || entry.Mask.RawValue&resolved.rule.Mask == 0 {
+			newEntries = append(newEntries, *entry)
+			continue
+		}
+
+		targets, noPropagate, auditFlags := entryRegion(entry)
+		// Inherited copies only match if they propagate the same way
+		ruleTargets := resolved.targets
+		if noPropagate != resolved.noPropagate {
+			ruleTargets &= inheritanceTargetSelf
+		}
+		commonTargets := targets & ruleTargets
+		commonAuditFlags := auditFlags
+		if resolved.auditFlags != 0 {
+			commonAuditFlags &= resolved.auditFlags
+		}
+		if commonTargets == 0 || (auditFlags != 0 && commonAuditFlags == 0) {
+			newEntries = append(newEntries, *entry)
+			continue
+		}
+
+		// The ACE is split into the objects the rule does not apply to, the audit flags it does not
+		// apply to, and the rights left where it applies
+		if otherTargets := targets &^ commonTargets; otherTargets != 0 {
+			part := *entry.Clone()
+			setEntryRegion(&part, otherTargets, noPropagate, auditFlags)
+			newEntries = append(newEntries, part)
+		}
+		if otherAuditFlags := auditFlags &^ commonAuditFlags; otherAuditFlags != 0 {
+			part := *entry.Clone()
+			setEntryRegion(&part, commonTargets, noPropagate, otherAuditFlags)
+			newEntries = append(newEntries, part)
+		}
+		if remainingMask := entry.Mask.RawValue &^ resolved.rule.Mask; remainingMask != 0 {
+			part := *entry.Clone()
+			setEntryRegion(&part, commonTargets, noPropagate, commonAuditFlags)
+			setEntryMask(&part, remainingMask)
+			newEntries = append(newEntries, part)
+		}
+	}
+
+	return newEntries
+}
+
+// removeEditRuleSpecific removes the ACEs matching exactly a rule from a list of ACEs.
+func removeEditRuleSpecific(entries []ace.AccessControlEntry, resolved *editRule) []ace.AccessControlEntry {
+	return slices.DeleteFunc(slices.Clone(entries), func(entry ace.AccessControlEntry) bool {
+		targets, noPropagate, auditFlags := entryRegion(&entry)
+		return resolved.matches(&entry) && entry.Mask.RawValue == resolved.rule.Mask &&
+			targets == resolved.targets && noPropagate == resolved.noPropagate && auditFlags == resolved.auditFlags
+	})
+}
+
+// purgeTrustee removes the explicit ACEs of a trustee from a list of ACEs.
+func purgeTrustee(entries []ace.AccessControlEntry, trustee *sid.SID) []ace.AccessControlEntry {
+	trusteeString := trustee.ToString()
+	return slices.DeleteFunc(slices.Clone(entries), func(entry ace.AccessControlEntry) bool {
+		return !entry.HasFlag(aceflags.ACE_FLAG_INHERITED) && entry.Identity.SID.ToString() == trusteeString
+	})
+}
+
+// daclInsertIndex returns the canonical position of a new explicit ACE in a canonical DACL: after
+// the explicit deny ACEs for a deny ACE, after the explicit allow ACEs for an allow ACE.
+func daclInsertIndex(entries []ace.AccessControlEntry, aceType uint8) int {
+	rank := 1
+	if aceType == acetype.ACE_TYPE_ACCESS_DENIED || aceType == acetype.ACE_TYPE_ACCESS_DENIED_OBJECT {
+		rank = 0
+	}
+	insertIndex := 0
+	for index := range entries {
+		if canonicalRank(&entries[index]) <= rank {
+			insertIndex = index + 1
+		}
+	}
+	return insertIndex
+}
+
+// saclInsertIndex returns the position of a new explicit ACE in a SACL: after the explicit ACEs.
+func saclInsertIndex(entries []ace.AccessControlEntry, aceType uint8) int {
+	insertIndex := 0
+	for index := range entries {
+		if !entries[index].HasFlag(aceflags.ACE_FLAG_INHERITED) {
+			insertIndex = index + 1
+		}
+	}
+	return insertIndex
+}
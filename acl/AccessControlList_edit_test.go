@@ -0,0 +1,284 @@
+package acl_test
+
+import (
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/ace/aceflags"
+	"github.com/TheManticoreProject/winacl/acl"
+	"github.com/TheManticoreProject/winacl/acl/revision"
+	"github.com/TheManticoreProject/winacl/rights"
+	"github.com/TheManticoreProject/winacl/sid"
+)
+
+// editTestSID parses a SID for the edit tests.
+func editTestSID(t *testing.T, value string) sid.SID {
+	t.Helper()
+	parsed := sid.SID{}
+	if err := parsed.FromString(value); err != nil {
+		t.Fatalf("FromString(%q) error = %v", value, err)
+	}
+	return parsed
+}
+
+func TestDACL_EditAccess(t *testing.T) {
+	const (
+		users      = "S-1-5-32-545"
+		admins     = "S-1-5-32-544"
+		anonymous  = "S-1-5-7"
+		genericAll = rights.RIGHT_GENERIC_ALL
+		read       = rights.RIGHT_GENERIC_READ
+		write      = rights.RIGHT_GENERIC_WRITE
+		execute    = rights.RIGHT_GENERIC_EXECUTE
+	)
+
+	tests := []struct {
+		name   string
+		sddl   string
+		edit   func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error
+		want   string
+		hasErr bool
+	}{
+		{
+			name: "AddAccessAllowInsertedBeforeInherited",
+			sddl: "D:(D;;GA;;;BG)(A;;GA;;;BA)(A;ID;GA;;;SY)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				return dacl.AddAccess(acl.ACCESS_CONTROL_TYPE_ALLOW, acl.AccessRule{SID: editTestSID(t, users), Mask: read})
+			},
+			want: "D:(D;;GA;;;BG)(A;;GA;;;BA)(A;;GR;;;BU)(A;ID;GA;;;SY)",
+		},
+		{
+			name: "AddAccessDenyInsertedAfterDenies",
+			sddl: "D:(D;;GA;;;BG)(A;;GA;;;BA)(A;ID;GA;;;SY)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				return dacl.AddAccess(acl.ACCESS_CONTROL_TYPE_DENY, acl.AccessRule{SID: editTestSID(t, anonymous), Mask: write})
+			},
+			want: "D:(D;;GA;;;BG)(D;;GW;;;AN)(A;;GA;;;BA)(A;ID;GA;;;SY)",
+		},
+		{
+			name: "AddAccessMergesMask",
+			sddl: "D:(A;;GW;;;BU)(A;;GA;;;BA)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				return dacl.AddAccess(acl.ACCESS_CONTROL_TYPE_ALLOW, acl.AccessRule{SID: editTestSID(t, users), Mask: read})
+			},
+			want: "D:(A;;GRGW;;;BU)(A;;GA;;;BA)",
+		},
+		{
+			name: "AddAccessMergesInheritance",
+			sddl: "D:(A;OI;GR;;;BU)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				return dacl.AddAccess(acl.ACCESS_CONTROL_TYPE_ALLOW, acl.AccessRule{SID: editTestSID(t, users), Mask: read, Flags: aceflags.ACE_FLAG_CONTAINER_INHERIT})
+			},
+			want: "D:(A;CIOI;GR;;;BU)",
+		},
+		{
+			name: "AddAccessDoesNotMergeInherited",
+			sddl: "D:(A;ID;GR;;;BU)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				return dacl.AddAccess(acl.ACCESS_CONTROL_TYPE_ALLOW, acl.AccessRule{SID: editTestSID(t, users), Mask: write})
+			},
+			want: "D:(A;;GW;;;BU)(A;ID;GR;;;BU)",
+		},
+		{
+			name: "AddAccessObject",
+			sddl: "D:(A;;GA;;;BA)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				objectType := guidFromString(t, "00299570-246d-11d0-a768-00aa006e0529")
+				if err := dacl.AddAccess(acl.ACCESS_CONTROL_TYPE_ALLOW, acl.AccessRule{SID: editTestSID(t, users), Mask: rights.RIGHT_DS_CONTROL_ACCESS, ObjectType: objectType}); err != nil {
+					return err
+				}
+				if dacl.Header.Revision.Value != revision.ACL_REVISION_DS {
+					t.Errorf("Revision = 0x%02x, want ACL_REVISION_DS", dacl.Header.Revision.Value)
+				}
+				return nil
+			},
+			want: "D:(A;;GA;;;BA)(OA;;CR;00299570-246d-11d0-a768-00aa006e0529;;BU)",
+		},
+		{
+			name: "AddAccessNotCanonical",
+			sddl: "D:(A;;GA;;;BA)(D;;GA;;;BG)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				return dacl.AddAccess(acl.ACCESS_CONTROL_TYPE_ALLOW, acl.AccessRule{SID: editTestSID(t, users), Mask: read})
+			},
+			want:   "D:(A;;GA;;;BA)(D;;GA;;;BG)",
+			hasErr: true,
+		},
+		{
+			name: "AddAccessInheritOnlyWithoutInheritance",
+			sddl: "D:(A;;GA;;;BA)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				return dacl.AddAccess(acl.ACCESS_CONTROL_TYPE_ALLOW, acl.AccessRule{SID: editTestSID(t, users), Mask: read, Flags: aceflags.ACE_FLAG_INHERIT_ONLY})
+			},
+			want:   "D:(A;;GA;;;BA)",
+			hasErr: true,
+		},
+		{
+			name: "AddAccessEmptyMask",
+			sddl: "D:(A;;GA;;;BA)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				return dacl.AddAccess(acl.ACCESS_CONTROL_TYPE_ALLOW, acl.AccessRule{SID: editTestSID(t, users)})
+			},
+			want:   "D:(A;;GA;;;BA)",
+			hasErr: true,
+		},
+		{
+			name: "SetAccessReplaces",
+			sddl: "D:(A;;GR;;;BU)(A;CI;GW;;;BU)(A;;GA;;;BA)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				return dacl.SetAccess(acl.ACCESS_CONTROL_TYPE_ALLOW, acl.AccessRule{SID: editTestSID(t, users), Mask: execute})
+			},
+			want: "D:(A;;GA;;;BA)(A;;GX;;;BU)",
+		},
+		{
+			name: "RemoveAccessReducesMask",
+			sddl: "D:(A;;GRGW;;;BU)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				return dacl.RemoveAccess(acl.ACCESS_CONTROL_TYPE_ALLOW, acl.AccessRule{SID: editTestSID(t, users), Mask: write})
+			},
+			want: "D:(A;;GR;;;BU)",
+		},
+		{
+			name: "RemoveAccessSplitsInheritance",
+			sddl: "D:(A;OICI;GRGW;;;BU)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				return dacl.RemoveAccess(acl.ACCESS_CONTROL_TYPE_ALLOW, acl.AccessRule{SID: editTestSID(t, users), Mask: write})
+			},
+			want: "D:(A;CIOIIO;GRGW;;;BU)(A;;GR;;;BU)",
+		},
+		{
+			name: "RemoveAccessAll",
+			sddl: "D:(A;;GR;;;BU)(A;;GA;;;BA)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				return dacl.RemoveAccess(acl.ACCESS_CONTROL_TYPE_ALLOW, acl.AccessRule{SID: editTestSID(t, users), Mask: genericAll | read})
+			},
+			want: "D:(A;;GA;;;BA)",
+		},
+		{
+			name: "RemoveAccessOtherType",
+			sddl: "D:(A;;GR;;;BU)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				return dacl.RemoveAccess(acl.ACCESS_CONTROL_TYPE_DENY, acl.AccessRule{SID: editTestSID(t, users), Mask: read})
+			},
+			want: "D:(A;;GR;;;BU)",
+		},
+		{
+			name: "RemoveAccessSpecificPartial",
+			sddl: "D:(A;;GRGW;;;BU)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				return dacl.RemoveAccessSpecific(acl.ACCESS_CONTROL_TYPE_ALLOW, acl.AccessRule{SID: editTestSID(t, users), Mask: read})
+			},
+			want: "D:(A;;GRGW;;;BU)",
+		},
+		{
+			name: "RemoveAccessSpecificExact",
+			sddl: "D:(A;CI;GR;;;BU)(A;;GR;;;BU)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				return dacl.RemoveAccessSpecific(acl.ACCESS_CONTROL_TYPE_ALLOW, acl.AccessRule{SID: editTestSID(t, users), Mask: read, Flags: aceflags.ACE_FLAG_CONTAINER_INHERIT})
+			},
+			want: "D:(A;;GR;;;BU)",
+		},
+		{
+			name: "PurgeAccessControlKeepsInherited",
+			sddl: "D:(D;;GW;;;BU)(A;;GR;;;BU)(A;;GA;;;BA)(A;ID;GX;;;BU)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				trustee := editTestSID(t, users)
+				dacl.PurgeAccessControl(&trustee)
+				return nil
+			},
+			want: "D:(A;;GA;;;BA)(A;ID;GX;;;BU)",
+		},
+		{
+			name: "AddAccessToAdminsAfterRemove",
+			sddl: "D:(A;;GA;;;BA)",
+			edit: func(dacl *acl.DiscretionaryAccessControlList, t *testing.T) error {
+				trustee := editTestSID(t, admins)
+				if err := dacl.RemoveAccess(acl.ACCESS_CONTROL_TYPE_ALLOW, acl.AccessRule{SID: trustee, Mask: genericAll}); err != nil {
+					return err
+				}
+				return dacl.AddAccess(acl.ACCESS_CONTROL_TYPE_ALLOW, acl.AccessRule{SID: trustee, Mask: read, Flags: aceflags.ACE_FLAG_OBJECT_INHERIT | aceflags.ACE_FLAG_CONTAINER_INHERIT})
+			},
+			want: "D:(A;CIOI;GR;;;BA)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ntsd := sddlTestDescriptor(t, tt.sddl)
+			err := tt.edit(ntsd.DACL, t)
+			if (err != nil) != tt.hasErr {
+				t.Fatalf("edit error = %v, hasErr %v", err, tt.hasErr)
+			}
+
+			got, err := ntsd.ToSDDLString()
+			if err != nil {
+				t.Fatalf("ToSDDLString() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("edited DACL = %s, want %s", got, tt.want)
+			}
+			if int(ntsd.DACL.Header.AceCount) != len(ntsd.DACL.Entries) {
+				t.Errorf("AceCount = %d, want %d", ntsd.DACL.Header.AceCount, len(ntsd.DACL.Entries))
+			}
+			if _, err := ntsd.Marshal(); err != nil {
+				t.Errorf("Marshal() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestSACL_EditAudit(t *testing.T) {
+	const everyone = "S-1-1-0"
+
+	ntsd := sddlTestDescriptor(t, "S:(AU;SA;GA;;;WD)(AU;IDFA;GR;;;BU)")
+	rule := acl.AccessRule{SID: editTestSID(t, everyone), Mask: rights.RIGHT_GENERIC_ALL}
+
+	steps := []struct {
+		name string
+		edit func() error
+		want string
+	}{
+		{
+			name: "AddAuditMergesAuditFlags",
+			edit: func() error { return ntsd.SACL.AddAudit(aceflags.ACE_FLAG_FAILED_ACCESS, rule) },
+			want: "S:(AU;SAFA;GA;;;WD)(AU;IDFA;GR;;;BU)",
+		},
+		{
+			name: "RemoveAuditSplitsAuditFlags",
+			edit: func() error {
+				return ntsd.SACL.RemoveAudit(aceflags.ACE_FLAG_SUCCESSFUL_ACCESS, acl.AccessRule{SID: rule.SID, Mask: rights.RIGHT_GENERIC_ALL})
+			},
+			want: "S:(AU;FA;GA;;;WD)(AU;IDFA;GR;;;BU)",
+		},
+		{
+			name: "AddAuditInsertedBeforeInherited",
+			edit: func() error {
+				return ntsd.SACL.AddAudit(aceflags.ACE_FLAG_SUCCESSFUL_ACCESS, acl.AccessRule{SID: rule.SID, Mask: rights.RIGHT_GENERIC_WRITE, Flags: aceflags.ACE_FLAG_CONTAINER_INHERIT})
+			},
+			want: "S:(AU;FA;GA;;;WD)(AU;CISA;GW;;;WD)(AU;IDFA;GR;;;BU)",
+		},
+		{
+			name: "PurgeAudit",
+			edit: func() error {
+				ntsd.SACL.PurgeAudit(&rule.SID)
+				return nil
+			},
+			want: "S:(AU;IDFA;GR;;;BU)",
+		},
+	}
+
+	for _, step := range steps {
+		if err := step.edit(); err != nil {
+			t.Fatalf("%s: error = %v", step.name, err)
+		}
+		got, err := ntsd.ToSDDLString()
+		if err != nil {
+			t.Fatalf("%s: ToSDDLString() error = %v", step.name, err)
+		}
+		if got != step.want {
+			t.Errorf("%s: edited SACL = %s, want %s", step.name, got, step.want)
+		}
+	}
+
+	if err := ntsd.SACL.AddAudit(0, rule); err == nil {
+		t.Errorf("AddAudit() without audit flags error = nil, want an error")
+	}
+}
@@ -0,0 +1,178 @@
+package acl
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/parsing"
+)
+
+// jsonAccessControlList is the JSON representation of a DACL or a SACL. The AclSize and the
+// AceCount of the header are not part of it, they are computed from the entries.
+type jsonAccessControlList struct {
+	Revision uint8                    `json:"revision"`
+	Sbz1     uint8                    `json:"sbz1,omitempty"`
+	Sbz2     uint16                   `json:"sbz2,omitempty"`
+	Entries  []ace.AccessControlEntry `json:"entries"`
+}
+
+// unmarshalJSONAccessControlList parses the JSON representation of an ACL.
+func unmarshalJSONAccessControlList(aclName string, data []byte) (*jsonAccessControlList, error) {
+	document := jsonAccessControlList{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid JSON %s: %w", aclName, err)
+	}
+	if document.Entries == nil {
+		document.Entries = []ace.AccessControlEntry{}
+	}
+	return &document, nil
+}
+
+// MarshalBinary returns the binary representation of the DACL, implementing
+// encoding.BinaryMarshaler. The DACL is left untouched, unlike with Marshal.
+//
+// Returns:
+//   - []byte: The binary representation of the DACL.
+//   - error: An error if the DACL cannot be serialized.
+func (dacl *DiscretionaryAccessControlList) MarshalBinary() ([]byte, error) {
+	// AppendBinary only writes the AclSize of the header, which is copied
+	layout := *dacl
+	return layout.AppendBinary(make([]byte, 0, dacl.Size()))
+}
+
+// UnmarshalBinary parses the binary representation of a DACL, implementing
+// encoding.BinaryUnmarshaler. The data is copied, so that the DACL does not reference it.
+// Like with Unmarshal, the bytes after the AceCount entries are ignored.
+//
+// Parameters:
+//   - data ([]byte): The binary representation of the DACL.
+//
+// Returns:
+//   - error: An error if the DACL cannot be parsed.
+func (dacl *DiscretionaryAccessControlList) UnmarshalBinary(data []byte) error {
+	*dacl = DiscretionaryAccessControlList{}
+	_, err := dacl.UnmarshalWithContext(data, parsing.NewContext(parsing.Options{Detach: true}))
+	return err
+}
+
+// MarshalJSON returns the JSON representation of the DACL, implementing json.Marshaler. The
+// representation is described by the JSON schema documented with
+// securitydescriptor.JSON_SCHEMA_VERSION.
+//
+// Returns:
+//   - []byte: The JSON object representing the DACL.
+//   - error: An error if the DACL cannot be serialized.
+func (dacl *DiscretionaryAccessControlList) MarshalJSON() ([]byte, error) {
+	entries := dacl.Entries
+	if entries == nil {
+		entries = []ace.AccessControlEntry{}
+	}
+	return json.Marshal(jsonAccessControlList{
+		Revision: dacl.Header.Revision.Value,
+		Sbz1:     dacl.Header.Sbz1,
+		Sbz2:     dacl.Header.Sbz2,
+		Entries:  entries,
+	})
+}
+
+// UnmarshalJSON parses the JSON representation of a DACL, implementing json.Unmarshaler. The
+// indexes of the entries and the header are computed, and RawBytes are refreshed like after
+// Marshal.
+//
+// Parameters:
+//   - data ([]byte): The JSON object representing the DACL.
+//
+// Returns:
+//   - error: An error if the data is not a valid JSON DACL.
+func (dacl *DiscretionaryAccessControlList) UnmarshalJSON(data []byte) error {
+	document, err := unmarshalJSONAccessControlList("DACL", data)
+	if err != nil {
+		return err
+	}
+
+	parsed := DiscretionaryAccessControlList{}
+	parsed.Header.Revision.Value = document.Revision
+	parsed.Header.Sbz1 = document.Sbz1
+	parsed.Header.Sbz2 = document.Sbz2
+	parsed.setEntries(document.Entries)
+	if _, err := parsed.Marshal(); err != nil {
+		return fmt.Errorf("invalid JSON DACL: %w", err)
+	}
+	*dacl = parsed
+	return nil
+}
+
+// MarshalBinary returns the binary representation of the SACL, implementing
+// encoding.BinaryMarshaler. The SACL is left untouched, unlike with Marshal.
+//
+// Returns:
+//   - []byte: The binary representation of the SACL.
+//   - error: An error if the SACL cannot be serialized.
+func (sacl *SystemAccessControlList) MarshalBinary() ([]byte, error) {
+	// AppendBinary only writes the AclSize of the header, which is copied
+	layout := *sacl
+	return layout.AppendBinary(make([]byte, 0, sacl.Size()))
+}
+
+// UnmarshalBinary parses the binary representation of a SACL, implementing
+// encoding.BinaryUnmarshaler. The data is copied, so that the SACL does not reference it.
+// Like with Unmarshal, the bytes after the AceCount entries are ignored.
+//
+// Parameters:
+//   - data ([]byte): The binary representation of the SACL.
+//
+// Returns:
+//   - error: An error if the SACL cannot be parsed.
+func (sacl *SystemAccessControlList) UnmarshalBinary(data []byte) error {
+	*sacl = SystemAccessControlList{}
+	_, err := sacl.UnmarshalWithContext(data, parsing.NewContext(parsing.Options{Detach: true}))
+	return err
+}
+
+// MarshalJSON returns the JSON representation of the SACL, implementing json.Marshaler. The
+// representation is described by the JSON schema documented with
+// securitydescriptor.JSON_SCHEMA_VERSION.
+//
+// Returns:
+//   - []byte: The JSON object representing the SACL.
+//   - error: An error if the SACL cannot be serialized.
+func (sacl *SystemAccessControlList) MarshalJSON() ([]byte, error) {
+	entries := sacl.Entries
+	if entries == nil {
+		entries = []ace.AccessControlEntry{}
+	}
+	return json.Marshal(jsonAccessControlList{
+		Revision: sacl.Header.Revision.Value,
+		Sbz1:     sacl.Header.Sbz1,
+		Sbz2:     sacl.Header.Sbz2,
+		Entries:  entries,
+	})
+}
+
+// UnmarshalJSON parses the JSON representation of a SACL, implementing json.Unmarshaler. The
+// indexes of the entries and the header are computed, and RawBytes are refreshed like after
+// Marshal.
+//
+// Parameters:
+//   - data ([]byte): The JSON object representing the SACL.
+//
+// Returns:
+//   - error: An error if the data is not a valid JSON SACL.
+func (sacl *SystemAccessControlList) UnmarshalJSON(data []byte) error {
+	document, err := unmarshalJSONAccessControlList("SACL", data)
+	if err != nil {
+		return err
+	}
+
+	parsed := SystemAccessControlList{}
+	parsed.Header.Revision.Value = document.Revision
+	parsed.Header.Sbz1 = document.Sbz1
+	parsed.Header.Sbz2 = document.Sbz2
+	parsed.setEntries(document.Entries)
+	if _, err := parsed.Marshal(); err != nil {
+		return fmt.Errorf("invalid JSON SACL: %w", err)
+	}
+	*sacl = parsed
+	return nil
+}
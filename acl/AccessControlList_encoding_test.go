@@ -0,0 +1,82 @@
+package acl_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/acl"
+)
+
+func TestDACL_Encoding(t *testing.T) {
+	data := buildSingleEntryDACL(t)
+
+	// The bytes after the DACL are ignored, and the DACL does not reference its input
+	input := append(bytes.Clone(data), 0xff, 0xff)
+	original := &acl.DiscretionaryAccessControlList{}
+	if err := original.UnmarshalBinary(input); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	input[0] = 0x00
+	if original.RawBytes[0] == 0x00 {
+		t.Errorf("UnmarshalBinary() references the input")
+	}
+
+	jsonData, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if !strings.HasPrefix(string(jsonData), `{"revision":4,"entries":[{"type":0,"typeName":"ACCESS_ALLOWED"`) {
+		t.Errorf("json.Marshal() = %s", jsonData)
+	}
+
+	fromJSON := &acl.DiscretionaryAccessControlList{}
+	if err := json.Unmarshal(jsonData, fromJSON); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if fromJSON.Header.AceCount != 1 || fromJSON.Entries[0].Index != 1 || int(fromJSON.Header.AclSize) != len(data) {
+		t.Errorf("json.Unmarshal() header = %+v, index %d", fromJSON.Header, fromJSON.Entries[0].Index)
+	}
+	binaryData, err := fromJSON.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	if !bytes.Equal(binaryData, data) {
+		t.Errorf("MarshalBinary() = %x, want %x", binaryData, data)
+	}
+
+	if err := json.Unmarshal([]byte(`{"revision":2,"entries":[{"type":0}]}`), fromJSON); err == nil {
+		t.Errorf("json.Unmarshal() of an invalid entry succeeded, want an error")
+	}
+}
+
+func TestSACL_Encoding(t *testing.T) {
+	empty := &acl.SystemAccessControlList{}
+	empty.Header.Revision.Value = 2
+	jsonData, err := json.Marshal(empty)
+	if err != nil || string(jsonData) != `{"revision":2,"entries":[]}` {
+		t.Fatalf("json.Marshal() = %s, %v", jsonData, err)
+	}
+
+	document := `{"revision":2,"entries":[{"type":2,"flags":192,"mask":983551,"sid":"S-1-1-0"}]}`
+	sacl := &acl.SystemAccessControlList{}
+	if err := json.Unmarshal([]byte(document), sacl); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if sacl.Header.AceCount != 1 || sacl.Entries[0].Identity.Name == "" || sacl.Entries[0].Header.Size != 20 {
+		t.Errorf("json.Unmarshal() = %+v", sacl.Entries[0])
+	}
+
+	binaryData, err := sacl.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	parsed := &acl.SystemAccessControlList{}
+	if err := parsed.UnmarshalBinary(binaryData); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	if !bytes.Equal(parsed.RawBytes, sacl.RawBytes) {
+		t.Errorf("UnmarshalBinary().RawBytes = %x, want %x", parsed.RawBytes, sacl.RawBytes)
+	}
+}
@@ -0,0 +1,92 @@
+package acl
+
+import (
+	"fmt"
+
+	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/acl/revision"
+	"github.com/TheManticoreProject/winacl/parsing"
+)
+
+// checkRevision checks that the revision of a DACL or a SACL is between ACL_REVISION and
+// ACL_REVISION_DS, the revisions accepted by Windows. In lenient mode, an unsupported revision
+// is recorded as an anomaly of the context instead of failing.
+//
+// Parameters:
+//   - aclName (string): The name of the ACL, "DACL" or "SACL", used in error messages.
+//   - value (uint8): The Revision field of the ACL header.
+//   - ctx (*parsing.Context): The parse context, located at the start of the ACL.
+//
+// Returns:
+//   - error: An error if the revision is not supported, otherwise nil.
+func checkRevision(aclName string, value uint8, ctx *parsing.Context) error {
+	if value >= revision.ACL_REVISION && value <= revision.ACL_REVISION_DS {
+		return nil
+	}
+	if !ctx.Lenient() {
+		return parsing.NewParseError(parsing.ERROR_CATEGORY_INVALID_REVISION, 0, "Header.Revision", "invalid %s: unsupported Revision (%d)", aclName, value)
+	}
+	ctx.Record(0, parsing.ANOMALY_CODE_REVISION_INVALID, "unsupported Revision (%d)", value)
+	return nil
+}
+
+// unmarshalEntries parses the ACEs that follow the header of a DACL or a SACL. Parsing is
+// bounded to the region declared by AclSize: the caller hands in the entire remaining buffer
+// (in a security descriptor the ACL is followed by the Owner/Group SIDs), so without this
+// bound a corrupt or oversized AceCount would walk past the ACL and mis-parse adjacent
+// components as ACEs.
+//
+// In lenient mode, an AclSize that does not match the available data is replaced by the size
+// of the available data, and parsing stops at the first ACE whose boundaries are unknown
+// instead of failing. Both recoveries are recorded as anomalies of the context.
+//
+// Parameters:
+//   - aclName (string): The name of the ACL, "DACL" or "SACL", used in error messages.
+//   - aclSize (uint16): The AclSize field of the ACL header.
+//   - aceCount (uint16): The AceCount field of the ACL header.
+//   - headerSize (int): The size of the ACL header.
+//   - marshalledData ([]byte): The data following the ACL header.
+//   - ctx (*parsing.Context): The parse context, located at the start of the ACL.
+//
+// Returns:
+//   - []ace.AccessControlEntry: The parsed ACEs, indexed from 1.
+//   - int: The number of bytes consumed by the ACEs.
+//   - error: An error if the ACEs cannot be parsed.
+func unmarshalEntries(aclName string, aclSize uint16, aceCount uint16, headerSize int, marshalledData []byte, ctx *parsing.Context) ([]ace.AccessControlEntry, int, error) {
+	aceRegionLen := int(aclSize) - headerSize
+	if int(aclSize) < headerSize {
+		if !ctx.Lenient() {
+			return nil, 0, parsing.NewParseError(parsing.ERROR_CATEGORY_OUT_OF_RANGE, 2, "Header.AclSize", "invalid %s: AclSize (%d) is smaller than the header size (%d)", aclName, aclSize, headerSize)
+		}
+		ctx.Record(2, parsing.ANOMALY_CODE_ACL_SIZE_INVALID, "AclSize (%d) is smaller than the header size (%d), using the available data (%d)", aclSize, headerSize, headerSize+len(marshalledData))
+		aceRegionLen = len(marshalledData)
+	}
+	if aceRegionLen > len(marshalledData) {
+		if !ctx.Lenient() {
+			return nil, 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 2, "Header.AclSize", "invalid %s: AclSize (%d) exceeds available data (%d)", aclName, aclSize, headerSize+len(marshalledData))
+		}
+		ctx.Record(2, parsing.ANOMALY_CODE_ACL_SIZE_INVALID, "AclSize (%d) exceeds available data (%d), using the available data", aclSize, headerSize+len(marshalledData))
+		aceRegionLen = len(marshalledData)
+	}
+	aceData := marshalledData[:aceRegionLen]
+
+	entries := []ace.AccessControlEntry{}
+	offset := 0
+	for index := 0; index < int(aceCount); index++ {
+		entry := ace.AccessControlEntry{}
+		rawBytesSize, err := entry.UnmarshalWithContext(aceData, ctx.Child(headerSize+offset, fmt.Sprintf("Entries[%d]", index)))
+		if err != nil {
+			if !ctx.Lenient() {
+				return nil, 0, parsing.WrapParseError(err, headerSize+offset, fmt.Sprintf("Entries[%d]", index), "failed to unmarshal ACE %d/%d within AclSize", index+1, aceCount)
+			}
+			ctx.Record(headerSize+offset, parsing.ANOMALY_CODE_ACE_COUNT_MISMATCH, "AceCount is %d but only %d ACEs could be parsed: %s", aceCount, index, err.Error())
+			break
+		}
+		entry.Index = uint16(index + 1)
+		entries = append(entries, entry)
+		offset += rawBytesSize
+		aceData = aceData[rawBytesSize:]
+	}
+
+	return entries, offset, nil
+}
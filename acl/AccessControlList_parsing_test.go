@@ -0,0 +1,51 @@
+package acl_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/acl"
+	"github.com/TheManticoreProject/winacl/parsing"
+)
+
+func TestDACL_UnmarshalWithContext_AclSizeExceedsData(t *testing.T) {
+	data := buildSingleEntryDACL(t)
+	binary.LittleEndian.PutUint16(data[2:4], uint16(len(data)+16))
+
+	strict := acl.DiscretionaryAccessControlList{}
+	if _, err := strict.UnmarshalWithContext(data, parsing.NewContext(parsing.Options{})); err == nil {
+		t.Fatalf("UnmarshalWithContext() expected an error in strict mode")
+	}
+
+	ctx := parsing.NewContext(parsing.Options{Lenient: true})
+	dacl := acl.DiscretionaryAccessControlList{}
+	rawBytesSize, err := dacl.UnmarshalWithContext(data, ctx)
+	if err != nil {
+		t.Fatalf("UnmarshalWithContext() error = %v", err)
+	}
+	if rawBytesSize != len(data) || len(dacl.Entries) != 1 {
+		t.Errorf("UnmarshalWithContext() = %d with %d entries, want %d with 1 entry", rawBytesSize, len(dacl.Entries), len(data))
+	}
+	anomalies := ctx.Anomalies()
+	if len(anomalies) != 1 || anomalies[0].Code != parsing.ANOMALY_CODE_ACL_SIZE_INVALID || anomalies[0].Offset != 2 {
+		t.Errorf("Anomalies() = %v", anomalies)
+	}
+}
+
+func TestSACL_UnmarshalWithContext_AceCountExceedsEntries(t *testing.T) {
+	data := buildSingleEntryDACL(t)
+	binary.LittleEndian.PutUint16(data[4:6], 3)
+
+	ctx := parsing.NewContext(parsing.Options{Lenient: true}).Child(0x14, "SACL")
+	sacl := acl.SystemAccessControlList{}
+	if _, err := sacl.UnmarshalWithContext(data, ctx); err != nil {
+		t.Fatalf("UnmarshalWithContext() error = %v", err)
+	}
+	if len(sacl.Entries) != 1 || sacl.Header.AceCount != 1 {
+		t.Errorf("SACL has %d entries and AceCount %d, want 1", len(sacl.Entries), sacl.Header.AceCount)
+	}
+	anomalies := ctx.Anomalies()
+	if len(anomalies) != 1 || anomalies[0].Code != parsing.ANOMALY_CODE_ACE_COUNT_MISMATCH || anomalies[0].Path != "SACL" || anomalies[0].Offset != 0x14+len(data) {
+		t.Errorf("Anomalies() = %v", anomalies)
+	}
+}
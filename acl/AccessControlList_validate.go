@@ -0,0 +1,178 @@
+package acl
+
+import (
+	"fmt"
+
+	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/ace/aceflags"
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/acl/revision"
+	"github.com/TheManticoreProject/winacl/validation"
+)
+
+// Validate checks the structure of the DACL and of its entries, like the IsValidAcl function
+// of the Windows API. It also reports the entries that only belong in a SACL and the entries
+// that break the canonical order of a DACL.
+//
+// Returns:
+//   - validation.Findings: The problems found in the DACL, with paths relative to the DACL.
+func (dacl *DiscretionaryAccessControlList) Validate() validation.Findings {
+	findings := validateAccessControlList(
+		dacl.Header.Revision.Value, dacl.Header.Sbz1, dacl.Header.AceCount, dacl.Header.Sbz2, dacl.Entries,
+	)
+
+	for index := range dacl.Entries {
+		entry := &dacl.Entries[index]
+		if isKnownAceType(entry) && !entry.IsAccessAllowedOrDenied() {
+			findings.Add(validation.SEVERITY_ERROR, validation.FINDING_CODE_ACL_ACE_TYPE_MISPLACED, entryPath(index), "%s ACE is not allowed in a DACL", entry.Header.Type.String())
+		}
+	}
+
+	// The canonical order is: explicit deny, explicit allow, then inherited entries
+	maxRank := 0
+	for index := range dacl.Entries {
+		entry := &dacl.Entries[index]
+		if !entry.IsAccessAllowedOrDenied() {
+			continue
+		}
+		rank := canonicalRank(entry)
+		if rank < maxRank {
+			findings.Add(validation.SEVERITY_WARNING, validation.FINDING_CODE_ACL_NOT_CANONICAL, entryPath(index), "%s entry is out of canonical order, it should come before the %s entries", canonicalRankNames[rank], canonicalRankNames[maxRank])
+		} else {
+			maxRank = rank
+		}
+	}
+
+	return findings
+}
+
+// Validate checks the structure of the SACL and of its entries, like the IsValidAcl function
+// of the Windows API. It also reports the entries that only belong in a DACL and the labels
+// that are defined more than once.
+//
+// Returns:
+//   - validation.Findings: The problems found in the SACL, with paths relative to the SACL.
+func (sacl *SystemAccessControlList) Validate() validation.Findings {
+	findings := validateAccessControlList(
+		sacl.Header.Revision.Value, sacl.Header.Sbz1, sacl.Header.AceCount, sacl.Header.Sbz2, sacl.Entries,
+	)
+
+	labels := 0
+	for index := range sacl.Entries {
+		entry := &sacl.Entries[index]
+		if isKnownAceType(entry) && entry.IsAccessAllowedOrDenied() {
+			findings.Add(validation.SEVERITY_ERROR, validation.FINDING_CODE_ACL_ACE_TYPE_MISPLACED, entryPath(index), "%s ACE is not allowed in a SACL", entry.Header.Type.String())
+		}
+		if entry.Header.Type.Value == acetype.ACE_TYPE_SYSTEM_MANDATORY_LABEL {
+			labels++
+			if labels == 2 {
+				findings.Add(validation.SEVERITY_WARNING, validation.FINDING_CODE_ACL_MULTIPLE_LABELS, entryPath(index), "SACL holds more than one mandatory label, only the first one is used")
+			}
+		}
+	}
+
+	return findings
+}
+
+// validateAccessControlList performs the checks shared by DACLs and SACLs: the header fields
+// and the entries themselves.
+//
+// Parameters:
+//   - aclRevision (uint8): The revision of the ACL.
+//   - sbz1 (uint8): The first reserved field of the header.
+//   - aceCount (uint16): The number of entries declared by the header.
+//   - sbz2 (uint16): The second reserved field of the header.
+//   - entries ([]ace.AccessControlEntry): The entries of the ACL.
+//
+// Returns:
+//   - validation.Findings: The problems found in the ACL.
+func validateAccessControlList(aclRevision uint8, sbz1 uint8, aceCount uint16, sbz2 uint16, entries []ace.AccessControlEntry) validation.Findings {
+	findings := validation.Findings{}
+
+	if aclRevision < revision.ACL_REVISION || aclRevision > revision.ACL_REVISION_DS {
+		findings.Add(validation.SEVERITY_ERROR, validation.FINDING_CODE_ACL_REVISION_INVALID, "Header.Revision", "ACL revision is %d, expected %d to %d", aclRevision, revision.ACL_REVISION, revision.ACL_REVISION_DS)
+	}
+	if sbz1 != 0 {
+		findings.Add(validation.SEVERITY_WARNING, validation.FINDING_CODE_ACL_SBZ_NOT_ZERO, "Header.Sbz1", "Sbz1 is 0x%02x, expected 0", sbz1)
+	}
+	if sbz2 != 0 {
+		findings.Add(validation.SEVERITY_WARNING, validation.FINDING_CODE_ACL_SBZ_NOT_ZERO, "Header.Sbz2", "Sbz2 is 0x%04x, expected 0", sbz2)
+	}
+	if int(aceCount) != len(entries) {
+		findings.Add(validation.SEVERITY_ERROR, validation.FINDING_CODE_ACL_ACE_COUNT_MISMATCH, "Header.AceCount", "AceCount is %d but the ACL holds %d entries", aceCount, len(entries))
+	}
+
+	for index := range entries {
+		entry := &entries[index]
+		if requiredRevision := requiredACLRevision(entry); aclRevision < requiredRevision {
+			findings.Add(validation.SEVERITY_ERROR, validation.FINDING_CODE_ACL_REVISION_TOO_LOW, entryPath(index), "%s ACE requires an ACL of revision %d, the ACL has revision %d", entry.Header.Type.String(), requiredRevision, aclRevision)
+		}
+		findings.Extend(entryPath(index), entry.Validate())
+	}
+
+	return findings
+}
+
+// requiredACLRevision returns the lowest ACL revision that can hold an entry: ACL_REVISION_DS
+// for object ACEs, ACL_REVISION3 for ACCESS_ALLOWED_COMPOUND ACEs and ACL_REVISION otherwise.
+//
+// Parameters:
+//   - entry (*ace.AccessControlEntry): The entry.
+//
+// Returns:
+//   - uint8: The lowest revision of an ACL holding the entry.
+func requiredACLRevision(entry *ace.AccessControlEntry) uint8 {
+	if entry.IsObjectAce() {
+		return revision.ACL_REVISION_DS
+	}
+	if entry.Header.Type.Value == acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND {
+		return revision.ACL_REVISION3
+	}
+	return revision.ACL_REVISION
+}
+
+// canonicalRankNames names the ranks returned by canonicalRank.
+var canonicalRankNames = []string{"explicit deny", "explicit allow", "inherited"}
+
+// canonicalRank returns the position of an access allowed or denied entry in the canonical
+// order of a DACL: 0 for explicit deny entries, 1 for explicit allow entries and 2 for
+// inherited entries.
+//
+// Parameters:
+//   - entry (*ace.AccessControlEntry): The entry.
+//
+// Returns:
+//   - int: The rank of the entry.
+func canonicalRank(entry *ace.AccessControlEntry) int {
+	if entry.Header.Flags.RawValue&aceflags.ACE_FLAG_INHERITED != 0 {
+		return 2
+	}
+	if entry.IsAccessDenied() {
+		return 0
+	}
+	return 1
+}
+
+// isKnownAceType checks if the type of an entry is defined by Windows. Unknown types are
+// reported by the validation of the entry itself.
+//
+// Parameters:
+//   - entry (*ace.AccessControlEntry): The entry.
+//
+// Returns:
+//   - bool: true if the type is defined by Windows, false otherwise.
+func isKnownAceType(entry *ace.AccessControlEntry) bool {
+	_, known := acetype.AccessControlEntryTypeValueToName[entry.Header.Type.Value]
+	return known
+}
+
+// entryPath returns the path of an entry of an ACL.
+//
+// Parameters:
+//   - index (int): The index of the entry.
+//
+// Returns:
+//   - string: The path of the entry.
+func entryPath(index int) string {
+	return fmt.Sprintf("Entries[%d]", index)
+}
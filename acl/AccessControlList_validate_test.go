@@ -0,0 +1,120 @@
+package acl_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/acl"
+	"github.com/TheManticoreProject/winacl/acl/revision"
+	"github.com/TheManticoreProject/winacl/validation"
+)
+
+const validateTestSID = "01050000000000051500000028bb82279261b9fe2474aa5d00020000"
+
+// validateTestEntry decodes an ACE from its hexadecimal representation.
+func validateTestEntry(t *testing.T, hexString string) ace.AccessControlEntry {
+	t.Helper()
+	rawBytes, err := hex.DecodeString(hexString)
+	if err != nil {
+		t.Fatalf("Failed to decode hex string: %v", err)
+	}
+	entry := ace.AccessControlEntry{}
+	if _, err := entry.Unmarshal(rawBytes); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	return entry
+}
+
+func TestDACLValidate(t *testing.T) {
+	explicitDeny := validateTestEntry(t, "01002400ff010f00"+validateTestSID)
+	explicitAllow := validateTestEntry(t, "00002400ff010f00"+validateTestSID)
+	inheritedAllow := validateTestEntry(t, "00102400ff010f00"+validateTestSID)
+	objectAllow := validateTestEntry(t, "05002800ff010f0000000000"+validateTestSID)
+	audit := validateTestEntry(t, "02402400ff010f00"+validateTestSID)
+	compoundAllow := validateTestEntry(t, "04004400ff010f0001000000"+validateTestSID+validateTestSID)
+
+	tests := []struct {
+		name     string
+		revision uint8
+		entries  []ace.AccessControlEntry
+		expected []validation.FindingCode
+	}{
+		{"canonical", revision.ACL_REVISION, []ace.AccessControlEntry{explicitDeny, explicitAllow, inheritedAllow}, nil},
+		{"object_ace", revision.ACL_REVISION_DS, []ace.AccessControlEntry{objectAllow}, nil},
+		{"object_ace_revision_2", revision.ACL_REVISION, []ace.AccessControlEntry{objectAllow}, []validation.FindingCode{validation.FINDING_CODE_ACL_REVISION_TOO_LOW}},
+		{"object_ace_revision_3", revision.ACL_REVISION3, []ace.AccessControlEntry{objectAllow}, []validation.FindingCode{validation.FINDING_CODE_ACL_REVISION_TOO_LOW}},
+		{"revision_3", revision.ACL_REVISION3, []ace.AccessControlEntry{explicitAllow}, nil},
+		{"compound_ace_revision_3", revision.ACL_REVISION3, []ace.AccessControlEntry{compoundAllow}, []validation.FindingCode{validation.FINDING_CODE_ACE_TYPE_RESERVED}},
+		{"compound_ace_revision_2", revision.ACL_REVISION, []ace.AccessControlEntry{compoundAllow}, []validation.FindingCode{validation.FINDING_CODE_ACL_REVISION_TOO_LOW, validation.FINDING_CODE_ACE_TYPE_RESERVED}},
+		{"revision_invalid", 5, []ace.AccessControlEntry{explicitAllow}, []validation.FindingCode{validation.FINDING_CODE_ACL_REVISION_INVALID}},
+		{"audit_in_dacl", revision.ACL_REVISION, []ace.AccessControlEntry{audit}, []validation.FindingCode{validation.FINDING_CODE_ACL_ACE_TYPE_MISPLACED}},
+		{"allow_before_deny", revision.ACL_REVISION, []ace.AccessControlEntry{explicitAllow, explicitDeny}, []validation.FindingCode{validation.FINDING_CODE_ACL_NOT_CANONICAL}},
+		{"inherited_before_explicit", revision.ACL_REVISION, []ace.AccessControlEntry{inheritedAllow, explicitAllow, explicitDeny}, []validation.FindingCode{validation.FINDING_CODE_ACL_NOT_CANONICAL, validation.FINDING_CODE_ACL_NOT_CANONICAL}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dacl := acl.DiscretionaryAccessControlList{}
+			dacl.Header.Revision.Value = tt.revision
+			for _, entry := range tt.entries {
+				dacl.AddEntry(entry)
+			}
+
+			findings := dacl.Validate()
+			if len(findings) != len(tt.expected) {
+				t.Fatalf("Validate() = %v, want %v", findings, tt.expected)
+			}
+			for i, code := range tt.expected {
+				if findings[i].Code != code {
+					t.Errorf("Validate()[%d].Code = %s, want %s", i, findings[i].Code, code)
+				}
+			}
+		})
+	}
+}
+
+func TestDACLValidate_Header(t *testing.T) {
+	dacl := acl.DiscretionaryAccessControlList{}
+	dacl.Header.Revision.Value = revision.ACL_REVISION
+	dacl.AddEntry(validateTestEntry(t, "00002400ff010f00"+validateTestSID))
+	dacl.Header.Sbz1 = 1
+	dacl.Header.Sbz2 = 1
+	dacl.Header.AceCount = 2
+
+	findings := dacl.Validate()
+	if got := findings.WithCode(validation.FINDING_CODE_ACL_SBZ_NOT_ZERO); len(got) != 2 {
+		t.Errorf("Validate() = %v, want two ACL_SBZ_NOT_ZERO", findings)
+	}
+	if got := findings.WithCode(validation.FINDING_CODE_ACL_ACE_COUNT_MISMATCH); len(got) != 1 || got[0].Path != "Header.AceCount" {
+		t.Errorf("Validate() = %v, want ACL_ACE_COUNT_MISMATCH", findings)
+	}
+
+	// Findings of the entries are prefixed with their index
+	dacl.Header.AceCount = 1
+	dacl.Entries[0].Header.Flags.RawValue = 0x08
+	findings = dacl.Validate()
+	if len(findings) != 3 || findings[2].Path != "Entries[0].Header.Flags" {
+		t.Errorf("Validate() = %v, want ACE_INHERIT_ONLY_NO_EFFECT on Entries[0].Header.Flags", findings)
+	}
+}
+
+func TestSACLValidate(t *testing.T) {
+	sacl := acl.SystemAccessControlList{}
+	sacl.Header.Revision.Value = revision.ACL_REVISION
+	sacl.AddEntry(validateTestEntry(t, "02402400ff010f00"+validateTestSID))
+	sacl.AddEntry(validateTestEntry(t, "11001400010000000101000000000010"+"00300000"))
+	if findings := sacl.Validate(); len(findings) != 0 {
+		t.Errorf("Validate() = %v, want no findings", findings)
+	}
+
+	sacl.AddEntry(validateTestEntry(t, "00002400ff010f00"+validateTestSID))
+	sacl.AddEntry(validateTestEntry(t, "11001400010000000101000000000010"+"00100000"))
+	findings := sacl.Validate()
+	if got := findings.WithCode(validation.FINDING_CODE_ACL_ACE_TYPE_MISPLACED); len(got) != 1 || got[0].Path != "Entries[2]" {
+		t.Errorf("Validate() = %v, want ACL_ACE_TYPE_MISPLACED on Entries[2]", findings)
+	}
+	if got := findings.WithCode(validation.FINDING_CODE_ACL_MULTIPLE_LABELS); len(got) != 1 || got[0].Path != "Entries[3]" {
+		t.Errorf("Validate() = %v, want ACL_MULTIPLE_LABELS on Entries[3]", findings)
+	}
+}
@@ -2,9 +2,11 @@ package acl
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/parsing"
 )
 
 // DiscretionaryAccessControlList represents a Discretionary Access Control List (DACL).
@@ -23,40 +25,46 @@ type DiscretionaryAccessControlList struct {
 // Parameters:
 //   - rawBytes ([]byte): The raw byte slice to be parsed.
 func (dacl *DiscretionaryAccessControlList) Unmarshal(marshalledData []byte) (int, error) {
+	return dacl.UnmarshalWithContext(marshalledData, parsing.NewContext(parsing.Options{}))
+}
+
+// UnmarshalWithContext parses the DACL like Unmarshal, following the options of the parse
+// context. In lenient mode, the ACEs that can be parsed are kept and the AceCount of the header
+// is lowered to their number, see parsing.Options.
+//
+// Parameters:
+//   - marshalledData ([]byte): The raw byte slice to be parsed.
+//   - ctx (*parsing.Context): The parse context, located at the start of the DACL.
+//
+// Returns:
+//   - int: The number of bytes consumed by the header and the ACEs.
+//   - error: An error if the DACL cannot be parsed.
+func (dacl *DiscretionaryAccessControlList) UnmarshalWithContext(marshalledData []byte, ctx *parsing.Context) (int, error) {
+	marshalledData = ctx.Detach(marshalledData)
 	dacl.RawBytesSize = 0
 	dacl.RawBytes = marshalledData
 
+	// Unmarshal the header
 	rawBytesSize, err := dacl.Header.Unmarshal(marshalledData)
 	if err != nil {
-		return 0, err
+		return 0, parsing.WrapParseError(err, 0, "Header", "")
 	}
 	dacl.RawBytesSize += uint32(rawBytesSize)
-	marshalledData = marshalledData[rawBytesSize:]
-
-	// Bound ACE parsing to the region declared by AclSize. The caller hands in
-	// the entire remaining buffer (in a security descriptor the ACL is followed
-	// by the Owner/Group SIDs), so without this bound a corrupt or oversized
-	// AceCount would walk past the ACL and mis-parse adjacent components as ACEs.
-	if int(dacl.Header.AclSize) < rawBytesSize {
-		return 0, fmt.Errorf("invalid DACL: AclSize (%d) is smaller than the header size (%d)", dacl.Header.AclSize, rawBytesSize)
-	}
-	aceRegionLen := int(dacl.Header.AclSize) - rawBytesSize
-	if aceRegionLen > len(marshalledData) {
-		return 0, fmt.Errorf("invalid DACL: AclSize (%d) exceeds available data (%d)", dacl.Header.AclSize, rawBytesSize+len(marshalledData))
+
+	err = checkRevision("DACL", dacl.Header.Revision.Value, ctx)
+	if err != nil {
+		return 0, err
 	}
-	aceData := marshalledData[:aceRegionLen]
 
-	// Parse all ACEs
-	for index := 0; index < int(dacl.Header.AceCount); index++ {
-		entry := ace.AccessControlEntry{}
-		rawBytesSize, err := entry.Unmarshal(aceData)
-		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal ACE %d/%d within AclSize: %w", index+1, dacl.Header.AceCount, err)
-		}
-		entry.Index = uint16(index + 1)
-		dacl.Entries = append(dacl.Entries, entry)
-		dacl.RawBytesSize += uint32(rawBytesSize)
-		aceData = aceData[rawBytesSize:]
+	// Unmarshal all ACEs
+	entries, entriesSize, err := unmarshalEntries("DACL", dacl.Header.AclSize, dacl.Header.AceCount, rawBytesSize, marshalledData[rawBytesSize:], ctx)
+	if err != nil {
+		return 0, err
+	}
+	dacl.Entries = append(dacl.Entries, entries...)
+	dacl.RawBytesSize += uint32(entriesSize)
+	if len(entries) < int(dacl.Header.AceCount) {
+		dacl.Header.AceCount = uint16(len(entries))
 	}
 
 	dacl.RawBytes = dacl.RawBytes[:dacl.RawBytesSize]
@@ -69,30 +77,96 @@ func (dacl *DiscretionaryAccessControlList) Unmarshal(marshalledData []byte) (in
 // Returns:
 //   - []byte: The serialized byte slice representing the DACL.
 func (dacl *DiscretionaryAccessControlList) Marshal() ([]byte, error) {
-	var marshalledData []byte
+	marshalledData, err := dacl.AppendBinary(make([]byte, 0, dacl.Size()))
+	if err != nil {
+		return nil, err
+	}
+	dacl.RefreshRawBytes(marshalledData)
+	return marshalledData, nil
+}
 
-	for _, ace := range dacl.Entries {
-		bytesStream, err := ace.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+// RefreshRawBytes points RawBytes and RawBytesSize at the marshalled bytes of the DACL, and
+// refreshes its header and its entries.
+//
+// Parameters:
+//   - marshalledData ([]byte): The bytes produced by AppendBinary for the DACL.
+func (dacl *DiscretionaryAccessControlList) RefreshRawBytes(marshalledData []byte) {
+	dacl.RawBytesSize = uint32(dacl.Size())
+	dacl.RawBytes = marshalledData[:dacl.RawBytesSize]
+	dacl.Header.RefreshRawBytes(marshalledData)
+
+	offset := dacl.Header.Size()
+	for index := range dacl.Entries {
+		dacl.Entries[index].RefreshRawBytes(marshalledData[offset:])
+		offset += dacl.Entries[index].Size()
 	}
+}
 
-	// Marshal the header at the beginning of the serialized data
-	// We need to include the header in the size calculation, it is 8 bytes long
-	totalSize := 8 + len(marshalledData)
+// Size returns the size of the binary representation of the DACL, as produced by Marshal.
+//
+// Returns:
+//   - int: The size of the header and of the entries, in bytes.
+func (dacl *DiscretionaryAccessControlList) Size() int {
+	size := dacl.Header.Size()
+	for index := range dacl.Entries {
+		size += dacl.Entries[index].Size()
+	}
+	return size
+}
+
+// AppendBinary appends the binary representation of the DACL to a buffer. When the buffer
+// has Size() bytes of spare capacity, nothing is allocated. Like Marshal, it updates
+// Header.AclSize to the size of the DACL.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: An error if the DACL is too large or one of its entries cannot be serialized.
+func (dacl *DiscretionaryAccessControlList) AppendBinary(buffer []byte) ([]byte, error) {
+	// The header holds the size of the whole DACL, it is 8 bytes long
+	totalSize := dacl.Size()
 	if totalSize > 0xFFFF {
 		return nil, fmt.Errorf("DACL too large to marshal: size %d exceeds the uint16 AclSize maximum (65535)", totalSize)
 	}
 	dacl.Header.AclSize = uint16(totalSize)
-	bytesStream, err := dacl.Header.Marshal()
+	buffer, err := dacl.Header.AppendBinary(buffer)
 	if err != nil {
 		return nil, err
 	}
-	marshalledData = append(bytesStream, marshalledData...)
 
-	return marshalledData, nil
+	// Marshal the entries. They are serialized from a copy, so that the padding of
+	// Header.Size done by AccessControlEntry.AppendBinary is not written back.
+	for index := range dacl.Entries {
+		entry := dacl.Entries[index]
+		buffer, err = entry.AppendBinary(buffer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return buffer, nil
+}
+
+// MarshalTo writes the binary representation of the DACL at the start of a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to write to, at least Size() bytes long.
+//
+// Returns:
+//   - int: The number of bytes written.
+//   - error: An error if the buffer is too short or the DACL cannot be serialized.
+func (dacl *DiscretionaryAccessControlList) MarshalTo(buffer []byte) (int, error) {
+	size := dacl.Size()
+	if len(buffer) < size {
+		return 0, fmt.Errorf("DACL requires a buffer of %d bytes, got %d: %w", size, len(buffer), io.ErrShortBuffer)
+	}
+	marshalledData, err := dacl.AppendBinary(buffer[:0:size])
+	if err != nil {
+		return 0, err
+	}
+	return len(marshalledData), nil
 }
 
 // Describe prints a detailed description of the DiscretionaryAccessControlList struct,
@@ -1,11 +1,13 @@
 package acl
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"strings"
 
 	"github.com/TheManticoreProject/winacl/acl/revision"
+	"github.com/TheManticoreProject/winacl/parsing"
 )
 
 // DiscretionaryAccessControlListHeader represents the header of a Discretionary Access Control List (DACL).
@@ -32,7 +34,7 @@ type DiscretionaryAccessControlListHeader struct {
 func (daclheader *DiscretionaryAccessControlListHeader) Unmarshal(marshalledData []byte) (int, error) {
 	// Parsing header
 	if len(marshalledData) < 8 {
-		return 0, fmt.Errorf("invalid raw bytes length")
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "invalid raw bytes length")
 	}
 
 	daclheader.RawBytes = marshalledData[:8]
@@ -40,7 +42,7 @@ func (daclheader *DiscretionaryAccessControlListHeader) Unmarshal(marshalledData
 
 	rawBytesSize, err := daclheader.Revision.Unmarshal(marshalledData[:1])
 	if err != nil {
-		return 0, fmt.Errorf("failed to unmarshal Revision: %w", err)
+		return 0, parsing.WrapParseError(err, 0, "Revision", "failed to unmarshal Revision")
 	}
 	daclheader.RawBytesSize += uint32(rawBytesSize)
 
@@ -64,36 +66,67 @@ func (daclheader *DiscretionaryAccessControlListHeader) Unmarshal(marshalledData
 // Returns:
 //   - []byte: The serialized byte slice representing the DACL header.
 func (daclheader *DiscretionaryAccessControlListHeader) Marshal() ([]byte, error) {
-	var marshalledData []byte
-
-	daclheader.RawBytesSize = 0
-
-	bytesStream, err := daclheader.Revision.Marshal()
+	marshalledData, err := daclheader.AppendBinary(make([]byte, 0, daclheader.Size()))
 	if err != nil {
 		return nil, err
 	}
-	marshalledData = append(marshalledData, bytesStream...)
-	daclheader.RawBytesSize += uint32(len(bytesStream))
 
-	marshalledData = append(marshalledData, daclheader.Sbz1)
-	daclheader.RawBytesSize += 1
+	daclheader.RefreshRawBytes(marshalledData)
 
-	buffer := make([]byte, 2)
-	binary.LittleEndian.PutUint16(buffer, daclheader.AclSize)
-	marshalledData = append(marshalledData, buffer...)
-	daclheader.RawBytesSize += 2
+	return marshalledData, nil
+}
 
-	binary.LittleEndian.PutUint16(buffer, daclheader.AceCount)
-	marshalledData = append(marshalledData, buffer...)
-	daclheader.RawBytesSize += 2
+// RefreshRawBytes points RawBytes and RawBytesSize at the 8 marshalled bytes of the DACL header.
+//
+// Parameters:
+//   - marshalledData ([]byte): The bytes produced by AppendBinary for the header.
+func (daclheader *DiscretionaryAccessControlListHeader) RefreshRawBytes(marshalledData []byte) {
+	daclheader.RawBytesSize = uint32(daclheader.Size())
+	daclheader.RawBytes = marshalledData[:daclheader.RawBytesSize]
+}
 
-	binary.LittleEndian.PutUint16(buffer, daclheader.Sbz2)
-	marshalledData = append(marshalledData, buffer...)
-	daclheader.RawBytesSize += 2
+// Clone returns a deep copy of the DACL header, sharing no memory with the original.
+//
+// Returns:
+//   - *DiscretionaryAccessControlListHeader: The copy of the header, nil if the header is nil.
+func (daclheader *DiscretionaryAccessControlListHeader) Clone() *DiscretionaryAccessControlListHeader {
+	if daclheader == nil {
+		return nil
+	}
+	clone := *daclheader
+	clone.RawBytes = bytes.Clone(daclheader.RawBytes)
+	return &clone
+}
 
-	daclheader.RawBytes = marshalledData
+// Size returns the size of the binary representation of the DACL header.
+//
+// Returns:
+//   - int: Always 8.
+func (daclheader *DiscretionaryAccessControlListHeader) Size() int {
+	return 8
+}
 
-	return marshalledData, nil
+// AppendBinary appends the binary representation of the DACL header to a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: An error if the revision cannot be serialized.
+func (daclheader *DiscretionaryAccessControlListHeader) AppendBinary(buffer []byte) ([]byte, error) {
+	buffer, err := daclheader.Revision.AppendBinary(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer = append(buffer, daclheader.Sbz1)
+	buffer = binary.LittleEndian.AppendUint16(buffer, daclheader.AclSize)
+	buffer = binary.LittleEndian.AppendUint16(buffer, daclheader.AceCount)
+	buffer = binary.LittleEndian.AppendUint16(buffer, daclheader.Sbz2)
+	daclheader.RawBytesSize = uint32(daclheader.Size())
+
+	return buffer, nil
 }
 
 // Describe prints a detailed description of the DiscretionaryAccessControlListHeader struct,
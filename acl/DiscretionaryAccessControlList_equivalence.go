@@ -0,0 +1,485 @@
+package acl
+
+import (
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/ace/aceflags"
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/guid"
+	"github.com/TheManticoreProject/winacl/rights"
+)
+
+// EquivalenceOptions configures the semantic analysis of DACLs, see
+// DiscretionaryAccessControlList.EquivalentTo.
+//
+// Attributes:
+//   - Mapping (rights.GenericMapping): The generic mapping of the type of the protected object,
+//     used to expand the generic rights of the ACEs before comparing them.
+//   - ObjectType (*guid.GUID): The object type the access is checked for. When set, the object
+//     ACEs for another object type never take effect on the object. When nil, the object ACEs
+//     are kept apart from the ACEs of their trustee, as they take effect for some checks only.
+//   - IgnoreInheritance (bool): Only compare the access to the object itself, ignoring the
+//     access the inheritable ACEs give on the child objects. Useful for files, which have no
+//     children.
+type EquivalenceOptions struct {
+	Mapping           rights.GenericMapping
+	ObjectType        *guid.GUID
+	IgnoreInheritance bool
+}
+
+// RedundancyKind is the reason why an ACE can be removed without changing the effective access.
+type RedundancyKind uint8
+
+const (
+	// REDUNDANCY_KIND_NOT_APPLICABLE is an ACE that never takes effect: an ACE type ignored in a
+	// DACL, an empty mask, an inherit-only ACE that is not inheritable, or an object ACE for
+	// another object type.
+	REDUNDANCY_KIND_NOT_APPLICABLE RedundancyKind = iota
+	// REDUNDANCY_KIND_DUPLICATE is an ACE whose rights are all in another ACE of the same type,
+	// flags and trustee.
+	REDUNDANCY_KIND_DUPLICATE
+	// REDUNDANCY_KIND_SHADOWED is an ACE whose rights are all already allowed or denied to its
+	// trustee by earlier ACEs, like an allow ACE following a broader deny ACE.
+	REDUNDANCY_KIND_SHADOWED
+	// REDUNDANCY_KIND_NO_EFFECT is an ACE whose removal changes the access of no token for
+	// another reason, like a deny ACE followed by no allow ACE granting the same rights.
+	REDUNDANCY_KIND_NO_EFFECT
+)
+
+// RedundancyKindNames maps the redundancy kinds to their names.
+var RedundancyKindNames = map[RedundancyKind]string{
+	REDUNDANCY_KIND_NOT_APPLICABLE: "NOT_APPLICABLE",
+	REDUNDANCY_KIND_DUPLICATE:      "DUPLICATE",
+	REDUNDANCY_KIND_SHADOWED:       "SHADOWED",
+	REDUNDANCY_KIND_NO_EFFECT:      "NO_EFFECT",
+}
+
+// String returns the name of the redundancy kind.
+//
+// Returns:
+//   - string: The name of the redundancy kind, or "?" if it is unknown.
+func (kind RedundancyKind) String() string {
+	if name, exists := RedundancyKindNames[kind]; exists {
+		return name
+	}
+	return "?"
+}
+
+// RedundantEntry is an ACE of a DACL that can be removed on its own without changing the
+// effective access. Removing several redundant entries at once may change it, for instance
+// when two ACEs duplicate each other; use Minimize to remove as many as possible.
+//
+// Attributes:
+//   - Index (int): The index of the ACE in the DACL.
+//   - Kind (RedundancyKind): Why the ACE is redundant.
+//   - Entry (*ace.AccessControlEntry): The ACE, in the Entries of the DACL.
+type RedundantEntry struct {
+	Index int
+	Kind  RedundancyKind
+	Entry *ace.AccessControlEntry
+}
+
+// String returns a one-line description of the redundant entry.
+//
+// Returns:
+//   - string: The index, the type and the reason of the redundant entry.
+func (entry RedundantEntry) String() string {
+	return fmt.Sprintf("ACE #%d (%s): %s", entry.Index, entry.Entry.Header.Type.String(), entry.Kind.String())
+}
+
+// The contexts in which the effective access given by a DACL is compared: the object itself,
+// its direct children, and its deeper descendants, which only receive the ACEs inherited
+// without NO_PROPAGATE_INHERIT.
+const (
+	evaluationContextSelf = iota
+	evaluationContextChildContainer
+	evaluationContextChildObject
+	evaluationContextDescendantContainer
+	evaluationContextDescendantObject
+	evaluationContextCount
+)
+
+// analyzedEntry is an ACE reduced to what the access check uses.
+type analyzedEntry struct {
+	index int
+	allow bool
+	// mask is the access mask, generic rights expanded
+	mask uint32
+	// trustee is the SID of the ACE, principals holds for each context the principal the ACE
+	// applies to, empty if the ACE does not take effect in the context
+	trustee    string
+	principals [evaluationContextCount]string
+	// mergeKey identifies the ACEs that only differ by their mask
+	mergeKey string
+}
+
+// EquivalentTo checks if two DACLs give the same effective access to every possible token, on
+// the object and on the child objects inheriting their ACEs.
+//
+// Each SID is considered independently of the others: a token can hold any set of SIDs, each
+// enabled or deny-only, as group memberships are unknown. For each right, a token is then granted
+// the right if the first ACE holding it and matching the token is an allow ACE. The comparison is
+// exact for the allow and deny ACEs. A conditional ACE or an object ACE is handled as an ACE for a
+// separate principal that depends on its condition or object type, so that the DACLs may be found
+// different while no real token tells them apart, but never the other way around.
+//
+// A nil DACL, which grants all access to everyone, is only equivalent to a nil DACL.
+//
+// Parameters:
+//   - other (*DiscretionaryAccessControlList): The DACL to compare with.
+//   - options (EquivalenceOptions): The generic mapping and object type to use.
+//
+// Returns:
+//   - bool: true if the DACLs give the same access to every token.
+//   - error: An error if a DACL holds an ACE whose effect cannot be determined, like an opaque or
+//     compound ACE.
+func (dacl *DiscretionaryAccessControlList) EquivalentTo(other *DiscretionaryAccessControlList, options EquivalenceOptions) (bool, error) {
+	if dacl == nil || other == nil {
+		return dacl == nil && other == nil, nil
+	}
+	entries, err := analyzeEntries(dacl.Entries, options)
+	if err != nil {
+		return false, err
+	}
+	otherEntries, err := analyzeEntries(other.Entries, options)
+	if err != nil {
+		return false, err
+	}
+	return effectiveAccessEqual(effectiveAccess(entries), effectiveAccess(otherEntries)), nil
+}
+
+// RedundantEntries finds the ACEs of the DACL that can be removed one at a time without changing
+// the effective access, see EquivalentTo.
+//
+// Parameters:
+//   - options (EquivalenceOptions): The generic mapping and object type to use.
+//
+// Returns:
+//   - []RedundantEntry: The redundant ACEs, in the order of the DACL.
+//   - error: An error if the DACL holds an ACE whose effect cannot be determined.
+func (dacl *DiscretionaryAccessControlList) RedundantEntries(options EquivalenceOptions) ([]RedundantEntry, error) {
+	redundantEntries := []RedundantEntry{}
+	if dacl == nil {
+		return redundantEntries, nil
+	}
+	entries, err := analyzeEntries(dacl.Entries, options)
+	if err != nil {
+		return nil, err
+	}
+	reference := effectiveAccess(entries)
+
+	for index := range entries {
+		remaining := slices.Delete(slices.Clone(entries), index, index+1)
+		if !effectiveAccessEqual(reference, effectiveAccess(remaining)) {
+			continue
+		}
+		redundantEntries = append(redundantEntries, RedundantEntry{
+			Index: index,
+			Kind:  classifyRedundancy(entries, index),
+			Entry: &dacl.Entries[index],
+		})
+	}
+
+	return redundantEntries, nil
+}
+
+// Minimize returns a DACL giving the same effective access as the DACL with as few ACEs as
+// possible: the redundant ACEs are removed, then the ACEs of the same type, flags and trustee
+// are merged when the order of the DACL allows it. The result has no redundant ACE, but may
+// not be the smallest of all the equivalent DACLs. The DACL itself is not modified.
+//
+// Parameters:
+//   - options (EquivalenceOptions): The generic mapping and object type to use.
+//
+// Returns:
+//   - *DiscretionaryAccessControlList: The minimized copy of the DACL, nil if the DACL is nil.
+//   - error: An error if the DACL holds an ACE whose effect cannot be determined.
+func (dacl *DiscretionaryAccessControlList) Minimize(options EquivalenceOptions) (*DiscretionaryAccessControlList, error) {
+	if dacl == nil {
+		return nil, nil
+	}
+	entries, err := analyzeEntries(dacl.Entries, options)
+	if err != nil {
+		return nil, err
+	}
+	reference := effectiveAccess(entries)
+
+	// The masks of the merged ACEs, as written in the DACL, by index of the first of them
+	rawMasks := map[int]uint32{}
+	for _, entry := range entries {
+		rawMasks[entry.index] = dacl.Entries[entry.index].Mask.RawValue
+	}
+
+	// Remove the redundant ACEs, starting from the end so that the first of duplicated ACEs is kept
+	for index := len(entries) - 1; index >= 0; index-- {
+		remaining := slices.Delete(slices.Clone(entries), index, index+1)
+		if effectiveAccessEqual(reference, effectiveAccess(remaining)) {
+			entries = remaining
+		}
+	}
+
+	// Merge the ACEs that only differ by their mask, at the position of either of them
+	for merged := true; merged; {
+		merged = false
+		for first := 0; first < len(entries) && !merged; first++ {
+			for second := first + 1; second < len(entries) && !merged; second++ {
+				if entries[first].mergeKey != entries[second].mergeKey {
+					continue
+				}
+				for _, kept := range []int{first, second} {
+					removed := first + second - kept
+					candidate := slices.Clone(entries)
+					candidate[kept].mask |= candidate[removed].mask
+					candidate = slices.Delete(candidate, removed, removed+1)
+					if effectiveAccessEqual(reference, effectiveAccess(candidate)) {
+						rawMasks[entries[kept].index] |= rawMasks[entries[removed].index]
+						entries = candidate
+						merged = true
+						break
+					}
+				}
+			}
+		}
+	}
+
+	minimized := dacl.Clone()
+	minimized.Entries = make([]ace.AccessControlEntry, 0, len(entries))
+	for _, entry := range entries {
+		minimizedEntry := dacl.Entries[entry.index].Clone()
+		if rawMask := rawMasks[entry.index]; rawMask != minimizedEntry.Mask.RawValue {
+			minimizedEntry.Mask.RawValue = rawMask
+			minimizedEntry.Mask.SetNamespace(minimizedEntry.Mask.Namespace)
+		}
+		minimizedEntry.Index = uint16(len(minimized.Entries) + 1)
+		minimized.Entries = append(minimized.Entries, *minimizedEntry)
+	}
+	minimized.Header.AceCount = uint16(len(minimized.Entries))
+	minimized.RawBytes = nil
+	minimized.RawBytesSize = 0
+
+	return minimized, nil
+}
+
+// analyzeEntries reduces the ACEs of a DACL to what the access check uses.
+//
+// Parameters:
+//   - entries ([]ace.AccessControlEntry): The ACEs of the DACL.
+//   - options (EquivalenceOptions): The generic mapping and object type to use.
+//
+// Returns:
+//   - []analyzedEntry: The analyzed ACEs, in order.
+//   - error: An error if an ACE is opaque or compound, as its effect cannot be determined.
+func analyzeEntries(entries []ace.AccessControlEntry, options EquivalenceOptions) ([]analyzedEntry, error) {
+	analyzedEntries := make([]analyzedEntry, 0, len(entries))
+
+	for index := range entries {
+		entry := &entries[index]
+		analyzed := analyzedEntry{index: index}
+
+		if entry.IsAccessAllowedOrDenied() {
+			if entry.Opaque {
+				return nil, fmt.Errorf("cannot analyze ACE #%d: its body of type 0x%02x is malformed and kept opaque", index, entry.Header.Type.Value)
+			}
+			if entry.Header.Type.Value == acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND {
+				return nil, fmt.Errorf("cannot analyze ACE #%d: ACCESS_ALLOWED_COMPOUND ACEs depend on the impersonating server", index)
+			}
+
+			analyzed.allow = !entry.IsAccessDenied()
+			analyzed.mask = rights.MapGenericMask(entry.Mask.RawValue, options.Mapping)
+			analyzed.trustee = entry.Identity.SID.ToString()
+			analyzed.principals = entryPrincipals(entry, analyzed.trustee, options)
+		}
+		// The other ACE types are ignored by the access check and have no principal
+
+		analyzed.mergeKey = fmt.Sprintf("%d|%d|%s|%s", entry.Header.Type.Value, entry.Header.Flags.RawValue, strings.Join(analyzed.principals[:], "|"), hex.EncodeToString(entry.ApplicationData))
+		analyzedEntries = append(analyzedEntries, analyzed)
+	}
+
+	return analyzedEntries, nil
+}
+
+// entryPrincipals returns the principal an allow or deny ACE applies to in each evaluation
+// context, empty where it does not take effect. The principal is the SID of the ACE, qualified
+// by the condition and the object types the ACE depends on.
+func entryPrincipals(entry *ace.AccessControlEntry, trustee string, options EquivalenceOptions) [evaluationContextCount]string {
+	var principals [evaluationContextCount]string
+
+	principal := trustee
+	if entry.IsConditional() {
+		principal += "?" + hex.EncodeToString(entry.ApplicationData)
+	}
+
+	objectTypePresent := entry.IsObjectAce() && entry.AccessControlObjectType.Flags.IsObjectTypePresent()
+	inheritedObjectTypePresent := entry.IsObjectAce() && entry.AccessControlObjectType.Flags.IsInheritedObjectTypePresent()
+
+	objectPrincipal := principal
+	if objectTypePresent {
+		objectPrincipal += "@" + entry.AccessControlObjectType.ObjectType.GUID.ToFormatD()
+	}
+
+	// On the object itself, the object type of the ACE is checked against the one of the object
+	if !entry.HasFlag(aceflags.ACE_FLAG_INHERIT_ONLY) {
+		switch {
+		case !objectTypePresent:
+			principals[evaluationContextSelf] = principal
+		case options.ObjectType == nil:
+			principals[evaluationContextSelf] = objectPrincipal
+		case entry.AccessControlObjectType.ObjectType.GUID.ToFormatD() == options.ObjectType.ToFormatD():
+			principals[evaluationContextSelf] = principal
+		}
+	}
+	if options.IgnoreInheritance {
+		return principals
+	}
+
+	// The inherited ACEs only take effect on the children of the inherited object type
+	childPrincipal := objectPrincipal
+	if inheritedObjectTypePresent {
+		childPrincipal += "#" + entry.AccessControlObjectType.InheritedObjectType.GUID.ToFormatD()
+	}
+	containerInherit := entry.HasFlag(aceflags.ACE_FLAG_CONTAINER_INHERIT)
+	objectInherit := entry.HasFlag(aceflags.ACE_FLAG_OBJECT_INHERIT)
+	propagate := !entry.HasFlag(aceflags.ACE_FLAG_NO_PROPAGATE_INHERIT)
+	if containerInherit {
+		principals[evaluationContextChildContainer] = childPrincipal
+	}
+	if objectInherit {
+		principals[evaluationContextChildObject] = childPrincipal
+	}
+	if containerInherit && propagate {
+		principals[evaluationContextDescendantContainer] = childPrincipal
+	}
+	if objectInherit && propagate {
+		principals[evaluationContextDescendantObject] = childPrincipal
+	}
+
+	return principals
+}
+
+// effectiveAccess computes a canonical form of the access given by a list of ACEs. For each
+// context and right, a token is granted the right through the first allow ACE of a principal
+// it holds enabled, unless it holds one of the principals denied the right before that ACE. The
+// canonical form maps each principal whose first allow ACE can take effect to these denied
+// principals: two lists of ACEs give the same access to every token if and only if their
+// canonical forms are equal.
+//
+// Parameters:
+//   - entries ([]analyzedEntry): The analyzed ACEs.
+//
+// Returns:
+//   - map[string]string: The sorted denied principals, by context, right and allowed principal.
+func effectiveAccess(entries []analyzedEntry) map[string]string {
+	access := map[string]string{}
+
+	for context := 0; context < evaluationContextCount; context++ {
+		for bit := 0; bit < 32; bit++ {
+			right := uint32(1) << bit
+			denied := []string{}
+			allowed := map[string]bool{}
+
+			for _, entry := range entries {
+				principal := entry.principals[context]
+				if principal == "" || entry.mask&right == 0 {
+					continue
+				}
+				if !entry.allow {
+					if !slices.Contains(denied, principal) {
+						denied = append(denied, principal)
+					}
+					continue
+				}
+				if allowed[principal] {
+					continue
+				}
+				allowed[principal] = true
+				// A token holding the principal enabled always holds its trustee, so a deny ACE
+				// for either of them before the allow ACE means it never takes effect
+				if slices.Contains(denied, principal) || slices.Contains(denied, entry.trustee) {
+					continue
+				}
+				sortedDenied := slices.Clone(denied)
+				sort.Strings(sortedDenied)
+				access[fmt.Sprintf("%d|%d|%s", context, bit, principal)] = strings.Join(sortedDenied, ",")
+			}
+		}
+	}
+
+	return access
+}
+
+// effectiveAccessEqual compares two canonical forms computed by effectiveAccess.
+func effectiveAccessEqual(access, other map[string]string) bool {
+	if len(access) != len(other) {
+		return false
+	}
+	for key, denied := range access {
+		if otherDenied, exists := other[key]; !exists || otherDenied != denied {
+			return false
+		}
+	}
+	return true
+}
+
+// classifyRedundancy finds why a redundant ACE can be removed.
+//
+// Parameters:
+//   - entries ([]analyzedEntry): The analyzed ACEs of the DACL.
+//   - index (int): The index of the redundant ACE.
+//
+// Returns:
+//   - RedundancyKind: The reason why the ACE is redundant.
+func classifyRedundancy(entries []analyzedEntry, index int) RedundancyKind {
+	entry := entries[index]
+
+	applicable := false
+	for _, principal := range entry.principals {
+		applicable = applicable || (principal != "" && entry.mask != 0)
+	}
+	if !applicable {
+		return REDUNDANCY_KIND_NOT_APPLICABLE
+	}
+
+	for otherIndex, other := range entries {
+		if otherIndex != index && other.mergeKey == entry.mergeKey && entry.mask&^other.mask == 0 {
+			return REDUNDANCY_KIND_DUPLICATE
+		}
+	}
+
+	// Each right of the ACE must already be decided for its principal by an earlier ACE: an allow
+	// or a deny for an allow ACE, a deny for a deny ACE
+	for context, principal := range entry.principals {
+		if principal == "" {
+			continue
+		}
+		for bit := 0; bit < 32; bit++ {
+			right := uint32(1) << bit
+			if entry.mask&right == 0 {
+				continue
+			}
+			decided := false
+			for _, earlier := range entries[:index] {
+				if earlier.mask&right == 0 {
+					continue
+				}
+				earlierPrincipal := earlier.principals[context]
+				if earlierPrincipal == principal && (entry.allow || !earlier.allow) {
+					decided = true
+				} else if entry.allow && !earlier.allow && earlierPrincipal == entry.trustee {
+					decided = true
+				}
+				if decided {
+					break
+				}
+			}
+			if !decided {
+				return REDUNDANCY_KIND_NO_EFFECT
+			}
+		}
+	}
+	return REDUNDANCY_KIND_SHADOWED
+}
@@ -0,0 +1,236 @@
+package acl_test
+
+import (
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/acl"
+	"github.com/TheManticoreProject/winacl/guid"
+	"github.com/TheManticoreProject/winacl/rights"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+)
+
+// sddlTestDescriptor parses an SDDL string for the tests of the package.
+func sddlTestDescriptor(t *testing.T, sddlString string) *securitydescriptor.NtSecurityDescriptor {
+	t.Helper()
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	if _, err := ntsd.FromSDDLString(sddlString); err != nil {
+		t.Fatalf("FromSDDLString(%q) error = %v", sddlString, err)
+	}
+	return ntsd
+}
+
+func TestDACL_EquivalentTo(t *testing.T) {
+	fileOptions := acl.EquivalenceOptions{Mapping: rights.RIGHTS_NAMESPACE_FILE.GenericMapping()}
+
+	tests := []struct {
+		name    string
+		dacl    string
+		other   string
+		options acl.EquivalenceOptions
+		want    bool
+	}{
+		{
+			name:    "SplitMask",
+			dacl:    "D:(A;;FR;;;BU)(A;;FW;;;BU)",
+			other:   "D:(A;;0x0012019f;;;BU)",
+			options: fileOptions,
+			want:    true,
+		},
+		{
+			name:    "GenericRights",
+			dacl:    "D:(A;;GA;;;BU)",
+			other:   "D:(A;;FA;;;BU)",
+			options: fileOptions,
+			want:    true,
+		},
+		{
+			name:    "GenericRightsWithoutMapping",
+			dacl:    "D:(A;;GA;;;BU)",
+			other:   "D:(A;;FA;;;BU)",
+			options: acl.EquivalenceOptions{},
+			want:    false,
+		},
+		{
+			name:    "Duplicated",
+			dacl:    "D:(A;;FA;;;BU)(A;;FA;;;BU)(A;;FR;;;BU)",
+			other:   "D:(A;;FA;;;BU)",
+			options: fileOptions,
+			want:    true,
+		},
+		{
+			name:    "TrailingDeny",
+			dacl:    "D:(A;;FR;;;AU)(D;;FA;;;WD)",
+			other:   "D:(A;;FR;;;AU)",
+			options: fileOptions,
+			want:    true,
+		},
+		{
+			name:    "DenyMovedAfterAllow",
+			dacl:    "D:(D;;FW;;;BG)(A;;FA;;;AU)",
+			other:   "D:(A;;FA;;;AU)(D;;FW;;;BG)",
+			options: fileOptions,
+			want:    false,
+		},
+		{
+			name:    "IndependentAllowsReordered",
+			dacl:    "D:(A;;FA;;;AU)(A;;FR;;;BU)",
+			other:   "D:(A;;FR;;;BU)(A;;FA;;;AU)",
+			options: fileOptions,
+			want:    true,
+		},
+		{
+			// A token holding BA deny-only, like a filtered administrator token, is denied by the
+			// second ACE, so the deny ACE is not redundant
+			name:    "DenyOnlyToken",
+			dacl:    "D:(A;;FA;;;BA)(D;;FA;;;BA)(A;;FA;;;AU)",
+			other:   "D:(A;;FA;;;BA)(A;;FA;;;AU)",
+			options: fileOptions,
+			want:    false,
+		},
+		{
+			name:    "Inheritance",
+			dacl:    "D:(A;OICI;FA;;;BU)",
+			other:   "D:(A;;FA;;;BU)",
+			options: fileOptions,
+			want:    false,
+		},
+		{
+			name:    "IgnoreInheritance",
+			dacl:    "D:(A;OICI;FA;;;BU)(A;OICIIO;FR;;;AU)",
+			other:   "D:(A;;FA;;;BU)",
+			options: acl.EquivalenceOptions{Mapping: fileOptions.Mapping, IgnoreInheritance: true},
+			want:    true,
+		},
+		{
+			name:    "ObjectAceForAnotherObjectType",
+			dacl:    "D:(A;;RPWP;;;AU)(OA;;CR;00299570-246d-11d0-a768-00aa006e0529;;BU)",
+			other:   "D:(A;;RPWP;;;AU)",
+			options: acl.EquivalenceOptions{ObjectType: guidFromString(t, "bf967aba-0de6-11d0-a285-00aa003049e2")},
+			want:    true,
+		},
+		{
+			name:    "ObjectAceWithoutObjectType",
+			dacl:    "D:(A;;RPWP;;;AU)(OA;;CR;00299570-246d-11d0-a768-00aa006e0529;;BU)",
+			other:   "D:(A;;RPWP;;;AU)",
+			options: acl.EquivalenceOptions{},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dacl := sddlTestDescriptor(t, tt.dacl).DACL
+			other := sddlTestDescriptor(t, tt.other).DACL
+
+			got, err := dacl.EquivalentTo(other, tt.options)
+			if err != nil {
+				t.Fatalf("EquivalentTo() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("EquivalentTo() = %v, want %v", got, tt.want)
+			}
+
+			// Equivalence is symmetric
+			if reverse, _ := other.EquivalentTo(dacl, tt.options); reverse != got {
+				t.Errorf("reverse EquivalentTo() = %v, want %v", reverse, got)
+			}
+		})
+	}
+}
+
+func TestDACL_RedundantEntries(t *testing.T) {
+	dacl := sddlTestDescriptor(t, "D:(D;;FA;;;BG)(A;;FR;;;BG)(A;;FA;;;BU)(A;;FR;;;BU)(A;IO;FA;;;WD)(D;;FW;;;AN)").DACL
+	options := acl.EquivalenceOptions{Mapping: rights.RIGHTS_NAMESPACE_FILE.GenericMapping()}
+
+	redundantEntries, err := dacl.RedundantEntries(options)
+	if err != nil {
+		t.Fatalf("RedundantEntries() error = %v", err)
+	}
+
+	want := map[int]acl.RedundancyKind{
+		1: acl.REDUNDANCY_KIND_SHADOWED,
+		3: acl.REDUNDANCY_KIND_DUPLICATE,
+		4: acl.REDUNDANCY_KIND_NOT_APPLICABLE,
+		5: acl.REDUNDANCY_KIND_NO_EFFECT,
+	}
+	if len(redundantEntries) != len(want) {
+		t.Fatalf("RedundantEntries() = %v, want %d entries", redundantEntries, len(want))
+	}
+	for _, redundantEntry := range redundantEntries {
+		if kind, exists := want[redundantEntry.Index]; !exists || kind != redundantEntry.Kind {
+			t.Errorf("RedundantEntries() holds %s, want %v", redundantEntry, want)
+		}
+		if redundantEntry.Entry != &dacl.Entries[redundantEntry.Index] {
+			t.Errorf("RedundantEntries() entry %d does not point into the DACL", redundantEntry.Index)
+		}
+	}
+}
+
+func TestDACL_Minimize(t *testing.T) {
+	options := acl.EquivalenceOptions{Mapping: rights.RIGHTS_NAMESPACE_FILE.GenericMapping()}
+
+	tests := []struct {
+		name  string
+		dacl  string
+		masks []uint32
+	}{
+		{
+			name:  "RemovesRedundantEntries",
+			dacl:  "D:(D;;FA;;;BG)(A;;FR;;;BG)(A;;FA;;;BU)(A;;FR;;;BU)(A;IO;FA;;;WD)(D;;FW;;;AN)",
+			masks: []uint32{0x001f01ff, 0x001f01ff},
+		},
+		{
+			name:  "MergesSplitMasks",
+			dacl:  "D:(A;;FR;;;BU)(A;;FA;;;AU)(A;;FW;;;BU)",
+			masks: []uint32{0x0012019f, 0x001f01ff},
+		},
+		{
+			// The deny ACE between the allow ACEs of BU prevents merging them
+			name:  "KeepsOrderDependentEntries",
+			dacl:  "D:(A;;FR;;;BU)(D;;FW;;;AN)(A;;FW;;;BU)",
+			masks: []uint32{0x00120089, 0x00120116, 0x00120116},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dacl := sddlTestDescriptor(t, tt.dacl).DACL
+			entryCount := len(dacl.Entries)
+
+			minimized, err := dacl.Minimize(options)
+			if err != nil {
+				t.Fatalf("Minimize() error = %v", err)
+			}
+			if len(dacl.Entries) != entryCount {
+				t.Errorf("Minimize() modified the DACL")
+			}
+
+			if len(minimized.Entries) != len(tt.masks) || int(minimized.Header.AceCount) != len(tt.masks) {
+				t.Fatalf("Minimize() has %d entries (AceCount %d), want %d", len(minimized.Entries), minimized.Header.AceCount, len(tt.masks))
+			}
+			for index, mask := range tt.masks {
+				if minimized.Entries[index].Mask.RawValue != mask {
+					t.Errorf("Minimize() entry %d mask = 0x%08x, want 0x%08x", index, minimized.Entries[index].Mask.RawValue, mask)
+				}
+			}
+
+			equivalent, err := dacl.EquivalentTo(minimized, options)
+			if err != nil || !equivalent {
+				t.Errorf("EquivalentTo(Minimize()) = %v, %v, want true", equivalent, err)
+			}
+			if _, err := minimized.Marshal(); err != nil {
+				t.Errorf("Marshal() of the minimized DACL error = %v", err)
+			}
+		})
+	}
+}
+
+// guidFromString parses a GUID for the equivalence tests.
+func guidFromString(t *testing.T, value string) *guid.GUID {
+	t.Helper()
+	parsed, err := guid.FromString(value)
+	if err != nil {
+		t.Fatalf("FromString(%q) error = %v", value, err)
+	}
+	return parsed
+}
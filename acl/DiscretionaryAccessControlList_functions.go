@@ -1,7 +1,9 @@
 package acl
 
 import (
+	"bytes"
 	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/rights"
 )
 
 // AddEntry adds a new ACE entry to the DiscretionaryAccessControlList.
@@ -46,3 +48,76 @@ func (dacl *DiscretionaryAccessControlList) ClearEntries() {
 	dacl.Entries = []ace.AccessControlEntry{}
 	dacl.Header.AceCount = 0
 }
+
+// SetRightsNamespace sets the rights namespace used to name the access mask bits of every ACE
+// of the DiscretionaryAccessControlList.
+//
+// Parameters:
+//   - namespace (rights.RightsNamespace): The rights namespace of the object the ACL protects.
+func (dacl *DiscretionaryAccessControlList) SetRightsNamespace(namespace rights.RightsNamespace) {
+	for index := range dacl.Entries {
+		dacl.Entries[index].SetRightsNamespace(namespace)
+	}
+}
+
+// MapGenericRights rewrites every ACE of the DiscretionaryAccessControlList into its specific-rights
+// form, using the generic mapping of the namespace of each ACE's mask.
+func (dacl *DiscretionaryAccessControlList) MapGenericRights() {
+	for index := range dacl.Entries {
+		dacl.Entries[index].MapGenericRights()
+	}
+}
+
+// TypedEntries returns the typed views of the ACE entries of the DiscretionaryAccessControlList.
+//
+// Returns:
+//   - []ace.TypedAccessControlEntry: The typed views of the entries, in order.
+//   - error: An error if an entry cannot be converted, see ace.AccessControlEntry.Typed.
+func (dacl *DiscretionaryAccessControlList) TypedEntries() ([]ace.TypedAccessControlEntry, error) {
+	typedEntries := make([]ace.TypedAccessControlEntry, 0, len(dacl.Entries))
+	for index := range dacl.Entries {
+		typed, err := dacl.Entries[index].Typed()
+		if err != nil {
+			return nil, err
+		}
+		typedEntries = append(typedEntries, typed)
+	}
+	return typedEntries, nil
+}
+
+// AddTypedEntry adds the ACE entry of a typed view to the DiscretionaryAccessControlList.
+//
+// Parameters:
+//   - typed (ace.TypedAccessControlEntry): The typed view of the ACE entry to add.
+//
+// Returns:
+//   - error: An error if the typed view cannot be converted into an ACE entry.
+func (dacl *DiscretionaryAccessControlList) AddTypedEntry(typed ace.TypedAccessControlEntry) error {
+	entry, err := typed.ToAccessControlEntry()
+	if err != nil {
+		return err
+	}
+	dacl.AddEntry(*entry)
+	return nil
+}
+
+// Clone returns a deep copy of the DACL, its entries included, sharing no memory with the
+// original.
+//
+// Returns:
+//   - *DiscretionaryAccessControlList: The copy of the DACL, nil if the DACL is nil.
+func (dacl *DiscretionaryAccessControlList) Clone() *DiscretionaryAccessControlList {
+	if dacl == nil {
+		return nil
+	}
+	clone := *dacl
+	clone.Header = *dacl.Header.Clone()
+	if dacl.Entries != nil {
+		clone.Entries = make([]ace.AccessControlEntry, len(dacl.Entries))
+		for index := range dacl.Entries {
+			clone.Entries[index] = *dacl.Entries[index].Clone()
+		}
+	}
+	clone.RawBytes = bytes.Clone(dacl.RawBytes)
+	return &clone
+}
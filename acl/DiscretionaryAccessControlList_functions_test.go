@@ -5,6 +5,7 @@ import (
 
 	"github.com/TheManticoreProject/winacl/ace"
 	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/ace/mask"
 	"github.com/TheManticoreProject/winacl/acl"
 )
 
@@ -128,3 +129,35 @@ func TestDACLRemoveEntry(t *testing.T) {
 		t.Errorf("Expected AceCount of 1 after removing non-existent entry, got %d", dacl.Header.AceCount)
 	}
 }
+
+func TestDACLTypedEntries(t *testing.T) {
+	dacl := acl.DiscretionaryAccessControlList{}
+
+	allowed := &ace.AccessAllowedAce{Mask: mask.AccessControlMask{RawValue: 0x1}}
+	allowed.Identity.SID.FromString("S-1-1-0")
+	if err := dacl.AddTypedEntry(allowed); err != nil {
+		t.Fatalf("AddTypedEntry() error = %v", err)
+	}
+	denied := &ace.AccessDeniedObjectAce{Mask: mask.AccessControlMask{RawValue: 0x2}}
+	denied.Identity.SID.FromString("S-1-5-32-545")
+	if err := dacl.AddTypedEntry(denied); err != nil {
+		t.Fatalf("AddTypedEntry() error = %v", err)
+	}
+	if dacl.Entries[0].Header.Type.Value != acetype.ACE_TYPE_ACCESS_ALLOWED || dacl.Entries[1].Header.Type.Value != acetype.ACE_TYPE_ACCESS_DENIED_OBJECT {
+		t.Errorf("AddTypedEntry() added entries of types 0x%02x and 0x%02x", dacl.Entries[0].Header.Type.Value, dacl.Entries[1].Header.Type.Value)
+	}
+
+	typedEntries, err := dacl.TypedEntries()
+	if err != nil {
+		t.Fatalf("TypedEntries() error = %v", err)
+	}
+	if len(typedEntries) != 2 {
+		t.Fatalf("TypedEntries() returned %d entries, want 2", len(typedEntries))
+	}
+	if entry, ok := typedEntries[0].(*ace.AccessAllowedAce); !ok || entry.Identity.SID.ToString() != "S-1-1-0" {
+		t.Errorf("TypedEntries()[0] = %#v", typedEntries[0])
+	}
+	if _, ok := typedEntries[1].(*ace.AccessDeniedObjectAce); !ok {
+		t.Errorf("TypedEntries()[1] = %T, want *ace.AccessDeniedObjectAce", typedEntries[1])
+	}
+}
@@ -2,9 +2,11 @@ package acl
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/parsing"
 )
 
 // SystemAccessControlList represents a System Access Control List (SACL).
@@ -23,41 +25,46 @@ type SystemAccessControlList struct {
 // Parameters:
 //   - rawBytes ([]byte): The raw byte slice to be parsed.
 func (sacl *SystemAccessControlList) Unmarshal(marshalledData []byte) (int, error) {
+	return sacl.UnmarshalWithContext(marshalledData, parsing.NewContext(parsing.Options{}))
+}
+
+// UnmarshalWithContext parses the SACL like Unmarshal, following the options of the parse
+// context. In lenient mode, the ACEs that can be parsed are kept and the AceCount of the header
+// is lowered to their number, see parsing.Options.
+//
+// Parameters:
+//   - marshalledData ([]byte): The raw byte slice to be parsed.
+//   - ctx (*parsing.Context): The parse context, located at the start of the SACL.
+//
+// Returns:
+//   - int: The number of bytes consumed by the header and the ACEs.
+//   - error: An error if the SACL cannot be parsed.
+func (sacl *SystemAccessControlList) UnmarshalWithContext(marshalledData []byte, ctx *parsing.Context) (int, error) {
+	marshalledData = ctx.Detach(marshalledData)
 	sacl.RawBytesSize = 0
 	sacl.RawBytes = marshalledData
 
 	// Unmarshal the header
 	rawBytesSize, err := sacl.Header.Unmarshal(marshalledData)
 	if err != nil {
-		return 0, err
+		return 0, parsing.WrapParseError(err, 0, "Header", "")
 	}
 	sacl.RawBytesSize += uint32(rawBytesSize)
-	marshalledData = marshalledData[rawBytesSize:]
-
-	// Bound ACE parsing to the region declared by AclSize. The caller hands in
-	// the entire remaining buffer (in a security descriptor the ACL is followed
-	// by the Owner/Group SIDs), so without this bound a corrupt or oversized
-	// AceCount would walk past the ACL and mis-parse adjacent components as ACEs.
-	if int(sacl.Header.AclSize) < rawBytesSize {
-		return 0, fmt.Errorf("invalid SACL: AclSize (%d) is smaller than the header size (%d)", sacl.Header.AclSize, rawBytesSize)
-	}
-	aceRegionLen := int(sacl.Header.AclSize) - rawBytesSize
-	if aceRegionLen > len(marshalledData) {
-		return 0, fmt.Errorf("invalid SACL: AclSize (%d) exceeds available data (%d)", sacl.Header.AclSize, rawBytesSize+len(marshalledData))
+
+	err = checkRevision("SACL", sacl.Header.Revision.Value, ctx)
+	if err != nil {
+		return 0, err
 	}
-	aceData := marshalledData[:aceRegionLen]
 
 	// Unmarshal all ACEs
-	for index := 0; index < int(sacl.Header.AceCount); index++ {
-		entry := ace.AccessControlEntry{}
-		rawBytesSize, err := entry.Unmarshal(aceData)
-		if err != nil {
-			return 0, fmt.Errorf("failed to unmarshal ACE %d/%d within AclSize: %w", index+1, sacl.Header.AceCount, err)
-		}
-		entry.Index = uint16(index + 1)
-		sacl.Entries = append(sacl.Entries, entry)
-		sacl.RawBytesSize += uint32(rawBytesSize)
-		aceData = aceData[rawBytesSize:]
+	entries, entriesSize, err := unmarshalEntries("SACL", sacl.Header.AclSize, sacl.Header.AceCount, rawBytesSize, marshalledData[rawBytesSize:], ctx)
+	if err != nil {
+		return 0, err
+	}
+	sacl.Entries = append(sacl.Entries, entries...)
+	sacl.RawBytesSize += uint32(entriesSize)
+	if len(entries) < int(sacl.Header.AceCount) {
+		sacl.Header.AceCount = uint16(len(entries))
 	}
 
 	sacl.RawBytes = sacl.RawBytes[:sacl.RawBytesSize]
@@ -70,31 +77,96 @@ func (sacl *SystemAccessControlList) Unmarshal(marshalledData []byte) (int, erro
 // Returns:
 //   - []byte: The serialized byte slice representing the SACL.
 func (sacl *SystemAccessControlList) Marshal() ([]byte, error) {
-	var marshalledData []byte
+	marshalledData, err := sacl.AppendBinary(make([]byte, 0, sacl.Size()))
+	if err != nil {
+		return nil, err
+	}
+	sacl.RefreshRawBytes(marshalledData)
+	return marshalledData, nil
+}
 
-	// Marshal the entries
-	for _, ace := range sacl.Entries {
-		bytesStream, err := ace.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		marshalledData = append(marshalledData, bytesStream...)
+// RefreshRawBytes points RawBytes and RawBytesSize at the marshalled bytes of the SACL, and
+// refreshes its header and its entries.
+//
+// Parameters:
+//   - marshalledData ([]byte): The bytes produced by AppendBinary for the SACL.
+func (sacl *SystemAccessControlList) RefreshRawBytes(marshalledData []byte) {
+	sacl.RawBytesSize = uint32(sacl.Size())
+	sacl.RawBytes = marshalledData[:sacl.RawBytesSize]
+	sacl.Header.RefreshRawBytes(marshalledData)
+
+	offset := sacl.Header.Size()
+	for index := range sacl.Entries {
+		sacl.Entries[index].RefreshRawBytes(marshalledData[offset:])
+		offset += sacl.Entries[index].Size()
 	}
+}
 
-	// Marshal the header at the beginning of the serialized data
-	// We need to include the header in the size calculation, it is 8 bytes long
-	totalSize := 8 + len(marshalledData)
+// Size returns the size of the binary representation of the SACL, as produced by Marshal.
+//
+// Returns:
+//   - int: The size of the header and of the entries, in bytes.
+func (sacl *SystemAccessControlList) Size() int {
+	size := sacl.Header.Size()
+	for index := range sacl.Entries {
+		size += sacl.Entries[index].Size()
+	}
+	return size
+}
+
+// AppendBinary appends the binary representation of the SACL to a buffer. When the buffer
+// has Size() bytes of spare capacity, nothing is allocated. Like Marshal, it updates
+// Header.AclSize to the size of the SACL.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: An error if the SACL is too large or one of its entries cannot be serialized.
+func (sacl *SystemAccessControlList) AppendBinary(buffer []byte) ([]byte, error) {
+	// The header holds the size of the whole SACL, it is 8 bytes long
+	totalSize := sacl.Size()
 	if totalSize > 0xFFFF {
 		return nil, fmt.Errorf("SACL too large to marshal: size %d exceeds the uint16 AclSize maximum (65535)", totalSize)
 	}
 	sacl.Header.AclSize = uint16(totalSize)
-	bytesStream, err := sacl.Header.Marshal()
+	buffer, err := sacl.Header.AppendBinary(buffer)
 	if err != nil {
 		return nil, err
 	}
-	marshalledData = append(bytesStream, marshalledData...)
 
-	return marshalledData, nil
+	// Marshal the entries. They are serialized from a copy, so that the padding of
+	// Header.Size done by AccessControlEntry.AppendBinary is not written back.
+	for index := range sacl.Entries {
+		entry := sacl.Entries[index]
+		buffer, err = entry.AppendBinary(buffer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return buffer, nil
+}
+
+// MarshalTo writes the binary representation of the SACL at the start of a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to write to, at least Size() bytes long.
+//
+// Returns:
+//   - int: The number of bytes written.
+//   - error: An error if the buffer is too short or the SACL cannot be serialized.
+func (sacl *SystemAccessControlList) MarshalTo(buffer []byte) (int, error) {
+	size := sacl.Size()
+	if len(buffer) < size {
+		return 0, fmt.Errorf("SACL requires a buffer of %d bytes, got %d: %w", size, len(buffer), io.ErrShortBuffer)
+	}
+	marshalledData, err := sacl.AppendBinary(buffer[:0:size])
+	if err != nil {
+		return 0, err
+	}
+	return len(marshalledData), nil
 }
 
 // Describe prints a detailed description of the SystemAccessControlList struct,
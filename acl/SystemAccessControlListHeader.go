@@ -1,11 +1,13 @@
 package acl
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"strings"
 
 	"github.com/TheManticoreProject/winacl/acl/revision"
+	"github.com/TheManticoreProject/winacl/parsing"
 )
 
 // SystemAccessControlListHeader represents the header of a System Access Control List (SACL).
@@ -31,7 +33,7 @@ type SystemAccessControlListHeader struct {
 func (saclheader *SystemAccessControlListHeader) Unmarshal(marshalledData []byte) (int, error) {
 	// Parsing header
 	if len(marshalledData) < 8 {
-		return 0, fmt.Errorf("invalid raw bytes length")
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "invalid raw bytes length")
 	}
 
 	saclheader.RawBytes = marshalledData[:8]
@@ -39,7 +41,7 @@ func (saclheader *SystemAccessControlListHeader) Unmarshal(marshalledData []byte
 
 	rawBytesSize, err := saclheader.Revision.Unmarshal(marshalledData[:1])
 	if err != nil {
-		return 0, fmt.Errorf("failed to unmarshal Revision: %w", err)
+		return 0, parsing.WrapParseError(err, 0, "Revision", "failed to unmarshal Revision")
 	}
 	saclheader.RawBytesSize += uint32(rawBytesSize)
 
@@ -63,34 +65,65 @@ func (saclheader *SystemAccessControlListHeader) Unmarshal(marshalledData []byte
 // Returns:
 //   - []byte: The serialized byte slice representing the SACL header.
 func (saclheader *SystemAccessControlListHeader) Marshal() ([]byte, error) {
-	var marshalledData []byte
-
-	saclheader.RawBytesSize = 0
-
-	bytesStream, err := saclheader.Revision.Marshal()
+	marshalledData, err := saclheader.AppendBinary(make([]byte, 0, saclheader.Size()))
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal Revision: %w", err)
+		return nil, err
 	}
-	marshalledData = append(marshalledData, bytesStream...)
-	saclheader.RawBytesSize += uint32(len(bytesStream))
+	saclheader.RefreshRawBytes(marshalledData)
+	return marshalledData, nil
+}
 
-	marshalledData = append(marshalledData, saclheader.Sbz1)
-	saclheader.RawBytesSize += 1
+// RefreshRawBytes points RawBytes and RawBytesSize at the 8 marshalled bytes of the SACL header.
+//
+// Parameters:
+//   - marshalledData ([]byte): The bytes produced by AppendBinary for the header.
+func (saclheader *SystemAccessControlListHeader) RefreshRawBytes(marshalledData []byte) {
+	saclheader.RawBytesSize = uint32(saclheader.Size())
+	saclheader.RawBytes = marshalledData[:saclheader.RawBytesSize]
+}
 
-	buffer := make([]byte, 2)
-	binary.LittleEndian.PutUint16(buffer, saclheader.AclSize)
-	marshalledData = append(marshalledData, buffer...)
-	saclheader.RawBytesSize += 2
+// Clone returns a deep copy of the SACL header, sharing no memory with the original.
+//
+// Returns:
+//   - *SystemAccessControlListHeader: The copy of the header, nil if the header is nil.
+func (saclheader *SystemAccessControlListHeader) Clone() *SystemAccessControlListHeader {
+	if saclheader == nil {
+		return nil
+	}
+	clone := *saclheader
+	clone.RawBytes = bytes.Clone(saclheader.RawBytes)
+	return &clone
+}
 
-	binary.LittleEndian.PutUint16(buffer, saclheader.AceCount)
-	marshalledData = append(marshalledData, buffer...)
-	saclheader.RawBytesSize += 2
+// Size returns the size of the binary representation of the SACL header.
+//
+// Returns:
+//   - int: Always 8.
+func (saclheader *SystemAccessControlListHeader) Size() int {
+	return 8
+}
 
-	binary.LittleEndian.PutUint16(buffer, saclheader.Sbz2)
-	marshalledData = append(marshalledData, buffer...)
-	saclheader.RawBytesSize += 2
+// AppendBinary appends the binary representation of the SACL header to a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: An error if the revision cannot be serialized.
+func (saclheader *SystemAccessControlListHeader) AppendBinary(buffer []byte) ([]byte, error) {
+	buffer, err := saclheader.Revision.AppendBinary(buffer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Revision: %w", err)
+	}
 
-	return marshalledData, nil
+	buffer = append(buffer, saclheader.Sbz1)
+	buffer = binary.LittleEndian.AppendUint16(buffer, saclheader.AclSize)
+	buffer = binary.LittleEndian.AppendUint16(buffer, saclheader.AceCount)
+	buffer = binary.LittleEndian.AppendUint16(buffer, saclheader.Sbz2)
+	saclheader.RawBytesSize = uint32(saclheader.Size())
+
+	return buffer, nil
 }
 
 // Describe prints a detailed description of the SystemAccessControlListHeader struct,
@@ -1,6 +1,16 @@
 package acl
 
-import "github.com/TheManticoreProject/winacl/ace"
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/ace/aceflags"
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/ace/conditional"
+	"github.com/TheManticoreProject/winacl/rights"
+	"github.com/TheManticoreProject/winacl/sid"
+)
 
 // AddEntry adds a new ACE entry to the SystemAccessControlList.
 //
@@ -44,3 +54,127 @@ func (sacl *SystemAccessControlList) ClearEntries() {
 	sacl.Entries = []ace.AccessControlEntry{}
 	sacl.Header.AceCount = 0
 }
+
+// SetRightsNamespace sets the rights namespace used to name the access mask bits of every ACE
+// of the SystemAccessControlList.
+//
+// Parameters:
+//   - namespace (rights.RightsNamespace): The rights namespace of the object the ACL protects.
+func (sacl *SystemAccessControlList) SetRightsNamespace(namespace rights.RightsNamespace) {
+	for index := range sacl.Entries {
+		sacl.Entries[index].SetRightsNamespace(namespace)
+	}
+}
+
+// MapGenericRights rewrites every ACE of the SystemAccessControlList into its specific-rights
+// form, using the generic mapping of the namespace of each ACE's mask.
+func (sacl *SystemAccessControlList) MapGenericRights() {
+	for index := range sacl.Entries {
+		sacl.Entries[index].MapGenericRights()
+	}
+}
+
+// EvaluateAccessRestrictions computes the maximum access that the process trust label and access
+// filter ACEs of the SystemAccessControlList leave to a caller, regardless of what the DACL grants.
+//
+// The first process trust label ACE limits the access of callers whose process trust label does
+// not dominate the trust SID of the ACE to the mask of the ACE. Every access filter ACE whose
+// conditional expression does not evaluate to TRUE limits the access to its mask. Inherit-only
+// ACEs are ignored.
+//
+// Parameters:
+//   - processTrustSID (*sid.SID): The process trust label SID of the caller (S-1-19-*), or nil for
+//     an unprotected process.
+//   - context (*conditional.EvaluationContext): The security context used to evaluate the conditions
+//     of the access filter ACEs.
+//
+// Returns:
+//   - uint32: The mask of the access that is not restricted, 0xFFFFFFFF if nothing is restricted.
+//   - error: An error if the conditional expression of an access filter ACE is malformed.
+func (sacl *SystemAccessControlList) EvaluateAccessRestrictions(processTrustSID *sid.SID, context *conditional.EvaluationContext) (uint32, error) {
+	allowedAccess := uint32(0xFFFFFFFF)
+	trustLabelSeen := false
+
+	for index := range sacl.Entries {
+		entry := &sacl.Entries[index]
+		if entry.Header.Flags.RawValue&aceflags.ACE_FLAG_INHERIT_ONLY != 0 {
+			continue
+		}
+
+		switch entry.Header.Type.Value {
+		case acetype.ACE_TYPE_SYSTEM_PROCESS_TRUST_LABEL:
+			if trustLabelSeen {
+				continue
+			}
+			trustLabelSeen = true
+			if processTrustSID == nil || !processTrustSID.ProcessTrustDominates(&entry.Identity.SID) {
+				allowedAccess &= entry.Mask.SpecificRights()
+			}
+
+		case acetype.ACE_TYPE_SYSTEM_ACCESS_FILTER:
+			result, err := entry.EvaluateCondition(context)
+			if err != nil {
+				return 0, fmt.Errorf("failed to evaluate access filter ACE #%d: %w", entry.Index, err)
+			}
+			if result != conditional.EVALUATION_RESULT_TRUE {
+				allowedAccess &= entry.Mask.SpecificRights()
+			}
+		}
+	}
+
+	return allowedAccess, nil
+}
+
+// TypedEntries returns the typed views of the ACE entries of the SystemAccessControlList.
+//
+// Returns:
+//   - []ace.TypedAccessControlEntry: The typed views of the entries, in order.
+//   - error: An error if an entry cannot be converted, see ace.AccessControlEntry.Typed.
+func (sacl *SystemAccessControlList) TypedEntries() ([]ace.TypedAccessControlEntry, error) {
+	typedEntries := make([]ace.TypedAccessControlEntry, 0, len(sacl.Entries))
+	for index := range sacl.Entries {
+		typed, err := sacl.Entries[index].Typed()
+		if err != nil {
+			return nil, err
+		}
+		typedEntries = append(typedEntries, typed)
+	}
+	return typedEntries, nil
+}
+
+// AddTypedEntry adds the ACE entry of a typed view to the SystemAccessControlList.
+//
+// Parameters:
+//   - typed (ace.TypedAccessControlEntry): The typed view of the ACE entry to add.
+//
+// Returns:
+//   - error: An error if the typed view cannot be converted into an ACE entry.
+func (sacl *SystemAccessControlList) AddTypedEntry(typed ace.TypedAccessControlEntry) error {
+	entry, err := typed.ToAccessControlEntry()
+	if err != nil {
+		return err
+	}
+	sacl.AddEntry(*entry)
+	return nil
+}
+
+// Clone returns a deep copy of the SACL, its entries included, sharing no memory with the
+// original.
+//
+// Returns:
+//   - *SystemAccessControlList: The copy of the SACL, nil if the SACL is nil.
+func (sacl *SystemAccessControlList) Clone() *SystemAccessControlList {
+	if sacl == nil {
+		return nil
+	}
+	clone := *sacl
+	clone.Header = *sacl.Header.Clone()
+	if sacl.Entries != nil {
+		clone.Entries = make([]ace.AccessControlEntry, len(sacl.Entries))
+		for index := range sacl.Entries {
+			clone.Entries[index] = *sacl.Entries[index].Clone()
+		}
+	}
+	clone.RawBytes = bytes.Clone(sacl.RawBytes)
+	return &clone
+}
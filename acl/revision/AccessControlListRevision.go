@@ -1,9 +1,10 @@
 package revision
 
-import "fmt"
+import "github.com/TheManticoreProject/winacl/parsing"
 
 const (
 	ACL_REVISION    = 0x02
+	ACL_REVISION3   = 0x03
 	ACL_REVISION_DS = 0x04
 )
 
@@ -18,7 +19,7 @@ type AccessControlListRevision struct {
 //   - rawBytes ([]byte): The byte slice to parse.
 func (aclrev *AccessControlListRevision) Unmarshal(marshalledData []byte) (int, error) {
 	if len(marshalledData) < 1 {
-		return 0, fmt.Errorf("AccessControlListRevision unmarshal requires at least 1 byte, got %d", len(marshalledData))
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "AccessControlListRevision unmarshal requires at least 1 byte, got %d", len(marshalledData))
 	}
 	aclrev.Value = uint8(marshalledData[0])
 
@@ -30,11 +31,27 @@ func (aclrev *AccessControlListRevision) Unmarshal(marshalledData []byte) (int,
 // Returns:
 //   - []byte: The serialized byte slice representing the ACL revision.
 func (aclrev *AccessControlListRevision) Marshal() ([]byte, error) {
-	var marshalledData []byte
+	return aclrev.AppendBinary(make([]byte, 0, aclrev.Size()))
+}
 
-	marshalledData = append(marshalledData, aclrev.Value)
+// Size returns the size of the binary representation of the AccessControlListRevision.
+//
+// Returns:
+//   - int: Always 1.
+func (aclrev *AccessControlListRevision) Size() int {
+	return 1
+}
 
-	return marshalledData, nil
+// AppendBinary appends the binary representation of the AccessControlListRevision to a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: Always nil.
+func (aclrev *AccessControlListRevision) AppendBinary(buffer []byte) ([]byte, error) {
+	return append(buffer, aclrev.Value), nil
 }
 
 // String returns the string representation of the AccessControlListRevision struct.
@@ -44,6 +61,8 @@ func (aclrev *AccessControlListRevision) Marshal() ([]byte, error) {
 func (aclrev *AccessControlListRevision) String() string {
 	if aclrev.Value == ACL_REVISION_DS {
 		return "ACL_REVISION_DS"
+	} else if aclrev.Value == ACL_REVISION3 {
+		return "ACL_REVISION3"
 	} else if aclrev.Value == ACL_REVISION {
 		return "ACL_REVISION"
 	} else {
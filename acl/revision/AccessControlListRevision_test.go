@@ -119,6 +119,11 @@ func TestAccessControlListRevisionString(t *testing.T) {
 			revision: revision.ACL_REVISION_DS,
 			expected: "ACL_REVISION_DS",
 		},
+		{
+			name:     "ACL_REVISION3",
+			revision: revision.ACL_REVISION3,
+			expected: "ACL_REVISION3",
+		},
 		{
 			name:     "Unknown revision",
 			revision: 0x01,
@@ -0,0 +1,232 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"iter"
+	"runtime"
+	"sync"
+
+	"github.com/TheManticoreProject/winacl/parsing"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+)
+
+// AnalyzeFunc analyses a parsed security descriptor. It is called concurrently by the workers
+// of a batch, so it must only share state through safe structures like the Caches.
+//
+// Parameters:
+//   - ctx (context.Context): The context of the batch, cancelled when the batch is stopped.
+//   - id (string): The identifier of the object of the descriptor, like its distinguished name.
+//   - ntsd (*securitydescriptor.NtSecurityDescriptor): The parsed security descriptor.
+//   - caches (*Caches): The caches shared by the workers.
+//
+// Returns:
+//   - any: The result of the analysis, see Result.Analysis.
+//   - error: An error if the analysis failed, see Result.Err.
+type AnalyzeFunc func(ctx context.Context, id string, ntsd *securitydescriptor.NtSecurityDescriptor, caches *Caches) (any, error)
+
+// Options configures a batch.
+//
+// Attributes:
+//   - Workers (int): The number of descriptors parsed and analysed concurrently,
+//     runtime.GOMAXPROCS(0) if 0 or less.
+//   - Buffer (int): The number of results that can wait to be consumed before the workers
+//     block, Workers if 0 or less. Blocked workers stop pulling items from the input, which
+//     bounds the memory used when the consumer is slower than the workers.
+//   - Parsing (parsing.Options): The options used to parse the descriptors, see
+//     securitydescriptor.NtSecurityDescriptor.UnmarshalWithOptions.
+//   - Analyze (AnalyzeFunc): The analysis run on each parsed descriptor. The descriptors are
+//     only parsed if nil.
+//   - Caches (*Caches): The caches passed to Analyze, NewCaches(nil, nil) if nil.
+type Options struct {
+	Workers int
+	Buffer  int
+	Parsing parsing.Options
+	Analyze AnalyzeFunc
+	Caches  *Caches
+}
+
+// Result is the outcome of the parsing and analysis of one descriptor of a batch.
+//
+// Attributes:
+//   - Index (int): The position of the descriptor in the input. Results are delivered in the
+//     order they complete, Index restores the order of the input.
+//   - ID (string): The identifier of the object, as given by the input.
+//   - Descriptor (*securitydescriptor.NtSecurityDescriptor): The parsed descriptor, nil if
+//     it cannot be parsed.
+//   - Anomalies (parsing.Anomalies): The anomalies recorded by a lenient parse.
+//   - Analysis (any): The value returned by Options.Analyze.
+//   - Err (error): The parsing or analysis error, nil on success.
+type Result struct {
+	Index      int
+	ID         string
+	Descriptor *securitydescriptor.NtSecurityDescriptor
+	Anomalies  parsing.Anomalies
+	Analysis   any
+	Err        error
+}
+
+// job is a descriptor waiting for a worker.
+type job struct {
+	index int
+	id    string
+	data  []byte
+}
+
+// Stream parses and analyses the descriptors of an iterator with a pool of workers, and
+// streams the results on the returned channel, which is closed once all the descriptors are
+// processed or the context is cancelled.
+//
+// The input is pulled lazily, as workers become available: at most Workers descriptors are
+// in flight and Buffer results wait to be consumed. The parsed descriptors reference the
+// bytes yielded by the iterator, which must not be modified once yielded. When the Detach
+// parse option is set, see parsing.Options, the bytes are copied as they are pulled, before
+// the iterator resumes, so that it may reuse its buffer.
+//
+// The caller must either consume the channel until it is closed, or cancel the context, to
+// release the workers.
+//
+// Parameters:
+//   - ctx (context.Context): The context of the batch. Once cancelled, no new descriptor is
+//     pulled from the input and the pending results are dropped.
+//   - items (iter.Seq2[string, []byte]): The identifiers and marshalled bytes of the descriptors.
+//   - options (Options): The configuration of the batch.
+//
+// Returns:
+//   - <-chan Result: The results, in completion order.
+func Stream(ctx context.Context, items iter.Seq2[string, []byte], options Options) <-chan Result {
+	workers := options.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	buffer := options.Buffer
+	if buffer <= 0 {
+		buffer = workers
+	}
+	if options.Caches == nil {
+		options.Caches = NewCaches(nil, nil)
+	}
+
+	jobs := make(chan job)
+	results := make(chan Result, buffer)
+
+	// The workers parse the jobs after the iterator has resumed, so the bytes are detached
+	// by the producer, and the parsed descriptors reference this copy
+	detach := options.Parsing.Detach
+	options.Parsing.Detach = false
+
+	// Producer, pulls the input as the workers take the jobs
+	go func() {
+		defer close(jobs)
+		index := 0
+		for id, data := range items {
+			if detach {
+				data = bytes.Clone(data)
+			}
+			select {
+			case jobs <- job{index: index, id: id, data: data}:
+			case <-ctx.Done():
+				return
+			}
+			index++
+		}
+	}()
+
+	// Workers
+	waitGroup := sync.WaitGroup{}
+	for range workers {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for job := range jobs {
+				if ctx.Err() != nil {
+					return
+				}
+				result := process(ctx, job, options)
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		waitGroup.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// process parses and analyses the descriptor of a job.
+//
+// Parameters:
+//   - ctx (context.Context): The context of the batch.
+//   - job (job): The descriptor to process.
+//   - options (Options): The configuration of the batch, with its caches set.
+//
+// Returns:
+//   - Result: The result of the job.
+func process(ctx context.Context, job job, options Options) Result {
+	result := Result{Index: job.index, ID: job.id}
+
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	_, anomalies, err := ntsd.UnmarshalWithOptions(job.data, options.Parsing)
+	result.Anomalies = anomalies
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Descriptor = ntsd
+
+	if options.Analyze != nil {
+		result.Analysis, result.Err = options.Analyze(ctx, job.id, ntsd, options.Caches)
+	}
+	return result
+}
+
+// Process runs a batch like Stream and passes each result to a handler, on the calling
+// goroutine. The batch stops at the first error returned by the handler.
+//
+// Parameters:
+//   - ctx (context.Context): The context of the batch.
+//   - items (iter.Seq2[string, []byte]): The identifiers and marshalled bytes of the descriptors.
+//   - options (Options): The configuration of the batch.
+//   - handle (func(Result) error): The handler of the results, in completion order.
+//
+// Returns:
+//   - error: The error of the handler, the error of the context if it was cancelled, or nil.
+func Process(ctx context.Context, items iter.Seq2[string, []byte], options Options, handle func(Result) error) error {
+	batchContext, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := Stream(batchContext, items, options)
+	for result := range results {
+		if err := handle(result); err != nil {
+			cancel()
+			// Release the workers blocked on the channel
+			for range results {
+			}
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// Validate is an AnalyzeFunc checking each descriptor with
+// securitydescriptor.NtSecurityDescriptor.Validate.
+//
+// Parameters:
+//   - ctx (context.Context): The context of the batch.
+//   - id (string): The identifier of the object.
+//   - ntsd (*securitydescriptor.NtSecurityDescriptor): The parsed security descriptor.
+//   - caches (*Caches): The caches shared by the workers, unused.
+//
+// Returns:
+//   - any: The validation.Findings of the descriptor.
+//   - error: Always nil.
+func Validate(ctx context.Context, id string, ntsd *securitydescriptor.NtSecurityDescriptor, caches *Caches) (any, error) {
+	return ntsd.Validate(), nil
+}
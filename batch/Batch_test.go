@@ -0,0 +1,218 @@
+package batch_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TheManticoreProject/winacl/batch"
+	"github.com/TheManticoreProject/winacl/parsing"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+	"github.com/TheManticoreProject/winacl/validation"
+)
+
+// batchTestDescriptor returns the marshalled bytes of a descriptor.
+func batchTestDescriptor(t *testing.T, sddlString string) []byte {
+	t.Helper()
+	ntsd := securitydescriptor.NtSecurityDescriptor{}
+	if _, err := ntsd.FromSDDLString(sddlString); err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+	marshalledData, err := ntsd.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	return marshalledData
+}
+
+// countingItems yields count copies of a descriptor and counts the items pulled.
+func countingItems(count int, marshalledData []byte, pulled *atomic.Int64) iter.Seq2[string, []byte] {
+	return func(yield func(string, []byte) bool) {
+		for index := 0; index < count; index++ {
+			pulled.Add(1)
+			if !yield(fmt.Sprintf("CN=object%d", index), marshalledData) {
+				return
+			}
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	valid := batchTestDescriptor(t, "O:BAG:BAD:(A;;FA;;;WD)(A;;FA;;;SY)")
+	items := func(yield func(string, []byte) bool) {
+		for index := 0; index < 100; index++ {
+			data := valid
+			if index%10 == 3 {
+				data = valid[:10]
+			}
+			if !yield(fmt.Sprintf("CN=object%d", index), data) {
+				return
+			}
+		}
+	}
+
+	seen := make(map[int]bool)
+	failed := 0
+	for result := range batch.Stream(context.Background(), items, batch.Options{Workers: 4, Analyze: batch.Validate}) {
+		if seen[result.Index] || result.ID != fmt.Sprintf("CN=object%d", result.Index) {
+			t.Errorf("unexpected result %d %s", result.Index, result.ID)
+		}
+		seen[result.Index] = true
+		if result.Index%10 == 3 {
+			if !errors.Is(result.Err, parsing.ErrTruncated) || result.Descriptor != nil {
+				t.Errorf("result %d error = %v, want a truncated error", result.Index, result.Err)
+			}
+			failed++
+			continue
+		}
+		if result.Err != nil || result.Descriptor == nil || len(result.Descriptor.DACL.Entries) != 2 {
+			t.Errorf("result %d = %v, %v", result.Index, result.Descriptor, result.Err)
+		}
+		if _, ok := result.Analysis.(validation.Findings); !ok {
+			t.Errorf("result %d Analysis = %T, want validation.Findings", result.Index, result.Analysis)
+		}
+	}
+	if len(seen) != 100 || failed != 10 {
+		t.Errorf("Stream() delivered %d results, %d failed", len(seen), failed)
+	}
+}
+
+func TestStream_Backpressure(t *testing.T) {
+	valid := batchTestDescriptor(t, "O:BAD:(A;;FA;;;WD)")
+	pulled := atomic.Int64{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results := batch.Stream(ctx, countingItems(1000, valid, &pulled), batch.Options{Workers: 2, Buffer: 2})
+	// Nothing is consumed: the workers fill the buffer and block, then the producer blocks.
+	time.Sleep(50 * time.Millisecond)
+	// 2 buffered results, 2 blocked workers and 1 job waiting in the producer.
+	if count := pulled.Load(); count > 5 {
+		t.Errorf("%d items pulled without consumer, want at most 5", count)
+	}
+
+	<-results
+	time.Sleep(20 * time.Millisecond)
+	if count := pulled.Load(); count > 6 {
+		t.Errorf("%d items pulled after one result, want at most 6", count)
+	}
+}
+
+func TestStream_Cancel(t *testing.T) {
+	valid := batchTestDescriptor(t, "O:BAD:(A;;FA;;;WD)")
+	pulled := atomic.Int64{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results := batch.Stream(ctx, countingItems(100000, valid, &pulled), batch.Options{Workers: 4})
+	received := 0
+	for range results {
+		received++
+		if received == 10 {
+			cancel()
+		}
+	}
+	if count := pulled.Load(); count >= 100000 {
+		t.Errorf("the input was fully pulled after cancellation")
+	}
+}
+
+func TestProcess(t *testing.T) {
+	valid := batchTestDescriptor(t, "O:BAD:(A;;FA;;;WD)")
+	pulled := atomic.Int64{}
+	stop := errors.New("stop")
+
+	handled := 0
+	err := batch.Process(context.Background(), countingItems(100000, valid, &pulled), batch.Options{Workers: 4}, func(result batch.Result) error {
+		handled++
+		if handled == 5 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || handled != 5 {
+		t.Errorf("Process() = %v after %d results, want the handler error after 5", err, handled)
+	}
+	if count := pulled.Load(); count >= 100000 {
+		t.Errorf("the input was fully pulled after the handler error")
+	}
+
+	handled = 0
+	err = batch.Process(context.Background(), countingItems(50, valid, &pulled), batch.Options{}, func(result batch.Result) error {
+		handled++
+		return nil
+	})
+	if err != nil || handled != 50 {
+		t.Errorf("Process() = %v after %d results, want nil after 50", err, handled)
+	}
+}
+
+func TestStream_SharedCaches(t *testing.T) {
+	valid := batchTestDescriptor(t, "O:BAD:(A;;FA;;;WD)(A;;FA;;;S-1-5-21-1-2-3-1104)(OA;;CR;00299570-246d-11d0-a768-00aa006e0529;;WD)")
+	pulled := atomic.Int64{}
+	resolved := atomic.Int64{}
+	caches := batch.NewCaches(func(sidString string) (string, error) {
+		resolved.Add(1)
+		if name, err := batch.ResolveWellKnownSID(sidString); err == nil {
+			return name, nil
+		}
+		return "user-" + sidString, nil
+	}, nil)
+
+	analyze := func(ctx context.Context, id string, ntsd *securitydescriptor.NtSecurityDescriptor, caches *batch.Caches) (any, error) {
+		names := []string{}
+		for _, entry := range ntsd.DACL.Entries {
+			name, err := caches.SIDs.Resolve(entry.Identity.SID.ToString())
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+			if entry.IsObjectAce() {
+				names = append(names, caches.GUIDs.Lookup(entry.AccessControlObjectType.ObjectType.GUID.ToFormatD()))
+			}
+		}
+		return names, nil
+	}
+
+	for result := range batch.Stream(context.Background(), countingItems(200, valid, &pulled), batch.Options{Workers: 8, Analyze: analyze, Caches: caches}) {
+		names, _ := result.Analysis.([]string)
+		if result.Err != nil || len(names) != 4 || names[1] != "user-S-1-5-21-1-2-3-1104" || names[3] != "EXTENDED_RIGHT_USER_FORCE_CHANGE_PASSWORD" {
+			t.Errorf("result %d = %v, %v", result.Index, names, result.Err)
+		}
+	}
+	if resolved.Load() != 2 || caches.SIDs.Len() != 2 {
+		t.Errorf("resolver called %d times for %d SIDs, want 2", resolved.Load(), caches.SIDs.Len())
+	}
+}
+
+func TestStream_DetachReusedBuffer(t *testing.T) {
+	descriptors := [][]byte{
+		batchTestDescriptor(t, "O:BAD:(A;;FA;;;WD)"),
+		batchTestDescriptor(t, "O:BAD:(A;;FA;;;SY)"),
+	}
+	// The iterator reuses its buffer, which Detach makes safe
+	items := func(yield func(string, []byte) bool) {
+		buffer := make([]byte, len(descriptors[0]))
+		for index := 0; index < 200; index++ {
+			copy(buffer, descriptors[index%2])
+			if !yield(fmt.Sprintf("CN=object%d", index), buffer) {
+				return
+			}
+		}
+	}
+
+	options := batch.Options{Workers: 4, Parsing: parsing.Options{Detach: true}}
+	for result := range batch.Stream(context.Background(), items, options) {
+		if result.Err != nil {
+			t.Fatalf("result %d error = %v", result.Index, result.Err)
+		}
+		want := []string{"S-1-1-0", "S-1-5-18"}[result.Index%2]
+		if got := result.Descriptor.DACL.Entries[0].Identity.SID.ToString(); got != want {
+			t.Errorf("result %d trustee = %s, want %s", result.Index, got, want)
+		}
+	}
+}
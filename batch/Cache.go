@@ -0,0 +1,212 @@
+package batch
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/TheManticoreProject/winacl/schema"
+	"github.com/TheManticoreProject/winacl/sid"
+)
+
+// ErrSIDNotResolved is returned by the default SID resolver for the SIDs that are not well-known.
+var ErrSIDNotResolved = errors.New("SID not resolved")
+
+// Cache memoizes the results of a load function. It is safe for concurrent use: the load
+// function is called at most once per key, concurrent lookups of a key being loaded wait for
+// its result. Errors are cached like values, so that a key failing to resolve is not
+// retried by every worker of a batch.
+type Cache[K comparable, V any] struct {
+	load func(K) (V, error)
+
+	lock    sync.Mutex
+	entries map[K]*cacheEntry[V]
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// cacheEntry is the memoized result of the load function for a key.
+type cacheEntry[V any] struct {
+	once  sync.Once
+	value V
+	err   error
+}
+
+// NewCache creates an empty cache.
+//
+// Parameters:
+//   - load (func(K) (V, error)): The function computing the value of a key on a cache miss.
+//
+// Returns:
+//   - *Cache[K, V]: The cache.
+func NewCache[K comparable, V any](load func(K) (V, error)) *Cache[K, V] {
+	return &Cache[K, V]{
+		load:    load,
+		entries: make(map[K]*cacheEntry[V]),
+	}
+}
+
+// Get returns the value of a key, loading it on the first lookup.
+//
+// Parameters:
+//   - key (K): The key to look up.
+//
+// Returns:
+//   - V: The value of the key.
+//   - error: The error returned by the load function for the key.
+func (cache *Cache[K, V]) Get(key K) (V, error) {
+	cache.lock.Lock()
+	entry, exists := cache.entries[key]
+	if !exists {
+		entry = &cacheEntry[V]{}
+		cache.entries[key] = entry
+	}
+	cache.lock.Unlock()
+
+	if exists {
+		cache.hits.Add(1)
+	} else {
+		cache.misses.Add(1)
+	}
+
+	entry.once.Do(func() {
+		entry.value, entry.err = cache.load(key)
+	})
+	return entry.value, entry.err
+}
+
+// Len returns the number of keys in the cache.
+//
+// Returns:
+//   - int: The number of keys looked up so far.
+func (cache *Cache[K, V]) Len() int {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	return len(cache.entries)
+}
+
+// Stats returns the number of lookups answered from the cache and the number of lookups that
+// called the load function.
+//
+// Returns:
+//   - uint64: The number of cache hits.
+//   - uint64: The number of cache misses.
+func (cache *Cache[K, V]) Stats() (uint64, uint64) {
+	return cache.hits.Load(), cache.misses.Load()
+}
+
+// SIDResolver resolves a SID, in its string form like "S-1-5-21-...-1104", to the name of the
+// account, for example with an LDAP lookup.
+type SIDResolver func(sidString string) (string, error)
+
+// ResolveWellKnownSID is the default SIDResolver. It only resolves the well-known SIDs, see
+// sid.WellKnownSIDs, and returns ErrSIDNotResolved for the others.
+//
+// Parameters:
+//   - sidString (string): The SID to resolve.
+//
+// Returns:
+//   - string: The name of the well-known SID.
+//   - error: ErrSIDNotResolved if the SID is not well-known.
+func ResolveWellKnownSID(sidString string) (string, error) {
+	if name, exists := sid.WellKnownSIDs[sidString]; exists {
+		return name, nil
+	}
+	return "", ErrSIDNotResolved
+}
+
+// SIDCache memoizes the resolution of SIDs to account names. It is safe for concurrent use.
+type SIDCache struct {
+	*Cache[string, string]
+}
+
+// NewSIDCache creates an empty SID cache.
+//
+// Parameters:
+//   - resolver (SIDResolver): The resolver called on a cache miss, ResolveWellKnownSID if nil.
+//
+// Returns:
+//   - *SIDCache: The cache.
+func NewSIDCache(resolver SIDResolver) *SIDCache {
+	if resolver == nil {
+		resolver = ResolveWellKnownSID
+	}
+	return &SIDCache{Cache: NewCache(func(sidString string) (string, error) {
+		return resolver(sidString)
+	})}
+}
+
+// Resolve returns the name of the account of a SID.
+//
+// Parameters:
+//   - sidString (string): The SID, in its string form.
+//
+// Returns:
+//   - string: The name of the account.
+//   - error: The error of the resolver.
+func (cache *SIDCache) Resolve(sidString string) (string, error) {
+	return cache.Get(strings.ToUpper(sidString))
+}
+
+// GUIDCache memoizes the lookup of the names of the GUIDs found in object ACEs: extended
+// rights, property sets, schema classes and attributes. It is safe for concurrent use.
+type GUIDCache struct {
+	*Cache[string, string]
+}
+
+// NewGUIDCache creates an empty GUID cache.
+//
+// Parameters:
+//   - registry (*schema.Registry): The registry used to name the GUIDs, the default
+//     registry of the process if nil.
+//
+// Returns:
+//   - *GUIDCache: The cache.
+func NewGUIDCache(registry *schema.Registry) *GUIDCache {
+	return &GUIDCache{Cache: NewCache(func(formatD string) (string, error) {
+		lookupRegistry := registry
+		if lookupRegistry == nil {
+			lookupRegistry = schema.GetDefaultRegistry()
+		}
+		return lookupRegistry.LookupName(formatD), nil
+	})}
+}
+
+// Lookup returns the name of a GUID.
+//
+// Parameters:
+//   - formatD (string): The GUID, in D format like "bf967a86-0de6-11d0-a285-00aa003049e2".
+//
+// Returns:
+//   - string: The name of the GUID, as returned by schema.Registry.LookupName.
+func (cache *GUIDCache) Lookup(formatD string) string {
+	name, _ := cache.Get(strings.ToLower(formatD))
+	return name
+}
+
+// Caches groups the caches shared by the workers of a batch.
+//
+// Attributes:
+//   - SIDs (*SIDCache): The cache of the names of the SIDs.
+//   - GUIDs (*GUIDCache): The cache of the names of the GUIDs.
+type Caches struct {
+	SIDs  *SIDCache
+	GUIDs *GUIDCache
+}
+
+// NewCaches creates empty caches.
+//
+// Parameters:
+//   - resolver (SIDResolver): The resolver of the SID cache, ResolveWellKnownSID if nil.
+//   - registry (*schema.Registry): The registry of the GUID cache, the default registry if nil.
+//
+// Returns:
+//   - *Caches: The caches.
+func NewCaches(resolver SIDResolver, registry *schema.Registry) *Caches {
+	return &Caches{
+		SIDs:  NewSIDCache(resolver),
+		GUIDs: NewGUIDCache(registry),
+	}
+}
@@ -0,0 +1,58 @@
+package batch_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/batch"
+)
+
+func TestCache_ConcurrentLoadOnce(t *testing.T) {
+	loads := atomic.Int64{}
+	cache := batch.NewCache(func(key int) (string, error) {
+		loads.Add(1)
+		if key < 0 {
+			return "", errors.New("negative key")
+		}
+		return fmt.Sprintf("value-%d", key), nil
+	})
+
+	waitGroup := sync.WaitGroup{}
+	for worker := 0; worker < 16; worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for key := -2; key < 50; key++ {
+				value, err := cache.Get(key)
+				if key < 0 && err == nil {
+					t.Errorf("Get(%d) error = nil", key)
+				}
+				if key >= 0 && (err != nil || value != fmt.Sprintf("value-%d", key)) {
+					t.Errorf("Get(%d) = %q, %v", key, value, err)
+				}
+			}
+		}()
+	}
+	waitGroup.Wait()
+
+	if loads.Load() != 52 || cache.Len() != 52 {
+		t.Errorf("load called %d times for %d keys, want 52", loads.Load(), cache.Len())
+	}
+	hits, misses := cache.Stats()
+	if misses != 52 || hits != 15*52 {
+		t.Errorf("Stats() = %d hits, %d misses", hits, misses)
+	}
+}
+
+func TestSIDCache_WellKnown(t *testing.T) {
+	cache := batch.NewSIDCache(nil)
+	if name, err := cache.Resolve("s-1-5-18"); err != nil || name == "" {
+		t.Errorf("Resolve(S-1-5-18) = %q, %v", name, err)
+	}
+	if _, err := cache.Resolve("S-1-5-21-1-2-3-1104"); !errors.Is(err, batch.ErrSIDNotResolved) {
+		t.Errorf("Resolve() error = %v, want ErrSIDNotResolved", err)
+	}
+}
@@ -0,0 +1,448 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/TheManticoreProject/winacl/ace/aceflags"
+	"github.com/TheManticoreProject/winacl/acl"
+	"github.com/TheManticoreProject/winacl/acl/revision"
+	"github.com/TheManticoreProject/winacl/identity"
+	"github.com/TheManticoreProject/winacl/rights"
+	"github.com/TheManticoreProject/winacl/schema"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+	"github.com/TheManticoreProject/winacl/securitydescriptor/control"
+	"github.com/TheManticoreProject/winacl/sid"
+)
+
+// EntryKind is the kind of ACE added by a Builder.
+type EntryKind uint8
+
+const (
+	// ENTRY_KIND_ALLOW adds an access allowed ACE to the DACL.
+	ENTRY_KIND_ALLOW EntryKind = iota
+	// ENTRY_KIND_DENY adds an access denied ACE to the DACL.
+	ENTRY_KIND_DENY
+	// ENTRY_KIND_AUDIT adds a system audit ACE to the SACL.
+	ENTRY_KIND_AUDIT
+)
+
+// EntryKindNames maps the entry kinds to their names.
+var EntryKindNames = map[EntryKind]string{
+	ENTRY_KIND_ALLOW: "ALLOW",
+	ENTRY_KIND_DENY:  "DENY",
+	ENTRY_KIND_AUDIT: "AUDIT",
+}
+
+// String returns the name of the entry kind.
+//
+// Returns:
+//   - string: The name of the entry kind, or "?" if it is unknown.
+func (kind EntryKind) String() string {
+	if name, exists := EntryKindNames[kind]; exists {
+		return name
+	}
+	return "?"
+}
+
+// builderEntry is an ACE recorded by a Builder, added to the descriptor by Build.
+type builderEntry struct {
+	kind       EntryKind
+	trustee    string
+	rule       acl.AccessRule
+	auditFlags uint8
+}
+
+// Builder builds a security descriptor step by step with readable names instead of nested
+// structures: trustees are SDDL aliases, SID strings or well-known names, rights are SDDL
+// tokens or right names, and object types are schema names or GUIDs.
+//
+// Each call is validated immediately, and the problems are reported together by Err and Build, so
+// that a chain of calls needs a single error check:
+//
+//	ntsd, err := builder.NewBuilder().
+//		Owner("BA").
+//		Group("SY").
+//		Allow("SY", "GA").
+//		Allow("S-1-5-21-1-2-3-1105", "RP", "WP").ObjectType("Personal-Information").Inherit("CI").InheritedObjectType("user").
+//		Allow("AU", "CR").ObjectType("User-Change-Password").
+//		AuditFailure("WD", "WD", "WO").
+//		Build()
+//
+// ACEs are added with the high-level edit operations of the ACLs, like
+// acl.DiscretionaryAccessControlList.AddAccess: they are placed in canonical order and ACEs of
+// the same trustee are merged when possible.
+type Builder struct {
+	registry  *schema.Registry
+	namespace rights.RightsNamespace
+
+	owner *sid.SID
+	group *sid.SID
+
+	control uint16
+	entries []builderEntry
+
+	errs []error
+}
+
+// NewBuilder creates a Builder for an empty security descriptor. Object types are resolved
+// with the default schema registry, and right names with the directory service namespace.
+//
+// Returns:
+//   - *Builder: A pointer to the newly created builder.
+func NewBuilder() *Builder {
+	return &Builder{
+		registry:  schema.GetDefaultRegistry(),
+		namespace: rights.RIGHTS_NAMESPACE_DIRECTORY_SERVICE,
+	}
+}
+
+// WithRegistry sets the schema registry used to resolve the object types given by name.
+//
+// Parameters:
+//   - registry (*schema.Registry): The registry, the default registry if nil.
+//
+// Returns:
+//   - *Builder: The builder, for chaining.
+func (builder *Builder) WithRegistry(registry *schema.Registry) *Builder {
+	if registry == nil {
+		registry = schema.GetDefaultRegistry()
+	}
+	builder.registry = registry
+	return builder
+}
+
+// WithNamespace sets the namespace of the right names given after this call, like
+// FILE_GENERIC_READ in the FILE namespace. The standard, generic and directory service right
+// names and the SDDL tokens are recognised in every namespace.
+//
+// Parameters:
+//   - namespace (rights.RightsNamespace): The namespace of the right names.
+//
+// Returns:
+//   - *Builder: The builder, for chaining.
+func (builder *Builder) WithNamespace(namespace rights.RightsNamespace) *Builder {
+	builder.namespace = namespace
+	return builder
+}
+
+// Owner sets the owner of the security descriptor.
+//
+// Parameters:
+//   - trustee (string): The SDDL alias (e.g. "BA"), SID string or well-known name of the owner.
+//
+// Returns:
+//   - *Builder: The builder, for chaining.
+func (builder *Builder) Owner(trustee string) *Builder {
+	owner, err := ResolveTrustee(trustee)
+	if err != nil {
+		builder.Fail(fmt.Errorf("owner: %w", err))
+		return builder
+	}
+	builder.owner = owner
+	return builder
+}
+
+// Group sets the primary group of the security descriptor.
+//
+// Parameters:
+//   - trustee (string): The SDDL alias (e.g. "DA"), SID string or well-known name of the group.
+//
+// Returns:
+//   - *Builder: The builder, for chaining.
+func (builder *Builder) Group(trustee string) *Builder {
+	group, err := ResolveTrustee(trustee)
+	if err != nil {
+		builder.Fail(fmt.Errorf("group: %w", err))
+		return builder
+	}
+	builder.group = group
+	return builder
+}
+
+// ProtectDACL sets the SE_DACL_PROTECTED control flag, which blocks the inheritance of ACEs
+// from the parent object into the DACL.
+//
+// Returns:
+//   - *Builder: The builder, for chaining.
+func (builder *Builder) ProtectDACL() *Builder {
+	builder.control |= control.NT_SECURITY_DESCRIPTOR_CONTROL_PD
+	return builder
+}
+
+// ProtectSACL sets the SE_SACL_PROTECTED control flag, which blocks the inheritance of ACEs
+// from the parent object into the SACL. The descriptor then has a SACL, even if empty.
+//
+// Returns:
+//   - *Builder: The builder, for chaining.
+func (builder *Builder) ProtectSACL() *Builder {
+	builder.control |= control.NT_SECURITY_DESCRIPTOR_CONTROL_PS
+	return builder
+}
+
+// Allow adds an access allowed ACE to the DACL.
+//
+// Parameters:
+//   - trustee (string): The SDDL alias, SID string or well-known name of the trustee.
+//   - rightNames (...string): The rights allowed, see ResolveRights.
+//
+// Returns:
+//   - *Builder: The builder, for chaining. ObjectType, InheritedObjectType and Inherit
+//     then apply to this ACE.
+func (builder *Builder) Allow(trustee string, rightNames ...string) *Builder {
+	return builder.addEntry(ENTRY_KIND_ALLOW, trustee, 0, rightNames)
+}
+
+// Deny adds an access denied ACE to the DACL.
+//
+// Parameters:
+//   - trustee (string): The SDDL alias, SID string or well-known name of the trustee.
+//   - rightNames (...string): The rights denied, see ResolveRights.
+//
+// Returns:
+//   - *Builder: The builder, for chaining. ObjectType, InheritedObjectType and Inherit
+//     then apply to this ACE.
+func (builder *Builder) Deny(trustee string, rightNames ...string) *Builder {
+	return builder.addEntry(ENTRY_KIND_DENY, trustee, 0, rightNames)
+}
+
+// Audit adds a system audit ACE to the SACL.
+//
+// Parameters:
+//   - trustee (string): The SDDL alias, SID string or well-known name of the trustee.
+//   - auditFlags (uint8): The accesses audited, a combination of ACE_FLAG_SUCCESSFUL_ACCESS
+//     and ACE_FLAG_FAILED_ACCESS.
+//   - rightNames (...string): The rights audited, see ResolveRights.
+//
+// Returns:
+//   - *Builder: The builder, for chaining. ObjectType, InheritedObjectType and Inherit
+//     then apply to this ACE.
+func (builder *Builder) Audit(trustee string, auditFlags uint8, rightNames ...string) *Builder {
+	return builder.addEntry(ENTRY_KIND_AUDIT, trustee, auditFlags, rightNames)
+}
+
+// AuditSuccess adds a system audit ACE for the successful accesses to the SACL.
+//
+// Parameters:
+//   - trustee (string): The SDDL alias, SID string or well-known name of the trustee.
+//   - rightNames (...string): The rights audited, see ResolveRights.
+//
+// Returns:
+//   - *Builder: The builder, for chaining.
+func (builder *Builder) AuditSuccess(trustee string, rightNames ...string) *Builder {
+	return builder.Audit(trustee, aceflags.ACE_FLAG_SUCCESSFUL_ACCESS, rightNames...)
+}
+
+// AuditFailure adds a system audit ACE for the failed accesses to the SACL.
+//
+// Parameters:
+//   - trustee (string): The SDDL alias, SID string or well-known name of the trustee.
+//   - rightNames (...string): The rights audited, see ResolveRights.
+//
+// Returns:
+//   - *Builder: The builder, for chaining.
+func (builder *Builder) AuditFailure(trustee string, rightNames ...string) *Builder {
+	return builder.Audit(trustee, aceflags.ACE_FLAG_FAILED_ACCESS, rightNames...)
+}
+
+// ObjectType sets the object type of the last ACE added, which makes it an object ACE.
+//
+// Parameters:
+//   - nameOrGUID (string): The name or GUID of an extended right, property set, validated
+//     write, attribute or class, see ResolveObjectType.
+//
+// Returns:
+//   - *Builder: The builder, for chaining.
+func (builder *Builder) ObjectType(nameOrGUID string) *Builder {
+	entry := builder.lastEntry("ObjectType")
+	if entry == nil {
+		return builder
+	}
+	objectType, err := ResolveObjectType(builder.registry, nameOrGUID)
+	if err != nil {
+		builder.failEntry(fmt.Errorf("object type: %w", err))
+		return builder
+	}
+	entry.rule.ObjectType = objectType
+	return builder
+}
+
+// InheritedObjectType sets the inherited object type of the last ACE added, the class of the
+// child objects inheriting it, which makes it an object ACE.
+//
+// Parameters:
+//   - nameOrGUID (string): The name or GUID of a class, see ResolveObjectType.
+//
+// Returns:
+//   - *Builder: The builder, for chaining.
+func (builder *Builder) InheritedObjectType(nameOrGUID string) *Builder {
+	entry := builder.lastEntry("InheritedObjectType")
+	if entry == nil {
+		return builder
+	}
+	inheritedObjectType, err := ResolveObjectType(builder.registry, nameOrGUID)
+	if err != nil {
+		builder.failEntry(fmt.Errorf("inherited object type: %w", err))
+		return builder
+	}
+	entry.rule.InheritedObjectType = inheritedObjectType
+	return builder
+}
+
+// Inherit sets the inheritance flags of the last ACE added.
+//
+// Parameters:
+//   - flagNames (...string): The inheritance flags, as SDDL tokens ("OI", "CI", "NP", "IO")
+//     or names ("OBJECT_INHERIT", "CONTAINER_INHERIT", "NO_PROPAGATE_INHERIT", "INHERIT_ONLY").
+//
+// Returns:
+//   - *Builder: The builder, for chaining.
+func (builder *Builder) Inherit(flagNames ...string) *Builder {
+	entry := builder.lastEntry("Inherit")
+	if entry == nil {
+		return builder
+	}
+	inheritanceFlags, err := ResolveInheritanceFlags(flagNames...)
+	if err != nil {
+		builder.failEntry(err)
+		return builder
+	}
+	entry.rule.Flags |= inheritanceFlags
+	return builder
+}
+
+// Fail records a problem found by the caller, like an invalid value read from a configuration,
+// so that Err and Build report it with the problems found by the builder.
+//
+// Parameters:
+//   - err (error): The problem.
+//
+// Returns:
+//   - *Builder: The builder, for chaining.
+func (builder *Builder) Fail(err error) *Builder {
+	builder.errs = append(builder.errs, err)
+	return builder
+}
+
+// Err returns the problems found by the calls made so far.
+//
+// Returns:
+//   - error: The joined errors of the calls, nil if every call was valid.
+func (builder *Builder) Err() error {
+	return errors.Join(builder.errs...)
+}
+
+// Build creates the security descriptor. It always has a DACL, empty if no access was allowed
+// or denied, which denies all access. It has a SACL if an audit ACE was added or the SACL is
+// protected. The builder can be reused afterwards, each Build returns a new descriptor.
+//
+// Returns:
+//   - *securitydescriptor.NtSecurityDescriptor: The security descriptor, nil on error.
+//   - error: The problems found by the calls made, or while adding the ACEs.
+func (builder *Builder) Build() (*securitydescriptor.NtSecurityDescriptor, error) {
+	if err := builder.Err(); err != nil {
+		return nil, err
+	}
+
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	ntsd.Header.Revision = 1
+	ntsd.Header.Control.RawValue = control.NT_SECURITY_DESCRIPTOR_CONTROL_SR | control.NT_SECURITY_DESCRIPTOR_CONTROL_DP | builder.control
+	if builder.owner != nil {
+		ntsd.Owner = &identity.Identity{SID: *builder.owner.Clone(), Name: builder.owner.LookupName()}
+	}
+	if builder.group != nil {
+		ntsd.Group = &identity.Identity{SID: *builder.group.Clone(), Name: builder.group.LookupName()}
+	}
+
+	ntsd.DACL = &acl.DiscretionaryAccessControlList{}
+	ntsd.DACL.Header.Revision.Value = revision.ACL_REVISION
+	if builder.control&control.NT_SECURITY_DESCRIPTOR_CONTROL_PS != 0 {
+		ntsd.SACL = newSACL()
+	}
+
+	for index, entry := range builder.entries {
+		var err error
+		switch entry.kind {
+		case ENTRY_KIND_ALLOW:
+			err = ntsd.DACL.AddAccess(acl.ACCESS_CONTROL_TYPE_ALLOW, cloneRule(entry.rule))
+		case ENTRY_KIND_DENY:
+			err = ntsd.DACL.AddAccess(acl.ACCESS_CONTROL_TYPE_DENY, cloneRule(entry.rule))
+		case ENTRY_KIND_AUDIT:
+			if ntsd.SACL == nil {
+				ntsd.SACL = newSACL()
+			}
+			err = ntsd.SACL.AddAudit(entry.auditFlags, cloneRule(entry.rule))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("entry #%d (%s %s): %w", index+1, entry.kind, entry.trustee, err)
+		}
+	}
+	if ntsd.SACL != nil {
+		ntsd.Header.Control.RawValue |= control.NT_SECURITY_DESCRIPTOR_CONTROL_SP
+	}
+
+	return ntsd, nil
+}
+
+// addEntry records an ACE, resolving its trustee and rights.
+func (builder *Builder) addEntry(kind EntryKind, trustee string, auditFlags uint8, rightNames []string) *Builder {
+	builder.entries = append(builder.entries, builderEntry{kind: kind, trustee: trustee, auditFlags: auditFlags})
+	entry := &builder.entries[len(builder.entries)-1]
+
+	trusteeSID, err := ResolveTrustee(trustee)
+	if err != nil {
+		builder.failEntry(err)
+	} else {
+		entry.rule.SID = *trusteeSID
+	}
+
+	mask, err := ResolveRights(builder.namespace, rightNames...)
+	if err != nil {
+		builder.failEntry(err)
+	} else if mask == 0 {
+		builder.failEntry(fmt.Errorf("no rights given"))
+	}
+	entry.rule.Mask = mask
+
+	if kind == ENTRY_KIND_AUDIT && (auditFlags == 0 || auditFlags&^aceflags.ACE_FLAG_AUDIT_FLAGS != 0) {
+		builder.failEntry(fmt.Errorf("invalid audit flags 0x%02x, expected a combination of SUCCESSFUL_ACCESS and FAILED_ACCESS", auditFlags))
+	}
+	return builder
+}
+
+// lastEntry returns the last ACE recorded, for the methods modifying it.
+func (builder *Builder) lastEntry(method string) *builderEntry {
+	if len(builder.entries) == 0 {
+		builder.Fail(fmt.Errorf("%s called before Allow, Deny or Audit", method))
+		return nil
+	}
+	return &builder.entries[len(builder.entries)-1]
+}
+
+// failEntry records a problem of the last ACE recorded.
+func (builder *Builder) failEntry(err error) {
+	entry := builder.entries[len(builder.entries)-1]
+	builder.Fail(fmt.Errorf("entry #%d (%s %s): %w", len(builder.entries), entry.kind, entry.trustee, err))
+}
+
+// newSACL creates an empty SACL.
+func newSACL() *acl.SystemAccessControlList {
+	sacl := &acl.SystemAccessControlList{}
+	sacl.Header.Revision.Value = revision.ACL_REVISION
+	return sacl
+}
+
+// cloneRule copies an access rule, so that the descriptors built do not share the object
+// types of the builder.
+func cloneRule(rule acl.AccessRule) acl.AccessRule {
+	rule.SID = *rule.SID.Clone()
+	if rule.ObjectType != nil {
+		objectType := *rule.ObjectType
+		rule.ObjectType = &objectType
+	}
+	if rule.InheritedObjectType != nil {
+		inheritedObjectType := *rule.InheritedObjectType
+		rule.InheritedObjectType = &inheritedObjectType
+	}
+	return rule
+}
@@ -0,0 +1,214 @@
+package builder
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/TheManticoreProject/winacl/ace/aceflags"
+	"github.com/TheManticoreProject/winacl/acl"
+	"github.com/TheManticoreProject/winacl/guid"
+	"github.com/TheManticoreProject/winacl/rights"
+	"github.com/TheManticoreProject/winacl/schema"
+	sddl_aceflags "github.com/TheManticoreProject/winacl/sddl/ace/aceflags"
+	sddl_rights "github.com/TheManticoreProject/winacl/sddl/rights"
+	sddl_sid "github.com/TheManticoreProject/winacl/sddl/sid"
+	"github.com/TheManticoreProject/winacl/sid"
+)
+
+// ResolveTrustee resolves the SID of a trustee given by SDDL alias, SID string or well-known
+// name.
+//
+// Parameters:
+//   - trustee (string): The SDDL alias (e.g. "AU"), SID string (e.g. "S-1-5-11") or
+//     well-known name (e.g. "Authenticated Users", case-insensitive) of the trustee.
+//
+// Returns:
+//   - *sid.SID: The SID of the trustee.
+//   - error: An error if the trustee cannot be resolved.
+func ResolveTrustee(trustee string) (*sid.SID, error) {
+	value := strings.TrimSpace(trustee)
+	if sidString, exists := sddl_sid.SDDLToSID[value]; exists {
+		value = sidString
+	} else if !strings.HasPrefix(strings.ToUpper(value), "S-") {
+		matches := make([]string, 0, 1)
+		for sidString, name := range sid.WellKnownSIDs {
+			if strings.EqualFold(name, value) {
+				matches = append(matches, sidString)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("unknown trustee %q", trustee)
+		case 1:
+			value = matches[0]
+		default:
+			slices.Sort(matches)
+			return nil, fmt.Errorf("ambiguous trustee %q, matching %s", trustee, strings.Join(matches, ", "))
+		}
+	}
+
+	resolved := &sid.SID{}
+	if err := resolved.FromString(value); err != nil {
+		return nil, fmt.Errorf("invalid trustee %q: %w", trustee, err)
+	}
+	return resolved, nil
+}
+
+// ResolveRights resolves an access mask from the names of its rights. Each name is, in this
+// order of precedence:
+//   - a hexadecimal mask (e.g. "0x00020094");
+//   - an SDDL rights token (e.g. "RP") or a concatenation of them (e.g. "RPWP");
+//   - the name of a right or composite right of the namespace (e.g. "FILE_GENERIC_READ");
+//   - the name of a standard, generic or directory service right, with or without the RIGHT_
+//     prefix (e.g. "DS_READ_PROPERTY", "GENERIC_ALL").
+//
+// Names are case-insensitive, except the SDDL tokens.
+//
+// Parameters:
+//   - namespace (rights.RightsNamespace): The namespace of the right names.
+//   - rightNames (...string): The names of the rights.
+//
+// Returns:
+//   - uint32: The access mask holding all the rights.
+//   - error: An error if a name cannot be resolved.
+func ResolveRights(namespace rights.RightsNamespace, rightNames ...string) (uint32, error) {
+	mask := uint32(0)
+	for _, rightName := range rightNames {
+		value, err := resolveRight(namespace, strings.TrimSpace(rightName))
+		if err != nil {
+			return 0, err
+		}
+		mask |= value
+	}
+	return mask, nil
+}
+
+// resolveRight resolves the access mask of a single right name, see ResolveRights.
+func resolveRight(namespace rights.RightsNamespace, rightName string) (uint32, error) {
+	if strings.HasPrefix(rightName, "0x") || strings.HasPrefix(rightName, "0X") {
+		value, err := strconv.ParseUint(rightName[2:], 16, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid access mask %q", rightName)
+		}
+		return uint32(value), nil
+	}
+
+	if value, ok := resolveSDDLRights(rightName); ok {
+		return value, nil
+	}
+
+	name := strings.TrimPrefix(strings.ToUpper(rightName), "RIGHT_")
+	definition := rights.RightsNamespaces[namespace]
+	for _, group := range [][]rights.NamedRight{definition.CompositeRights, definition.Rights} {
+		for _, right := range group {
+			if right.Name == name {
+				return right.Value, nil
+			}
+		}
+	}
+	if value, exists := rights.RightNameToRightValue[name]; exists {
+		return value, nil
+	}
+	return 0, fmt.Errorf("unknown right %q in the %s namespace", rightName, namespace)
+}
+
+// resolveSDDLRights resolves a concatenation of SDDL rights tokens, like the rights field of
+// an ACE string.
+func resolveSDDLRights(rightName string) (uint32, bool) {
+	if rightName == "" || len(rightName)%2 != 0 {
+		return 0, false
+	}
+	value := uint32(0)
+	for index := 0; index < len(rightName); index += 2 {
+		tokenValue, exists := sddl_rights.SDDLToRight[rightName[index:index+2]]
+		if !exists {
+			return 0, false
+		}
+		value |= tokenValue
+	}
+	return value, true
+}
+
+// ResolveObjectType resolves the GUID of an object type given by name. The name is looked up
+// in the registry as, in this order, an extended right, property set or validated write
+// (rightsGuid), an attribute and a class (schemaIDGUID).
+//
+// Parameters:
+//   - registry (*schema.Registry): The registry used to resolve names, the default registry
+//     if nil.
+//   - nameOrGUID (string): The GUID (D format) or name of the object type (e.g.
+//     "User-Force-Change-Password", "member", "user").
+//
+// Returns:
+//   - *guid.GUID: The GUID of the object type.
+//   - error: An error if the name cannot be resolved.
+func ResolveObjectType(registry *schema.Registry, nameOrGUID string) (*guid.GUID, error) {
+	value := strings.TrimSpace(nameOrGUID)
+	if parsed, err := guid.FromString(value); err == nil {
+		return parsed, nil
+	}
+
+	if registry == nil {
+		registry = schema.GetDefaultRegistry()
+	}
+	formatD := ""
+	if extendedRight, found := registry.LookupExtendedRight(value); found {
+		formatD = extendedRight.RightsGUID
+	} else if schemaAttribute, found := registry.LookupAttribute(value); found {
+		formatD = schemaAttribute.SchemaIDGUID
+	} else if schemaClass, found := registry.LookupClass(value); found {
+		formatD = schemaClass.SchemaIDGUID
+	} else {
+		return nil, fmt.Errorf("unknown object type %q", nameOrGUID)
+	}
+
+	parsed, err := guid.FromString(formatD)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GUID %q of the object type %q: %w", formatD, nameOrGUID, err)
+	}
+	return parsed, nil
+}
+
+// ResolveInheritanceFlags resolves the ACE flags from the names of inheritance flags.
+//
+// Parameters:
+//   - flagNames (...string): The SDDL tokens ("OI", "CI", "NP", "IO") or names
+//     ("OBJECT_INHERIT", "CONTAINER_INHERIT", "NO_PROPAGATE_INHERIT", "INHERIT_ONLY", with or
+//     without the ACE_FLAG_ prefix, case-insensitive) of the flags.
+//
+// Returns:
+//   - uint8: The inheritance flags.
+//   - error: An error if a name is not the name of an inheritance flag.
+func ResolveInheritanceFlags(flagNames ...string) (uint8, error) {
+	inheritanceFlags := uint8(0)
+	for _, flagName := range flagNames {
+		name := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(flagName)), "ACE_FLAG_")
+		value, found := inheritanceFlagValue(name)
+		if !found {
+			return 0, fmt.Errorf("unknown inheritance flag %q", flagName)
+		}
+		inheritanceFlags |= value
+	}
+	return inheritanceFlags, nil
+}
+
+// inheritanceFlagValue returns the value of an inheritance flag from its uppercase SDDL token or
+// name.
+func inheritanceFlagValue(name string) (uint8, bool) {
+	if value, exists := sddl_aceflags.SDDLToACEFlag[name]; exists && value&acl.INHERITANCE_FLAGS != 0 {
+		return value, true
+	}
+	for _, value := range []uint8{
+		aceflags.ACE_FLAG_OBJECT_INHERIT,
+		aceflags.ACE_FLAG_CONTAINER_INHERIT,
+		aceflags.ACE_FLAG_NO_PROPAGATE_INHERIT,
+		aceflags.ACE_FLAG_INHERIT_ONLY,
+	} {
+		if name == aceflags.AccessControlEntryFlagToName[value] {
+			return value, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,275 @@
+package builder_test
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/ace/aceflags"
+	"github.com/TheManticoreProject/winacl/builder"
+	"github.com/TheManticoreProject/winacl/rights"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+	"github.com/TheManticoreProject/winacl/securitydescriptor/control"
+)
+
+// builderTestSDDL builds a descriptor and returns its SDDL form.
+func builderTestSDDL(t *testing.T, b *builder.Builder) string {
+	t.Helper()
+	ntsd, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	sddlString, err := ntsd.ToSDDLString()
+	if err != nil {
+		t.Fatalf("ToSDDLString() error = %v", err)
+	}
+	return sddlString
+}
+
+func TestBuilder_Build(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *builder.Builder
+		want    string
+	}{
+		{
+			name:    "OwnerGroupAndDACL",
+			builder: builder.NewBuilder().Owner("BA").Group("SY").Allow("SY", "GA").Allow("AU", "GENERIC_READ"),
+			want:    "O:BAG:SYD:(A;;GA;;;SY)(A;;GR;;;AU)",
+		},
+		{
+			name:    "CanonicalOrder",
+			builder: builder.NewBuilder().Allow("AU", "GR").Deny("AN", "GA").Allow("SY", "GA"),
+			want:    "D:(D;;GA;;;AN)(A;;GR;;;AU)(A;;GA;;;SY)",
+		},
+		{
+			name:    "MergedRights",
+			builder: builder.NewBuilder().Allow("AU", "RP").Allow("Authenticated Users", "DS_LIST_CONTENTS"),
+			want:    "D:(A;;RPLC;;;AU)",
+		},
+		{
+			name: "ObjectTypesByName",
+			builder: builder.NewBuilder().
+				Allow("S-1-5-21-1-2-3-1105", "RP", "WP").ObjectType("Personal-Information").InheritedObjectType("user").Inherit("CI").
+				Allow("AU", "CR").ObjectType("User-Change-Password"),
+			want: "D:(OA;CI;RPWP;77b5b886-944a-11d1-aebd-0000f80367c1;bf967aba-0de6-11d0-a285-00aa003049e2;S-1-5-21-1-2-3-1105)" +
+				"(OA;;CR;ab721a53-1e2f-11d0-9819-00aa0040529b;;AU)",
+		},
+		{
+			name:    "InheritanceFlagNames",
+			builder: builder.NewBuilder().Allow("BU", "GR").Inherit("OBJECT_INHERIT", "ci", "ACE_FLAG_INHERIT_ONLY"),
+			want:    "D:(A;CIOIIO;GR;;;BU)",
+		},
+		{
+			name:    "Audit",
+			builder: builder.NewBuilder().Allow("SY", "GA").AuditFailure("WD", "WD", "WO").AuditSuccess("WD", "WD", "WO"),
+			want:    "D:(A;;GA;;;SY)S:(AU;SAFA;WDWO;;;WD)",
+		},
+		{
+			name:    "Protected",
+			builder: builder.NewBuilder().ProtectDACL().ProtectSACL().Allow("SY", "0x001f01ff"),
+			want:    "D:P(A;;FA;;;SY)S:P",
+		},
+		{
+			name:    "EmptyDACL",
+			builder: builder.NewBuilder().Owner("SY"),
+			want:    "O:SYD:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := builderTestSDDL(t, tt.builder); got != tt.want {
+				t.Errorf("Build() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *builder.Builder
+		want    []string
+	}{
+		{
+			name:    "UnknownTrustee",
+			builder: builder.NewBuilder().Owner("Nobody Special").Allow("XX", "GA"),
+			want:    []string{"owner: unknown trustee \"Nobody Special\"", "entry #1 (ALLOW XX): unknown trustee \"XX\""},
+		},
+		{
+			name:    "UnknownRight",
+			builder: builder.NewBuilder().Allow("AU", "RP", "READ_EVERYTHING"),
+			want:    []string{"entry #1 (ALLOW AU): unknown right \"READ_EVERYTHING\""},
+		},
+		{
+			name:    "NoRights",
+			builder: builder.NewBuilder().Deny("AU"),
+			want:    []string{"entry #1 (DENY AU): no rights given"},
+		},
+		{
+			name:    "UnknownObjectType",
+			builder: builder.NewBuilder().Allow("AU", "RP").Allow("BU", "WP").ObjectType("not-an-attribute"),
+			want:    []string{"entry #2 (ALLOW BU): object type: unknown object type \"not-an-attribute\""},
+		},
+		{
+			name:    "ModifierWithoutEntry",
+			builder: builder.NewBuilder().Inherit("CI"),
+			want:    []string{"Inherit called before Allow, Deny or Audit"},
+		},
+		{
+			name:    "NotAnInheritanceFlag",
+			builder: builder.NewBuilder().Allow("AU", "RP").Inherit("ID"),
+			want:    []string{"unknown inheritance flag \"ID\""},
+		},
+		{
+			name:    "InvalidAuditFlags",
+			builder: builder.NewBuilder().Audit("WD", aceflags.ACE_FLAG_CONTAINER_INHERIT, "GA"),
+			want:    []string{"entry #1 (AUDIT WD): invalid audit flags 0x02"},
+		},
+		{
+			name:    "InheritOnlyWithoutInheritance",
+			builder: builder.NewBuilder().Allow("AU", "RP").Inherit("IO"),
+			want:    []string{"entry #1 (ALLOW AU): invalid access rule: INHERIT_ONLY requires"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ntsd, err := tt.builder.Build()
+			if err == nil {
+				t.Fatalf("Build() = %v, want an error", ntsd)
+			}
+			if ntsd != nil {
+				t.Errorf("Build() = %v, want nil on error", ntsd)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Build() error = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuilder_BuildIsRepeatable(t *testing.T) {
+	b := builder.NewBuilder().Owner("BA").Allow("AU", "RP").ObjectType("member")
+
+	first, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	first.DACL.Entries[0].AccessControlObjectType.ObjectType.GUID.A = 0
+
+	second, err := b.Build()
+	if err != nil {
+		t.Fatalf("second Build() error = %v", err)
+	}
+	if got := second.DACL.Entries[0].AccessControlObjectType.ObjectType.GUID.ToFormatD(); got != "bf9679c0-0de6-11d0-a285-00aa003049e2" {
+		t.Errorf("second Build() object type = %s, want the member attribute", got)
+	}
+
+	// The descriptors built are complete: they survive a binary round trip
+	data, err := second.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	parsed := &securitydescriptor.NtSecurityDescriptor{}
+	if _, err := parsed.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !parsed.Diff(second).IsEmpty() {
+		t.Errorf("Unmarshal(Marshal()) differs: %s", parsed.Diff(second))
+	}
+}
+
+func TestBuilder_EmptyDACL(t *testing.T) {
+	ntsd, err := builder.NewBuilder().Owner("BA").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	// The empty DACL denies all access: it must not be marshalled as a NULL DACL
+	data, err := ntsd.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if offsetDacl := binary.LittleEndian.Uint32(data[16:20]); offsetDacl == 0 {
+		t.Fatalf("Marshal() OffsetDacl = 0, want an empty DACL")
+	}
+	parsed := &securitydescriptor.NtSecurityDescriptor{}
+	if _, err := parsed.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if parsed.DACL == nil || len(parsed.DACL.Entries) != 0 || !parsed.Header.Control.HasControl(control.NT_SECURITY_DESCRIPTOR_CONTROL_DP) {
+		t.Fatalf("Unmarshal(Marshal()) DACL = %v, want a present empty DACL", parsed.DACL)
+	}
+
+	// The same holds through SDDL
+	sddlString, err := parsed.ToSDDLString()
+	if err != nil || sddlString != "O:BAD:" {
+		t.Fatalf("ToSDDLString() = %q, %v, want O:BAD:", sddlString, err)
+	}
+	fromSDDL := &securitydescriptor.NtSecurityDescriptor{}
+	if _, err := fromSDDL.FromSDDLString(sddlString); err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+	if fromSDDL.DACL == nil || len(fromSDDL.DACL.Entries) != 0 {
+		t.Fatalf("FromSDDLString(%q) DACL = %v, want a present empty DACL", sddlString, fromSDDL.DACL)
+	}
+	data, err = fromSDDL.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if binary.LittleEndian.Uint32(data[16:20]) == 0 {
+		t.Errorf("Marshal() of the SDDL descriptor has a NULL DACL")
+	}
+}
+
+func TestResolveObjectType(t *testing.T) {
+	for nameOrGUID, want := range map[string]string{
+		"User-Force-Change-Password":           "00299570-246d-11d0-a768-00aa006e0529",
+		"member":                               "bf9679c0-0de6-11d0-a285-00aa003049e2",
+		"user":                                 "bf967aba-0de6-11d0-a285-00aa003049e2",
+		"BF967ABA-0DE6-11D0-A285-00AA003049E2": "bf967aba-0de6-11d0-a285-00aa003049e2",
+	} {
+		got, err := builder.ResolveObjectType(nil, nameOrGUID)
+		if err != nil {
+			t.Errorf("ResolveObjectType(%q) error = %v", nameOrGUID, err)
+			continue
+		}
+		if got.ToFormatD() != want {
+			t.Errorf("ResolveObjectType(%q) = %s, want %s", nameOrGUID, got.ToFormatD(), want)
+		}
+	}
+}
+
+func TestResolveRights(t *testing.T) {
+	tests := []struct {
+		name       string
+		namespace  rights.RightsNamespace
+		rightNames []string
+		want       uint32
+		wantErr    bool
+	}{
+		{"SDDLTokens", rights.RIGHTS_NAMESPACE_DIRECTORY_SERVICE, []string{"RP", "WPCR"}, 0x00000130, false},
+		{"RightNames", rights.RIGHTS_NAMESPACE_DIRECTORY_SERVICE, []string{"ds_read_property", "RIGHT_WRITE_DAC"}, 0x00040010, false},
+		{"HexMask", rights.RIGHTS_NAMESPACE_DIRECTORY_SERVICE, []string{"0x00020094"}, 0x00020094, false},
+		{"NamespaceRight", rights.RIGHTS_NAMESPACE_FILE, []string{"FILE_GENERIC_READ"}, 0x00120089, false},
+		{"GenericRightInNamespace", rights.RIGHTS_NAMESPACE_REGISTRY_KEY, []string{"GENERIC_ALL"}, 0x10000000, false},
+		{"RightOfAnotherNamespace", rights.RIGHTS_NAMESPACE_DIRECTORY_SERVICE, []string{"FILE_GENERIC_READ"}, 0, true},
+		{"InvalidHexMask", rights.RIGHTS_NAMESPACE_DIRECTORY_SERVICE, []string{"0xZZ"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := builder.ResolveRights(tt.namespace, tt.rightNames...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveRights() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveRights() = 0x%08x, want 0x%08x", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,12 +2,13 @@ package guid
 
 import (
 	"fmt"
+	"io"
 	"math/rand/v2"
 	"regexp"
 	"strconv"
 	"strings"
 
-	"github.com/TheManticoreProject/winacl/rights"
+	"github.com/TheManticoreProject/winacl/parsing"
 	"github.com/TheManticoreProject/winacl/schema"
 )
 
@@ -83,7 +84,7 @@ func (guid *GUID) Equal(other *GUID) bool {
 // - An error if the parsing fails.
 func (guid *GUID) Unmarshal(data []byte) (int, error) {
 	if len(data) < 16 {
-		return 0, fmt.Errorf("GUID unmarshal requires at least 16 bytes, got %d", len(data))
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "GUID unmarshal requires at least 16 bytes, got %d", len(data))
 	}
 
 	guid.A = uint32(data[0]) | uint32(data[1])<<8 | uint32(data[2])<<16 | uint32(data[3])<<24
@@ -366,20 +367,50 @@ func FromFormatX(data string) (*GUID, error) {
 // - A byte array containing the raw bytes of the GUID.
 // - An error if the marshalling fails.
 func (guid *GUID) Marshal() ([]byte, error) {
-	data := make([]byte, 0)
-	data = append(data, byte(guid.A), byte(guid.A>>8), byte(guid.A>>16), byte(guid.A>>24))
-	data = append(data, byte(guid.B), byte(guid.B>>8))
-	data = append(data, byte(guid.C), byte(guid.C>>8))
-	data = append(data, byte(guid.D>>8), byte(guid.D))
-	eBytes := make([]byte, 6)
+	return guid.AppendBinary(make([]byte, 0, guid.Size()))
+}
 
-	for i := 0; i < 6; i++ {
-		eBytes[5-i] = byte((guid.E >> uint64(i*8)) & 0xff)
-	}
+// Size returns the size of the binary representation of the GUID.
+//
+// Returns:
+// - Always 16.
+func (guid *GUID) Size() int {
+	return 16
+}
 
-	data = append(data, eBytes...)
+// AppendBinary appends the raw bytes of the GUID to a buffer.
+//
+// Parameters:
+// - buffer: The buffer to append to.
+//
+// Returns:
+// - The extended buffer.
+// - Always nil.
+func (guid *GUID) AppendBinary(buffer []byte) ([]byte, error) {
+	buffer = append(buffer, byte(guid.A), byte(guid.A>>8), byte(guid.A>>16), byte(guid.A>>24))
+	buffer = append(buffer, byte(guid.B), byte(guid.B>>8))
+	buffer = append(buffer, byte(guid.C), byte(guid.C>>8))
+	buffer = append(buffer, byte(guid.D>>8), byte(guid.D))
+	for i := 5; i >= 0; i-- {
+		buffer = append(buffer, byte((guid.E>>uint64(i*8))&0xff))
+	}
+	return buffer, nil
+}
 
-	return data, nil
+// MarshalTo writes the raw bytes of the GUID at the start of a buffer.
+//
+// Parameters:
+// - buffer: The buffer to write to, at least 16 bytes long.
+//
+// Returns:
+// - The number of bytes written.
+// - An error if the buffer is too short.
+func (guid *GUID) MarshalTo(buffer []byte) (int, error) {
+	if len(buffer) < guid.Size() {
+		return 0, fmt.Errorf("GUID requires a buffer of %d bytes, got %d: %w", guid.Size(), len(buffer), io.ErrShortBuffer)
+	}
+	_, err := guid.AppendBinary(buffer[:0:guid.Size()])
+	return guid.Size(), err
 }
 
 // ToFormatN returns the GUID in the format N: 00000000000000000000000000000000
@@ -444,23 +475,29 @@ func (guid *GUID) ToFormatX() string {
 	)
 }
 
-// LookupName returns the name of the GUID if it is a well known GUID or a property set or a schema attribute
+// LookupName returns the name of the GUID if it is a well known GUID or a property set or a schema attribute or a schema class
 //
-// The function takes a GUID and looks up the name of the GUID in the well known GUIDs, property sets, or schema attributes.
+// The function takes a GUID and looks up the name of the GUID in the default schema registry, which holds the
+// objects loaded at runtime on top of the well known GUIDs, property sets, schema attributes, and schema classes.
 //
 // Returns:
 // - A string containing the name of the GUID.
-// - "?" if the GUID is not found in the well known GUIDs, property sets, or schema attributes.
+// - "?" if the GUID is not found in the well known GUIDs, property sets, schema attributes, or schema classes.
 func (guid *GUID) LookupName() string {
-	formatD := guid.ToFormatD()
+	return guid.LookupNameInRegistry(schema.GetDefaultRegistry())
+}
 
-	if name, exists := rights.GUIDToExtendedRight[formatD]; exists {
-		return name
-	} else if name, exists := schema.GUIDToPropertySet[formatD]; exists {
-		return name
-	} else if name, exists := schema.GUIDToSchemaAttributeDisplayName[formatD]; exists {
-		return fmt.Sprintf("LDAP Attribute: %s", name)
-	} else {
-		return "?"
+// LookupNameInRegistry returns the name of the GUID as resolved by a specific schema registry
+//
+// Parameters:
+// - registry: The schema registry to consult, e.g. one loaded from the schema export of a given forest.
+//
+// Returns:
+// - A string containing the name of the GUID.
+// - "?" if the GUID is not found in the registry nor in the built-in catalog.
+func (guid *GUID) LookupNameInRegistry(registry *schema.Registry) string {
+	if registry == nil {
+		registry = schema.GetDefaultRegistry()
 	}
+	return registry.LookupName(guid.ToFormatD())
 }
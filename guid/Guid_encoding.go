@@ -0,0 +1,81 @@
+package guid
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalBinary returns the raw bytes of the GUID, implementing encoding.BinaryMarshaler.
+//
+// Returns:
+// - The 16 raw bytes of the GUID.
+// - Always nil.
+func (guid *GUID) MarshalBinary() ([]byte, error) {
+	return guid.Marshal()
+}
+
+// UnmarshalBinary parses the raw bytes of a GUID, implementing encoding.BinaryUnmarshaler.
+//
+// Parameters:
+// - data: The 16 raw bytes of the GUID.
+//
+// Returns:
+// - An error if the data is not exactly 16 bytes long.
+func (guid *GUID) UnmarshalBinary(data []byte) error {
+	if len(data) != guid.Size() {
+		return fmt.Errorf("invalid binary GUID: expected %d bytes, got %d", guid.Size(), len(data))
+	}
+	_, err := guid.Unmarshal(data)
+	return err
+}
+
+// MarshalText returns the GUID in the format D, implementing encoding.TextMarshaler.
+//
+// Returns:
+// - The GUID in the format D: 00000000-0000-0000-0000-000000000000
+// - Always nil.
+func (guid GUID) MarshalText() ([]byte, error) {
+	return []byte(guid.ToFormatD()), nil
+}
+
+// UnmarshalText parses a GUID, implementing encoding.TextUnmarshaler.
+//
+// Parameters:
+// - text: The GUID in any of the formats accepted by FromString.
+//
+// Returns:
+// - An error if the text is not a valid GUID.
+func (guid *GUID) UnmarshalText(text []byte) error {
+	parsed, err := FromString(string(text))
+	if err != nil {
+		return err
+	}
+	*guid = *parsed
+	return nil
+}
+
+// MarshalJSON returns the JSON string holding the GUID in the format D, implementing
+// json.Marshaler. MarshalText and MarshalJSON have value receivers, so that they also apply
+// to the GUIDs held by value in maps, as values or as keys.
+//
+// Returns:
+// - The JSON string, like "bf967aba-0de6-11d0-a285-00aa003049e2".
+// - Always nil.
+func (guid GUID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(guid.ToFormatD())
+}
+
+// UnmarshalJSON parses a JSON string holding a GUID, implementing json.Unmarshaler.
+//
+// Parameters:
+// - data: The JSON string, holding the GUID in any of the formats accepted by FromString.
+//
+// Returns:
+// - An error if the data is not a JSON string holding a valid GUID.
+func (guid *GUID) UnmarshalJSON(data []byte) error {
+	var guidString string
+	if err := json.Unmarshal(data, &guidString); err != nil {
+		return fmt.Errorf("invalid JSON GUID: %w", err)
+	}
+	return guid.UnmarshalText([]byte(guidString))
+}
@@ -0,0 +1,55 @@
+package guid
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEncoding(t *testing.T) {
+	original := &GUID{A: 0xbf967aba, B: 0x0de6, C: 0x11d0, D: 0xa285, E: 0x00aa003049e2}
+
+	binaryData, err := original.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	fromBinary := &GUID{}
+	if err := fromBinary.UnmarshalBinary(binaryData); err != nil || *fromBinary != *original {
+		t.Errorf("UnmarshalBinary() = %s, %v, want %s", fromBinary.ToFormatD(), err, original.ToFormatD())
+	}
+	if err := fromBinary.UnmarshalBinary(binaryData[:15]); err == nil {
+		t.Errorf("UnmarshalBinary() of 15 bytes succeeded, want an error")
+	}
+
+	text, err := original.MarshalText()
+	if err != nil || string(text) != "bf967aba-0de6-11d0-a285-00aa003049e2" {
+		t.Fatalf("MarshalText() = %q, %v", text, err)
+	}
+	fromText := &GUID{}
+	if err := fromText.UnmarshalText([]byte("{BF967ABA-0DE6-11D0-A285-00AA003049E2}")); err != nil || *fromText != *original {
+		t.Errorf("UnmarshalText() = %s, %v, want %s", fromText.ToFormatD(), err, original.ToFormatD())
+	}
+
+	jsonData, err := json.Marshal(original)
+	if err != nil || string(jsonData) != `"bf967aba-0de6-11d0-a285-00aa003049e2"` {
+		t.Fatalf("json.Marshal() = %s, %v", jsonData, err)
+	}
+	fromJSON := &GUID{}
+	if err := json.Unmarshal(jsonData, fromJSON); err != nil || *fromJSON != *original {
+		t.Errorf("json.Unmarshal() = %s, %v, want %s", fromJSON.ToFormatD(), err, original.ToFormatD())
+	}
+	if err := json.Unmarshal([]byte(`42`), fromJSON); err == nil {
+		t.Errorf("json.Unmarshal() of a number succeeded, want an error")
+	}
+
+	// GUIDs held by value, as struct fields, map values and map keys
+	byValue := struct {
+		ObjectType GUID
+		Classes    map[string]GUID
+		Names      map[GUID]string
+	}{*original, map[string]GUID{"user": *original}, map[GUID]string{*original: "user"}}
+	jsonData, err = json.Marshal(byValue)
+	want := `{"ObjectType":"bf967aba-0de6-11d0-a285-00aa003049e2","Classes":{"user":"bf967aba-0de6-11d0-a285-00aa003049e2"},"Names":{"bf967aba-0de6-11d0-a285-00aa003049e2":"user"}}`
+	if err != nil || string(jsonData) != want {
+		t.Errorf("json.Marshal() by value = %s, %v, want %s", jsonData, err, want)
+	}
+}
@@ -0,0 +1,123 @@
+package guid
+
+import (
+	"database/sql/driver"
+
+	"github.com/TheManticoreProject/winacl/internal/storage"
+)
+
+// Value returns the 16 raw bytes of the GUID, implementing driver.Valuer. It has a value
+// receiver, so that GUIDs and sql.Null[guid.GUID] can be passed as query arguments, and
+// database/sql stores nil *GUID arguments as NULL. Wrap the GUID in SQLText or SQLJSON to
+// store it in a text or JSON column instead.
+//
+// Returns:
+// - The 16 raw bytes as a []byte.
+// - Always nil.
+func (guid GUID) Value() (driver.Value, error) {
+	return guid.MarshalBinary()
+}
+
+// Scan parses a GUID read from a database column, implementing sql.Scanner. The 16 raw
+// bytes, the formats accepted by FromString and JSON strings are all accepted, from []byte
+// or string values, so that the storage format of a column can be changed without migrating
+// its data.
+//
+// Parameters:
+// - src: The value read from the database column.
+//
+// Returns:
+// - An error if the value is NULL or is not a valid GUID.
+func (guid *GUID) Scan(src any) error {
+	data, err := storage.ScanBytes(src, "guid.GUID")
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(data) == guid.Size():
+		return guid.UnmarshalBinary(data)
+	case len(data) > 0 && data[0] == '"':
+		return guid.UnmarshalJSON(data)
+	default:
+		return guid.UnmarshalText(data)
+	}
+}
+
+// SQLText stores a GUID in a text column in the format D, like
+// "bf967aba-0de6-11d0-a285-00aa003049e2". The format is chosen for each value, so that
+// columns of different formats can be used concurrently:
+//
+//	db.Exec("INSERT INTO acl (object_type) VALUES (?)", guid.SQLText{GUID: objectType})
+//	row.Scan(&guid.SQLText{GUID: objectType})
+//
+// A nil GUID is stored as NULL, and NULL is scanned as a nil GUID.
+type SQLText struct {
+	*GUID
+}
+
+// Value returns the GUID in the format D, implementing driver.Valuer.
+//
+// Returns:
+// - The GUID in the format D as a string, or nil if the GUID is nil.
+// - Always nil.
+func (text SQLText) Value() (driver.Value, error) {
+	if text.GUID == nil {
+		return nil, nil
+	}
+	return text.GUID.ToFormatD(), nil
+}
+
+// Scan parses a GUID read from a database column into the GUID, allocated if nil,
+// implementing sql.Scanner. Every representation accepted by GUID.Scan is accepted.
+//
+// Parameters:
+// - src: The value read from the database column.
+//
+// Returns:
+// - An error if the value is not a valid GUID.
+func (text *SQLText) Scan(src any) error {
+	return scanNullable(&text.GUID, src)
+}
+
+// SQLJSON stores a GUID in a JSON column as a JSON string holding the format D, see SQLText.
+// A nil GUID is stored as NULL, and NULL is scanned as a nil GUID.
+type SQLJSON struct {
+	*GUID
+}
+
+// Value returns the JSON representation of the GUID, implementing driver.Valuer.
+//
+// Returns:
+// - The JSON string as a string, or nil if the GUID is nil.
+// - An error if the GUID cannot be serialized.
+func (document SQLJSON) Value() (driver.Value, error) {
+	if document.GUID == nil {
+		return nil, nil
+	}
+	data, err := document.GUID.MarshalJSON()
+	return string(data), err
+}
+
+// Scan parses a GUID read from a database column into the GUID, allocated if nil,
+// implementing sql.Scanner. Every representation accepted by GUID.Scan is accepted.
+//
+// Parameters:
+// - src: The value read from the database column.
+//
+// Returns:
+// - An error if the value is not a valid GUID.
+func (document *SQLJSON) Scan(src any) error {
+	return scanNullable(&document.GUID, src)
+}
+
+// scanNullable scans a value into the GUID of a wrapper, setting it to nil for NULL.
+func scanNullable(target **GUID, src any) error {
+	if src == nil {
+		*target = nil
+		return nil
+	}
+	if *target == nil {
+		*target = &GUID{}
+	}
+	return (*target).Scan(src)
+}
@@ -2,6 +2,8 @@ package guid
 
 import (
 	"testing"
+
+	"github.com/TheManticoreProject/winacl/schema"
 )
 
 func TestMarshal(t *testing.T) {
@@ -151,3 +153,43 @@ func TestFromStringToStringFormatX(t *testing.T) {
 		}
 	}
 }
+
+func TestLookupNameSchemaClass(t *testing.T) {
+	tests := map[string]string{
+		"bf967aba-0de6-11d0-a285-00aa003049e2": "LDAP Class: user",
+		"bf967a86-0de6-11d0-a285-00aa003049e2": "LDAP Class: computer",
+		"7b8b558a-93a5-4af7-adca-c017e67f1057": "LDAP Class: msDS-GroupManagedServiceAccount",
+		"e0fa1e8c-9b45-11d0-afdd-00c04fd930c9": "LDAP Class: dnsNode",
+	}
+	for formatD, expected := range tests {
+		guid, err := FromString(formatD)
+		if err != nil {
+			t.Fatalf("FromString(%q) error = %v", formatD, err)
+		}
+		if name := guid.LookupName(); name != expected {
+			t.Errorf("LookupName(%s) = %q, want %q", formatD, name, expected)
+		}
+	}
+}
+
+func TestLookupNameDefaultRegistry(t *testing.T) {
+	registry := schema.NewRegistry()
+	err := registry.AddClass(schema.SchemaClass{LDAPDisplayName: "contosoWidget", SchemaIDGUID: "12345678-1234-5678-9abc-def012345678"})
+	if err != nil {
+		t.Fatalf("AddClass() error = %v", err)
+	}
+
+	guid, _ := FromString("12345678-1234-5678-9abc-def012345678")
+	if name := guid.LookupName(); name != "?" {
+		t.Errorf("LookupName() before SetDefaultRegistry = %q, want \"?\"", name)
+	}
+	if name := guid.LookupNameInRegistry(registry); name != "LDAP Class: contosoWidget" {
+		t.Errorf("LookupNameInRegistry() = %q", name)
+	}
+
+	schema.SetDefaultRegistry(registry)
+	defer schema.SetDefaultRegistry(nil)
+	if name := guid.LookupName(); name != "LDAP Class: contosoWidget" {
+		t.Errorf("LookupName() after SetDefaultRegistry = %q", name)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/TheManticoreProject/winacl/parsing"
 	"github.com/TheManticoreProject/winacl/sid"
 )
 
@@ -31,14 +32,15 @@ type Identity struct {
 // Parameters:
 //   - RawBytes ([]byte): The raw byte data containing the SID information.
 func (identity *Identity) Unmarshal(marshalledData []byte) (int, error) {
-	identity.RawBytes = marshalledData
+	identity.RawBytes = nil
 	identity.RawBytesSize = 0
 
 	_, err := identity.SID.Unmarshal(marshalledData)
 	if err != nil {
-		return 0, err
+		return 0, parsing.WrapParseError(err, 0, "SID", "")
 	}
 	identity.RawBytesSize = identity.SID.RawBytesSize
+	identity.RawBytes = marshalledData[:identity.RawBytesSize]
 
 	sidString := identity.SID.ToString()
 	if name, exists := sid.WellKnownSIDs[sidString]; exists {
@@ -53,17 +55,45 @@ func (identity *Identity) Unmarshal(marshalledData []byte) (int, error) {
 // Returns:
 //   - []byte: The serialized byte slice representing the Identity.
 func (identity *Identity) Marshal() ([]byte, error) {
-	marshalledData := []byte{}
-
-	bytesStream, err := identity.SID.Marshal()
+	marshalledData, err := identity.AppendBinary(make([]byte, 0, identity.Size()))
 	if err != nil {
 		return nil, err
 	}
-	marshalledData = append(marshalledData, bytesStream...)
-
+	identity.RefreshRawBytes(marshalledData)
 	return marshalledData, nil
 }
 
+// RefreshRawBytes points RawBytes and RawBytesSize at the marshalled bytes of the Identity,
+// which are those of its SID.
+//
+// Parameters:
+//   - marshalledData ([]byte): The bytes produced by AppendBinary for the Identity.
+func (identity *Identity) RefreshRawBytes(marshalledData []byte) {
+	identity.SID.RefreshRawBytes(marshalledData)
+	identity.RawBytes = identity.SID.RawBytes
+	identity.RawBytesSize = identity.SID.RawBytesSize
+}
+
+// Size returns the size of the binary representation of the Identity.
+//
+// Returns:
+//   - int: The size of the SID of the Identity, in bytes.
+func (identity *Identity) Size() int {
+	return identity.SID.Size()
+}
+
+// AppendBinary appends the binary representation of the Identity to a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: An error if the SID cannot be serialized.
+func (identity *Identity) AppendBinary(buffer []byte) ([]byte, error) {
+	return identity.SID.AppendBinary(buffer)
+}
+
 // Describe prints a detailed description of the Identity struct, including its SID and name,
 // formatted with indentation for clarity.
 //
@@ -1,5 +1,7 @@
 package identity
 
+import "bytes"
+
 // Equal checks if two Identity objects are equal by comparing all their fields.
 //
 // Parameters:
@@ -24,3 +26,17 @@ func (identity *Identity) Equal(other *Identity) bool {
 
 	return true
 }
+
+// Clone returns a deep copy of the Identity, sharing no memory with the original.
+//
+// Returns:
+//   - *Identity: The copy of the Identity, nil if the Identity is nil.
+func (identity *Identity) Clone() *Identity {
+	if identity == nil {
+		return nil
+	}
+	clone := *identity
+	clone.SID = *identity.SID.Clone()
+	clone.RawBytes = bytes.Clone(identity.RawBytes)
+	return &clone
+}
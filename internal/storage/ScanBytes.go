@@ -0,0 +1,31 @@
+// Package storage holds the helpers shared by the database/sql support of the sid, guid and
+// securitydescriptor packages.
+package storage
+
+import (
+	"fmt"
+)
+
+// ScanBytes returns the bytes of a value read from a database column, as given to
+// sql.Scanner.Scan. Drivers return bytea and blob columns as []byte and text columns either
+// as string or []byte, so the caller tells the formats apart from the content.
+//
+// Parameters:
+//   - src (any): The value read from the database column.
+//   - typeName (string): The name of the type scanned into, for the error messages.
+//
+// Returns:
+//   - []byte: The bytes of the value. They may be reused by the driver after Scan returns.
+//   - error: An error if the value is NULL or is neither a []byte nor a string.
+func ScanBytes(src any, typeName string) ([]byte, error) {
+	switch value := src.(type) {
+	case []byte:
+		return value, nil
+	case string:
+		return []byte(value), nil
+	case nil:
+		return nil, fmt.Errorf("cannot scan NULL into %s, scan into sql.Null[%s] instead", typeName, typeName)
+	default:
+		return nil, fmt.Errorf("cannot scan %T into %s", src, typeName)
+	}
+}
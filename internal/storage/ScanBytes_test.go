@@ -0,0 +1,36 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/internal/storage"
+)
+
+func TestScanBytes(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     any
+		want    string
+		wantErr string
+	}{
+		{"Bytes", []byte("S-1-1-0"), "S-1-1-0", ""},
+		{"String", "S-1-1-0", "S-1-1-0", ""},
+		{"Null", nil, "", "cannot scan NULL into sid.SID, scan into sql.Null[sid.SID] instead"},
+		{"Integer", int64(42), "", "cannot scan int64 into sid.SID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := storage.ScanBytes(tt.src, "sid.SID")
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("ScanBytes() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || string(got) != tt.want {
+				t.Errorf("ScanBytes() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package sqlitetest_test
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/guid"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+	"github.com/TheManticoreProject/winacl/sid"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteTestSDDL = "O:BAG:SYD:P(A;;GA;;;SY)(OA;CI;RPWP;bf967a86-0de6-11d0-a285-00aa003049e2;;AU)S:(AU;SA;GA;;;WD)"
+
+// openTestDatabase creates an SQLite database holding an objects table with a BLOB, a TEXT
+// and a JSON column for the SIDs, the GUIDs and the security descriptors.
+func openTestDatabase(t *testing.T) *sql.DB {
+	t.Helper()
+	// The busy timeout makes the concurrent writers wait for the lock instead of failing
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "winacl.db")+"?_pragma=busy_timeout(10000)")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`CREATE TABLE objects (
+		id INTEGER PRIMARY KEY,
+		sid_blob BLOB, sid_text TEXT, sid_json JSON,
+		guid_blob BLOB, guid_text TEXT, guid_json JSON,
+		sd_blob BLOB, sd_text TEXT, sd_json JSON
+	)`)
+	if err != nil {
+		t.Fatalf("CREATE TABLE error = %v", err)
+	}
+	return db
+}
+
+// testValues returns the SID, the GUID and the security descriptor stored by the tests.
+func testValues(t *testing.T) (*sid.SID, *guid.GUID, *securitydescriptor.NtSecurityDescriptor) {
+	t.Helper()
+	trustee := &sid.SID{}
+	if err := trustee.FromString("S-1-5-21-1-2-3-1105"); err != nil {
+		t.Fatalf("FromString() error = %v", err)
+	}
+	objectType, err := guid.FromString("bf967a86-0de6-11d0-a285-00aa003049e2")
+	if err != nil {
+		t.Fatalf("guid.FromString() error = %v", err)
+	}
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	if _, err := ntsd.FromSDDLString(sqliteTestSDDL); err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+	return trustee, objectType, ntsd
+}
+
+const insertObject = `INSERT INTO objects (id, sid_blob, sid_text, sid_json, guid_blob, guid_text, guid_json, sd_blob, sd_text, sd_json)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+const selectObject = `SELECT sid_blob, sid_text, sid_json, guid_blob, guid_text, guid_json, sd_blob, sd_text, sd_json
+	FROM objects WHERE id = ?`
+
+func TestSQLite_Formats(t *testing.T) {
+	db := openTestDatabase(t)
+	trustee, objectType, ntsd := testValues(t)
+
+	_, err := db.Exec(insertObject, 1,
+		trustee, sid.SQLText{SID: trustee}, sid.SQLJSON{SID: trustee},
+		*objectType, guid.SQLText{GUID: objectType}, guid.SQLJSON{GUID: objectType},
+		securitydescriptor.SQLBinary{NtSecurityDescriptor: ntsd}, securitydescriptor.SQLText{NtSecurityDescriptor: ntsd}, securitydescriptor.SQLJSON{NtSecurityDescriptor: ntsd},
+	)
+	if err != nil {
+		t.Fatalf("INSERT error = %v", err)
+	}
+
+	// Each value is stored in the format of its wrapper
+	var types string
+	err = db.QueryRow(`SELECT typeof(sid_blob) || ' ' || typeof(sid_text) || ' ' || typeof(sid_json) || ' ' ||
+		typeof(guid_blob) || ' ' || typeof(guid_text) || ' ' || typeof(guid_json) || ' ' ||
+		typeof(sd_blob) || ' ' || typeof(sd_text) || ' ' || typeof(sd_json) FROM objects WHERE id = 1`).Scan(&types)
+	if err != nil {
+		t.Fatalf("SELECT typeof() error = %v", err)
+	}
+	if want := "blob text text blob text text blob text text"; types != want {
+		t.Errorf("stored types = %s, want %s", types, want)
+	}
+	var sidText, sidJSON, guidText, sdText, sdVersion string
+	err = db.QueryRow(`SELECT sid_text, sid_json, guid_text, sd_text, json_extract(sd_json, '$.version') FROM objects WHERE id = 1`).Scan(&sidText, &sidJSON, &guidText, &sdText, &sdVersion)
+	if err != nil {
+		t.Fatalf("SELECT error = %v", err)
+	}
+	if sidText != "S-1-5-21-1-2-3-1105" || sidJSON != `"S-1-5-21-1-2-3-1105"` || guidText != "bf967a86-0de6-11d0-a285-00aa003049e2" || sdText != sqliteTestSDDL || sdVersion != "1" {
+		t.Errorf("stored values = %s, %s, %s, %s, version %s", sidText, sidJSON, guidText, sdText, sdVersion)
+	}
+
+	// The wrappers and the types themselves read every format
+	sids := []*sid.SID{{}, nil, {}}
+	guids := []*guid.GUID{{}, nil, {}}
+	descriptors := []*securitydescriptor.NtSecurityDescriptor{{}, nil, {}}
+	sidText2, guidText2, sdText2 := sid.SQLText{}, guid.SQLText{}, securitydescriptor.SQLText{}
+	err = db.QueryRow(selectObject, 1).Scan(
+		sids[0], &sidText2, &sid.SQLJSON{SID: sids[2]},
+		guids[0], &guidText2, &guid.SQLJSON{GUID: guids[2]},
+		descriptors[0], &sdText2, &securitydescriptor.SQLJSON{NtSecurityDescriptor: descriptors[2]},
+	)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	sids[1], guids[1], descriptors[1] = sidText2.SID, guidText2.GUID, sdText2.NtSecurityDescriptor
+	for index := range sids {
+		if sids[index] == nil || !sids[index].Equal(trustee) {
+			t.Errorf("Scan() of SID column %d = %v, want %s", index, sids[index], trustee)
+		}
+		if guids[index] == nil || *guids[index] != *objectType {
+			t.Errorf("Scan() of GUID column %d = %v, want %s", index, guids[index], objectType.ToFormatD())
+		}
+		if descriptors[index] == nil {
+			t.Errorf("Scan() of descriptor column %d = nil", index)
+		} else if got, _ := descriptors[index].ToSDDLString(); got != sqliteTestSDDL {
+			t.Errorf("Scan() of descriptor column %d = %s, want %s", index, got, sqliteTestSDDL)
+		}
+	}
+	want, _ := ntsd.MarshalBinary()
+	for _, index := range []int{0, 2} {
+		if got, _ := descriptors[index].MarshalBinary(); !bytes.Equal(got, want) {
+			t.Errorf("Scan() of descriptor column %d = %x, want %x", index, got, want)
+		}
+	}
+
+	// A text column can be read as any type of the same value
+	otherFormat := &sid.SQLJSON{}
+	if err := db.QueryRow(`SELECT sid_text FROM objects WHERE id = 1`).Scan(otherFormat); err != nil || !otherFormat.SID.Equal(trustee) {
+		t.Errorf("Scan() of the text column into SQLJSON = %v, %v", otherFormat.SID, err)
+	}
+}
+
+func TestSQLite_Null(t *testing.T) {
+	db := openTestDatabase(t)
+	trustee, objectType, _ := testValues(t)
+
+	var nullSID *sid.SID
+	_, err := db.Exec(insertObject, 1,
+		nullSID, sid.SQLText{}, sid.SQLJSON{},
+		sql.Null[guid.GUID]{}, guid.SQLText{}, guid.SQLJSON{},
+		securitydescriptor.SQLBinary{}, securitydescriptor.SQLText{}, securitydescriptor.SQLJSON{},
+	)
+	if err != nil {
+		t.Fatalf("INSERT error = %v", err)
+	}
+
+	var nullCount int
+	err = db.QueryRow(`SELECT (sid_blob IS NULL) + (sid_text IS NULL) + (sid_json IS NULL) + (guid_blob IS NULL) +
+		(guid_text IS NULL) + (guid_json IS NULL) + (sd_blob IS NULL) + (sd_text IS NULL) + (sd_json IS NULL)
+		FROM objects WHERE id = 1`).Scan(&nullCount)
+	if err != nil || nullCount != 9 {
+		t.Fatalf("stored %d NULL values, %v, want 9", nullCount, err)
+	}
+
+	// NULL is scanned as a nil value by the wrappers, and as an invalid sql.Null
+	sidBlob := sql.Null[sid.SID]{V: *trustee, Valid: true}
+	sidText, sidJSON := sid.SQLText{SID: trustee}, sid.SQLJSON{SID: &sid.SID{}}
+	guidBlob := sql.Null[guid.GUID]{}
+	guidText, guidJSON := guid.SQLText{GUID: objectType}, guid.SQLJSON{}
+	sdBlob := securitydescriptor.SQLBinary{NtSecurityDescriptor: &securitydescriptor.NtSecurityDescriptor{}}
+	sdText, sdJSON := securitydescriptor.SQLText{NtSecurityDescriptor: &securitydescriptor.NtSecurityDescriptor{}}, securitydescriptor.SQLJSON{}
+	err = db.QueryRow(selectObject, 1).Scan(&sidBlob, &sidText, &sidJSON, &guidBlob, &guidText, &guidJSON, &sdBlob, &sdText, &sdJSON)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if sidBlob.Valid || sidText.SID != nil || sidJSON.SID != nil || guidBlob.Valid || guidText.GUID != nil || guidJSON.GUID != nil || sdBlob.NtSecurityDescriptor != nil || sdText.NtSecurityDescriptor != nil || sdJSON.NtSecurityDescriptor != nil {
+		t.Errorf("Scan() of NULL values = %v %v %v %v %v %v %v %v %v", sidBlob.Valid, sidText.SID, sidJSON.SID, guidBlob.Valid, guidText.GUID, guidJSON.GUID, sdBlob.NtSecurityDescriptor, sdText.NtSecurityDescriptor, sdJSON.NtSecurityDescriptor)
+	}
+
+	// The types themselves cannot hold NULL
+	if err := db.QueryRow(`SELECT sid_blob FROM objects WHERE id = 1`).Scan(&sid.SID{}); err == nil {
+		t.Errorf("Scan() of NULL into a SID succeeded, want an error")
+	}
+}
+
+func TestSQLite_ConcurrentFormats(t *testing.T) {
+	db := openTestDatabase(t)
+	trustee, objectType, ntsd := testValues(t)
+
+	// The formats are chosen for each value, so writers using different formats for the same
+	// columns do not interfere
+	var wait sync.WaitGroup
+	errs := make(chan error, 40)
+	for id := 1; id <= 40; id++ {
+		wait.Add(1)
+		go func(id int) {
+			defer wait.Done()
+			var err error
+			if id%2 == 0 {
+				_, err = db.Exec(insertObject, id, nil, sid.SQLText{SID: trustee}, nil, nil, guid.SQLText{GUID: objectType}, nil, nil, securitydescriptor.SQLText{NtSecurityDescriptor: ntsd}, nil)
+			} else {
+				_, err = db.Exec(insertObject, id, nil, sid.SQLJSON{SID: trustee}, nil, nil, guid.SQLJSON{GUID: objectType}, nil, nil, securitydescriptor.SQLJSON{NtSecurityDescriptor: ntsd}, nil)
+			}
+			if err != nil {
+				errs <- fmt.Errorf("INSERT %d: %w", id, err)
+			}
+		}(id)
+	}
+	wait.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	var textCount, jsonCount int
+	err := db.QueryRow(`SELECT
+		sum(sid_text = 'S-1-5-21-1-2-3-1105' AND guid_text = 'bf967a86-0de6-11d0-a285-00aa003049e2' AND sd_text = ?),
+		sum(sid_text = '"S-1-5-21-1-2-3-1105"' AND guid_text = '"bf967a86-0de6-11d0-a285-00aa003049e2"' AND json_valid(sd_text))
+		FROM objects`, sqliteTestSDDL).Scan(&textCount, &jsonCount)
+	if err != nil || textCount != 20 || jsonCount != 20 {
+		t.Errorf("stored %d text rows and %d JSON rows, %v, want 20 and 20", textCount, jsonCount, err)
+	}
+}
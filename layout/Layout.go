@@ -0,0 +1,222 @@
+package layout
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/TheManticoreProject/winacl/parsing"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+)
+
+// FieldKind is the kind of a region of the layout of a security descriptor.
+type FieldKind uint8
+
+const (
+	// FIELD_KIND_COMPONENT is a structure holding other regions, like a SID, an ACL or an ACE.
+	FIELD_KIND_COMPONENT FieldKind = iota
+	// FIELD_KIND_FIELD is a leaf field of a structure, decoded to a value.
+	FIELD_KIND_FIELD
+	// FIELD_KIND_GAP is a range of bytes that is not used by any component.
+	FIELD_KIND_GAP
+)
+
+// FieldKindNames maps the field kinds to their names.
+var FieldKindNames = map[FieldKind]string{
+	FIELD_KIND_COMPONENT: "COMPONENT",
+	FIELD_KIND_FIELD:     "FIELD",
+	FIELD_KIND_GAP:       "GAP",
+}
+
+// String returns the name of the field kind.
+//
+// Returns:
+//   - string: The name of the field kind, or "?" if it is unknown.
+func (kind FieldKind) String() string {
+	if name, exists := FieldKindNames[kind]; exists {
+		return name
+	}
+	return "?"
+}
+
+// Field is a region of the layout of a security descriptor.
+//
+// Attributes:
+//   - Kind (FieldKind): The kind of the region.
+//   - Path (string): The path of the region, like "DACL.Entries[0].Mask". Gaps take the path
+//     of the innermost component holding them, or "" outside of any component.
+//   - Offset (int): The offset of the region, relative to the start of the descriptor.
+//   - Length (int): The length of the region, in bytes.
+//   - Raw ([]byte): The bytes of the region.
+//   - Value (string): The decoded value of a field, or a summary of a component.
+type Field struct {
+	Kind   FieldKind
+	Path   string
+	Offset int
+	Length int
+	Raw    []byte
+	Value  string
+}
+
+// End returns the offset following the region.
+//
+// Returns:
+//   - int: The offset of the first byte after the region.
+func (field *Field) End() int {
+	return field.Offset + field.Length
+}
+
+// Layout is the byte layout of a marshalled security descriptor.
+//
+// Attributes:
+//   - Data ([]byte): The marshalled security descriptor.
+//   - Fields ([]Field): The regions of the descriptor, ordered by offset. A component comes
+//     before the regions it holds.
+//   - Anomalies (parsing.Anomalies): The anomalies found while parsing the descriptor leniently.
+type Layout struct {
+	Data      []byte
+	Fields    []Field
+	Anomalies parsing.Anomalies
+}
+
+// Explain parses a marshalled security descriptor and maps every byte of it: the components
+// and their fields with their offsets, lengths, raw bytes and decoded values, and the gaps
+// that are not used by any component. The descriptor is parsed leniently so that odd
+// descriptors can still be inspected, see parsing.Options; components that cannot be parsed
+// show up as gaps.
+//
+// Parameters:
+//   - marshalledData ([]byte): The marshalled security descriptor.
+//
+// Returns:
+//   - *Layout: The layout of the descriptor.
+//   - error: An error if the header of the descriptor cannot be parsed.
+func Explain(marshalledData []byte) (*Layout, error) {
+	ntsd := securitydescriptor.NtSecurityDescriptor{}
+	_, anomalies, err := ntsd.UnmarshalWithOptions(marshalledData, parsing.Options{Lenient: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain security descriptor: %w", err)
+	}
+
+	explainer := explainer{data: marshalledData}
+	explainer.explainSecurityDescriptor(&ntsd)
+
+	layout := &Layout{
+		Data:      marshalledData,
+		Fields:    explainer.fields,
+		Anomalies: anomalies,
+	}
+	layout.sort()
+	layout.addGaps()
+
+	return layout, nil
+}
+
+// Leaves returns the fields and the gaps of the layout, without the components.
+//
+// Returns:
+//   - []Field: The fields and the gaps, ordered by offset.
+func (layout *Layout) Leaves() []Field {
+	leaves := []Field{}
+	for _, field := range layout.Fields {
+		if field.Kind != FIELD_KIND_COMPONENT {
+			leaves = append(leaves, field)
+		}
+	}
+	return leaves
+}
+
+// Gaps returns the ranges of bytes of the layout that are not used by any component.
+//
+// Returns:
+//   - []Field: The gaps, ordered by offset.
+func (layout *Layout) Gaps() []Field {
+	gaps := []Field{}
+	for _, field := range layout.Fields {
+		if field.Kind == FIELD_KIND_GAP {
+			gaps = append(gaps, field)
+		}
+	}
+	return gaps
+}
+
+// Lookup returns the region of the layout at a path.
+//
+// Parameters:
+//   - path (string): The path of the region, like "Owner.SID.RelativeIdentifier".
+//
+// Returns:
+//   - *Field: The first region with this path, or nil if there is none.
+func (layout *Layout) Lookup(path string) *Field {
+	for index := range layout.Fields {
+		if layout.Fields[index].Kind != FIELD_KIND_GAP && layout.Fields[index].Path == path {
+			return &layout.Fields[index]
+		}
+	}
+	return nil
+}
+
+// sort orders the regions by offset, keeping the components before the regions they hold.
+func (layout *Layout) sort() {
+	sort.SliceStable(layout.Fields, func(i, j int) bool {
+		return layout.Fields[i].Offset < layout.Fields[j].Offset
+	})
+}
+
+// addGaps inserts a gap for each range of bytes that is not covered by a field. The layout
+// must be sorted.
+func (layout *Layout) addGaps() {
+	fields := []Field{}
+	covered := 0
+
+	flush := func(end int) {
+		if end <= covered {
+			return
+		}
+		fields = append(fields, Field{
+			Kind:   FIELD_KIND_GAP,
+			Path:   layout.innermostComponent(covered, end),
+			Offset: covered,
+			Length: end - covered,
+			Raw:    layout.Data[covered:end],
+			Value:  fmt.Sprintf("%d unused bytes", end-covered),
+		})
+		covered = end
+	}
+
+	for _, field := range layout.Fields {
+		if field.Kind == FIELD_KIND_FIELD {
+			flush(field.Offset)
+			if field.End() > covered {
+				covered = field.End()
+			}
+		}
+		fields = append(fields, field)
+	}
+	flush(len(layout.Data))
+
+	layout.Fields = fields
+	layout.sort()
+}
+
+// innermostComponent returns the path of the smallest component holding a range of bytes.
+//
+// Parameters:
+//   - offset (int): The offset of the range.
+//   - end (int): The offset following the range.
+//
+// Returns:
+//   - string: The path of the component, or "" if no component holds the range.
+func (layout *Layout) innermostComponent(offset int, end int) string {
+	path := ""
+	length := -1
+	for _, field := range layout.Fields {
+		if field.Kind != FIELD_KIND_COMPONENT || field.Offset > offset || field.End() < end {
+			continue
+		}
+		if length == -1 || field.Length < length {
+			path = field.Path
+			length = field.Length
+		}
+	}
+	return path
+}
@@ -0,0 +1,171 @@
+package layout
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+	"github.com/TheManticoreProject/winacl/sid"
+)
+
+// explainer collects the regions of a security descriptor, walking its components with the
+// RawBytesSize bookkeeping they filled while being parsed.
+type explainer struct {
+	data   []byte
+	fields []Field
+}
+
+// add records a region, clamped to the data of the descriptor.
+//
+// Parameters:
+//   - kind (FieldKind): The kind of the region.
+//   - offset (int): The offset of the region, relative to the start of the descriptor.
+//   - length (int): The length of the region, in bytes.
+//   - path (string): The path of the region.
+//   - format (string): The format of the value of the region, followed by its arguments.
+func (explainer *explainer) add(kind FieldKind, offset int, length int, path string, format string, args ...any) {
+	if offset < 0 || offset >= len(explainer.data) || length <= 0 {
+		return
+	}
+	if offset+length > len(explainer.data) {
+		length = len(explainer.data) - offset
+	}
+	explainer.fields = append(explainer.fields, Field{
+		Kind:   kind,
+		Path:   path,
+		Offset: offset,
+		Length: length,
+		Raw:    explainer.data[offset : offset+length],
+		Value:  fmt.Sprintf(format, args...),
+	})
+}
+
+// explainSecurityDescriptor records the header of the descriptor and its components.
+func (explainer *explainer) explainSecurityDescriptor(ntsd *securitydescriptor.NtSecurityDescriptor) {
+	header := &ntsd.Header
+	explainer.add(FIELD_KIND_COMPONENT, 0, 20, "Header", "NtSecurityDescriptorHeader")
+	explainer.add(FIELD_KIND_FIELD, 0, 1, "Header.Revision", "%d", header.Revision)
+	explainer.add(FIELD_KIND_FIELD, 1, 1, "Header.Sbz1", "0x%02x", header.Sbz1)
+	controlFlags := append([]string{}, header.Control.Flags...)
+	sort.Strings(controlFlags)
+	explainer.add(FIELD_KIND_FIELD, 2, 2, "Header.Control", "0x%04x (%s)", header.Control.RawValue, strings.Join(controlFlags, "|"))
+	explainer.add(FIELD_KIND_FIELD, 4, 4, "Header.OffsetOwner", "0x%08x", header.OffsetOwner)
+	explainer.add(FIELD_KIND_FIELD, 8, 4, "Header.OffsetGroup", "0x%08x", header.OffsetGroup)
+	explainer.add(FIELD_KIND_FIELD, 12, 4, "Header.OffsetSacl", "0x%08x", header.OffsetSacl)
+	explainer.add(FIELD_KIND_FIELD, 16, 4, "Header.OffsetDacl", "0x%08x", header.OffsetDacl)
+
+	if ntsd.Owner != nil && header.OffsetOwner != 0 {
+		explainer.explainSID(int(header.OffsetOwner), "Owner.SID", &ntsd.Owner.SID)
+	}
+	if ntsd.Group != nil && header.OffsetGroup != 0 {
+		explainer.explainSID(int(header.OffsetGroup), "Group.SID", &ntsd.Group.SID)
+	}
+	if ntsd.SACL != nil && header.OffsetSacl != 0 {
+		h := &ntsd.SACL.Header
+		explainer.explainACL(int(header.OffsetSacl), "SACL", h.Revision.Value, h.Sbz1, h.AclSize, h.AceCount, h.Sbz2, ntsd.SACL.Entries)
+	}
+	if ntsd.DACL != nil && header.OffsetDacl != 0 {
+		h := &ntsd.DACL.Header
+		explainer.explainACL(int(header.OffsetDacl), "DACL", h.Revision.Value, h.Sbz1, h.AclSize, h.AceCount, h.Sbz2, ntsd.DACL.Entries)
+	}
+}
+
+// explainSID records a SID and its fields.
+func (explainer *explainer) explainSID(offset int, path string, s *sid.SID) {
+	explainer.add(FIELD_KIND_COMPONENT, offset, int(s.RawBytesSize), path, "%s", s.ToString())
+	explainer.add(FIELD_KIND_FIELD, offset, 1, path+".RevisionLevel", "%d", s.RevisionLevel)
+	explainer.add(FIELD_KIND_FIELD, offset+1, 1, path+".SubAuthorityCount", "%d", s.SubAuthorityCount)
+	explainer.add(FIELD_KIND_FIELD, offset+2, 6, path+".IdentifierAuthority", "%d", s.IdentifierAuthority.Value)
+	cursor := offset + 8
+	for index, subAuthority := range s.SubAuthorities {
+		explainer.add(FIELD_KIND_FIELD, cursor, 4, fmt.Sprintf("%s.SubAuthorities[%d]", path, index), "%d", subAuthority)
+		cursor += 4
+	}
+	if s.SubAuthorityCount > 0 {
+		explainer.add(FIELD_KIND_FIELD, cursor, 4, path+".RelativeIdentifier", "%d", s.RelativeIdentifier)
+	}
+}
+
+// explainACL records a DACL or a SACL, its header and its entries. The component spans the
+// AclSize of the header, so that the slack after the last entry shows up as a gap of the ACL.
+func (explainer *explainer) explainACL(offset int, path string, revision uint8, sbz1 uint8, aclSize uint16, aceCount uint16, sbz2 uint16, entries []ace.AccessControlEntry) {
+	explainer.add(FIELD_KIND_COMPONENT, offset, int(aclSize), path, "%d entries", len(entries))
+	explainer.add(FIELD_KIND_FIELD, offset, 1, path+".Header.Revision", "%d", revision)
+	explainer.add(FIELD_KIND_FIELD, offset+1, 1, path+".Header.Sbz1", "0x%02x", sbz1)
+	explainer.add(FIELD_KIND_FIELD, offset+2, 2, path+".Header.AclSize", "%d", aclSize)
+	explainer.add(FIELD_KIND_FIELD, offset+4, 2, path+".Header.AceCount", "%d", aceCount)
+	explainer.add(FIELD_KIND_FIELD, offset+6, 2, path+".Header.Sbz2", "0x%04x", sbz2)
+
+	cursor := offset + 8
+	for index := range entries {
+		explainer.explainACE(cursor, fmt.Sprintf("%s.Entries[%d]", path, index), &entries[index])
+		cursor += int(entries[index].Header.Size)
+	}
+}
+
+// explainACE records an ACE, its header and the fields of its body.
+func (explainer *explainer) explainACE(offset int, path string, entry *ace.AccessControlEntry) {
+	size := int(entry.Header.Size)
+	explainer.add(FIELD_KIND_COMPONENT, offset, size, path, "%s", entry.Header.Type.String())
+	explainer.add(FIELD_KIND_FIELD, offset, 1, path+".Header.Type", "0x%02x (%s)", entry.Header.Type.Value, entry.Header.Type.String())
+	explainer.add(FIELD_KIND_FIELD, offset+1, 1, path+".Header.Flags", "0x%02x (%s)", entry.Header.Flags.RawValue, entry.Header.Flags.String())
+	explainer.add(FIELD_KIND_FIELD, offset+2, 2, path+".Header.Size", "%d", entry.Header.Size)
+
+	cursor := offset + 4
+	if entry.Opaque {
+		explainer.add(FIELD_KIND_FIELD, cursor, len(entry.ApplicationData), path+".Body", "opaque body of %d bytes", len(entry.ApplicationData))
+		return
+	}
+
+	explainer.add(FIELD_KIND_FIELD, cursor, 4, path+".Mask", "0x%08x (%s)", entry.Mask.RawValue, entry.Mask.String())
+	cursor += 4
+
+	if entry.IsObjectAce() {
+		objectType := &entry.AccessControlObjectType
+		explainer.add(FIELD_KIND_FIELD, cursor, 4, path+".AccessControlObjectType.Flags", "0x%08x (%s)", objectType.Flags.Value, objectType.Flags.String())
+		cursor += 4
+		if objectType.Flags.IsObjectTypePresent() {
+			explainer.add(FIELD_KIND_FIELD, cursor, 16, path+".AccessControlObjectType.ObjectType", "%s", objectType.ObjectType.GUID.ToFormatD())
+			cursor += 16
+		}
+		if objectType.Flags.IsInheritedObjectTypePresent() {
+			explainer.add(FIELD_KIND_FIELD, cursor, 16, path+".AccessControlObjectType.InheritedObjectType", "%s", objectType.InheritedObjectType.GUID.ToFormatD())
+			cursor += 16
+		}
+	}
+
+	if entry.Header.Type.Value == acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND {
+		explainer.add(FIELD_KIND_FIELD, cursor, 2, path+".Compound.Type", "%d", entry.Compound.Type)
+		explainer.add(FIELD_KIND_FIELD, cursor+2, 2, path+".Compound.Reserved", "0x%04x", entry.Compound.Reserved)
+		explainer.explainSID(cursor+4, path+".Compound.ServerIdentity.SID", &entry.Compound.ServerIdentity.SID)
+		cursor += int(entry.Compound.RawBytesSize)
+	}
+
+	explainer.explainSID(cursor, path+".Identity.SID", &entry.Identity.SID)
+	cursor += int(entry.Identity.RawBytesSize)
+
+	if len(entry.ApplicationData) != 0 {
+		explainer.add(FIELD_KIND_FIELD, cursor, len(entry.ApplicationData), path+".ApplicationData", "%s", describeApplicationData(entry))
+		cursor += len(entry.ApplicationData)
+	}
+
+	if cursor < offset+size {
+		explainer.add(FIELD_KIND_FIELD, cursor, offset+size-cursor, path+".Padding", "%d bytes", offset+size-cursor)
+	}
+}
+
+// describeApplicationData returns the decoded value of the ApplicationData of an ACE: the
+// SDDL form of the conditional expression of callback ACEs, or its length otherwise.
+func describeApplicationData(entry *ace.AccessControlEntry) string {
+	condition, err := entry.Condition()
+	if err == nil && condition != nil {
+		sddl, err := condition.ToSDDLString()
+		if err == nil {
+			return sddl
+		}
+	}
+	return fmt.Sprintf("%d bytes", len(entry.ApplicationData))
+}
@@ -0,0 +1,171 @@
+package layout
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// HEXDUMP_BYTES_PER_LINE is the number of bytes printed on each line of the hexdump.
+const HEXDUMP_BYTES_PER_LINE = 16
+
+// HexDump renders the layout as an annotated hexdump for terminals. Each field or gap is
+// printed with its offset, its bytes and its decoded value, and each component opens an
+// indented block of annotations. Fields longer than HEXDUMP_BYTES_PER_LINE bytes span several
+// lines.
+//
+// Parameters:
+//   - colors (bool): Whether to highlight the output with ANSI escape sequences.
+//
+// Returns:
+//   - string: The annotated hexdump.
+func (layout *Layout) HexDump(colors bool) string {
+	paint := func(code string, text string) string {
+		if !colors {
+			return text
+		}
+		return "\x1b[" + code + "m" + text + "\x1b[0m"
+	}
+
+	blank := strings.Repeat(" ", HEXDUMP_BYTES_PER_LINE*3-1)
+	builder := strings.Builder{}
+	ends := []int{}
+	for _, field := range layout.Fields {
+		// Close the components that end before this region.
+		for len(ends) != 0 && ends[len(ends)-1] <= field.Offset {
+			ends = ends[:len(ends)-1]
+		}
+		indentPrompt := strings.Repeat(" │ ", len(ends))
+
+		if field.Kind == FIELD_KIND_COMPONENT {
+			fmt.Fprintf(&builder, "0x%04x  %s  %s%s %s\n",
+				field.Offset,
+				blank,
+				indentPrompt,
+				paint("1;94", fmt.Sprintf("<%s>", field.Path)),
+				paint("90", fmt.Sprintf("0x%04x-0x%04x (%d bytes) %s", field.Offset, field.End(), field.Length, field.Value)),
+			)
+			ends = append(ends, field.End())
+			continue
+		}
+
+		name := field.Path
+		if field.Kind == FIELD_KIND_GAP {
+			name = paint("91", "<gap>")
+		} else {
+			name = paint("93", name)
+		}
+		for start := 0; start < len(field.Raw); start += HEXDUMP_BYTES_PER_LINE {
+			end := min(start+HEXDUMP_BYTES_PER_LINE, len(field.Raw))
+			chunk := fmt.Sprintf("%-*s", HEXDUMP_BYTES_PER_LINE*3-1, hexBytes(field.Raw[start:end]))
+			if start == 0 {
+				fmt.Fprintf(&builder, "0x%04x  %s  %s%s : %s\n", field.Offset, chunk, indentPrompt, name, paint("96", field.Value))
+			} else {
+				fmt.Fprintf(&builder, "0x%04x  %s  %s\n", field.Offset+start, chunk, indentPrompt)
+			}
+		}
+	}
+
+	for _, anomaly := range layout.Anomalies {
+		fmt.Fprintf(&builder, "%s %s\n", paint("91", "anomaly"), anomaly.String())
+	}
+
+	return builder.String()
+}
+
+// hexBytes formats bytes as space separated hexadecimal pairs.
+//
+// Parameters:
+//   - data ([]byte): The bytes to format.
+//
+// Returns:
+//   - string: The formatted bytes, like "01 00 04 80".
+func hexBytes(data []byte) string {
+	pairs := make([]string, len(data))
+	for index, value := range data {
+		pairs[index] = fmt.Sprintf("%02x", value)
+	}
+	return strings.Join(pairs, " ")
+}
+
+// jsonField is the JSON form of a Field.
+type jsonField struct {
+	Kind   string `json:"kind"`
+	Path   string `json:"path"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	Raw    string `json:"raw"`
+	Value  string `json:"value"`
+}
+
+// jsonAnomaly is the JSON form of a parsing.Anomaly.
+type jsonAnomaly struct {
+	Offset  int    `json:"offset"`
+	Path    string `json:"path"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// jsonLayout is the JSON form of a Layout.
+type jsonLayout struct {
+	Size      int           `json:"size"`
+	Fields    []jsonField   `json:"fields"`
+	Anomalies []jsonAnomaly `json:"anomalies"`
+}
+
+// MarshalJSON renders the field as a JSON object, with its raw bytes in hexadecimal.
+//
+// Returns:
+//   - []byte: The JSON object.
+//   - error: An error if the field cannot be rendered.
+func (field Field) MarshalJSON() ([]byte, error) {
+	return json.Marshal(field.toJSON())
+}
+
+// toJSON returns the JSON form of the field.
+func (field *Field) toJSON() jsonField {
+	return jsonField{
+		Kind:   field.Kind.String(),
+		Path:   field.Path,
+		Offset: field.Offset,
+		Length: field.Length,
+		Raw:    hex.EncodeToString(field.Raw),
+		Value:  field.Value,
+	}
+}
+
+// MarshalJSON renders the layout as a JSON object holding the size of the descriptor, its
+// regions and the anomalies found while parsing it.
+//
+// Returns:
+//   - []byte: The JSON object.
+//   - error: An error if the layout cannot be rendered.
+func (layout *Layout) MarshalJSON() ([]byte, error) {
+	document := jsonLayout{
+		Size:      len(layout.Data),
+		Fields:    make([]jsonField, 0, len(layout.Fields)),
+		Anomalies: make([]jsonAnomaly, 0, len(layout.Anomalies)),
+	}
+	for index := range layout.Fields {
+		document.Fields = append(document.Fields, layout.Fields[index].toJSON())
+	}
+	for _, anomaly := range layout.Anomalies {
+		document.Anomalies = append(document.Anomalies, jsonAnomaly{
+			Offset:  anomaly.Offset,
+			Path:    anomaly.Path,
+			Code:    string(anomaly.Code),
+			Message: anomaly.Message,
+		})
+	}
+	return json.Marshal(document)
+}
+
+// ToJSON renders the layout as an indented JSON document, see MarshalJSON.
+//
+// Returns:
+//   - []byte: The JSON document.
+//   - error: An error if the layout cannot be rendered.
+func (layout *Layout) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(layout, "", "  ")
+}
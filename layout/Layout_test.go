@@ -0,0 +1,215 @@
+package layout_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/layout"
+	"github.com/TheManticoreProject/winacl/parsing"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+)
+
+// layoutTestDescriptor returns the marshalled bytes of a descriptor with an owner, a group and
+// a DACL holding a plain ACE and an object ACE.
+func layoutTestDescriptor(t *testing.T) []byte {
+	t.Helper()
+	ntsd := securitydescriptor.NtSecurityDescriptor{}
+	if _, err := ntsd.FromSDDLString("O:BAG:SYD:(A;;FA;;;WD)(OA;;CR;00299570-246d-11d0-a768-00aa006e0529;;S-1-5-21-1-2-3-500)"); err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+	marshalledData, err := ntsd.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	return marshalledData
+}
+
+// checkCoverage checks that the fields and gaps of the layout cover every byte exactly once.
+func checkCoverage(t *testing.T, explained *layout.Layout) {
+	t.Helper()
+	covered := 0
+	for _, leaf := range explained.Leaves() {
+		if leaf.Offset != covered {
+			t.Fatalf("leaf %s at 0x%04x, want 0x%04x", leaf.Path, leaf.Offset, covered)
+		}
+		if !bytes.Equal(leaf.Raw, explained.Data[leaf.Offset:leaf.End()]) {
+			t.Errorf("leaf %s Raw = %x", leaf.Path, leaf.Raw)
+		}
+		covered = leaf.End()
+	}
+	if covered != len(explained.Data) {
+		t.Errorf("leaves cover %d bytes, want %d", covered, len(explained.Data))
+	}
+}
+
+func TestExplain(t *testing.T) {
+	marshalledData := layoutTestDescriptor(t)
+	explained, err := layout.Explain(marshalledData)
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	checkCoverage(t, explained)
+
+	if gaps := explained.Gaps(); len(gaps) != 0 {
+		t.Errorf("Gaps() = %v, want none", gaps)
+	}
+
+	offsetDacl := int(binary.LittleEndian.Uint32(marshalledData[16:20]))
+	tests := []struct {
+		path   string
+		offset int
+		length int
+		value  string
+	}{
+		{"Header.Control", 2, 2, "0x8004 (DP|SR)"},
+		{"DACL", offsetDacl, 84, "2 entries"},
+		{"DACL.Header.AceCount", offsetDacl + 4, 2, "2"},
+		{"DACL.Entries[0].Identity.SID", offsetDacl + 16, 12, "S-1-1-0"},
+		{"DACL.Entries[1].Header.Type", offsetDacl + 28, 1, "0x05 (ACCESS_ALLOWED_OBJECT)"},
+		{"DACL.Entries[1].AccessControlObjectType.ObjectType", offsetDacl + 40, 16, "00299570-246d-11d0-a768-00aa006e0529"},
+		{"DACL.Entries[1].Identity.SID.RelativeIdentifier", offsetDacl + 80, 4, "500"},
+		{"Owner.SID", int(binary.LittleEndian.Uint32(marshalledData[4:8])), 16, "S-1-5-32-544"},
+		{"Group.SID.IdentifierAuthority", int(binary.LittleEndian.Uint32(marshalledData[8:12])) + 2, 6, "5"},
+	}
+	for _, tt := range tests {
+		field := explained.Lookup(tt.path)
+		if field == nil {
+			t.Errorf("Lookup(%q) = nil", tt.path)
+			continue
+		}
+		if field.Offset != tt.offset || field.Length != tt.length || field.Value != tt.value {
+			t.Errorf("Lookup(%q) = 0x%04x+%d %q, want 0x%04x+%d %q", tt.path, field.Offset, field.Length, field.Value, tt.offset, tt.length, tt.value)
+		}
+	}
+}
+
+func TestExplain_Gaps(t *testing.T) {
+	marshalledData := layoutTestDescriptor(t)
+	offsetDacl := int(binary.LittleEndian.Uint32(marshalledData[16:20]))
+
+	// Move the DACL 8 bytes further and append 4 trailing bytes.
+	data := append([]byte{}, marshalledData[:offsetDacl]...)
+	data = append(data, make([]byte, 8)...)
+	data = append(data, marshalledData[offsetDacl:]...)
+	data = append(data, 0xde, 0xad, 0xbe, 0xef)
+	binary.LittleEndian.PutUint32(data[16:20], uint32(offsetDacl+8))
+	binary.LittleEndian.PutUint32(data[4:8], binary.LittleEndian.Uint32(data[4:8])+8)
+	binary.LittleEndian.PutUint32(data[8:12], binary.LittleEndian.Uint32(data[8:12])+8)
+
+	explained, err := layout.Explain(data)
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	checkCoverage(t, explained)
+
+	gaps := explained.Gaps()
+	if len(gaps) != 2 {
+		t.Fatalf("Gaps() = %v, want 2 gaps", gaps)
+	}
+	if gaps[0].Offset != offsetDacl || gaps[0].Length != 8 || gaps[0].Path != "" {
+		t.Errorf("Gaps()[0] = %+v", gaps[0])
+	}
+	if gaps[1].Offset != len(data)-4 || !bytes.Equal(gaps[1].Raw, []byte{0xde, 0xad, 0xbe, 0xef}) {
+		t.Errorf("Gaps()[1] = %+v", gaps[1])
+	}
+}
+
+func TestExplain_DroppedComponent(t *testing.T) {
+	marshalledData := layoutTestDescriptor(t)
+	offsetGroup := int(binary.LittleEndian.Uint32(marshalledData[8:12]))
+	// Claim more sub-authorities than the group SID holds, the group is dropped.
+	marshalledData[offsetGroup+1] = 15
+
+	explained, err := layout.Explain(marshalledData)
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	checkCoverage(t, explained)
+
+	if explained.Lookup("Group.SID") != nil {
+		t.Errorf("Lookup(Group.SID) is not nil for a dropped group")
+	}
+	gaps := explained.Gaps()
+	if len(gaps) != 1 || gaps[0].Offset != offsetGroup || gaps[0].End() != len(marshalledData) {
+		t.Errorf("Gaps() = %v, want the group", gaps)
+	}
+	if len(explained.Anomalies) != 1 || explained.Anomalies[0].Code != parsing.ANOMALY_CODE_COMPONENT_INVALID {
+		t.Errorf("Anomalies = %v", explained.Anomalies)
+	}
+}
+
+func TestExplain_InvalidHeader(t *testing.T) {
+	if _, err := layout.Explain([]byte{0x01, 0x00}); err == nil {
+		t.Errorf("Explain() expected an error for a truncated header")
+	}
+}
+
+func TestLayout_HexDump(t *testing.T) {
+	explained, err := layout.Explain(append(layoutTestDescriptor(t), 0x00, 0x00))
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+
+	dump := explained.HexDump(false)
+	line := func(offset int, hexBytes string, annotation string) string {
+		return fmt.Sprintf("0x%04x  %-47s  %s\n", offset, hexBytes, annotation)
+	}
+	expected := []string{
+		line(0x00, "01", " │ Header.Revision : 1"),
+		line(0x02, "04 80", " │ Header.Control : 0x8004 (DP|SR)"),
+		line(0x14, "", "<DACL> 0x0014-0x0068 (84 bytes) 2 entries"),
+		line(0x30, "05", " │  │ DACL.Entries[1].Header.Type : 0x05 (ACCESS_ALLOWED_OBJECT)"),
+		line(0x3c, "70 95 29 00 6d 24 d0 11 a7 68 00 aa 00 6e 05 29", " │  │ DACL.Entries[1].AccessControlObjectType.ObjectType : 00299570-246d-11d0-a768-00aa006e0529"),
+		line(0x84, "00 00", "<gap> : 2 unused bytes"),
+	}
+	for _, expectedLine := range expected {
+		if !strings.Contains(dump, expectedLine) {
+			t.Errorf("HexDump() does not contain %q:\n%s", expectedLine, dump)
+		}
+	}
+	if strings.Contains(dump, "\x1b[") {
+		t.Errorf("HexDump(false) contains escape sequences")
+	}
+	if !strings.Contains(explained.HexDump(true), "\x1b[93mHeader.Revision\x1b[0m") {
+		t.Errorf("HexDump(true) does not highlight the fields")
+	}
+}
+
+func TestLayout_ToJSON(t *testing.T) {
+	marshalledData := layoutTestDescriptor(t)
+	explained, err := layout.Explain(marshalledData)
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	data, err := explained.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+
+	document := struct {
+		Size   int `json:"size"`
+		Fields []struct {
+			Kind   string `json:"kind"`
+			Path   string `json:"path"`
+			Offset int    `json:"offset"`
+			Length int    `json:"length"`
+			Raw    string `json:"raw"`
+			Value  string `json:"value"`
+		} `json:"fields"`
+		Anomalies []any `json:"anomalies"`
+	}{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if document.Size != len(marshalledData) || len(document.Fields) != len(explained.Fields) || document.Anomalies == nil {
+		t.Errorf("ToJSON() = %s", data)
+	}
+	first := document.Fields[0]
+	if first.Kind != "COMPONENT" || first.Path != "Header" || first.Raw != "0100048068000000780000000000000014000000" {
+		t.Errorf("ToJSON() fields[0] = %+v", first)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/TheManticoreProject/winacl/object/flags"
+	"github.com/TheManticoreProject/winacl/parsing"
 )
 
 // AccessControlObjectType represents the access control object type.
@@ -27,12 +28,15 @@ type AccessControlObjectType struct {
 //   - int: The size of the parsed AccessControlObjectType in bytes.
 //   - error: An error if the parsing fails.
 func (aco *AccessControlObjectType) Unmarshal(rawBytes []byte) (int, error) {
+	aco.RawBytes = nil
 	aco.RawBytesSize = 0
 
 	if len(rawBytes) < 4 {
-		return 0, fmt.Errorf("AccessControlObjectType unmarshal requires at least 4 bytes, got %d", len(rawBytes))
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "AccessControlObjectType unmarshal requires at least 4 bytes, got %d", len(rawBytes))
 	}
 
+	marshalledData := rawBytes
+
 	rawBytesSize, err := aco.Flags.Unmarshal(rawBytes[0:4])
 	if err != nil {
 		return 0, err
@@ -45,7 +49,7 @@ func (aco *AccessControlObjectType) Unmarshal(rawBytes []byte) (int, error) {
 		if (aco.Flags.Value & flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT) == flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT {
 			rawBytesSize, err = aco.ObjectType.Unmarshal(rawBytes)
 			if err != nil {
-				return 0, err
+				return 0, parsing.WrapParseError(err, int(aco.RawBytesSize), "ObjectType", "")
 			}
 			aco.RawBytesSize += uint32(rawBytesSize)
 			rawBytes = rawBytes[rawBytesSize:]
@@ -55,13 +59,15 @@ func (aco *AccessControlObjectType) Unmarshal(rawBytes []byte) (int, error) {
 		if (aco.Flags.Value & flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT) == flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT {
 			rawBytesSize, err = aco.InheritedObjectType.Unmarshal(rawBytes)
 			if err != nil {
-				return 0, err
+				return 0, parsing.WrapParseError(err, int(aco.RawBytesSize), "InheritedObjectType", "")
 			}
 			aco.RawBytesSize += uint32(rawBytesSize)
 			// rawBytes = rawBytes[rawBytesSize:]
 		}
 	}
 
+	aco.RawBytes = marshalledData[:aco.RawBytesSize]
+
 	return int(aco.RawBytesSize), nil
 }
 
@@ -70,31 +76,78 @@ func (aco *AccessControlObjectType) Unmarshal(rawBytes []byte) (int, error) {
 // Returns:
 //   - []byte: The serialized byte slice representing the AccessControlObjectType.
 func (aco *AccessControlObjectType) Marshal() ([]byte, error) {
-	var marshalledData []byte
+	marshalledData, err := aco.AppendBinary(make([]byte, 0, aco.Size()))
+	if err != nil {
+		return nil, err
+	}
+	aco.RefreshRawBytes(marshalledData)
+	return marshalledData, nil
+}
+
+// RefreshRawBytes points RawBytes and RawBytesSize at the marshalled bytes of the
+// AccessControlObjectType, and refreshes the GUIDs announced by its flags.
+//
+// Parameters:
+//   - marshalledData ([]byte): The bytes produced by AppendBinary for the AccessControlObjectType.
+func (aco *AccessControlObjectType) RefreshRawBytes(marshalledData []byte) {
+	aco.RawBytesSize = uint32(aco.Size())
+	aco.RawBytes = marshalledData[:aco.RawBytesSize]
+
+	offset := aco.Flags.Size()
+	if (aco.Flags.Value & flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT) == flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT {
+		aco.ObjectType.RefreshRawBytes(marshalledData[offset:])
+		offset += aco.ObjectType.Size()
+	}
+	if (aco.Flags.Value & flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT) == flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT {
+		aco.InheritedObjectType.RefreshRawBytes(marshalledData[offset:])
+	}
+}
+
+// Size returns the size of the binary representation of the AccessControlObjectType, which
+// depends on the GUIDs announced by its flags.
+//
+// Returns:
+//   - int: The size of the flags and of the present GUIDs, in bytes.
+func (aco *AccessControlObjectType) Size() int {
+	size := aco.Flags.Size()
+	if (aco.Flags.Value & flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT) == flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT {
+		size += aco.ObjectType.Size()
+	}
+	if (aco.Flags.Value & flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT) == flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT {
+		size += aco.InheritedObjectType.Size()
+	}
+	return size
+}
 
-	bytesStream, err := aco.Flags.Marshal()
+// AppendBinary appends the binary representation of the AccessControlObjectType to a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: An error if one of the fields cannot be serialized.
+func (aco *AccessControlObjectType) AppendBinary(buffer []byte) ([]byte, error) {
+	buffer, err := aco.Flags.AppendBinary(buffer)
 	if err != nil {
 		return nil, err
 	}
-	marshalledData = append(marshalledData, bytesStream...)
 
 	if (aco.Flags.Value & flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT) == flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT {
-		bytesStream, err = aco.ObjectType.Marshal()
+		buffer, err = aco.ObjectType.AppendBinary(buffer)
 		if err != nil {
 			return nil, err
 		}
-		marshalledData = append(marshalledData, bytesStream...)
 	}
 
 	if (aco.Flags.Value & flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT) == flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT {
-		bytesStream, err = aco.InheritedObjectType.Marshal()
+		buffer, err = aco.InheritedObjectType.AppendBinary(buffer)
 		if err != nil {
 			return nil, err
 		}
-		marshalledData = append(marshalledData, bytesStream...)
 	}
 
-	return marshalledData, nil
+	return buffer, nil
 }
 
 // Describe prints a human-readable representation of the AccessControlObjectType.
@@ -102,20 +155,32 @@ func (aco *AccessControlObjectType) Marshal() ([]byte, error) {
 // Attributes:
 //   - indent (int): The indentation level for the output.
 func (aco *AccessControlObjectType) Describe(indent int) {
+	aco.DescribeWithNames(indent, aco.ObjectType.GUID.LookupName(), aco.InheritedObjectType.GUID.LookupName())
+}
+
+// DescribeWithNames prints a human-readable representation of the AccessControlObjectType, using
+// the given names for the ObjectType and InheritedObjectType GUIDs. This lets the caller, which
+// knows the access mask of the ACE, print what the GUIDs actually designate.
+//
+// Attributes:
+//   - indent (int): The indentation level for the output.
+//   - objectTypeName (string): The name printed next to the ObjectType GUID.
+//   - inheritedObjectTypeName (string): The name printed next to the InheritedObjectType GUID.
+func (aco *AccessControlObjectType) DescribeWithNames(indent int, objectTypeName, inheritedObjectTypeName string) {
 	indentPrompt := strings.Repeat(" │ ", indent)
 
 	fmt.Printf("%s<AccessControlObjectType>\n", indentPrompt)
 
 	if aco.Flags.Value == (flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT | flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT) {
 		fmt.Printf("%s │ \x1b[93mFlags\x1b[0m               : \x1b[96m0x%08x\x1b[0m (\x1b[94m%s\x1b[0m)\n", indentPrompt, aco.Flags.Value, aco.Flags.Name)
-		fmt.Printf("%s │ \x1b[93mObjectType\x1b[0m          : \x1b[96m%s\x1b[0m (\x1b[94m%s\x1b[0m)\n", indentPrompt, aco.ObjectType.GUID.ToFormatD(), aco.ObjectType.GUID.LookupName())
-		fmt.Printf("%s │ \x1b[93mInheritedObjectType\x1b[0m : \x1b[96m%s\x1b[0m (\x1b[94m%s\x1b[0m)\n", indentPrompt, aco.InheritedObjectType.GUID.ToFormatD(), aco.InheritedObjectType.GUID.LookupName())
+		fmt.Printf("%s │ \x1b[93mObjectType\x1b[0m          : \x1b[96m%s\x1b[0m (\x1b[94m%s\x1b[0m)\n", indentPrompt, aco.ObjectType.GUID.ToFormatD(), objectTypeName)
+		fmt.Printf("%s │ \x1b[93mInheritedObjectType\x1b[0m : \x1b[96m%s\x1b[0m (\x1b[94m%s\x1b[0m)\n", indentPrompt, aco.InheritedObjectType.GUID.ToFormatD(), inheritedObjectTypeName)
 	} else if aco.Flags.Value == flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT {
 		fmt.Printf("%s │ \x1b[93mFlags\x1b[0m               : \x1b[96m0x%08x\x1b[0m (\x1b[94m%s\x1b[0m)\n", indentPrompt, aco.Flags.Value, aco.Flags.Name)
-		fmt.Printf("%s │ \x1b[93mInheritedObjectType\x1b[0m : \x1b[96m%s\x1b[0m (\x1b[94m%s\x1b[0m)\n", indentPrompt, aco.InheritedObjectType.GUID.ToFormatD(), aco.InheritedObjectType.GUID.LookupName())
+		fmt.Printf("%s │ \x1b[93mInheritedObjectType\x1b[0m : \x1b[96m%s\x1b[0m (\x1b[94m%s\x1b[0m)\n", indentPrompt, aco.InheritedObjectType.GUID.ToFormatD(), inheritedObjectTypeName)
 	} else if aco.Flags.Value == flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT {
 		fmt.Printf("%s │ \x1b[93mFlags\x1b[0m      : \x1b[96m0x%08x\x1b[0m (\x1b[94m%s\x1b[0m)\n", indentPrompt, aco.Flags.Value, aco.Flags.Name)
-		fmt.Printf("%s │ \x1b[93mObjectType\x1b[0m : \x1b[96m%s\x1b[0m (\x1b[94m%s\x1b[0m)\n", indentPrompt, aco.ObjectType.GUID.ToFormatD(), aco.ObjectType.GUID.LookupName())
+		fmt.Printf("%s │ \x1b[93mObjectType\x1b[0m : \x1b[96m%s\x1b[0m (\x1b[94m%s\x1b[0m)\n", indentPrompt, aco.ObjectType.GUID.ToFormatD(), objectTypeName)
 	} else if aco.Flags.Value == flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_NONE {
 		fmt.Printf("%s │ \x1b[93mFlags\x1b[0m : \x1b[96m0x%08x\x1b[0m (\x1b[94m%s\x1b[0m)\n", indentPrompt, aco.Flags.Value, aco.Flags.Name)
 	} else {
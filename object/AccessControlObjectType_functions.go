@@ -1,5 +1,7 @@
 package object
 
+import "bytes"
+
 // Equal checks if two AccessControlObjectType objects are equal by comparing all their fields.
 //
 // Parameters:
@@ -33,3 +35,18 @@ func (aco *AccessControlObjectType) Equal(other *AccessControlObjectType) bool {
 
 	return true
 }
+
+// Clone returns a deep copy of the AccessControlObjectType, sharing no memory with the original.
+//
+// Returns:
+//   - *AccessControlObjectType: The copy of the AccessControlObjectType, nil if it is nil.
+func (aco *AccessControlObjectType) Clone() *AccessControlObjectType {
+	if aco == nil {
+		return nil
+	}
+	clone := *aco
+	clone.ObjectType = *aco.ObjectType.Clone()
+	clone.InheritedObjectType = *aco.InheritedObjectType.Clone()
+	clone.RawBytes = bytes.Clone(aco.RawBytes)
+	return &clone
+}
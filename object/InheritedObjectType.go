@@ -34,7 +34,7 @@ type InheritedObjectType struct {
 // specifically setting the RawBytes and RawBytesSize, and parsing the GUID
 // from the provided raw data.
 func (inheritedObjType *InheritedObjectType) Unmarshal(rawBytes []byte) (int, error) {
-	inheritedObjType.RawBytes = rawBytes
+	inheritedObjType.RawBytes = nil
 	inheritedObjType.RawBytesSize = 0
 
 	rawBytesSize, err := inheritedObjType.GUID.Unmarshal(rawBytes)
@@ -42,6 +42,7 @@ func (inheritedObjType *InheritedObjectType) Unmarshal(rawBytes []byte) (int, er
 		return 0, err
 	}
 	inheritedObjType.RawBytesSize += uint32(rawBytesSize)
+	inheritedObjType.RawBytes = rawBytes[:inheritedObjType.RawBytesSize]
 
 	return int(inheritedObjType.RawBytesSize), nil
 }
@@ -49,12 +50,46 @@ func (inheritedObjType *InheritedObjectType) Unmarshal(rawBytes []byte) (int, er
 // Marshal returns the raw byte representation of the InheritedObjectType.
 // It returns the GUID as a byte slice.
 func (inheritedObjType *InheritedObjectType) Marshal() ([]byte, error) {
-	bytesStream, err := inheritedObjType.GUID.Marshal()
+	marshalledData, err := inheritedObjType.AppendBinary(make([]byte, 0, inheritedObjType.Size()))
 	if err != nil {
 		return nil, err
 	}
-	inheritedObjType.RawBytesSize = uint32(len(bytesStream))
-	return bytesStream, nil
+	inheritedObjType.RefreshRawBytes(marshalledData)
+	return marshalledData, nil
+}
+
+// RefreshRawBytes points RawBytes and RawBytesSize at the 16 marshalled bytes of the GUID.
+//
+// Parameters:
+//   - marshalledData ([]byte): The bytes produced by AppendBinary for the InheritedObjectType.
+func (inheritedObjType *InheritedObjectType) RefreshRawBytes(marshalledData []byte) {
+	inheritedObjType.RawBytesSize = uint32(inheritedObjType.Size())
+	inheritedObjType.RawBytes = marshalledData[:inheritedObjType.RawBytesSize]
+}
+
+// Size returns the size of the binary representation of the InheritedObjectType.
+//
+// Returns:
+//   - int: Always 16, the size of the GUID.
+func (inheritedObjType *InheritedObjectType) Size() int {
+	return inheritedObjType.GUID.Size()
+}
+
+// AppendBinary appends the binary representation of the InheritedObjectType to a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: An error if the GUID cannot be serialized.
+func (inheritedObjType *InheritedObjectType) AppendBinary(buffer []byte) ([]byte, error) {
+	buffer, err := inheritedObjType.GUID.AppendBinary(buffer)
+	if err != nil {
+		return nil, err
+	}
+	inheritedObjType.RawBytesSize = uint32(inheritedObjType.GUID.Size())
+	return buffer, nil
 }
 
 // Describe prints a formatted representation of the InheritedObjectType instance,
@@ -1,6 +1,10 @@
 package object
 
-import "github.com/TheManticoreProject/winacl/guid"
+import (
+	"bytes"
+
+	"github.com/TheManticoreProject/winacl/guid"
+)
 
 // Equal returns true if the InheritedObjectType is equal to the other InheritedObjectType.
 func (inheritedObjType *InheritedObjectType) Equal(other *InheritedObjectType) bool {
@@ -26,3 +30,13 @@ func (inheritedObjType *InheritedObjectType) GetGUID() guid.GUID {
 func (inheritedObjType *InheritedObjectType) SetGUID(guid guid.GUID) {
 	inheritedObjType.GUID = guid
 }
+
+// Clone returns a deep copy of the InheritedObjectType, sharing no memory with the original.
+func (inheritedObjType *InheritedObjectType) Clone() *InheritedObjectType {
+	if inheritedObjType == nil {
+		return nil
+	}
+	clone := *inheritedObjType
+	clone.RawBytes = bytes.Clone(inheritedObjType.RawBytes)
+	return &clone
+}
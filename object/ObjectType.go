@@ -29,7 +29,7 @@ type ObjectType struct {
 // It expects the RawBytes to be at least 16 bytes long, as that is the size needed to store the GUID.
 // It also sets the RawBytes and RawBytesSize fields.
 func (objType *ObjectType) Unmarshal(rawBytes []byte) (int, error) {
-	objType.RawBytes = rawBytes
+	objType.RawBytes = nil
 	objType.RawBytesSize = 0
 
 	rawBytesSize, err := objType.GUID.Unmarshal(rawBytes)
@@ -37,6 +37,7 @@ func (objType *ObjectType) Unmarshal(rawBytes []byte) (int, error) {
 		return 0, err
 	}
 	objType.RawBytesSize += uint32(rawBytesSize)
+	objType.RawBytes = rawBytes[:objType.RawBytesSize]
 
 	return int(objType.RawBytesSize), nil
 }
@@ -44,12 +45,46 @@ func (objType *ObjectType) Unmarshal(rawBytes []byte) (int, error) {
 // Marshal returns the raw byte representation of the ObjectType.
 // It returns the GUID as a byte slice.
 func (objType *ObjectType) Marshal() ([]byte, error) {
-	bytesStream, err := objType.GUID.Marshal()
+	marshalledData, err := objType.AppendBinary(make([]byte, 0, objType.Size()))
 	if err != nil {
 		return nil, err
 	}
-	objType.RawBytesSize = uint32(len(bytesStream))
-	return bytesStream, nil
+	objType.RefreshRawBytes(marshalledData)
+	return marshalledData, nil
+}
+
+// RefreshRawBytes points RawBytes and RawBytesSize at the 16 marshalled bytes of the GUID.
+//
+// Parameters:
+//   - marshalledData ([]byte): The bytes produced by AppendBinary for the ObjectType.
+func (objType *ObjectType) RefreshRawBytes(marshalledData []byte) {
+	objType.RawBytesSize = uint32(objType.Size())
+	objType.RawBytes = marshalledData[:objType.RawBytesSize]
+}
+
+// Size returns the size of the binary representation of the ObjectType.
+//
+// Returns:
+//   - int: Always 16, the size of the GUID.
+func (objType *ObjectType) Size() int {
+	return objType.GUID.Size()
+}
+
+// AppendBinary appends the binary representation of the ObjectType to a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: An error if the GUID cannot be serialized.
+func (objType *ObjectType) AppendBinary(buffer []byte) ([]byte, error) {
+	buffer, err := objType.GUID.AppendBinary(buffer)
+	if err != nil {
+		return nil, err
+	}
+	objType.RawBytesSize = uint32(objType.GUID.Size())
+	return buffer, nil
 }
 
 // Describe prints a formatted representation of the ObjectType instance,
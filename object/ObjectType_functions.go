@@ -1,6 +1,10 @@
 package object
 
-import "github.com/TheManticoreProject/winacl/guid"
+import (
+	"bytes"
+
+	"github.com/TheManticoreProject/winacl/guid"
+)
 
 // Equal returns true if the ObjectType is equal to the other ObjectType.
 func (objType *ObjectType) Equal(other *ObjectType) bool {
@@ -26,3 +30,13 @@ func (objType *ObjectType) GetGUID() guid.GUID {
 func (objType *ObjectType) SetGUID(guid guid.GUID) {
 	objType.GUID = guid
 }
+
+// Clone returns a deep copy of the ObjectType, sharing no memory with the original.
+func (objType *ObjectType) Clone() *ObjectType {
+	if objType == nil {
+		return nil
+	}
+	clone := *objType
+	clone.RawBytes = bytes.Clone(objType.RawBytes)
+	return &clone
+}
@@ -2,7 +2,8 @@ package flags
 
 import (
 	"encoding/binary"
-	"fmt"
+
+	"github.com/TheManticoreProject/winacl/parsing"
 )
 
 // A set of bit flags that indicate whether the ObjectType and InheritedObjectType members are present. This parameter can be one or more of the following values.
@@ -28,7 +29,7 @@ type AccessControlObjectTypeFlags struct {
 //   - RawBytes ([]byte): The byte slice representing the AccessControlObjectTypeFlags.
 func (acotype *AccessControlObjectTypeFlags) Unmarshal(rawBytes []byte) (int, error) {
 	if len(rawBytes) < 4 {
-		return 0, fmt.Errorf("AccessControlObjectTypeFlags unmarshal requires at least 4 bytes, got %d", len(rawBytes))
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "AccessControlObjectTypeFlags unmarshal requires at least 4 bytes, got %d", len(rawBytes))
 	}
 	acotype.Value = binary.LittleEndian.Uint32(rawBytes[0:4])
 	return 4, nil
@@ -39,11 +40,27 @@ func (acotype *AccessControlObjectTypeFlags) Unmarshal(rawBytes []byte) (int, er
 // Returns:
 //   - []byte: The serialized byte slice representing the AccessControlObjectTypeFlags.
 func (acotype *AccessControlObjectTypeFlags) Marshal() ([]byte, error) {
-	marshalledData := make([]byte, 4)
+	return acotype.AppendBinary(make([]byte, 0, acotype.Size()))
+}
 
-	binary.LittleEndian.PutUint32(marshalledData, acotype.Value)
+// Size returns the size of the binary representation of the AccessControlObjectTypeFlags.
+//
+// Returns:
+//   - int: Always 4.
+func (acotype *AccessControlObjectTypeFlags) Size() int {
+	return 4
+}
 
-	return marshalledData, nil
+// AppendBinary appends the binary representation of the AccessControlObjectTypeFlags to a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: Always nil.
+func (acotype *AccessControlObjectTypeFlags) AppendBinary(buffer []byte) ([]byte, error) {
+	return binary.LittleEndian.AppendUint32(buffer, acotype.Value), nil
 }
 
 // String returns a string representation of the AccessControlObjectTypeFlags.
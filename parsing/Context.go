@@ -0,0 +1,196 @@
+package parsing
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Options controls how binary structures are parsed.
+//
+// Attributes:
+//   - Lenient (bool): When set, the parsers recover from corrupt data instead of failing on the
+//     first error. Each recovery is recorded as an Anomaly: offsets pointing past the end of the
+//     buffer drop the component, AceCount values larger than the ACEs present are lowered, and
+//     ACEs whose body cannot be parsed are kept as Opaque entries.
+//   - Detach (bool): When set, the input is copied before parsing, so that the RawBytes and
+//     ApplicationData fields of the parsed structures do not alias the buffer of the caller,
+//     which can then be reused or modified. By default, they reference the input buffer.
+type Options struct {
+	Lenient bool
+	Detach  bool
+}
+
+// AnomalyCode identifies the kind of corruption recovered from by a lenient parser.
+type AnomalyCode string
+
+const (
+	// ANOMALY_CODE_OFFSET_OUT_OF_RANGE reports a component offset pointing outside of the buffer.
+	ANOMALY_CODE_OFFSET_OUT_OF_RANGE AnomalyCode = "OFFSET_OUT_OF_RANGE"
+	// ANOMALY_CODE_COMPONENT_INVALID reports a component that could not be parsed and was dropped.
+	ANOMALY_CODE_COMPONENT_INVALID AnomalyCode = "COMPONENT_INVALID"
+	// ANOMALY_CODE_ACL_SIZE_INVALID reports an AclSize that does not match the available data.
+	ANOMALY_CODE_ACL_SIZE_INVALID AnomalyCode = "ACL_SIZE_INVALID"
+	// ANOMALY_CODE_ACE_COUNT_MISMATCH reports an AceCount larger than the number of ACEs present.
+	ANOMALY_CODE_ACE_COUNT_MISMATCH AnomalyCode = "ACE_COUNT_MISMATCH"
+	// ANOMALY_CODE_ACE_BODY_OPAQUE reports an ACE whose body could not be parsed and is kept opaque.
+	ANOMALY_CODE_ACE_BODY_OPAQUE AnomalyCode = "ACE_BODY_OPAQUE"
+	// ANOMALY_CODE_ACE_TRAILING_SLACK reports trailing bytes after the fields of a fixed layout ACE.
+	ANOMALY_CODE_ACE_TRAILING_SLACK AnomalyCode = "ACE_TRAILING_SLACK"
+	// ANOMALY_CODE_REVISION_INVALID reports a revision that is not supported by the structure.
+	ANOMALY_CODE_REVISION_INVALID AnomalyCode = "REVISION_INVALID"
+)
+
+// Anomaly is a corruption that a lenient parser recovered from.
+//
+// Attributes:
+//   - Offset (int): The absolute byte offset of the anomaly in the parsed buffer.
+//   - Path (string): The component holding the anomaly, like "DACL.Entries[3]".
+//   - Code (AnomalyCode): The kind of the anomaly.
+//   - Message (string): A human readable description of the anomaly and of the recovery.
+type Anomaly struct {
+	Offset  int
+	Path    string
+	Code    AnomalyCode
+	Message string
+}
+
+// String returns a one-line description of the anomaly.
+//
+// Returns:
+//   - string: The offset, path, code and message of the anomaly.
+func (anomaly Anomaly) String() string {
+	return fmt.Sprintf("0x%04x %s %s: %s", anomaly.Offset, anomaly.Path, anomaly.Code, anomaly.Message)
+}
+
+// Anomalies is a list of anomalies, in parsing order.
+type Anomalies []Anomaly
+
+// String returns the anomalies, one per line.
+//
+// Returns:
+//   - string: The description of the anomalies.
+func (anomalies Anomalies) String() string {
+	lines := make([]string, 0, len(anomalies))
+	for _, anomaly := range anomalies {
+		lines = append(lines, anomaly.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Context carries the parse options and the location of the structure being parsed through
+// nested parsers, and collects the anomalies they record.
+//
+// Attributes:
+//   - Options (Options): The parse options.
+//   - Offset (int): The absolute offset of the structure being parsed in the original buffer.
+//   - Path (string): The path of the structure being parsed, like "DACL.Entries[3]".
+type Context struct {
+	Options Options
+	Offset  int
+	Path    string
+
+	// Internal
+	anomalies *Anomalies
+	detached  bool
+}
+
+// NewContext creates the context of a top-level structure, located at offset 0.
+//
+// Parameters:
+//   - options (Options): The parse options.
+//
+// Returns:
+//   - *Context: The new context.
+func NewContext(options Options) *Context {
+	return &Context{Options: options, anomalies: &Anomalies{}}
+}
+
+// Lenient checks if the parsers must recover from corrupt data.
+//
+// Returns:
+//   - bool: true in lenient mode, false otherwise.
+func (ctx *Context) Lenient() bool {
+	return ctx.Options.Lenient
+}
+
+// Child creates the context of a nested structure. The anomalies recorded in the child
+// context are collected by the parent context.
+//
+// Parameters:
+//   - offset (int): The offset of the nested structure, relative to the current structure.
+//   - path (string): The path of the nested structure, relative to the current structure.
+//
+// Returns:
+//   - *Context: The context of the nested structure.
+func (ctx *Context) Child(offset int, path string) *Context {
+	return &Context{
+		Options:   ctx.Options,
+		Offset:    ctx.Offset + offset,
+		Path:      JoinPath(ctx.Path, path),
+		anomalies: ctx.anomalies,
+		detached:  ctx.detached,
+	}
+}
+
+// Detach returns the data that the parsed structures may reference. With the Detach option,
+// the data is copied the first time, and the nested structures parsed in child contexts
+// reference that copy instead of copying their own part again.
+//
+// Parameters:
+//   - data ([]byte): The data of the current structure.
+//
+// Returns:
+//   - []byte: A copy of the data if it must be detached from the input, the data otherwise.
+func (ctx *Context) Detach(data []byte) []byte {
+	if !ctx.Options.Detach || ctx.detached {
+		return data
+	}
+	ctx.detached = true
+	return bytes.Clone(data)
+}
+
+// Record records an anomaly located in the current structure.
+//
+// Parameters:
+//   - offset (int): The offset of the anomaly, relative to the current structure.
+//   - code (AnomalyCode): The kind of the anomaly.
+//   - format (string): The format of the message, followed by its arguments.
+func (ctx *Context) Record(offset int, code AnomalyCode, format string, args ...any) {
+	*ctx.anomalies = append(*ctx.anomalies, Anomaly{
+		Offset:  ctx.Offset + offset,
+		Path:    ctx.Path,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Anomalies returns the anomalies recorded by the context and all its children.
+//
+// Returns:
+//   - Anomalies: The anomalies, in parsing order.
+func (ctx *Context) Anomalies() Anomalies {
+	return *ctx.anomalies
+}
+
+// JoinPath joins two paths of a structure with a dot, ignoring empty parts. A path starting
+// with "[" is an index and is appended without a dot.
+//
+// Parameters:
+//   - prefix (string): The path of the parent structure.
+//   - path (string): The path relative to the parent structure.
+//
+// Returns:
+//   - string: The joined path.
+func JoinPath(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	if path == "" {
+		return prefix
+	}
+	if strings.HasPrefix(path, "[") {
+		return prefix + path
+	}
+	return prefix + "." + path
+}
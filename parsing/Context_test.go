@@ -0,0 +1,75 @@
+package parsing_test
+
+import (
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/parsing"
+)
+
+func TestContext(t *testing.T) {
+	ctx := parsing.NewContext(parsing.Options{Lenient: true})
+	if !ctx.Lenient() {
+		t.Errorf("Lenient() = false")
+	}
+
+	dacl := ctx.Child(0x30, "DACL")
+	entry := dacl.Child(0x08, "Entries[2]")
+	if entry.Offset != 0x38 || entry.Path != "DACL.Entries[2]" {
+		t.Errorf("Child() = %d %q, want 56 DACL.Entries[2]", entry.Offset, entry.Path)
+	}
+
+	dacl.Record(2, parsing.ANOMALY_CODE_ACL_SIZE_INVALID, "AclSize %d", 12)
+	entry.Record(4, parsing.ANOMALY_CODE_ACE_BODY_OPAQUE, "opaque")
+
+	anomalies := ctx.Anomalies()
+	if len(anomalies) != 2 {
+		t.Fatalf("Anomalies() = %v, want 2 anomalies", anomalies)
+	}
+	if anomalies[0].Offset != 0x32 || anomalies[0].Path != "DACL" || anomalies[0].Message != "AclSize 12" {
+		t.Errorf("Anomalies()[0] = %+v", anomalies[0])
+	}
+	if got := anomalies[1].String(); got != "0x003c DACL.Entries[2] ACE_BODY_OPAQUE: opaque" {
+		t.Errorf("Anomalies()[1].String() = %q", got)
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		path     string
+		expected string
+	}{
+		{"", "", ""},
+		{"SACL", "", "SACL"},
+		{"", "Owner", "Owner"},
+		{"SACL", "Entries[0]", "SACL.Entries[0]"},
+		{"SACL.Entries", "[0]", "SACL.Entries[0]"},
+	}
+
+	for _, tt := range tests {
+		if got := parsing.JoinPath(tt.prefix, tt.path); got != tt.expected {
+			t.Errorf("JoinPath(%q, %q) = %q, want %q", tt.prefix, tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestContext_Detach(t *testing.T) {
+	data := []byte{1, 2, 3, 4}
+
+	ctx := parsing.NewContext(parsing.Options{})
+	if detached := ctx.Detach(data); &detached[0] != &data[0] {
+		t.Errorf("Detach() copied the data without the Detach option")
+	}
+
+	ctx = parsing.NewContext(parsing.Options{Detach: true})
+	detached := ctx.Detach(data)
+	if &detached[0] == &data[0] || string(detached) != string(data) {
+		t.Fatalf("Detach() = %v, want a copy of %v", detached, data)
+	}
+
+	// Nested structures reference the copy of their parent
+	child := ctx.Child(2, "DACL")
+	if nested := child.Detach(detached[2:]); &nested[0] != &detached[2] {
+		t.Errorf("Detach() copied the data of a child context again")
+	}
+}
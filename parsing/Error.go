@@ -0,0 +1,174 @@
+package parsing
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCategory classifies the parse errors.
+type ErrorCategory uint8
+
+const (
+	// ERROR_CATEGORY_INVALID is the category of the errors that have no more specific category.
+	ERROR_CATEGORY_INVALID ErrorCategory = iota
+	// ERROR_CATEGORY_TRUNCATED is the category of the errors raised when the data ends before
+	// the end of a structure.
+	ERROR_CATEGORY_TRUNCATED
+	// ERROR_CATEGORY_OUT_OF_RANGE is the category of the errors raised when an offset or a size
+	// points outside of the data or of its enclosing structure.
+	ERROR_CATEGORY_OUT_OF_RANGE
+	// ERROR_CATEGORY_INVALID_REVISION is the category of the errors raised when a structure has
+	// an unsupported revision.
+	ERROR_CATEGORY_INVALID_REVISION
+	// ERROR_CATEGORY_UNKNOWN_TYPE is the category of the errors raised when an ACE has an
+	// unknown type.
+	ERROR_CATEGORY_UNKNOWN_TYPE
+)
+
+// The sentinel errors of the categories, to be used with errors.Is.
+var (
+	ErrInvalid         = errors.New("invalid data")
+	ErrTruncated       = errors.New("truncated data")
+	ErrOutOfRange      = errors.New("out of range")
+	ErrInvalidRevision = errors.New("invalid revision")
+	ErrUnknownType     = errors.New("unknown type")
+)
+
+// ErrorCategorySentinels maps the categories to their sentinel errors.
+var ErrorCategorySentinels = map[ErrorCategory]error{
+	ERROR_CATEGORY_INVALID:          ErrInvalid,
+	ERROR_CATEGORY_TRUNCATED:        ErrTruncated,
+	ERROR_CATEGORY_OUT_OF_RANGE:     ErrOutOfRange,
+	ERROR_CATEGORY_INVALID_REVISION: ErrInvalidRevision,
+	ERROR_CATEGORY_UNKNOWN_TYPE:     ErrUnknownType,
+}
+
+// ErrorCategoryNames maps the categories to their names.
+var ErrorCategoryNames = map[ErrorCategory]string{
+	ERROR_CATEGORY_INVALID:          "INVALID",
+	ERROR_CATEGORY_TRUNCATED:        "TRUNCATED",
+	ERROR_CATEGORY_OUT_OF_RANGE:     "OUT_OF_RANGE",
+	ERROR_CATEGORY_INVALID_REVISION: "INVALID_REVISION",
+	ERROR_CATEGORY_UNKNOWN_TYPE:     "UNKNOWN_TYPE",
+}
+
+// String returns the name of the category.
+//
+// Returns:
+//   - string: The name of the category, or "?" if it is unknown.
+func (category ErrorCategory) String() string {
+	if name, exists := ErrorCategoryNames[category]; exists {
+		return name
+	}
+	return "?"
+}
+
+// ParseError is an error raised while parsing a binary structure. It is located by the path
+// of the component that failed and by its byte offset, and classified by a category. The
+// parsers wrap the errors of the nested structures in a new ParseError at each level, so
+// that the outermost ParseError, returned by errors.As, holds the full path and the offset
+// relative to the start of the outermost structure, e.g. of the security descriptor.
+//
+// Attributes:
+//   - Category (ErrorCategory): The category of the error.
+//   - Path (string): The path of the component that failed, like "DACL.Entries[3].Identity".
+//   - Offset (int): The byte offset of the failure.
+//   - Err (error): The underlying error, holding the message.
+type ParseError struct {
+	Category ErrorCategory
+	Path     string
+	Offset   int
+	Err      error
+}
+
+// NewParseError creates the error of a structure that failed to parse.
+//
+// Parameters:
+//   - category (ErrorCategory): The category of the error.
+//   - offset (int): The byte offset of the failure, relative to the structure.
+//   - path (string): The path of the failing field, relative to the structure, or "".
+//   - format (string): The format of the message, followed by its arguments.
+//
+// Returns:
+//   - *ParseError: The new error.
+func NewParseError(category ErrorCategory, offset int, path string, format string, args ...any) *ParseError {
+	return &ParseError{
+		Category: category,
+		Path:     path,
+		Offset:   offset,
+		Err:      fmt.Errorf(format, args...),
+	}
+}
+
+// WrapParseError wraps the error of a nested structure, locating it in the enclosing
+// structure. The category of a wrapped ParseError is kept, other errors are wrapped with
+// the ERROR_CATEGORY_INVALID category.
+//
+// Parameters:
+//   - err (error): The error of the nested structure.
+//   - offset (int): The offset of the nested structure, relative to the enclosing structure.
+//   - path (string): The path of the nested structure, relative to the enclosing structure.
+//   - format (string): The format of the message prefix, followed by its arguments. An empty
+//     format keeps the message of err unchanged.
+//
+// Returns:
+//   - *ParseError: The wrapping error, whose message is the prefix followed by the message of err.
+func WrapParseError(err error, offset int, path string, format string, args ...any) *ParseError {
+	wrapped := &ParseError{
+		Category: ERROR_CATEGORY_INVALID,
+		Path:     path,
+		Offset:   offset,
+		Err:      err,
+	}
+	if format != "" {
+		wrapped.Err = fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), err)
+	}
+
+	var nested *ParseError
+	if errors.As(err, &nested) {
+		wrapped.Category = nested.Category
+		wrapped.Path = JoinPath(path, nested.Path)
+		wrapped.Offset = offset + nested.Offset
+	}
+
+	return wrapped
+}
+
+// Error returns the message of the error.
+//
+// Returns:
+//   - string: The message of the error.
+func (parseError *ParseError) Error() string {
+	return parseError.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+//
+// Returns:
+//   - error: The underlying error.
+func (parseError *ParseError) Unwrap() error {
+	return parseError.Err
+}
+
+// Is checks if the target is the sentinel error of the category of the error, so that
+// errors.Is(err, parsing.ErrTruncated) matches the truncated data errors.
+//
+// Parameters:
+//   - target (error): The error to compare with.
+//
+// Returns:
+//   - bool: true if target is the sentinel error of the category, false otherwise.
+func (parseError *ParseError) Is(target error) bool {
+	return ErrorCategorySentinels[parseError.Category] == target
+}
+
+// Location returns a one-line description of the location and category of the error.
+//
+// Returns:
+//   - string: The category, path and offset of the error.
+func (parseError *ParseError) Location() string {
+	if parseError.Path == "" {
+		return fmt.Sprintf("%s at offset 0x%04x", parseError.Category, parseError.Offset)
+	}
+	return fmt.Sprintf("%s in %s at offset 0x%04x", parseError.Category, parseError.Path, parseError.Offset)
+}
@@ -0,0 +1,64 @@
+package parsing_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/parsing"
+)
+
+func TestParseError(t *testing.T) {
+	leaf := parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 12, "", "not enough data to parse SubAuthority %d", 1)
+	identity := parsing.WrapParseError(leaf, 0, "SID", "")
+	entry := parsing.WrapParseError(identity, 8, "Identity", "failed to unmarshal Identity")
+	dacl := parsing.WrapParseError(entry, 28, "Entries[1]", "failed to unmarshal ACE %d/%d within AclSize", 2, 2)
+	err := fmt.Errorf("outer: %w", parsing.WrapParseError(dacl, 0x30, "DACL", "failed to unmarshal DACL"))
+
+	var parseError *parsing.ParseError
+	if !errors.As(err, &parseError) {
+		t.Fatalf("errors.As() did not find a ParseError in %v", err)
+	}
+	if parseError.Path != "DACL.Entries[1].Identity.SID" {
+		t.Errorf("Path = %q", parseError.Path)
+	}
+	if parseError.Offset != 0x30+28+8+12 {
+		t.Errorf("Offset = %d, want %d", parseError.Offset, 0x30+28+8+12)
+	}
+	if parseError.Category != parsing.ERROR_CATEGORY_TRUNCATED {
+		t.Errorf("Category = %s", parseError.Category)
+	}
+	if !errors.Is(err, parsing.ErrTruncated) || errors.Is(err, parsing.ErrOutOfRange) {
+		t.Errorf("errors.Is() does not match the category of %v", err)
+	}
+	expected := "failed to unmarshal DACL: failed to unmarshal ACE 2/2 within AclSize: failed to unmarshal Identity: not enough data to parse SubAuthority 1"
+	if parseError.Error() != expected {
+		t.Errorf("Error() = %q, want %q", parseError.Error(), expected)
+	}
+	if got := parseError.Location(); got != "TRUNCATED in DACL.Entries[1].Identity.SID at offset 0x0060" {
+		t.Errorf("Location() = %q", got)
+	}
+}
+
+func TestWrapParseError_ForeignError(t *testing.T) {
+	inner := errors.New("boom")
+	err := parsing.WrapParseError(inner, 4, "Owner", "")
+	if err.Category != parsing.ERROR_CATEGORY_INVALID || err.Path != "Owner" || err.Offset != 4 {
+		t.Errorf("WrapParseError() = %+v", err)
+	}
+	if !errors.Is(err, inner) || !errors.Is(err, parsing.ErrInvalid) {
+		t.Errorf("errors.Is() does not match the wrapped error")
+	}
+	if err.Error() != "boom" {
+		t.Errorf("Error() = %q, want boom", err.Error())
+	}
+}
+
+func TestErrorCategory_String(t *testing.T) {
+	if got := parsing.ERROR_CATEGORY_UNKNOWN_TYPE.String(); got != "UNKNOWN_TYPE" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := parsing.ErrorCategory(0xff).String(); got != "?" {
+		t.Errorf("String() = %q", got)
+	}
+}
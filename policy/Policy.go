@@ -0,0 +1,280 @@
+// Package policy compiles declarative descriptions of security descriptors, written in JSON or
+// YAML, into NtSecurityDescriptor structures. See Policy for the format.
+//
+// The YAML documents are parsed without external dependency, so only the subset of YAML used by
+// configuration files is supported:
+//   - block mappings, one "key: value" entry per line, with plain or quoted keys;
+//   - block sequences of "- " items, including mappings starting on the line of their "-";
+//   - flow sequences of scalars written on one line, like "[RP, WP]";
+//   - plain, single-quoted and double-quoted scalars written on one line, true, false and null
+//     being the only values that are not strings;
+//   - comments, and the "---" and "..." markers of a single document.
+//
+// The other constructs are rejected with an error naming them: anchors, aliases, tags, flow
+// mappings, block scalars ("|" and ">"), multi-line scalars, complex mapping keys ("?"),
+// directives and multiple documents. Use JSON for the documents needing them.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/TheManticoreProject/winacl/ace/aceflags"
+	"github.com/TheManticoreProject/winacl/builder"
+	"github.com/TheManticoreProject/winacl/rights"
+	"github.com/TheManticoreProject/winacl/schema"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+)
+
+// StringList is a list of names which can be written as a single string or as a list of strings,
+// like "rights: GA" or "rights: [RP, WP]".
+type StringList []string
+
+// UnmarshalJSON decodes a string or a list of strings.
+//
+// Parameters:
+//   - data ([]byte): The JSON string or array of strings.
+//
+// Returns:
+//   - error: An error if the value is neither a string nor a list of strings.
+func (list *StringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*list = StringList{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("expected a string or a list of strings, got %s", data)
+	}
+	*list = multiple
+	return nil
+}
+
+// Entry is an ACE of a policy.
+//
+// Attributes:
+//   - Type (string): "allow" or "deny" in the DACL, "audit" in the SACL.
+//   - Trustee (string): The SDDL alias, SID string or well-known name of the trustee.
+//   - Rights (StringList): The rights, see builder.ResolveRights.
+//   - ObjectType (string): The name or GUID of the object type, see builder.ResolveObjectType.
+//   - InheritedObjectType (string): The name or GUID of the class of the child objects
+//     inheriting the ACE.
+//   - Inherit (StringList): The inheritance flags, see builder.ResolveInheritanceFlags.
+//   - Audit (StringList): The accesses audited by an audit entry, "success" and/or "failure".
+type Entry struct {
+	Type                string     `json:"type"`
+	Trustee             string     `json:"trustee"`
+	Rights              StringList `json:"rights"`
+	ObjectType          string     `json:"objectType,omitempty"`
+	InheritedObjectType string     `json:"inheritedObjectType,omitempty"`
+	Inherit             StringList `json:"inherit,omitempty"`
+	Audit               StringList `json:"audit,omitempty"`
+}
+
+// Policy is the declarative description of a security descriptor, written in JSON or YAML and
+// compiled into an NtSecurityDescriptor:
+//
+//	owner: BA
+//	group: SY
+//	protectDacl: true
+//	dacl:
+//	  - type: allow
+//	    trustee: SY
+//	    rights: GA
+//	  - type: allow
+//	    trustee: S-1-5-21-1-2-3-1105
+//	    rights: [RP, WP]
+//	    objectType: Personal-Information
+//	    inheritedObjectType: user
+//	    inherit: CI
+//	sacl:
+//	  - type: audit
+//	    trustee: WD
+//	    rights: [WD, WO]
+//	    audit: [success, failure]
+//
+// Attributes:
+//   - Owner (string): The owner, none if empty.
+//   - Group (string): The primary group, none if empty.
+//   - Namespace (string): The rights namespace of the right names (e.g. "FILE"), the directory
+//     service namespace if empty.
+//   - ProtectDACL (bool): Whether the inheritance of ACEs into the DACL is blocked.
+//   - ProtectSACL (bool): Whether the inheritance of ACEs into the SACL is blocked.
+//   - DACL ([]Entry): The allow and deny entries.
+//   - SACL ([]Entry): The audit entries.
+type Policy struct {
+	Owner       string  `json:"owner,omitempty"`
+	Group       string  `json:"group,omitempty"`
+	Namespace   string  `json:"namespace,omitempty"`
+	ProtectDACL bool    `json:"protectDacl,omitempty"`
+	ProtectSACL bool    `json:"protectSacl,omitempty"`
+	DACL        []Entry `json:"dacl,omitempty"`
+	SACL        []Entry `json:"sacl,omitempty"`
+}
+
+// ParseJSON parses a policy written in JSON. Unknown fields are rejected, so that misspelled
+// keys are not silently ignored.
+//
+// Parameters:
+//   - data ([]byte): The JSON document.
+//
+// Returns:
+//   - *Policy: The parsed policy.
+//   - error: An error if the document is not a valid policy.
+func ParseJSON(data []byte) (*Policy, error) {
+	decoder := json.NewDecoder(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	decoder.DisallowUnknownFields()
+	policy := &Policy{}
+	if err := decoder.Decode(policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("failed to parse policy: unexpected data after the policy")
+	}
+	return policy, nil
+}
+
+// ParseYAML parses a policy written in YAML. Only the block style subset of YAML used by
+// configuration files is supported, see the package documentation.
+//
+// Parameters:
+//   - data ([]byte): The YAML document.
+//
+// Returns:
+//   - *Policy: The parsed policy.
+//   - error: An error if the document is not a valid policy.
+func ParseYAML(data []byte) (*Policy, error) {
+	document, err := parseYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	if document == nil {
+		document = map[string]any{}
+	}
+	jsonData, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	return ParseJSON(jsonData)
+}
+
+// Builder returns a builder holding the policy, to which more entries can be added.
+//
+// Parameters:
+//   - registry (*schema.Registry): The registry used to resolve the object types, the default
+//     registry if nil.
+//
+// Returns:
+//   - *builder.Builder: The builder, with the errors of the invalid entries, see builder.Builder.Err.
+func (policy *Policy) Builder(registry *schema.Registry) *builder.Builder {
+	b := builder.NewBuilder().WithRegistry(registry)
+
+	if policy.Namespace != "" {
+		namespace, found := rights.LookupRightsNamespace(policy.Namespace)
+		if !found {
+			return b.Fail(fmt.Errorf("namespace: unknown rights namespace %q", policy.Namespace))
+		}
+		b.WithNamespace(namespace)
+	}
+	if policy.Owner != "" {
+		b.Owner(policy.Owner)
+	}
+	if policy.Group != "" {
+		b.Group(policy.Group)
+	}
+	if policy.ProtectDACL {
+		b.ProtectDACL()
+	}
+	if policy.ProtectSACL {
+		b.ProtectSACL()
+	}
+
+	for index, entry := range policy.DACL {
+		switch strings.ToLower(entry.Type) {
+		case "allow":
+			b.Allow(entry.Trustee, entry.Rights...)
+		case "deny":
+			b.Deny(entry.Trustee, entry.Rights...)
+		default:
+			b.Fail(fmt.Errorf("dacl[%d]: invalid type %q, expected allow or deny", index, entry.Type))
+			continue
+		}
+		if len(entry.Audit) != 0 {
+			b.Fail(fmt.Errorf("dacl[%d]: audit is only valid in the SACL", index))
+		}
+		applyEntryOptions(b, entry)
+	}
+
+	for index, entry := range policy.SACL {
+		if strings.ToLower(entry.Type) != "audit" {
+			b.Fail(fmt.Errorf("sacl[%d]: invalid type %q, expected audit", index, entry.Type))
+			continue
+		}
+		auditFlags, err := resolveAuditFlags(entry.Audit)
+		if err != nil {
+			b.Fail(fmt.Errorf("sacl[%d]: %w", index, err))
+			continue
+		}
+		b.Audit(entry.Trustee, auditFlags, entry.Rights...)
+		applyEntryOptions(b, entry)
+	}
+
+	return b
+}
+
+// Compile compiles the policy into a security descriptor.
+//
+// Parameters:
+//   - registry (*schema.Registry): The registry used to resolve the object types, the default
+//     registry if nil.
+//
+// Returns:
+//   - *securitydescriptor.NtSecurityDescriptor: The security descriptor.
+//   - error: The problems found in the policy.
+func (policy *Policy) Compile(registry *schema.Registry) (*securitydescriptor.NtSecurityDescriptor, error) {
+	return policy.Builder(registry).Build()
+}
+
+// applyEntryOptions applies the object types and inheritance flags of a policy entry to the ACE
+// last added to the builder.
+func applyEntryOptions(b *builder.Builder, entry Entry) {
+	if entry.ObjectType != "" {
+		b.ObjectType(entry.ObjectType)
+	}
+	if entry.InheritedObjectType != "" {
+		b.InheritedObjectType(entry.InheritedObjectType)
+	}
+	if len(entry.Inherit) != 0 {
+		b.Inherit(entry.Inherit...)
+	}
+}
+
+// resolveAuditFlags resolves the audit flags of an audit entry.
+//
+// Parameters:
+//   - names (StringList): "success" or "failure", the SDDL tokens "SA" and "FA", or the flag
+//     names SUCCESSFUL_ACCESS and FAILED_ACCESS, case-insensitive.
+//
+// Returns:
+//   - uint8: The audit flags.
+//   - error: An error if the list is empty or holds an unknown name.
+func resolveAuditFlags(names StringList) (uint8, error) {
+	if len(names) == 0 {
+		return 0, fmt.Errorf("audit is required, expected success and/or failure")
+	}
+	auditFlags := uint8(0)
+	for _, name := range names {
+		switch strings.ToUpper(strings.TrimSpace(name)) {
+		case "SUCCESS", "SA", "SUCCESSFUL_ACCESS":
+			auditFlags |= aceflags.ACE_FLAG_SUCCESSFUL_ACCESS
+		case "FAILURE", "FA", "FAILED_ACCESS":
+			auditFlags |= aceflags.ACE_FLAG_FAILED_ACCESS
+		default:
+			return 0, fmt.Errorf("unknown audit flag %q, expected success or failure", name)
+		}
+	}
+	return auditFlags, nil
+}
@@ -0,0 +1,246 @@
+package policy_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/policy"
+)
+
+// policyTestYAML is the delegation used by the policy tests, in YAML.
+const policyTestYAML = `
+# Delegation of the helpdesk
+---
+owner: BA
+group: "SY"
+protectDacl: true
+dacl:
+  - type: allow
+    trustee: SY
+    rights: GA
+  - type: allow
+    trustee: S-1-5-21-1-2-3-1105   # helpdesk
+    rights: [RP, WP]
+    objectType: Personal-Information
+    inheritedObjectType: user
+    inherit: [CI, IO]
+  - type: deny
+    trustee: 'Anonymous'
+    rights:
+    - GENERIC_ALL
+sacl:
+  - type: audit
+    trustee: WD
+    rights: [WD, WO]
+    audit: [success, failure]
+`
+
+// policyTestJSON is the delegation of policyTestYAML, in JSON.
+const policyTestJSON = `{
+  "owner": "BA",
+  "group": "SY",
+  "protectDacl": true,
+  "dacl": [
+    {"type": "allow", "trustee": "SY", "rights": "GA"},
+    {
+      "type": "allow",
+      "trustee": "S-1-5-21-1-2-3-1105",
+      "rights": ["RP", "WP"],
+      "objectType": "Personal-Information",
+      "inheritedObjectType": "user",
+      "inherit": ["CI", "IO"]
+    },
+    {"type": "deny", "trustee": "Anonymous", "rights": ["GENERIC_ALL"]}
+  ],
+  "sacl": [
+    {"type": "audit", "trustee": "WD", "rights": ["WD", "WO"], "audit": ["success", "failure"]}
+  ]
+}`
+
+// policyTestSDDL is the descriptor compiled from policyTestYAML and policyTestJSON.
+const policyTestSDDL = "O:BAG:SYD:P(D;;GA;;;AN)(A;;GA;;;SY)" +
+	"(OA;CIIO;RPWP;77b5b886-944a-11d1-aebd-0000f80367c1;bf967aba-0de6-11d0-a285-00aa003049e2;S-1-5-21-1-2-3-1105)" +
+	"S:(AU;SAFA;WDWO;;;WD)"
+
+func TestPolicy_Compile(t *testing.T) {
+	parsers := map[string]func() (*policy.Policy, error){
+		"YAML": func() (*policy.Policy, error) { return policy.ParseYAML([]byte(policyTestYAML)) },
+		"JSON": func() (*policy.Policy, error) { return policy.ParseJSON([]byte(policyTestJSON)) },
+	}
+
+	for name, parse := range parsers {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := parse()
+			if err != nil {
+				t.Fatalf("Parse%s() error = %v", name, err)
+			}
+			ntsd, err := parsed.Compile(nil)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			got, err := ntsd.ToSDDLString()
+			if err != nil {
+				t.Fatalf("ToSDDLString() error = %v", err)
+			}
+			if got != policyTestSDDL {
+				t.Errorf("Compile() = %s, want %s", got, policyTestSDDL)
+			}
+			if _, err := ntsd.Marshal(); err != nil {
+				t.Errorf("Marshal() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestPolicy_Builder(t *testing.T) {
+	parsed, err := policy.ParseYAML([]byte("owner: BA\ndacl:\n  - {type: allow}\n"))
+	if err == nil {
+		t.Fatalf("ParseYAML() = %+v, want an error for the flow mapping", parsed)
+	}
+
+	parsed, err = policy.ParseYAML([]byte("owner: BA\n"))
+	if err != nil {
+		t.Fatalf("ParseYAML() error = %v", err)
+	}
+	ntsd, err := parsed.Builder(nil).Allow("AU", "RP").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if got, _ := ntsd.ToSDDLString(); got != "O:BAD:(A;;RP;;;AU)" {
+		t.Errorf("Build() = %s, want O:BAD:(A;;RP;;;AU)", got)
+	}
+}
+
+func TestPolicy_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     []string
+	}{
+		{
+			name:     "UnknownField",
+			document: "owner: BA\nowners: SY\n",
+			want:     []string{"unknown field \"owners\""},
+		},
+		{
+			name:     "InvalidIndentation",
+			document: "owner: BA\n  group: SY\n",
+			want:     []string{"line 2: unexpected indentation"},
+		},
+		{
+			name:     "DuplicateKey",
+			document: "owner: BA\nowner: SY\n",
+			want:     []string{"line 2: duplicate key \"owner\""},
+		},
+		{
+			name:     "Tabs",
+			document: "dacl:\n\t- type: allow\n",
+			want:     []string{"line 2: tabs are not allowed"},
+		},
+		{
+			name:     "Anchor",
+			document: "owner: &owner BA\ngroup: *owner\n",
+			want:     []string{"line 1: unsupported YAML syntax \"&owner BA\": anchors are not supported"},
+		},
+		{
+			name:     "AnchorKey",
+			document: "&owner owner: BA\n",
+			want:     []string{"line 1: unsupported YAML syntax \"&owner owner: BA\": anchors are not supported"},
+		},
+		{
+			name:     "Alias",
+			document: "dacl:\n  - *entry\n",
+			want:     []string{"line 2: unsupported YAML syntax \"*entry\": aliases are not supported"},
+		},
+		{
+			name:     "Tag",
+			document: "owner: !!str BA\n",
+			want:     []string{"tags are not supported"},
+		},
+		{
+			name:     "FlowMapping",
+			document: "{owner: BA}\n",
+			want:     []string{"line 1: unsupported YAML syntax \"{owner: BA}\": flow mappings are not supported"},
+		},
+		{
+			name:     "FlowMappingItem",
+			document: "dacl: [{type: allow}]\n",
+			want:     []string{"flow mappings are not supported"},
+		},
+		{
+			name:     "BlockScalar",
+			document: "owner: |\n  BA\n",
+			want:     []string{"line 1: unsupported YAML syntax \"|\": block scalars are not supported"},
+		},
+		{
+			name:     "MultiLineScalar",
+			document: "owner: Builtin\n  Administrators\n",
+			want:     []string{"line 2: unsupported YAML syntax \"Administrators\": multi-line scalars are not supported"},
+		},
+		{
+			name:     "MultiLineQuotedScalar",
+			document: "owner: \"Builtin\n  Administrators\"\n",
+			want:     []string{"line 1: unterminated double-quoted scalar \"Builtin, multi-line scalars are not supported"},
+		},
+		{
+			name:     "MultiLineFlowSequence",
+			document: "dacl:\n  - rights: [RP,\n      WP]\n",
+			want:     []string{"line 2: unterminated flow sequence [RP,, flow sequences must be written on one line"},
+		},
+		{
+			name:     "ComplexKey",
+			document: "? owner\n: BA\n",
+			want:     []string{"line 1: unsupported YAML syntax \"? owner\": complex mapping keys are not supported"},
+		},
+		{
+			name:     "Directive",
+			document: "%YAML 1.2\n---\nowner: BA\n",
+			want:     []string{"line 1: unsupported YAML syntax \"%YAML 1.2\": directives are not supported"},
+		},
+		{
+			name:     "CompactMapping",
+			document: "owner: name: BA\n",
+			want:     []string{"line 1: unsupported YAML syntax \"name: BA\": mappings must be written one entry per line"},
+		},
+		{
+			name:     "MultipleDocuments",
+			document: "owner: BA\n---\nowner: SY\n",
+			want:     []string{"line 2: multiple documents are not supported"},
+		},
+		{
+			name:     "UnknownNamespace",
+			document: "namespace: SPACESHIP\n",
+			want:     []string{"unknown rights namespace \"SPACESHIP\""},
+		},
+		{
+			name: "InvalidEntries",
+			document: "dacl:\n" +
+				"  - type: audit\n    trustee: WD\n    rights: GA\n" +
+				"  - type: allow\n    trustee: AU\n    rights: [RP, XX]\n" +
+				"sacl:\n" +
+				"  - type: audit\n    trustee: WD\n    rights: GA\n",
+			want: []string{
+				"dacl[0]: invalid type \"audit\", expected allow or deny",
+				"entry #1 (ALLOW AU): unknown right \"XX\"",
+				"sacl[0]: audit is required",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := policy.ParseYAML([]byte(tt.document))
+			if err == nil {
+				_, err = parsed.Compile(nil)
+			}
+			if err == nil {
+				t.Fatalf("ParseYAML().Compile() succeeded, want an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,410 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a significant line of a YAML document: not blank, not a comment, and without its
+// trailing comment.
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// yamlParser parses the lines of a YAML document.
+type yamlParser struct {
+	lines    []yamlLine
+	position int
+}
+
+// parseYAML parses the subset of YAML used by policy files, documented with the package, into
+// the values produced by encoding/json: map[string]any, []any, string, bool and nil. Every
+// construct outside of the subset is rejected with an error naming it.
+//
+// Parameters:
+//   - data ([]byte): The YAML document.
+//
+// Returns:
+//   - any: The value of the document, nil if it is empty.
+//   - error: An error if the document is invalid or uses an unsupported feature.
+func parseYAML(data []byte) (any, error) {
+	lines, err := splitYAMLLines(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	parser := &yamlParser{lines: lines}
+	value, err := parser.parseBlock(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if parser.position < len(lines) {
+		return nil, parser.errorf("unexpected indentation")
+	}
+	return value, nil
+}
+
+// splitYAMLLines splits a YAML document into its significant lines.
+func splitYAMLLines(document string) ([]yamlLine, error) {
+	document = strings.TrimPrefix(document, "\xef\xbb\xbf")
+	lines := make([]yamlLine, 0)
+	documentStarted := false
+	for index, rawLine := range strings.Split(document, "\n") {
+		rawLine = strings.TrimRight(rawLine, "\r")
+		content := strings.TrimLeft(rawLine, " ")
+		if strings.HasPrefix(content, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed in the indentation", index+1)
+		}
+		text := strings.TrimRight(stripYAMLComment(content), " \t")
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(rawLine, "%") {
+			return nil, fmt.Errorf("line %d: unsupported YAML syntax %q: directives are not supported", index+1, text)
+		}
+		if text == "---" || text == "..." {
+			if documentStarted && text == "---" {
+				return nil, fmt.Errorf("line %d: multiple documents are not supported", index+1)
+			}
+			documentStarted = true
+			continue
+		}
+		documentStarted = true
+		lines = append(lines, yamlLine{number: index + 1, indent: len(rawLine) - len(content), text: text})
+	}
+	return lines, nil
+}
+
+// stripYAMLComment removes the comment of a line. A comment starts with a "#" at the start of
+// the line or after a space, outside of quoted scalars.
+func stripYAMLComment(text string) string {
+	quote := byte(0)
+	for index := 0; index < len(text); index++ {
+		character := text[index]
+		switch {
+		case quote == '"' && character == '\\':
+			index++
+		case quote != 0:
+			if character == quote {
+				quote = 0
+			}
+		case character == '"' || character == '\'':
+			if index == 0 || strings.IndexByte(" [,:-", text[index-1]) != -1 {
+				quote = character
+			}
+		case character == '#':
+			if index == 0 || text[index-1] == ' ' || text[index-1] == '\t' {
+				return text[:index]
+			}
+		}
+	}
+	return text
+}
+
+// parseBlock parses the sequence or mapping starting at the current line.
+func (parser *yamlParser) parseBlock(indent int) (any, error) {
+	if isYAMLSequenceItem(parser.lines[parser.position].text) {
+		return parser.parseSequence(indent)
+	}
+	return parser.parseMapping(indent)
+}
+
+// parseSequence parses the items of a block sequence at the given indentation.
+func (parser *yamlParser) parseSequence(indent int) ([]any, error) {
+	items := make([]any, 0)
+	for parser.position < len(parser.lines) {
+		line := parser.lines[parser.position]
+		if line.indent < indent || (line.indent == indent && !isYAMLSequenceItem(line.text)) {
+			break
+		}
+		if line.indent > indent {
+			return nil, parser.errorf("unexpected indentation")
+		}
+
+		rest := strings.TrimLeft(line.text[1:], " ")
+		switch {
+		case rest == "":
+			parser.position++
+			item, err := parser.parseNested(indent, false)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		case isYAMLSequenceItem(rest) || isYAMLMappingEntry(rest):
+			// The nested block starts on the line of the "-", at the column of its first character
+			parser.lines[parser.position] = yamlLine{
+				number: line.number,
+				indent: line.indent + len(line.text) - len(rest),
+				text:   rest,
+			}
+			item, err := parser.parseBlock(parser.lines[parser.position].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		default:
+			item, err := parser.parseScalar(rest)
+			if err == nil {
+				err = parser.checkContinuation(indent)
+			}
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// parseMapping parses the entries of a block mapping at the given indentation.
+func (parser *yamlParser) parseMapping(indent int) (map[string]any, error) {
+	mapping := make(map[string]any)
+	for parser.position < len(parser.lines) {
+		line := parser.lines[parser.position]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, parser.errorf("unexpected indentation")
+		}
+		if isYAMLSequenceItem(line.text) {
+			return nil, parser.errorf("unexpected sequence item in a mapping")
+		}
+
+		key, rest, err := parser.splitMappingEntry(line.text)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := mapping[key]; exists {
+			return nil, parser.errorf("duplicate key %q", key)
+		}
+
+		var value any
+		if rest == "" {
+			parser.position++
+			value, err = parser.parseNested(indent, true)
+		} else {
+			value, err = parser.parseScalar(rest)
+			if err == nil {
+				err = parser.checkContinuation(indent)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		mapping[key] = value
+	}
+	return mapping, nil
+}
+
+// parseNested parses the value of a sequence item or mapping entry written on the next lines:
+// a block indented deeper than its parent, or a sequence at the indentation of the key of a
+// mapping entry. The value is null if there is none.
+func (parser *yamlParser) parseNested(indent int, mappingValue bool) (any, error) {
+	if parser.position >= len(parser.lines) {
+		return nil, nil
+	}
+	next := parser.lines[parser.position]
+	switch {
+	case next.indent > indent:
+		return parser.parseBlock(next.indent)
+	case mappingValue && next.indent == indent && isYAMLSequenceItem(next.text):
+		return parser.parseSequence(indent)
+	default:
+		return nil, nil
+	}
+}
+
+// splitMappingEntry splits a mapping entry into its key and the rest of the line.
+func (parser *yamlParser) splitMappingEntry(text string) (string, string, error) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := quotedScalarEnd(text)
+		if end == -1 || end+1 >= len(text) || text[end+1] != ':' || (end+2 < len(text) && text[end+2] != ' ') {
+			return "", "", parser.errorf("invalid mapping key %s", text)
+		}
+		key, err := parser.parseScalar(text[:end+1])
+		if err != nil {
+			return "", "", err
+		}
+		return key.(string), strings.TrimLeft(text[end+2:], " "), nil
+	}
+
+	if err := parser.checkIndicator(text); err != nil {
+		return "", "", err
+	}
+	separator := mappingSeparator(text)
+	if separator == -1 {
+		return "", "", parser.errorf("expected a mapping entry \"key: value\", got %q", text)
+	}
+	return strings.TrimRight(text[:separator], " "), strings.TrimLeft(text[separator+1:], " "), nil
+}
+
+// parseScalar parses a scalar or a flow sequence of scalars.
+func (parser *yamlParser) parseScalar(text string) (any, error) {
+	switch text[0] {
+	case '"':
+		if quotedScalarEnd(text) == -1 {
+			return nil, parser.errorf("unterminated double-quoted scalar %s, multi-line scalars are not supported", text)
+		}
+		if quotedScalarEnd(text) != len(text)-1 {
+			return nil, parser.errorf("invalid double-quoted scalar %s", text)
+		}
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, parser.errorf("invalid double-quoted scalar %s: %v", text, err)
+		}
+		return value, nil
+	case '\'':
+		if quotedScalarEnd(text) == -1 {
+			return nil, parser.errorf("unterminated single-quoted scalar %s, multi-line scalars are not supported", text)
+		}
+		if quotedScalarEnd(text) != len(text)-1 {
+			return nil, parser.errorf("invalid single-quoted scalar %s", text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case '[':
+		return parser.parseFlowSequence(text)
+	}
+	if err := parser.checkIndicator(text); err != nil {
+		return nil, err
+	}
+	if mappingSeparator(text) != -1 {
+		return nil, parser.errorf("unsupported YAML syntax %q: mappings must be written one entry per line", text)
+	}
+
+	switch text {
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	case "null", "Null", "NULL", "~":
+		return nil, nil
+	}
+	return text, nil
+}
+
+// checkIndicator rejects the text of a key or a scalar starting with an indicator of a YAML
+// construct outside of the supported subset.
+func (parser *yamlParser) checkIndicator(text string) error {
+	constructs := map[byte]string{
+		'&': "anchors", '*': "aliases", '!': "tags", '{': "flow mappings", '|': "block scalars",
+		'>': "block scalars", '?': "complex mapping keys", '%': "directives", '@': "reserved indicators",
+		'`': "reserved indicators", '[': "flow sequences as mapping keys",
+	}
+	if construct, exists := constructs[text[0]]; exists {
+		return parser.errorf("unsupported YAML syntax %q: %s are not supported", text, construct)
+	}
+	return nil
+}
+
+// checkContinuation moves past the line of a scalar value, and rejects the scalar if it
+// continues on the next lines, indented deeper than its key or "-".
+func (parser *yamlParser) checkContinuation(indent int) error {
+	parser.position++
+	if parser.position == len(parser.lines) || parser.lines[parser.position].indent <= indent {
+		return nil
+	}
+	next := parser.lines[parser.position].text
+	if isYAMLSequenceItem(next) || isYAMLMappingEntry(next) {
+		return parser.errorf("unexpected indentation")
+	}
+	return parser.errorf("unsupported YAML syntax %q: multi-line scalars are not supported", next)
+}
+
+// parseFlowSequence parses a flow sequence of scalars, like "[RP, WP]".
+func (parser *yamlParser) parseFlowSequence(text string) ([]any, error) {
+	if !strings.HasSuffix(text, "]") {
+		return nil, parser.errorf("unterminated flow sequence %s, flow sequences must be written on one line", text)
+	}
+	content := strings.TrimSpace(text[1 : len(text)-1])
+	items := make([]any, 0)
+	for content != "" {
+		end := len(content)
+		if content[0] == '"' || content[0] == '\'' {
+			end = quotedScalarEnd(content) + 1
+			if end == 0 {
+				return nil, parser.errorf("unterminated quoted scalar in %s", text)
+			}
+		} else if comma := strings.IndexByte(content, ','); comma != -1 {
+			end = comma
+		}
+
+		itemText := strings.TrimSpace(content[:end])
+		if itemText == "" || itemText[0] == '[' {
+			return nil, parser.errorf("invalid flow sequence %s", text)
+		}
+		item, err := parser.parseScalar(itemText)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		content = strings.TrimSpace(content[end:])
+		if content != "" {
+			if content[0] != ',' {
+				return nil, parser.errorf("invalid flow sequence %s", text)
+			}
+			content = strings.TrimSpace(content[1:])
+		}
+	}
+	return items, nil
+}
+
+// errorf returns an error for the current line.
+func (parser *yamlParser) errorf(format string, args ...any) error {
+	number := 0
+	if parser.position < len(parser.lines) {
+		number = parser.lines[parser.position].number
+	} else if len(parser.lines) != 0 {
+		number = parser.lines[len(parser.lines)-1].number
+	}
+	return fmt.Errorf("line %d: %s", number, fmt.Sprintf(format, args...))
+}
+
+// isYAMLSequenceItem checks if a line is an item of a block sequence.
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// isYAMLMappingEntry checks if a line is an entry of a block mapping.
+func isYAMLMappingEntry(text string) bool {
+	if text[0] == '"' || text[0] == '\'' {
+		end := quotedScalarEnd(text)
+		return end != -1 && end+1 < len(text) && text[end+1] == ':'
+	}
+	return text[0] != '[' && text[0] != '{' && mappingSeparator(text) != -1
+}
+
+// mappingSeparator returns the index of the ":" separating the key of a mapping entry from its
+// value, -1 if there is none. The ":" must be followed by a space or end the line.
+func mappingSeparator(text string) int {
+	for index := 0; index < len(text); index++ {
+		if text[index] == ':' && (index+1 == len(text) || text[index+1] == ' ') {
+			return index
+		}
+	}
+	return -1
+}
+
+// quotedScalarEnd returns the index of the closing quote of the quoted scalar starting text, -1
+// if it is not terminated.
+func quotedScalarEnd(text string) int {
+	quote := text[0]
+	for index := 1; index < len(text); index++ {
+		switch {
+		case quote == '"' && text[index] == '\\':
+			index++
+		case text[index] == quote && quote == '\'' && index+1 < len(text) && text[index+1] == '\'':
+			index++
+		case text[index] == quote:
+			return index
+		}
+	}
+	return -1
+}
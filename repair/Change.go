@@ -0,0 +1,87 @@
+package repair
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChangeCode identifies the kind of a change made by Repair.
+type ChangeCode string
+
+const (
+	// CHANGE_CODE_REVISION_FIXED reports a revision replaced by a supported one.
+	CHANGE_CODE_REVISION_FIXED ChangeCode = "REVISION_FIXED"
+	// CHANGE_CODE_OFFSET_CLEARED reports a component offset pointing inside the header or past
+	// the end of the data, which was cleared.
+	CHANGE_CODE_OFFSET_CLEARED ChangeCode = "OFFSET_CLEARED"
+	// CHANGE_CODE_SUB_AUTHORITY_COUNT_FIXED reports a SubAuthorityCount lowered to the number of
+	// sub-authorities that fit in the available bytes.
+	CHANGE_CODE_SUB_AUTHORITY_COUNT_FIXED ChangeCode = "SUB_AUTHORITY_COUNT_FIXED"
+	// CHANGE_CODE_ACL_SIZE_FIXED reports an AclSize recomputed from the entries of the ACL.
+	CHANGE_CODE_ACL_SIZE_FIXED ChangeCode = "ACL_SIZE_FIXED"
+	// CHANGE_CODE_ACE_COUNT_FIXED reports an AceCount recomputed from the entries of the ACL.
+	CHANGE_CODE_ACE_COUNT_FIXED ChangeCode = "ACE_COUNT_FIXED"
+	// CHANGE_CODE_ACE_TRUNCATED reports an ACE whose Size overran the ACL and was truncated.
+	CHANGE_CODE_ACE_TRUNCATED ChangeCode = "ACE_TRUNCATED"
+	// CHANGE_CODE_ACE_DROPPED reports ACEs that could not be delimited and were dropped.
+	CHANGE_CODE_ACE_DROPPED ChangeCode = "ACE_DROPPED"
+	// CHANGE_CODE_COMPONENT_DROPPED reports a component that could not be parsed and was dropped.
+	CHANGE_CODE_COMPONENT_DROPPED ChangeCode = "COMPONENT_DROPPED"
+	// CHANGE_CODE_CONTROL_FIXED reports a control flag aligned with the components present.
+	CHANGE_CODE_CONTROL_FIXED ChangeCode = "CONTROL_FIXED"
+)
+
+// Change is a change made by Repair to a security descriptor.
+//
+// Attributes:
+//   - Offset (int): The offset of the changed bytes, relative to the start of the original
+//     descriptor.
+//   - Path (string): The path of the changed component, like "DACL.Entries[2]".
+//   - Code (ChangeCode): The kind of the change.
+//   - Message (string): A human readable description of the change.
+type Change struct {
+	Offset  int
+	Path    string
+	Code    ChangeCode
+	Message string
+}
+
+// String returns a one-line representation of the change.
+//
+// Returns:
+//   - string: The change, like "0x0016 DACL ACL_SIZE_FIXED: AclSize 12 recomputed as 28".
+func (change Change) String() string {
+	return fmt.Sprintf("0x%04x %s %s: %s", change.Offset, change.Path, change.Code, change.Message)
+}
+
+// Changes is the list of the changes made by Repair, in the order they were made.
+type Changes []Change
+
+// WithCode returns the changes of a kind.
+//
+// Parameters:
+//   - code (ChangeCode): The kind of the changes to return.
+//
+// Returns:
+//   - Changes: The changes with this code.
+func (changes Changes) WithCode(code ChangeCode) Changes {
+	selected := Changes{}
+	for _, change := range changes {
+		if change.Code == code {
+			selected = append(selected, change)
+		}
+	}
+	return selected
+}
+
+// String returns the changes, one per line.
+//
+// Returns:
+//   - string: The changes.
+func (changes Changes) String() string {
+	lines := make([]string, len(changes))
+	for index, change := range changes {
+		lines[index] = change.String()
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,352 @@
+package repair
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/acl/revision"
+	"github.com/TheManticoreProject/winacl/object/flags"
+	"github.com/TheManticoreProject/winacl/parsing"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+	"github.com/TheManticoreProject/winacl/securitydescriptor/control"
+	"github.com/TheManticoreProject/winacl/validation"
+)
+
+// SECURITY_DESCRIPTOR_HEADER_SIZE is the size of the header of a self-relative security descriptor.
+const SECURITY_DESCRIPTOR_HEADER_SIZE = 20
+
+// ACL_HEADER_SIZE is the size of the header of an ACL.
+const ACL_HEADER_SIZE = 8
+
+// ErrDACLLost is returned by Repair when the DACL of a descriptor cannot be recovered. The
+// repaired descriptor would have no DACL, which grants full access to everyone, so Repair
+// refuses to produce it.
+var ErrDACLLost = errors.New("the DACL cannot be recovered, the repaired descriptor would grant full access to everyone")
+
+// componentOffsets lists the offset fields of the header, in the order they are repaired.
+var componentOffsets = []struct {
+	Path        string
+	FieldOffset int
+}{
+	{"Owner", 4},
+	{"Group", 8},
+	{"SACL", 12},
+	{"DACL", 16},
+}
+
+// Repair fixes the common corruptions of a marshalled security descriptor and reports the
+// changes it made. It works in two passes over a copy of the data:
+//
+//   - The bytes are patched in place: offsets pointing inside the header or past the end of
+//     the data are cleared, SubAuthorityCount fields are lowered to the number of
+//     sub-authorities that fit in the available bytes, ACEs whose Size overruns their ACL are
+//     truncated, or dropped with the following ACEs when they cannot be delimited, and the
+//     AclSize and AceCount fields are recomputed from the remaining ACEs.
+//   - The patched bytes are parsed leniently, see parsing.Options. The components that still
+//     cannot be parsed are dropped, and the control flags are aligned with the components
+//     present.
+//
+// The repaired descriptor is then marshalled and parsed again in strict mode, so that it can
+// be re-marshalled as is.
+//
+// Parameters:
+//   - marshalledData ([]byte): The marshalled security descriptor to repair. It is not modified.
+//
+// Returns:
+//   - *securitydescriptor.NtSecurityDescriptor: The repaired security descriptor.
+//   - Changes: The changes made, with their offsets in marshalledData.
+//   - error: An error if the descriptor cannot be repaired, ErrDACLLost if its DACL is lost.
+func Repair(marshalledData []byte) (*securitydescriptor.NtSecurityDescriptor, Changes, error) {
+	if len(marshalledData) < SECURITY_DESCRIPTOR_HEADER_SIZE {
+		return nil, nil, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "Header", "cannot repair security descriptor: %d bytes is too short for the header (%d)", len(marshalledData), SECURITY_DESCRIPTOR_HEADER_SIZE)
+	}
+
+	repairer := repairer{data: append([]byte{}, marshalledData...)}
+	daclPresent := repairer.control()&control.NT_SECURITY_DESCRIPTOR_CONTROL_DP != 0 && repairer.uint32At(16) != 0
+
+	repairer.repairHeader()
+
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	_, anomalies, err := ntsd.UnmarshalWithOptions(repairer.data, parsing.Options{Lenient: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse the patched security descriptor: %w", err)
+	}
+	repairer.recordAnomalies(anomalies)
+
+	if daclPresent && ntsd.DACL == nil {
+		return nil, repairer.changes, ErrDACLLost
+	}
+	repairer.repairStructure(ntsd)
+
+	repairedData, err := ntsd.Marshal()
+	if err != nil {
+		return nil, repairer.changes, fmt.Errorf("failed to marshal the repaired security descriptor: %w", err)
+	}
+	repaired := &securitydescriptor.NtSecurityDescriptor{}
+	_, err = repaired.Unmarshal(repairedData)
+	if err != nil {
+		return nil, repairer.changes, fmt.Errorf("failed to parse the repaired security descriptor: %w", err)
+	}
+
+	return repaired, repairer.changes, nil
+}
+
+// repairer holds the data being repaired and the changes made to it.
+type repairer struct {
+	data    []byte
+	changes Changes
+}
+
+// record adds a change to the list of changes.
+func (repairer *repairer) record(offset int, path string, code ChangeCode, format string, args ...any) {
+	repairer.changes = append(repairer.changes, Change{
+		Offset:  offset,
+		Path:    path,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// uint16At reads a little-endian uint16 of the data.
+func (repairer *repairer) uint16At(offset int) uint16 {
+	return binary.LittleEndian.Uint16(repairer.data[offset:])
+}
+
+// uint32At reads a little-endian uint32 of the data.
+func (repairer *repairer) uint32At(offset int) uint32 {
+	return binary.LittleEndian.Uint32(repairer.data[offset:])
+}
+
+// control reads the Control field of the header.
+func (repairer *repairer) control() uint16 {
+	return repairer.uint16At(2)
+}
+
+// repairHeader patches the revision of the header, then each component it points to.
+func (repairer *repairer) repairHeader() {
+	if repairer.data[0] != 1 {
+		repairer.record(0, "Header.Revision", CHANGE_CODE_REVISION_FIXED, "Revision %d replaced by 1", repairer.data[0])
+		repairer.data[0] = 1
+	}
+
+	for _, component := range componentOffsets {
+		offset := int(repairer.uint32At(component.FieldOffset))
+		if offset == 0 {
+			continue
+		}
+		if offset < SECURITY_DESCRIPTOR_HEADER_SIZE || offset >= len(repairer.data) {
+			repairer.clearOffset(component.FieldOffset, component.Path, "offset 0x%x is outside of the data (0x%x-0x%x)", offset, SECURITY_DESCRIPTOR_HEADER_SIZE, len(repairer.data))
+			continue
+		}
+
+		switch component.Path {
+		case "Owner", "Group":
+			if _, ok := repairer.repairSID(offset, len(repairer.data), component.Path+".SID"); !ok {
+				repairer.clearOffset(component.FieldOffset, component.Path, "%d bytes is too short for a SID", len(repairer.data)-offset)
+			}
+		default:
+			if !repairer.repairACL(offset, component.Path) {
+				repairer.clearOffset(component.FieldOffset, component.Path, "%d bytes is too short for an ACL header", len(repairer.data)-offset)
+			}
+		}
+	}
+}
+
+// clearOffset clears an offset field of the header, dropping its component.
+func (repairer *repairer) clearOffset(fieldOffset int, path string, format string, args ...any) {
+	repairer.record(fieldOffset, path, CHANGE_CODE_OFFSET_CLEARED, "%s, offset cleared", fmt.Sprintf(format, args...))
+	binary.LittleEndian.PutUint32(repairer.data[fieldOffset:], 0)
+}
+
+// repairSID lowers the SubAuthorityCount of a SID to the number of sub-authorities that fit
+// between its offset and end.
+//
+// Returns:
+//   - int: The size of the SID.
+//   - bool: false if there is not enough room for the fixed part of a SID.
+func (repairer *repairer) repairSID(offset int, end int, path string) (int, bool) {
+	if end-offset < 8 {
+		return 0, false
+	}
+	count := int(repairer.data[offset+1])
+	maxCount := min((end-offset-8)/4, validation.SID_MAX_SUB_AUTHORITIES)
+	if count > maxCount {
+		repairer.record(offset+1, path, CHANGE_CODE_SUB_AUTHORITY_COUNT_FIXED, "SubAuthorityCount %d lowered to %d to fit in %d bytes", count, maxCount, end-offset)
+		repairer.data[offset+1] = uint8(maxCount)
+		count = maxCount
+	}
+	return 8 + 4*count, true
+}
+
+// repairACL patches an ACL: its ACEs are bounded to the ACL, or to the data when AclSize does
+// not fit, then AclSize, AceCount and Revision are recomputed from the ACEs kept.
+//
+// Returns:
+//   - bool: false if there is not enough room for an ACL header.
+func (repairer *repairer) repairACL(offset int, path string) bool {
+	if len(repairer.data)-offset < ACL_HEADER_SIZE {
+		return false
+	}
+	aclSize := int(repairer.uint16At(offset + 2))
+	aceCount := int(repairer.uint16At(offset + 4))
+
+	end := offset + aclSize
+	if aclSize < ACL_HEADER_SIZE || end > len(repairer.data) {
+		end = min(len(repairer.data), offset+0xffff)
+	}
+
+	cursor := offset + ACL_HEADER_SIZE
+	kept := 0
+	hasObjectAce := false
+	for ; kept < aceCount && cursor < end; kept++ {
+		entryPath := fmt.Sprintf("%s.Entries[%d]", path, kept)
+		size, ok := repairer.repairACE(cursor, end, entryPath)
+		if !ok {
+			repairer.record(cursor, entryPath, CHANGE_CODE_ACE_DROPPED, "%d of %d ACEs dropped, their boundaries are unknown", aceCount-kept, aceCount)
+			break
+		}
+		probe := ace.AccessControlEntry{}
+		probe.Header.Type.Value = repairer.data[cursor]
+		hasObjectAce = hasObjectAce || probe.IsObjectAce()
+		cursor += size
+	}
+
+	if kept != aceCount {
+		repairer.record(offset+4, path+".Header.AceCount", CHANGE_CODE_ACE_COUNT_FIXED, "AceCount %d recomputed as %d", aceCount, kept)
+		binary.LittleEndian.PutUint16(repairer.data[offset+4:], uint16(kept))
+	}
+	if cursor-offset != aclSize {
+		repairer.record(offset+2, path+".Header.AclSize", CHANGE_CODE_ACL_SIZE_FIXED, "AclSize %d recomputed as %d", aclSize, cursor-offset)
+		binary.LittleEndian.PutUint16(repairer.data[offset+2:], uint16(cursor-offset))
+	}
+
+	aclRevision := repairer.data[offset]
+	if aclRevision < revision.ACL_REVISION || aclRevision > revision.ACL_REVISION_DS {
+		repaired := uint8(revision.ACL_REVISION)
+		if hasObjectAce {
+			repaired = revision.ACL_REVISION_DS
+		}
+		repairer.record(offset, path+".Header.Revision", CHANGE_CODE_REVISION_FIXED, "Revision %d replaced by %d", aclRevision, repaired)
+		repairer.data[offset] = repaired
+	}
+
+	return true
+}
+
+// repairACE patches an ACE: a Size overrunning the end of the ACL is truncated, then the
+// SubAuthorityCount of its SIDs is fixed against the bytes of the ACE.
+//
+// Returns:
+//   - int: The size of the ACE.
+//   - bool: false if the ACE cannot be delimited, or cannot be parsed even leniently.
+func (repairer *repairer) repairACE(offset int, end int, path string) (int, bool) {
+	if end-offset < 4 {
+		return 0, false
+	}
+	size := int(repairer.uint16At(offset + 2))
+	if size < 4 {
+		return 0, false
+	}
+	if size > end-offset {
+		truncated := (end - offset) &^ 3
+		if truncated < 4 {
+			return 0, false
+		}
+		repairer.record(offset+2, path+".Header.Size", CHANGE_CODE_ACE_TRUNCATED, "Size %d overruns the ACL, truncated to %d", size, truncated)
+		binary.LittleEndian.PutUint16(repairer.data[offset+2:], uint16(truncated))
+		size = truncated
+	}
+
+	repairer.repairACESIDs(offset, offset+size, path)
+
+	entry := ace.AccessControlEntry{}
+	_, err := entry.UnmarshalWithContext(repairer.data[offset:offset+size], parsing.NewContext(parsing.Options{Lenient: true}))
+	if err != nil {
+		return 0, false
+	}
+	return size, true
+}
+
+// repairACESIDs fixes the SubAuthorityCount of the SIDs of an ACE of a known type, see
+// repairSID.
+func (repairer *repairer) repairACESIDs(offset int, end int, path string) {
+	aceType := repairer.data[offset]
+	if _, known := acetype.AccessControlEntryTypeValueToName[aceType]; !known {
+		return
+	}
+	probe := ace.AccessControlEntry{}
+	probe.Header.Type.Value = aceType
+
+	sidOffset := offset + 8
+	switch {
+	case probe.IsObjectAce():
+		if end-offset < 12 {
+			return
+		}
+		objectFlags := repairer.uint32At(offset + 8)
+		sidOffset = offset + 12
+		if objectFlags&flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT != 0 {
+			sidOffset += 16
+		}
+		if objectFlags&flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT != 0 {
+			sidOffset += 16
+		}
+	case aceType == acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND:
+		serverSize, ok := repairer.repairSID(offset+12, end, path+".Compound.ServerIdentity.SID")
+		if !ok {
+			return
+		}
+		sidOffset = offset + 12 + serverSize
+	}
+
+	repairer.repairSID(sidOffset, end, path+".Identity.SID")
+}
+
+// recordAnomalies records the changes made by the lenient parsing of the patched data.
+func (repairer *repairer) recordAnomalies(anomalies parsing.Anomalies) {
+	for _, anomaly := range anomalies {
+		switch anomaly.Code {
+		case parsing.ANOMALY_CODE_COMPONENT_INVALID, parsing.ANOMALY_CODE_OFFSET_OUT_OF_RANGE:
+			repairer.record(anomaly.Offset, anomaly.Path, CHANGE_CODE_COMPONENT_DROPPED, "%s", anomaly.Message)
+		case parsing.ANOMALY_CODE_ACE_COUNT_MISMATCH:
+			repairer.record(anomaly.Offset, anomaly.Path, CHANGE_CODE_ACE_DROPPED, "%s", anomaly.Message)
+		}
+	}
+}
+
+// repairStructure drops the components that cannot be marshalled, and aligns the control
+// flags with the components present.
+func (repairer *repairer) repairStructure(ntsd *securitydescriptor.NtSecurityDescriptor) {
+	if ntsd.Owner != nil && ntsd.Owner.SID.RevisionLevel == 0 {
+		repairer.record(int(ntsd.Header.OffsetOwner), "Owner", CHANGE_CODE_COMPONENT_DROPPED, "SID of revision 0 dropped")
+		ntsd.Owner = nil
+	}
+	if ntsd.Group != nil && ntsd.Group.SID.RevisionLevel == 0 {
+		repairer.record(int(ntsd.Header.OffsetGroup), "Group", CHANGE_CODE_COMPONENT_DROPPED, "SID of revision 0 dropped")
+		ntsd.Group = nil
+	}
+	if ntsd.SACL != nil && len(ntsd.SACL.Entries) == 0 {
+		repairer.record(int(ntsd.Header.OffsetSacl), "SACL", CHANGE_CODE_COMPONENT_DROPPED, "SACL without entries dropped")
+		ntsd.SACL = nil
+	}
+
+	repairer.alignControl(ntsd, control.NT_SECURITY_DESCRIPTOR_CONTROL_SR, true)
+	// An empty DACL is kept as present when DACL_PRESENT is set, since it denies all access
+	daclKept := ntsd.DACL != nil && (len(ntsd.DACL.Entries) != 0 || ntsd.Header.Control.HasControl(control.NT_SECURITY_DESCRIPTOR_CONTROL_DP))
+	repairer.alignControl(ntsd, control.NT_SECURITY_DESCRIPTOR_CONTROL_DP, daclKept)
+	repairer.alignControl(ntsd, control.NT_SECURITY_DESCRIPTOR_CONTROL_SP, ntsd.SACL != nil)
+}
+
+// alignControl sets or clears a control flag of the descriptor.
+func (repairer *repairer) alignControl(ntsd *securitydescriptor.NtSecurityDescriptor, flag uint16, expected bool) {
+	name := control.NtSecurityDescriptorControlValueToShortName[flag]
+	if expected && ntsd.Header.Control.AddControl(flag) {
+		repairer.record(2, "Header.Control", CHANGE_CODE_CONTROL_FIXED, "%s set", name)
+	}
+	if !expected && ntsd.Header.Control.RemoveControl(flag) {
+		repairer.record(2, "Header.Control", CHANGE_CODE_CONTROL_FIXED, "%s cleared", name)
+	}
+}
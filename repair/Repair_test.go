@@ -0,0 +1,206 @@
+package repair_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/parsing"
+	"github.com/TheManticoreProject/winacl/repair"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+	"github.com/TheManticoreProject/winacl/securitydescriptor/control"
+)
+
+// repairTestDescriptor returns the marshalled bytes of a descriptor with a SACL, a DACL of
+// three entries, an owner and a group, laid out as SACL, DACL, Owner, Group.
+func repairTestDescriptor(t *testing.T) []byte {
+	t.Helper()
+	ntsd := securitydescriptor.NtSecurityDescriptor{}
+	if _, err := ntsd.FromSDDLString("O:BAG:S-1-5-21-1-2-3-513D:(A;;FA;;;WD)(A;;FA;;;SY)(A;;FR;;;BU)S:(AU;SA;FA;;;WD)"); err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+	marshalledData, err := ntsd.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	return marshalledData
+}
+
+// checkRepaired checks that the repaired descriptor can be marshalled and parsed again.
+func checkRepaired(t *testing.T, repaired *securitydescriptor.NtSecurityDescriptor) []byte {
+	t.Helper()
+	marshalledData, err := repaired.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	reparsed := securitydescriptor.NtSecurityDescriptor{}
+	if _, err := reparsed.Unmarshal(marshalledData); err != nil {
+		t.Fatalf("Unmarshal() of the repaired descriptor error = %v", err)
+	}
+	return marshalledData
+}
+
+func TestRepair_Valid(t *testing.T) {
+	marshalledData := repairTestDescriptor(t)
+	original := append([]byte{}, marshalledData...)
+
+	repaired, changes, err := repair.Repair(marshalledData)
+	if err != nil {
+		t.Fatalf("Repair() error = %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Repair() changes = %v, want none", changes)
+	}
+	if !bytes.Equal(checkRepaired(t, repaired), original) {
+		t.Errorf("Repair() changed a valid descriptor")
+	}
+	if !bytes.Equal(marshalledData, original) {
+		t.Errorf("Repair() modified its input")
+	}
+}
+
+func TestRepair_AclSizeAndAceCount(t *testing.T) {
+	marshalledData := repairTestDescriptor(t)
+	offsetDacl := int(binary.LittleEndian.Uint32(marshalledData[16:20]))
+	binary.LittleEndian.PutUint16(marshalledData[offsetDacl+2:], 0xfff0)
+	binary.LittleEndian.PutUint16(marshalledData[offsetDacl+4:], 9)
+
+	repaired, changes, err := repair.Repair(marshalledData)
+	if err != nil {
+		t.Fatalf("Repair() error = %v", err)
+	}
+	checkRepaired(t, repaired)
+
+	if len(repaired.DACL.Entries) != 3 || repaired.DACL.Header.AceCount != 3 {
+		t.Errorf("Repair() DACL has %d entries, AceCount %d", len(repaired.DACL.Entries), repaired.DACL.Header.AceCount)
+	}
+	aceCount := changes.WithCode(repair.CHANGE_CODE_ACE_COUNT_FIXED)
+	if len(aceCount) != 1 || aceCount[0].Offset != offsetDacl+4 || aceCount[0].Path != "DACL.Header.AceCount" {
+		t.Errorf("Repair() changes = %v", changes)
+	}
+	if len(changes.WithCode(repair.CHANGE_CODE_ACL_SIZE_FIXED)) != 1 {
+		t.Errorf("Repair() changes = %v, want an AclSize fix", changes)
+	}
+}
+
+func TestRepair_AceOverrun(t *testing.T) {
+	marshalledData := repairTestDescriptor(t)
+	offsetDacl := int(binary.LittleEndian.Uint32(marshalledData[16:20]))
+	// The second entry claims to span past the end of the DACL.
+	offsetEntry := offsetDacl + 8 + 20
+	binary.LittleEndian.PutUint16(marshalledData[offsetEntry+2:], 0x200)
+
+	repaired, changes, err := repair.Repair(marshalledData)
+	if err != nil {
+		t.Fatalf("Repair() error = %v", err)
+	}
+	checkRepaired(t, repaired)
+
+	truncated := changes.WithCode(repair.CHANGE_CODE_ACE_TRUNCATED)
+	if len(truncated) != 1 || truncated[0].Path != "DACL.Entries[1].Header.Size" || truncated[0].Offset != offsetEntry+2 {
+		t.Errorf("Repair() changes = %v", changes)
+	}
+	// The truncated entry spans the rest of the DACL, the third entry is gone.
+	if len(repaired.DACL.Entries) != 2 || len(changes.WithCode(repair.CHANGE_CODE_ACE_COUNT_FIXED)) != 1 {
+		t.Errorf("Repair() DACL has %d entries, changes = %v", len(repaired.DACL.Entries), changes)
+	}
+	if repaired.DACL.Entries[0].Identity.SID.ToString() != "S-1-1-0" {
+		t.Errorf("Repair() lost the first entry")
+	}
+}
+
+func TestRepair_AceUndelimited(t *testing.T) {
+	marshalledData := repairTestDescriptor(t)
+	offsetDacl := int(binary.LittleEndian.Uint32(marshalledData[16:20]))
+	binary.LittleEndian.PutUint16(marshalledData[offsetDacl+8+20+2:], 2)
+
+	repaired, changes, err := repair.Repair(marshalledData)
+	if err != nil {
+		t.Fatalf("Repair() error = %v", err)
+	}
+	checkRepaired(t, repaired)
+
+	dropped := changes.WithCode(repair.CHANGE_CODE_ACE_DROPPED)
+	if len(dropped) != 1 || dropped[0].Path != "DACL.Entries[1]" || len(repaired.DACL.Entries) != 1 {
+		t.Errorf("Repair() changes = %v", changes)
+	}
+}
+
+func TestRepair_SubAuthorityCount(t *testing.T) {
+	marshalledData := repairTestDescriptor(t)
+	offsetGroup := int(binary.LittleEndian.Uint32(marshalledData[8:12]))
+	// The group is the last component, claim more sub-authorities than the data holds.
+	marshalledData[offsetGroup+1] = 9
+
+	repaired, changes, err := repair.Repair(marshalledData)
+	if err != nil {
+		t.Fatalf("Repair() error = %v", err)
+	}
+	checkRepaired(t, repaired)
+
+	fixed := changes.WithCode(repair.CHANGE_CODE_SUB_AUTHORITY_COUNT_FIXED)
+	if len(fixed) != 1 || fixed[0].Offset != offsetGroup+1 || fixed[0].Path != "Group.SID" {
+		t.Errorf("Repair() changes = %v", changes)
+	}
+	if repaired.Group == nil || repaired.Group.SID.ToString() != "S-1-5-21-1-2-3-513" {
+		t.Errorf("Repair() Group = %v", repaired.Group)
+	}
+}
+
+func TestRepair_OffsetInsideHeader(t *testing.T) {
+	marshalledData := repairTestDescriptor(t)
+	binary.LittleEndian.PutUint32(marshalledData[4:8], 8)
+
+	repaired, changes, err := repair.Repair(marshalledData)
+	if err != nil {
+		t.Fatalf("Repair() error = %v", err)
+	}
+	checkRepaired(t, repaired)
+
+	cleared := changes.WithCode(repair.CHANGE_CODE_OFFSET_CLEARED)
+	if len(cleared) != 1 || cleared[0].Offset != 4 || cleared[0].Path != "Owner" || repaired.Owner != nil {
+		t.Errorf("Repair() changes = %v", changes)
+	}
+}
+
+func TestRepair_Control(t *testing.T) {
+	marshalledData := repairTestDescriptor(t)
+	// Clear SR and SP, the SACL is present.
+	value := binary.LittleEndian.Uint16(marshalledData[2:4])
+	value &^= control.NT_SECURITY_DESCRIPTOR_CONTROL_SR | control.NT_SECURITY_DESCRIPTOR_CONTROL_SP
+	binary.LittleEndian.PutUint16(marshalledData[2:4], value)
+
+	repaired, changes, err := repair.Repair(marshalledData)
+	if err != nil {
+		t.Fatalf("Repair() error = %v", err)
+	}
+	checkRepaired(t, repaired)
+
+	if len(changes.WithCode(repair.CHANGE_CODE_CONTROL_FIXED)) != 2 {
+		t.Errorf("Repair() changes = %v", changes)
+	}
+	if !repaired.Header.Control.HasControl(control.NT_SECURITY_DESCRIPTOR_CONTROL_SR | control.NT_SECURITY_DESCRIPTOR_CONTROL_SP) {
+		t.Errorf("Repair() Control = 0x%04x", repaired.Header.Control.RawValue)
+	}
+}
+
+func TestRepair_DACLLost(t *testing.T) {
+	marshalledData := repairTestDescriptor(t)
+	binary.LittleEndian.PutUint32(marshalledData[16:20], 12)
+
+	_, changes, err := repair.Repair(marshalledData)
+	if !errors.Is(err, repair.ErrDACLLost) {
+		t.Fatalf("Repair() error = %v, want ErrDACLLost", err)
+	}
+	if len(changes.WithCode(repair.CHANGE_CODE_OFFSET_CLEARED)) != 1 {
+		t.Errorf("Repair() changes = %v", changes)
+	}
+}
+
+func TestRepair_Truncated(t *testing.T) {
+	_, _, err := repair.Repair([]byte{0x01, 0x00, 0x04, 0x80})
+	if !errors.Is(err, parsing.ErrTruncated) {
+		t.Errorf("Repair() error = %v, want a truncated error", err)
+	}
+}
@@ -0,0 +1,103 @@
+package rights
+
+import "strings"
+
+// ExtendedRight describes an Active Directory controlAccessRight object, as found in the
+// Extended-Rights container of the configuration naming context.
+//
+// Fields:
+//   - Name: The common name of the controlAccessRight object (e.g. "DS-Replication-Get-Changes").
+//   - DisplayName: The displayName of the controlAccessRight object (e.g. "Replicating Directory Changes").
+//   - RightsGUID: The rightsGuid of the controlAccessRight object, in lowercase D format. This is the
+//     GUID found in the ObjectType of object ACEs.
+//   - ValidAccesses: The access mask bits the right can be used with (DS_CONTROL_ACCESS for extended
+//     rights, DS_SELF for validated writes, DS_READ_PROPERTY and DS_WRITE_PROPERTY for property sets).
+//   - AppliesTo: The schemaIDGUIDs of the classes the right applies to, in lowercase D format.
+type ExtendedRight struct {
+	Name          string
+	DisplayName   string
+	RightsGUID    string
+	ValidAccesses uint32
+	AppliesTo     []string
+}
+
+// ExtendedRightKind classifies a controlAccessRight object according to the access mask bits
+// it is valid for.
+type ExtendedRightKind int
+
+const (
+	// EXTENDED_RIGHT_KIND_UNKNOWN is used when the validAccesses do not identify the kind of the right.
+	EXTENDED_RIGHT_KIND_UNKNOWN ExtendedRightKind = iota
+	// EXTENDED_RIGHT_KIND_CONTROL_ACCESS is a control access right, used with DS_CONTROL_ACCESS.
+	EXTENDED_RIGHT_KIND_CONTROL_ACCESS
+	// EXTENDED_RIGHT_KIND_VALIDATED_WRITE is a validated write, used with DS_WRITE_PROPERTY_EXTENDED (DS_SELF).
+	EXTENDED_RIGHT_KIND_VALIDATED_WRITE
+	// EXTENDED_RIGHT_KIND_PROPERTY_SET is a property set, used with DS_READ_PROPERTY and DS_WRITE_PROPERTY.
+	EXTENDED_RIGHT_KIND_PROPERTY_SET
+)
+
+// String returns the name of the ExtendedRightKind.
+//
+// Returns:
+//   - string: "Extended right", "Validated write", "Property set" or "Unknown".
+func (kind ExtendedRightKind) String() string {
+	switch kind {
+	case EXTENDED_RIGHT_KIND_CONTROL_ACCESS:
+		return "Extended right"
+	case EXTENDED_RIGHT_KIND_VALIDATED_WRITE:
+		return "Validated write"
+	case EXTENDED_RIGHT_KIND_PROPERTY_SET:
+		return "Property set"
+	default:
+		return "Unknown"
+	}
+}
+
+// Kind classifies the right from its ValidAccesses.
+//
+// Returns:
+//   - ExtendedRightKind: The kind of the right, EXTENDED_RIGHT_KIND_UNKNOWN if ValidAccesses is not set.
+func (extendedRight ExtendedRight) Kind() ExtendedRightKind {
+	switch {
+	case extendedRight.ValidAccesses&RIGHT_DS_CONTROL_ACCESS != 0:
+		return EXTENDED_RIGHT_KIND_CONTROL_ACCESS
+	case extendedRight.ValidAccesses&RIGHT_DS_WRITE_PROPERTY_EXTENDED != 0:
+		return EXTENDED_RIGHT_KIND_VALIDATED_WRITE
+	case extendedRight.ValidAccesses&(RIGHT_DS_READ_PROPERTY|RIGHT_DS_WRITE_PROPERTY) != 0:
+		return EXTENDED_RIGHT_KIND_PROPERTY_SET
+	default:
+		return EXTENDED_RIGHT_KIND_UNKNOWN
+	}
+}
+
+// IsValidFor reports whether the right can be used with at least one of the bits of an access mask.
+//
+// Parameters:
+//   - accessMask (uint32): The access mask of the ACE referencing the right.
+//
+// Returns:
+//   - bool: true if the mask shares at least one bit with ValidAccesses, false otherwise.
+func (extendedRight ExtendedRight) IsValidFor(accessMask uint32) bool {
+	return extendedRight.ValidAccesses&accessMask != 0
+}
+
+// AppliesToClass reports whether the right is listed as applying to the given class. A right
+// with an empty AppliesTo list is considered to apply to every class, since its applicability
+// is unknown. Class inheritance is not taken into account here; see schema.Registry for that.
+//
+// Parameters:
+//   - schemaIDGUID (string): The schemaIDGUID of the class, in D format.
+//
+// Returns:
+//   - bool: true if the right applies to the class, false otherwise.
+func (extendedRight ExtendedRight) AppliesToClass(schemaIDGUID string) bool {
+	if len(extendedRight.AppliesTo) == 0 {
+		return true
+	}
+	for _, appliesTo := range extendedRight.AppliesTo {
+		if strings.EqualFold(appliesTo, schemaIDGUID) {
+			return true
+		}
+	}
+	return false
+}
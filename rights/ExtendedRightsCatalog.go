@@ -0,0 +1,207 @@
+package rights
+
+import "strings"
+
+// Validated writes. Their rightsGuid is the schemaIDGUID of the attribute they control, except for
+// DS-Validated-Write-Computer (see EXTENDED_RIGHT_DS_VALIDATED_WRITE_COMPUTER). Validated-DNS-Host-Name
+// shares its rightsGuid with the DNS-Host-Name-Attributes property set.
+const (
+	VALIDATED_WRITE_SELF_MEMBERSHIP                = "bf9679c0-0de6-11d0-a285-00aa003049e2"
+	VALIDATED_WRITE_DNS_HOST_NAME                  = "72e39547-7b18-11d1-adef-00c04fd8d5cd"
+	VALIDATED_WRITE_MS_DS_ADDITIONAL_DNS_HOST_NAME = "80863791-dbe9-4eb8-837e-7f0ab55d9ac7"
+	VALIDATED_WRITE_MS_DS_BEHAVIOR_VERSION         = "d31a8757-2447-4545-8081-3bb610cacbf2"
+	VALIDATED_WRITE_SPN                            = "f3a64788-5306-11d1-a9c5-0000f80367c1"
+)
+
+// validAccesses of each kind of controlAccessRight, and rightsGuids of the property sets. The rights
+// package cannot import the schema package, which defines the matching PROPERTY_SET_* constants.
+const (
+	validatedWriteAccesses                           = RIGHT_DS_WRITE_PROPERTY_EXTENDED
+	controlAccessAccesses                            = RIGHT_DS_CONTROL_ACCESS
+	propertySetAccesses                              = RIGHT_DS_READ_PROPERTY | RIGHT_DS_WRITE_PROPERTY
+	propertySetRightsGUIDDNSHostNameAttributes       = "72e39547-7b18-11d1-adef-00c04fd8d5cd"
+	propertySetRightsGUIDAccountRestrictions         = "4c164200-20c0-11d0-a768-00aa006e0529"
+	propertySetRightsGUIDDomainPassword              = "c7407360-20bf-11d0-a768-00aa006e0529"
+	propertySetRightsGUIDGeneralInformation          = "59ba2f42-79a2-11d0-9020-00c04fc2d3cf"
+	propertySetRightsGUIDMembership                  = "bc0ac240-79a9-11d0-9020-00c04fc2d4cf"
+	propertySetRightsGUIDLogonInformation            = "5f202010-79a5-11d0-9020-00c04fc2d4cf"
+	propertySetRightsGUIDMSTSGatewayAccess           = "ffa6f046-ca4b-4feb-b40d-04dfee722543"
+	propertySetRightsGUIDDomainOtherParameters       = "b8119fd0-04f6-4762-ab7a-4986c76b3f9a"
+	propertySetRightsGUIDPersonalInformation         = "77b5b886-944a-11d1-aebd-0000f80367c1"
+	propertySetRightsGUIDEmailInformation            = "e45795b2-9455-11d1-aebd-0000f80367c1"
+	propertySetRightsGUIDPrivateInformation          = "91e647de-d96f-4b70-9557-d63ff4f3ccd8"
+	propertySetRightsGUIDPublicInformation           = "e48d0154-bcf8-11d1-8702-00c04fb96050"
+	propertySetRightsGUIDRASInformation              = "037088f8-0ae1-11d2-b422-00a0c968f939"
+	propertySetRightsGUIDTerminalServerLicenseServer = "5805bc62-bdc9-4428-a5e2-856a0f4c185e"
+	propertySetRightsGUIDWebInformation              = "e45795b3-9455-11d1-aebd-0000f80367c1"
+)
+
+// schemaIDGUIDs of the classes referenced by the appliesTo lists below. The rights package cannot
+// import the schema package, which defines the matching SCHEMA_CLASS_* constants.
+const (
+	appliesToComputer               = "bf967a86-0de6-11d0-a285-00aa003049e2"
+	appliesToConfiguration          = "bf967a87-0de6-11d0-a285-00aa003049e2"
+	appliesToContact                = "5cb41ed0-0e4c-11d0-a286-00aa003049e2"
+	appliesToCrossRef               = "bf967a8d-0de6-11d0-a285-00aa003049e2"
+	appliesToDMD                    = "bf967a8f-0de6-11d0-a285-00aa003049e2"
+	appliesToDomainDNS              = "19195a5b-6da0-11d0-afd3-00c04fd930c9"
+	appliesToGroup                  = "bf967a9c-0de6-11d0-a285-00aa003049e2"
+	appliesToGroupPolicyContainer   = "f30e3bc2-9ff0-11d1-b603-0000f80367c1"
+	appliesToNTDSDSA                = "f0f8ffab-1191-11d0-a060-00aa006c33ed"
+	appliesToOrganizationalUnit     = "bf967aa5-0de6-11d0-a285-00aa003049e2"
+	appliesToPKICertificateTemplate = "e5209ca2-3bba-11d2-90cc-00c04fd91ab1"
+	appliesToRIDManager             = "6617188d-8f3c-11d0-afda-00c04fd930c9"
+	appliesToUser                   = "bf967aba-0de6-11d0-a285-00aa003049e2"
+)
+
+// ExtendedRightsCatalog lists the built-in controlAccessRight objects of the Extended-Rights container:
+// control access rights, validated writes and property sets. AppliesTo only lists the base classes a
+// right is registered for; subclasses (e.g. computer and inetOrgPerson for user) are resolved through
+// the class hierarchy by schema.Registry. Rights whose applicability is not tracked have an empty AppliesTo.
+//
+// A rightsGuid can appear more than once when a validated write and a property set share it, which is
+// the case of Validated-DNS-Host-Name and DNS-Host-Name-Attributes.
+var ExtendedRightsCatalog = []ExtendedRight{
+	// Control access rights
+	{Name: "Abandon-Replication", DisplayName: "Abandon Replication", RightsGUID: EXTENDED_RIGHT_ABANDON_REPLICATION, ValidAccesses: controlAccessAccesses},
+	{Name: "Add-GUID", DisplayName: "Add GUID", RightsGUID: EXTENDED_RIGHT_ADD_GUID, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS}},
+	{Name: "Allocate-Rids", DisplayName: "Allocate Rids", RightsGUID: EXTENDED_RIGHT_ALLOCATE_RIDS, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToNTDSDSA}},
+	{Name: "Allowed-To-Authenticate", DisplayName: "Allowed to Authenticate", RightsGUID: EXTENDED_RIGHT_ALLOWED_TO_AUTHENTICATE, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToUser}},
+	{Name: "Apply-Group-Policy", DisplayName: "Apply Group Policy", RightsGUID: EXTENDED_RIGHT_APPLY_GROUP_POLICY, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToGroupPolicyContainer}},
+	{Name: "Certificate-Enrollment", DisplayName: "Enroll", RightsGUID: EXTENDED_RIGHT_CERTIFICATE_ENROLLMENT, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToPKICertificateTemplate}},
+	{Name: "Certificate-AutoEnrollment", DisplayName: "AutoEnrollment", RightsGUID: EXTENDED_RIGHT_CERTIFICATE_AUTOENROLLMENT, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToPKICertificateTemplate}},
+	{Name: "Change-Domain-Master", DisplayName: "Change Domain Master", RightsGUID: EXTENDED_RIGHT_CHANGE_DOMAIN_MASTER, ValidAccesses: controlAccessAccesses},
+	{Name: "Change-Infrastructure-Master", DisplayName: "Change Infrastructure Master", RightsGUID: EXTENDED_RIGHT_CHANGE_INFRASTRUCTURE_MASTER, ValidAccesses: controlAccessAccesses},
+	{Name: "Change-PDC", DisplayName: "Change PDC", RightsGUID: EXTENDED_RIGHT_CHANGE_PDC, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS}},
+	{Name: "Change-Rid-Master", DisplayName: "Change Rid Master", RightsGUID: EXTENDED_RIGHT_CHANGE_RID_MASTER, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToRIDManager}},
+	{Name: "Change-Schema-Master", DisplayName: "Change Schema Master", RightsGUID: EXTENDED_RIGHT_CHANGE_SCHEMA_MASTER, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDMD}},
+	{Name: "Create-Inbound-Forest-Trust", DisplayName: "Create Inbound Forest Trust", RightsGUID: EXTENDED_RIGHT_CREATE_INBOUND_FOREST_TRUST, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS}},
+	{Name: "Do-Garbage-Collection", DisplayName: "Do Garbage Collection", RightsGUID: EXTENDED_RIGHT_DO_GARBAGE_COLLECTION, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToNTDSDSA}},
+	{Name: "Domain-Administer-Server", DisplayName: "Domain Administer Server", RightsGUID: EXTENDED_RIGHT_DOMAIN_ADMINISTER_SERVER, ValidAccesses: controlAccessAccesses},
+	{Name: "DS-Check-Stale-Phantoms", DisplayName: "Check Stale Phantoms", RightsGUID: EXTENDED_RIGHT_DS_CHECK_STALE_PHANTOMS, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToNTDSDSA}},
+	{Name: "DS-Execute-Intentions-Script", DisplayName: "Execute Forest Update Script", RightsGUID: EXTENDED_RIGHT_DS_EXECUTE_INTENTIONS_SCRIPT, ValidAccesses: controlAccessAccesses},
+	{Name: "DS-Install-Replica", DisplayName: "Add/Remove Replica In Domain", RightsGUID: EXTENDED_RIGHT_DS_INSTALL_REPLICA, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS}},
+	{Name: "DS-Query-Self-Quota", DisplayName: "Query Self Quota", RightsGUID: EXTENDED_RIGHT_DS_QUERY_SELF_QUOTA, ValidAccesses: controlAccessAccesses},
+	{Name: "DS-Replication-Get-Changes", DisplayName: "Replicating Directory Changes", RightsGUID: EXTENDED_RIGHT_DS_REPLICATION_GET_CHANGES, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS, appliesToConfiguration, appliesToDMD}},
+	{Name: "DS-Replication-Get-Changes-All", DisplayName: "Replicating Directory Changes All", RightsGUID: EXTENDED_RIGHT_DS_REPLICATION_GET_CHANGES_ALL, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS, appliesToConfiguration, appliesToDMD}},
+	{Name: "DS-Replication-Get-Changes-In-Filtered-Set", DisplayName: "Replicating Directory Changes In Filtered Set", RightsGUID: EXTENDED_RIGHT_DS_REPLICATION_GET_CHANGES_IN_FILTERED_SET, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS, appliesToConfiguration, appliesToDMD}},
+	{Name: "DS-Replication-Manage-Topology", DisplayName: "Manage Replication Topology", RightsGUID: EXTENDED_RIGHT_DS_REPLICATION_MANAGE_TOPOLOGY, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS, appliesToConfiguration, appliesToDMD}},
+	{Name: "DS-Replication-Monitor-Topology", DisplayName: "Monitor Active Directory Replication", RightsGUID: EXTENDED_RIGHT_DS_REPLICATION_MONITOR_TOPOLOGY, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS, appliesToConfiguration, appliesToDMD}},
+	{Name: "DS-Replication-Synchronize", DisplayName: "Replication Synchronization", RightsGUID: EXTENDED_RIGHT_DS_REPLICATION_SYNCHRONIZE, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS, appliesToConfiguration, appliesToDMD}},
+	{Name: "Enable-Per-User-Reversibly-Encrypted-Password", DisplayName: "Enable Per User Reversibly Encrypted Password", RightsGUID: EXTENDED_RIGHT_ENABLE_PER_USER_REVERSIBLY_ENCRYPTED_PASSWORD, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS}},
+	{Name: "Generate-RSoP-Logging", DisplayName: "Generate Resultant Set of Policy (Logging)", RightsGUID: EXTENDED_RIGHT_GENERATE_RSOP_LOGGING, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS, appliesToOrganizationalUnit}},
+	{Name: "Generate-RSoP-Planning", DisplayName: "Generate Resultant Set of Policy (Planning)", RightsGUID: EXTENDED_RIGHT_GENERATE_RSOP_PLANNING, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS, appliesToOrganizationalUnit}},
+	{Name: "Manage-Optional-Features", DisplayName: "Manage Optional Features", RightsGUID: EXTENDED_RIGHT_MANAGE_OPTIONAL_FEATURES, ValidAccesses: controlAccessAccesses},
+	{Name: "Migrate-SID-History", DisplayName: "Migrate SID History", RightsGUID: EXTENDED_RIGHT_MIGRATE_SID_HISTORY, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS}},
+	{Name: "msmq-Open-Connector", DisplayName: "Open Connector Queue", RightsGUID: EXTENDED_RIGHT_MSMQ_OPEN_CONNECTOR, ValidAccesses: controlAccessAccesses},
+	{Name: "msmq-Peek", DisplayName: "Peek Message", RightsGUID: EXTENDED_RIGHT_MSMQ_PEEK, ValidAccesses: controlAccessAccesses},
+	{Name: "msmq-Peek-computer-Journal", DisplayName: "Peek Computer Journal", RightsGUID: EXTENDED_RIGHT_MSMQ_PEEK_COMPUTER_JOURNAL, ValidAccesses: controlAccessAccesses},
+	{Name: "msmq-Peek-Dead-Letter", DisplayName: "Peek Dead Letter", RightsGUID: EXTENDED_RIGHT_MSMQ_PEEK_DEAD_LETTER, ValidAccesses: controlAccessAccesses},
+	{Name: "msmq-Receive", DisplayName: "Receive Message", RightsGUID: EXTENDED_RIGHT_MSMQ_RECEIVE, ValidAccesses: controlAccessAccesses},
+	{Name: "msmq-Receive-computer-Journal", DisplayName: "Receive Computer Journal", RightsGUID: EXTENDED_RIGHT_MSMQ_RECEIVE_COMPUTER_JOURNAL, ValidAccesses: controlAccessAccesses},
+	{Name: "msmq-Receive-Dead-Letter", DisplayName: "Receive Dead Letter", RightsGUID: EXTENDED_RIGHT_MSMQ_RECEIVE_DEAD_LETTER, ValidAccesses: controlAccessAccesses},
+	{Name: "msmq-Receive-journal", DisplayName: "Receive Journal", RightsGUID: EXTENDED_RIGHT_MSMQ_RECEIVE_JOURNAL, ValidAccesses: controlAccessAccesses},
+	{Name: "msmq-Send", DisplayName: "Send Message", RightsGUID: EXTENDED_RIGHT_MSMQ_SEND, ValidAccesses: controlAccessAccesses},
+	{Name: "Open-Address-Book", DisplayName: "Open Address List", RightsGUID: EXTENDED_RIGHT_OPEN_ADDRESS_BOOK, ValidAccesses: controlAccessAccesses},
+	{Name: "Read-Only-Replication-Secret-Synchronization", DisplayName: "Read Only Replication Secret Synchronization", RightsGUID: EXTENDED_RIGHT_READ_ONLY_REPLICATION_SECRET_SYNCHRONIZATION, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS}},
+	{Name: "Reanimate-Tombstones", DisplayName: "Reanimate Tombstones", RightsGUID: EXTENDED_RIGHT_REANIMATE_TOMBSTONES, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS, appliesToConfiguration, appliesToDMD}},
+	{Name: "Recalculate-Hierarchy", DisplayName: "Recalculate Hierarchy", RightsGUID: EXTENDED_RIGHT_RECALCULATE_HIERARCHY, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToNTDSDSA}},
+	{Name: "Recalculate-Security-Inheritance", DisplayName: "Recalculate Security Inheritance", RightsGUID: EXTENDED_RIGHT_RECALCULATE_SECURITY_INHERITANCE, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToNTDSDSA}},
+	{Name: "Receive-As", DisplayName: "Receive As", RightsGUID: EXTENDED_RIGHT_RECEIVE_AS, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToUser}},
+	{Name: "Refresh-Group-Cache", DisplayName: "Refresh Group Cache for Logons", RightsGUID: EXTENDED_RIGHT_REFRESH_GROUP_CACHE, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToNTDSDSA}},
+	{Name: "Reload-SSL-Certificate", DisplayName: "Reload SSL/TLS Certificate", RightsGUID: EXTENDED_RIGHT_RELOAD_SSL_CERTIFICATE, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToNTDSDSA}},
+	{Name: "Run-Protect-Admin-Groups-Task", DisplayName: "Run Protect Admin Groups Task", RightsGUID: EXTENDED_RIGHT_RUN_PROTECT_ADMIN_GROUPS_TASK, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS}},
+	{Name: "SAM-Enumerate-Entire-Domain", DisplayName: "Enumerate Entire SAM Domain", RightsGUID: EXTENDED_RIGHT_SAM_ENUMERATE_ENTIRE_DOMAIN, ValidAccesses: controlAccessAccesses},
+	{Name: "Send-As", DisplayName: "Send As", RightsGUID: EXTENDED_RIGHT_SEND_AS, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToUser}},
+	{Name: "Send-To", DisplayName: "Send To", RightsGUID: EXTENDED_RIGHT_SEND_TO, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToGroup}},
+	{Name: "Unexpire-Password", DisplayName: "Unexpire Password", RightsGUID: EXTENDED_RIGHT_UNEXPIRE_PASSWORD, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS}},
+	{Name: "Update-Password-Not-Required-Bit", DisplayName: "Update Password Not Required Bit", RightsGUID: EXTENDED_RIGHT_UPDATE_PASSWORD_NOT_REQUIRED_BIT, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS}},
+	{Name: "Update-Schema-Cache", DisplayName: "Update Schema Cache", RightsGUID: EXTENDED_RIGHT_UPDATE_SCHEMA_CACHE, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDMD}},
+	{Name: "User-Change-Password", DisplayName: "Change Password", RightsGUID: EXTENDED_RIGHT_USER_CHANGE_PASSWORD, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToUser}},
+	{Name: "User-Force-Change-Password", DisplayName: "Reset Password", RightsGUID: EXTENDED_RIGHT_USER_FORCE_CHANGE_PASSWORD, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToUser}},
+	{Name: "DS-Clone-Domain-Controller", DisplayName: "Allow a DC to create a clone of itself", RightsGUID: EXTENDED_RIGHT_DS_CLONE_DOMAIN_CONTROLLER, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToDomainDNS}},
+	{Name: "DS-Read-Partition-Secrets", DisplayName: "Read secret attributes of objects in a Partition", RightsGUID: EXTENDED_RIGHT_DS_READ_PARTITION_SECRETS, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToCrossRef}},
+	{Name: "DS-Write-Partition-Secrets", DisplayName: "Write secret attributes of objects in a Partition", RightsGUID: EXTENDED_RIGHT_DS_WRITE_PARTITION_SECRETS, ValidAccesses: controlAccessAccesses, AppliesTo: []string{appliesToCrossRef}},
+	{Name: "DS-Set-Owner", DisplayName: "Set Owner of an object during creation.", RightsGUID: EXTENDED_RIGHT_DS_SET_OWNER, ValidAccesses: controlAccessAccesses},
+	{Name: "DS-Bypass-Quota", DisplayName: "Bypass the quota restrictions during creation.", RightsGUID: EXTENDED_RIGHT_DS_BYPASS_QUOTA, ValidAccesses: controlAccessAccesses},
+
+	// Validated writes
+	{Name: "Self-Membership", DisplayName: "Add/Remove self as member", RightsGUID: VALIDATED_WRITE_SELF_MEMBERSHIP, ValidAccesses: validatedWriteAccesses, AppliesTo: []string{appliesToGroup}},
+	{Name: "Validated-DNS-Host-Name", DisplayName: "Validated write to DNS host name", RightsGUID: VALIDATED_WRITE_DNS_HOST_NAME, ValidAccesses: validatedWriteAccesses, AppliesTo: []string{appliesToComputer}},
+	{Name: "Validated-MS-DS-Additional-DNS-Host-Name", DisplayName: "Validated write to MS DS Additional DNS Host Name", RightsGUID: VALIDATED_WRITE_MS_DS_ADDITIONAL_DNS_HOST_NAME, ValidAccesses: validatedWriteAccesses, AppliesTo: []string{appliesToComputer}},
+	{Name: "Validated-MS-DS-Behavior-Version", DisplayName: "Validated write to MS DS behavior version", RightsGUID: VALIDATED_WRITE_MS_DS_BEHAVIOR_VERSION, ValidAccesses: validatedWriteAccesses, AppliesTo: []string{appliesToNTDSDSA}},
+	{Name: "Validated-SPN", DisplayName: "Validated write to service principal name", RightsGUID: VALIDATED_WRITE_SPN, ValidAccesses: validatedWriteAccesses, AppliesTo: []string{appliesToComputer}},
+	{Name: "DS-Validated-Write-Computer", DisplayName: "Validated write to computer attributes.", RightsGUID: EXTENDED_RIGHT_DS_VALIDATED_WRITE_COMPUTER, ValidAccesses: validatedWriteAccesses, AppliesTo: []string{appliesToComputer}},
+
+	// Property sets
+	{Name: "User-Account-Restrictions", DisplayName: "Account Restrictions", RightsGUID: propertySetRightsGUIDAccountRestrictions, ValidAccesses: propertySetAccesses, AppliesTo: []string{appliesToUser}},
+	{Name: "DNS-Host-Name-Attributes", DisplayName: "DNS Host Name Attributes", RightsGUID: propertySetRightsGUIDDNSHostNameAttributes, ValidAccesses: propertySetAccesses, AppliesTo: []string{appliesToComputer}},
+	{Name: "Domain-Password", DisplayName: "Domain Password & Lockout Policies", RightsGUID: propertySetRightsGUIDDomainPassword, ValidAccesses: propertySetAccesses, AppliesTo: []string{appliesToDomainDNS}},
+	{Name: "General-Information", DisplayName: "General Information", RightsGUID: propertySetRightsGUIDGeneralInformation, ValidAccesses: propertySetAccesses, AppliesTo: []string{appliesToUser}},
+	{Name: "Membership", DisplayName: "Group Membership", RightsGUID: propertySetRightsGUIDMembership, ValidAccesses: propertySetAccesses, AppliesTo: []string{appliesToUser, appliesToGroup}},
+	{Name: "User-Logon", DisplayName: "Logon Information", RightsGUID: propertySetRightsGUIDLogonInformation, ValidAccesses: propertySetAccesses, AppliesTo: []string{appliesToUser}},
+	{Name: "MS-TS-GatewayAccess", DisplayName: "MS-TS-GatewayAccess", RightsGUID: propertySetRightsGUIDMSTSGatewayAccess, ValidAccesses: propertySetAccesses, AppliesTo: []string{appliesToComputer}},
+	{Name: "Domain-Other-Parameters", DisplayName: "Other Domain Parameters (for use by SAM)", RightsGUID: propertySetRightsGUIDDomainOtherParameters, ValidAccesses: propertySetAccesses, AppliesTo: []string{appliesToDomainDNS}},
+	{Name: "Personal-Information", DisplayName: "Personal Information", RightsGUID: propertySetRightsGUIDPersonalInformation, ValidAccesses: propertySetAccesses, AppliesTo: []string{appliesToUser, appliesToContact}},
+	{Name: "Email-Information", DisplayName: "Phone and Mail Options", RightsGUID: propertySetRightsGUIDEmailInformation, ValidAccesses: propertySetAccesses, AppliesTo: []string{appliesToUser, appliesToContact, appliesToGroup}},
+	{Name: "Private-Information", DisplayName: "Private Information", RightsGUID: propertySetRightsGUIDPrivateInformation, ValidAccesses: propertySetAccesses, AppliesTo: []string{appliesToUser}},
+	{Name: "Public-Information", DisplayName: "Public Information", RightsGUID: propertySetRightsGUIDPublicInformation, ValidAccesses: propertySetAccesses, AppliesTo: []string{appliesToUser, appliesToContact}},
+	{Name: "RAS-Information", DisplayName: "Remote Access Information", RightsGUID: propertySetRightsGUIDRASInformation, ValidAccesses: propertySetAccesses, AppliesTo: []string{appliesToUser}},
+	{Name: "Terminal-Server-License-Server", DisplayName: "Terminal Server License Server", RightsGUID: propertySetRightsGUIDTerminalServerLicenseServer, ValidAccesses: propertySetAccesses, AppliesTo: []string{appliesToUser}},
+	{Name: "Web-Information", DisplayName: "Web Information", RightsGUID: propertySetRightsGUIDWebInformation, ValidAccesses: propertySetAccesses, AppliesTo: []string{appliesToUser, appliesToContact, appliesToGroup}},
+}
+
+// LookupExtendedRight returns the first built-in controlAccessRight matching the given rightsGuid,
+// common name or displayName. The comparison is case-insensitive. When a rightsGuid is shared by
+// several rights, use LookupExtendedRightForAccess to pick the one matching an access mask.
+//
+// Parameters:
+//   - nameOrGUID (string): The rightsGuid (D format), name or displayName of the right.
+//
+// Returns:
+//   - ExtendedRight: The matching right.
+//   - bool: true if the right was found, false otherwise.
+func LookupExtendedRight(nameOrGUID string) (ExtendedRight, bool) {
+	for _, extendedRight := range ExtendedRightsCatalog {
+		if strings.EqualFold(extendedRight.RightsGUID, nameOrGUID) ||
+			strings.EqualFold(extendedRight.Name, nameOrGUID) ||
+			strings.EqualFold(extendedRight.DisplayName, nameOrGUID) {
+			return extendedRight, true
+		}
+	}
+	return ExtendedRight{}, false
+}
+
+// LookupExtendedRightForAccess returns the built-in controlAccessRight with the given rightsGuid
+// that is valid for the access mask of the ACE referencing it. If no right with this rightsGuid
+// is valid for the mask, the first right with this rightsGuid is returned.
+//
+// Parameters:
+//   - rightsGUID (string): The rightsGuid of the right, in D format.
+//   - accessMask (uint32): The access mask of the ACE referencing the right.
+//
+// Returns:
+//   - ExtendedRight: The matching right.
+//   - bool: true if a right with this rightsGuid was found, false otherwise.
+func LookupExtendedRightForAccess(rightsGUID string, accessMask uint32) (ExtendedRight, bool) {
+	return selectExtendedRightForAccess(ExtendedRightsCatalog, rightsGUID, accessMask)
+}
+
+// selectExtendedRightForAccess picks, among the rights with the given rightsGuid, the first one valid
+// for the access mask, or the first one with the rightsGuid if none is.
+func selectExtendedRightForAccess(extendedRights []ExtendedRight, rightsGUID string, accessMask uint32) (ExtendedRight, bool) {
+	var fallback ExtendedRight
+	found := false
+	for _, extendedRight := range extendedRights {
+		if !strings.EqualFold(extendedRight.RightsGUID, rightsGUID) {
+			continue
+		}
+		if extendedRight.IsValidFor(accessMask) {
+			return extendedRight, true
+		}
+		if !found {
+			fallback, found = extendedRight, true
+		}
+	}
+	return fallback, found
+}
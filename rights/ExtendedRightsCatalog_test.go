@@ -0,0 +1,85 @@
+package rights_test
+
+import (
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/rights"
+)
+
+// TestExtendedRightsCatalog_CoversConstants verifies that every EXTENDED_RIGHT_* constant has a
+// structured record in the catalog.
+func TestExtendedRightsCatalog_CoversConstants(t *testing.T) {
+	for rightsGUID, name := range rights.GUIDToExtendedRight {
+		extendedRight, exists := rights.LookupExtendedRight(rightsGUID)
+		if !exists {
+			t.Errorf("%s (%s) has no record in ExtendedRightsCatalog", name, rightsGUID)
+			continue
+		}
+		if extendedRight.Kind() == rights.EXTENDED_RIGHT_KIND_UNKNOWN {
+			t.Errorf("%s has no validAccesses", extendedRight.Name)
+		}
+	}
+}
+
+func TestExtendedRightsCatalog_Kind(t *testing.T) {
+	testCases := []struct {
+		nameOrGUID string
+		expected   rights.ExtendedRightKind
+	}{
+		{rights.EXTENDED_RIGHT_DS_REPLICATION_GET_CHANGES, rights.EXTENDED_RIGHT_KIND_CONTROL_ACCESS},
+		{"Reset Password", rights.EXTENDED_RIGHT_KIND_CONTROL_ACCESS},
+		{rights.VALIDATED_WRITE_SPN, rights.EXTENDED_RIGHT_KIND_VALIDATED_WRITE},
+		{"self-membership", rights.EXTENDED_RIGHT_KIND_VALIDATED_WRITE},
+		{rights.EXTENDED_RIGHT_DS_VALIDATED_WRITE_COMPUTER, rights.EXTENDED_RIGHT_KIND_VALIDATED_WRITE},
+		{"4C164200-20C0-11D0-A768-00AA006E0529", rights.EXTENDED_RIGHT_KIND_PROPERTY_SET},
+	}
+
+	for _, testCase := range testCases {
+		extendedRight, exists := rights.LookupExtendedRight(testCase.nameOrGUID)
+		if !exists {
+			t.Errorf("LookupExtendedRight(%q) not found", testCase.nameOrGUID)
+			continue
+		}
+		if kind := extendedRight.Kind(); kind != testCase.expected {
+			t.Errorf("LookupExtendedRight(%q).Kind() = %s, want %s", testCase.nameOrGUID, kind, testCase.expected)
+		}
+	}
+
+	if _, exists := rights.LookupExtendedRight("00000000-0000-0000-0000-000000000000"); exists {
+		t.Errorf("LookupExtendedRight() found an unknown right")
+	}
+}
+
+// TestLookupExtendedRightForAccess verifies that a rightsGuid shared by a validated write and a
+// property set resolves according to the access mask.
+func TestLookupExtendedRightForAccess(t *testing.T) {
+	extendedRight, exists := rights.LookupExtendedRightForAccess(rights.VALIDATED_WRITE_DNS_HOST_NAME, rights.RIGHT_DS_WRITE_PROPERTY_EXTENDED)
+	if !exists || extendedRight.Name != "Validated-DNS-Host-Name" {
+		t.Errorf("LookupExtendedRightForAccess(SW) = %+v, %v", extendedRight, exists)
+	}
+
+	extendedRight, exists = rights.LookupExtendedRightForAccess(rights.VALIDATED_WRITE_DNS_HOST_NAME, rights.RIGHT_DS_READ_PROPERTY)
+	if !exists || extendedRight.Name != "DNS-Host-Name-Attributes" {
+		t.Errorf("LookupExtendedRightForAccess(RP) = %+v, %v", extendedRight, exists)
+	}
+
+	extendedRight, exists = rights.LookupExtendedRightForAccess(rights.EXTENDED_RIGHT_SEND_AS, rights.RIGHT_DS_READ_PROPERTY)
+	if !exists || extendedRight.Name != "Send-As" || extendedRight.IsValidFor(rights.RIGHT_DS_READ_PROPERTY) {
+		t.Errorf("LookupExtendedRightForAccess(Send-As, RP) = %+v, %v", extendedRight, exists)
+	}
+}
+
+func TestExtendedRight_AppliesToClass(t *testing.T) {
+	sendTo, _ := rights.LookupExtendedRight(rights.EXTENDED_RIGHT_SEND_TO)
+	if !sendTo.AppliesToClass("BF967A9C-0DE6-11D0-A285-00AA003049E2") {
+		t.Errorf("Send-To should apply to group")
+	}
+	if sendTo.AppliesToClass("bf967aba-0de6-11d0-a285-00aa003049e2") {
+		t.Errorf("Send-To should not apply to user")
+	}
+
+	setOwner, _ := rights.LookupExtendedRight(rights.EXTENDED_RIGHT_DS_SET_OWNER)
+	if !setOwner.AppliesToClass("bf967aba-0de6-11d0-a285-00aa003049e2") {
+		t.Errorf("a right without appliesTo should apply to every class")
+	}
+}
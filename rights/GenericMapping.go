@@ -0,0 +1,149 @@
+package rights
+
+// GENERIC_RIGHTS is the union of the four generic rights.
+const GENERIC_RIGHTS = RIGHT_GENERIC_READ | RIGHT_GENERIC_WRITE | RIGHT_GENERIC_EXECUTE | RIGHT_GENERIC_ALL
+
+// GenericMapping defines the specific and standard rights granted by each generic right on a
+// type of securable object, like the GENERIC_MAPPING structure of the Windows API.
+//
+// Fields:
+//   - GenericRead: The rights granted by GENERIC_READ.
+//   - GenericWrite: The rights granted by GENERIC_WRITE.
+//   - GenericExecute: The rights granted by GENERIC_EXECUTE.
+//   - GenericAll: The rights granted by GENERIC_ALL.
+type GenericMapping struct {
+	GenericRead    uint32
+	GenericWrite   uint32
+	GenericExecute uint32
+	GenericAll     uint32
+}
+
+// fileGenericMapping is the generic mapping of files, directories and named pipes.
+var fileGenericMapping = GenericMapping{
+	GenericRead:    0x00120089,
+	GenericWrite:   0x00120116,
+	GenericExecute: 0x001200A0,
+	GenericAll:     0x001F01FF,
+}
+
+// GenericMappings maps each namespace to the generic mapping of its object type.
+// Sources:
+//   - https://learn.microsoft.com/en-us/windows/win32/secauthz/generic-access-rights
+//   - https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-adts/990fb975-ab31-4bc1-8b75-5da132cd4584
+var GenericMappings = map[RightsNamespace]GenericMapping{
+	RIGHTS_NAMESPACE_DIRECTORY_SERVICE: {
+		GenericRead:    RIGHT_READ_CONTROL | RIGHT_DS_LIST_CONTENTS | RIGHT_DS_READ_PROPERTY | RIGHT_DS_LIST_OBJECT,
+		GenericWrite:   RIGHT_READ_CONTROL | RIGHT_DS_WRITE_PROPERTY_EXTENDED | RIGHT_DS_WRITE_PROPERTY,
+		GenericExecute: RIGHT_READ_CONTROL | RIGHT_DS_LIST_CONTENTS,
+		GenericAll:     0x000F01FF,
+	},
+	RIGHTS_NAMESPACE_FILE:       fileGenericMapping,
+	RIGHTS_NAMESPACE_DIRECTORY:  fileGenericMapping,
+	RIGHTS_NAMESPACE_NAMED_PIPE: fileGenericMapping,
+	RIGHTS_NAMESPACE_REGISTRY_KEY: {
+		GenericRead:    0x00020019,
+		GenericWrite:   0x00020006,
+		GenericExecute: 0x00020019,
+		GenericAll:     0x000F003F,
+	},
+	RIGHTS_NAMESPACE_SERVICE: {
+		GenericRead:    0x0002008D,
+		GenericWrite:   0x00020002,
+		GenericExecute: 0x00020170,
+		GenericAll:     0x000F01FF,
+	},
+	RIGHTS_NAMESPACE_SERVICE_CONTROL_MANAGER: {
+		GenericRead:    0x00020014,
+		GenericWrite:   0x00020022,
+		GenericExecute: 0x00020009,
+		GenericAll:     0x000F003F,
+	},
+	RIGHTS_NAMESPACE_PROCESS: {
+		GenericRead:    0x00020410,
+		GenericWrite:   0x00020BEA,
+		GenericExecute: 0x00121000,
+		GenericAll:     0x001FFFFF,
+	},
+	RIGHTS_NAMESPACE_THREAD: {
+		GenericRead:    0x00020048,
+		GenericWrite:   0x00020437,
+		GenericExecute: 0x00121800,
+		GenericAll:     0x001FFFFF,
+	},
+	RIGHTS_NAMESPACE_TOKEN: {
+		GenericRead:    0x00020008,
+		GenericWrite:   0x000200E0,
+		GenericExecute: 0x00020000,
+		GenericAll:     0x000F01FF,
+	},
+	RIGHTS_NAMESPACE_SECTION: {
+		GenericRead:    0x00020005,
+		GenericWrite:   0x00020002,
+		GenericExecute: 0x00020008,
+		GenericAll:     0x000F001F,
+	},
+	RIGHTS_NAMESPACE_EVENT: {
+		GenericRead:    0x00020001,
+		GenericWrite:   0x00020002,
+		GenericExecute: 0x00100000,
+		GenericAll:     0x001F0003,
+	},
+	RIGHTS_NAMESPACE_DESKTOP: {
+		GenericRead:    0x00020041,
+		GenericWrite:   0x000200BE,
+		GenericExecute: 0x00020100,
+		GenericAll:     0x000F01FF,
+	},
+	RIGHTS_NAMESPACE_WINDOW_STATION: {
+		GenericRead:    0x00020303,
+		GenericWrite:   0x0002001C,
+		GenericExecute: 0x00020060,
+		GenericAll:     0x000F037F,
+	},
+}
+
+// GenericMapping returns the generic mapping of the object type of the namespace.
+//
+// Returns:
+//   - GenericMapping: The generic mapping of the namespace, or the zero mapping if the namespace is unknown.
+func (namespace RightsNamespace) GenericMapping() GenericMapping {
+	return GenericMappings[namespace]
+}
+
+// MapGenericMask maps the generic rights of an access mask to the rights of the namespace.
+//
+// Parameters:
+//   - accessMask (uint32): The access mask to map.
+//
+// Returns:
+//   - uint32: The access mask without generic rights, see MapGenericMask.
+func (namespace RightsNamespace) MapGenericMask(accessMask uint32) uint32 {
+	return MapGenericMask(accessMask, namespace.GenericMapping())
+}
+
+// MapGenericMask replaces the generic rights of an access mask with the specific and standard
+// rights they grant according to a generic mapping, like the MapGenericMask function of the
+// Windows API. The other bits of the mask are kept.
+//
+// Parameters:
+//   - accessMask (uint32): The access mask to map.
+//   - mapping (GenericMapping): The generic mapping of the object type the mask applies to.
+//
+// Returns:
+//   - uint32: The access mask with the generic rights replaced by the rights they grant.
+func MapGenericMask(accessMask uint32, mapping GenericMapping) uint32 {
+	mappedMask := accessMask &^ GENERIC_RIGHTS
+	if accessMask&RIGHT_GENERIC_READ != 0 {
+		mappedMask |= mapping.GenericRead
+	}
+	if accessMask&RIGHT_GENERIC_WRITE != 0 {
+		mappedMask |= mapping.GenericWrite
+	}
+	if accessMask&RIGHT_GENERIC_EXECUTE != 0 {
+		mappedMask |= mapping.GenericExecute
+	}
+	if accessMask&RIGHT_GENERIC_ALL != 0 {
+		mappedMask |= mapping.GenericAll
+	}
+	return mappedMask
+}
@@ -0,0 +1,53 @@
+package rights_test
+
+import (
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/rights"
+)
+
+func TestMapGenericMask(t *testing.T) {
+	tests := []struct {
+		name       string
+		namespace  rights.RightsNamespace
+		accessMask uint32
+		expected   uint32
+	}{
+		{"Directory service GENERIC_ALL", rights.RIGHTS_NAMESPACE_DIRECTORY_SERVICE, rights.RIGHT_GENERIC_ALL, 0x000F01FF},
+		{"Directory service GENERIC_READ", rights.RIGHTS_NAMESPACE_DIRECTORY_SERVICE, rights.RIGHT_GENERIC_READ, 0x00020094},
+		{"Directory service GENERIC_WRITE keeps specific bits", rights.RIGHTS_NAMESPACE_DIRECTORY_SERVICE, rights.RIGHT_GENERIC_WRITE | rights.RIGHT_DS_CONTROL_ACCESS, 0x00020128},
+		{"File GENERIC_READ|GENERIC_EXECUTE", rights.RIGHTS_NAMESPACE_FILE, rights.RIGHT_GENERIC_READ | rights.RIGHT_GENERIC_EXECUTE, 0x001200A9},
+		{"Registry key GENERIC_WRITE", rights.RIGHTS_NAMESPACE_REGISTRY_KEY, rights.RIGHT_GENERIC_WRITE, 0x00020006},
+		{"Service GENERIC_EXECUTE", rights.RIGHTS_NAMESPACE_SERVICE, rights.RIGHT_GENERIC_EXECUTE, 0x00020170},
+		{"Process GENERIC_ALL", rights.RIGHTS_NAMESPACE_PROCESS, rights.RIGHT_GENERIC_ALL, 0x001FFFFF},
+		{"No generic rights", rights.RIGHTS_NAMESPACE_FILE, 0x00000001 | rights.RIGHT_MAXIMUM_ALLOWED, 0x02000001},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.namespace.MapGenericMask(tt.accessMask); got != tt.expected {
+				t.Errorf("MapGenericMask(0x%08x) = 0x%08x, want 0x%08x", tt.accessMask, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGenericMappings(t *testing.T) {
+	for namespace, definition := range rights.RightsNamespaces {
+		mapping, exists := rights.GenericMappings[namespace]
+		if !exists {
+			t.Errorf("no generic mapping for namespace %s", definition.Name)
+			continue
+		}
+		for _, value := range []uint32{mapping.GenericRead, mapping.GenericWrite, mapping.GenericExecute} {
+			if value&rights.GENERIC_RIGHTS != 0 || value&^mapping.GenericAll != 0 {
+				t.Errorf("generic mapping of %s: 0x%08x is not contained in GenericAll 0x%08x", definition.Name, value, mapping.GenericAll)
+			}
+		}
+	}
+
+	custom := rights.GenericMapping{GenericRead: 0x1, GenericWrite: 0x2, GenericExecute: 0x4, GenericAll: 0x7}
+	if got := rights.MapGenericMask(rights.RIGHT_GENERIC_READ|rights.RIGHT_GENERIC_EXECUTE, custom); got != 0x5 {
+		t.Errorf("MapGenericMask() with a custom mapping = 0x%08x, want 0x00000005", got)
+	}
+}
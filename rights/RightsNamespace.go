@@ -0,0 +1,420 @@
+package rights
+
+import (
+	"sort"
+	"strings"
+)
+
+// Standard rights shared by every securable object type, in addition to RIGHT_DELETE,
+// RIGHT_READ_CONTROL, RIGHT_WRITE_DAC, RIGHT_WRITE_OWNER and the generic rights.
+const (
+	RIGHT_SYNCHRONIZE            = uint32(0x00100000)
+	RIGHT_ACCESS_SYSTEM_SECURITY = uint32(0x01000000)
+	RIGHT_MAXIMUM_ALLOWED        = uint32(0x02000000)
+)
+
+// RightsNamespace identifies the type of securable object an access mask applies to. The low
+// 16 bits of a mask are object-specific, so the same value means different rights on a file, a
+// registry key or a directory service object.
+type RightsNamespace int
+
+const (
+	// RIGHTS_NAMESPACE_DIRECTORY_SERVICE is the namespace of Active Directory objects. It is the zero
+	// value, so masks without an explicit namespace are decoded with the DS rights table.
+	RIGHTS_NAMESPACE_DIRECTORY_SERVICE RightsNamespace = iota
+	RIGHTS_NAMESPACE_FILE
+	RIGHTS_NAMESPACE_DIRECTORY
+	RIGHTS_NAMESPACE_REGISTRY_KEY
+	RIGHTS_NAMESPACE_SERVICE
+	RIGHTS_NAMESPACE_SERVICE_CONTROL_MANAGER
+	RIGHTS_NAMESPACE_PROCESS
+	RIGHTS_NAMESPACE_THREAD
+	RIGHTS_NAMESPACE_TOKEN
+	RIGHTS_NAMESPACE_NAMED_PIPE
+	RIGHTS_NAMESPACE_SECTION
+	RIGHTS_NAMESPACE_EVENT
+	RIGHTS_NAMESPACE_DESKTOP
+	RIGHTS_NAMESPACE_WINDOW_STATION
+)
+
+// NamedRight associates the name of a right with its access mask value.
+type NamedRight struct {
+	Name  string
+	Value uint32
+}
+
+// RightsNamespaceDefinition describes the rights of a namespace.
+//
+// Fields:
+//   - Name: The name of the namespace (e.g. "FILE").
+//   - Rights: The individual rights of the namespace, including the standard and generic rights.
+//   - CompositeRights: The named combinations of rights (e.g. FILE_GENERIC_READ), recognised when
+//     all of their bits are set in a mask.
+type RightsNamespaceDefinition struct {
+	Name            string
+	Rights          []NamedRight
+	CompositeRights []NamedRight
+}
+
+// standardRights are the standard and generic rights common to the non-DS namespaces.
+var standardRights = []NamedRight{
+	{"DELETE", RIGHT_DELETE},
+	{"READ_CONTROL", RIGHT_READ_CONTROL},
+	{"WRITE_DAC", RIGHT_WRITE_DAC},
+	{"WRITE_OWNER", RIGHT_WRITE_OWNER},
+	{"SYNCHRONIZE", RIGHT_SYNCHRONIZE},
+	{"ACCESS_SYSTEM_SECURITY", RIGHT_ACCESS_SYSTEM_SECURITY},
+	{"MAXIMUM_ALLOWED", RIGHT_MAXIMUM_ALLOWED},
+	{"GENERIC_ALL", RIGHT_GENERIC_ALL},
+	{"GENERIC_EXECUTE", RIGHT_GENERIC_EXECUTE},
+	{"GENERIC_WRITE", RIGHT_GENERIC_WRITE},
+	{"GENERIC_READ", RIGHT_GENERIC_READ},
+}
+
+// fileCompositeRights are the composite rights of files, directories and named pipes.
+var fileCompositeRights = []NamedRight{
+	{"FILE_ALL_ACCESS", 0x001F01FF},
+	{"FILE_GENERIC_READ", 0x00120089},
+	{"FILE_GENERIC_WRITE", 0x00120116},
+	{"FILE_GENERIC_EXECUTE", 0x001200A0},
+}
+
+// withStandardRights appends the standard and generic rights to object-specific rights.
+func withStandardRights(specificRights ...NamedRight) []NamedRight {
+	return append(specificRights, standardRights...)
+}
+
+// RightsNamespaces maps each namespace to the definition of its rights.
+// Sources: https://learn.microsoft.com/en-us/windows/win32/secauthz/access-rights-and-access-masks
+var RightsNamespaces = map[RightsNamespace]RightsNamespaceDefinition{
+	RIGHTS_NAMESPACE_DIRECTORY_SERVICE: {
+		Name: "DIRECTORY_SERVICE",
+		// Filled from RightValueToRightName in init, so that DS masks decode exactly as before.
+	},
+	RIGHTS_NAMESPACE_FILE: {
+		Name: "FILE",
+		Rights: withStandardRights(
+			NamedRight{"FILE_READ_DATA", 0x00000001},
+			NamedRight{"FILE_WRITE_DATA", 0x00000002},
+			NamedRight{"FILE_APPEND_DATA", 0x00000004},
+			NamedRight{"FILE_READ_EA", 0x00000008},
+			NamedRight{"FILE_WRITE_EA", 0x00000010},
+			NamedRight{"FILE_EXECUTE", 0x00000020},
+			NamedRight{"FILE_DELETE_CHILD", 0x00000040},
+			NamedRight{"FILE_READ_ATTRIBUTES", 0x00000080},
+			NamedRight{"FILE_WRITE_ATTRIBUTES", 0x00000100},
+		),
+		CompositeRights: fileCompositeRights,
+	},
+	RIGHTS_NAMESPACE_DIRECTORY: {
+		Name: "DIRECTORY",
+		Rights: withStandardRights(
+			NamedRight{"FILE_LIST_DIRECTORY", 0x00000001},
+			NamedRight{"FILE_ADD_FILE", 0x00000002},
+			NamedRight{"FILE_ADD_SUBDIRECTORY", 0x00000004},
+			NamedRight{"FILE_READ_EA", 0x00000008},
+			NamedRight{"FILE_WRITE_EA", 0x00000010},
+			NamedRight{"FILE_TRAVERSE", 0x00000020},
+			NamedRight{"FILE_DELETE_CHILD", 0x00000040},
+			NamedRight{"FILE_READ_ATTRIBUTES", 0x00000080},
+			NamedRight{"FILE_WRITE_ATTRIBUTES", 0x00000100},
+		),
+		CompositeRights: fileCompositeRights,
+	},
+	RIGHTS_NAMESPACE_REGISTRY_KEY: {
+		Name: "REGISTRY_KEY",
+		Rights: withStandardRights(
+			NamedRight{"KEY_QUERY_VALUE", 0x00000001},
+			NamedRight{"KEY_SET_VALUE", 0x00000002},
+			NamedRight{"KEY_CREATE_SUB_KEY", 0x00000004},
+			NamedRight{"KEY_ENUMERATE_SUB_KEYS", 0x00000008},
+			NamedRight{"KEY_NOTIFY", 0x00000010},
+			NamedRight{"KEY_CREATE_LINK", 0x00000020},
+			NamedRight{"KEY_WOW64_64KEY", 0x00000100},
+			NamedRight{"KEY_WOW64_32KEY", 0x00000200},
+		),
+		// KEY_EXECUTE has the same value as KEY_READ and is reported as KEY_READ.
+		CompositeRights: []NamedRight{
+			{"KEY_ALL_ACCESS", 0x000F003F},
+			{"KEY_READ", 0x00020019},
+			{"KEY_WRITE", 0x00020006},
+		},
+	},
+	RIGHTS_NAMESPACE_SERVICE: {
+		Name: "SERVICE",
+		Rights: withStandardRights(
+			NamedRight{"SERVICE_QUERY_CONFIG", 0x00000001},
+			NamedRight{"SERVICE_CHANGE_CONFIG", 0x00000002},
+			NamedRight{"SERVICE_QUERY_STATUS", 0x00000004},
+			NamedRight{"SERVICE_ENUMERATE_DEPENDENTS", 0x00000008},
+			NamedRight{"SERVICE_START", 0x00000010},
+			NamedRight{"SERVICE_STOP", 0x00000020},
+			NamedRight{"SERVICE_PAUSE_CONTINUE", 0x00000040},
+			NamedRight{"SERVICE_INTERROGATE", 0x00000080},
+			NamedRight{"SERVICE_USER_DEFINED_CONTROL", 0x00000100},
+		),
+		CompositeRights: []NamedRight{
+			{"SERVICE_ALL_ACCESS", 0x000F01FF},
+		},
+	},
+	RIGHTS_NAMESPACE_SERVICE_CONTROL_MANAGER: {
+		Name: "SERVICE_CONTROL_MANAGER",
+		Rights: withStandardRights(
+			NamedRight{"SC_MANAGER_CONNECT", 0x00000001},
+			NamedRight{"SC_MANAGER_CREATE_SERVICE", 0x00000002},
+			NamedRight{"SC_MANAGER_ENUMERATE_SERVICE", 0x00000004},
+			NamedRight{"SC_MANAGER_LOCK", 0x00000008},
+			NamedRight{"SC_MANAGER_QUERY_LOCK_STATUS", 0x00000010},
+			NamedRight{"SC_MANAGER_MODIFY_BOOT_CONFIG", 0x00000020},
+		),
+		CompositeRights: []NamedRight{
+			{"SC_MANAGER_ALL_ACCESS", 0x000F003F},
+		},
+	},
+	RIGHTS_NAMESPACE_PROCESS: {
+		Name: "PROCESS",
+		Rights: withStandardRights(
+			NamedRight{"PROCESS_TERMINATE", 0x00000001},
+			NamedRight{"PROCESS_CREATE_THREAD", 0x00000002},
+			NamedRight{"PROCESS_SET_SESSIONID", 0x00000004},
+			NamedRight{"PROCESS_VM_OPERATION", 0x00000008},
+			NamedRight{"PROCESS_VM_READ", 0x00000010},
+			NamedRight{"PROCESS_VM_WRITE", 0x00000020},
+			NamedRight{"PROCESS_DUP_HANDLE", 0x00000040},
+			NamedRight{"PROCESS_CREATE_PROCESS", 0x00000080},
+			NamedRight{"PROCESS_SET_QUOTA", 0x00000100},
+			NamedRight{"PROCESS_SET_INFORMATION", 0x00000200},
+			NamedRight{"PROCESS_QUERY_INFORMATION", 0x00000400},
+			NamedRight{"PROCESS_SUSPEND_RESUME", 0x00000800},
+			NamedRight{"PROCESS_QUERY_LIMITED_INFORMATION", 0x00001000},
+			NamedRight{"PROCESS_SET_LIMITED_INFORMATION", 0x00002000},
+		),
+		CompositeRights: []NamedRight{
+			{"PROCESS_ALL_ACCESS", 0x001FFFFF},
+		},
+	},
+	RIGHTS_NAMESPACE_THREAD: {
+		Name: "THREAD",
+		Rights: withStandardRights(
+			NamedRight{"THREAD_TERMINATE", 0x00000001},
+			NamedRight{"THREAD_SUSPEND_RESUME", 0x00000002},
+			NamedRight{"THREAD_GET_CONTEXT", 0x00000008},
+			NamedRight{"THREAD_SET_CONTEXT", 0x00000010},
+			NamedRight{"THREAD_SET_INFORMATION", 0x00000020},
+			NamedRight{"THREAD_QUERY_INFORMATION", 0x00000040},
+			NamedRight{"THREAD_SET_THREAD_TOKEN", 0x00000080},
+			NamedRight{"THREAD_IMPERSONATE", 0x00000100},
+			NamedRight{"THREAD_DIRECT_IMPERSONATION", 0x00000200},
+			NamedRight{"THREAD_SET_LIMITED_INFORMATION", 0x00000400},
+			NamedRight{"THREAD_QUERY_LIMITED_INFORMATION", 0x00000800},
+			NamedRight{"THREAD_RESUME", 0x00001000},
+		),
+		CompositeRights: []NamedRight{
+			{"THREAD_ALL_ACCESS", 0x001FFFFF},
+		},
+	},
+	RIGHTS_NAMESPACE_TOKEN: {
+		Name: "TOKEN",
+		Rights: withStandardRights(
+			NamedRight{"TOKEN_ASSIGN_PRIMARY", 0x00000001},
+			NamedRight{"TOKEN_DUPLICATE", 0x00000002},
+			NamedRight{"TOKEN_IMPERSONATE", 0x00000004},
+			NamedRight{"TOKEN_QUERY", 0x00000008},
+			NamedRight{"TOKEN_QUERY_SOURCE", 0x00000010},
+			NamedRight{"TOKEN_ADJUST_PRIVILEGES", 0x00000020},
+			NamedRight{"TOKEN_ADJUST_GROUPS", 0x00000040},
+			NamedRight{"TOKEN_ADJUST_DEFAULT", 0x00000080},
+			NamedRight{"TOKEN_ADJUST_SESSIONID", 0x00000100},
+		),
+		// TOKEN_EXECUTE has the same value as READ_CONTROL and is not listed.
+		CompositeRights: []NamedRight{
+			{"TOKEN_ALL_ACCESS", 0x000F01FF},
+			{"TOKEN_READ", 0x00020008},
+			{"TOKEN_WRITE", 0x000200E0},
+		},
+	},
+	RIGHTS_NAMESPACE_NAMED_PIPE: {
+		Name: "NAMED_PIPE",
+		Rights: withStandardRights(
+			NamedRight{"FILE_READ_DATA", 0x00000001},
+			NamedRight{"FILE_WRITE_DATA", 0x00000002},
+			NamedRight{"FILE_CREATE_PIPE_INSTANCE", 0x00000004},
+			NamedRight{"FILE_READ_EA", 0x00000008},
+			NamedRight{"FILE_WRITE_EA", 0x00000010},
+			NamedRight{"FILE_EXECUTE", 0x00000020},
+			NamedRight{"FILE_READ_ATTRIBUTES", 0x00000080},
+			NamedRight{"FILE_WRITE_ATTRIBUTES", 0x00000100},
+		),
+		CompositeRights: fileCompositeRights,
+	},
+	RIGHTS_NAMESPACE_SECTION: {
+		Name: "SECTION",
+		Rights: withStandardRights(
+			NamedRight{"SECTION_QUERY", 0x00000001},
+			NamedRight{"SECTION_MAP_WRITE", 0x00000002},
+			NamedRight{"SECTION_MAP_READ", 0x00000004},
+			NamedRight{"SECTION_MAP_EXECUTE", 0x00000008},
+			NamedRight{"SECTION_EXTEND_SIZE", 0x00000010},
+			NamedRight{"SECTION_MAP_EXECUTE_EXPLICIT", 0x00000020},
+		),
+		CompositeRights: []NamedRight{
+			{"SECTION_ALL_ACCESS", 0x000F001F},
+		},
+	},
+	RIGHTS_NAMESPACE_EVENT: {
+		Name: "EVENT",
+		Rights: withStandardRights(
+			NamedRight{"EVENT_QUERY_STATE", 0x00000001},
+			NamedRight{"EVENT_MODIFY_STATE", 0x00000002},
+		),
+		CompositeRights: []NamedRight{
+			{"EVENT_ALL_ACCESS", 0x001F0003},
+		},
+	},
+	RIGHTS_NAMESPACE_DESKTOP: {
+		Name: "DESKTOP",
+		Rights: withStandardRights(
+			NamedRight{"DESKTOP_READOBJECTS", 0x00000001},
+			NamedRight{"DESKTOP_CREATEWINDOW", 0x00000002},
+			NamedRight{"DESKTOP_CREATEMENU", 0x00000004},
+			NamedRight{"DESKTOP_HOOKCONTROL", 0x00000008},
+			NamedRight{"DESKTOP_JOURNALRECORD", 0x00000010},
+			NamedRight{"DESKTOP_JOURNALPLAYBACK", 0x00000020},
+			NamedRight{"DESKTOP_ENUMERATE", 0x00000040},
+			NamedRight{"DESKTOP_WRITEOBJECTS", 0x00000080},
+			NamedRight{"DESKTOP_SWITCHDESKTOP", 0x00000100},
+		),
+	},
+	RIGHTS_NAMESPACE_WINDOW_STATION: {
+		Name: "WINDOW_STATION",
+		Rights: withStandardRights(
+			NamedRight{"WINSTA_ENUMDESKTOPS", 0x00000001},
+			NamedRight{"WINSTA_READATTRIBUTES", 0x00000002},
+			NamedRight{"WINSTA_ACCESSCLIPBOARD", 0x00000004},
+			NamedRight{"WINSTA_CREATEDESKTOP", 0x00000008},
+			NamedRight{"WINSTA_WRITEATTRIBUTES", 0x00000010},
+			NamedRight{"WINSTA_ACCESSGLOBALATOMS", 0x00000020},
+			NamedRight{"WINSTA_EXITWINDOWS", 0x00000040},
+			NamedRight{"WINSTA_ENUMERATE", 0x00000100},
+			NamedRight{"WINSTA_READSCREEN", 0x00000200},
+		),
+		CompositeRights: []NamedRight{
+			{"WINSTA_ALL_ACCESS", 0x0000037F},
+		},
+	},
+}
+
+func init() {
+	definition := RightsNamespaces[RIGHTS_NAMESPACE_DIRECTORY_SERVICE]
+	for value, name := range RightValueToRightName {
+		definition.Rights = append(definition.Rights, NamedRight{Name: name, Value: value})
+	}
+	RightsNamespaces[RIGHTS_NAMESPACE_DIRECTORY_SERVICE] = definition
+}
+
+// String returns the name of the namespace.
+//
+// Returns:
+//   - string: The name of the namespace (e.g. "FILE"), or "?" if the namespace is unknown.
+func (namespace RightsNamespace) String() string {
+	if definition, exists := RightsNamespaces[namespace]; exists {
+		return definition.Name
+	}
+	return "?"
+}
+
+// LookupRightsNamespace returns the namespace with the given name. The comparison is case-insensitive.
+//
+// Parameters:
+//   - name (string): The name of the namespace (e.g. "registry_key").
+//
+// Returns:
+//   - RightsNamespace: The matching namespace.
+//   - bool: true if the namespace was found, false otherwise.
+func LookupRightsNamespace(name string) (RightsNamespace, bool) {
+	for namespace, definition := range RightsNamespaces {
+		if strings.EqualFold(definition.Name, name) {
+			return namespace, true
+		}
+	}
+	return RIGHTS_NAMESPACE_DIRECTORY_SERVICE, false
+}
+
+// RightName returns the name of a right or composite right of the namespace.
+//
+// Parameters:
+//   - value (uint32): The access mask value of the right.
+//
+// Returns:
+//   - string: The name of the right.
+//   - bool: true if the namespace defines a right with this value, false otherwise.
+func (namespace RightsNamespace) RightName(value uint32) (string, bool) {
+	definition := RightsNamespaces[namespace]
+	for _, compositeRight := range definition.CompositeRights {
+		if compositeRight.Value == value {
+			return compositeRight.Name, true
+		}
+	}
+	for _, right := range definition.Rights {
+		if right.Value == value {
+			return right.Name, true
+		}
+	}
+	return "", false
+}
+
+// Decode splits an access mask into the names and values of the rights of the namespace.
+// Composite rights whose bits are all set are reported first, unless they are contained in a
+// larger matching composite right; individual rights are then reported for the bits not covered
+// by a composite right. Names are sorted alphabetically within each group.
+//
+// Parameters:
+//   - accessMask (uint32): The access mask to decode.
+//
+// Returns:
+//   - []string: The names of the rights.
+//   - []uint32: The values of the rights, in the same order as the names.
+func (namespace RightsNamespace) Decode(accessMask uint32) ([]string, []uint32) {
+	definition := RightsNamespaces[namespace]
+
+	matchedComposites := make([]NamedRight, 0)
+	for _, compositeRight := range definition.CompositeRights {
+		if accessMask&compositeRight.Value == compositeRight.Value {
+			matchedComposites = append(matchedComposites, compositeRight)
+		}
+	}
+
+	covered := uint32(0)
+	composites := make([]NamedRight, 0, len(matchedComposites))
+	for _, compositeRight := range matchedComposites {
+		contained := false
+		for _, other := range matchedComposites {
+			if other.Value != compositeRight.Value && other.Value&compositeRight.Value == compositeRight.Value {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			composites = append(composites, compositeRight)
+			covered |= compositeRight.Value
+		}
+	}
+
+	individualRights := make([]NamedRight, 0)
+	for _, right := range definition.Rights {
+		if accessMask&right.Value == right.Value && covered&right.Value != right.Value {
+			individualRights = append(individualRights, right)
+		}
+	}
+
+	names := make([]string, 0, len(composites)+len(individualRights))
+	values := make([]uint32, 0, len(composites)+len(individualRights))
+	for _, group := range [][]NamedRight{composites, individualRights} {
+		sort.Slice(group, func(i, j int) bool { return group[i].Name < group[j].Name })
+		for _, right := range group {
+			names = append(names, right.Name)
+			values = append(values, right.Value)
+		}
+	}
+	return names, values
+}
@@ -0,0 +1,102 @@
+package rights_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/rights"
+)
+
+func TestRightsNamespace_Decode(t *testing.T) {
+	testCases := []struct {
+		name      string
+		namespace rights.RightsNamespace
+		mask      uint32
+		expected  []string
+	}{
+		{
+			name:      "File read and execute",
+			namespace: rights.RIGHTS_NAMESPACE_FILE,
+			mask:      0x001200A9,
+			expected:  []string{"FILE_GENERIC_EXECUTE", "FILE_GENERIC_READ"},
+		},
+		{
+			name:      "File full control hides the contained composite rights",
+			namespace: rights.RIGHTS_NAMESPACE_FILE,
+			mask:      0x001F01FF,
+			expected:  []string{"FILE_ALL_ACCESS"},
+		},
+		{
+			name:      "Directory specific rights",
+			namespace: rights.RIGHTS_NAMESPACE_DIRECTORY,
+			mask:      0x00000025,
+			expected:  []string{"FILE_ADD_SUBDIRECTORY", "FILE_LIST_DIRECTORY", "FILE_TRAVERSE"},
+		},
+		{
+			name:      "Registry key read plus set value",
+			namespace: rights.RIGHTS_NAMESPACE_REGISTRY_KEY,
+			mask:      0x0002001B,
+			expected:  []string{"KEY_READ", "KEY_SET_VALUE"},
+		},
+		{
+			name:      "Service all access",
+			namespace: rights.RIGHTS_NAMESPACE_SERVICE,
+			mask:      0x000F01FF,
+			expected:  []string{"SERVICE_ALL_ACCESS"},
+		},
+		{
+			name:      "Token query with a generic right",
+			namespace: rights.RIGHTS_NAMESPACE_TOKEN,
+			mask:      0x80000008,
+			expected:  []string{"GENERIC_READ", "TOKEN_QUERY"},
+		},
+		{
+			name:      "Directory service rights are unchanged",
+			namespace: rights.RIGHTS_NAMESPACE_DIRECTORY_SERVICE,
+			mask:      0x001200A9,
+			expected:  []string{"DS_CREATE_CHILD", "DS_LIST_OBJECT", "DS_WRITE_PROPERTY", "DS_WRITE_PROPERTY_EXTENDED", "READ_CONTROL"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			names, values := testCase.namespace.Decode(testCase.mask)
+			if !slices.Equal(names, testCase.expected) {
+				t.Errorf("Decode(0x%08x) = %v, want %v", testCase.mask, names, testCase.expected)
+			}
+			if len(values) != len(names) {
+				t.Errorf("Decode(0x%08x) returned %d values for %d names", testCase.mask, len(values), len(names))
+			}
+		})
+	}
+}
+
+func TestRightsNamespace_RightName(t *testing.T) {
+	if name, exists := rights.RIGHTS_NAMESPACE_PROCESS.RightName(0x00000010); !exists || name != "PROCESS_VM_READ" {
+		t.Errorf("RightName(0x10) = %q, %v", name, exists)
+	}
+	if name, exists := rights.RIGHTS_NAMESPACE_FILE.RightName(0x00120089); !exists || name != "FILE_GENERIC_READ" {
+		t.Errorf("RightName(0x120089) = %q, %v", name, exists)
+	}
+	if _, exists := rights.RIGHTS_NAMESPACE_EVENT.RightName(0x00000004); exists {
+		t.Errorf("RightName(0x4) should not exist for events")
+	}
+}
+
+func TestLookupRightsNamespace(t *testing.T) {
+	for namespace, definition := range rights.RightsNamespaces {
+		found, exists := rights.LookupRightsNamespace(definition.Name)
+		if !exists || found != namespace {
+			t.Errorf("LookupRightsNamespace(%q) = %v, %v", definition.Name, found, exists)
+		}
+		if namespace.String() != definition.Name {
+			t.Errorf("String() = %q, want %q", namespace.String(), definition.Name)
+		}
+	}
+	if namespace, exists := rights.LookupRightsNamespace("registry_key"); !exists || namespace != rights.RIGHTS_NAMESPACE_REGISTRY_KEY {
+		t.Errorf("LookupRightsNamespace(registry_key) = %v, %v", namespace, exists)
+	}
+	if _, exists := rights.LookupRightsNamespace("printer"); exists {
+		t.Errorf("LookupRightsNamespace(printer) should not exist")
+	}
+}
@@ -0,0 +1,42 @@
+package rights_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/rights"
+)
+
+// TestRights_ConcurrentLookups reads the global maps of the package from many goroutines. The
+// maps are only written by their initializers, run with `go test -race` to check it.
+func TestRights_ConcurrentLookups(t *testing.T) {
+	namespaces := []rights.RightsNamespace{}
+	for namespace := range rights.RightsNamespaces {
+		namespaces = append(namespaces, namespace)
+	}
+
+	waitGroup := sync.WaitGroup{}
+	for worker := 0; worker < 8; worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for iteration := 0; iteration < 100; iteration++ {
+				for _, namespace := range namespaces {
+					names, values := namespace.Decode(0x001f01ff)
+					if len(names) != len(values) {
+						t.Errorf("Decode() returned %d names for %d values", len(names), len(values))
+					}
+					_ = namespace.MapGenericMask(0x10000000)
+					if _, found := rights.LookupRightsNamespace(namespace.String()); !found {
+						t.Errorf("LookupRightsNamespace(%s) not found", namespace.String())
+					}
+				}
+				if _, found := rights.LookupExtendedRight("User-Force-Change-Password"); !found {
+					t.Errorf("LookupExtendedRight() not found")
+				}
+				_, _ = rights.LookupExtendedRightForAccess("00299570-246d-11d0-a768-00aa006e0529", 0x100)
+			}
+		}()
+	}
+	waitGroup.Wait()
+}
@@ -0,0 +1,279 @@
+package schema
+
+import (
+	"sort"
+	"strings"
+)
+
+// searchFlags bits of an attributeSchema object ([MS-ADTS] 2.2.10).
+const (
+	SEARCH_FLAG_ATTINDEX              uint32 = 0x00000001 // fATTINDEX: the attribute is indexed.
+	SEARCH_FLAG_PDNTATTINDEX          uint32 = 0x00000002 // fPDNTATTINDEX: container-scoped index.
+	SEARCH_FLAG_ANR                   uint32 = 0x00000004 // fANR: the attribute is part of the ambiguous name resolution set.
+	SEARCH_FLAG_PRESERVEONDELETE      uint32 = 0x00000008 // fPRESERVEONDELETE: the attribute is kept on tombstones.
+	SEARCH_FLAG_COPY                  uint32 = 0x00000010 // fCOPY: the attribute is copied when duplicating a user.
+	SEARCH_FLAG_TUPLEINDEX            uint32 = 0x00000020 // fTUPLEINDEX: tuple index for medial searches.
+	SEARCH_FLAG_SUBTREEATTINDEX       uint32 = 0x00000040 // fSUBTREEATTINDEX: subtree index for VLV searches.
+	SEARCH_FLAG_CONFIDENTIAL          uint32 = 0x00000080 // fCONFIDENTIAL: reading requires CONTROL_ACCESS in addition to READ_PROPERTY.
+	SEARCH_FLAG_NEVERVALUEAUDIT       uint32 = 0x00000100 // fNEVERVALUEAUDIT: value changes are not audited.
+	SEARCH_FLAG_RODCFILTEREDATTRIBUTE uint32 = 0x00000200 // fRODCFilteredAttribute: the attribute is not replicated to RODCs.
+	SEARCH_FLAG_EXTENDEDLINKTRACKING  uint32 = 0x00000400 // fEXTENDEDLINKTRACKING: extended link tracking.
+	SEARCH_FLAG_BASEONLY              uint32 = 0x00000800 // fBASEONLY: the attribute is only returned in base-scope searches.
+	SEARCH_FLAG_PARTITIONSECRET       uint32 = 0x00001000 // fPARTITIONSECRET: the attribute is a partition secret.
+)
+
+// systemFlags bits of an attributeSchema object ([MS-ADTS] 2.2.9).
+const (
+	SYSTEM_FLAG_ATTR_NOT_REPLICATED         uint32 = 0x00000001 // The attribute is not replicated.
+	SYSTEM_FLAG_ATTR_REQ_PARTIAL_SET_MEMBER uint32 = 0x00000002 // The attribute is a member of the partial attribute set (global catalog).
+	SYSTEM_FLAG_ATTR_IS_CONSTRUCTED         uint32 = 0x00000004 // The attribute is constructed and never stored.
+	SYSTEM_FLAG_ATTR_IS_OPERATIONAL         uint32 = 0x00000008 // The attribute is operational.
+	SYSTEM_FLAG_SCHEMA_BASE_OBJECT          uint32 = 0x00000010 // The attribute is part of the base schema.
+	SYSTEM_FLAG_ATTR_IS_RDN                 uint32 = 0x00000020 // The attribute can be used as an RDN.
+)
+
+// attributeSyntax values of an attributeSchema object ([MS-ADTS] 3.1.1.2.2.2).
+const (
+	ATTRIBUTE_SYNTAX_DN                   = "2.5.5.1"
+	ATTRIBUTE_SYNTAX_OID                  = "2.5.5.2"
+	ATTRIBUTE_SYNTAX_CASE_EXACT_STRING    = "2.5.5.3"
+	ATTRIBUTE_SYNTAX_CASE_IGNORE_STRING   = "2.5.5.4"
+	ATTRIBUTE_SYNTAX_PRINTABLE_STRING     = "2.5.5.5"
+	ATTRIBUTE_SYNTAX_NUMERIC_STRING       = "2.5.5.6"
+	ATTRIBUTE_SYNTAX_DN_BINARY            = "2.5.5.7"
+	ATTRIBUTE_SYNTAX_BOOLEAN              = "2.5.5.8"
+	ATTRIBUTE_SYNTAX_INTEGER              = "2.5.5.9"
+	ATTRIBUTE_SYNTAX_OCTET_STRING         = "2.5.5.10"
+	ATTRIBUTE_SYNTAX_GENERALIZED_TIME     = "2.5.5.11"
+	ATTRIBUTE_SYNTAX_UNICODE_STRING       = "2.5.5.12"
+	ATTRIBUTE_SYNTAX_PRESENTATION_ADDRESS = "2.5.5.13"
+	ATTRIBUTE_SYNTAX_DN_STRING            = "2.5.5.14"
+	ATTRIBUTE_SYNTAX_NT_SECURITY_DESC     = "2.5.5.15"
+	ATTRIBUTE_SYNTAX_LARGE_INTEGER        = "2.5.5.16"
+	ATTRIBUTE_SYNTAX_SID                  = "2.5.5.17"
+)
+
+// SchemaAttribute describes an Active Directory attributeSchema object.
+//
+// Fields:
+//   - Name: The lowercased common name used as key in SchemaAttributeDisplayNameToGUID (e.g. "user-account-control").
+//   - LDAPDisplayName: The lDAPDisplayName of the attribute (e.g. "userAccountControl"), or an empty
+//     string when it is not part of the built-in catalog.
+//   - SchemaIDGUID: The schemaIDGUID of the attribute, in lowercase D format.
+//   - AttributeSecurityGUID: The GUID of the property set (or validated write) the attribute belongs
+//     to, in lowercase D format, or an empty string when the attribute is in no property set.
+//   - SearchFlags: The searchFlags of the attribute (see the SEARCH_FLAG_* constants).
+//   - SystemFlags: The systemFlags of the attribute (see the SYSTEM_FLAG_* constants).
+//   - AttributeSyntax: The attributeSyntax OID of the attribute (see the ATTRIBUTE_SYNTAX_* constants).
+//   - OMSyntax: The oMSyntax of the attribute.
+//   - IsSingleValued: Whether the attribute is single-valued.
+//
+// The built-in catalog knows the name, schemaIDGUID and attributeSecurityGUID of every attribute;
+// the remaining fields are only filled for the security-relevant attributes of schemaAttributeMetadata.
+type SchemaAttribute struct {
+	Name                  string
+	LDAPDisplayName       string
+	SchemaIDGUID          string
+	AttributeSecurityGUID string
+	SearchFlags           uint32
+	SystemFlags           uint32
+	AttributeSyntax       string
+	OMSyntax              int
+	IsSingleValued        bool
+}
+
+// schemaAttributeMetadata holds the typed metadata of security-relevant attributes, keyed by
+// the lowercased common name used in SchemaAttributeDisplayNameToGUID.
+var schemaAttributeMetadata = map[string]SchemaAttribute{
+	"account-expires":                {LDAPDisplayName: "accountExpires", AttributeSyntax: ATTRIBUTE_SYNTAX_LARGE_INTEGER, OMSyntax: 65, IsSingleValued: true, SearchFlags: 0x10, SystemFlags: 0x12},
+	"admin-count":                    {LDAPDisplayName: "adminCount", AttributeSyntax: ATTRIBUTE_SYNTAX_INTEGER, OMSyntax: 2, IsSingleValued: true, SystemFlags: 0x10},
+	"alt-security-identities":        {LDAPDisplayName: "altSecurityIdentities", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, SearchFlags: 0x1, SystemFlags: 0x12},
+	"certificate-templates":          {LDAPDisplayName: "certificateTemplates", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, SystemFlags: 0x10},
+	"current-value":                  {LDAPDisplayName: "currentValue", AttributeSyntax: ATTRIBUTE_SYNTAX_OCTET_STRING, OMSyntax: 4, IsSingleValued: true, SystemFlags: 0x10},
+	"dbcs-pwd":                       {LDAPDisplayName: "dBCSPwd", AttributeSyntax: ATTRIBUTE_SYNTAX_OCTET_STRING, OMSyntax: 4, IsSingleValued: true, SystemFlags: 0x10},
+	"dns-host-name":                  {LDAPDisplayName: "dNSHostName", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, IsSingleValued: true, SearchFlags: 0x1, SystemFlags: 0x12},
+	"dns-record":                     {LDAPDisplayName: "dnsRecord", AttributeSyntax: ATTRIBUTE_SYNTAX_OCTET_STRING, OMSyntax: 4, SystemFlags: 0x10},
+	"gp-link":                        {LDAPDisplayName: "gPLink", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, IsSingleValued: true, SystemFlags: 0x10},
+	"gpc-file-sys-path":              {LDAPDisplayName: "gPCFileSysPath", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, IsSingleValued: true, SystemFlags: 0x10},
+	"gpc-machine-extension-names":    {LDAPDisplayName: "gPCMachineExtensionNames", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, IsSingleValued: true, SystemFlags: 0x10},
+	"group-type":                     {LDAPDisplayName: "groupType", AttributeSyntax: ATTRIBUTE_SYNTAX_INTEGER, OMSyntax: 2, IsSingleValued: true, SearchFlags: 0x9, SystemFlags: 0x12},
+	"home-directory":                 {LDAPDisplayName: "homeDirectory", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, IsSingleValued: true, SearchFlags: 0x10, SystemFlags: 0x10},
+	"is-member-of-dl":                {LDAPDisplayName: "memberOf", AttributeSyntax: ATTRIBUTE_SYNTAX_DN, OMSyntax: 127, SystemFlags: 0x12},
+	"lm-pwd-history":                 {LDAPDisplayName: "lmPwdHistory", AttributeSyntax: ATTRIBUTE_SYNTAX_OCTET_STRING, OMSyntax: 4, SystemFlags: 0x10},
+	"logon-hours":                    {LDAPDisplayName: "logonHours", AttributeSyntax: ATTRIBUTE_SYNTAX_OCTET_STRING, OMSyntax: 4, IsSingleValued: true, SearchFlags: 0x10, SystemFlags: 0x10},
+	"managed-by":                     {LDAPDisplayName: "managedBy", AttributeSyntax: ATTRIBUTE_SYNTAX_DN, OMSyntax: 127, IsSingleValued: true, SystemFlags: 0x10},
+	"member":                         {LDAPDisplayName: "member", AttributeSyntax: ATTRIBUTE_SYNTAX_DN, OMSyntax: 127, SystemFlags: 0x12},
+	"ms-ds-additional-dns-host-name": {LDAPDisplayName: "msDS-AdditionalDnsHostName", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, SystemFlags: 0x10},
+	"ms-ds-allowed-to-act-on-behalf-of-other-identity": {LDAPDisplayName: "msDS-AllowedToActOnBehalfOfOtherIdentity", AttributeSyntax: ATTRIBUTE_SYNTAX_NT_SECURITY_DESC, OMSyntax: 66, IsSingleValued: true, SystemFlags: 0x10},
+	"ms-ds-allowed-to-delegate-to":                     {LDAPDisplayName: "msDS-AllowedToDelegateTo", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, SystemFlags: 0x10},
+	"ms-ds-creator-sid":                                {LDAPDisplayName: "mS-DS-CreatorSID", AttributeSyntax: ATTRIBUTE_SYNTAX_SID, OMSyntax: 4, IsSingleValued: true, SystemFlags: 0x10},
+	"ms-ds-groupmsamembership":                         {LDAPDisplayName: "msDS-GroupMSAMembership", AttributeSyntax: ATTRIBUTE_SYNTAX_NT_SECURITY_DESC, OMSyntax: 66, IsSingleValued: true, SystemFlags: 0x10},
+	"ms-ds-key-credential-link":                        {LDAPDisplayName: "msDS-KeyCredentialLink", AttributeSyntax: ATTRIBUTE_SYNTAX_DN_BINARY, OMSyntax: 127, SystemFlags: 0x10},
+	"ms-ds-machine-account-quota":                      {LDAPDisplayName: "ms-DS-MachineAccountQuota", AttributeSyntax: ATTRIBUTE_SYNTAX_INTEGER, OMSyntax: 2, IsSingleValued: true, SystemFlags: 0x10},
+	"ms-ds-managedpassword":                            {LDAPDisplayName: "msDS-ManagedPassword", AttributeSyntax: ATTRIBUTE_SYNTAX_OCTET_STRING, OMSyntax: 4, IsSingleValued: true, SystemFlags: 0x14},
+	"ms-ds-managedpasswordid":                          {LDAPDisplayName: "msDS-ManagedPasswordId", AttributeSyntax: ATTRIBUTE_SYNTAX_OCTET_STRING, OMSyntax: 4, IsSingleValued: true, SystemFlags: 0x10},
+	"ms-ds-supported-encryption-types":                 {LDAPDisplayName: "msDS-SupportedEncryptionTypes", AttributeSyntax: ATTRIBUTE_SYNTAX_INTEGER, OMSyntax: 2, IsSingleValued: true, SystemFlags: 0x10},
+	"ms-fve-keypackage":                                {LDAPDisplayName: "msFVE-KeyPackage", AttributeSyntax: ATTRIBUTE_SYNTAX_OCTET_STRING, OMSyntax: 4, IsSingleValued: true, SearchFlags: 0x298, SystemFlags: 0x10},
+	"ms-fve-recoverypassword":                          {LDAPDisplayName: "msFVE-RecoveryPassword", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, IsSingleValued: true, SearchFlags: 0x298, SystemFlags: 0x10},
+	"ms-pki-accountcredentials":                        {LDAPDisplayName: "msPKIAccountCredentials", AttributeSyntax: ATTRIBUTE_SYNTAX_DN_BINARY, OMSyntax: 127, SearchFlags: 0x280, SystemFlags: 0x10},
+	"ms-pki-certificate-application-policy":            {LDAPDisplayName: "msPKI-Certificate-Application-Policy", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, SystemFlags: 0x10},
+	"ms-pki-certificate-name-flag":                     {LDAPDisplayName: "msPKI-Certificate-Name-Flag", AttributeSyntax: ATTRIBUTE_SYNTAX_INTEGER, OMSyntax: 2, IsSingleValued: true, SystemFlags: 0x10},
+	"ms-pki-credential-roaming-tokens":                 {LDAPDisplayName: "msPKI-CredentialRoamingTokens", AttributeSyntax: ATTRIBUTE_SYNTAX_DN_BINARY, OMSyntax: 127, SearchFlags: 0x280, SystemFlags: 0x10},
+	"ms-pki-dpapimasterkeys":                           {LDAPDisplayName: "msPKIDPAPIMasterKeys", AttributeSyntax: ATTRIBUTE_SYNTAX_DN_BINARY, OMSyntax: 127, SearchFlags: 0x280, SystemFlags: 0x10},
+	"ms-pki-enrollment-flag":                           {LDAPDisplayName: "msPKI-Enrollment-Flag", AttributeSyntax: ATTRIBUTE_SYNTAX_INTEGER, OMSyntax: 2, IsSingleValued: true, SystemFlags: 0x10},
+	"ms-pki-ra-signature":                              {LDAPDisplayName: "msPKI-RA-Signature", AttributeSyntax: ATTRIBUTE_SYNTAX_INTEGER, OMSyntax: 2, IsSingleValued: true, SystemFlags: 0x10},
+	"ms-pki-roamingtimestamp":                          {LDAPDisplayName: "msPKIRoamingTimeStamp", AttributeSyntax: ATTRIBUTE_SYNTAX_OCTET_STRING, OMSyntax: 4, IsSingleValued: true, SearchFlags: 0x280, SystemFlags: 0x10},
+	"ms-tpm-ownerinformation":                          {LDAPDisplayName: "msTPM-OwnerInformation", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, IsSingleValued: true, SearchFlags: 0x280, SystemFlags: 0x10},
+	"nt-pwd-history":                                   {LDAPDisplayName: "ntPwdHistory", AttributeSyntax: ATTRIBUTE_SYNTAX_OCTET_STRING, OMSyntax: 4, SystemFlags: 0x10},
+	"nt-security-descriptor":                           {LDAPDisplayName: "nTSecurityDescriptor", AttributeSyntax: ATTRIBUTE_SYNTAX_NT_SECURITY_DESC, OMSyntax: 66, IsSingleValued: true, SearchFlags: 0x8, SystemFlags: 0x12},
+	"object-sid":                                       {LDAPDisplayName: "objectSid", AttributeSyntax: ATTRIBUTE_SYNTAX_SID, OMSyntax: 4, IsSingleValued: true, SearchFlags: 0x9, SystemFlags: 0x12},
+	"pki-extended-key-usage":                           {LDAPDisplayName: "pKIExtendedKeyUsage", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, SystemFlags: 0x10},
+	"primary-group-id":                                 {LDAPDisplayName: "primaryGroupID", AttributeSyntax: ATTRIBUTE_SYNTAX_INTEGER, OMSyntax: 2, IsSingleValued: true, SearchFlags: 0x11, SystemFlags: 0x12},
+	"prior-value":                                      {LDAPDisplayName: "priorValue", AttributeSyntax: ATTRIBUTE_SYNTAX_OCTET_STRING, OMSyntax: 4, IsSingleValued: true, SystemFlags: 0x10},
+	"profile-path":                                     {LDAPDisplayName: "profilePath", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, IsSingleValued: true, SearchFlags: 0x10, SystemFlags: 0x10},
+	"pwd-last-set":                                     {LDAPDisplayName: "pwdLastSet", AttributeSyntax: ATTRIBUTE_SYNTAX_LARGE_INTEGER, OMSyntax: 65, IsSingleValued: true, SystemFlags: 0x10},
+	"sam-account-name":                                 {LDAPDisplayName: "sAMAccountName", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, IsSingleValued: true, SearchFlags: 0xd, SystemFlags: 0x12},
+	"script-path":                                      {LDAPDisplayName: "scriptPath", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, IsSingleValued: true, SearchFlags: 0x10, SystemFlags: 0x10},
+	"service-principal-name":                           {LDAPDisplayName: "servicePrincipalName", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, SearchFlags: 0x1, SystemFlags: 0x12},
+	"sid-history":                                      {LDAPDisplayName: "sIDHistory", AttributeSyntax: ATTRIBUTE_SYNTAX_SID, OMSyntax: 4, SearchFlags: 0x1, SystemFlags: 0x12},
+	"supplemental-credentials":                         {LDAPDisplayName: "supplementalCredentials", AttributeSyntax: ATTRIBUTE_SYNTAX_OCTET_STRING, OMSyntax: 4, SystemFlags: 0x10},
+	"trust-auth-incoming":                              {LDAPDisplayName: "trustAuthIncoming", AttributeSyntax: ATTRIBUTE_SYNTAX_OCTET_STRING, OMSyntax: 4, IsSingleValued: true, SystemFlags: 0x10},
+	"trust-auth-outgoing":                              {LDAPDisplayName: "trustAuthOutgoing", AttributeSyntax: ATTRIBUTE_SYNTAX_OCTET_STRING, OMSyntax: 4, IsSingleValued: true, SystemFlags: 0x10},
+	"unicode-pwd":                                      {LDAPDisplayName: "unicodePwd", AttributeSyntax: ATTRIBUTE_SYNTAX_OCTET_STRING, OMSyntax: 4, IsSingleValued: true, SystemFlags: 0x10},
+	"user-account-control":                             {LDAPDisplayName: "userAccountControl", AttributeSyntax: ATTRIBUTE_SYNTAX_INTEGER, OMSyntax: 2, IsSingleValued: true, SearchFlags: 0x19, SystemFlags: 0x12},
+	"user-password":                                    {LDAPDisplayName: "userPassword", AttributeSyntax: ATTRIBUTE_SYNTAX_OCTET_STRING, OMSyntax: 4, SystemFlags: 0x10},
+	"user-principal-name":                              {LDAPDisplayName: "userPrincipalName", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, IsSingleValued: true, SearchFlags: 0x1, SystemFlags: 0x12},
+	"user-workstations":                                {LDAPDisplayName: "userWorkstations", AttributeSyntax: ATTRIBUTE_SYNTAX_UNICODE_STRING, OMSyntax: 64, IsSingleValued: true, SearchFlags: 0x10, SystemFlags: 0x10},
+	"x509-cert":                                        {LDAPDisplayName: "userCertificate", AttributeSyntax: ATTRIBUTE_SYNTAX_OCTET_STRING, OMSyntax: 4, SystemFlags: 0x12},
+}
+
+// SchemaAttributes maps the schemaIDGUID of each built-in attribute to its attributeSchema description.
+var SchemaAttributes = map[string]SchemaAttribute{}
+
+// schemaAttributeNameToGUID maps the lowercased Name and LDAPDisplayName of each built-in attribute to its schemaIDGUID.
+var schemaAttributeNameToGUID = map[string]string{}
+
+func init() {
+	attributeSecurityGUIDs := map[string]string{}
+	for attributeSecurityGUID, names := range PropertySetToAttributeDisplayNames {
+		for _, name := range names {
+			attributeSecurityGUIDs[name] = attributeSecurityGUID
+		}
+	}
+
+	for name, schemaIDGUID := range SchemaAttributeDisplayNameToGUID {
+		// The attribute table also lists the schemaIDGUIDs of classSchema objects.
+		if _, isClass := SchemaClasses[schemaIDGUID]; isClass {
+			continue
+		}
+
+		schemaAttribute := schemaAttributeMetadata[name]
+		schemaAttribute.Name = name
+		schemaAttribute.SchemaIDGUID = schemaIDGUID
+		schemaAttribute.AttributeSecurityGUID = attributeSecurityGUIDs[name]
+		SchemaAttributes[schemaIDGUID] = schemaAttribute
+
+		schemaAttributeNameToGUID[name] = schemaIDGUID
+		if schemaAttribute.LDAPDisplayName != "" {
+			schemaAttributeNameToGUID[strings.ToLower(schemaAttribute.LDAPDisplayName)] = schemaIDGUID
+		}
+	}
+}
+
+// LookupSchemaAttribute returns the built-in attributeSchema description matching the given
+// schemaIDGUID, lowercased common name or lDAPDisplayName. The comparison is case-insensitive.
+//
+// Parameters:
+//   - nameOrGUID (string): The schemaIDGUID (D format), name or lDAPDisplayName of the attribute.
+//
+// Returns:
+//   - SchemaAttribute: The matching attribute description.
+//   - bool: true if the attribute was found, false otherwise.
+func LookupSchemaAttribute(nameOrGUID string) (SchemaAttribute, bool) {
+	key := strings.ToLower(nameOrGUID)
+	if schemaAttribute, exists := SchemaAttributes[key]; exists {
+		return schemaAttribute, true
+	}
+	if schemaIDGUID, exists := schemaAttributeNameToGUID[key]; exists {
+		return SchemaAttributes[schemaIDGUID], true
+	}
+	return SchemaAttribute{}, false
+}
+
+// GetPropertySetAttributes expands a property set into the attributes it contains, i.e. the
+// attributes whose attributeSecurityGUID is the given GUID.
+//
+// Parameters:
+//   - propertySetGUID (string): The GUID of the property set, in D format.
+//
+// Returns:
+//   - []SchemaAttribute: The attributes of the property set, sorted by Name. The slice is empty if
+//     the GUID is not a known property set.
+func GetPropertySetAttributes(propertySetGUID string) []SchemaAttribute {
+	schemaAttributes := make([]SchemaAttribute, 0)
+	for _, name := range PropertySetToAttributeDisplayNames[strings.ToLower(propertySetGUID)] {
+		if schemaAttribute, exists := LookupSchemaAttribute(name); exists {
+			schemaAttributes = append(schemaAttributes, schemaAttribute)
+		}
+	}
+	sort.Slice(schemaAttributes, func(i, j int) bool {
+		return schemaAttributes[i].Name < schemaAttributes[j].Name
+	})
+	return schemaAttributes
+}
+
+// GetAttributePropertySet returns the GUID of the property set containing an attribute.
+//
+// Parameters:
+//   - nameOrGUID (string): The schemaIDGUID (D format), name or lDAPDisplayName of the attribute.
+//
+// Returns:
+//   - string: The attributeSecurityGUID of the attribute.
+//   - bool: true if the attribute is known and belongs to a property set, false otherwise.
+func GetAttributePropertySet(nameOrGUID string) (string, bool) {
+	schemaAttribute, exists := LookupSchemaAttribute(nameOrGUID)
+	if !exists || schemaAttribute.AttributeSecurityGUID == "" {
+		return "", false
+	}
+	return schemaAttribute.AttributeSecurityGUID, true
+}
+
+// HasSearchFlag checks if a specific searchFlags bit is set on the attribute.
+//
+// Parameters:
+//   - flag (uint32): The SEARCH_FLAG_* value to check.
+//
+// Returns:
+//   - bool: true if the flag is set, false otherwise.
+func (schemaAttribute SchemaAttribute) HasSearchFlag(flag uint32) bool {
+	return schemaAttribute.SearchFlags&flag == flag
+}
+
+// HasSystemFlag checks if a specific systemFlags bit is set on the attribute.
+//
+// Parameters:
+//   - flag (uint32): The SYSTEM_FLAG_* value to check.
+//
+// Returns:
+//   - bool: true if the flag is set, false otherwise.
+func (schemaAttribute SchemaAttribute) HasSystemFlag(flag uint32) bool {
+	return schemaAttribute.SystemFlags&flag == flag
+}
+
+// IsConfidential returns true if reading the attribute requires CONTROL_ACCESS (searchFlags 0x80).
+func (schemaAttribute SchemaAttribute) IsConfidential() bool {
+	return schemaAttribute.HasSearchFlag(SEARCH_FLAG_CONFIDENTIAL)
+}
+
+// IsRODCFiltered returns true if the attribute is not replicated to read-only domain controllers (searchFlags 0x200).
+func (schemaAttribute SchemaAttribute) IsRODCFiltered() bool {
+	return schemaAttribute.HasSearchFlag(SEARCH_FLAG_RODCFILTEREDATTRIBUTE)
+}
+
+// IsInPropertySet returns true if the attribute belongs to the given property set.
+//
+// Parameters:
+//   - propertySetGUID (string): The GUID of the property set, in D format.
+func (schemaAttribute SchemaAttribute) IsInPropertySet(propertySetGUID string) bool {
+	return schemaAttribute.AttributeSecurityGUID != "" && strings.EqualFold(schemaAttribute.AttributeSecurityGUID, propertySetGUID)
+}
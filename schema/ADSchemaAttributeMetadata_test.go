@@ -0,0 +1,81 @@
+package schema
+
+import (
+	"testing"
+)
+
+func Test_SchemaAttributeMetadata_In_SchemaAttributeDisplayNameToGUID(t *testing.T) {
+	for name := range schemaAttributeMetadata {
+		if _, exists := SchemaAttributeDisplayNameToGUID[name]; !exists {
+			t.Errorf("Key %s from schemaAttributeMetadata not found in SchemaAttributeDisplayNameToGUID", name)
+		}
+	}
+}
+
+func Test_SchemaAttributes_ExcludesClasses(t *testing.T) {
+	for schemaClassGUID := range SchemaClasses {
+		if _, exists := SchemaAttributes[schemaClassGUID]; exists {
+			t.Errorf("Class %s should not be listed in SchemaAttributes", schemaClassGUID)
+		}
+	}
+}
+
+func TestLookupSchemaAttribute(t *testing.T) {
+	for _, nameOrGUID := range []string{"userAccountControl", "USERACCOUNTCONTROL", "user-account-control", SCHEMA_ATTRIBUTE_USER_ACCOUNT_CONTROL} {
+		schemaAttribute, exists := LookupSchemaAttribute(nameOrGUID)
+		if !exists {
+			t.Fatalf("LookupSchemaAttribute(%q) not found", nameOrGUID)
+		}
+		if schemaAttribute.SchemaIDGUID != SCHEMA_ATTRIBUTE_USER_ACCOUNT_CONTROL {
+			t.Errorf("LookupSchemaAttribute(%q).SchemaIDGUID = %s", nameOrGUID, schemaAttribute.SchemaIDGUID)
+		}
+		if !schemaAttribute.IsInPropertySet(PROPERTY_SET_ACCOUNT_RESTRICTIONS) {
+			t.Errorf("userAccountControl should be in the Account Restrictions property set")
+		}
+		if schemaAttribute.AttributeSyntax != ATTRIBUTE_SYNTAX_INTEGER || !schemaAttribute.IsSingleValued {
+			t.Errorf("userAccountControl should be a single-valued integer, got %+v", schemaAttribute)
+		}
+	}
+	if _, exists := LookupSchemaAttribute("notAnAttribute"); exists {
+		t.Errorf("LookupSchemaAttribute(\"notAnAttribute\") should not exist")
+	}
+}
+
+func TestSchemaAttribute_SearchFlags(t *testing.T) {
+	recoveryPassword, _ := LookupSchemaAttribute("msFVE-RecoveryPassword")
+	if !recoveryPassword.IsConfidential() || !recoveryPassword.IsRODCFiltered() {
+		t.Errorf("msFVE-RecoveryPassword should be confidential and RODC-filtered, searchFlags = 0x%x", recoveryPassword.SearchFlags)
+	}
+	member, _ := LookupSchemaAttribute("member")
+	if member.IsConfidential() || member.IsRODCFiltered() {
+		t.Errorf("member should be neither confidential nor RODC-filtered, searchFlags = 0x%x", member.SearchFlags)
+	}
+}
+
+func TestGetPropertySetAttributes(t *testing.T) {
+	schemaAttributes := GetPropertySetAttributes(PROPERTY_SET_DNS_HOST_NAME_ATTRIBUTES)
+	if len(schemaAttributes) != 2 {
+		t.Fatalf("GetPropertySetAttributes(DNS host name attributes) returned %d attributes, want 2", len(schemaAttributes))
+	}
+	if schemaAttributes[0].LDAPDisplayName != "dNSHostName" || schemaAttributes[1].LDAPDisplayName != "msDS-AdditionalDnsHostName" {
+		t.Errorf("unexpected attributes %v", schemaAttributes)
+	}
+
+	if len(GetPropertySetAttributes("00000000-0000-0000-0000-000000000000")) != 0 {
+		t.Errorf("GetPropertySetAttributes of an unknown GUID should be empty")
+	}
+}
+
+func TestGetAttributePropertySet(t *testing.T) {
+	for _, attributeSecurityGUID := range []string{PROPERTY_SET_ACCOUNT_RESTRICTIONS, PROPERTY_SET_GROUP_MEMBERSHIP, PROPERTY_SET_PERSONAL_INFORMATION} {
+		for _, schemaAttribute := range GetPropertySetAttributes(attributeSecurityGUID) {
+			propertySetGUID, exists := GetAttributePropertySet(schemaAttribute.SchemaIDGUID)
+			if !exists || propertySetGUID != attributeSecurityGUID {
+				t.Errorf("GetAttributePropertySet(%s) = %s, want %s", schemaAttribute.Name, propertySetGUID, attributeSecurityGUID)
+			}
+		}
+	}
+	if _, exists := GetAttributePropertySet("unicodePwd"); exists {
+		t.Errorf("unicodePwd should not belong to a property set")
+	}
+}
@@ -0,0 +1,559 @@
+package schema
+
+import "strings"
+
+const (
+	SCHEMA_CLASS_ATTRIBUTE_SCHEMA                        = "bf967a80-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_BUILTIN_DOMAIN                          = "bf967a81-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_CERTIFICATION_AUTHORITY                 = "3fdfee50-47f4-11d1-a9c3-0000f80367c1"
+	SCHEMA_CLASS_CLASS_SCHEMA                            = "bf967a83-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_COMPUTER                                = "bf967a86-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_CONTACT                                 = "5cb41ed0-0e4c-11d0-a286-00aa003049e2"
+	SCHEMA_CLASS_CONFIGURATION                           = "bf967a87-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_CONTAINER                               = "bf967a8b-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_CONTROL_ACCESS_RIGHT                    = "8297931e-86d3-11d0-afda-00c04fd930c9"
+	SCHEMA_CLASS_CROSS_REF                               = "bf967a8d-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_DMD                                     = "bf967a8f-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_DNS_NODE                                = "e0fa1e8c-9b45-11d0-afdd-00c04fd930c9"
+	SCHEMA_CLASS_DNS_ZONE                                = "e0fa1e8b-9b45-11d0-afdd-00c04fd930c9"
+	SCHEMA_CLASS_DOMAIN_DNS                              = "19195a5b-6da0-11d0-afd3-00c04fd930c9"
+	SCHEMA_CLASS_DOMAIN_POLICY                           = "bf967a99-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_FOREIGN_SECURITY_PRINCIPAL              = "89e31c12-8530-11d0-afda-00c04fd930c9"
+	SCHEMA_CLASS_GROUP                                   = "bf967a9c-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_GROUP_POLICY_CONTAINER                  = "f30e3bc2-9ff0-11d1-b603-0000f80367c1"
+	SCHEMA_CLASS_INET_ORG_PERSON                         = "4828cc14-1437-45bc-9b07-ad6f015e5f28"
+	SCHEMA_CLASS_LOST_AND_FOUND                          = "52ab8671-5709-11d1-a9c6-0000f80367c1"
+	SCHEMA_CLASS_MS_DS_DELEGATED_MANAGED_SERVICE_ACCOUNT = "0feb936f-47b3-49f2-9386-1dedc2c23765"
+	SCHEMA_CLASS_MS_DS_GROUP_MANAGED_SERVICE_ACCOUNT     = "7b8b558a-93a5-4af7-adca-c017e67f1057"
+	SCHEMA_CLASS_MS_DS_MANAGED_SERVICE_ACCOUNT           = "ce206244-5827-4a86-ba1c-1c0c386c1b64"
+	SCHEMA_CLASS_MS_DS_PASSWORD_SETTINGS                 = "3bcd9db8-f84b-451c-952f-6c52b81f9ec6"
+	SCHEMA_CLASS_MS_FVE_RECOVERY_INFORMATION             = "ea715d30-8f53-40d0-bd1e-6109186d782c"
+	SCHEMA_CLASS_MS_TPM_INFORMATION_OBJECT               = "85045b6a-47a6-4243-a7cc-6890701f662c"
+	SCHEMA_CLASS_NTDS_CONNECTION                         = "19195a60-6da0-11d0-afd3-00c04fd930c9"
+	SCHEMA_CLASS_NTDS_DSA                                = "f0f8ffab-1191-11d0-a060-00aa006c33ed"
+	SCHEMA_CLASS_ORGANIZATIONAL_PERSON                   = "bf967aa4-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_ORGANIZATIONAL_UNIT                     = "bf967aa5-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_PERSON                                  = "bf967aa7-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_PKI_CERTIFICATE_TEMPLATE                = "e5209ca2-3bba-11d2-90cc-00c04fd91ab1"
+	SCHEMA_CLASS_PKI_ENROLLMENT_SERVICE                  = "ee4aa692-3bba-11d2-90cc-00c04fd91ab1"
+	SCHEMA_CLASS_PRINT_QUEUE                             = "bf967aa8-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_RID_MANAGER                             = "6617188d-8f3c-11d0-afda-00c04fd930c9"
+	SCHEMA_CLASS_RID_SET                                 = "7bfdcb89-4807-11d1-a9c3-0000f80367c1"
+	SCHEMA_CLASS_SECRET                                  = "bf967aae-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_SERVER                                  = "bf967a92-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_SERVICE_CONNECTION_POINT                = "28630ec1-41d5-11d1-a9c1-0000f80367c1"
+	SCHEMA_CLASS_SITE                                    = "bf967ab3-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_SITE_LINK                               = "d50c2cde-8951-11d1-aebc-0000f80367c1"
+	SCHEMA_CLASS_SUBNET                                  = "b7b13124-b82e-11d0-afee-0000f80367c1"
+	SCHEMA_CLASS_TOP                                     = "bf967ab7-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_TRUSTED_DOMAIN                          = "bf967ab8-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_USER                                    = "bf967aba-0de6-11d0-a285-00aa003049e2"
+	SCHEMA_CLASS_VOLUME                                  = "bf967abb-0de6-11d0-a285-00aa003049e2"
+)
+
+// SchemaClass describes an Active Directory classSchema object.
+//
+// Fields:
+//   - LDAPDisplayName: The lDAPDisplayName of the class (e.g. "user").
+//   - CN: The common name of the classSchema object (e.g. "User").
+//   - SchemaIDGUID: The schemaIDGUID of the class, in lowercase D format. This is the
+//     GUID found in the ObjectType of CREATE_CHILD / DELETE_CHILD ACEs and in the
+//     InheritedObjectType of inheritable ACEs.
+//   - SubClassOf: The lDAPDisplayName of the parent class in the class hierarchy.
+//   - PossSuperiors: The lDAPDisplayNames of the classes that may contain an instance of this class.
+//   - MustContain: The lDAPDisplayNames of the mandatory attributes defined by this class.
+//   - MayContain: The lDAPDisplayNames of the optional attributes defined by this class. Only the
+//     security-relevant subset is listed for the built-in catalog; attributes inherited through
+//     SubClassOf are not repeated.
+//   - DefaultSecurityDescriptor: The defaultSecurityDescriptor of the class in SDDL form, or an
+//     empty string when it is not part of the built-in catalog.
+type SchemaClass struct {
+	LDAPDisplayName           string
+	CN                        string
+	SchemaIDGUID              string
+	SubClassOf                string
+	PossSuperiors             []string
+	MustContain               []string
+	MayContain                []string
+	DefaultSecurityDescriptor string
+}
+
+// SchemaClasses maps the schemaIDGUID of each built-in class to its classSchema description.
+var SchemaClasses = map[string]SchemaClass{
+	SCHEMA_CLASS_ATTRIBUTE_SCHEMA: {
+		LDAPDisplayName: "attributeSchema",
+		CN:              "Attribute-Schema",
+		SchemaIDGUID:    SCHEMA_CLASS_ATTRIBUTE_SCHEMA,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"dMD"},
+		MustContain:     []string{"attributeID", "attributeSyntax", "cn", "isSingleValued", "lDAPDisplayName", "oMSyntax", "schemaIDGUID"},
+		MayContain:      []string{"attributeSecurityGUID", "searchFlags", "systemFlags", "systemOnly"},
+	},
+	SCHEMA_CLASS_BUILTIN_DOMAIN: {
+		LDAPDisplayName: "builtinDomain",
+		CN:              "Builtin-Domain",
+		SchemaIDGUID:    SCHEMA_CLASS_BUILTIN_DOMAIN,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"domainDNS"},
+	},
+	SCHEMA_CLASS_CERTIFICATION_AUTHORITY: {
+		LDAPDisplayName: "certificationAuthority",
+		CN:              "Certification-Authority",
+		SchemaIDGUID:    SCHEMA_CLASS_CERTIFICATION_AUTHORITY,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"container"},
+		MustContain:     []string{"authorityRevocationList", "cACertificate", "certificateRevocationList", "cn"},
+		MayContain:      []string{"certificateTemplates", "cRLObject", "deltaRevocationList", "dNSHostName"},
+	},
+	SCHEMA_CLASS_CLASS_SCHEMA: {
+		LDAPDisplayName: "classSchema",
+		CN:              "Class-Schema",
+		SchemaIDGUID:    SCHEMA_CLASS_CLASS_SCHEMA,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"dMD"},
+		MustContain:     []string{"cn", "defaultObjectCategory", "governsID", "objectClassCategory", "schemaIDGUID", "subClassOf"},
+		MayContain:      []string{"defaultSecurityDescriptor", "lDAPDisplayName", "mayContain", "mustContain", "possSuperiors", "systemMayContain", "systemMustContain", "systemPossSuperiors"},
+	},
+	SCHEMA_CLASS_COMPUTER: {
+		LDAPDisplayName: "computer",
+		CN:              "Computer",
+		SchemaIDGUID:    SCHEMA_CLASS_COMPUTER,
+		SubClassOf:      "user",
+		PossSuperiors:   []string{"container", "organizationalUnit", "domainDNS"},
+		MayContain:      []string{"dNSHostName", "msDS-AdditionalDnsHostName", "msDS-AllowedToActOnBehalfOfOtherIdentity", "msDS-KeyCredentialLink", "msTPM-TpmInformationForComputer", "operatingSystem", "operatingSystemVersion", "servicePrincipalName"},
+		DefaultSecurityDescriptor: "D:(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;DA)(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;SY)(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;AO)" +
+			"(A;;RPLCLORC;;;PS)(OA;;CR;ab721a53-1e2f-11d0-9819-00aa0040529b;;PS)(OA;;SW;f3a64788-5306-11d1-a9c5-0000f80367c1;;PS)" +
+			"(OA;;SW;72e39547-7b18-11d1-adef-00c04fd8d5cd;;PS)(OA;;RPWP;77b5b886-944a-11d1-aebd-0000f80367c1;;PS)" +
+			"(OA;;RPWP;e45795b2-9455-11d1-aebd-0000f80367c1;;PS)(OA;;RPWP;e45795b3-9455-11d1-aebd-0000f80367c1;;PS)" +
+			"(A;;RPLCLORC;;;AU)",
+	},
+	SCHEMA_CLASS_CONTACT: {
+		LDAPDisplayName: "contact",
+		CN:              "Contact",
+		SchemaIDGUID:    SCHEMA_CLASS_CONTACT,
+		SubClassOf:      "organizationalPerson",
+		PossSuperiors:   []string{"container", "organizationalUnit", "domainDNS"},
+		MustContain:     []string{"cn"},
+		DefaultSecurityDescriptor: "D:(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;DA)(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;SY)(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;AO)" +
+			"(A;;RPLCLORC;;;AU)",
+	},
+	SCHEMA_CLASS_CONFIGURATION: {
+		LDAPDisplayName: "configuration",
+		CN:              "Configuration",
+		SchemaIDGUID:    SCHEMA_CLASS_CONFIGURATION,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"domainDNS"},
+		MustContain:     []string{"cn"},
+		MayContain:      []string{"gPLink", "gPOptions", "msDS-USNLastSyncSuccess"},
+	},
+	SCHEMA_CLASS_CONTAINER: {
+		LDAPDisplayName:           "container",
+		CN:                        "Container",
+		SchemaIDGUID:              SCHEMA_CLASS_CONTAINER,
+		SubClassOf:                "top",
+		PossSuperiors:             []string{"container", "domainDNS", "organizationalUnit", "site", "server"},
+		MustContain:               []string{"cn"},
+		DefaultSecurityDescriptor: "D:(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;DA)(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;SY)(A;;RPLCLORC;;;AU)",
+	},
+	SCHEMA_CLASS_CONTROL_ACCESS_RIGHT: {
+		LDAPDisplayName: "controlAccessRight",
+		CN:              "Control-Access-Right",
+		SchemaIDGUID:    SCHEMA_CLASS_CONTROL_ACCESS_RIGHT,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"container"},
+		MayContain:      []string{"appliesTo", "displayName", "rightsGuid", "validAccesses"},
+	},
+	SCHEMA_CLASS_CROSS_REF: {
+		LDAPDisplayName: "crossRef",
+		CN:              "Cross-Ref",
+		SchemaIDGUID:    SCHEMA_CLASS_CROSS_REF,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"crossRefContainer"},
+		MustContain:     []string{"cn", "nCName"},
+		MayContain:      []string{"dnsRoot", "nETBIOSName", "trustParent"},
+	},
+	SCHEMA_CLASS_DMD: {
+		LDAPDisplayName: "dMD",
+		CN:              "DMD",
+		SchemaIDGUID:    SCHEMA_CLASS_DMD,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"configuration"},
+		MustContain:     []string{"cn"},
+		MayContain:      []string{"fSMORoleOwner", "prefixMap", "schemaInfo"},
+	},
+	SCHEMA_CLASS_DNS_NODE: {
+		LDAPDisplayName:           "dnsNode",
+		CN:                        "Dns-Node",
+		SchemaIDGUID:              SCHEMA_CLASS_DNS_NODE,
+		SubClassOf:                "top",
+		PossSuperiors:             []string{"dnsZone"},
+		MustContain:               []string{"dc"},
+		MayContain:                []string{"dNSProperty", "dnsRecord", "dNSTombstoned"},
+		DefaultSecurityDescriptor: "O:BAG:BAD:(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;DA)(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;SY)(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;ED)(A;;RPLCLORC;;;WD)",
+	},
+	SCHEMA_CLASS_DNS_ZONE: {
+		LDAPDisplayName:           "dnsZone",
+		CN:                        "Dns-Zone",
+		SchemaIDGUID:              SCHEMA_CLASS_DNS_ZONE,
+		SubClassOf:                "top",
+		PossSuperiors:             []string{"container"},
+		MustContain:               []string{"dc"},
+		MayContain:                []string{"dNSProperty", "managedBy"},
+		DefaultSecurityDescriptor: "O:BAG:BAD:(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;DA)(A;;CC;;;AU)(A;;RPLCLORC;;;WD)(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;SY)(A;CI;RPWPCRCCDCLCLORCWOWDSDDTSW;;;ED)",
+	},
+	SCHEMA_CLASS_DOMAIN_DNS: {
+		LDAPDisplayName: "domainDNS",
+		CN:              "Domain-DNS",
+		SchemaIDGUID:    SCHEMA_CLASS_DOMAIN_DNS,
+		SubClassOf:      "domain",
+		PossSuperiors:   []string{"domainDNS"},
+		MustContain:     []string{"dc"},
+		MayContain:      []string{"gPLink", "gPOptions", "lockoutDuration", "lockoutThreshold", "maxPwdAge", "minPwdLength", "ms-DS-MachineAccountQuota", "msDS-Behavior-Version", "pwdProperties"},
+	},
+	SCHEMA_CLASS_DOMAIN_POLICY: {
+		LDAPDisplayName: "domainPolicy",
+		CN:              "Domain-Policy",
+		SchemaIDGUID:    SCHEMA_CLASS_DOMAIN_POLICY,
+		SubClassOf:      "leaf",
+		PossSuperiors:   []string{"container", "organizationalUnit", "domainDNS"},
+	},
+	SCHEMA_CLASS_FOREIGN_SECURITY_PRINCIPAL: {
+		LDAPDisplayName:           "foreignSecurityPrincipal",
+		CN:                        "Foreign-Security-Principal",
+		SchemaIDGUID:              SCHEMA_CLASS_FOREIGN_SECURITY_PRINCIPAL,
+		SubClassOf:                "top",
+		PossSuperiors:             []string{"container"},
+		MustContain:               []string{"objectSid"},
+		MayContain:                []string{"foreignIdentifier"},
+		DefaultSecurityDescriptor: "D:(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;DA)(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;SY)(A;;RPLCLORC;;;AU)",
+	},
+	SCHEMA_CLASS_GROUP: {
+		LDAPDisplayName: "group",
+		CN:              "Group",
+		SchemaIDGUID:    SCHEMA_CLASS_GROUP,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"builtinDomain", "container", "domainDNS", "organizationalUnit"},
+		MustContain:     []string{"groupType"},
+		MayContain:      []string{"adminCount", "managedBy", "member", "nonSecurityMember", "primaryGroupToken", "sAMAccountName"},
+		DefaultSecurityDescriptor: "D:(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;DA)(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;SY)(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;AO)" +
+			"(A;;RPLCLORC;;;PS)(OA;;CR;ab721a55-1e2f-11d0-9819-00aa0040529b;;AU)(A;;RPLCLORC;;;AU)",
+	},
+	SCHEMA_CLASS_GROUP_POLICY_CONTAINER: {
+		LDAPDisplayName: "groupPolicyContainer",
+		CN:              "Group-Policy-Container",
+		SchemaIDGUID:    SCHEMA_CLASS_GROUP_POLICY_CONTAINER,
+		SubClassOf:      "container",
+		PossSuperiors:   []string{"container"},
+		MayContain:      []string{"displayName", "flags", "gPCFileSysPath", "gPCFunctionalityVersion", "gPCMachineExtensionNames", "gPCUserExtensionNames", "versionNumber"},
+		DefaultSecurityDescriptor: "D:P(A;CI;RPWPCCDCLCLOLORCWOWDSDDTSW;;;DA)(A;CI;RPWPCCDCLCLOLORCWOWDSDDTSW;;;EA)(A;CI;RPWPCCDCLCLOLORCWOWDSDDTSW;;;CO)" +
+			"(A;CI;RPWPCCDCLCLORCWOWDSDDTSW;;;SY)(A;CI;RPLCLORC;;;AU)(OA;CI;CR;edacfd8f-ffb3-11d1-b41d-00a0c968f939;;AU)(A;CI;LCRPLORC;;;ED)",
+	},
+	SCHEMA_CLASS_INET_ORG_PERSON: {
+		LDAPDisplayName: "inetOrgPerson",
+		CN:              "inetOrgPerson",
+		SchemaIDGUID:    SCHEMA_CLASS_INET_ORG_PERSON,
+		SubClassOf:      "user",
+		PossSuperiors:   []string{"builtinDomain", "container", "domainDNS", "organizationalUnit"},
+		MayContain:      []string{"employeeNumber", "jpegPhoto", "uid", "userPKCS12", "userSMIMECertificate"},
+	},
+	SCHEMA_CLASS_LOST_AND_FOUND: {
+		LDAPDisplayName: "lostAndFound",
+		CN:              "Lost-And-Found",
+		SchemaIDGUID:    SCHEMA_CLASS_LOST_AND_FOUND,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"domainDNS", "configuration", "dMD"},
+	},
+	SCHEMA_CLASS_MS_DS_DELEGATED_MANAGED_SERVICE_ACCOUNT: {
+		LDAPDisplayName: "msDS-DelegatedManagedServiceAccount",
+		CN:              "ms-DS-Delegated-Managed-Service-Account",
+		SchemaIDGUID:    SCHEMA_CLASS_MS_DS_DELEGATED_MANAGED_SERVICE_ACCOUNT,
+		SubClassOf:      "computer",
+		PossSuperiors:   []string{"container", "organizationalUnit", "domainDNS"},
+		MayContain:      []string{"msDS-DelegatedMSAState", "msDS-GroupMSAMembership", "msDS-ManagedAccountPrecededByLink", "msDS-ManagedPasswordId", "msDS-ManagedPasswordInterval"},
+	},
+	SCHEMA_CLASS_MS_DS_GROUP_MANAGED_SERVICE_ACCOUNT: {
+		LDAPDisplayName: "msDS-GroupManagedServiceAccount",
+		CN:              "ms-DS-Group-Managed-Service-Account",
+		SchemaIDGUID:    SCHEMA_CLASS_MS_DS_GROUP_MANAGED_SERVICE_ACCOUNT,
+		SubClassOf:      "computer",
+		PossSuperiors:   []string{"container", "organizationalUnit", "domainDNS"},
+		MustContain:     []string{"msDS-ManagedPasswordInterval"},
+		MayContain:      []string{"msDS-GroupMSAMembership", "msDS-ManagedPassword", "msDS-ManagedPasswordId", "msDS-ManagedPasswordPreviousId"},
+	},
+	SCHEMA_CLASS_MS_DS_MANAGED_SERVICE_ACCOUNT: {
+		LDAPDisplayName: "msDS-ManagedServiceAccount",
+		CN:              "ms-DS-Managed-Service-Account",
+		SchemaIDGUID:    SCHEMA_CLASS_MS_DS_MANAGED_SERVICE_ACCOUNT,
+		SubClassOf:      "computer",
+		PossSuperiors:   []string{"container", "organizationalUnit", "domainDNS"},
+	},
+	SCHEMA_CLASS_MS_DS_PASSWORD_SETTINGS: {
+		LDAPDisplayName: "msDS-PasswordSettings",
+		CN:              "ms-DS-Password-Settings",
+		SchemaIDGUID:    SCHEMA_CLASS_MS_DS_PASSWORD_SETTINGS,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"msDS-PasswordSettingsContainer"},
+		MustContain:     []string{"msDS-LockoutDuration", "msDS-LockoutObservationWindow", "msDS-LockoutThreshold", "msDS-MaximumPasswordAge", "msDS-MinimumPasswordAge", "msDS-MinimumPasswordLength", "msDS-PasswordComplexityEnabled", "msDS-PasswordHistoryLength", "msDS-PasswordReversibleEncryptionEnabled", "msDS-PasswordSettingsPrecedence"},
+		MayContain:      []string{"msDS-PSOAppliesTo"},
+	},
+	SCHEMA_CLASS_MS_FVE_RECOVERY_INFORMATION: {
+		LDAPDisplayName: "msFVE-RecoveryInformation",
+		CN:              "ms-FVE-RecoveryInformation",
+		SchemaIDGUID:    SCHEMA_CLASS_MS_FVE_RECOVERY_INFORMATION,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"computer"},
+		MustContain:     []string{"msFVE-RecoveryGuid", "msFVE-RecoveryPassword"},
+		MayContain:      []string{"msFVE-KeyPackage", "msFVE-VolumeGuid"},
+	},
+	SCHEMA_CLASS_MS_TPM_INFORMATION_OBJECT: {
+		LDAPDisplayName: "msTPM-InformationObject",
+		CN:              "ms-TPM-Information-Object",
+		SchemaIDGUID:    SCHEMA_CLASS_MS_TPM_INFORMATION_OBJECT,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"msTPM-InformationObjectsContainer"},
+		MustContain:     []string{"msTPM-OwnerInformation"},
+		MayContain:      []string{"msTPM-OwnerInformationTemp", "msTPM-SrkPubThumbprint"},
+	},
+	SCHEMA_CLASS_NTDS_CONNECTION: {
+		LDAPDisplayName: "nTDSConnection",
+		CN:              "NTDS-Connection",
+		SchemaIDGUID:    SCHEMA_CLASS_NTDS_CONNECTION,
+		SubClassOf:      "leaf",
+		PossSuperiors:   []string{"nTDSDSA", "nTFRSMember", "nTFRSReplicaSet"},
+		MustContain:     []string{"enabledConnection", "options"},
+		MayContain:      []string{"fromServer", "schedule", "transportType"},
+	},
+	SCHEMA_CLASS_NTDS_DSA: {
+		LDAPDisplayName: "nTDSDSA",
+		CN:              "NTDS-DSA",
+		SchemaIDGUID:    SCHEMA_CLASS_NTDS_DSA,
+		SubClassOf:      "applicationSettings",
+		PossSuperiors:   []string{"server", "organization"},
+		MayContain:      []string{"hasMasterNCs", "invocationId", "msDS-hasMasterNCs", "options", "queryPolicyObject"},
+	},
+	SCHEMA_CLASS_ORGANIZATIONAL_PERSON: {
+		LDAPDisplayName: "organizationalPerson",
+		CN:              "Organizational-Person",
+		SchemaIDGUID:    SCHEMA_CLASS_ORGANIZATIONAL_PERSON,
+		SubClassOf:      "person",
+		PossSuperiors:   []string{"organization", "organizationalUnit"},
+		MayContain:      []string{"company", "department", "givenName", "mail", "manager", "mobile", "streetAddress", "title"},
+	},
+	SCHEMA_CLASS_ORGANIZATIONAL_UNIT: {
+		LDAPDisplayName: "organizationalUnit",
+		CN:              "Organizational-Unit",
+		SchemaIDGUID:    SCHEMA_CLASS_ORGANIZATIONAL_UNIT,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"country", "organization", "organizationalUnit", "domainDNS"},
+		MustContain:     []string{"ou"},
+		MayContain:      []string{"gPLink", "gPOptions", "managedBy"},
+		DefaultSecurityDescriptor: "D:(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;DA)(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;SY)(A;;RPLCLORC;;;AU)(A;;LCRPLORC;;;ED)" +
+			"(OA;;CCDC;bf967a86-0de6-11d0-a285-00aa003049e2;;AO)(OA;;CCDC;bf967aba-0de6-11d0-a285-00aa003049e2;;AO)" +
+			"(OA;;CCDC;bf967a9c-0de6-11d0-a285-00aa003049e2;;AO)(OA;;CCDC;bf967aa8-0de6-11d0-a285-00aa003049e2;;PO)" +
+			"(OA;;CCDC;4828cc14-1437-45bc-9b07-ad6f015e5f28;;AO)",
+	},
+	SCHEMA_CLASS_PERSON: {
+		LDAPDisplayName: "person",
+		CN:              "Person",
+		SchemaIDGUID:    SCHEMA_CLASS_PERSON,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"container", "organizationalUnit"},
+		MustContain:     []string{"cn"},
+		MayContain:      []string{"seeAlso", "sn", "telephoneNumber", "userPassword"},
+	},
+	SCHEMA_CLASS_PKI_CERTIFICATE_TEMPLATE: {
+		LDAPDisplayName: "pKICertificateTemplate",
+		CN:              "PKI-Certificate-Template",
+		SchemaIDGUID:    SCHEMA_CLASS_PKI_CERTIFICATE_TEMPLATE,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"container"},
+		MayContain:      []string{"displayName", "flags", "msPKI-Certificate-Application-Policy", "msPKI-Certificate-Name-Flag", "msPKI-Enrollment-Flag", "msPKI-RA-Signature", "pKIExtendedKeyUsage"},
+	},
+	SCHEMA_CLASS_PKI_ENROLLMENT_SERVICE: {
+		LDAPDisplayName: "pKIEnrollmentService",
+		CN:              "PKI-Enrollment-Service",
+		SchemaIDGUID:    SCHEMA_CLASS_PKI_ENROLLMENT_SERVICE,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"container"},
+		MayContain:      []string{"cACertificate", "certificateTemplates", "dNSHostName", "signatureAlgorithms"},
+	},
+	SCHEMA_CLASS_PRINT_QUEUE: {
+		LDAPDisplayName: "printQueue",
+		CN:              "Print-Queue",
+		SchemaIDGUID:    SCHEMA_CLASS_PRINT_QUEUE,
+		SubClassOf:      "connectionPoint",
+		PossSuperiors:   []string{"computer", "container", "organizationalUnit", "domainDNS"},
+		MustContain:     []string{"printerName", "serverName", "shortServerName", "uNCName", "versionNumber"},
+	},
+	SCHEMA_CLASS_RID_MANAGER: {
+		LDAPDisplayName: "rIDManager",
+		CN:              "RID-Manager",
+		SchemaIDGUID:    SCHEMA_CLASS_RID_MANAGER,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"container"},
+		MustContain:     []string{"rIDAvailablePool"},
+	},
+	SCHEMA_CLASS_RID_SET: {
+		LDAPDisplayName: "rIDSet",
+		CN:              "RID-Set",
+		SchemaIDGUID:    SCHEMA_CLASS_RID_SET,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"computer", "user"},
+		MustContain:     []string{"rIDAllocationPool", "rIDNextRID", "rIDPreviousAllocationPool", "rIDUsedPool"},
+	},
+	SCHEMA_CLASS_SECRET: {
+		LDAPDisplayName: "secret",
+		CN:              "Secret",
+		SchemaIDGUID:    SCHEMA_CLASS_SECRET,
+		SubClassOf:      "leaf",
+		PossSuperiors:   []string{"container"},
+		MayContain:      []string{"currentValue", "lastSetTime", "priorSetTime", "priorValue"},
+	},
+	SCHEMA_CLASS_SERVER: {
+		LDAPDisplayName: "server",
+		CN:              "Server",
+		SchemaIDGUID:    SCHEMA_CLASS_SERVER,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"serversContainer"},
+		MayContain:      []string{"dNSHostName", "serverReference"},
+	},
+	SCHEMA_CLASS_SERVICE_CONNECTION_POINT: {
+		LDAPDisplayName: "serviceConnectionPoint",
+		CN:              "Service-Connection-Point",
+		SchemaIDGUID:    SCHEMA_CLASS_SERVICE_CONNECTION_POINT,
+		SubClassOf:      "connectionPoint",
+		PossSuperiors:   []string{"computer", "container", "organizationalUnit"},
+		MayContain:      []string{"serviceBindingInformation", "serviceClassName", "serviceDNSName"},
+	},
+	SCHEMA_CLASS_SITE: {
+		LDAPDisplayName: "site",
+		CN:              "Site",
+		SchemaIDGUID:    SCHEMA_CLASS_SITE,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"sitesContainer"},
+		MayContain:      []string{"gPLink", "gPOptions", "location", "managedBy"},
+	},
+	SCHEMA_CLASS_SITE_LINK: {
+		LDAPDisplayName: "siteLink",
+		CN:              "Site-Link",
+		SchemaIDGUID:    SCHEMA_CLASS_SITE_LINK,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"interSiteTransport"},
+		MustContain:     []string{"siteList"},
+		MayContain:      []string{"cost", "replInterval", "schedule"},
+	},
+	SCHEMA_CLASS_SUBNET: {
+		LDAPDisplayName: "subnet",
+		CN:              "Subnet",
+		SchemaIDGUID:    SCHEMA_CLASS_SUBNET,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"subnetContainer"},
+		MayContain:      []string{"location", "siteObject"},
+	},
+	SCHEMA_CLASS_TOP: {
+		LDAPDisplayName: "top",
+		CN:              "Top",
+		SchemaIDGUID:    SCHEMA_CLASS_TOP,
+		SubClassOf:      "top",
+		PossSuperiors:   []string{"lostAndFound"},
+		MustContain:     []string{"instanceType", "nTSecurityDescriptor", "objectCategory", "objectClass"},
+		MayContain:      []string{"description", "displayName", "distinguishedName", "name", "objectGUID", "whenChanged", "whenCreated"},
+	},
+	SCHEMA_CLASS_TRUSTED_DOMAIN: {
+		LDAPDisplayName: "trustedDomain",
+		CN:              "Trusted-Domain",
+		SchemaIDGUID:    SCHEMA_CLASS_TRUSTED_DOMAIN,
+		SubClassOf:      "leaf",
+		PossSuperiors:   []string{"container"},
+		MayContain:      []string{"flatName", "initialAuthIncoming", "initialAuthOutgoing", "securityIdentifier", "trustAttributes", "trustAuthIncoming", "trustAuthOutgoing", "trustDirection", "trustPartner", "trustType"},
+	},
+	SCHEMA_CLASS_VOLUME: {
+		LDAPDisplayName: "volume",
+		CN:              "Volume",
+		SchemaIDGUID:    SCHEMA_CLASS_VOLUME,
+		SubClassOf:      "connectionPoint",
+		PossSuperiors:   []string{"container", "organizationalUnit", "domainDNS"},
+		MustContain:     []string{"uNCName"},
+	},
+	SCHEMA_CLASS_USER: {
+		LDAPDisplayName: "user",
+		CN:              "User",
+		SchemaIDGUID:    SCHEMA_CLASS_USER,
+		SubClassOf:      "organizationalPerson",
+		PossSuperiors:   []string{"builtinDomain", "container", "domainDNS", "organizationalUnit"},
+		MayContain: []string{"accountExpires", "adminCount", "altSecurityIdentities", "badPwdCount", "homeDirectory", "lastLogonTimestamp",
+			"logonHours", "memberOf", "msDS-AllowedToDelegateTo", "msDS-KeyCredentialLink", "msDS-SupportedEncryptionTypes", "primaryGroupID",
+			"profilePath", "pwdLastSet", "sAMAccountName", "scriptPath", "servicePrincipalName", "unicodePwd", "userAccountControl",
+			"userCertificate", "userPrincipalName", "userWorkstations"},
+		DefaultSecurityDescriptor: "D:(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;DA)(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;SY)(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;AO)" +
+			"(A;;RPLCLORC;;;PS)(OA;;CR;ab721a53-1e2f-11d0-9819-00aa0040529b;;PS)(OA;;CR;ab721a54-1e2f-11d0-9819-00aa0040529b;;PS)" +
+			"(OA;;CR;ab721a56-1e2f-11d0-9819-00aa0040529b;;PS)(OA;;RPWP;77b5b886-944a-11d1-aebd-0000f80367c1;;PS)" +
+			"(OA;;RPWP;e45795b2-9455-11d1-aebd-0000f80367c1;;PS)(OA;;RPWP;e45795b3-9455-11d1-aebd-0000f80367c1;;PS)" +
+			"(OA;;RP;037088f8-0ae1-11d2-b422-00a0c968f939;;RS)(OA;;RP;4c164200-20c0-11d0-a768-00aa006e0529;;RS)" +
+			"(OA;;RP;bc0ac240-79a9-11d0-9020-00c04fc2d4cf;;RS)(A;;RC;;;AU)(OA;;RP;59ba2f42-79a2-11d0-9020-00c04fc2d3cf;;AU)" +
+			"(OA;;RP;77b5b886-944a-11d1-aebd-0000f80367c1;;AU)(OA;;RP;e45795b3-9455-11d1-aebd-0000f80367c1;;AU)" +
+			"(OA;;RP;e48d0154-bcf8-11d1-8702-00c04fb96050;;AU)(OA;;CR;ab721a53-1e2f-11d0-9819-00aa0040529b;;WD)" +
+			"(OA;;RP;5f202010-79a5-11d0-9020-00c04fc2d4cf;;RS)(OA;;RPWP;bf967a7f-0de6-11d0-a285-00aa003049e2;;CA)",
+	},
+}
+
+// SchemaClassDisplayNameToGUID maps the lDAPDisplayName of each built-in class to its schemaIDGUID.
+var SchemaClassDisplayNameToGUID = map[string]string{}
+
+// GUIDToSchemaClassDisplayName maps the schemaIDGUID of each built-in class to its lDAPDisplayName.
+var GUIDToSchemaClassDisplayName = map[string]string{}
+
+func init() {
+	for schemaIDGUID, schemaClass := range SchemaClasses {
+		SchemaClassDisplayNameToGUID[schemaClass.LDAPDisplayName] = schemaIDGUID
+		GUIDToSchemaClassDisplayName[schemaIDGUID] = schemaClass.LDAPDisplayName
+	}
+}
+
+// LookupSchemaClass returns the built-in classSchema description matching the given
+// schemaIDGUID or lDAPDisplayName. The comparison is case-insensitive.
+//
+// Parameters:
+//   - nameOrGUID (string): The schemaIDGUID (D format) or lDAPDisplayName of the class.
+//
+// Returns:
+//   - SchemaClass: The matching class description.
+//   - bool: true if the class was found, false otherwise.
+func LookupSchemaClass(nameOrGUID string) (SchemaClass, bool) {
+	if schemaClass, exists := SchemaClasses[strings.ToLower(nameOrGUID)]; exists {
+		return schemaClass, true
+	}
+	for _, schemaClass := range SchemaClasses {
+		if strings.EqualFold(schemaClass.LDAPDisplayName, nameOrGUID) {
+			return schemaClass, true
+		}
+	}
+	return SchemaClass{}, false
+}
+
+// IsSubClassOf reports whether the class derives, directly or through its SubClassOf chain,
+// from the class with the given lDAPDisplayName. A class is considered a subclass of itself.
+// The chain is only followed through classes present in the built-in catalog.
+//
+// Parameters:
+//   - ldapDisplayName (string): The lDAPDisplayName of the candidate ancestor class.
+//
+// Returns:
+//   - bool: true if the class derives from the given class, false otherwise.
+func (schemaClass SchemaClass) IsSubClassOf(ldapDisplayName string) bool {
+	current := schemaClass
+	for range len(SchemaClasses) + 1 {
+		if strings.EqualFold(current.LDAPDisplayName, ldapDisplayName) {
+			return true
+		}
+		if current.SubClassOf == "" || strings.EqualFold(current.SubClassOf, current.LDAPDisplayName) {
+			return false
+		}
+		parent, exists := LookupSchemaClass(current.SubClassOf)
+		if !exists {
+			return strings.EqualFold(current.SubClassOf, ldapDisplayName)
+		}
+		current = parent
+	}
+	return false
+}
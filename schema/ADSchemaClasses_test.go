@@ -0,0 +1,56 @@
+package schema
+
+import (
+	"testing"
+)
+
+func Test_SchemaClassDisplayNameToGUID_In_GUIDToSchemaClassDisplayName(t *testing.T) {
+	for schemaClassDisplayName, schemaClassGUID := range SchemaClassDisplayNameToGUID {
+		if _, exists := GUIDToSchemaClassDisplayName[schemaClassGUID]; !exists {
+			t.Errorf("Key %s from SchemaClassDisplayNameToGUID not found in GUIDToSchemaClassDisplayName", schemaClassDisplayName)
+		}
+	}
+}
+
+func Test_GUIDToSchemaClassDisplayName_In_SchemaClassDisplayNameToGUID(t *testing.T) {
+	for schemaClassGUID, schemaClassDisplayName := range GUIDToSchemaClassDisplayName {
+		if _, exists := SchemaClassDisplayNameToGUID[schemaClassDisplayName]; !exists {
+			t.Errorf("Key %s from GUIDToSchemaClassDisplayName not found in SchemaClassDisplayNameToGUID", schemaClassGUID)
+		}
+	}
+}
+
+func Test_SchemaClasses_KeyMatchesSchemaIDGUID(t *testing.T) {
+	for schemaClassGUID, schemaClass := range SchemaClasses {
+		if schemaClass.SchemaIDGUID != schemaClassGUID {
+			t.Errorf("SchemaClasses[%s].SchemaIDGUID = %s", schemaClassGUID, schemaClass.SchemaIDGUID)
+		}
+		if schemaClass.LDAPDisplayName == "" {
+			t.Errorf("SchemaClasses[%s] has no LDAPDisplayName", schemaClassGUID)
+		}
+	}
+}
+
+func TestLookupSchemaClass(t *testing.T) {
+	for _, nameOrGUID := range []string{"computer", "Computer", SCHEMA_CLASS_COMPUTER, "BF967A86-0DE6-11D0-A285-00AA003049E2"} {
+		schemaClass, exists := LookupSchemaClass(nameOrGUID)
+		if !exists || schemaClass.SchemaIDGUID != SCHEMA_CLASS_COMPUTER {
+			t.Errorf("LookupSchemaClass(%q) = %v, %v", nameOrGUID, schemaClass.LDAPDisplayName, exists)
+		}
+	}
+	if _, exists := LookupSchemaClass("notAClass"); exists {
+		t.Errorf("LookupSchemaClass(\"notAClass\") should not exist")
+	}
+}
+
+func TestSchemaClass_IsSubClassOf(t *testing.T) {
+	gmsa := SchemaClasses[SCHEMA_CLASS_MS_DS_GROUP_MANAGED_SERVICE_ACCOUNT]
+	for _, ancestor := range []string{"msDS-GroupManagedServiceAccount", "computer", "user", "person", "top"} {
+		if !gmsa.IsSubClassOf(ancestor) {
+			t.Errorf("msDS-GroupManagedServiceAccount should be a subclass of %s", ancestor)
+		}
+	}
+	if gmsa.IsSubClassOf("group") {
+		t.Errorf("msDS-GroupManagedServiceAccount should not be a subclass of group")
+	}
+}
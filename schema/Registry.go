@@ -0,0 +1,437 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/TheManticoreProject/winacl/rights"
+)
+
+// Registry holds classSchema, attributeSchema and controlAccessRight objects loaded at runtime
+// (for example from a schema export of a specific forest) on top of the built-in catalog.
+//
+// Lookups first consult the loaded objects and fall back to the built-in maps, so a registry
+// both overrides and extends them. A Registry is safe for concurrent use, and several
+// registries can be used side by side for different forests.
+type Registry struct {
+	mu sync.RWMutex
+
+	classes        map[string]SchemaClass
+	attributes     map[string]SchemaAttribute
+	extendedRights map[string][]rights.ExtendedRight
+
+	// Lowercased names to GUIDs, for lookups by name
+	classNames         map[string]string
+	attributeNames     map[string]string
+	extendedRightNames map[string]string
+}
+
+// defaultRegistry is the registry consulted by guid.GUID.LookupName and the analysis functions.
+var defaultRegistry atomic.Pointer[Registry]
+
+func init() {
+	defaultRegistry.Store(NewRegistry())
+}
+
+// NewRegistry creates an empty Registry backed by the built-in catalog.
+//
+// Returns:
+//   - *Registry: A pointer to the newly created registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		classes:            make(map[string]SchemaClass),
+		attributes:         make(map[string]SchemaAttribute),
+		extendedRights:     make(map[string][]rights.ExtendedRight),
+		classNames:         make(map[string]string),
+		attributeNames:     make(map[string]string),
+		extendedRightNames: make(map[string]string),
+	}
+}
+
+// GetDefaultRegistry returns the process-wide registry consulted by guid.GUID.LookupName and
+// the analysis functions. Unless replaced with SetDefaultRegistry, it only contains the built-in catalog.
+//
+// Returns:
+//   - *Registry: The default registry.
+func GetDefaultRegistry() *Registry {
+	return defaultRegistry.Load()
+}
+
+// SetDefaultRegistry replaces the process-wide registry. Passing nil restores an empty registry
+// backed by the built-in catalog.
+//
+// Parameters:
+//   - registry (*Registry): The new default registry.
+func SetDefaultRegistry(registry *Registry) {
+	if registry == nil {
+		registry = NewRegistry()
+	}
+	defaultRegistry.Store(registry)
+}
+
+// AddClass adds or replaces a class in the registry.
+//
+// Parameters:
+//   - schemaClass (SchemaClass): The class to add. Its SchemaIDGUID must be set.
+//
+// Returns:
+//   - error: An error if the class has no valid SchemaIDGUID.
+func (registry *Registry) AddClass(schemaClass SchemaClass) error {
+	schemaIDGUID, err := normalizeGUIDString(schemaClass.SchemaIDGUID)
+	if err != nil {
+		return fmt.Errorf("invalid schemaIDGUID of class %q: %w", schemaClass.LDAPDisplayName, err)
+	}
+	schemaClass.SchemaIDGUID = schemaIDGUID
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.classes[schemaIDGUID] = schemaClass
+	if schemaClass.LDAPDisplayName != "" {
+		registry.classNames[strings.ToLower(schemaClass.LDAPDisplayName)] = schemaIDGUID
+	}
+	return nil
+}
+
+// AddAttribute adds or replaces an attribute in the registry.
+//
+// Parameters:
+//   - schemaAttribute (SchemaAttribute): The attribute to add. Its SchemaIDGUID must be set.
+//
+// Returns:
+//   - error: An error if the attribute has no valid SchemaIDGUID or AttributeSecurityGUID.
+func (registry *Registry) AddAttribute(schemaAttribute SchemaAttribute) error {
+	schemaIDGUID, err := normalizeGUIDString(schemaAttribute.SchemaIDGUID)
+	if err != nil {
+		return fmt.Errorf("invalid schemaIDGUID of attribute %q: %w", schemaAttribute.LDAPDisplayName, err)
+	}
+	schemaAttribute.SchemaIDGUID = schemaIDGUID
+	if schemaAttribute.AttributeSecurityGUID != "" {
+		attributeSecurityGUID, err := normalizeGUIDString(schemaAttribute.AttributeSecurityGUID)
+		if err != nil {
+			return fmt.Errorf("invalid attributeSecurityGUID of attribute %q: %w", schemaAttribute.LDAPDisplayName, err)
+		}
+		schemaAttribute.AttributeSecurityGUID = attributeSecurityGUID
+	}
+	schemaAttribute.Name = strings.ToLower(schemaAttribute.Name)
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.attributes[schemaIDGUID] = schemaAttribute
+	if schemaAttribute.Name != "" {
+		registry.attributeNames[schemaAttribute.Name] = schemaIDGUID
+	}
+	if schemaAttribute.LDAPDisplayName != "" {
+		registry.attributeNames[strings.ToLower(schemaAttribute.LDAPDisplayName)] = schemaIDGUID
+	}
+	return nil
+}
+
+// AddExtendedRight adds or replaces a controlAccessRight object in the registry. A right replaces
+// a previously added right with the same rightsGuid and common name; rights with the same rightsGuid
+// but different names (e.g. a validated write and a property set) are kept side by side.
+//
+// Parameters:
+//   - extendedRight (rights.ExtendedRight): The right to add. Its RightsGUID must be set.
+//
+// Returns:
+//   - error: An error if the right has no valid RightsGUID.
+func (registry *Registry) AddExtendedRight(extendedRight rights.ExtendedRight) error {
+	rightsGUID, err := normalizeGUIDString(extendedRight.RightsGUID)
+	if err != nil {
+		return fmt.Errorf("invalid rightsGuid of control access right %q: %w", extendedRight.Name, err)
+	}
+	extendedRight.RightsGUID = rightsGUID
+	appliesTo := make([]string, 0, len(extendedRight.AppliesTo))
+	for _, schemaIDGUID := range extendedRight.AppliesTo {
+		if normalized, err := normalizeGUIDString(schemaIDGUID); err == nil {
+			appliesTo = append(appliesTo, normalized)
+		}
+	}
+	extendedRight.AppliesTo = appliesTo
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	replaced := false
+	for index, existing := range registry.extendedRights[rightsGUID] {
+		if strings.EqualFold(existing.Name, extendedRight.Name) {
+			registry.extendedRights[rightsGUID][index] = extendedRight
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		registry.extendedRights[rightsGUID] = append(registry.extendedRights[rightsGUID], extendedRight)
+	}
+	if extendedRight.Name != "" {
+		registry.extendedRightNames[strings.ToLower(extendedRight.Name)] = rightsGUID
+	}
+	if extendedRight.DisplayName != "" {
+		registry.extendedRightNames[strings.ToLower(extendedRight.DisplayName)] = rightsGUID
+	}
+	return nil
+}
+
+// LookupClass returns the class matching the given schemaIDGUID or lDAPDisplayName, looking
+// at the loaded classes first and at the built-in catalog second.
+//
+// Parameters:
+//   - nameOrGUID (string): The schemaIDGUID (D format) or lDAPDisplayName of the class.
+//
+// Returns:
+//   - SchemaClass: The matching class description.
+//   - bool: true if the class was found, false otherwise.
+func (registry *Registry) LookupClass(nameOrGUID string) (SchemaClass, bool) {
+	key := strings.ToLower(nameOrGUID)
+
+	registry.mu.RLock()
+	schemaClass, exists := registry.classes[key]
+	if !exists {
+		if schemaIDGUID, found := registry.classNames[key]; found {
+			schemaClass, exists = registry.classes[schemaIDGUID]
+		}
+	}
+	registry.mu.RUnlock()
+
+	if exists {
+		return schemaClass, true
+	}
+	return LookupSchemaClass(nameOrGUID)
+}
+
+// LookupAttribute returns the attribute matching the given schemaIDGUID, name or lDAPDisplayName,
+// looking at the loaded attributes first and at the built-in catalog second.
+//
+// Parameters:
+//   - nameOrGUID (string): The schemaIDGUID (D format), name or lDAPDisplayName of the attribute.
+//
+// Returns:
+//   - SchemaAttribute: The matching attribute description.
+//   - bool: true if the attribute was found, false otherwise.
+func (registry *Registry) LookupAttribute(nameOrGUID string) (SchemaAttribute, bool) {
+	key := strings.ToLower(nameOrGUID)
+
+	registry.mu.RLock()
+	schemaAttribute, exists := registry.attributes[key]
+	if !exists {
+		if schemaIDGUID, found := registry.attributeNames[key]; found {
+			schemaAttribute, exists = registry.attributes[schemaIDGUID]
+		}
+	}
+	registry.mu.RUnlock()
+
+	if exists {
+		return schemaAttribute, true
+	}
+	return LookupSchemaAttribute(nameOrGUID)
+}
+
+// LookupExtendedRight returns the controlAccessRight object matching the given rightsGuid, common
+// name or displayName, looking at the loaded rights first and at the built-in catalog second.
+//
+// Parameters:
+//   - nameOrGUID (string): The rightsGuid (D format), name or displayName of the right.
+//
+// Returns:
+//   - rights.ExtendedRight: The matching right.
+//   - bool: true if the right was found, false otherwise.
+func (registry *Registry) LookupExtendedRight(nameOrGUID string) (rights.ExtendedRight, bool) {
+	key := strings.ToLower(nameOrGUID)
+
+	registry.mu.RLock()
+	extendedRight, exists := registry.lookupLoadedExtendedRight(key)
+	registry.mu.RUnlock()
+
+	if exists {
+		return extendedRight, true
+	}
+	return rights.LookupExtendedRight(nameOrGUID)
+}
+
+// lookupLoadedExtendedRight looks up a loaded right by lowercased rightsGuid or name. The caller
+// must hold the read lock.
+func (registry *Registry) lookupLoadedExtendedRight(key string) (rights.ExtendedRight, bool) {
+	if extendedRights, exists := registry.extendedRights[key]; exists && len(extendedRights) != 0 {
+		return extendedRights[0], true
+	}
+	rightsGUID, exists := registry.extendedRightNames[key]
+	if !exists {
+		return rights.ExtendedRight{}, false
+	}
+	for _, extendedRight := range registry.extendedRights[rightsGUID] {
+		if strings.EqualFold(extendedRight.Name, key) || strings.EqualFold(extendedRight.DisplayName, key) {
+			return extendedRight, true
+		}
+	}
+	return rights.ExtendedRight{}, false
+}
+
+// LookupExtendedRightForAccess returns the controlAccessRight object with the given rightsGuid that
+// is valid for the access mask of the ACE referencing it, looking at the loaded rights first and at
+// the built-in catalog second. This distinguishes rights sharing a rightsGuid, such as the
+// Validated-DNS-Host-Name validated write and the DNS-Host-Name-Attributes property set.
+//
+// Parameters:
+//   - rightsGUID (string): The rightsGuid of the right, in D format.
+//   - accessMask (uint32): The access mask of the ACE referencing the right.
+//
+// Returns:
+//   - rights.ExtendedRight: The matching right.
+//   - bool: true if a right with this rightsGuid was found, false otherwise.
+func (registry *Registry) LookupExtendedRightForAccess(rightsGUID string, accessMask uint32) (rights.ExtendedRight, bool) {
+	key := strings.ToLower(rightsGUID)
+
+	registry.mu.RLock()
+	loaded := registry.extendedRights[key]
+	registry.mu.RUnlock()
+
+	for _, extendedRight := range loaded {
+		if extendedRight.IsValidFor(accessMask) {
+			return extendedRight, true
+		}
+	}
+	if extendedRight, exists := rights.LookupExtendedRightForAccess(key, accessMask); exists {
+		if len(loaded) == 0 || extendedRight.IsValidFor(accessMask) {
+			return extendedRight, true
+		}
+	}
+	if len(loaded) != 0 {
+		return loaded[0], true
+	}
+	return rights.ExtendedRight{}, false
+}
+
+// IsExtendedRightApplicable reports whether a controlAccessRight can be used on objects of a class.
+// The right applies if one of its AppliesTo classes is the class itself or one of its ancestors in
+// the class hierarchy. Unknown rights, unknown classes and rights with an empty AppliesTo list are
+// considered applicable, so that only known mismatches are reported.
+//
+// Parameters:
+//   - rightsGUID (string): The rightsGuid of the right, in D format.
+//   - accessMask (uint32): The access mask of the ACE referencing the right.
+//   - classNameOrGUID (string): The schemaIDGUID (D format) or lDAPDisplayName of the object's class.
+//
+// Returns:
+//   - bool: false if the right is known not to apply to the class, true otherwise.
+func (registry *Registry) IsExtendedRightApplicable(rightsGUID string, accessMask uint32, classNameOrGUID string) bool {
+	extendedRight, exists := registry.LookupExtendedRightForAccess(rightsGUID, accessMask)
+	if !exists || len(extendedRight.AppliesTo) == 0 {
+		return true
+	}
+	schemaClass, exists := registry.LookupClass(classNameOrGUID)
+	if !exists {
+		return true
+	}
+	for range len(SchemaClasses) + 1 {
+		if extendedRight.AppliesToClass(schemaClass.SchemaIDGUID) {
+			return true
+		}
+		if schemaClass.SubClassOf == "" || strings.EqualFold(schemaClass.SubClassOf, schemaClass.LDAPDisplayName) {
+			return false
+		}
+		parent, exists := registry.LookupClass(schemaClass.SubClassOf)
+		if !exists {
+			return false
+		}
+		schemaClass = parent
+	}
+	return false
+}
+
+// GetPropertySetAttributes expands a property set into the attributes it contains, taking the
+// loaded attributes into account.
+//
+// Parameters:
+//   - propertySetGUID (string): The GUID of the property set, in D format.
+//
+// Returns:
+//   - []SchemaAttribute: The attributes of the property set, sorted by Name.
+func (registry *Registry) GetPropertySetAttributes(propertySetGUID string) []SchemaAttribute {
+	propertySetGUID = strings.ToLower(propertySetGUID)
+
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	schemaAttributes := make([]SchemaAttribute, 0)
+	for _, schemaAttribute := range GetPropertySetAttributes(propertySetGUID) {
+		if _, overridden := registry.attributes[schemaAttribute.SchemaIDGUID]; !overridden {
+			schemaAttributes = append(schemaAttributes, schemaAttribute)
+		}
+	}
+	for _, schemaAttribute := range registry.attributes {
+		if schemaAttribute.AttributeSecurityGUID == propertySetGUID {
+			schemaAttributes = append(schemaAttributes, schemaAttribute)
+		}
+	}
+	sort.Slice(schemaAttributes, func(i, j int) bool {
+		return schemaAttributes[i].Name < schemaAttributes[j].Name
+	})
+	return schemaAttributes
+}
+
+// LookupName returns a human readable name for a GUID found in an ACE: an extended right,
+// a property set, a schema class or a schema attribute. Loaded objects take precedence over
+// the built-in catalog.
+//
+// Parameters:
+//   - formatD (string): The GUID in D format.
+//
+// Returns:
+//   - string: The name of the GUID, or "?" if the GUID is unknown.
+func (registry *Registry) LookupName(formatD string) string {
+	key := strings.ToLower(formatD)
+
+	registry.mu.RLock()
+	extendedRight, isExtendedRight := registry.lookupLoadedExtendedRight(key)
+	schemaClass, isClass := registry.classes[key]
+	schemaAttribute, isAttribute := registry.attributes[key]
+	registry.mu.RUnlock()
+
+	if isExtendedRight {
+		return extendedRightConstantName(extendedRight)
+	} else if isClass {
+		return fmt.Sprintf("LDAP Class: %s", schemaClass.LDAPDisplayName)
+	} else if isAttribute {
+		return fmt.Sprintf("LDAP Attribute: %s", schemaAttribute.Name)
+	}
+
+	if name, exists := rights.GUIDToExtendedRight[key]; exists {
+		return name
+	} else if name, exists := GUIDToPropertySet[key]; exists {
+		return name
+	} else if name, exists := GUIDToSchemaClassDisplayName[key]; exists {
+		// Checked before the attribute table, which also lists the schemaIDGUIDs
+		// of classSchema objects under their CN.
+		return fmt.Sprintf("LDAP Class: %s", name)
+	} else if name, exists := GUIDToSchemaAttributeDisplayName[key]; exists {
+		return fmt.Sprintf("LDAP Attribute: %s", name)
+	}
+	return "?"
+}
+
+// extendedRightConstantName names a loaded controlAccessRight the way the built-in constants do,
+// e.g. "EXTENDED_RIGHT_DS_REPLICATION_GET_CHANGES", "VALIDATED_WRITE_SPN" or "PROPERTY_SET_ACCOUNT_RESTRICTIONS".
+func extendedRightConstantName(extendedRight rights.ExtendedRight) string {
+	toConstant := strings.NewReplacer("-", "_", " ", "_")
+
+	name := extendedRight.Name
+	if name == "" {
+		name = extendedRight.DisplayName
+	}
+	switch extendedRight.Kind() {
+	case rights.EXTENDED_RIGHT_KIND_PROPERTY_SET:
+		if extendedRight.DisplayName != "" {
+			name = extendedRight.DisplayName
+		}
+		return "PROPERTY_SET_" + strings.ToUpper(toConstant.Replace(name))
+	case rights.EXTENDED_RIGHT_KIND_VALIDATED_WRITE:
+		return "VALIDATED_WRITE_" + strings.ToUpper(toConstant.Replace(strings.TrimPrefix(name, "Validated-")))
+	default:
+		return "EXTENDED_RIGHT_" + strings.ToUpper(toConstant.Replace(name))
+	}
+}
@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/rights"
+)
+
+// TestSchema_ConcurrentLookups reads the global maps of the package and the default registry
+// from many goroutines while a registry is being loaded. The global maps are only written by
+// their initializers, run with `go test -race` to check it.
+func TestSchema_ConcurrentLookups(t *testing.T) {
+	defer SetDefaultRegistry(nil)
+	registry := NewRegistry()
+
+	waitGroup := sync.WaitGroup{}
+	for worker := 0; worker < 8; worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for iteration := 0; iteration < 100; iteration++ {
+				if _, found := LookupSchemaClass("user"); !found {
+					t.Errorf("LookupSchemaClass(user) not found")
+				}
+				if _, found := LookupSchemaAttribute("member"); !found {
+					t.Errorf("LookupSchemaAttribute(member) not found")
+				}
+				_ = GetPropertySetAttributes("4c164200-20c0-11d0-a768-00aa006e0529")
+				_ = GetDefaultRegistry().LookupName("bf967aba-0de6-11d0-a285-00aa003049e2")
+				_ = registry.LookupName(fmt.Sprintf("00000000-0000-0000-0000-%012x", iteration))
+				_, _ = registry.LookupExtendedRight("Custom-Right-1")
+			}
+		}()
+	}
+
+	// Writer, loads the registry and swaps the default registry
+	waitGroup.Add(1)
+	go func() {
+		defer waitGroup.Done()
+		for iteration := 0; iteration < 100; iteration++ {
+			err := registry.AddExtendedRight(rights.ExtendedRight{
+				Name:          fmt.Sprintf("Custom-Right-%d", iteration),
+				RightsGUID:    fmt.Sprintf("00000000-0000-0000-0000-%012x", iteration),
+				ValidAccesses: 0x100,
+			})
+			if err != nil {
+				t.Errorf("AddExtendedRight() error = %v", err)
+			}
+			SetDefaultRegistry(registry)
+		}
+	}()
+	waitGroup.Wait()
+}
@@ -0,0 +1,466 @@
+package schema
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/TheManticoreProject/winacl/rights"
+)
+
+var guidFormatDRegex = regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$")
+
+// ldapEntry is a directory object read from a schema export, keyed by lowercased attribute name.
+type ldapEntry struct {
+	DN         string
+	Attributes map[string][][]byte
+}
+
+// LoadLDIF loads the classSchema, attributeSchema and controlAccessRight objects of an LDIF
+// export (as written by ldifde or ldapsearch) into the registry. Other objects are ignored.
+// Nothing is added to the registry if the export cannot be parsed.
+//
+// Parameters:
+//   - reader (io.Reader): The LDIF export.
+//
+// Returns:
+//   - error: An error if the export is malformed or an object lacks a valid GUID.
+func (registry *Registry) LoadLDIF(reader io.Reader) error {
+	entries, err := parseLDIF(reader)
+	if err != nil {
+		return err
+	}
+	return registry.loadEntries(entries)
+}
+
+// LoadJSON loads the classSchema, attributeSchema and controlAccessRight objects of a JSON
+// export into the registry. Other objects are ignored. Nothing is added to the registry if
+// the export cannot be parsed.
+//
+// The export is either an array of objects, or an object whose values are arrays of objects.
+// Each object maps attribute names (case-insensitive) to a value or an array of values. GUIDs
+// can be given in D format, as base64 or as an array of 16 bytes.
+//
+// Parameters:
+//   - reader (io.Reader): The JSON export.
+//
+// Returns:
+//   - error: An error if the export is malformed or an object lacks a valid GUID.
+func (registry *Registry) LoadJSON(reader io.Reader) error {
+	entries, err := parseJSONExport(reader)
+	if err != nil {
+		return err
+	}
+	return registry.loadEntries(entries)
+}
+
+// loadEntries converts the entries into schema objects and adds them to the registry.
+func (registry *Registry) loadEntries(entries []ldapEntry) error {
+	schemaClasses := make([]SchemaClass, 0)
+	schemaAttributes := make([]SchemaAttribute, 0)
+	extendedRights := make([]rights.ExtendedRight, 0)
+
+	for _, entry := range entries {
+		objectClasses := make([]string, 0)
+		for _, value := range entry.Attributes["objectclass"] {
+			objectClasses = append(objectClasses, strings.ToLower(string(value)))
+		}
+
+		switch {
+		case slices.Contains(objectClasses, "classschema"):
+			schemaClass, err := entry.toSchemaClass()
+			if err != nil {
+				return fmt.Errorf("failed to load classSchema %q: %w", entry.DN, err)
+			}
+			schemaClasses = append(schemaClasses, schemaClass)
+		case slices.Contains(objectClasses, "attributeschema"):
+			schemaAttribute, err := entry.toSchemaAttribute()
+			if err != nil {
+				return fmt.Errorf("failed to load attributeSchema %q: %w", entry.DN, err)
+			}
+			schemaAttributes = append(schemaAttributes, schemaAttribute)
+		case slices.Contains(objectClasses, "controlaccessright"):
+			extendedRight, err := entry.toExtendedRight()
+			if err != nil {
+				return fmt.Errorf("failed to load controlAccessRight %q: %w", entry.DN, err)
+			}
+			extendedRights = append(extendedRights, extendedRight)
+		}
+	}
+
+	// The GUIDs have already been validated, so adding cannot fail
+	for _, schemaClass := range schemaClasses {
+		_ = registry.AddClass(schemaClass)
+	}
+	for _, schemaAttribute := range schemaAttributes {
+		_ = registry.AddAttribute(schemaAttribute)
+	}
+	for _, extendedRight := range extendedRights {
+		_ = registry.AddExtendedRight(extendedRight)
+	}
+
+	return nil
+}
+
+func (entry *ldapEntry) toSchemaClass() (SchemaClass, error) {
+	schemaIDGUID, err := entry.guid("schemaidguid")
+	if err != nil {
+		return SchemaClass{}, err
+	}
+	return SchemaClass{
+		LDAPDisplayName:           entry.string("ldapdisplayname"),
+		CN:                        entry.string("cn"),
+		SchemaIDGUID:              schemaIDGUID,
+		SubClassOf:                entry.string("subclassof"),
+		PossSuperiors:             entry.strings("possSuperiors", "systemPossSuperiors"),
+		MustContain:               entry.strings("mustContain", "systemMustContain"),
+		MayContain:                entry.strings("mayContain", "systemMayContain"),
+		DefaultSecurityDescriptor: entry.string("defaultsecuritydescriptor"),
+	}, nil
+}
+
+func (entry *ldapEntry) toSchemaAttribute() (SchemaAttribute, error) {
+	schemaIDGUID, err := entry.guid("schemaidguid")
+	if err != nil {
+		return SchemaAttribute{}, err
+	}
+	attributeSecurityGUID := ""
+	if len(entry.Attributes["attributesecurityguid"]) != 0 {
+		attributeSecurityGUID, err = entry.guid("attributesecurityguid")
+		if err != nil {
+			return SchemaAttribute{}, err
+		}
+	}
+	return SchemaAttribute{
+		Name:                  strings.ToLower(entry.string("cn")),
+		LDAPDisplayName:       entry.string("ldapdisplayname"),
+		SchemaIDGUID:          schemaIDGUID,
+		AttributeSecurityGUID: attributeSecurityGUID,
+		SearchFlags:           entry.uint32("searchflags"),
+		SystemFlags:           entry.uint32("systemflags"),
+		AttributeSyntax:       entry.string("attributesyntax"),
+		OMSyntax:              int(entry.uint32("omsyntax")),
+		IsSingleValued:        strings.EqualFold(entry.string("issinglevalued"), "TRUE"),
+	}, nil
+}
+
+func (entry *ldapEntry) toExtendedRight() (rights.ExtendedRight, error) {
+	rightsGUID, err := entry.guid("rightsguid")
+	if err != nil {
+		return rights.ExtendedRight{}, err
+	}
+	appliesTo := make([]string, 0)
+	for _, value := range entry.Attributes["appliesto"] {
+		if schemaIDGUID, err := decodeGUIDValue(value); err == nil {
+			appliesTo = append(appliesTo, schemaIDGUID)
+		}
+	}
+	return rights.ExtendedRight{
+		Name:          entry.string("cn"),
+		DisplayName:   entry.string("displayname"),
+		RightsGUID:    rightsGUID,
+		ValidAccesses: entry.uint32("validaccesses"),
+		AppliesTo:     appliesTo,
+	}, nil
+}
+
+// string returns the first value of an attribute, or an empty string.
+func (entry *ldapEntry) string(attribute string) string {
+	values := entry.Attributes[attribute]
+	if len(values) == 0 {
+		return ""
+	}
+	return string(values[0])
+}
+
+// strings returns all the values of the given attributes.
+func (entry *ldapEntry) strings(attributes ...string) []string {
+	values := make([]string, 0)
+	for _, attribute := range attributes {
+		for _, value := range entry.Attributes[strings.ToLower(attribute)] {
+			values = append(values, string(value))
+		}
+	}
+	return values
+}
+
+// uint32 returns the first value of an integer attribute. Active Directory stores flags as
+// signed 32-bit integers, so negative values are reinterpreted as unsigned.
+func (entry *ldapEntry) uint32(attribute string) uint32 {
+	value, err := strconv.ParseInt(strings.TrimSpace(entry.string(attribute)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint32(value)
+}
+
+// guid returns the first value of a GUID attribute in lowercase D format.
+func (entry *ldapEntry) guid(attribute string) (string, error) {
+	values := entry.Attributes[attribute]
+	if len(values) == 0 {
+		return "", fmt.Errorf("missing attribute %s", attribute)
+	}
+	formatD, err := decodeGUIDValue(values[0])
+	if err != nil {
+		return "", fmt.Errorf("invalid attribute %s: %w", attribute, err)
+	}
+	return formatD, nil
+}
+
+// decodeGUIDValue decodes a GUID given as 16 raw bytes, as a string in D or B format, or as base64.
+func decodeGUIDValue(value []byte) (string, error) {
+	if len(value) == 16 {
+		return guidBytesToFormatD(value), nil
+	}
+	if formatD, err := normalizeGUIDString(string(value)); err == nil {
+		return formatD, nil
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(value))); err == nil && len(decoded) == 16 {
+		return guidBytesToFormatD(decoded), nil
+	}
+	return "", fmt.Errorf("cannot decode GUID value %q", value)
+}
+
+// guidBytesToFormatD converts the 16-byte binary (mixed-endian) form of a GUID to its D format.
+func guidBytesToFormatD(data []byte) string {
+	return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x",
+		binary.LittleEndian.Uint32(data[0:4]),
+		binary.LittleEndian.Uint16(data[4:6]),
+		binary.LittleEndian.Uint16(data[6:8]),
+		binary.BigEndian.Uint16(data[8:10]),
+		data[10:16],
+	)
+}
+
+// normalizeGUIDString returns a GUID in D or B format as lowercase D format.
+func normalizeGUIDString(value string) (string, error) {
+	formatD := strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(value), "{"), "}"))
+	if !guidFormatDRegex.MatchString(formatD) {
+		return "", fmt.Errorf("invalid GUID %q", value)
+	}
+	return formatD, nil
+}
+
+// parseLDIF parses the content records of an LDIF file (RFC 2849).
+func parseLDIF(reader io.Reader) ([]ldapEntry, error) {
+	entries := make([]ldapEntry, 0)
+	current := ldapEntry{Attributes: make(map[string][][]byte)}
+	lines := make([]string, 0)
+
+	flush := func() error {
+		for _, line := range lines {
+			if err := current.addLDIFLine(line); err != nil {
+				return err
+			}
+		}
+		if current.DN != "" || len(current.Attributes) != 0 {
+			entries = append(entries, current)
+		}
+		current = ldapEntry{Attributes: make(map[string][][]byte)}
+		lines = lines[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		switch {
+		case line == "":
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(line, " "):
+			// Continuation of the previous line
+			if len(lines) == 0 {
+				return nil, fmt.Errorf("line %d: continuation line without a preceding line", lineNumber)
+			}
+			lines[len(lines)-1] += line[1:]
+		case strings.HasPrefix(line, "#"):
+			// Comments can be continued as well, keep them to absorb their continuation lines
+			lines = append(lines, line)
+		default:
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read LDIF: %w", err)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// addLDIFLine adds an unfolded "attribute: value" or "attribute:: base64" line to the entry.
+func (entry *ldapEntry) addLDIFLine(line string) error {
+	if strings.HasPrefix(line, "#") {
+		return nil
+	}
+	separator := strings.Index(line, ":")
+	if separator <= 0 {
+		return fmt.Errorf("invalid LDIF line %q", line)
+	}
+	attribute := strings.ToLower(line[:separator])
+	// Strip attribute options such as ";binary" or ";range=0-1499"
+	if option := strings.Index(attribute, ";"); option != -1 {
+		attribute = attribute[:option]
+	}
+	rest := line[separator+1:]
+
+	var value []byte
+	switch {
+	case strings.HasPrefix(rest, ":"):
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(rest[1:]))
+		if err != nil {
+			return fmt.Errorf("invalid base64 value for attribute %s: %w", attribute, err)
+		}
+		value = decoded
+	case strings.HasPrefix(rest, "<"):
+		// URL references are not followed
+		return nil
+	default:
+		value = []byte(strings.TrimLeft(rest, " "))
+	}
+
+	switch attribute {
+	case "version", "changetype", "control":
+		return nil
+	case "dn":
+		entry.DN = string(value)
+	default:
+		entry.Attributes[attribute] = append(entry.Attributes[attribute], value)
+	}
+	return nil
+}
+
+// parseJSONExport parses a JSON export of directory objects.
+func parseJSONExport(reader io.Reader) ([]ldapEntry, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	objects := make([]map[string]any, 0)
+	switch document := document.(type) {
+	case []any:
+		for index, item := range document {
+			object, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("JSON array element %d is not an object", index)
+			}
+			objects = append(objects, object)
+		}
+	case map[string]any:
+		if hasKeyFold(document, "objectClass") {
+			objects = append(objects, document)
+			break
+		}
+		for _, value := range document {
+			items, ok := value.([]any)
+			if !ok {
+				continue
+			}
+			for _, item := range items {
+				if object, ok := item.(map[string]any); ok {
+					objects = append(objects, object)
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("JSON export must be an array or an object")
+	}
+
+	entries := make([]ldapEntry, 0, len(objects))
+	for _, object := range objects {
+		entry := ldapEntry{Attributes: make(map[string][][]byte)}
+		for key, value := range object {
+			attribute := strings.ToLower(key)
+			if attribute == "dn" || attribute == "distinguishedname" {
+				if dn, ok := value.(string); ok {
+					entry.DN = dn
+				}
+			}
+			entry.Attributes[attribute] = jsonValues(attribute, value)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// jsonValues converts a JSON value into LDAP attribute values. An array of numbers is read as
+// raw bytes for GUID attributes, and as multiple integer values otherwise.
+func jsonValues(attribute string, value any) [][]byte {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return [][]byte{[]byte(value)}
+	case json.Number:
+		return [][]byte{[]byte(value.String())}
+	case bool:
+		return [][]byte{[]byte(strings.ToUpper(strconv.FormatBool(value)))}
+	case []any:
+		if strings.HasSuffix(attribute, "guid") {
+			if raw, ok := jsonBytes(value); ok {
+				return [][]byte{raw}
+			}
+		}
+		values := make([][]byte, 0, len(value))
+		for _, item := range value {
+			values = append(values, jsonValues(attribute, item)...)
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// jsonBytes converts an array of JSON numbers in the range 0-255 to bytes.
+func jsonBytes(items []any) ([]byte, bool) {
+	raw := make([]byte, 0, len(items))
+	for _, item := range items {
+		number, ok := item.(json.Number)
+		if !ok {
+			return nil, false
+		}
+		value, err := strconv.ParseUint(number.String(), 10, 8)
+		if err != nil {
+			return nil, false
+		}
+		raw = append(raw, byte(value))
+	}
+	return raw, len(raw) != 0
+}
+
+func hasKeyFold(object map[string]any, key string) bool {
+	for candidate := range object {
+		if strings.EqualFold(candidate, key) {
+			return true
+		}
+	}
+	return false
+}
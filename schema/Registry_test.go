@@ -0,0 +1,274 @@
+package schema
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/rights"
+)
+
+const testSchemaLDIF = `version: 1
+
+# LAPS extension
+dn: CN=ms-Mcs-AdmPwd,CN=Schema,CN=Configuration,DC=corp,DC=local
+changetype: add
+objectClass: top
+objectClass: attributeSchema
+cn: ms-Mcs-AdmPwd
+lDAPDisplayName: ms-Mcs-AdmPwd
+schemaIDGUID:: eFY0EjQSeFaavN7wEjRWeA==
+attributeSecurityGUID:: AEIWTMAg0BGnaACqAG4FKQ==
+attributeSyntax: 2.5.5.5
+oMSyntax: 19
+isSingleValued: TRUE
+searchFlags: 904
+systemFlags: -2147483648
+
+dn: CN=User,CN=Schema,CN=Configuration,DC=corp,DC=local
+objectClass: top
+objectClass: classSchema
+cn: User
+lDAPDisplayName: user
+schemaIDGUID:: unqWv+YN0BGihQCqADBJ4g==
+subClassOf: organizationalPerson
+systemPossSuperiors: builtinDomain
+possSuperiors: organizationalUnit
+systemMayContain: userAccountControl
+mayContain: ms-Mcs-AdmPwd
+defaultSecurityDescriptor: D:(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;DA)(A;;RPLCLO
+ RC;;;AU)
+
+dn: CN=Custom-Right,CN=Extended-Rights,CN=Configuration,DC=corp,DC=local
+objectClass: top
+objectClass: controlAccessRight
+cn: Custom-Right
+displayName: Custom Right
+rightsGuid: 0a1b2c3d-4e5f-6071-8293-a4b5c6d7e8f9
+validAccesses: 256
+appliesTo: bf967aba-0de6-11d0-a285-00aa003049e2
+`
+
+const testSchemaJSON = `[
+	{
+		"distinguishedName": "CN=Contoso-Widget,CN=Schema,CN=Configuration,DC=corp,DC=local",
+		"objectClass": ["top", "classSchema"],
+		"cn": "Contoso-Widget",
+		"lDAPDisplayName": "contosoWidget",
+		"schemaIDGUID": [120, 86, 52, 18, 52, 18, 120, 86, 154, 188, 222, 240, 18, 52, 86, 121],
+		"subClassOf": "top",
+		"systemPossSuperiors": ["organizationalUnit", "container"]
+	},
+	{
+		"objectClass": ["top", "controlAccessRight"],
+		"cn": "Contoso-Property-Set",
+		"displayName": "Contoso Widget Settings",
+		"rightsGuid": "{0A1B2C3D-4E5F-6071-8293-A4B5C6D7E8FA}",
+		"validAccesses": 48
+	},
+	{
+		"objectClass": ["top", "attributeSchema"],
+		"cn": "Contoso-Widget-Color",
+		"lDAPDisplayName": "contosoWidgetColor",
+		"schemaIDGUID": "0a1b2c3d-4e5f-6071-8293-a4b5c6d7e8fb",
+		"attributeSecurityGUID": "0a1b2c3d-4e5f-6071-8293-a4b5c6d7e8fa",
+		"isSingleValued": true,
+		"searchFlags": 0
+	}
+]`
+
+func TestRegistry_LoadLDIF(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.LoadLDIF(strings.NewReader(testSchemaLDIF)); err != nil {
+		t.Fatalf("LoadLDIF() error = %v", err)
+	}
+
+	admPwd, exists := registry.LookupAttribute("MS-MCS-ADMPWD")
+	if !exists {
+		t.Fatalf("ms-Mcs-AdmPwd not found in registry")
+	}
+	if admPwd.SchemaIDGUID != "12345678-1234-5678-9abc-def012345678" {
+		t.Errorf("SchemaIDGUID = %s", admPwd.SchemaIDGUID)
+	}
+	if !admPwd.IsConfidential() || !admPwd.IsRODCFiltered() || !admPwd.IsSingleValued {
+		t.Errorf("unexpected metadata %+v", admPwd)
+	}
+	if admPwd.SystemFlags != 0x80000000 {
+		t.Errorf("SystemFlags = 0x%x, want 0x80000000", admPwd.SystemFlags)
+	}
+	if !admPwd.IsInPropertySet(PROPERTY_SET_ACCOUNT_RESTRICTIONS) {
+		t.Errorf("AttributeSecurityGUID = %s", admPwd.AttributeSecurityGUID)
+	}
+
+	user, exists := registry.LookupClass("user")
+	if !exists || user.SchemaIDGUID != SCHEMA_CLASS_USER {
+		t.Fatalf("LookupClass(user) = %+v, %v", user, exists)
+	}
+	if len(user.PossSuperiors) != 2 || len(user.MayContain) != 2 {
+		t.Errorf("unexpected class %+v", user)
+	}
+	if user.DefaultSecurityDescriptor != "D:(A;;RPWPCRCCDCLCLORCWOWDSDDTSW;;;DA)(A;;RPLCLORC;;;AU)" {
+		t.Errorf("folded LDIF line not unfolded: %q", user.DefaultSecurityDescriptor)
+	}
+
+	customRight, exists := registry.LookupExtendedRight("Custom Right")
+	if !exists || customRight.ValidAccesses != 0x100 || len(customRight.AppliesTo) != 1 {
+		t.Errorf("LookupExtendedRight(Custom Right) = %+v, %v", customRight, exists)
+	}
+
+	tests := map[string]string{
+		"12345678-1234-5678-9abc-def012345678": "LDAP Attribute: ms-mcs-admpwd",
+		"0a1b2c3d-4e5f-6071-8293-a4b5c6d7e8f9": "EXTENDED_RIGHT_CUSTOM_RIGHT",
+		SCHEMA_CLASS_COMPUTER:                  "LDAP Class: computer",
+		PROPERTY_SET_ACCOUNT_RESTRICTIONS:      "PROPERTY_SET_ACCOUNT_RESTRICTIONS",
+		"00000000-0000-0000-0000-000000000000": "?",
+	}
+	for formatD, expected := range tests {
+		if name := registry.LookupName(formatD); name != expected {
+			t.Errorf("LookupName(%s) = %q, want %q", formatD, name, expected)
+		}
+	}
+
+	// The property set now expands to the loaded attribute as well
+	found := false
+	for _, schemaAttribute := range registry.GetPropertySetAttributes(PROPERTY_SET_ACCOUNT_RESTRICTIONS) {
+		found = found || schemaAttribute.LDAPDisplayName == "ms-Mcs-AdmPwd"
+	}
+	if !found {
+		t.Errorf("GetPropertySetAttributes(Account Restrictions) does not contain ms-Mcs-AdmPwd")
+	}
+}
+
+func TestRegistry_LoadJSON(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.LoadJSON(strings.NewReader(testSchemaJSON)); err != nil {
+		t.Fatalf("LoadJSON() error = %v", err)
+	}
+
+	widget, exists := registry.LookupClass("contosoWidget")
+	if !exists || widget.SchemaIDGUID != "12345678-1234-5678-9abc-def012345679" {
+		t.Errorf("LookupClass(contosoWidget) = %+v, %v", widget, exists)
+	}
+	if name := registry.LookupName("0a1b2c3d-4e5f-6071-8293-a4b5c6d7e8fa"); name != "PROPERTY_SET_CONTOSO_WIDGET_SETTINGS" {
+		t.Errorf("LookupName(property set) = %q", name)
+	}
+	schemaAttributes := registry.GetPropertySetAttributes("0a1b2c3d-4e5f-6071-8293-a4b5c6d7e8fa")
+	if len(schemaAttributes) != 1 || schemaAttributes[0].LDAPDisplayName != "contosoWidgetColor" || !schemaAttributes[0].IsSingleValued {
+		t.Errorf("GetPropertySetAttributes() = %+v", schemaAttributes)
+	}
+}
+
+func TestRegistry_LoadInvalid(t *testing.T) {
+	registry := NewRegistry()
+	invalidLDIF := "dn: CN=Broken,CN=Schema\nobjectClass: classSchema\nlDAPDisplayName: broken\nschemaIDGUID: not-a-guid\n"
+	if err := registry.LoadLDIF(strings.NewReader(invalidLDIF)); err == nil {
+		t.Errorf("LoadLDIF() with an invalid schemaIDGUID should fail")
+	}
+	if err := registry.LoadJSON(strings.NewReader(`"not an export"`)); err == nil {
+		t.Errorf("LoadJSON() with an invalid document should fail")
+	}
+	if _, exists := registry.LookupClass("broken"); exists {
+		t.Errorf("a failed load should not add objects to the registry")
+	}
+}
+
+func TestRegistry_Isolation(t *testing.T) {
+	forestA := NewRegistry()
+	forestB := NewRegistry()
+	if err := forestA.LoadLDIF(strings.NewReader(testSchemaLDIF)); err != nil {
+		t.Fatalf("LoadLDIF() error = %v", err)
+	}
+	if _, exists := forestB.LookupAttribute("ms-Mcs-AdmPwd"); exists {
+		t.Errorf("objects loaded in one registry should not be visible in another")
+	}
+	if _, exists := GetDefaultRegistry().LookupAttribute("ms-Mcs-AdmPwd"); exists {
+		t.Errorf("objects loaded in a registry should not be visible in the default registry")
+	}
+}
+
+func TestRegistry_ConcurrentUse(t *testing.T) {
+	registry := NewRegistry()
+	var wg sync.WaitGroup
+	for worker := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if worker%2 == 0 {
+				_ = registry.LoadLDIF(strings.NewReader(testSchemaLDIF))
+			} else {
+				_ = registry.LoadJSON(strings.NewReader(testSchemaJSON))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				registry.LookupName("12345678-1234-5678-9abc-def012345678")
+				registry.LookupClass("user")
+				registry.GetPropertySetAttributes(PROPERTY_SET_ACCOUNT_RESTRICTIONS)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, exists := registry.LookupClass("contosoWidget"); !exists {
+		t.Errorf("contosoWidget not found after concurrent loads")
+	}
+}
+
+func TestRegistry_IsExtendedRightApplicable(t *testing.T) {
+	registry := NewRegistry()
+
+	testCases := []struct {
+		rightsGUID string
+		accessMask uint32
+		class      string
+		expected   bool
+	}{
+		{rights.EXTENDED_RIGHT_USER_FORCE_CHANGE_PASSWORD, rights.RIGHT_DS_CONTROL_ACCESS, "user", true},
+		// computer and inetOrgPerson derive from user
+		{rights.EXTENDED_RIGHT_USER_FORCE_CHANGE_PASSWORD, rights.RIGHT_DS_CONTROL_ACCESS, SCHEMA_CLASS_COMPUTER, true},
+		{rights.EXTENDED_RIGHT_USER_FORCE_CHANGE_PASSWORD, rights.RIGHT_DS_CONTROL_ACCESS, "inetOrgPerson", true},
+		{rights.EXTENDED_RIGHT_USER_FORCE_CHANGE_PASSWORD, rights.RIGHT_DS_CONTROL_ACCESS, "group", false},
+		{rights.EXTENDED_RIGHT_DS_REPLICATION_GET_CHANGES_ALL, rights.RIGHT_DS_CONTROL_ACCESS, "domainDNS", true},
+		{rights.EXTENDED_RIGHT_DS_REPLICATION_GET_CHANGES_ALL, rights.RIGHT_DS_CONTROL_ACCESS, "user", false},
+		{rights.VALIDATED_WRITE_DNS_HOST_NAME, rights.RIGHT_DS_WRITE_PROPERTY_EXTENDED, "computer", true},
+		{rights.VALIDATED_WRITE_DNS_HOST_NAME, rights.RIGHT_DS_WRITE_PROPERTY_EXTENDED, "user", false},
+		{rights.EXTENDED_RIGHT_DS_SET_OWNER, rights.RIGHT_DS_CONTROL_ACCESS, "user", true},
+		{rights.EXTENDED_RIGHT_SEND_AS, rights.RIGHT_DS_CONTROL_ACCESS, "unknownClass", true},
+	}
+
+	for _, testCase := range testCases {
+		if applicable := registry.IsExtendedRightApplicable(testCase.rightsGUID, testCase.accessMask, testCase.class); applicable != testCase.expected {
+			t.Errorf("IsExtendedRightApplicable(%s, 0x%x, %s) = %v, want %v", testCase.rightsGUID, testCase.accessMask, testCase.class, applicable, testCase.expected)
+		}
+	}
+}
+
+func TestRegistry_LookupExtendedRightForAccess(t *testing.T) {
+	registry := NewRegistry()
+	sharedGUID := "0a1b2c3d-4e5f-6071-8293-a4b5c6d7e8f0"
+	if err := registry.AddExtendedRight(rights.ExtendedRight{Name: "Validated-Widget", RightsGUID: sharedGUID, ValidAccesses: rights.RIGHT_DS_WRITE_PROPERTY_EXTENDED}); err != nil {
+		t.Fatalf("AddExtendedRight() error = %v", err)
+	}
+	if err := registry.AddExtendedRight(rights.ExtendedRight{Name: "Widget-Attributes", RightsGUID: sharedGUID, ValidAccesses: rights.RIGHT_DS_READ_PROPERTY | rights.RIGHT_DS_WRITE_PROPERTY}); err != nil {
+		t.Fatalf("AddExtendedRight() error = %v", err)
+	}
+
+	if extendedRight, _ := registry.LookupExtendedRightForAccess(sharedGUID, rights.RIGHT_DS_WRITE_PROPERTY_EXTENDED); extendedRight.Name != "Validated-Widget" {
+		t.Errorf("LookupExtendedRightForAccess(SW) = %q", extendedRight.Name)
+	}
+	if extendedRight, _ := registry.LookupExtendedRightForAccess(sharedGUID, rights.RIGHT_DS_WRITE_PROPERTY); extendedRight.Name != "Widget-Attributes" {
+		t.Errorf("LookupExtendedRightForAccess(WP) = %q", extendedRight.Name)
+	}
+	if extendedRight, _ := registry.LookupExtendedRight("widget-attributes"); extendedRight.Kind() != rights.EXTENDED_RIGHT_KIND_PROPERTY_SET {
+		t.Errorf("LookupExtendedRight(widget-attributes).Kind() = %s", extendedRight.Kind())
+	}
+	if name := registry.LookupName(sharedGUID); name != "VALIDATED_WRITE_WIDGET" {
+		t.Errorf("LookupName() = %q", name)
+	}
+
+	// Built-in rights are used when nothing is loaded for the rightsGuid
+	if extendedRight, exists := registry.LookupExtendedRight("Send-As"); !exists || extendedRight.RightsGUID != rights.EXTENDED_RIGHT_SEND_AS {
+		t.Errorf("LookupExtendedRight(Send-As) = %+v, %v", extendedRight, exists)
+	}
+}
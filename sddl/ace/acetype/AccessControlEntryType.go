@@ -40,6 +40,6 @@ var SDDLToACETypeMap = map[string]uint8{
 	SDDL_SCOPED_POLICY_ID:               ntsd_acetype.ACE_TYPE_SYSTEM_SCOPED_POLICY_ID,
 	SDDL_CALLBACK_AUDIT:                 ntsd_acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK,
 	SDDL_CALLBACK_OBJECT_ACCESS_ALLOWED: ntsd_acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK_OBJECT,
-	// SDDL_PROCESS_TRUST_LABEL:            ntsd_ace.ACE_TYPE_SYSTEM_PROCESS_TRUST_LABEL,
-	// SDDL_ACCESS_FILTER:                  ntsd_ace.ACE_TYPE_SYSTEM_ACCESS_FILTER,
+	SDDL_PROCESS_TRUST_LABEL:            ntsd_acetype.ACE_TYPE_SYSTEM_PROCESS_TRUST_LABEL,
+	SDDL_ACCESS_FILTER:                  ntsd_acetype.ACE_TYPE_SYSTEM_ACCESS_FILTER,
 }
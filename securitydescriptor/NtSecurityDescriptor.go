@@ -2,10 +2,13 @@ package securitydescriptor
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/TheManticoreProject/winacl/acl"
 	"github.com/TheManticoreProject/winacl/identity"
+	"github.com/TheManticoreProject/winacl/parsing"
+	"github.com/TheManticoreProject/winacl/securitydescriptor/control"
 	"github.com/TheManticoreProject/winacl/securitydescriptor/header"
 )
 
@@ -14,6 +17,10 @@ import (
 // region; an offset inside the header overlaps it and is structurally invalid.
 const ntSecurityDescriptorHeaderSize uint32 = 20
 
+// ntSecurityDescriptorRevision is the only revision of the security descriptor
+// format, SECURITY_DESCRIPTOR_REVISION.
+const ntSecurityDescriptorRevision uint8 = 1
+
 // NtSecurityDescriptor represents a Windows security descriptor.
 type NtSecurityDescriptor struct {
 	Header header.NtSecurityDescriptorHeader
@@ -37,13 +44,53 @@ type NtSecurityDescriptor struct {
 // Returns:
 //   - error: An error if parsing fails, otherwise nil.
 func (ntsd *NtSecurityDescriptor) Unmarshal(marshalledData []byte) (int, error) {
+	return ntsd.UnmarshalWithContext(marshalledData, parsing.NewContext(parsing.Options{}))
+}
+
+// UnmarshalWithOptions parses the security descriptor like Unmarshal, following the parse
+// options. In lenient mode, the components that cannot be parsed are dropped instead of
+// failing, and the descriptor is recovered as much as possible, see parsing.Options.
+//
+// Parameters:
+//   - marshalledData ([]byte): The raw byte array to be parsed.
+//   - options (parsing.Options): The parse options.
+//
+// Returns:
+//   - int: The number of bytes consumed by the security descriptor.
+//   - parsing.Anomalies: The anomalies recovered from, with their offset and component path.
+//   - error: An error if parsing fails.
+func (ntsd *NtSecurityDescriptor) UnmarshalWithOptions(marshalledData []byte, options parsing.Options) (int, parsing.Anomalies, error) {
+	ctx := parsing.NewContext(options)
+	rawBytesSize, err := ntsd.UnmarshalWithContext(marshalledData, ctx)
+	return rawBytesSize, ctx.Anomalies(), err
+}
+
+// UnmarshalWithContext parses the security descriptor like Unmarshal, following the options
+// of the parse context. The paths of the anomalies are relative to the path of the context,
+// so that a security descriptor embedded in a larger structure can be located.
+//
+// Parameters:
+//   - marshalledData ([]byte): The raw byte array to be parsed.
+//   - ctx (*parsing.Context): The parse context, located at the start of the security descriptor.
+//
+// Returns:
+//   - int: The number of bytes consumed by the security descriptor.
+//   - error: An error if parsing fails.
+func (ntsd *NtSecurityDescriptor) UnmarshalWithContext(marshalledData []byte, ctx *parsing.Context) (int, error) {
+	marshalledData = ctx.Detach(marshalledData)
 	ntsd.RawBytes = marshalledData
 	ntsd.RawBytesSize = 0
 
 	// Unmarshal the header
 	_, err := ntsd.Header.Unmarshal(marshalledData)
 	if err != nil {
-		return 0, err
+		return 0, parsing.WrapParseError(err, 0, "Header", "")
+	}
+	if ntsd.Header.Revision != ntSecurityDescriptorRevision {
+		if !ctx.Lenient() {
+			return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_INVALID_REVISION, 0, "Header.Revision", "invalid security descriptor: unsupported Revision (%d)", ntsd.Header.Revision)
+		}
+		ctx.Record(0, parsing.ANOMALY_CODE_REVISION_INVALID, "unsupported Revision (%d)", ntsd.Header.Revision)
 	}
 	// Track the maximum extent (offset + component size) across all components,
 	// since components are placed at specific offsets within the buffer rather
@@ -58,13 +105,18 @@ func (ntsd *NtSecurityDescriptor) Unmarshal(marshalledData []byte) (int, error)
 			}
 			rawBytesSize, err := ntsd.Owner.Unmarshal(ntsd.RawBytes[ntsd.Header.OffsetOwner:])
 			if err != nil {
-				return 0, fmt.Errorf("failed to unmarshal Owner: %w", err)
-			}
-			if end := ntsd.Header.OffsetOwner + uint32(rawBytesSize); end > ntsd.RawBytesSize {
+				if err := recoverComponent(ctx, int(ntsd.Header.OffsetOwner), "Owner", parsing.ANOMALY_CODE_COMPONENT_INVALID, parsing.WrapParseError(err, int(ntsd.Header.OffsetOwner), "Owner", "failed to unmarshal Owner")); err != nil {
+					return 0, err
+				}
+				ntsd.Owner = nil
+			} else if end := ntsd.Header.OffsetOwner + uint32(rawBytesSize); end > ntsd.RawBytesSize {
 				ntsd.RawBytesSize = end
 			}
 		} else {
-			return 0, fmt.Errorf("failed to unmarshal Owner: offset is invalid OffsetOwner=%d (must be >= %d and < %d)", ntsd.Header.OffsetOwner, ntSecurityDescriptorHeaderSize, len(ntsd.RawBytes))
+			if err := recoverComponent(ctx, 4, "Owner", parsing.ANOMALY_CODE_OFFSET_OUT_OF_RANGE, parsing.NewParseError(parsing.ERROR_CATEGORY_OUT_OF_RANGE, 4, "Header.OffsetOwner", "failed to unmarshal Owner: offset is invalid OffsetOwner=%d (must be >= %d and < %d)", ntsd.Header.OffsetOwner, ntSecurityDescriptorHeaderSize, len(ntsd.RawBytes))); err != nil {
+				return 0, err
+			}
+			ntsd.Owner = nil
 		}
 	}
 
@@ -76,13 +128,18 @@ func (ntsd *NtSecurityDescriptor) Unmarshal(marshalledData []byte) (int, error)
 			}
 			rawBytesSize, err := ntsd.Group.Unmarshal(ntsd.RawBytes[ntsd.Header.OffsetGroup:])
 			if err != nil {
-				return 0, fmt.Errorf("failed to unmarshal Group: %w", err)
-			}
-			if end := ntsd.Header.OffsetGroup + uint32(rawBytesSize); end > ntsd.RawBytesSize {
+				if err := recoverComponent(ctx, int(ntsd.Header.OffsetGroup), "Group", parsing.ANOMALY_CODE_COMPONENT_INVALID, parsing.WrapParseError(err, int(ntsd.Header.OffsetGroup), "Group", "failed to unmarshal Group")); err != nil {
+					return 0, err
+				}
+				ntsd.Group = nil
+			} else if end := ntsd.Header.OffsetGroup + uint32(rawBytesSize); end > ntsd.RawBytesSize {
 				ntsd.RawBytesSize = end
 			}
 		} else {
-			return 0, fmt.Errorf("failed to unmarshal Group: offset is invalid OffsetGroup=%d (must be >= %d and < %d)", ntsd.Header.OffsetGroup, ntSecurityDescriptorHeaderSize, len(ntsd.RawBytes))
+			if err := recoverComponent(ctx, 8, "Group", parsing.ANOMALY_CODE_OFFSET_OUT_OF_RANGE, parsing.NewParseError(parsing.ERROR_CATEGORY_OUT_OF_RANGE, 8, "Header.OffsetGroup", "failed to unmarshal Group: offset is invalid OffsetGroup=%d (must be >= %d and < %d)", ntsd.Header.OffsetGroup, ntSecurityDescriptorHeaderSize, len(ntsd.RawBytes))); err != nil {
+				return 0, err
+			}
+			ntsd.Group = nil
 		}
 	}
 
@@ -92,15 +149,20 @@ func (ntsd *NtSecurityDescriptor) Unmarshal(marshalledData []byte) (int, error)
 			if ntsd.DACL == nil {
 				ntsd.DACL = &acl.DiscretionaryAccessControlList{}
 			}
-			rawBytesSize, err := ntsd.DACL.Unmarshal(ntsd.RawBytes[ntsd.Header.OffsetDacl:])
+			rawBytesSize, err := ntsd.DACL.UnmarshalWithContext(ntsd.RawBytes[ntsd.Header.OffsetDacl:], ctx.Child(int(ntsd.Header.OffsetDacl), "DACL"))
 			if err != nil {
-				return 0, fmt.Errorf("failed to unmarshal DACL: %w", err)
-			}
-			if end := ntsd.Header.OffsetDacl + uint32(rawBytesSize); end > ntsd.RawBytesSize {
+				if err := recoverComponent(ctx, int(ntsd.Header.OffsetDacl), "DACL", parsing.ANOMALY_CODE_COMPONENT_INVALID, parsing.WrapParseError(err, int(ntsd.Header.OffsetDacl), "DACL", "failed to unmarshal DACL")); err != nil {
+					return 0, err
+				}
+				ntsd.DACL = nil
+			} else if end := ntsd.Header.OffsetDacl + uint32(rawBytesSize); end > ntsd.RawBytesSize {
 				ntsd.RawBytesSize = end
 			}
 		} else {
-			return 0, fmt.Errorf("failed to unmarshal DACL: offset is invalid OffsetDacl=%d (must be >= %d and < %d)", ntsd.Header.OffsetDacl, ntSecurityDescriptorHeaderSize, len(ntsd.RawBytes))
+			if err := recoverComponent(ctx, 16, "DACL", parsing.ANOMALY_CODE_OFFSET_OUT_OF_RANGE, parsing.NewParseError(parsing.ERROR_CATEGORY_OUT_OF_RANGE, 16, "Header.OffsetDacl", "failed to unmarshal DACL: offset is invalid OffsetDacl=%d (must be >= %d and < %d)", ntsd.Header.OffsetDacl, ntSecurityDescriptorHeaderSize, len(ntsd.RawBytes))); err != nil {
+				return 0, err
+			}
+			ntsd.DACL = nil
 		}
 	}
 
@@ -110,111 +172,237 @@ func (ntsd *NtSecurityDescriptor) Unmarshal(marshalledData []byte) (int, error)
 			if ntsd.SACL == nil {
 				ntsd.SACL = &acl.SystemAccessControlList{}
 			}
-			rawBytesSize, err := ntsd.SACL.Unmarshal(ntsd.RawBytes[ntsd.Header.OffsetSacl:])
+			rawBytesSize, err := ntsd.SACL.UnmarshalWithContext(ntsd.RawBytes[ntsd.Header.OffsetSacl:], ctx.Child(int(ntsd.Header.OffsetSacl), "SACL"))
 			if err != nil {
-				return 0, fmt.Errorf("failed to unmarshal SACL: %w", err)
-			}
-			if end := ntsd.Header.OffsetSacl + uint32(rawBytesSize); end > ntsd.RawBytesSize {
+				if err := recoverComponent(ctx, int(ntsd.Header.OffsetSacl), "SACL", parsing.ANOMALY_CODE_COMPONENT_INVALID, parsing.WrapParseError(err, int(ntsd.Header.OffsetSacl), "SACL", "failed to unmarshal SACL")); err != nil {
+					return 0, err
+				}
+				ntsd.SACL = nil
+			} else if end := ntsd.Header.OffsetSacl + uint32(rawBytesSize); end > ntsd.RawBytesSize {
 				ntsd.RawBytesSize = end
 			}
 		} else {
-			return 0, fmt.Errorf("failed to unmarshal SACL: offset is invalid OffsetSacl=%d (must be >= %d and < %d)", ntsd.Header.OffsetSacl, ntSecurityDescriptorHeaderSize, len(ntsd.RawBytes))
+			if err := recoverComponent(ctx, 12, "SACL", parsing.ANOMALY_CODE_OFFSET_OUT_OF_RANGE, parsing.NewParseError(parsing.ERROR_CATEGORY_OUT_OF_RANGE, 12, "Header.OffsetSacl", "failed to unmarshal SACL: offset is invalid OffsetSacl=%d (must be >= %d and < %d)", ntsd.Header.OffsetSacl, ntSecurityDescriptorHeaderSize, len(ntsd.RawBytes))); err != nil {
+				return 0, err
+			}
+			ntsd.SACL = nil
 		}
 	}
 
+	ntsd.RawBytes = ntsd.RawBytes[:ntsd.RawBytesSize]
+
 	return int(ntsd.RawBytesSize), nil
 }
 
+// recoverComponent handles a component of the security descriptor that cannot be parsed. In
+// strict mode the error is returned. In lenient mode the error is recorded as an anomaly of
+// the context and nil is returned, so that the caller drops the component.
+//
+// Parameters:
+//   - ctx (*parsing.Context): The parse context of the security descriptor.
+//   - offset (int): The offset of the anomaly, relative to the security descriptor.
+//   - path (string): The path of the component.
+//   - code (parsing.AnomalyCode): The kind of the anomaly.
+//   - err (error): The parsing error.
+//
+// Returns:
+//   - error: The parsing error in strict mode, nil in lenient mode.
+func recoverComponent(ctx *parsing.Context, offset int, path string, code parsing.AnomalyCode, err error) error {
+	if !ctx.Lenient() {
+		return err
+	}
+	ctx.Child(0, path).Record(offset, code, "%s, component dropped", err.Error())
+	return nil
+}
+
 // Marshal serializes the NtSecurityDescriptor struct into a byte slice.
 //
 // Returns:
 //   - ([]byte, error): A byte slice containing the serialized data and an error if serialization fails, otherwise nil.
 func (ntsd *NtSecurityDescriptor) Marshal() ([]byte, error) {
-	// Initialize a byte slice to hold the serialized data
-	var err error
-	var marshalledData []byte
+	marshalledData, err := ntsd.AppendBinary(make([]byte, 0, ntsd.Size()))
+	if err != nil {
+		return nil, err
+	}
+	ntsd.RefreshRawBytes(marshalledData)
+	return marshalledData, nil
+}
 
-	offset := 20
+// RefreshRawBytes points the RawBytes and RawBytesSize fields of the security descriptor and
+// of all its components at their bytes in a marshalled security descriptor, so that they
+// reflect the fields after a modification. Marshal calls it on the bytes it returns;
+// AppendBinary and MarshalTo do not, since the caller may reuse their buffer.
+//
+// Parameters:
+//   - marshalledData ([]byte): The bytes produced by AppendBinary for the security
+//     descriptor, whose header offsets locate the components.
+func (ntsd *NtSecurityDescriptor) RefreshRawBytes(marshalledData []byte) {
+	ntsd.RawBytesSize = uint32(ntsd.Size())
+	ntsd.RawBytes = marshalledData[:ntsd.RawBytesSize]
+	ntsd.Header.RefreshRawBytes(marshalledData)
 
-	// Marshal SACL
-	dataSacl := []byte{}
-	if ntsd.SACL != nil && len(ntsd.SACL.Entries) > 0 {
-		dataSacl, err = ntsd.SACL.Marshal()
+	if ntsd.hasSACL() {
+		ntsd.SACL.RefreshRawBytes(marshalledData[ntsd.Header.OffsetSacl:])
+	}
+	if ntsd.hasDACL() {
+		ntsd.DACL.RefreshRawBytes(marshalledData[ntsd.Header.OffsetDacl:])
+	}
+	if ntsd.hasOwner() {
+		ntsd.Owner.RefreshRawBytes(marshalledData[ntsd.Header.OffsetOwner:])
+	}
+	if ntsd.hasGroup() {
+		ntsd.Group.RefreshRawBytes(marshalledData[ntsd.Header.OffsetGroup:])
+	}
+}
+
+// hasSACL checks if the SACL is serialized. An empty SACL is only serialized when
+// SACL_PRESENT is set, see hasDACL.
+func (ntsd *NtSecurityDescriptor) hasSACL() bool {
+	if ntsd.SACL == nil {
+		return false
+	}
+	return len(ntsd.SACL.Entries) > 0 || ntsd.Header.Control.HasControl(control.NT_SECURITY_DESCRIPTOR_CONTROL_SP)
+}
+
+// hasDACL checks if the DACL is serialized. An empty DACL is only serialized when
+// DACL_PRESENT is set: it denies all access, and writing it as a NULL DACL (a zero offset)
+// would grant full access to everyone instead. Without DACL_PRESENT, the empty DACL of
+// NewSecurityDescriptor is not part of the descriptor.
+func (ntsd *NtSecurityDescriptor) hasDACL() bool {
+	if ntsd.DACL == nil {
+		return false
+	}
+	return len(ntsd.DACL.Entries) > 0 || ntsd.Header.Control.HasControl(control.NT_SECURITY_DESCRIPTOR_CONTROL_DP)
+}
+
+// hasOwner checks if the owner is serialized. A zero-value Identity (as produced by
+// NewSecurityDescriptor) has an unset SID whose RevisionLevel is 0, which is not a valid
+// SID. It is treated as "no owner", mirroring the presence checks used for the SACL and
+// DACL, instead of emitting an invalid S-0-0-0 SID.
+func (ntsd *NtSecurityDescriptor) hasOwner() bool {
+	return ntsd.Owner != nil && ntsd.Owner.SID.RevisionLevel != 0
+}
+
+// hasGroup checks if the group is serialized, see hasOwner.
+func (ntsd *NtSecurityDescriptor) hasGroup() bool {
+	return ntsd.Group != nil && ntsd.Group.SID.RevisionLevel != 0
+}
+
+// Size returns the size of the binary representation of the security descriptor, as
+// produced by Marshal.
+//
+// Returns:
+//   - int: The size of the header and of the serialized components, in bytes.
+func (ntsd *NtSecurityDescriptor) Size() int {
+	size := ntsd.Header.Size()
+	if ntsd.hasSACL() {
+		size += ntsd.SACL.Size()
+	}
+	if ntsd.hasDACL() {
+		size += ntsd.DACL.Size()
+	}
+	if ntsd.hasOwner() {
+		size += ntsd.Owner.SID.Size()
+	}
+	if ntsd.hasGroup() {
+		size += ntsd.Group.SID.Size()
+	}
+	return size
+}
+
+// AppendBinary appends the binary representation of the security descriptor to a buffer,
+// laid out as header, SACL, DACL, Owner and Group. When the buffer has Size() bytes of
+// spare capacity, nothing is allocated, which makes it possible to marshal many descriptors
+// into a pooled buffer. Like Marshal, it updates the offsets of the header.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: An error if one of the components cannot be serialized.
+func (ntsd *NtSecurityDescriptor) AppendBinary(buffer []byte) ([]byte, error) {
+	// Compute the offsets of the components from their sizes
+	offset := ntsd.Header.Size()
+
+	ntsd.Header.OffsetSacl = 0
+	if ntsd.hasSACL() {
+		ntsd.Header.OffsetSacl = uint32(offset)
+		offset += ntsd.SACL.Size()
+	}
+
+	ntsd.Header.OffsetDacl = 0
+	if ntsd.hasDACL() {
+		ntsd.Header.OffsetDacl = uint32(offset)
+		offset += ntsd.DACL.Size()
+	}
+
+	ntsd.Header.OffsetOwner = 0
+	if ntsd.hasOwner() {
+		ntsd.Header.OffsetOwner = uint32(offset)
+		offset += ntsd.Owner.SID.Size()
+	}
+
+	ntsd.Header.OffsetGroup = 0
+	if ntsd.hasGroup() {
+		ntsd.Header.OffsetGroup = uint32(offset)
+	}
+
+	buffer, err := ntsd.Header.AppendBinary(buffer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Header: %w", err)
+	}
+
+	if ntsd.hasSACL() {
+		buffer, err = ntsd.SACL.AppendBinary(buffer)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal SACL: %w", err)
 		}
-		ntsd.Header.OffsetSacl = uint32(offset)
-		offset += len(dataSacl)
-	} else {
-		ntsd.Header.OffsetSacl = 0
 	}
 
-	// Marshal DACL
-	dataDacl := []byte{}
-	if ntsd.DACL != nil && len(ntsd.DACL.Entries) > 0 {
-		dataDacl, err = ntsd.DACL.Marshal()
+	if ntsd.hasDACL() {
+		buffer, err = ntsd.DACL.AppendBinary(buffer)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal DACL: %w", err)
 		}
-		ntsd.Header.OffsetDacl = uint32(offset)
-		offset += len(dataDacl)
-	} else {
-		ntsd.Header.OffsetDacl = 0
 	}
 
-	// Marshal Owner. A zero-value Identity (as produced by NewSecurityDescriptor)
-	// has an unset SID whose RevisionLevel is 0, which is not a valid SID. Treat
-	// it as "no owner" and write OffsetOwner = 0, mirroring the presence checks
-	// used for the SACL and DACL, instead of emitting an invalid S-0-0-0 SID.
-	dataOwner := []byte{}
-	if ntsd.Owner != nil && ntsd.Owner.SID.RevisionLevel != 0 {
-		dataOwner, err = ntsd.Owner.SID.Marshal()
+	if ntsd.hasOwner() {
+		buffer, err = ntsd.Owner.SID.AppendBinary(buffer)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal Owner: %w", err)
 		}
-		ntsd.Header.OffsetOwner = uint32(offset)
-		offset += len(dataOwner)
-	} else {
-		ntsd.Header.OffsetOwner = 0
 	}
 
-	// Marshal Group (see the Owner note above).
-	dataGroup := []byte{}
-	if ntsd.Group != nil && ntsd.Group.SID.RevisionLevel != 0 {
-		dataGroup, err = ntsd.Group.SID.Marshal()
+	if ntsd.hasGroup() {
+		buffer, err = ntsd.Group.SID.AppendBinary(buffer)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal Group: %w", err)
 		}
-		ntsd.Header.OffsetGroup = uint32(offset)
-		offset += len(dataGroup)
-	} else {
-		ntsd.Header.OffsetGroup = 0
 	}
 
-	// Update the header and append the header bytes
-	marshalledData, err = ntsd.Header.Marshal()
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal Header: %w", err)
-	}
+	return buffer, nil
+}
 
-	// Append the SACL bytes if present
-	if ntsd.Header.OffsetSacl != 0 {
-		marshalledData = append(marshalledData, dataSacl...)
-	}
-	// Append the DACL bytes if present
-	if ntsd.Header.OffsetDacl != 0 {
-		marshalledData = append(marshalledData, dataDacl...)
-	}
-	// Append the Owner SID bytes if present
-	if ntsd.Header.OffsetOwner != 0 {
-		marshalledData = append(marshalledData, dataOwner...)
+// MarshalTo writes the binary representation of the security descriptor at the start of a
+// buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to write to, at least Size() bytes long.
+//
+// Returns:
+//   - int: The number of bytes written.
+//   - error: An error if the buffer is too short or the security descriptor cannot be serialized.
+func (ntsd *NtSecurityDescriptor) MarshalTo(buffer []byte) (int, error) {
+	size := ntsd.Size()
+	if len(buffer) < size {
+		return 0, fmt.Errorf("security descriptor requires a buffer of %d bytes, got %d: %w", size, len(buffer), io.ErrShortBuffer)
 	}
-	// Append the Group SID bytes if present
-	if ntsd.Header.OffsetGroup != 0 {
-		marshalledData = append(marshalledData, dataGroup...)
+	marshalledData, err := ntsd.AppendBinary(buffer[:0:size])
+	if err != nil {
+		return 0, err
 	}
-
-	return marshalledData, nil
+	return len(marshalledData), nil
 }
 
 // Describe prints the NtSecurityDescriptor in a human-readable format.
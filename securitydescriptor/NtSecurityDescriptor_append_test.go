@@ -0,0 +1,139 @@
+package securitydescriptor_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+// appendTestSDDL describes a descriptor with object and callback entries.
+const appendTestSDDL = "O:BAG:S-1-5-21-1-2-3-513" +
+	"D:(A;;FA;;;WD)(D;CIIO;FR;;;BU)" +
+	"(OA;CI;WP;bf967a86-0de6-11d0-a285-00aa003049e2;bf967aba-0de6-11d0-a285-00aa003049e2;AU)" +
+	"(XA;;FX;;;WD;(@User.Title == \"PM\"))" +
+	"S:(AU;SA;FA;;;WD)"
+
+func TestNtSecurityDescriptor_AppendBinary(t *testing.T) {
+	ntsd := sddlTestDescriptor(t, appendTestSDDL)
+	marshalledData, err := ntsd.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if ntsd.Size() != len(marshalledData) {
+		t.Errorf("Size() = %d, want %d", ntsd.Size(), len(marshalledData))
+	}
+
+	prefix := []byte{0xde, 0xad}
+	appended, err := ntsd.AppendBinary(prefix)
+	if err != nil {
+		t.Fatalf("AppendBinary() error = %v", err)
+	}
+	if !bytes.Equal(appended[:2], prefix) || !bytes.Equal(appended[2:], marshalledData) {
+		t.Errorf("AppendBinary() = %x, want %x after the prefix", appended, marshalledData)
+	}
+}
+
+func TestNtSecurityDescriptor_AppendBinary_NoAllocation(t *testing.T) {
+	ntsd := sddlTestDescriptor(t, appendTestSDDL)
+	buffer := make([]byte, 0, ntsd.Size())
+
+	allocations := testing.AllocsPerRun(100, func() {
+		var err error
+		buffer, err = ntsd.AppendBinary(buffer[:0])
+		if err != nil {
+			t.Fatalf("AppendBinary() error = %v", err)
+		}
+	})
+	if allocations != 0 {
+		t.Errorf("AppendBinary() into a sized buffer did %v allocations, want 0", allocations)
+	}
+}
+
+func TestNtSecurityDescriptor_MarshalTo(t *testing.T) {
+	ntsd := sddlTestDescriptor(t, appendTestSDDL)
+	marshalledData, err := ntsd.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	buffer := bytes.Repeat([]byte{0xff}, len(marshalledData)+4)
+	written, err := ntsd.MarshalTo(buffer)
+	if err != nil {
+		t.Fatalf("MarshalTo() error = %v", err)
+	}
+	if written != len(marshalledData) || !bytes.Equal(buffer[:written], marshalledData) {
+		t.Errorf("MarshalTo() wrote %d bytes %x, want %x", written, buffer[:written], marshalledData)
+	}
+	if !bytes.Equal(buffer[written:], []byte{0xff, 0xff, 0xff, 0xff}) {
+		t.Errorf("MarshalTo() wrote past the descriptor")
+	}
+
+	if _, err := ntsd.MarshalTo(buffer[:len(marshalledData)-1]); !errors.Is(err, io.ErrShortBuffer) {
+		t.Errorf("MarshalTo() error = %v, want io.ErrShortBuffer", err)
+	}
+}
+
+func TestNtSecurityDescriptor_Size_Components(t *testing.T) {
+	ntsd := sddlTestDescriptor(t, appendTestSDDL)
+	for name, component := range map[string]interface {
+		Size() int
+		Marshal() ([]byte, error)
+	}{
+		"SACL":  ntsd.SACL,
+		"DACL":  ntsd.DACL,
+		"Owner": &ntsd.Owner.SID,
+		"Group": &ntsd.Group.SID,
+	} {
+		marshalledData, err := component.Marshal()
+		if err != nil {
+			t.Fatalf("%s.Marshal() error = %v", name, err)
+		}
+		if component.Size() != len(marshalledData) {
+			t.Errorf("%s.Size() = %d, want %d", name, component.Size(), len(marshalledData))
+		}
+	}
+	for index := range ntsd.DACL.Entries {
+		entry := &ntsd.DACL.Entries[index]
+		marshalledData, err := entry.Marshal()
+		if err != nil {
+			t.Fatalf("DACL.Entries[%d].Marshal() error = %v", index, err)
+		}
+		if entry.Size() != len(marshalledData) {
+			t.Errorf("DACL.Entries[%d].Size() = %d, want %d", index, entry.Size(), len(marshalledData))
+		}
+	}
+}
+
+func BenchmarkNtSecurityDescriptor_Marshal(b *testing.B) {
+	ntsd := sddlTestDescriptor(b, appendTestSDDL)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ntsd.Marshal(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNtSecurityDescriptor_AppendBinary(b *testing.B) {
+	ntsd := sddlTestDescriptor(b, appendTestSDDL)
+	buffer := make([]byte, 0, ntsd.Size())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var err error
+		buffer, err = ntsd.AppendBinary(buffer[:0])
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNtSecurityDescriptor_MarshalBinary(b *testing.B) {
+	ntsd := sddlTestDescriptor(b, appendTestSDDL)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ntsd.MarshalBinary(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
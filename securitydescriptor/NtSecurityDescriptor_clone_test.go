@@ -0,0 +1,161 @@
+package securitydescriptor_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/parsing"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+)
+
+func TestNtSecurityDescriptor_Clone(t *testing.T) {
+	marshalledData := sddlTestDescriptor(t, appendTestSDDL).RawBytes
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	if _, err := ntsd.Unmarshal(marshalledData); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	clone := ntsd.Clone()
+	if !clone.Equal(ntsd) {
+		t.Fatalf("Clone() is not equal to the original")
+	}
+	if clone.Owner == ntsd.Owner || clone.Group == ntsd.Group || clone.DACL == ntsd.DACL || clone.SACL == ntsd.SACL {
+		t.Fatalf("Clone() shares the components of the original")
+	}
+
+	flagValues := slices.Clone(ntsd.DACL.Entries[0].Header.Flags.Values)
+
+	// Modify every level of the copy
+	clone.Owner.SID.SubAuthorities = append(clone.Owner.SID.SubAuthorities[:0], 99)
+	clone.DACL.Entries[0].Mask.RawValue = 0x1
+	clone.DACL.Entries[0].Header.Flags.Values = append(clone.DACL.Entries[0].Header.Flags.Values[:0], 0x10, 0x20)
+	clone.DACL.Entries[3].ApplicationData[4] ^= 0xff
+	clone.SACL.Entries = clone.SACL.Entries[:0]
+	clone.RawBytes[0] = 0xff
+	clone.DACL.RawBytes[0] = 0xff
+
+	remarshalledData, err := ntsd.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !bytes.Equal(remarshalledData, marshalledData) {
+		t.Errorf("modifying the clone changed the original:\n got %x\nwant %x", remarshalledData, marshalledData)
+	}
+	if !bytes.Equal(ntsd.RawBytes, marshalledData) {
+		t.Errorf("modifying the clone changed the RawBytes of the original")
+	}
+	if !slices.Equal(ntsd.DACL.Entries[0].Header.Flags.Values, flagValues) {
+		t.Errorf("modifying the clone changed the flags of the original: %v", ntsd.DACL.Entries[0].Header.Flags.Values)
+	}
+	if (*securitydescriptor.NtSecurityDescriptor)(nil).Clone() != nil {
+		t.Errorf("Clone() of nil is not nil")
+	}
+}
+
+func TestNtSecurityDescriptor_UnmarshalWithOptions_Detach(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  parsing.Options
+		detached bool
+	}{
+		{"Aliased", parsing.Options{}, false},
+		{"Detach", parsing.Options{Detach: true}, true},
+		{"DetachLenient", parsing.Options{Detach: true, Lenient: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			marshalledData := sddlTestDescriptor(t, appendTestSDDL).RawBytes
+			expected := bytes.Clone(marshalledData)
+
+			ntsd := &securitydescriptor.NtSecurityDescriptor{}
+			if _, _, err := ntsd.UnmarshalWithOptions(marshalledData, tt.options); err != nil {
+				t.Fatalf("UnmarshalWithOptions() error = %v", err)
+			}
+
+			// Reuse the input buffer
+			for index := range marshalledData {
+				marshalledData[index] = 0xee
+			}
+
+			entry := &ntsd.DACL.Entries[3]
+			intact := bytes.Equal(ntsd.RawBytes, expected) &&
+				bytes.Equal(ntsd.DACL.RawBytes, expected[ntsd.Header.OffsetDacl:ntsd.Header.OffsetDacl+ntsd.DACL.RawBytesSize]) &&
+				bytes.Equal(entry.RawBytes[entry.RawBytesSize:], entry.ApplicationData) &&
+				entry.ApplicationData[0] != 0xee &&
+				ntsd.Owner.SID.RawBytes[0] != 0xee
+			if intact != tt.detached {
+				t.Errorf("raw bytes intact after reusing the input = %v, want %v", intact, tt.detached)
+			}
+
+			if tt.detached {
+				remarshalledData, err := ntsd.Marshal()
+				if err != nil {
+					t.Fatalf("Marshal() error = %v", err)
+				}
+				if !bytes.Equal(remarshalledData, expected) {
+					t.Errorf("Marshal() = %x, want %x", remarshalledData, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestNtSecurityDescriptor_Marshal_RefreshesRawBytes(t *testing.T) {
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	if _, err := ntsd.Unmarshal(sddlTestDescriptor(t, appendTestSDDL).RawBytes); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	// The components hold their own bytes, not the rest of the input
+	if len(ntsd.Owner.RawBytes) != int(ntsd.Owner.RawBytesSize) || len(ntsd.DACL.Entries[0].Mask.RawBytes) != 4 {
+		t.Fatalf("Unmarshal() RawBytes do not match RawBytesSize")
+	}
+
+	// Grow the owner, which moves it, and change an entry
+	ntsd.Owner.SID.SubAuthorities = append(ntsd.Owner.SID.SubAuthorities, 1234)
+	ntsd.Owner.SID.SubAuthorityCount++
+	ntsd.DACL.Entries[1].Mask.RawValue = 0x00120089
+
+	marshalledData, err := ntsd.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	if !bytes.Equal(ntsd.RawBytes, marshalledData) || int(ntsd.RawBytesSize) != len(marshalledData) {
+		t.Errorf("RawBytes = %x, want %x", ntsd.RawBytes, marshalledData)
+	}
+	if !bytes.Equal(ntsd.Header.RawBytes, marshalledData[:20]) {
+		t.Errorf("Header.RawBytes = %x, want %x", ntsd.Header.RawBytes, marshalledData[:20])
+	}
+	ownerData, _ := ntsd.Owner.SID.Marshal()
+	if !bytes.Equal(ntsd.Owner.RawBytes, ownerData) || !bytes.Equal(marshalledData[ntsd.Header.OffsetOwner:ntsd.Header.OffsetOwner+ntsd.Owner.RawBytesSize], ownerData) {
+		t.Errorf("Owner.RawBytes = %x, want %x", ntsd.Owner.RawBytes, ownerData)
+	}
+	dacl := marshalledData[ntsd.Header.OffsetDacl : int(ntsd.Header.OffsetDacl)+ntsd.DACL.Size()]
+	if !bytes.Equal(ntsd.DACL.RawBytes, dacl) || !bytes.Equal(ntsd.DACL.Header.RawBytes, dacl[:8]) {
+		t.Errorf("DACL.RawBytes = %x, want %x", ntsd.DACL.RawBytes, dacl)
+	}
+
+	offset := 8
+	for index := range ntsd.DACL.Entries {
+		entry := &ntsd.DACL.Entries[index]
+		size := entry.Size()
+		if !bytes.Equal(entry.RawBytes, dacl[offset:offset+size]) {
+			t.Errorf("Entries[%d].RawBytes = %x, want %x", index, entry.RawBytes, dacl[offset:offset+size])
+		}
+		if binary.LittleEndian.Uint32(entry.Mask.RawBytes) != entry.Mask.RawValue {
+			t.Errorf("Entries[%d].Mask.RawBytes = %x, want %08x", index, entry.Mask.RawBytes, entry.Mask.RawValue)
+		}
+		sidData, _ := entry.Identity.SID.Marshal()
+		if !bytes.Equal(entry.Identity.RawBytes, sidData) {
+			t.Errorf("Entries[%d].Identity.RawBytes = %x, want %x", index, entry.Identity.RawBytes, sidData)
+		}
+		if !bytes.Equal(entry.RawBytes[entry.RawBytesSize:], entry.ApplicationData) {
+			t.Errorf("Entries[%d] RawBytesSize = %d does not end at the ApplicationData", index, entry.RawBytesSize)
+		}
+		offset += size
+	}
+}
@@ -0,0 +1,407 @@
+package securitydescriptor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/identity"
+	"github.com/TheManticoreProject/winacl/object/flags"
+	"github.com/TheManticoreProject/winacl/securitydescriptor/control"
+	"github.com/TheManticoreProject/winacl/sid"
+)
+
+// DiffKind is the kind of a change of an ACE between two security descriptors.
+type DiffKind uint8
+
+const (
+	// DIFF_KIND_ADDED is an ACE present only in the new descriptor.
+	DIFF_KIND_ADDED DiffKind = iota
+	// DIFF_KIND_REMOVED is an ACE present only in the old descriptor.
+	DIFF_KIND_REMOVED
+	// DIFF_KIND_MODIFIED is an ACE present in both descriptors whose mask, conditional
+	// expression, attribute data or compound fields changed.
+	DIFF_KIND_MODIFIED
+	// DIFF_KIND_MOVED is an ACE present unchanged in both descriptors, at another position
+	// relative to the other ACEs of the ACL.
+	DIFF_KIND_MOVED
+)
+
+// DiffKindNames maps the diff kinds to their names.
+var DiffKindNames = map[DiffKind]string{
+	DIFF_KIND_ADDED:    "ADDED",
+	DIFF_KIND_REMOVED:  "REMOVED",
+	DIFF_KIND_MODIFIED: "MODIFIED",
+	DIFF_KIND_MOVED:    "MOVED",
+}
+
+// String returns the name of the diff kind.
+//
+// Returns:
+//   - string: The name of the diff kind, or "?" if it is unknown.
+func (kind DiffKind) String() string {
+	if name, exists := DiffKindNames[kind]; exists {
+		return name
+	}
+	return "?"
+}
+
+// SIDChange is a change of the owner or of the group of a security descriptor.
+//
+// Attributes:
+//   - Old (*sid.SID): The SID in the old descriptor, nil if it has none.
+//   - New (*sid.SID): The SID in the new descriptor, nil if it has none.
+type SIDChange struct {
+	Old *sid.SID
+	New *sid.SID
+}
+
+// ControlChange is a change of the control flags of a security descriptor.
+//
+// Attributes:
+//   - Old (uint16): The control flags of the old descriptor.
+//   - New (uint16): The control flags of the new descriptor.
+//   - Added ([]string): The short names of the flags set in the new descriptor only, like "PD".
+//   - Removed ([]string): The short names of the flags set in the old descriptor only.
+type ControlChange struct {
+	Old     uint16
+	New     uint16
+	Added   []string
+	Removed []string
+}
+
+// EntryChange is a change of an ACE between two security descriptors. ACEs are matched by
+// trustee, type, flags and object types, not by index, so that inserting an ACE does not
+// report all the following ones as modified.
+//
+// Attributes:
+//   - Kind (DiffKind): The kind of the change.
+//   - OldIndex (int): The index of the ACE in the old ACL, -1 for an added ACE.
+//   - NewIndex (int): The index of the ACE in the new ACL, -1 for a removed ACE.
+//   - Old (*ace.AccessControlEntry): The ACE in the old ACL, nil for an added ACE.
+//   - New (*ace.AccessControlEntry): The ACE in the new ACL, nil for a removed ACE.
+//   - Fields ([]string): The fields of a modified ACE that changed: "Mask",
+//     "ApplicationData", "Compound", and "Position" when it also moved.
+type EntryChange struct {
+	Kind     DiffKind
+	OldIndex int
+	NewIndex int
+	Old      *ace.AccessControlEntry
+	New      *ace.AccessControlEntry
+	Fields   []string
+}
+
+// ACLDiff is the difference between the DACLs or the SACLs of two security descriptors.
+//
+// Attributes:
+//   - OldPresent (bool): Whether the old descriptor has the ACL.
+//   - NewPresent (bool): Whether the new descriptor has the ACL.
+//   - Entries ([]EntryChange): The changes of the ACEs, in the order of the new ACL, the
+//     removed ACEs being placed where they were in the old ACL.
+type ACLDiff struct {
+	OldPresent bool
+	NewPresent bool
+	Entries    []EntryChange
+}
+
+// IsEmpty checks if the ACLs are the same.
+//
+// Returns:
+//   - bool: true if the presence and the ACEs of the ACL did not change.
+func (aclDiff *ACLDiff) IsEmpty() bool {
+	return aclDiff.OldPresent == aclDiff.NewPresent && len(aclDiff.Entries) == 0
+}
+
+// IsReorderedOnly checks if the only changes of the ACL are ACEs that moved.
+//
+// Returns:
+//   - bool: true if the ACL has changes and all of them are of kind DIFF_KIND_MOVED.
+func (aclDiff *ACLDiff) IsReorderedOnly() bool {
+	if aclDiff.IsEmpty() || aclDiff.OldPresent != aclDiff.NewPresent {
+		return false
+	}
+	for _, change := range aclDiff.Entries {
+		if change.Kind != DIFF_KIND_MOVED {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff is the structured difference between two security descriptors, see
+// NtSecurityDescriptor.Diff. It can be rendered as JSON with MarshalJSON and as text with
+// Unified.
+//
+// Attributes:
+//   - Owner (*SIDChange): The change of the owner, nil if it did not change.
+//   - Group (*SIDChange): The change of the group, nil if it did not change.
+//   - Control (*ControlChange): The change of the control flags, nil if they did not change.
+//   - DACL (ACLDiff): The changes of the DACL.
+//   - SACL (ACLDiff): The changes of the SACL.
+type Diff struct {
+	Owner   *SIDChange
+	Group   *SIDChange
+	Control *ControlChange
+	DACL    ACLDiff
+	SACL    ACLDiff
+}
+
+// IsEmpty checks if the two security descriptors are the same.
+//
+// Returns:
+//   - bool: true if nothing changed.
+func (diff *Diff) IsEmpty() bool {
+	return diff.Owner == nil && diff.Group == nil && diff.Control == nil && diff.DACL.IsEmpty() && diff.SACL.IsEmpty()
+}
+
+// Diff compares the security descriptor with a newer version of it. Unlike Equal, only the
+// meaning of the descriptors is compared: their layout, like the offsets of the components or
+// the raw bytes they were parsed from, is ignored.
+//
+// Parameters:
+//   - other (*NtSecurityDescriptor): The new version of the security descriptor.
+//
+// Returns:
+//   - *Diff: The changes from the security descriptor to the other one.
+func (ntsd *NtSecurityDescriptor) Diff(other *NtSecurityDescriptor) *Diff {
+	diff := &Diff{}
+
+	diff.Owner = diffSID(ntsd.Owner, other.Owner)
+	diff.Group = diffSID(ntsd.Group, other.Group)
+	diff.Control = diffControl(ntsd.Header.Control.RawValue, other.Header.Control.RawValue)
+
+	var oldEntries, newEntries []ace.AccessControlEntry
+	if ntsd.DACL != nil {
+		oldEntries = ntsd.DACL.Entries
+	}
+	if other.DACL != nil {
+		newEntries = other.DACL.Entries
+	}
+	diff.DACL = diffACL(ntsd.DACL != nil, other.DACL != nil, oldEntries, newEntries)
+
+	oldEntries, newEntries = nil, nil
+	if ntsd.SACL != nil {
+		oldEntries = ntsd.SACL.Entries
+	}
+	if other.SACL != nil {
+		newEntries = other.SACL.Entries
+	}
+	diff.SACL = diffACL(ntsd.SACL != nil, other.SACL != nil, oldEntries, newEntries)
+
+	return diff
+}
+
+// diffSID compares the owners or the groups of two descriptors. An identity with an unset SID
+// is absent, like for Marshal.
+func diffSID(oldIdentity, newIdentity *identity.Identity) *SIDChange {
+	var oldSID, newSID *sid.SID
+	if oldIdentity != nil && oldIdentity.SID.RevisionLevel != 0 {
+		oldSID = &oldIdentity.SID
+	}
+	if newIdentity != nil && newIdentity.SID.RevisionLevel != 0 {
+		newSID = &newIdentity.SID
+	}
+	if oldSID == nil && newSID == nil {
+		return nil
+	}
+	if oldSID != nil && newSID != nil && oldSID.ToString() == newSID.ToString() {
+		return nil
+	}
+	return &SIDChange{Old: oldSID, New: newSID}
+}
+
+// diffControl compares the control flags of two descriptors.
+func diffControl(oldValue, newValue uint16) *ControlChange {
+	if oldValue == newValue {
+		return nil
+	}
+	change := &ControlChange{Old: oldValue, New: newValue, Added: []string{}, Removed: []string{}}
+	for bit := 0; bit < 16; bit++ {
+		flag := uint16(1) << bit
+		name, exists := control.NtSecurityDescriptorControlValueToShortName[flag]
+		if !exists {
+			name = fmt.Sprintf("0x%04x", flag)
+		}
+		switch {
+		case newValue&flag != 0 && oldValue&flag == 0:
+			change.Added = append(change.Added, name)
+		case oldValue&flag != 0 && newValue&flag == 0:
+			change.Removed = append(change.Removed, name)
+		}
+	}
+	return change
+}
+
+// diffACL compares the entries of two ACLs.
+//
+// Parameters:
+//   - oldPresent (bool): Whether the old ACL is present.
+//   - newPresent (bool): Whether the new ACL is present.
+//   - oldEntries ([]ace.AccessControlEntry): The entries of the old ACL.
+//   - newEntries ([]ace.AccessControlEntry): The entries of the new ACL.
+//
+// Returns:
+//   - ACLDiff: The changes of the ACL.
+func diffACL(oldPresent, newPresent bool, oldEntries, newEntries []ace.AccessControlEntry) ACLDiff {
+	aclDiff := ACLDiff{OldPresent: oldPresent, NewPresent: newPresent, Entries: []EntryChange{}}
+
+	// Match the entries by key, in order, so that duplicated entries are paired up one by one
+	pending := map[string][]int{}
+	for index := range oldEntries {
+		key := diffEntryKey(&oldEntries[index])
+		pending[key] = append(pending[key], index)
+	}
+	oldToNew := make([]int, len(oldEntries))
+	for index := range oldToNew {
+		oldToNew[index] = -1
+	}
+	newToOld := make([]int, len(newEntries))
+	for index := range newEntries {
+		newToOld[index] = -1
+		key := diffEntryKey(&newEntries[index])
+		if candidates := pending[key]; len(candidates) != 0 {
+			newToOld[index] = candidates[0]
+			oldToNew[candidates[0]] = index
+			pending[key] = candidates[1:]
+		}
+	}
+
+	// The matched entries that keep their relative order are the longest increasing
+	// subsequence of their new indexes, taken in the old order. The others moved.
+	matched := []int{}
+	for oldIndex, newIndex := range oldToNew {
+		if newIndex != -1 {
+			matched = append(matched, oldIndex)
+		}
+	}
+	inOrder := longestIncreasingSubsequence(matched, oldToNew)
+
+	type positioned struct {
+		position int
+		change   EntryChange
+	}
+	changes := []positioned{}
+
+	for newIndex := range newEntries {
+		oldIndex := newToOld[newIndex]
+		if oldIndex == -1 {
+			changes = append(changes, positioned{2*newIndex + 1, EntryChange{Kind: DIFF_KIND_ADDED, OldIndex: -1, NewIndex: newIndex, New: &newEntries[newIndex]}})
+			continue
+		}
+
+		fields := diffEntryFields(&oldEntries[oldIndex], &newEntries[newIndex])
+		if !inOrder[oldIndex] {
+			fields = append(fields, "Position")
+		}
+		switch {
+		case len(fields) == 1 && fields[0] == "Position":
+			changes = append(changes, positioned{2*newIndex + 1, EntryChange{Kind: DIFF_KIND_MOVED, OldIndex: oldIndex, NewIndex: newIndex, Old: &oldEntries[oldIndex], New: &newEntries[newIndex]}})
+		case len(fields) != 0:
+			changes = append(changes, positioned{2*newIndex + 1, EntryChange{Kind: DIFF_KIND_MODIFIED, OldIndex: oldIndex, NewIndex: newIndex, Old: &oldEntries[oldIndex], New: &newEntries[newIndex], Fields: fields}})
+		}
+	}
+
+	// A removed entry is placed right after the new entry matching the last old entry kept in
+	// order before it, and before the entries added there
+	for oldIndex := range oldEntries {
+		if oldToNew[oldIndex] != -1 {
+			continue
+		}
+		position := 0
+		for preceding := oldIndex - 1; preceding >= 0; preceding-- {
+			if inOrder[preceding] {
+				position = oldToNew[preceding] + 1
+				break
+			}
+		}
+		changes = append(changes, positioned{2 * position, EntryChange{Kind: DIFF_KIND_REMOVED, OldIndex: oldIndex, NewIndex: -1, Old: &oldEntries[oldIndex]}})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].position < changes[j].position
+	})
+	for _, change := range changes {
+		aclDiff.Entries = append(aclDiff.Entries, change.change)
+	}
+
+	return aclDiff
+}
+
+// longestIncreasingSubsequence finds the entries keeping their relative order between two
+// ACLs.
+//
+// Parameters:
+//   - matched ([]int): The old indexes of the matched entries, in increasing order.
+//   - oldToNew ([]int): The new index of each old entry.
+//
+// Returns:
+//   - map[int]bool: The old indexes of the entries of a longest subsequence whose new
+//     indexes are increasing.
+func longestIncreasingSubsequence(matched []int, oldToNew []int) map[int]bool {
+	// tails[length-1] is the position in matched of the smallest tail of a subsequence of
+	// this length, previous links each element to its predecessor in the subsequence
+	tails := []int{}
+	previous := make([]int, len(matched))
+	for position, oldIndex := range matched {
+		newIndex := oldToNew[oldIndex]
+		length := sort.Search(len(tails), func(i int) bool {
+			return oldToNew[matched[tails[i]]] >= newIndex
+		})
+		previous[position] = -1
+		if length > 0 {
+			previous[position] = tails[length-1]
+		}
+		if length == len(tails) {
+			tails = append(tails, position)
+		} else {
+			tails[length] = position
+		}
+	}
+
+	inOrder := map[int]bool{}
+	if len(tails) != 0 {
+		for position := tails[len(tails)-1]; position != -1; position = previous[position] {
+			inOrder[matched[position]] = true
+		}
+	}
+	return inOrder
+}
+
+// diffEntryKey returns the identity of an ACE used to match it between two ACLs: its type,
+// flags, trustee and object types. Opaque ACEs are matched by their whole body.
+func diffEntryKey(entry *ace.AccessControlEntry) string {
+	if entry.Opaque {
+		return fmt.Sprintf("%d|%d|opaque|%s", entry.Header.Type.Value, entry.Header.Flags.RawValue, hex.EncodeToString(entry.ApplicationData))
+	}
+	key := fmt.Sprintf("%d|%d|%s", entry.Header.Type.Value, entry.Header.Flags.RawValue, entry.Identity.SID.ToString())
+	if entry.IsObjectAce() {
+		objectType := &entry.AccessControlObjectType
+		key += fmt.Sprintf("|%d", objectType.Flags.Value)
+		if objectType.Flags.Value&flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT != 0 {
+			key += "|" + objectType.ObjectType.GUID.ToFormatD()
+		}
+		if objectType.Flags.Value&flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT != 0 {
+			key += "|" + objectType.InheritedObjectType.GUID.ToFormatD()
+		}
+	}
+	return key
+}
+
+// diffEntryFields returns the fields that differ between two matched ACEs.
+func diffEntryFields(oldEntry, newEntry *ace.AccessControlEntry) []string {
+	fields := []string{}
+	if oldEntry.Mask.RawValue != newEntry.Mask.RawValue {
+		fields = append(fields, "Mask")
+	}
+	if !bytes.Equal(oldEntry.ApplicationData, newEntry.ApplicationData) {
+		fields = append(fields, "ApplicationData")
+	}
+	if oldEntry.Header.Type.Value == acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND && !oldEntry.Compound.Equal(&newEntry.Compound) {
+		fields = append(fields, "Compound")
+	}
+	return slices.Clip(fields)
+}
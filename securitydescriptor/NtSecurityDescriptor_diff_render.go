@@ -0,0 +1,226 @@
+package securitydescriptor
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/TheManticoreProject/winacl/ace"
+)
+
+// jsonSIDChange is the JSON form of a SIDChange.
+type jsonSIDChange struct {
+	Old *string `json:"old"`
+	New *string `json:"new"`
+}
+
+// jsonControlChange is the JSON form of a ControlChange.
+type jsonControlChange struct {
+	Old     uint16   `json:"old"`
+	New     uint16   `json:"new"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// jsonEntryChange is the JSON form of an EntryChange.
+type jsonEntryChange struct {
+	Kind     string   `json:"kind"`
+	OldIndex int      `json:"oldIndex"`
+	NewIndex int      `json:"newIndex"`
+	Old      *string  `json:"old"`
+	New      *string  `json:"new"`
+	Fields   []string `json:"fields"`
+}
+
+// jsonACLDiff is the JSON form of an ACLDiff.
+type jsonACLDiff struct {
+	OldPresent bool              `json:"oldPresent"`
+	NewPresent bool              `json:"newPresent"`
+	Entries    []jsonEntryChange `json:"entries"`
+}
+
+// jsonDiff is the JSON form of a Diff.
+type jsonDiff struct {
+	Owner   *jsonSIDChange     `json:"owner"`
+	Group   *jsonSIDChange     `json:"group"`
+	Control *jsonControlChange `json:"control"`
+	DACL    jsonACLDiff        `json:"dacl"`
+	SACL    jsonACLDiff        `json:"sacl"`
+}
+
+// MarshalJSON renders the diff as a JSON object. SIDs are written in their string form and
+// ACEs in SDDL, unchanged parts being null.
+//
+// Returns:
+//   - []byte: The JSON object.
+//   - error: An error if the diff cannot be rendered.
+func (diff *Diff) MarshalJSON() ([]byte, error) {
+	document := jsonDiff{
+		Owner: diff.Owner.toJSON(),
+		Group: diff.Group.toJSON(),
+		DACL:  diff.DACL.toJSON(),
+		SACL:  diff.SACL.toJSON(),
+	}
+	if diff.Control != nil {
+		document.Control = &jsonControlChange{
+			Old:     diff.Control.Old,
+			New:     diff.Control.New,
+			Added:   diff.Control.Added,
+			Removed: diff.Control.Removed,
+		}
+	}
+	return json.Marshal(document)
+}
+
+// ToJSON renders the diff as an indented JSON document, see MarshalJSON.
+//
+// Returns:
+//   - []byte: The JSON document.
+//   - error: An error if the diff cannot be rendered.
+func (diff *Diff) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(diff, "", "  ")
+}
+
+// toJSON returns the JSON form of the SID change, nil if there is none.
+func (change *SIDChange) toJSON() *jsonSIDChange {
+	if change == nil {
+		return nil
+	}
+	document := &jsonSIDChange{}
+	if change.Old != nil {
+		value := change.Old.ToString()
+		document.Old = &value
+	}
+	if change.New != nil {
+		value := change.New.ToString()
+		document.New = &value
+	}
+	return document
+}
+
+// toJSON returns the JSON form of the ACL diff.
+func (aclDiff *ACLDiff) toJSON() jsonACLDiff {
+	document := jsonACLDiff{
+		OldPresent: aclDiff.OldPresent,
+		NewPresent: aclDiff.NewPresent,
+		Entries:    make([]jsonEntryChange, 0, len(aclDiff.Entries)),
+	}
+	for _, change := range aclDiff.Entries {
+		entry := jsonEntryChange{
+			Kind:     change.Kind.String(),
+			OldIndex: change.OldIndex,
+			NewIndex: change.NewIndex,
+			Fields:   change.Fields,
+		}
+		if entry.Fields == nil {
+			entry.Fields = []string{}
+		}
+		if change.Old != nil {
+			value := diffEntryString(change.Old)
+			entry.Old = &value
+		}
+		if change.New != nil {
+			value := diffEntryString(change.New)
+			entry.New = &value
+		}
+		document.Entries = append(document.Entries, entry)
+	}
+	return document
+}
+
+// Unified renders the diff as text, in a format close to the unified diff format: a section
+// per changed part of the descriptor, removed lines starting with '-', added lines with '+'
+// and moved ACEs with '~'. ACEs are written in SDDL, prefixed with their index.
+//
+// Returns:
+//   - string: The text of the diff, holding only the header lines if the diff is empty.
+func (diff *Diff) Unified() string {
+	var builder strings.Builder
+
+	builder.WriteString("--- old\n")
+	builder.WriteString("+++ new\n")
+
+	writeSIDChange(&builder, "Owner", "O:", diff.Owner)
+	writeSIDChange(&builder, "Group", "G:", diff.Group)
+
+	if diff.Control != nil {
+		builder.WriteString("@@ Control @@\n")
+		fmt.Fprintf(&builder, "-0x%04x\n", diff.Control.Old)
+		fmt.Fprintf(&builder, "+0x%04x\n", diff.Control.New)
+		if len(diff.Control.Added) != 0 {
+			fmt.Fprintf(&builder, "# added: %s\n", strings.Join(diff.Control.Added, "|"))
+		}
+		if len(diff.Control.Removed) != 0 {
+			fmt.Fprintf(&builder, "# removed: %s\n", strings.Join(diff.Control.Removed, "|"))
+		}
+	}
+
+	writeACLDiff(&builder, "DACL", &diff.DACL)
+	writeACLDiff(&builder, "SACL", &diff.SACL)
+
+	return builder.String()
+}
+
+// String returns the unified form of the diff, see Unified.
+//
+// Returns:
+//   - string: The text of the diff.
+func (diff *Diff) String() string {
+	return diff.Unified()
+}
+
+// writeSIDChange writes the section of a changed owner or group.
+func writeSIDChange(builder *strings.Builder, section string, prefix string, change *SIDChange) {
+	if change == nil {
+		return
+	}
+	fmt.Fprintf(builder, "@@ %s @@\n", section)
+	if change.Old != nil {
+		fmt.Fprintf(builder, "-%s%s\n", prefix, sddlSIDToString(change.Old))
+	}
+	if change.New != nil {
+		fmt.Fprintf(builder, "+%s%s\n", prefix, sddlSIDToString(change.New))
+	}
+}
+
+// writeACLDiff writes the section of a changed DACL or SACL.
+func writeACLDiff(builder *strings.Builder, section string, aclDiff *ACLDiff) {
+	if aclDiff.IsEmpty() {
+		return
+	}
+	fmt.Fprintf(builder, "@@ %s @@\n", section)
+	switch {
+	case aclDiff.OldPresent && !aclDiff.NewPresent:
+		fmt.Fprintf(builder, "# %s removed\n", section)
+	case !aclDiff.OldPresent && aclDiff.NewPresent:
+		fmt.Fprintf(builder, "# %s added\n", section)
+	}
+	for _, change := range aclDiff.Entries {
+		switch change.Kind {
+		case DIFF_KIND_ADDED:
+			fmt.Fprintf(builder, "+[%d] (%s)\n", change.NewIndex, diffEntryString(change.New))
+		case DIFF_KIND_REMOVED:
+			fmt.Fprintf(builder, "-[%d] (%s)\n", change.OldIndex, diffEntryString(change.Old))
+		case DIFF_KIND_MODIFIED:
+			fmt.Fprintf(builder, "-[%d] (%s)\n", change.OldIndex, diffEntryString(change.Old))
+			fmt.Fprintf(builder, "+[%d] (%s)\n", change.NewIndex, diffEntryString(change.New))
+			fmt.Fprintf(builder, "# %s\n", strings.Join(change.Fields, ", "))
+		case DIFF_KIND_MOVED:
+			fmt.Fprintf(builder, "~[%d->%d] (%s)\n", change.OldIndex, change.NewIndex, diffEntryString(change.New))
+		}
+	}
+}
+
+// diffEntryString returns the SDDL form of an ACE, without its parentheses. ACEs without an
+// SDDL form, like opaque and compound ACEs, are written as their type and hexadecimal body.
+func diffEntryString(entry *ace.AccessControlEntry) string {
+	if value, err := sddlACEToString(entry); err == nil {
+		return value
+	}
+	data, err := entry.AppendBinary(nil)
+	if err != nil {
+		data = entry.ApplicationData
+	}
+	return fmt.Sprintf("<%s %s>", entry.Header.Type.String(), hex.EncodeToString(data))
+}
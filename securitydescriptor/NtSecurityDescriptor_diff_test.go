@@ -0,0 +1,227 @@
+package securitydescriptor_test
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+)
+
+// diffEntrySummary describes an EntryChange for comparisons in the diff tests.
+type diffEntrySummary struct {
+	kind     securitydescriptor.DiffKind
+	oldIndex int
+	newIndex int
+	fields   []string
+}
+
+func TestNtSecurityDescriptor_Diff(t *testing.T) {
+	tests := []struct {
+		name       string
+		oldSDDL    string
+		newSDDL    string
+		wantOwner  bool
+		wantDACL   []diffEntrySummary
+		reordering bool
+	}{
+		{
+			name:    "Unchanged",
+			oldSDDL: "O:BAG:SYD:(A;;FA;;;WD)(D;;FR;;;BU)",
+			newSDDL: "O:BAG:SYD:(A;;FA;;;WD)(D;;FR;;;BU)",
+		},
+		{
+			name:      "OwnerChanged",
+			oldSDDL:   "O:BAG:SYD:(A;;FA;;;WD)",
+			newSDDL:   "O:SYG:SYD:(A;;FA;;;WD)",
+			wantOwner: true,
+		},
+		{
+			name:    "EntryInserted",
+			oldSDDL: "D:(A;;FA;;;WD)(A;;FR;;;BU)",
+			newSDDL: "D:(D;;FA;;;AN)(A;;FA;;;WD)(A;;FR;;;BU)",
+			wantDACL: []diffEntrySummary{
+				{securitydescriptor.DIFF_KIND_ADDED, -1, 0, nil},
+			},
+		},
+		{
+			name:    "EntryRemoved",
+			oldSDDL: "D:(A;;FA;;;WD)(A;;FR;;;BU)(A;;FX;;;AU)",
+			newSDDL: "D:(A;;FA;;;WD)(A;;FX;;;AU)",
+			wantDACL: []diffEntrySummary{
+				{securitydescriptor.DIFF_KIND_REMOVED, 1, -1, nil},
+			},
+		},
+		{
+			name:    "MaskModified",
+			oldSDDL: "D:(A;;FR;;;WD)(A;;FR;;;BU)",
+			newSDDL: "D:(A;;FA;;;WD)(A;;FR;;;BU)",
+			wantDACL: []diffEntrySummary{
+				{securitydescriptor.DIFF_KIND_MODIFIED, 0, 0, []string{"Mask"}},
+			},
+		},
+		{
+			name:    "ReorderedOnly",
+			oldSDDL: "D:(A;;FA;;;WD)(A;;FR;;;BU)(A;;FX;;;AU)",
+			newSDDL: "D:(A;;FX;;;AU)(A;;FA;;;WD)(A;;FR;;;BU)",
+			wantDACL: []diffEntrySummary{
+				{securitydescriptor.DIFF_KIND_MOVED, 2, 0, nil},
+			},
+			reordering: true,
+		},
+		{
+			name:    "ModifiedAndMoved",
+			oldSDDL: "D:(A;;FA;;;WD)(A;;FR;;;BU)(A;;FR;;;AU)",
+			newSDDL: "D:(A;;FA;;;AU)(A;;FA;;;WD)(A;;FR;;;BU)",
+			wantDACL: []diffEntrySummary{
+				{securitydescriptor.DIFF_KIND_MODIFIED, 2, 0, []string{"Mask", "Position"}},
+			},
+		},
+		{
+			name:    "ObjectTypeChanged",
+			oldSDDL: "D:(OA;;WP;bf967a86-0de6-11d0-a285-00aa003049e2;;AU)",
+			newSDDL: "D:(OA;;WP;bf967aba-0de6-11d0-a285-00aa003049e2;;AU)",
+			wantDACL: []diffEntrySummary{
+				{securitydescriptor.DIFF_KIND_REMOVED, 0, -1, nil},
+				{securitydescriptor.DIFF_KIND_ADDED, -1, 0, nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := sddlTestDescriptor(t, tt.oldSDDL).Diff(sddlTestDescriptor(t, tt.newSDDL))
+
+			if (diff.Owner != nil) != tt.wantOwner {
+				t.Errorf("Owner = %v, want changed = %v", diff.Owner, tt.wantOwner)
+			}
+			if diff.Group != nil {
+				t.Errorf("Group = %v, want nil", diff.Group)
+			}
+
+			got := []diffEntrySummary{}
+			for _, change := range diff.DACL.Entries {
+				got = append(got, diffEntrySummary{change.Kind, change.OldIndex, change.NewIndex, change.Fields})
+			}
+			if len(got) != len(tt.wantDACL) {
+				t.Fatalf("DACL.Entries = %+v, want %+v", got, tt.wantDACL)
+			}
+			for index := range got {
+				if got[index].kind != tt.wantDACL[index].kind || got[index].oldIndex != tt.wantDACL[index].oldIndex ||
+					got[index].newIndex != tt.wantDACL[index].newIndex || !slices.Equal(got[index].fields, tt.wantDACL[index].fields) {
+					t.Errorf("DACL.Entries[%d] = %+v, want %+v", index, got[index], tt.wantDACL[index])
+				}
+			}
+
+			if diff.DACL.IsReorderedOnly() != tt.reordering {
+				t.Errorf("DACL.IsReorderedOnly() = %v, want %v", diff.DACL.IsReorderedOnly(), tt.reordering)
+			}
+			wantEmpty := !tt.wantOwner && len(tt.wantDACL) == 0
+			if diff.IsEmpty() != wantEmpty {
+				t.Errorf("IsEmpty() = %v, want %v", diff.IsEmpty(), wantEmpty)
+			}
+		})
+	}
+}
+
+func TestNtSecurityDescriptor_Diff_IgnoresLayout(t *testing.T) {
+	parsed := sddlTestDescriptor(t, appendTestSDDL)
+	diff := parsed.Diff(parsed.Clone())
+	if !diff.IsEmpty() {
+		t.Errorf("Diff() of a clone = %s, want empty", diff.Unified())
+	}
+
+	// The same descriptor built from SDDL has no raw bytes and other offsets, but the same meaning
+	built := sddlTestDescriptor(t, "O:BAG:S-1-5-21-1-2-3-513"+
+		"D:(A;;FA;;;WD)(D;CIIO;FR;;;BU)"+
+		"(OA;CI;WP;bf967a86-0de6-11d0-a285-00aa003049e2;bf967aba-0de6-11d0-a285-00aa003049e2;AU)"+
+		"(XA;;FX;;;WD;(@User.Title == \"PM\"))"+
+		"S:(AU;SA;FA;;;WD)")
+	if diff := parsed.Diff(built); !diff.IsEmpty() {
+		t.Errorf("Diff() of the SDDL descriptor = %s, want empty", diff.Unified())
+	}
+}
+
+func TestNtSecurityDescriptor_Diff_Control(t *testing.T) {
+	oldNtsd := sddlTestDescriptor(t, "D:(A;;FA;;;WD)")
+	newNtsd := sddlTestDescriptor(t, "D:PAI(A;;FA;;;WD)")
+
+	diff := oldNtsd.Diff(newNtsd)
+	if diff.Control == nil {
+		t.Fatal("Control = nil, want a change")
+	}
+	if !slices.Equal(diff.Control.Added, []string{"DI", "PD"}) {
+		t.Errorf("Control.Added = %v, want [DI PD]", diff.Control.Added)
+	}
+	if len(diff.Control.Removed) != 0 {
+		t.Errorf("Control.Removed = %v, want none", diff.Control.Removed)
+	}
+}
+
+func TestDiff_ToJSON(t *testing.T) {
+	oldNtsd := sddlTestDescriptor(t, "O:BAD:(A;;GA;;;WD)(A;;GR;;;BU)")
+	newNtsd := sddlTestDescriptor(t, "O:SYD:(A;;GA;;;WD)(A;;GX;;;AU)")
+
+	data, err := oldNtsd.Diff(newNtsd).ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+
+	var document struct {
+		Owner struct {
+			Old string `json:"old"`
+			New string `json:"new"`
+		} `json:"owner"`
+		Group *struct{} `json:"group"`
+		DACL  struct {
+			Entries []struct {
+				Kind     string  `json:"kind"`
+				OldIndex int     `json:"oldIndex"`
+				NewIndex int     `json:"newIndex"`
+				Old      *string `json:"old"`
+				New      *string `json:"new"`
+			} `json:"entries"`
+		} `json:"dacl"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v\n%s", err, data)
+	}
+	if document.Owner.Old != "S-1-5-32-544" || document.Owner.New != "S-1-5-18" {
+		t.Errorf("owner = %+v, want S-1-5-32-544 -> S-1-5-18", document.Owner)
+	}
+	if document.Group != nil {
+		t.Errorf("group = %+v, want null", document.Group)
+	}
+	if len(document.DACL.Entries) != 2 {
+		t.Fatalf("dacl.entries = %+v, want 2 entries", document.DACL.Entries)
+	}
+	removed, added := document.DACL.Entries[0], document.DACL.Entries[1]
+	if removed.Kind != "REMOVED" || removed.Old == nil || *removed.Old != "A;;GR;;;BU" || removed.New != nil {
+		t.Errorf("dacl.entries[0] = %+v, want the removed A;;GR;;;BU", removed)
+	}
+	if added.Kind != "ADDED" || added.New == nil || *added.New != "A;;GX;;;AU" || added.NewIndex != 1 {
+		t.Errorf("dacl.entries[1] = %+v, want the added A;;GX;;;AU", added)
+	}
+}
+
+func TestDiff_Unified(t *testing.T) {
+	oldNtsd := sddlTestDescriptor(t, "O:BAD:(A;;GA;;;WD)(A;;GR;;;BU)(A;;GX;;;AU)S:(AU;SA;FA;;;WD)")
+	newNtsd := sddlTestDescriptor(t, "O:SYD:(A;;GX;;;AU)(A;;GA;;;WD)(A;;GW;;;BU)S:(AU;SA;FA;;;WD)")
+
+	unified := oldNtsd.Diff(newNtsd).Unified()
+	for _, want := range []string{
+		"--- old\n+++ new\n",
+		"@@ Owner @@\n-O:BA\n+O:SY\n",
+		"@@ DACL @@\n",
+		"~[2->0] (A;;GX;;;AU)\n",
+		"-[1] (A;;GR;;;BU)\n+[2] (A;;GW;;;BU)\n# Mask\n",
+	} {
+		if !strings.Contains(unified, want) {
+			t.Errorf("Unified() = %q, want it to contain %q", unified, want)
+		}
+	}
+	if strings.Contains(unified, "@@ SACL @@") || strings.Contains(unified, "@@ Group @@") {
+		t.Errorf("Unified() = %q, want no section for unchanged parts", unified)
+	}
+}
@@ -0,0 +1,236 @@
+package securitydescriptor
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TheManticoreProject/winacl/acl"
+	"github.com/TheManticoreProject/winacl/identity"
+	"github.com/TheManticoreProject/winacl/parsing"
+	"github.com/TheManticoreProject/winacl/securitydescriptor/control"
+	"github.com/TheManticoreProject/winacl/sid"
+)
+
+// JSON_SCHEMA_VERSION is the version of the JSON schema of the security descriptors, written
+// in their "version" field by MarshalJSON. UnmarshalJSON rejects the documents without a
+// version or with a greater version.
+//
+// The schema of version 1 is the following. The fields marked as informative are written for
+// the readers of the document and ignored when it is parsed. The offsets, the ACL sizes, the
+// ACE counts and the indexes of the entries are not part of it, they are computed from the
+// components.
+//
+// Security descriptor:
+//
+//	{
+//	  "version": 1,
+//	  "revision": 1,
+//	  "sbz1": 0,                          (omitted when 0)
+//	  "control": 32772,                   (the SECURITY_DESCRIPTOR_CONTROL bits)
+//	  "controlFlags": ["DP", "SR"],       (informative)
+//	  "owner": "S-1-5-32-544",            (null when absent)
+//	  "group": "S-1-5-18",                (null when absent)
+//	  "dacl": ACL,                        (null when absent)
+//	  "sacl": ACL                         (null when absent)
+//	}
+//
+// ACL:
+//
+//	{
+//	  "revision": 4,
+//	  "sbz1": 0,                          (omitted when 0)
+//	  "sbz2": 0,                          (omitted when 0)
+//	  "entries": [ACE, ...]
+//	}
+//
+// ACE:
+//
+//	{
+//	  "type": 5,
+//	  "typeName": "ACCESS_ALLOWED_OBJECT", (informative)
+//	  "flags": 2,
+//	  "flagNames": ["CONTAINER_INHERIT"],  (informative)
+//	  "size": 56,                          (the AceSize, larger than the content when padded)
+//	  "mask": 48,                          (omitted for opaque ACEs)
+//	  "rights": ["DS_READ_PROPERTY", "DS_WRITE_PROPERTY"], (informative, the names of the mask bits)
+//	  "sid": "S-1-5-11",                   (omitted for opaque ACEs)
+//	  "objectFlags": 3,                    (object ACEs only, the raw flags of the object type fields)
+//	  "objectType": "bf9679c0-...",        (object ACEs only, omitted when absent)
+//	  "inheritedObjectType": "bf967aba-...", (object ACEs only, omitted when absent)
+//	  "compound": {"type": 1, "reserved": 0, "serverSid": "S-1-5-18"}, (compound ACEs only)
+//	  "applicationData": "AQID",           (base64, omitted when empty)
+//	  "opaque": true                       (omitted when false)
+//	}
+//
+// The applicationData holds the conditional expression or the resource attribute of the
+// callback and system resource attribute ACEs, and the whole body of the opaque ACEs, whose
+// type is unknown. The objectFlags of an object ACE must match the presence of its objectType
+// and inheritedObjectType, and are computed from them when omitted. The unknown fields are
+// ignored, so that documents written by later minor additions to the schema can still be read.
+const JSON_SCHEMA_VERSION = 1
+
+// jsonNtSecurityDescriptor is the JSON representation of a security descriptor, see
+// JSON_SCHEMA_VERSION.
+type jsonNtSecurityDescriptor struct {
+	Version      int                                 `json:"version"`
+	Revision     uint8                               `json:"revision"`
+	Sbz1         uint8                               `json:"sbz1,omitempty"`
+	Control      uint16                              `json:"control"`
+	ControlFlags []string                            `json:"controlFlags"`
+	Owner        *sid.SID                            `json:"owner"`
+	Group        *sid.SID                            `json:"group"`
+	DACL         *acl.DiscretionaryAccessControlList `json:"dacl"`
+	SACL         *acl.SystemAccessControlList        `json:"sacl"`
+}
+
+// MarshalBinary returns the binary representation of the security descriptor, implementing
+// encoding.BinaryMarshaler. The security descriptor is left untouched, unlike with Marshal.
+//
+// Returns:
+//   - []byte: The self-relative binary representation of the security descriptor.
+//   - error: An error if the security descriptor cannot be serialized.
+func (ntsd *NtSecurityDescriptor) MarshalBinary() ([]byte, error) {
+	// AppendBinary writes the offsets of the header and the AclSize of the ACLs, so it runs on
+	// a shallow copy with copied ACL headers. The entries and the SIDs are only read.
+	layout := *ntsd
+	if ntsd.DACL != nil {
+		dacl := *ntsd.DACL
+		layout.DACL = &dacl
+	}
+	if ntsd.SACL != nil {
+		sacl := *ntsd.SACL
+		layout.SACL = &sacl
+	}
+	return layout.AppendBinary(make([]byte, 0, ntsd.Size()))
+}
+
+// UnmarshalBinary parses the binary representation of a security descriptor, implementing
+// encoding.BinaryUnmarshaler. The data is copied, so that the security descriptor does not
+// reference it. Like with Unmarshal, the bytes after the components are ignored.
+//
+// Parameters:
+//   - data ([]byte): The self-relative binary representation of the security descriptor.
+//
+// Returns:
+//   - error: An error if the security descriptor cannot be parsed.
+func (ntsd *NtSecurityDescriptor) UnmarshalBinary(data []byte) error {
+	*ntsd = NtSecurityDescriptor{}
+	_, _, err := ntsd.UnmarshalWithOptions(data, parsing.Options{Detach: true})
+	return err
+}
+
+// MarshalText returns the SDDL string of the security descriptor, implementing
+// encoding.TextMarshaler. SDDL does not hold every detail of the binary form, like the
+// padding of the ACEs or the reserved fields, see MarshalJSON for a lossless encoding.
+//
+// Returns:
+//   - []byte: The SDDL string of the security descriptor.
+//   - error: An error if the security descriptor cannot be written in SDDL.
+func (ntsd *NtSecurityDescriptor) MarshalText() ([]byte, error) {
+	sddlString, err := ntsd.ToSDDLString()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(sddlString), nil
+}
+
+// UnmarshalText parses the SDDL string of a security descriptor, implementing
+// encoding.TextUnmarshaler.
+//
+// Parameters:
+//   - text ([]byte): The SDDL string of the security descriptor.
+//
+// Returns:
+//   - error: An error if the text is not a valid SDDL string.
+func (ntsd *NtSecurityDescriptor) UnmarshalText(text []byte) error {
+	parsed := NtSecurityDescriptor{}
+	if _, err := parsed.FromSDDLString(string(text)); err != nil {
+		return err
+	}
+	*ntsd = parsed
+	return nil
+}
+
+// MarshalJSON returns the JSON representation of the security descriptor, implementing
+// json.Marshaler. The document follows the schema of JSON_SCHEMA_VERSION, and keeps every
+// field of the binary form except the layout, which is computed.
+//
+// Returns:
+//   - []byte: The JSON object representing the security descriptor.
+//   - error: An error if the security descriptor cannot be serialized.
+func (ntsd *NtSecurityDescriptor) MarshalJSON() ([]byte, error) {
+	document := jsonNtSecurityDescriptor{
+		Version:      JSON_SCHEMA_VERSION,
+		Revision:     ntsd.Header.Revision,
+		Sbz1:         ntsd.Header.Sbz1,
+		Control:      ntsd.Header.Control.RawValue,
+		ControlFlags: []string{},
+		DACL:         ntsd.DACL,
+		SACL:         ntsd.SACL,
+	}
+	for bit := 0; bit < 16; bit++ {
+		flag := uint16(1) << bit
+		if document.Control&flag == 0 {
+			continue
+		}
+		name, exists := control.NtSecurityDescriptorControlValueToShortName[flag]
+		if !exists {
+			name = fmt.Sprintf("0x%04x", flag)
+		}
+		document.ControlFlags = append(document.ControlFlags, name)
+	}
+	if ntsd.hasOwner() {
+		document.Owner = &ntsd.Owner.SID
+	}
+	if ntsd.hasGroup() {
+		document.Group = &ntsd.Group.SID
+	}
+	return json.Marshal(document)
+}
+
+// ToJSON returns the JSON representation of the security descriptor as an indented document,
+// see MarshalJSON.
+//
+// Returns:
+//   - []byte: The JSON document.
+//   - error: An error if the security descriptor cannot be serialized.
+func (ntsd *NtSecurityDescriptor) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(ntsd, "", "  ")
+}
+
+// UnmarshalJSON parses the JSON representation of a security descriptor, implementing
+// json.Unmarshaler. The offsets are computed, and RawBytes are refreshed like after Marshal.
+//
+// Parameters:
+//   - data ([]byte): The JSON object representing the security descriptor, following the
+//     schema of JSON_SCHEMA_VERSION or of an earlier version.
+//
+// Returns:
+//   - error: An error if the data is not a valid JSON security descriptor or if its version
+//     is not supported.
+func (ntsd *NtSecurityDescriptor) UnmarshalJSON(data []byte) error {
+	document := jsonNtSecurityDescriptor{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("invalid JSON security descriptor: %w", err)
+	}
+	if document.Version < 1 || document.Version > JSON_SCHEMA_VERSION {
+		return fmt.Errorf("invalid JSON security descriptor: unsupported schema version %d, expected 1 to %d", document.Version, JSON_SCHEMA_VERSION)
+	}
+
+	parsed := NtSecurityDescriptor{DACL: document.DACL, SACL: document.SACL}
+	parsed.Header.Revision = document.Revision
+	parsed.Header.Sbz1 = document.Sbz1
+	parsed.Header.Control.Unmarshal(binary.LittleEndian.AppendUint16(nil, document.Control))
+	if document.Owner != nil {
+		parsed.Owner = &identity.Identity{Name: document.Owner.LookupName(), SID: *document.Owner}
+	}
+	if document.Group != nil {
+		parsed.Group = &identity.Identity{Name: document.Group.LookupName(), SID: *document.Group}
+	}
+	if _, err := parsed.Marshal(); err != nil {
+		return fmt.Errorf("invalid JSON security descriptor: %w", err)
+	}
+	*ntsd = parsed
+	return nil
+}
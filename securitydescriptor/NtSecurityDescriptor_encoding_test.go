@@ -0,0 +1,126 @@
+package securitydescriptor_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+)
+
+func TestNtSecurityDescriptor_JSON(t *testing.T) {
+	original := sddlTestDescriptor(t, appendTestSDDL)
+	marshalledData, err := original.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+
+	jsonData, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	for _, want := range []string{
+		`{"version":1,"revision":1,"control":32788,"controlFlags":["DP","SP","SR"],"owner":"S-1-5-32-544","group":"S-1-5-21-1-2-3-513"`,
+		`"mask":32,"rights":["DS_WRITE_PROPERTY"],"sid":"S-1-5-11","objectFlags":3,"objectType":"bf967a86-0de6-11d0-a285-00aa003049e2"`,
+		`"sacl":{"revision":2,"entries":[{"type":2,"typeName":"SYSTEM_AUDIT","flags":64,"flagNames":["SUCCESSFUL_ACCESS"]`,
+	} {
+		if !strings.Contains(string(jsonData), want) {
+			t.Errorf("json.Marshal() = %s, want it to contain %s", jsonData, want)
+		}
+	}
+
+	fromJSON := &securitydescriptor.NtSecurityDescriptor{}
+	if err := json.Unmarshal(jsonData, fromJSON); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !bytes.Equal(fromJSON.RawBytes, marshalledData) {
+		t.Errorf("json.Unmarshal().RawBytes = %x, want %x", fromJSON.RawBytes, marshalledData)
+	}
+	if !fromJSON.Diff(original).IsEmpty() {
+		t.Errorf("json.Unmarshal(json.Marshal()) differs: %s", fromJSON.Diff(original))
+	}
+
+	indented, err := fromJSON.ToJSON()
+	if err != nil || !bytes.HasPrefix(indented, []byte("{\n  \"version\": 1,")) {
+		t.Errorf("ToJSON() = %s, %v", indented, err)
+	}
+}
+
+func TestNtSecurityDescriptor_JSON_Version(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		wantErr  string
+	}{
+		{"Current", `{"version":1,"revision":1,"control":32772,"owner":"S-1-5-18","dacl":{"revision":2,"entries":[]}}`, ""},
+		{"UnknownFieldsIgnored", `{"version":1,"revision":1,"control":32768,"comment":"audited"}`, ""},
+		{"Missing", `{"revision":1,"control":32768}`, "unsupported schema version 0"},
+		{"Future", `{"version":2,"revision":1,"control":32768}`, "unsupported schema version 2"},
+		{"InvalidOwner", `{"version":1,"revision":1,"control":32768,"owner":"BA"}`, "invalid JSON security descriptor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ntsd := &securitydescriptor.NtSecurityDescriptor{}
+			err := json.Unmarshal([]byte(tt.document), ntsd)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("json.Unmarshal() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("json.Unmarshal() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNtSecurityDescriptor_TextAndBinary(t *testing.T) {
+	const sddlString = "O:BAG:SYD:P(A;;GA;;;SY)(OA;CI;RPWP;bf967a86-0de6-11d0-a285-00aa003049e2;;AU)S:(AU;SA;GA;;;WD)"
+
+	fromText := &securitydescriptor.NtSecurityDescriptor{}
+	if err := fromText.UnmarshalText([]byte(sddlString)); err != nil {
+		t.Fatalf("UnmarshalText() error = %v", err)
+	}
+	text, err := fromText.MarshalText()
+	if err != nil || string(text) != sddlString {
+		t.Errorf("MarshalText(UnmarshalText()) = %s, %v, want %s", text, err, sddlString)
+	}
+	if err := fromText.UnmarshalText([]byte("O:XX")); err == nil {
+		t.Errorf("UnmarshalText() of an invalid SDDL string succeeded, want an error")
+	}
+
+	// MarshalBinary does not modify the descriptor, and UnmarshalBinary resets it
+	original := sddlTestDescriptor(t, appendTestSDDL)
+	rawBytes := bytes.Clone(original.RawBytes)
+	marshalledData, err := original.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	if !bytes.Equal(original.RawBytes, rawBytes) || !bytes.Equal(marshalledData, rawBytes) {
+		t.Errorf("MarshalBinary() = %x, want %x", marshalledData, rawBytes)
+	}
+	stale := *original
+	stale.Header.OffsetOwner, stale.Header.OffsetDacl = 0xffff, 0
+	staleDACL := *original.DACL
+	staleDACL.Header.AclSize = 0
+	stale.DACL = &staleDACL
+	if marshalledData, err := stale.MarshalBinary(); err != nil || !bytes.Equal(marshalledData, rawBytes) {
+		t.Errorf("MarshalBinary() with stale offsets = %x, %v, want %x", marshalledData, err, rawBytes)
+	}
+	if stale.Header.OffsetOwner != 0xffff || stale.Header.OffsetDacl != 0 || stale.DACL.Header.AclSize != 0 {
+		t.Errorf("MarshalBinary() modified the header or the DACL header")
+	}
+	if allocs := testing.AllocsPerRun(10, func() { original.MarshalBinary() }); allocs > 1 {
+		t.Errorf("MarshalBinary() allocates %.0f times, want 1", allocs)
+	}
+	if err := fromText.UnmarshalBinary(marshalledData); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	marshalledData[0] = 0xff
+	if fromText.RawBytes[0] != 0x01 || !fromText.Diff(original).IsEmpty() {
+		t.Errorf("UnmarshalBinary() = %v, want a detached copy of the original", fromText)
+	}
+}
@@ -0,0 +1,112 @@
+package securitydescriptor_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/parsing"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+)
+
+func TestNtSecurityDescriptor_Unmarshal_ParseErrorLocation(t *testing.T) {
+	marshalledData := sddlTestDescriptor(t, lenientTestSDDL).RawBytes
+	offsetDacl := int(binary.LittleEndian.Uint32(marshalledData[16:20]))
+	// The second entry follows the DACL header (8 bytes) and the first entry (20 bytes),
+	// its SID follows the ACE header and the mask. Claim 5 sub-authorities out of 1.
+	offsetSid := offsetDacl + 8 + 20 + 8
+	marshalledData[offsetSid+1] = 5
+
+	ntsd := securitydescriptor.NtSecurityDescriptor{}
+	_, err := ntsd.Unmarshal(marshalledData)
+	if err == nil {
+		t.Fatalf("Unmarshal() expected an error for a truncated SID")
+	}
+
+	var parseError *parsing.ParseError
+	if !errors.As(err, &parseError) {
+		t.Fatalf("errors.As() did not find a ParseError in %v", err)
+	}
+	if parseError.Path != "DACL.Entries[1].Identity.SID" {
+		t.Errorf("Path = %q, want DACL.Entries[1].Identity.SID", parseError.Path)
+	}
+	// The first sub-authority is present, the second one is missing.
+	if parseError.Offset != offsetSid+12 {
+		t.Errorf("Offset = %d, want %d", parseError.Offset, offsetSid+12)
+	}
+	if !errors.Is(err, parsing.ErrTruncated) {
+		t.Errorf("errors.Is(err, ErrTruncated) = false for %v", err)
+	}
+}
+
+func TestNtSecurityDescriptor_Unmarshal_ParseErrorCategories(t *testing.T) {
+	offsetDacl := func(marshalledData []byte) int {
+		return int(binary.LittleEndian.Uint32(marshalledData[16:20]))
+	}
+
+	tests := []struct {
+		name     string
+		corrupt  func(marshalledData []byte) int
+		sentinel error
+		path     string
+	}{
+		{
+			name: "Unknown ACE type",
+			corrupt: func(marshalledData []byte) int {
+				marshalledData[offsetDacl(marshalledData)+8] = 0x7f
+				return offsetDacl(marshalledData) + 8
+			},
+			sentinel: parsing.ErrUnknownType,
+			path:     "DACL.Entries[0].Header.Type",
+		},
+		{
+			name: "Offset out of range",
+			corrupt: func(marshalledData []byte) int {
+				binary.LittleEndian.PutUint32(marshalledData[4:8], uint32(len(marshalledData)+0x10))
+				return 4
+			},
+			sentinel: parsing.ErrOutOfRange,
+			path:     "Header.OffsetOwner",
+		},
+		{
+			name: "Invalid ACL revision",
+			corrupt: func(marshalledData []byte) int {
+				marshalledData[offsetDacl(marshalledData)] = 0x09
+				return offsetDacl(marshalledData)
+			},
+			sentinel: parsing.ErrInvalidRevision,
+			path:     "DACL.Header.Revision",
+		},
+		{
+			name: "Invalid descriptor revision",
+			corrupt: func(marshalledData []byte) int {
+				marshalledData[0] = 0x02
+				return 0
+			},
+			sentinel: parsing.ErrInvalidRevision,
+			path:     "Header.Revision",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			marshalledData := sddlTestDescriptor(t, lenientTestSDDL).RawBytes
+			offset := tt.corrupt(marshalledData)
+
+			ntsd := securitydescriptor.NtSecurityDescriptor{}
+			_, err := ntsd.Unmarshal(marshalledData)
+			if !errors.Is(err, tt.sentinel) {
+				t.Fatalf("Unmarshal() error = %v, want %v", err, tt.sentinel)
+			}
+			var parseError *parsing.ParseError
+			if !errors.As(err, &parseError) || parseError.Path != tt.path || parseError.Offset != offset {
+				t.Errorf("Unmarshal() error = %+v, want %s at %d", parseError, tt.path, offset)
+			}
+
+			lenient := securitydescriptor.NtSecurityDescriptor{}
+			if _, _, err := lenient.UnmarshalWithOptions(marshalledData, parsing.Options{Lenient: true}); err != nil {
+				t.Errorf("UnmarshalWithOptions() error = %v in lenient mode", err)
+			}
+		})
+	}
+}
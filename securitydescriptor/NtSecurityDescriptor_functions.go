@@ -1,12 +1,19 @@
 package securitydescriptor
 
 import (
+	"bytes"
 	"slices"
 	"strings"
 
+	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/ace/aceflags"
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/ace/conditional"
 	"github.com/TheManticoreProject/winacl/acl"
 	"github.com/TheManticoreProject/winacl/acl/revision"
 	"github.com/TheManticoreProject/winacl/identity"
+	"github.com/TheManticoreProject/winacl/rights"
+	"github.com/TheManticoreProject/winacl/schema"
 	"github.com/TheManticoreProject/winacl/sid"
 )
 
@@ -96,6 +103,20 @@ func (ntsd *NtSecurityDescriptor) FindIdentitiesWithAllExtendedRights(extendedRi
 	return identitiesMap
 }
 
+// maskHasRight reports whether all the bits of a right are set in an access mask.
+// The bits are tested on the raw value rather than on the decoded rights, because a
+// rights namespace reports a composite right instead of the individual rights it covers.
+//
+// Parameters:
+//   - accessMask (uint32): The access mask to test.
+//   - accessMaskRightValue (uint32): The access mask right value to look for.
+//
+// Returns:
+//   - bool: true if the right is non-zero and all of its bits are set, false otherwise.
+func maskHasRight(accessMask uint32, accessMaskRightValue uint32) bool {
+	return accessMaskRightValue != 0 && accessMask&accessMaskRightValue == accessMaskRightValue
+}
+
 // FindIdentitiesWithRight finds identities that have a specific access mask right.
 //
 // Parameters:
@@ -108,7 +129,7 @@ func (ntsd *NtSecurityDescriptor) FindIdentitiesWithRight(accessMaskRightValue u
 
 	for _, ace := range ntsd.DACL.Entries {
 		matchingRights := make([]uint32, 0)
-		if slices.Contains(ace.Mask.Values, accessMaskRightValue) {
+		if maskHasRight(ace.Mask.RawValue, accessMaskRightValue) {
 			matchingRights = append(matchingRights, accessMaskRightValue)
 			identitiesMap[&ace.Identity.SID] = matchingRights
 		}
@@ -134,7 +155,7 @@ func (ntsd *NtSecurityDescriptor) FindIdentitiesWithAnyRight(accessMaskRights []
 	for _, ace := range ntsd.DACL.Entries {
 		matchingRights := make([]uint32, 0)
 		for _, accessMaskRightValue := range accessMaskRights {
-			if slices.Contains(ace.Mask.Values, accessMaskRightValue) {
+			if maskHasRight(ace.Mask.RawValue, accessMaskRightValue) {
 				matchingRights = append(matchingRights, accessMaskRightValue)
 			}
 		}
@@ -164,7 +185,7 @@ func (ntsd *NtSecurityDescriptor) FindIdentitiesWithAllRights(accessMaskRights [
 		allRightsMatched := true
 		// fmt.Printf("ACE ID %d\n", ace.Index)
 		for _, accessMaskRightValue := range accessMaskRights {
-			if slices.Contains(ace.Mask.Values, accessMaskRightValue) {
+			if maskHasRight(ace.Mask.RawValue, accessMaskRightValue) {
 				// Right is present
 				allRightsMatched = allRightsMatched && true
 			} else {
@@ -232,6 +253,220 @@ func (ntsd *NtSecurityDescriptor) FindIdentitiesWithUnexpectedExtendedRights(exp
 	return unexpectedIdentities
 }
 
+// FindIdentitiesWithChildObjectRight finds identities that are granted a child object right
+// (typically DS_CREATE_CHILD or DS_DELETE_CHILD) on a specific schema class. An allow ACE
+// without an ObjectType applies to every class and is therefore reported as well.
+//
+// Parameters:
+//   - accessMaskRightValue (uint32): The access mask right value to search for.
+//   - schemaClass (string): The schemaIDGUID or lDAPDisplayName of the class (e.g. "computer").
+//
+// Returns:
+//   - map[*identity.SID][]string: A map of identities to the schemaIDGUID of the matching class.
+func (ntsd *NtSecurityDescriptor) FindIdentitiesWithChildObjectRight(accessMaskRightValue uint32, schemaClass string) map[*sid.SID][]string {
+	return ntsd.FindIdentitiesWithChildObjectRightInRegistry(schema.GetDefaultRegistry(), accessMaskRightValue, schemaClass)
+}
+
+// FindIdentitiesWithChildObjectRightInRegistry is FindIdentitiesWithChildObjectRight resolving the class
+// name with a specific schema registry.
+//
+// Parameters:
+//   - registry (*schema.Registry): The schema registry used to resolve the class.
+//   - accessMaskRightValue (uint32): The access mask right value to search for.
+//   - schemaClass (string): The schemaIDGUID or lDAPDisplayName of the class (e.g. "computer").
+//
+// Returns:
+//   - map[*identity.SID][]string: A map of identities to the schemaIDGUID of the matching class.
+func (ntsd *NtSecurityDescriptor) FindIdentitiesWithChildObjectRightInRegistry(registry *schema.Registry, accessMaskRightValue uint32, schemaClass string) map[*sid.SID][]string {
+	identitiesMap := make(map[*sid.SID][]string)
+
+	schemaIDGUID := strings.ToLower(schemaClass)
+	if class, exists := registry.LookupClass(schemaClass); exists {
+		schemaIDGUID = class.SchemaIDGUID
+	}
+
+	for _, ace := range ntsd.DACL.Entries {
+		if ace.Header.Type.Value != acetype.ACE_TYPE_ACCESS_ALLOWED && ace.Header.Type.Value != acetype.ACE_TYPE_ACCESS_ALLOWED_OBJECT {
+			continue
+		}
+		if ace.Mask.SpecificRights()&accessMaskRightValue != accessMaskRightValue {
+			continue
+		}
+		if ace.AccessControlObjectType.Flags.IsObjectTypePresent() && !strings.EqualFold(ace.AccessControlObjectType.ObjectType.GUID.ToFormatD(), schemaIDGUID) {
+			continue
+		}
+		identitiesMap[&ace.Identity.SID] = []string{schemaIDGUID}
+	}
+
+	return identitiesMap
+}
+
+// FindIdentitiesWithAttributeRight finds identities that are granted a property right
+// (typically DS_READ_PROPERTY or DS_WRITE_PROPERTY) on a specific attribute. The right can be
+// granted on the attribute itself, on the property set the attribute belongs to, or on all
+// properties by an allow ACE without an ObjectType.
+//
+// Parameters:
+//   - accessMaskRightValue (uint32): The access mask right value to search for.
+//   - attribute (string): The schemaIDGUID, name or lDAPDisplayName of the attribute (e.g. "userAccountControl").
+//
+// Returns:
+//   - map[*identity.SID][]string: A map of identities to the GUIDs through which the right is granted.
+//     An empty string denotes an ACE without an ObjectType.
+func (ntsd *NtSecurityDescriptor) FindIdentitiesWithAttributeRight(accessMaskRightValue uint32, attribute string) map[*sid.SID][]string {
+	return ntsd.FindIdentitiesWithAttributeRightInRegistry(schema.GetDefaultRegistry(), accessMaskRightValue, attribute)
+}
+
+// FindIdentitiesWithAttributeRightInRegistry is FindIdentitiesWithAttributeRight resolving the attribute
+// and its property set with a specific schema registry.
+//
+// Parameters:
+//   - registry (*schema.Registry): The schema registry used to resolve the attribute.
+//   - accessMaskRightValue (uint32): The access mask right value to search for.
+//   - attribute (string): The schemaIDGUID, name or lDAPDisplayName of the attribute (e.g. "userAccountControl").
+//
+// Returns:
+//   - map[*identity.SID][]string: A map of identities to the GUIDs through which the right is granted.
+//     An empty string denotes an ACE without an ObjectType.
+func (ntsd *NtSecurityDescriptor) FindIdentitiesWithAttributeRightInRegistry(registry *schema.Registry, accessMaskRightValue uint32, attribute string) map[*sid.SID][]string {
+	identitiesMap := make(map[*sid.SID][]string)
+
+	schemaIDGUID := strings.ToLower(attribute)
+	attributeSecurityGUID := ""
+	if schemaAttribute, exists := registry.LookupAttribute(attribute); exists {
+		schemaIDGUID = schemaAttribute.SchemaIDGUID
+		attributeSecurityGUID = schemaAttribute.AttributeSecurityGUID
+	}
+
+	for _, ace := range ntsd.DACL.Entries {
+		if ace.Header.Type.Value != acetype.ACE_TYPE_ACCESS_ALLOWED && ace.Header.Type.Value != acetype.ACE_TYPE_ACCESS_ALLOWED_OBJECT {
+			continue
+		}
+		if ace.Mask.SpecificRights()&accessMaskRightValue != accessMaskRightValue {
+			continue
+		}
+		grantedThrough := ""
+		if ace.AccessControlObjectType.Flags.IsObjectTypePresent() {
+			grantedThrough = ace.AccessControlObjectType.ObjectType.GUID.ToFormatD()
+			if grantedThrough != schemaIDGUID && (attributeSecurityGUID == "" || grantedThrough != attributeSecurityGUID) {
+				continue
+			}
+		}
+		identitiesMap[&ace.Identity.SID] = append(identitiesMap[&ace.Identity.SID], grantedThrough)
+	}
+
+	return identitiesMap
+}
+
+// FindEntriesWithInapplicableExtendedRights finds the object ACEs of the DACL and SACL that reference
+// a control access right, validated write or property set which does not apply to the class of the
+// object the security descriptor belongs to. Such ACEs have no effect and usually indicate a
+// misconfiguration, e.g. DS-Replication-Get-Changes granted on a user object. Inherit-only ACEs are
+// ignored, since they do not control access to the object itself.
+//
+// Parameters:
+//   - schemaClass (string): The schemaIDGUID or lDAPDisplayName of the object's class (e.g. "user").
+//
+// Returns:
+//   - []*ace.AccessControlEntry: The ACEs referencing a right that does not apply to the class.
+func (ntsd *NtSecurityDescriptor) FindEntriesWithInapplicableExtendedRights(schemaClass string) []*ace.AccessControlEntry {
+	return ntsd.FindEntriesWithInapplicableExtendedRightsInRegistry(schema.GetDefaultRegistry(), schemaClass)
+}
+
+// FindEntriesWithInapplicableExtendedRightsInRegistry is FindEntriesWithInapplicableExtendedRights resolving
+// the rights and the class hierarchy with a specific schema registry.
+//
+// Parameters:
+//   - registry (*schema.Registry): The schema registry used to resolve the rights and the class.
+//   - schemaClass (string): The schemaIDGUID or lDAPDisplayName of the object's class (e.g. "user").
+//
+// Returns:
+//   - []*ace.AccessControlEntry: The ACEs referencing a right that does not apply to the class.
+func (ntsd *NtSecurityDescriptor) FindEntriesWithInapplicableExtendedRightsInRegistry(registry *schema.Registry, schemaClass string) []*ace.AccessControlEntry {
+	entries := make([]*ace.AccessControlEntry, 0)
+
+	candidates := make([]*ace.AccessControlEntry, 0)
+	if ntsd.DACL != nil {
+		for index := range ntsd.DACL.Entries {
+			candidates = append(candidates, &ntsd.DACL.Entries[index])
+		}
+	}
+	if ntsd.SACL != nil {
+		for index := range ntsd.SACL.Entries {
+			candidates = append(candidates, &ntsd.SACL.Entries[index])
+		}
+	}
+
+	rightsAccesses := rights.RIGHT_DS_CONTROL_ACCESS | rights.RIGHT_DS_WRITE_PROPERTY_EXTENDED | rights.RIGHT_DS_READ_PROPERTY | rights.RIGHT_DS_WRITE_PROPERTY
+	for _, entry := range candidates {
+		if !entry.AccessControlObjectType.Flags.IsObjectTypePresent() {
+			continue
+		}
+		if entry.Header.Flags.RawValue&aceflags.ACE_FLAG_INHERIT_ONLY != 0 {
+			continue
+		}
+		accessMask := entry.Mask.SpecificRights() & rightsAccesses
+		if accessMask == 0 {
+			continue
+		}
+		rightsGUID := entry.AccessControlObjectType.ObjectType.GUID.ToFormatD()
+		extendedRight, exists := registry.LookupExtendedRightForAccess(rightsGUID, accessMask)
+		if !exists || !extendedRight.IsValidFor(accessMask) {
+			// Not a controlAccessRight, e.g. the schemaIDGUID of an attribute
+			continue
+		}
+		if !registry.IsExtendedRightApplicable(rightsGUID, accessMask, schemaClass) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// SetRightsNamespace sets the rights namespace used to name the access mask bits of the ACEs of
+// the DACL and the SACL, e.g. rights.RIGHTS_NAMESPACE_REGISTRY_KEY for the security descriptor of
+// a registry key. The namespace is also used to pick composite rights in SDDL output.
+//
+// Parameters:
+//   - namespace (rights.RightsNamespace): The rights namespace of the object the security descriptor protects.
+func (ntsd *NtSecurityDescriptor) SetRightsNamespace(namespace rights.RightsNamespace) {
+	if ntsd.DACL != nil {
+		ntsd.DACL.SetRightsNamespace(namespace)
+	}
+	if ntsd.SACL != nil {
+		ntsd.SACL.SetRightsNamespace(namespace)
+	}
+}
+
+// MapGenericRights rewrites the ACEs of the DACL and the SACL into their specific-rights form,
+// replacing the generic rights with the rights they grant on the object type of the namespace of
+// each ACE's mask. Call SetRightsNamespace first for objects that are not directory service objects.
+func (ntsd *NtSecurityDescriptor) MapGenericRights() {
+	if ntsd.DACL != nil {
+		ntsd.DACL.MapGenericRights()
+	}
+	if ntsd.SACL != nil {
+		ntsd.SACL.MapGenericRights()
+	}
+}
+
+// EvaluateAccessRestrictions computes the maximum access that the process trust label and access
+// filter ACEs of the SACL leave to a caller, see SystemAccessControlList.EvaluateAccessRestrictions.
+//
+// Parameters:
+//   - processTrustSID (*sid.SID): The process trust label SID of the caller, or nil for an unprotected process.
+//   - context (*conditional.EvaluationContext): The security context used to evaluate the access filter conditions.
+//
+// Returns:
+//   - uint32: The mask of the access that is not restricted, 0xFFFFFFFF if nothing is restricted.
+//   - error: An error if the conditional expression of an access filter ACE is malformed.
+func (ntsd *NtSecurityDescriptor) EvaluateAccessRestrictions(processTrustSID *sid.SID, context *conditional.EvaluationContext) (uint32, error) {
+	if ntsd.SACL == nil {
+		return 0xFFFFFFFF, nil
+	}
+	return ntsd.SACL.EvaluateAccessRestrictions(processTrustSID, context)
+}
+
 // GetOwner returns the Owner field of the NtSecurityDescriptor.
 //
 // Returns:
@@ -387,3 +622,24 @@ func NewSecurityDescriptor() *NtSecurityDescriptor {
 
 	return ntsd
 }
+
+// Clone returns a deep copy of the security descriptor. The Owner, Group, DACL and SACL are
+// copied instead of shared, and no field references the buffer the descriptor was parsed
+// from, so that the copy can be modified or outlive that buffer without affecting the
+// original.
+//
+// Returns:
+//   - *NtSecurityDescriptor: The copy of the security descriptor, nil if it is nil.
+func (ntsd *NtSecurityDescriptor) Clone() *NtSecurityDescriptor {
+	if ntsd == nil {
+		return nil
+	}
+	clone := *ntsd
+	clone.Header = *ntsd.Header.Clone()
+	clone.Owner = ntsd.Owner.Clone()
+	clone.Group = ntsd.Group.Clone()
+	clone.DACL = ntsd.DACL.Clone()
+	clone.SACL = ntsd.SACL.Clone()
+	clone.RawBytes = bytes.Clone(ntsd.RawBytes)
+	return &clone
+}
@@ -5,8 +5,11 @@ import (
 
 	"github.com/TheManticoreProject/winacl/ace"
 	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/ace/conditional"
 	"github.com/TheManticoreProject/winacl/acl"
+	"github.com/TheManticoreProject/winacl/rights"
 	"github.com/TheManticoreProject/winacl/securitydescriptor"
+	"github.com/TheManticoreProject/winacl/sid"
 )
 
 func TestNtSecurityDescriptorDaclOperations(t *testing.T) {
@@ -98,3 +101,111 @@ func TestNtSecurityDescriptorEqual(t *testing.T) {
 		t.Error("Non-nil descriptor should not equal nil")
 	}
 }
+
+func TestNtSecurityDescriptorFindIdentitiesWithRight_RightsNamespace(t *testing.T) {
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	if _, err := ntsd.FromSDDLString("D:(A;;FR;;;BU)(A;;0x00000001;;;WD)(A;;FW;;;BA)"); err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+	ntsd.SetRightsNamespace(rights.RIGHTS_NAMESPACE_FILE)
+
+	// FILE_READ_DATA is covered by the FILE_GENERIC_READ composite right of the first ACE
+	found := map[string]bool{}
+	for id := range ntsd.FindIdentitiesWithRight(0x00000001) {
+		found[id.ToString()] = true
+	}
+	if len(found) != 2 || !found["S-1-5-32-545"] || !found["S-1-1-0"] {
+		t.Errorf("FindIdentitiesWithRight(FILE_READ_DATA) = %v, want S-1-5-32-545 and S-1-1-0", found)
+	}
+
+	found = map[string]bool{}
+	for id := range ntsd.FindIdentitiesWithAllRights([]uint32{0x00000001, 0x00020000}) {
+		found[id.ToString()] = true
+	}
+	if len(found) != 1 || !found["S-1-5-32-545"] {
+		t.Errorf("FindIdentitiesWithAllRights(FILE_READ_DATA, READ_CONTROL) = %v, want S-1-5-32-545", found)
+	}
+
+	if identities := ntsd.FindIdentitiesWithAnyRight([]uint32{0}); len(identities) != 0 {
+		t.Errorf("FindIdentitiesWithAnyRight(0) = %d identities, want none", len(identities))
+	}
+}
+
+func TestNtSecurityDescriptorMapGenericRights(t *testing.T) {
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	if _, err := ntsd.FromSDDLString("D:(A;;GA;;;S-1-5-21-1-2-3-1001)(A;;GR;;;WD)(ML;;NW;;;LW)"); err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+
+	// Analysis sees the rights granted by the generic bits before the ACEs are rewritten
+	found := map[string]bool{}
+	for id := range ntsd.FindIdentitiesWithAttributeRight(rights.RIGHT_DS_WRITE_PROPERTY, "userAccountControl") {
+		found[id.ToString()] = true
+	}
+	if !found["S-1-5-21-1-2-3-1001"] || found["S-1-1-0"] {
+		t.Errorf("FindIdentitiesWithAttributeRight() = %v, want only S-1-5-21-1-2-3-1001", found)
+	}
+
+	labelMask := ntsd.DACL.Entries[2].Mask.RawValue
+	ntsd.MapGenericRights()
+	expected := []uint32{0x000F01FF, 0x00020094, labelMask}
+	for index, entry := range ntsd.DACL.Entries {
+		if entry.Mask.RawValue != expected[index] {
+			t.Errorf("entry %d: RawValue = 0x%08x, want 0x%08x", index, entry.Mask.RawValue, expected[index])
+		}
+	}
+	if !ntsd.DACL.Entries[0].Mask.HasRight(rights.RIGHT_DS_CONTROL_ACCESS) {
+		t.Errorf("expected the mapped GENERIC_ALL ACE to grant DS_CONTROL_ACCESS, got %v", ntsd.DACL.Entries[0].Mask.Flags)
+	}
+
+	fileNtsd := &securitydescriptor.NtSecurityDescriptor{}
+	if _, err := fileNtsd.FromSDDLString("D:(A;;GRGX;;;BU)"); err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+	fileNtsd.SetRightsNamespace(rights.RIGHTS_NAMESPACE_FILE)
+	fileNtsd.MapGenericRights()
+	if fileNtsd.DACL.Entries[0].Mask.String() != "FILE_GENERIC_EXECUTE|FILE_GENERIC_READ" {
+		t.Errorf("Mask.String() = %q after mapping the generic file rights", fileNtsd.DACL.Entries[0].Mask.String())
+	}
+}
+
+func TestNtSecurityDescriptorEvaluateAccessRestrictions(t *testing.T) {
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	if _, err := ntsd.FromSDDLString("S:(TL;;CC;;;S-1-19-512-8192)(FL;;RC;;;WD;(@User.Clearance >= 3))"); err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+
+	parseSID := func(value string) *sid.SID {
+		s := &sid.SID{}
+		if err := s.FromString(value); err != nil {
+			t.Fatalf("FromString(%s) error = %v", value, err)
+		}
+		return s
+	}
+	cleared := &conditional.EvaluationContext{
+		UserAttributes: map[string]conditional.Value{"Clearance": conditional.IntegerValue(5)},
+	}
+
+	tests := []struct {
+		name            string
+		processTrustSID *sid.SID
+		context         *conditional.EvaluationContext
+		expected        uint32
+	}{
+		{"Untrusted process without claims", nil, nil, 0x00000000},
+		{"Dominating process without claims", parseSID("S-1-19-1024-8192"), nil, 0x00020000},
+		{"Dominated process with claims", parseSID("S-1-19-512-4096"), cleared, 0x00000001},
+		{"Dominating process with claims", parseSID("S-1-19-512-8192"), cleared, 0xFFFFFFFF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := ntsd.EvaluateAccessRestrictions(tt.processTrustSID, tt.context)
+			if err != nil {
+				t.Fatalf("EvaluateAccessRestrictions() error = %v", err)
+			}
+			if allowed != tt.expected {
+				t.Errorf("EvaluateAccessRestrictions() = 0x%08x, want 0x%08x", allowed, tt.expected)
+			}
+		})
+	}
+}
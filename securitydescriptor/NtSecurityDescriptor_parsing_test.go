@@ -0,0 +1,114 @@
+package securitydescriptor_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/parsing"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+)
+
+// lenientTestSDDL describes a descriptor with a SACL, a DACL of two entries, an owner and a
+// group, whose marshalled bytes are corrupted by the lenient parsing tests.
+const lenientTestSDDL = "O:BAG:SYD:(A;;FA;;;WD)(A;;FA;;;SY)S:(AU;SA;FA;;;WD)"
+
+// sddlTestDescriptor parses an SDDL string for the tests of the package and returns the
+// descriptor parsed back from its marshalled bytes, which RawBytes holds.
+func sddlTestDescriptor(t testing.TB, sddlString string) *securitydescriptor.NtSecurityDescriptor {
+	t.Helper()
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	if _, err := ntsd.FromSDDLString(sddlString); err != nil {
+		t.Fatalf("FromSDDLString(%q) error = %v", sddlString, err)
+	}
+	marshalledData, err := ntsd.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	parsed := &securitydescriptor.NtSecurityDescriptor{}
+	if _, err := parsed.Unmarshal(marshalledData); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	return parsed
+}
+
+func TestNtSecurityDescriptor_UnmarshalWithOptions_OffsetOutOfRange(t *testing.T) {
+	marshalledData := sddlTestDescriptor(t, lenientTestSDDL).RawBytes
+	binary.LittleEndian.PutUint32(marshalledData[12:16], uint32(len(marshalledData)+0x100))
+
+	strict := securitydescriptor.NtSecurityDescriptor{}
+	if _, err := strict.Unmarshal(marshalledData); err == nil {
+		t.Fatalf("Unmarshal() expected an error for a SACL offset past the end")
+	}
+
+	ntsd := securitydescriptor.NtSecurityDescriptor{}
+	_, anomalies, err := ntsd.UnmarshalWithOptions(marshalledData, parsing.Options{Lenient: true})
+	if err != nil {
+		t.Fatalf("UnmarshalWithOptions() error = %v", err)
+	}
+	if ntsd.SACL != nil || ntsd.DACL == nil || len(ntsd.DACL.Entries) != 2 || ntsd.Owner == nil || ntsd.Group == nil {
+		t.Errorf("UnmarshalWithOptions() did not keep the valid components")
+	}
+	if len(anomalies) != 1 || anomalies[0].Code != parsing.ANOMALY_CODE_OFFSET_OUT_OF_RANGE || anomalies[0].Path != "SACL" || anomalies[0].Offset != 12 {
+		t.Errorf("UnmarshalWithOptions() anomalies = %v", anomalies)
+	}
+}
+
+func TestNtSecurityDescriptor_UnmarshalWithOptions_AceCount(t *testing.T) {
+	marshalledData := sddlTestDescriptor(t, lenientTestSDDL).RawBytes
+	offsetDacl := binary.LittleEndian.Uint32(marshalledData[16:20])
+	binary.LittleEndian.PutUint16(marshalledData[offsetDacl+4:], 5)
+
+	strict := securitydescriptor.NtSecurityDescriptor{}
+	if _, _, err := strict.UnmarshalWithOptions(marshalledData, parsing.Options{}); err == nil {
+		t.Fatalf("UnmarshalWithOptions() expected an error in strict mode")
+	}
+
+	ntsd := securitydescriptor.NtSecurityDescriptor{}
+	_, anomalies, err := ntsd.UnmarshalWithOptions(marshalledData, parsing.Options{Lenient: true})
+	if err != nil {
+		t.Fatalf("UnmarshalWithOptions() error = %v", err)
+	}
+	if len(ntsd.DACL.Entries) != 2 || ntsd.DACL.Header.AceCount != 2 {
+		t.Errorf("DACL has %d entries and AceCount %d, want 2", len(ntsd.DACL.Entries), ntsd.DACL.Header.AceCount)
+	}
+	if len(anomalies) != 1 || anomalies[0].Code != parsing.ANOMALY_CODE_ACE_COUNT_MISMATCH || anomalies[0].Path != "DACL" {
+		t.Fatalf("UnmarshalWithOptions() anomalies = %v", anomalies)
+	}
+	if anomalies[0].Offset != int(offsetDacl)+int(ntsd.DACL.Header.AclSize) {
+		t.Errorf("Offset = %d, want the end of the DACL (%d)", anomalies[0].Offset, int(offsetDacl)+int(ntsd.DACL.Header.AclSize))
+	}
+
+	// The recovered descriptor is consistent again
+	binary.LittleEndian.PutUint16(marshalledData[offsetDacl+4:], 2)
+	serializedBytes, err := ntsd.Marshal()
+	if err != nil || !bytes.Equal(serializedBytes, marshalledData) {
+		t.Errorf("Marshal() = %x, %v, want %x", serializedBytes, err, marshalledData)
+	}
+}
+
+func TestNtSecurityDescriptor_UnmarshalWithOptions_OpaqueEntry(t *testing.T) {
+	marshalledData := sddlTestDescriptor(t, lenientTestSDDL).RawBytes
+	offsetDacl := binary.LittleEndian.Uint32(marshalledData[16:20])
+	// The SubAuthorityCount of the SID of the second entry, after the DACL header, the first
+	// entry, the ACE header, the mask and the SID revision
+	secondEntry := offsetDacl + 8 + uint32(binary.LittleEndian.Uint16(marshalledData[offsetDacl+10:]))
+	marshalledData[secondEntry+9] = 8
+
+	ntsd := securitydescriptor.NtSecurityDescriptor{}
+	_, anomalies, err := ntsd.UnmarshalWithOptions(marshalledData, parsing.Options{Lenient: true})
+	if err != nil {
+		t.Fatalf("UnmarshalWithOptions() error = %v", err)
+	}
+	if len(ntsd.DACL.Entries) != 2 || !ntsd.DACL.Entries[1].Opaque {
+		t.Errorf("second DACL entry is not opaque")
+	}
+	if len(anomalies) != 1 || anomalies[0].Code != parsing.ANOMALY_CODE_ACE_BODY_OPAQUE || anomalies[0].Path != "DACL.Entries[1]" || anomalies[0].Offset != int(secondEntry)+4 {
+		t.Errorf("UnmarshalWithOptions() anomalies = %v", anomalies)
+	}
+
+	serializedBytes, err := ntsd.Marshal()
+	if err != nil || !bytes.Equal(serializedBytes, marshalledData) {
+		t.Errorf("Marshal() = %x, %v, want %x", serializedBytes, err, marshalledData)
+	}
+}
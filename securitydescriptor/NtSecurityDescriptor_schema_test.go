@@ -0,0 +1,190 @@
+package securitydescriptor_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/rights"
+	"github.com/TheManticoreProject/winacl/schema"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+)
+
+// TestSchemaClasses_DefaultSecurityDescriptor verifies that every defaultSecurityDescriptor
+// shipped in the built-in class catalog is valid SDDL.
+func TestSchemaClasses_DefaultSecurityDescriptor(t *testing.T) {
+	for _, schemaClass := range schema.SchemaClasses {
+		if schemaClass.DefaultSecurityDescriptor == "" {
+			continue
+		}
+		ntsd := &securitydescriptor.NtSecurityDescriptor{}
+		if _, err := ntsd.FromSDDLString(schemaClass.DefaultSecurityDescriptor); err != nil {
+			t.Errorf("defaultSecurityDescriptor of %s: %v", schemaClass.LDAPDisplayName, err)
+		}
+	}
+}
+
+func TestFindIdentitiesWithChildObjectRight(t *testing.T) {
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	ou := schema.SchemaClasses[schema.SCHEMA_CLASS_ORGANIZATIONAL_UNIT]
+	if _, err := ntsd.FromSDDLString(ou.DefaultSecurityDescriptor); err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+
+	found := map[string]bool{}
+	for id := range ntsd.FindIdentitiesWithChildObjectRight(rights.RIGHT_DS_CREATE_CHILD, "computer") {
+		found[id.ToString()] = true
+	}
+	// Account Operators through the object ACE, Domain Admins and SYSTEM through full control.
+	for _, expected := range []string{"S-1-5-32-548", "S-1-5-21-0-0-0-512", "S-1-5-18"} {
+		if !found[expected] {
+			t.Errorf("expected %s to be able to create computer objects, got %v", expected, found)
+		}
+	}
+	// Print Operators may only create printQueue objects.
+	if found["S-1-5-32-550"] {
+		t.Errorf("Print Operators should not be able to create computer objects")
+	}
+
+	found = map[string]bool{}
+	for id := range ntsd.FindIdentitiesWithChildObjectRight(rights.RIGHT_DS_CREATE_CHILD, schema.SCHEMA_CLASS_PRINT_QUEUE) {
+		found[id.ToString()] = true
+	}
+	if !found["S-1-5-32-550"] {
+		t.Errorf("expected Print Operators to be able to create printQueue objects, got %v", found)
+	}
+}
+
+func TestFindIdentitiesWithAttributeRight(t *testing.T) {
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	_, err := ntsd.FromSDDLString(
+		"D:(OA;;WP;" + schema.PROPERTY_SET_ACCOUNT_RESTRICTIONS + ";;S-1-5-21-1-2-3-1001)" +
+			"(OA;;WP;" + schema.SCHEMA_ATTRIBUTE_USER_ACCOUNT_CONTROL + ";;S-1-5-21-1-2-3-1002)" +
+			"(OA;;WP;" + schema.SCHEMA_ATTRIBUTE_SERVICE_PRINCIPAL_NAME + ";;S-1-5-21-1-2-3-1003)" +
+			"(A;;RPWP;;;S-1-5-21-1-2-3-1004)" +
+			"(OA;;RP;" + schema.PROPERTY_SET_ACCOUNT_RESTRICTIONS + ";;S-1-5-21-1-2-3-1005)",
+	)
+	if err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+
+	found := map[string][]string{}
+	for id, grantedThrough := range ntsd.FindIdentitiesWithAttributeRight(rights.RIGHT_DS_WRITE_PROPERTY, "userAccountControl") {
+		found[id.ToString()] = grantedThrough
+	}
+	expected := map[string]string{
+		"S-1-5-21-1-2-3-1001": schema.PROPERTY_SET_ACCOUNT_RESTRICTIONS,
+		"S-1-5-21-1-2-3-1002": schema.SCHEMA_ATTRIBUTE_USER_ACCOUNT_CONTROL,
+		"S-1-5-21-1-2-3-1004": "",
+	}
+	if len(found) != len(expected) {
+		t.Errorf("FindIdentitiesWithAttributeRight() = %v, want %v", found, expected)
+	}
+	for id, grantedThrough := range expected {
+		if len(found[id]) != 1 || found[id][0] != grantedThrough {
+			t.Errorf("identity %s granted through %v, want %q", id, found[id], grantedThrough)
+		}
+	}
+}
+
+func TestFindIdentitiesWithAttributeRightInRegistry(t *testing.T) {
+	registry := schema.NewRegistry()
+	err := registry.AddAttribute(schema.SchemaAttribute{
+		Name:                  "ms-mcs-admpwd",
+		LDAPDisplayName:       "ms-Mcs-AdmPwd",
+		SchemaIDGUID:          "12345678-1234-5678-9abc-def012345678",
+		AttributeSecurityGUID: schema.PROPERTY_SET_ACCOUNT_RESTRICTIONS,
+	})
+	if err != nil {
+		t.Fatalf("AddAttribute() error = %v", err)
+	}
+
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	if _, err := ntsd.FromSDDLString("D:(OA;;RP;" + schema.PROPERTY_SET_ACCOUNT_RESTRICTIONS + ";;S-1-5-21-1-2-3-1001)"); err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+
+	if len(ntsd.FindIdentitiesWithAttributeRight(rights.RIGHT_DS_READ_PROPERTY, "ms-Mcs-AdmPwd")) != 0 {
+		t.Errorf("ms-Mcs-AdmPwd is unknown to the default registry and should only match by GUID")
+	}
+	if len(ntsd.FindIdentitiesWithAttributeRightInRegistry(registry, rights.RIGHT_DS_READ_PROPERTY, "ms-Mcs-AdmPwd")) != 1 {
+		t.Errorf("expected the property set ACE to grant read access to ms-Mcs-AdmPwd")
+	}
+}
+
+func TestFindEntriesWithInapplicableExtendedRights(t *testing.T) {
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	_, err := ntsd.FromSDDLString(
+		"D:(OA;;CR;" + rights.EXTENDED_RIGHT_USER_FORCE_CHANGE_PASSWORD + ";;S-1-5-21-1-2-3-1001)" +
+			"(OA;;CR;" + rights.EXTENDED_RIGHT_DS_REPLICATION_GET_CHANGES_ALL + ";;S-1-5-21-1-2-3-1002)" +
+			"(OA;;SW;" + rights.VALIDATED_WRITE_SELF_MEMBERSHIP + ";;S-1-5-21-1-2-3-1003)" +
+			"(OA;;WP;" + schema.SCHEMA_ATTRIBUTE_MEMBER + ";;S-1-5-21-1-2-3-1004)" +
+			"(OA;CIIO;CR;" + rights.EXTENDED_RIGHT_DS_REPLICATION_GET_CHANGES + ";;S-1-5-21-1-2-3-1005)" +
+			"(OA;;RP;" + schema.PROPERTY_SET_PERSONAL_INFORMATION + ";;S-1-5-21-1-2-3-1006)",
+	)
+	if err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+
+	found := map[string]bool{}
+	for _, entry := range ntsd.FindEntriesWithInapplicableExtendedRights("computer") {
+		found[entry.Identity.SID.ToString()] = true
+	}
+	// Replication rights only apply to naming context heads, and Self-Membership to groups.
+	// The WP ACE references the member attribute, not the Self-Membership validated write, and
+	// inherit-only ACEs do not apply to the object itself.
+	expected := map[string]bool{"S-1-5-21-1-2-3-1002": true, "S-1-5-21-1-2-3-1003": true}
+	if len(found) != len(expected) {
+		t.Errorf("FindEntriesWithInapplicableExtendedRights(computer) = %v, want %v", found, expected)
+	}
+	for sid := range expected {
+		if !found[sid] {
+			t.Errorf("expected the ACE of %s to be reported, got %v", sid, found)
+		}
+	}
+
+	if entries := ntsd.FindEntriesWithInapplicableExtendedRights("group"); len(entries) != 3 {
+		t.Errorf("FindEntriesWithInapplicableExtendedRights(group) returned %d entries, want 3", len(entries))
+	}
+}
+
+func TestToSDDLStringWithComments(t *testing.T) {
+	sddlString := "O:DAG:DAD:PAI(A;;RPLCLORC;;;AU)" +
+		"(OA;;CR;" + rights.EXTENDED_RIGHT_USER_FORCE_CHANGE_PASSWORD + ";;S-1-5-21-1-2-3-1001)" +
+		"(OA;CIIO;WP;" + schema.SCHEMA_ATTRIBUTE_MEMBER + ";" + schema.SCHEMA_CLASS_GROUP + ";S-1-5-21-1-2-3-1002)"
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	if _, err := ntsd.FromSDDLString(sddlString); err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+
+	commented, err := ntsd.ToSDDLStringWithComments()
+	if err != nil {
+		t.Fatalf("ToSDDLStringWithComments() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(commented, "\n"), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("ToSDDLStringWithComments() returned %d lines, want 6:\n%s", len(lines), commented)
+	}
+	if strings.Contains(lines[3], "#") {
+		t.Errorf("ACE without ObjectType should not be commented: %q", lines[3])
+	}
+	if !strings.HasSuffix(lines[4], "# ObjectType: Extended right User-Force-Change-Password (Reset Password)") {
+		t.Errorf("unexpected comment for the extended right ACE: %q", lines[4])
+	}
+	if !strings.HasSuffix(lines[5], "# ObjectType: Property member, InheritedObjectType: Class group") {
+		t.Errorf("unexpected comment for the property ACE: %q", lines[5])
+	}
+
+	// Removing the comments gives back the regular SDDL
+	var stripped strings.Builder
+	for _, line := range lines {
+		line, _, _ = strings.Cut(line, "#")
+		stripped.WriteString(strings.TrimSpace(line))
+	}
+	expected, err := ntsd.ToSDDLString()
+	if err != nil {
+		t.Fatalf("ToSDDLString() error = %v", err)
+	}
+	if stripped.String() != expected {
+		t.Errorf("stripped commented SDDL = %q, want %q", stripped.String(), expected)
+	}
+}
@@ -8,12 +8,14 @@ import (
 	ntsd_ace "github.com/TheManticoreProject/winacl/ace"
 	"github.com/TheManticoreProject/winacl/ace/aceflags"
 	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/ace/conditional"
 	"github.com/TheManticoreProject/winacl/acl"
 	"github.com/TheManticoreProject/winacl/acl/revision"
 	"github.com/TheManticoreProject/winacl/guid"
 	"github.com/TheManticoreProject/winacl/identity"
 	"github.com/TheManticoreProject/winacl/object"
 	"github.com/TheManticoreProject/winacl/object/flags"
+	ntsd_rights "github.com/TheManticoreProject/winacl/rights"
 	"github.com/TheManticoreProject/winacl/securitydescriptor/control"
 	"github.com/TheManticoreProject/winacl/sid"
 
@@ -31,7 +33,7 @@ import (
 // Returns:
 //   - (int, error): Always returns 0 for the int value, and an error if parsing fails.
 func (ntsd *NtSecurityDescriptor) FromSDDLString(sddlString string) (int, error) {
-	ownerStr, groupStr, daclFlags, daclAces, saclFlags, saclAces, err := cutSDDL(sddlString)
+	components, err := cutSDDL(sddlString)
 	if err != nil {
 		return 0, fmt.Errorf("failed to parse SDDL: %w", err)
 	}
@@ -39,31 +41,31 @@ func (ntsd *NtSecurityDescriptor) FromSDDLString(sddlString string) (int, error)
 	ntsd.Header.Revision = 1
 
 	// Parse owner
-	if ownerStr != "" {
-		ownerSID, err := sddlParseSID(ownerStr)
+	if components.owner != "" {
+		ownerSID, err := sddlParseSID(components.owner)
 		if err != nil {
-			return 0, fmt.Errorf("failed to parse owner SID '%s': %w", ownerStr, err)
+			return 0, fmt.Errorf("failed to parse owner SID '%s': %w", components.owner, err)
 		}
 		ntsd.Owner = &identity.Identity{SID: *ownerSID}
 		ntsd.Owner.Name = ownerSID.LookupName()
 	}
 
 	// Parse group
-	if groupStr != "" {
-		groupSID, err := sddlParseSID(groupStr)
+	if components.group != "" {
+		groupSID, err := sddlParseSID(components.group)
 		if err != nil {
-			return 0, fmt.Errorf("failed to parse group SID '%s': %w", groupStr, err)
+			return 0, fmt.Errorf("failed to parse group SID '%s': %w", components.group, err)
 		}
 		ntsd.Group = &identity.Identity{SID: *groupSID}
 		ntsd.Group.Name = groupSID.LookupName()
 	}
 
-	// Parse DACL
-	if len(daclAces) > 0 || daclFlags != "" {
+	// Parse DACL, "D:" alone being an empty DACL which denies all access
+	if components.daclPresent {
 		var entries []ntsd_ace.AccessControlEntry
-		if len(daclAces) > 0 {
+		if len(components.daclAces) > 0 {
 			var err error
-			entries, err = sddlParseACL(daclAces)
+			entries, err = sddlParseACL(components.daclAces)
 			if err != nil {
 				return 0, fmt.Errorf("failed to parse DACL: %w", err)
 			}
@@ -77,21 +79,21 @@ func (ntsd *NtSecurityDescriptor) FromSDDLString(sddlString string) (int, error)
 		ntsd.Header.Control.RawValue |= control.NT_SECURITY_DESCRIPTOR_CONTROL_DP
 
 		// Parse DACL flags
-		if daclFlags != "" {
-			controlBits, err := sddlParseACLFlags(daclFlags, true)
+		if components.daclFlags != "" {
+			controlBits, err := sddlParseACLFlags(components.daclFlags, true)
 			if err != nil {
-				return 0, fmt.Errorf("failed to parse DACL flags '%s': %w", daclFlags, err)
+				return 0, fmt.Errorf("failed to parse DACL flags '%s': %w", components.daclFlags, err)
 			}
 			ntsd.Header.Control.RawValue |= controlBits
 		}
 	}
 
 	// Parse SACL
-	if len(saclAces) > 0 || saclFlags != "" {
+	if components.saclPresent {
 		var entries []ntsd_ace.AccessControlEntry
-		if len(saclAces) > 0 {
+		if len(components.saclAces) > 0 {
 			var err error
-			entries, err = sddlParseACL(saclAces)
+			entries, err = sddlParseACL(components.saclAces)
 			if err != nil {
 				return 0, fmt.Errorf("failed to parse SACL: %w", err)
 			}
@@ -105,10 +107,10 @@ func (ntsd *NtSecurityDescriptor) FromSDDLString(sddlString string) (int, error)
 		ntsd.Header.Control.RawValue |= control.NT_SECURITY_DESCRIPTOR_CONTROL_SP
 
 		// Parse SACL flags
-		if saclFlags != "" {
-			controlBits, err := sddlParseACLFlags(saclFlags, false)
+		if components.saclFlags != "" {
+			controlBits, err := sddlParseACLFlags(components.saclFlags, false)
 			if err != nil {
-				return 0, fmt.Errorf("failed to parse SACL flags '%s': %w", saclFlags, err)
+				return 0, fmt.Errorf("failed to parse SACL flags '%s': %w", components.saclFlags, err)
 			}
 			ntsd.Header.Control.RawValue |= controlBits
 		}
@@ -172,51 +174,138 @@ func (ntsd *NtSecurityDescriptor) ToSDDLString() (string, error) {
 	return sb.String(), nil
 }
 
+// ToSDDLStringWithComments converts the NtSecurityDescriptor to a multi-line SDDL representation
+// meant for humans: each component and each ACE is on its own line, and object ACEs are followed by
+// a comment telling what their ObjectType and InheritedObjectType designate (e.g. "Extended right
+// User-Force-Change-Password" or "Property set User-Account-Restrictions"). Removing the comments
+// and line breaks gives back the output of ToSDDLString.
+//
+// Returns:
+//   - (string, error): The commented SDDL representation and any error that occurred.
+func (ntsd *NtSecurityDescriptor) ToSDDLStringWithComments() (string, error) {
+	var sb strings.Builder
+
+	if ntsd.Owner != nil {
+		sb.WriteString("O:" + sddlSIDToString(&ntsd.Owner.SID) + "\n")
+	}
+	if ntsd.Group != nil {
+		sb.WriteString("G:" + sddlSIDToString(&ntsd.Group.SID) + "\n")
+	}
+
+	writeACL := func(prefix string, isDACL bool, entries []ntsd_ace.AccessControlEntry) error {
+		sb.WriteString(prefix + sddlACLFlagsToString(ntsd.Header.Control.RawValue, isDACL) + "\n")
+		for _, entry := range entries {
+			aceStr, err := sddlACEToString(&entry)
+			if err != nil {
+				return err
+			}
+			sb.WriteString("(" + aceStr + ")")
+			if comment := sddlACEComment(&entry); comment != "" {
+				sb.WriteString("  # " + comment)
+			}
+			sb.WriteString("\n")
+		}
+		return nil
+	}
+
+	if ntsd.DACL != nil {
+		if err := writeACL("D:", true, ntsd.DACL.Entries); err != nil {
+			return "", fmt.Errorf("failed to convert DACL ACE to SDDL: %w", err)
+		}
+	}
+	if ntsd.SACL != nil {
+		if err := writeACL("S:", false, ntsd.SACL.Entries); err != nil {
+			return "", fmt.Errorf("failed to convert SACL ACE to SDDL: %w", err)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// sddlACEComment describes the ObjectType and InheritedObjectType of an object ACE, or returns
+// an empty string for ACEs without them.
+func sddlACEComment(ace *ntsd_ace.AccessControlEntry) string {
+	comments := make([]string, 0, 2)
+	if objectType := ace.InterpretObjectType(); objectType.Kind != ntsd_ace.OBJECT_TYPE_KIND_NONE {
+		comments = append(comments, "ObjectType: "+objectType.String())
+	}
+	if inheritedObjectType := ace.InterpretInheritedObjectType(); inheritedObjectType.Kind != ntsd_ace.OBJECT_TYPE_KIND_NONE {
+		comments = append(comments, "InheritedObjectType: "+inheritedObjectType.String())
+	}
+	return strings.Join(comments, ", ")
+}
+
+// sddlComponents holds the component parts of an SDDL string, see cutSDDL.
+type sddlComponents struct {
+	owner       string
+	group       string
+	daclPresent bool
+	daclFlags   string
+	daclAces    []string
+	saclPresent bool
+	saclFlags   string
+	saclAces    []string
+}
+
 // cutSDDL parses an SDDL string into its component parts.
 // This is a local copy to avoid circular imports with the sddl package.
-// Returns: owner, group, daclFlags, daclAces, saclFlags, saclAces, error
-func cutSDDL(sddlString string) (string, string, string, []string, string, []string, error) {
+// The DACL and SACL are present when their "D:" and "S:" markers are, even without flags or ACEs.
+func cutSDDL(sddlString string) (sddlComponents, error) {
 	sddlString = strings.TrimSpace(sddlString)
 	if len(sddlString) == 0 {
-		return "", "", "", nil, "", nil, nil
+		return sddlComponents{}, nil
 	}
 
-	components := map[string]string{
-		"O:": "",
-		"G:": "",
-		"D:": "",
-		"S:": "",
-	}
+	components := map[string]string{}
 
+	// The component markers are only looked for outside of the ACEs, so that the string
+	// literals of conditional expressions may hold "D:" or "S:"
 	currentComponent := ""
+	depth := 0
+	inLiteral := false
 	k := 0
 	for k < len(sddlString) {
 		upperChar := strings.ToUpper(string(sddlString[k]))
-		if k+1 < len(sddlString) && (upperChar == "O" || upperChar == "G" || upperChar == "D" || upperChar == "S") && sddlString[k+1] == ':' {
+		if depth == 0 && k+1 < len(sddlString) && (upperChar == "O" || upperChar == "G" || upperChar == "D" || upperChar == "S") && sddlString[k+1] == ':' {
 			currentComponent = upperChar + ":"
+			components[currentComponent] += ""
 			k += 2
 			continue
 		}
+		switch {
+		case sddlString[k] == '"':
+			inLiteral = !inLiteral
+		case inLiteral:
+		case sddlString[k] == '(':
+			depth++
+		case sddlString[k] == ')' && depth > 0:
+			depth--
+		}
 		if currentComponent != "" {
 			components[currentComponent] += string(sddlString[k])
 		}
 		k++
 	}
 
-	daclFlags, daclAces, err := cutAces(components["D:"])
+	parts := sddlComponents{owner: components["O:"], group: components["G:"]}
+	var err error
+	_, parts.daclPresent = components["D:"]
+	parts.daclFlags, parts.daclAces, err = cutAces(components["D:"])
 	if err != nil {
-		return "", "", "", nil, "", nil, fmt.Errorf("DACL: %w", err)
+		return sddlComponents{}, fmt.Errorf("DACL: %w", err)
 	}
-	saclFlags, saclAces, err := cutAces(components["S:"])
+	_, parts.saclPresent = components["S:"]
+	parts.saclFlags, parts.saclAces, err = cutAces(components["S:"])
 	if err != nil {
-		return "", "", "", nil, "", nil, fmt.Errorf("SACL: %w", err)
+		return sddlComponents{}, fmt.Errorf("SACL: %w", err)
 	}
 
-	return components["O:"], components["G:"], daclFlags, daclAces, saclFlags, saclAces, nil
+	return parts, nil
 }
 
 // cutAces extracts the ACL flags prefix and individual ACE strings from a DACL/SACL component.
 // Returns an error if parentheses are unbalanced, so malformed input cannot be silently accepted.
+// The parentheses inside the "..." string literals of conditional expressions are not counted.
 func cutAces(aclStr string) (string, []string, error) {
 	var aces []string
 
@@ -232,6 +321,12 @@ func cutAces(aclStr string) (string, []string, error) {
 	aceStart := start
 	for i := start; i < len(aclStr); i++ {
 		switch aclStr[i] {
+		case '"':
+			end := strings.IndexByte(aclStr[i+1:], '"')
+			if end == -1 {
+				return "", nil, fmt.Errorf("unterminated string literal at position %d", i)
+			}
+			i += end + 1
 		case '(':
 			if depth == 0 {
 				aceStart = i + 1
@@ -372,6 +467,21 @@ func sddlParseACE(aceStr string) (*ntsd_ace.AccessControlEntry, error) {
 		ace.Identity.Name = parsedSID.LookupName()
 	}
 
+	// Parse the conditional expression of callback and access filter ACEs. The expression may
+	// itself contain semicolons inside string literals.
+	if len(parts) > 6 && ace.IsConditional() {
+		conditionStr := strings.TrimSpace(strings.Join(parts[6:], ";"))
+		if conditionStr != "" {
+			condition := &conditional.ConditionalExpression{}
+			if _, err := condition.FromSDDLString(conditionStr); err != nil {
+				return nil, fmt.Errorf("failed to parse conditional expression '%s': %w", conditionStr, err)
+			}
+			if err := ace.SetCondition(condition); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	return ace, nil
 }
 
@@ -432,6 +542,14 @@ func sddlParseRights(s string) (uint32, error) {
 func sddlACEToString(ace *ntsd_ace.AccessControlEntry) (string, error) {
 	var parts [6]string
 
+	// The body of an opaque ACE could not be decoded, so its fields cannot be written
+	if ace.Opaque {
+		return "", fmt.Errorf("cannot convert ACE #%d to SDDL: its body of type 0x%02x is malformed and kept opaque", ace.Index, ace.Header.Type.Value)
+	}
+	if ace.Header.Type.Value == acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND {
+		return "", fmt.Errorf("cannot convert ACE #%d to SDDL: ACCESS_ALLOWED_COMPOUND ACEs have no SDDL representation", ace.Index)
+	}
+
 	// ACE type
 	typeStr, err := sddlACETypeToString(ace.Header.Type.Value)
 	if err != nil {
@@ -443,7 +561,7 @@ func sddlACEToString(ace *ntsd_ace.AccessControlEntry) (string, error) {
 	parts[1] = sddlACEFlagsToString(ace.Header.Flags.RawValue)
 
 	// Rights - pass ACE type for context-aware mapping
-	parts[2] = sddlRightsToString(ace.Mask.RawValue, ace.Header.Type.Value)
+	parts[2] = sddlRightsToString(ace.Mask.RawValue, ace.Header.Type.Value, ace.Mask.Namespace)
 
 	// Object GUID
 	if ace.AccessControlObjectType.Flags.Value&flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT != 0 {
@@ -460,6 +578,19 @@ func sddlACEToString(ace *ntsd_ace.AccessControlEntry) (string, error) {
 		parts[5] = sddlSIDToString(&ace.Identity.SID)
 	}
 
+	// Conditional expression of callback and access filter ACEs
+	condition, err := ace.Condition()
+	if err != nil {
+		return "", err
+	}
+	if condition != nil {
+		conditionStr, err := condition.ToSDDLString()
+		if err != nil {
+			return "", fmt.Errorf("failed to convert conditional expression: %w", err)
+		}
+		return strings.Join(parts[:], ";") + ";" + conditionStr, nil
+	}
+
 	return strings.Join(parts[:], ";"), nil
 }
 
@@ -502,15 +633,33 @@ func sddlACEFlagsToString(flagVal uint8) string {
 	return sb.String()
 }
 
+// namespaceCompositeRights lists the SDDL composite rights tried first for each rights namespace.
+var namespaceCompositeRights = map[ntsd_rights.RightsNamespace][]string{
+	ntsd_rights.RIGHTS_NAMESPACE_FILE:         {"FA", "FR", "FW", "FX"},
+	ntsd_rights.RIGHTS_NAMESPACE_DIRECTORY:    {"FA", "FR", "FW", "FX"},
+	ntsd_rights.RIGHTS_NAMESPACE_NAMED_PIPE:   {"FA", "FR", "FW", "FX"},
+	ntsd_rights.RIGHTS_NAMESPACE_REGISTRY_KEY: {"KA", "KR", "KW"},
+}
+
 // sddlRightsToString converts an access mask to its SDDL string.
 // The aceType parameter is used to disambiguate rights that share the same
 // bit value but have different SDDL abbreviations depending on context
-// (e.g. mandatory label rights NR/NW/NX vs DS rights CC/DC/LC).
-func sddlRightsToString(maskVal uint32, aceType uint8) string {
+// (e.g. mandatory label rights NR/NW/NX vs DS rights CC/DC/LC). The namespace
+// selects the composite rights tried first (FR/FW/FX for files, KR/KW for keys).
+func sddlRightsToString(maskVal uint32, aceType uint8, namespace ntsd_rights.RightsNamespace) string {
 	if maskVal == 0 {
 		return ""
 	}
 
+	// Composite rights of the namespace (exact match)
+	if aceType != acetype.ACE_TYPE_SYSTEM_MANDATORY_LABEL {
+		for _, token := range namespaceCompositeRights[namespace] {
+			if maskVal == sddl_rights.SDDLToRight[token] {
+				return token
+			}
+		}
+	}
+
 	// Try composite rights first (exact match)
 	compositeRights := []struct {
 		value uint32
@@ -1,10 +1,12 @@
 package securitydescriptor
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/TheManticoreProject/winacl/ace/acetype"
 	"github.com/TheManticoreProject/winacl/object/flags"
+	"github.com/TheManticoreProject/winacl/rights"
 	"github.com/TheManticoreProject/winacl/securitydescriptor/control"
 )
 
@@ -501,3 +503,124 @@ func TestSDDLtoNtSecurityDescriptor_via_sddl_package(t *testing.T) {
 		t.Error("Should have 1 DACL entry")
 	}
 }
+
+func TestToSDDLString_RightsNamespace(t *testing.T) {
+	ntsd := NtSecurityDescriptor{}
+	if _, err := ntsd.FromSDDLString("D:(A;;0x00120089;;;BU)(A;;0x00020019;;;WD)(ML;;NW;;;LW)"); err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+
+	ntsd.SetRightsNamespace(rights.RIGHTS_NAMESPACE_FILE)
+	if ntsd.DACL.Entries[0].Mask.String() != "FILE_GENERIC_READ" {
+		t.Errorf("Mask.String() = %q", ntsd.DACL.Entries[0].Mask.String())
+	}
+	if ntsd.DACL.Entries[2].Mask.Namespace != rights.RIGHTS_NAMESPACE_DIRECTORY_SERVICE {
+		t.Errorf("the namespace of a mandatory label ACE should not change")
+	}
+	sddlString, err := ntsd.ToSDDLString()
+	if err != nil {
+		t.Fatalf("ToSDDLString() error = %v", err)
+	}
+	if sddlString != "D:(A;;FR;;;BU)(A;;RCRPCCSW;;;WD)(ML;;NW;;;LW)" {
+		t.Errorf("ToSDDLString() = %q with the file namespace", sddlString)
+	}
+
+	ntsd.SetRightsNamespace(rights.RIGHTS_NAMESPACE_REGISTRY_KEY)
+	sddlString, err = ntsd.ToSDDLString()
+	if err != nil {
+		t.Fatalf("ToSDDLString() error = %v", err)
+	}
+	if !strings.Contains(sddlString, "(A;;KR;;;WD)") {
+		t.Errorf("ToSDDLString() = %q with the registry key namespace", sddlString)
+	}
+}
+
+func TestSDDL_TrustLabelAndConditionalACEs(t *testing.T) {
+	tests := []string{
+		"S:(TL;;CC;;;S-1-19-512-8192)(FL;;RC;;;WD;(@User.Clearance >= 3))",
+		"D:(XA;;FA;;;WD;(Member_of {SID(BA)}))",
+		`D:(ZA;;CR;00299570-246d-11d0-a768-00aa006e0529;;AU;((@User.Title == "PM;QA") && (Member_of_Any {SID(BA), SID(BU)})))`,
+		`D:(XA;;FA;;;WD;(@User.x == "a;b)"))`,
+		`D:(XA;;FA;;;WD;(@User.Department == "S:(D:)"))(A;;FA;;;BU)S:(AU;SA;FA;;;WD)`,
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			ntsd := NtSecurityDescriptor{}
+			if _, err := ntsd.FromSDDLString(input); err != nil {
+				t.Fatalf("FromSDDLString() error = %v", err)
+			}
+			output, err := ntsd.ToSDDLString()
+			if err != nil {
+				t.Fatalf("ToSDDLString() error = %v", err)
+			}
+			if output != input {
+				t.Errorf("ToSDDLString() = %s, want %s", output, input)
+			}
+
+			marshalledData, err := ntsd.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			decoded := NtSecurityDescriptor{}
+			if _, err := decoded.Unmarshal(marshalledData); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			output, err = decoded.ToSDDLString()
+			if err != nil {
+				t.Fatalf("ToSDDLString() after Unmarshal error = %v", err)
+			}
+			if output != input {
+				t.Errorf("ToSDDLString() after Unmarshal = %s, want %s", output, input)
+			}
+		})
+	}
+
+	ntsd := NtSecurityDescriptor{}
+	if _, err := ntsd.FromSDDLString("S:(FL;;0x1;;;WD;(@User.Clearance >=))"); err == nil {
+		t.Errorf("FromSDDLString() expected an error for a malformed condition")
+	}
+	if _, err := ntsd.FromSDDLString(`D:(XA;;FA;;;WD;(@User.x == "a))`); err == nil || !strings.Contains(err.Error(), "unterminated string literal") {
+		t.Errorf("FromSDDLString() error = %v, want an unterminated string literal", err)
+	}
+}
+
+func TestSDDL_AlarmACEs(t *testing.T) {
+	input := "S:(AL;SA;FA;;;WD)(OL;FA;CR;00299570-246d-11d0-a768-00aa006e0529;;AU)"
+
+	ntsd := NtSecurityDescriptor{}
+	if _, err := ntsd.FromSDDLString(input); err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+	marshalledData, err := ntsd.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	decoded := NtSecurityDescriptor{}
+	if _, err := decoded.Unmarshal(marshalledData); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	for _, entry := range decoded.SACL.Entries {
+		if entry.Opaque {
+			t.Errorf("entry %d: Opaque = true after a round-trip", entry.Index)
+		}
+	}
+	output, err := decoded.ToSDDLString()
+	if err != nil {
+		t.Fatalf("ToSDDLString() error = %v", err)
+	}
+	if output != input {
+		t.Errorf("ToSDDLString() after Unmarshal = %s, want %s", output, input)
+	}
+
+	// Opaque and compound ACEs have no SDDL representation
+	decoded.SACL.Entries[0].Opaque = true
+	if _, err := decoded.ToSDDLString(); err == nil {
+		t.Errorf("ToSDDLString() expected an error for an opaque ACE")
+	}
+	decoded.SACL.Entries[0].Opaque = false
+	decoded.SACL.Entries[0].Header.Type.Value = acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND
+	if _, err := decoded.ToSDDLString(); err == nil {
+		t.Errorf("ToSDDLString() expected an error for a compound ACE")
+	}
+}
@@ -0,0 +1,153 @@
+package securitydescriptor
+
+import (
+	"database/sql/driver"
+
+	"github.com/TheManticoreProject/winacl/internal/storage"
+)
+
+// Scan parses a security descriptor read from a database column, implementing sql.Scanner.
+// The binary representation, SDDL strings and JSON documents are all accepted, from []byte
+// or string values, so that the storage format of a column can be changed without migrating
+// its data. The binary representation is recognized by its revision byte, and the JSON
+// documents by their opening brace.
+//
+// Parameters:
+//   - src (any): The value read from the database column.
+//
+// Returns:
+//   - error: An error if the value is NULL or is not a valid security descriptor.
+func (ntsd *NtSecurityDescriptor) Scan(src any) error {
+	data, err := storage.ScanBytes(src, "securitydescriptor.NtSecurityDescriptor")
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(data) > 0 && data[0] == ntSecurityDescriptorRevision:
+		return ntsd.UnmarshalBinary(data)
+	case len(data) > 0 && data[0] == '{':
+		return ntsd.UnmarshalJSON(data)
+	default:
+		return ntsd.UnmarshalText(data)
+	}
+}
+
+// SQLBinary stores a security descriptor in a binary column as its self-relative binary
+// representation. NtSecurityDescriptor is not a driver.Valuer itself, since a value receiver
+// would copy the whole descriptor on each call, so the descriptors are passed to queries in
+// one of the SQLBinary, SQLText or SQLJSON wrappers. The format is chosen for each value, so
+// that columns of different formats can be used concurrently:
+//
+//	db.Exec("INSERT INTO objects (sd) VALUES (?)", securitydescriptor.SQLBinary{NtSecurityDescriptor: ntsd})
+//	row.Scan(&securitydescriptor.SQLBinary{NtSecurityDescriptor: ntsd})
+//
+// A nil security descriptor is stored as NULL, and NULL is scanned as a nil security
+// descriptor.
+type SQLBinary struct {
+	*NtSecurityDescriptor
+}
+
+// Value returns the self-relative binary representation of the security descriptor,
+// implementing driver.Valuer.
+//
+// Returns:
+//   - driver.Value: The binary representation as a []byte, or nil if the security descriptor is nil.
+//   - error: An error if the security descriptor cannot be serialized.
+func (blob SQLBinary) Value() (driver.Value, error) {
+	if blob.NtSecurityDescriptor == nil {
+		return nil, nil
+	}
+	return blob.NtSecurityDescriptor.MarshalBinary()
+}
+
+// Scan parses a security descriptor read from a database column into the security
+// descriptor, allocated if nil, implementing sql.Scanner. Every representation accepted by
+// NtSecurityDescriptor.Scan is accepted.
+//
+// Parameters:
+//   - src (any): The value read from the database column.
+//
+// Returns:
+//   - error: An error if the value is not a valid security descriptor.
+func (blob *SQLBinary) Scan(src any) error {
+	return scanNullable(&blob.NtSecurityDescriptor, src)
+}
+
+// SQLText stores a security descriptor in a text column as its SDDL string, see SQLBinary.
+// SDDL does not hold every detail of the binary form, see MarshalText, use SQLJSON for a
+// lossless text storage. A nil security descriptor is stored as NULL, and NULL is scanned as
+// a nil security descriptor.
+type SQLText struct {
+	*NtSecurityDescriptor
+}
+
+// Value returns the SDDL string of the security descriptor, implementing driver.Valuer.
+//
+// Returns:
+//   - driver.Value: The SDDL string as a string, or nil if the security descriptor is nil.
+//   - error: An error if the security descriptor cannot be written in SDDL.
+func (text SQLText) Value() (driver.Value, error) {
+	if text.NtSecurityDescriptor == nil {
+		return nil, nil
+	}
+	return text.NtSecurityDescriptor.ToSDDLString()
+}
+
+// Scan parses a security descriptor read from a database column into the security
+// descriptor, allocated if nil, implementing sql.Scanner. Every representation accepted by
+// NtSecurityDescriptor.Scan is accepted.
+//
+// Parameters:
+//   - src (any): The value read from the database column.
+//
+// Returns:
+//   - error: An error if the value is not a valid security descriptor.
+func (text *SQLText) Scan(src any) error {
+	return scanNullable(&text.NtSecurityDescriptor, src)
+}
+
+// SQLJSON stores a security descriptor in a JSON column as its JSON document of
+// JSON_SCHEMA_VERSION, see SQLBinary. A nil security descriptor is stored as NULL, and NULL is
+// scanned as a nil security descriptor.
+type SQLJSON struct {
+	*NtSecurityDescriptor
+}
+
+// Value returns the JSON document of the security descriptor, implementing driver.Valuer.
+//
+// Returns:
+//   - driver.Value: The JSON document as a string, or nil if the security descriptor is nil.
+//   - error: An error if the security descriptor cannot be serialized.
+func (document SQLJSON) Value() (driver.Value, error) {
+	if document.NtSecurityDescriptor == nil {
+		return nil, nil
+	}
+	data, err := document.NtSecurityDescriptor.MarshalJSON()
+	return string(data), err
+}
+
+// Scan parses a security descriptor read from a database column into the security
+// descriptor, allocated if nil, implementing sql.Scanner. Every representation accepted by
+// NtSecurityDescriptor.Scan is accepted.
+//
+// Parameters:
+//   - src (any): The value read from the database column.
+//
+// Returns:
+//   - error: An error if the value is not a valid security descriptor.
+func (document *SQLJSON) Scan(src any) error {
+	return scanNullable(&document.NtSecurityDescriptor, src)
+}
+
+// scanNullable scans a value into the security descriptor of a wrapper, setting it to nil for
+// NULL.
+func scanNullable(target **NtSecurityDescriptor, src any) error {
+	if src == nil {
+		*target = nil
+		return nil
+	}
+	if *target == nil {
+		*target = &NtSecurityDescriptor{}
+	}
+	return (*target).Scan(src)
+}
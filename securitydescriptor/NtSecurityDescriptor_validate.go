@@ -0,0 +1,84 @@
+package securitydescriptor
+
+import (
+	"github.com/TheManticoreProject/winacl/identity"
+	"github.com/TheManticoreProject/winacl/securitydescriptor/control"
+	"github.com/TheManticoreProject/winacl/validation"
+)
+
+// Validate checks the structure of the security descriptor and of its components, like the
+// IsValidSecurityDescriptor function of the Windows API, and reports the constructs that are
+// rejected by Windows or that are likely mistakes. It is meant to be run as a gate before a
+// security descriptor is written back, e.g. to the nTSecurityDescriptor attribute of an
+// Active Directory object.
+//
+// Returns:
+//   - validation.Findings: The problems found in the security descriptor, with paths like
+//     "DACL.Entries[2].Identity.SID".
+func (ntsd *NtSecurityDescriptor) Validate() validation.Findings {
+	findings := validation.Findings{}
+	sdControl := &ntsd.Header.Control
+
+	// Header
+	if ntsd.Header.Revision != 1 {
+		findings.Add(validation.SEVERITY_ERROR, validation.FINDING_CODE_SD_REVISION_INVALID, "Header.Revision", "security descriptor revision is %d, expected 1", ntsd.Header.Revision)
+	}
+	if ntsd.Header.Sbz1 != 0 && !sdControl.HasControl(control.NT_SECURITY_DESCRIPTOR_CONTROL_RM) {
+		findings.Add(validation.SEVERITY_WARNING, validation.FINDING_CODE_SD_SBZ1_NOT_ZERO, "Header.Sbz1", "Sbz1 is 0x%02x but RM_CONTROL_VALID is not set", ntsd.Header.Sbz1)
+	}
+	if !sdControl.HasControl(control.NT_SECURITY_DESCRIPTOR_CONTROL_SR) {
+		findings.Add(validation.SEVERITY_WARNING, validation.FINDING_CODE_SD_NOT_SELF_RELATIVE, "Header.Control", "SELF_RELATIVE is not set, but the security descriptor is marshalled in self-relative format")
+	}
+
+	// Owner and group
+	if isIdentityMissing(ntsd.Owner) {
+		findings.Add(validation.SEVERITY_WARNING, validation.FINDING_CODE_SD_OWNER_MISSING, "Owner", "security descriptor has no owner")
+	} else {
+		findings.Extend("Owner.SID", validation.ValidateSID(&ntsd.Owner.SID))
+	}
+	if isIdentityMissing(ntsd.Group) {
+		findings.Add(validation.SEVERITY_INFO, validation.FINDING_CODE_SD_GROUP_MISSING, "Group", "security descriptor has no primary group")
+	} else {
+		findings.Extend("Group.SID", validation.ValidateSID(&ntsd.Group.SID))
+	}
+
+	// DACL
+	daclPresent := sdControl.HasControl(control.NT_SECURITY_DESCRIPTOR_CONTROL_DP)
+	if ntsd.DACL != nil && len(ntsd.DACL.Entries) > 0 {
+		if !daclPresent {
+			findings.Add(validation.SEVERITY_ERROR, validation.FINDING_CODE_SD_DACL_PRESENT_NOT_SET, "Header.Control", "DACL has entries but DACL_PRESENT is not set, the DACL is ignored")
+		}
+		findings.Extend("DACL", ntsd.DACL.Validate())
+	} else if daclPresent {
+		if ntsd.DACL == nil {
+			findings.Add(validation.SEVERITY_WARNING, validation.FINDING_CODE_SD_NULL_DACL, "DACL", "DACL_PRESENT is set with a NULL DACL, which grants full access to everyone")
+		} else {
+			findings.Add(validation.SEVERITY_INFO, validation.FINDING_CODE_SD_EMPTY_DACL, "DACL", "DACL is empty and denies all access")
+		}
+	}
+
+	// SACL
+	saclPresent := sdControl.HasControl(control.NT_SECURITY_DESCRIPTOR_CONTROL_SP)
+	if ntsd.SACL != nil && len(ntsd.SACL.Entries) > 0 {
+		if !saclPresent {
+			findings.Add(validation.SEVERITY_ERROR, validation.FINDING_CODE_SD_SACL_PRESENT_NOT_SET, "Header.Control", "SACL has entries but SACL_PRESENT is not set, the SACL is ignored")
+		}
+		findings.Extend("SACL", ntsd.SACL.Validate())
+	} else if saclPresent {
+		findings.Add(validation.SEVERITY_INFO, validation.FINDING_CODE_SD_NULL_SACL, "SACL", "SACL_PRESENT is set without SACL entries")
+	}
+
+	return findings
+}
+
+// isIdentityMissing checks if an owner or group identity is absent. Like Marshal, it treats
+// an identity whose SID has a RevisionLevel of 0 as absent.
+//
+// Parameters:
+//   - id (*identity.Identity): The identity.
+//
+// Returns:
+//   - bool: true if the identity is absent, false otherwise.
+func isIdentityMissing(id *identity.Identity) bool {
+	return id == nil || id.SID.RevisionLevel == 0
+}
@@ -0,0 +1,109 @@
+package securitydescriptor_test
+
+import (
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/acl"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+	"github.com/TheManticoreProject/winacl/securitydescriptor/control"
+	"github.com/TheManticoreProject/winacl/validation"
+)
+
+func TestNtSecurityDescriptor_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		sddl     string
+		expected []validation.FindingCode
+	}{
+		{"valid", "O:BAG:SYD:(D;;FA;;;AN)(A;;FA;;;WD)(A;ID;FA;;;SY)S:(AU;SA;FA;;;WD)", nil},
+		{"owner_missing", "G:SYD:(A;;FA;;;WD)", []validation.FindingCode{validation.FINDING_CODE_SD_OWNER_MISSING}},
+		{"group_missing", "O:BAD:(A;;FA;;;WD)", []validation.FindingCode{validation.FINDING_CODE_SD_GROUP_MISSING}},
+		{"audit_in_dacl", "O:BAG:SYD:(AU;SA;FA;;;WD)", []validation.FindingCode{validation.FINDING_CODE_ACL_ACE_TYPE_MISPLACED}},
+		{"allow_in_sacl", "O:BAG:SYS:(A;;FA;;;WD)", []validation.FindingCode{validation.FINDING_CODE_ACL_ACE_TYPE_MISPLACED}},
+		{"not_canonical", "O:BAG:SYD:(A;;FA;;;WD)(D;;FA;;;AN)", []validation.FindingCode{validation.FINDING_CODE_ACL_NOT_CANONICAL}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ntsd := securitydescriptor.NtSecurityDescriptor{}
+			if _, err := ntsd.FromSDDLString(tt.sddl); err != nil {
+				t.Fatalf("FromSDDLString() error = %v", err)
+			}
+
+			findings := ntsd.Validate()
+			if len(findings) != len(tt.expected) {
+				t.Fatalf("Validate() = %v, want %v", findings, tt.expected)
+			}
+			for i, code := range tt.expected {
+				if findings[i].Code != code {
+					t.Errorf("Validate()[%d].Code = %s, want %s", i, findings[i].Code, code)
+				}
+			}
+		})
+	}
+}
+
+func TestNtSecurityDescriptor_Validate_Structure(t *testing.T) {
+	ntsd := securitydescriptor.NtSecurityDescriptor{}
+	if _, err := ntsd.FromSDDLString("O:BAG:SYD:(OA;;CR;00299570-246d-11d0-a768-00aa006e0529;;AU)"); err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+	ntsd.DACL.Header.Revision.Value = 2
+	ntsd.Header.Sbz1 = 1
+	ntsd.Owner.SID.SubAuthorityCount = 16
+	ntsd.Owner.SID.SubAuthorities = make([]uint32, 15)
+
+	findings := ntsd.Validate()
+	expected := []struct {
+		code     validation.FindingCode
+		path     string
+		severity validation.Severity
+	}{
+		{validation.FINDING_CODE_SD_SBZ1_NOT_ZERO, "Header.Sbz1", validation.SEVERITY_WARNING},
+		{validation.FINDING_CODE_SID_TOO_MANY_SUBAUTHORITIES, "Owner.SID", validation.SEVERITY_ERROR},
+		{validation.FINDING_CODE_ACL_REVISION_TOO_LOW, "DACL.Entries[0]", validation.SEVERITY_ERROR},
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Validate() = %v, want %d findings", findings, len(expected))
+	}
+	for i, want := range expected {
+		if findings[i].Code != want.code || findings[i].Path != want.path || findings[i].Severity != want.severity {
+			t.Errorf("Validate()[%d] = %v, want %s %s %s", i, findings[i], want.severity, want.path, want.code)
+		}
+	}
+	if !findings.HasErrors() {
+		t.Errorf("HasErrors() = false")
+	}
+}
+
+func TestNtSecurityDescriptor_Validate_Control(t *testing.T) {
+	ntsd := securitydescriptor.NtSecurityDescriptor{}
+	if _, err := ntsd.FromSDDLString("O:BAG:SYD:(A;;FA;;;WD)S:(AU;SA;FA;;;WD)"); err != nil {
+		t.Fatalf("FromSDDLString() error = %v", err)
+	}
+
+	// Entries without the present flags are ignored by Windows
+	ntsd.Header.Control.RawValue &^= control.NT_SECURITY_DESCRIPTOR_CONTROL_DP | control.NT_SECURITY_DESCRIPTOR_CONTROL_SP | control.NT_SECURITY_DESCRIPTOR_CONTROL_SR
+	findings := ntsd.Validate()
+	for _, code := range []validation.FindingCode{validation.FINDING_CODE_SD_NOT_SELF_RELATIVE, validation.FINDING_CODE_SD_DACL_PRESENT_NOT_SET, validation.FINDING_CODE_SD_SACL_PRESENT_NOT_SET} {
+		if len(findings.WithCode(code)) != 1 {
+			t.Errorf("Validate() = %v, want %s", findings, code)
+		}
+	}
+
+	// DP set with a NULL DACL
+	ntsd.Header.Control.RawValue |= control.NT_SECURITY_DESCRIPTOR_CONTROL_DP | control.NT_SECURITY_DESCRIPTOR_CONTROL_SR
+	ntsd.DACL = nil
+	ntsd.SACL = nil
+	findings = ntsd.Validate()
+	if got := findings.WithCode(validation.FINDING_CODE_SD_NULL_DACL); len(got) != 1 || got[0].Severity != validation.SEVERITY_WARNING {
+		t.Errorf("Validate() = %v, want SD_NULL_DACL", findings)
+	}
+
+	// An empty DACL denies all access
+	ntsd.DACL = &acl.DiscretionaryAccessControlList{}
+	findings = ntsd.Validate()
+	if got := findings.WithCode(validation.FINDING_CODE_SD_EMPTY_DACL); len(got) != 1 || got[0].Severity != validation.SEVERITY_INFO {
+		t.Errorf("Validate() = %v, want SD_EMPTY_DACL", findings)
+	}
+}
@@ -2,7 +2,8 @@ package control
 
 import (
 	"encoding/binary"
-	"fmt"
+
+	"github.com/TheManticoreProject/winacl/parsing"
 )
 
 // NtSecurityDescriptorControl represents the control flags for a NT Security Descriptor.
@@ -86,7 +87,7 @@ var NtSecurityDescriptorControlValueToShortName = map[uint16]string{
 //   - rawValue (uint16): The raw value to be parsed, representing the control flags as a bitmask.
 func (nsdc *NtSecurityDescriptorControl) Unmarshal(rawValue []byte) (int, error) {
 	if len(rawValue) < 2 {
-		return 0, fmt.Errorf("NtSecurityDescriptorControl unmarshal requires at least 2 bytes, got %d", len(rawValue))
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "NtSecurityDescriptorControl unmarshal requires at least 2 bytes, got %d", len(rawValue))
 	}
 	nsdc.RawValue = binary.LittleEndian.Uint16(rawValue)
 	nsdc.Values = []uint16{}
@@ -107,7 +108,25 @@ func (nsdc *NtSecurityDescriptorControl) Unmarshal(rawValue []byte) (int, error)
 // Returns:
 //   - []byte: The serialized byte slice representing the security descriptor control.
 func (nsdc *NtSecurityDescriptorControl) Marshal() ([]byte, error) {
-	marshalledData := make([]byte, 2)
-	binary.LittleEndian.PutUint16(marshalledData, nsdc.RawValue)
-	return marshalledData, nil
+	return nsdc.AppendBinary(make([]byte, 0, nsdc.Size()))
+}
+
+// Size returns the size of the binary representation of the NtSecurityDescriptorControl.
+//
+// Returns:
+//   - int: Always 2.
+func (nsdc *NtSecurityDescriptorControl) Size() int {
+	return 2
+}
+
+// AppendBinary appends the binary representation of the NtSecurityDescriptorControl to a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: Always nil.
+func (nsdc *NtSecurityDescriptorControl) AppendBinary(buffer []byte) ([]byte, error) {
+	return binary.LittleEndian.AppendUint16(buffer, nsdc.RawValue), nil
 }
@@ -1,5 +1,7 @@
 package control
 
+import "slices"
+
 // HasControl checks if a specific control bit is set in the RawValue.
 // Parameters:
 //   - control (uint16): The control flag to check (NT_SECURITY_DESCRIPTOR_CONTROL_*).
@@ -109,3 +111,17 @@ func (nsdc *NtSecurityDescriptorControl) Equal(other *NtSecurityDescriptorContro
 
 	return true
 }
+
+// Clone returns a deep copy of the NtSecurityDescriptorControl, sharing no memory with the original.
+//
+// Returns:
+//   - *NtSecurityDescriptorControl: The copy of the control, nil if the control is nil.
+func (nsdc *NtSecurityDescriptorControl) Clone() *NtSecurityDescriptorControl {
+	if nsdc == nil {
+		return nil
+	}
+	clone := *nsdc
+	clone.Values = slices.Clone(nsdc.Values)
+	clone.Flags = slices.Clone(nsdc.Flags)
+	return &clone
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/TheManticoreProject/winacl/parsing"
 	"github.com/TheManticoreProject/winacl/securitydescriptor/control"
 )
 
@@ -48,7 +49,7 @@ type NtSecurityDescriptorHeader struct {
 func (ntsd *NtSecurityDescriptorHeader) Unmarshal(marshalledData []byte) (int, error) {
 	// Parsing header
 	if len(marshalledData) < 20 {
-		return 0, fmt.Errorf("invalid raw bytes length")
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "invalid raw bytes length")
 	}
 
 	ntsd.RawBytes = marshalledData[:20]
@@ -63,7 +64,7 @@ func (ntsd *NtSecurityDescriptorHeader) Unmarshal(marshalledData []byte) (int, e
 	ntsd.Control = control.NtSecurityDescriptorControl{}
 	bytesRead, err := ntsd.Control.Unmarshal(marshalledData[2:4])
 	if err != nil {
-		return 0, fmt.Errorf("failed to unmarshal Control: %w", err)
+		return 0, parsing.WrapParseError(err, 2, "Control", "failed to unmarshal Control")
 	}
 	ntsd.RawBytesSize += uint32(bytesRead)
 
@@ -87,32 +88,54 @@ func (ntsd *NtSecurityDescriptorHeader) Unmarshal(marshalledData []byte) (int, e
 // Returns:
 //   - []byte: The serialized byte slice representing the security descriptor header.
 func (ntsdh *NtSecurityDescriptorHeader) Marshal() ([]byte, error) {
-	marshalledData := []byte{}
-
-	marshalledData = append(marshalledData, ntsdh.Revision)
-
-	marshalledData = append(marshalledData, ntsdh.Sbz1)
-
-	bytesStream, err := ntsdh.Control.Marshal()
+	marshalledData, err := ntsdh.AppendBinary(make([]byte, 0, ntsdh.Size()))
 	if err != nil {
 		return nil, err
 	}
-	marshalledData = append(marshalledData, bytesStream...)
+	ntsdh.RefreshRawBytes(marshalledData)
+	return marshalledData, nil
+}
 
-	buffer := make([]byte, 4)
-	binary.LittleEndian.PutUint32(buffer, ntsdh.OffsetOwner)
-	marshalledData = append(marshalledData, buffer...)
+// RefreshRawBytes points RawBytes and RawBytesSize at the 20 marshalled bytes of the header.
+//
+// Parameters:
+//   - marshalledData ([]byte): The bytes produced by AppendBinary for the header.
+func (ntsdh *NtSecurityDescriptorHeader) RefreshRawBytes(marshalledData []byte) {
+	ntsdh.RawBytesSize = uint32(ntsdh.Size())
+	ntsdh.RawBytes = marshalledData[:ntsdh.RawBytesSize]
+}
+
+// Size returns the size of the binary representation of the security descriptor header.
+//
+// Returns:
+//   - int: Always 20.
+func (ntsdh *NtSecurityDescriptorHeader) Size() int {
+	return 20
+}
 
-	binary.LittleEndian.PutUint32(buffer, ntsdh.OffsetGroup)
-	marshalledData = append(marshalledData, buffer...)
+// AppendBinary appends the binary representation of the security descriptor header to a
+// buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: An error if the control flags cannot be serialized.
+func (ntsdh *NtSecurityDescriptorHeader) AppendBinary(buffer []byte) ([]byte, error) {
+	buffer = append(buffer, ntsdh.Revision, ntsdh.Sbz1)
 
-	binary.LittleEndian.PutUint32(buffer, ntsdh.OffsetSacl)
-	marshalledData = append(marshalledData, buffer...)
+	buffer, err := ntsdh.Control.AppendBinary(buffer)
+	if err != nil {
+		return nil, err
+	}
 
-	binary.LittleEndian.PutUint32(buffer, ntsdh.OffsetDacl)
-	marshalledData = append(marshalledData, buffer...)
+	buffer = binary.LittleEndian.AppendUint32(buffer, ntsdh.OffsetOwner)
+	buffer = binary.LittleEndian.AppendUint32(buffer, ntsdh.OffsetGroup)
+	buffer = binary.LittleEndian.AppendUint32(buffer, ntsdh.OffsetSacl)
+	buffer = binary.LittleEndian.AppendUint32(buffer, ntsdh.OffsetDacl)
 
-	return marshalledData, nil
+	return buffer, nil
 }
 
 // Describe prints a detailed description of the NtSecurityDescriptorHeader struct,
@@ -1,5 +1,7 @@
 package header
 
+import "bytes"
+
 // GetRevision returns the Revision field value.
 func (ntsd *NtSecurityDescriptorHeader) GetRevision() uint8 {
 	return ntsd.Revision
@@ -62,3 +64,17 @@ func (ntsdh *NtSecurityDescriptorHeader) Equal(other *NtSecurityDescriptorHeader
 
 	return true
 }
+
+// Clone returns a deep copy of the NtSecurityDescriptorHeader, sharing no memory with the original.
+//
+// Returns:
+//   - *NtSecurityDescriptorHeader: The copy of the header, nil if the header is nil.
+func (ntsd *NtSecurityDescriptorHeader) Clone() *NtSecurityDescriptorHeader {
+	if ntsd == nil {
+		return nil
+	}
+	clone := *ntsd
+	clone.Control = *ntsd.Control.Clone()
+	clone.RawBytes = bytes.Clone(ntsd.RawBytes)
+	return &clone
+}
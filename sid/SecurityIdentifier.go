@@ -3,9 +3,11 @@ package sid
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 
+	"github.com/TheManticoreProject/winacl/parsing"
 	"github.com/TheManticoreProject/winacl/sid/authority"
 )
 
@@ -50,6 +52,19 @@ const (
 	WELLKNOWNSID_SECURITY_MANDATORY_LABEL_SYSTEM_INTEGRITY_LEVEL      = "S-1-16-16384"
 	WELLKNOWNSID_SECURITY_MANDATORY_LABEL_PROTECTED_PROCESS           = "S-1-16-20480"
 	WELLKNOWNSID_SECURITY_MANDATORY_LABEL_SECURE_PROCESS              = "S-1-16-28672"
+	// Process Trust Label (S-1-19-<protection type>-<protection level>)
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_LIGHT_NONE         = "S-1-19-512-0"
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_LIGHT_AUTHENTICODE = "S-1-19-512-1024"
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_LIGHT_ANTIMALWARE  = "S-1-19-512-1536"
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_LIGHT_APP          = "S-1-19-512-2048"
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_LIGHT_WINDOWS      = "S-1-19-512-4096"
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_LIGHT_WINTCB       = "S-1-19-512-8192"
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_NONE               = "S-1-19-1024-0"
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_AUTHENTICODE       = "S-1-19-1024-1024"
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_ANTIMALWARE        = "S-1-19-1024-1536"
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_APP                = "S-1-19-1024-2048"
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_WINDOWS            = "S-1-19-1024-4096"
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_WINTCB             = "S-1-19-1024-8192"
 	//
 	WELLKNOWNSID_DOMAIN_ADMINISTRATOR_ACCOUNT        = "S-1-5-21-0-0-0-500"
 	WELLKNOWNSID_DOMAIN_GUEST_ACCOUNT                = "S-1-5-21-0-0-0-501"
@@ -174,6 +189,20 @@ var WellKnownSIDs = map[string]string{
 	WELLKNOWNSID_SECURITY_MANDATORY_LABEL_PROTECTED_PROCESS:           "Protected Process",
 	WELLKNOWNSID_SECURITY_MANDATORY_LABEL_SECURE_PROCESS:              "Secure Process",
 
+	// Process Trust Label
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_LIGHT_NONE:         "PROCESS TRUST LABEL\\ProtectedLight-None",
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_LIGHT_AUTHENTICODE: "PROCESS TRUST LABEL\\ProtectedLight-Authenticode",
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_LIGHT_ANTIMALWARE:  "PROCESS TRUST LABEL\\ProtectedLight-Antimalware",
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_LIGHT_APP:          "PROCESS TRUST LABEL\\ProtectedLight-App",
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_LIGHT_WINDOWS:      "PROCESS TRUST LABEL\\ProtectedLight-Windows",
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_LIGHT_WINTCB:       "PROCESS TRUST LABEL\\ProtectedLight-WinTcb",
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_NONE:               "PROCESS TRUST LABEL\\Protected-None",
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_AUTHENTICODE:       "PROCESS TRUST LABEL\\Protected-Authenticode",
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_ANTIMALWARE:        "PROCESS TRUST LABEL\\Protected-Antimalware",
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_APP:                "PROCESS TRUST LABEL\\Protected-App",
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_WINDOWS:            "PROCESS TRUST LABEL\\Protected-Windows",
+	WELLKNOWNSID_PROCESS_TRUST_LABEL_PROTECTED_WINTCB:             "PROCESS TRUST LABEL\\Protected-WinTcb",
+
 	// Special identity groups
 	WELLKNOWNSID_DOMAIN_ADMINISTRATOR_ACCOUNT:        "Administrator Account",
 	WELLKNOWNSID_DOMAIN_GUEST_ACCOUNT:                "Guest Account",
@@ -271,7 +300,7 @@ func (sid *SID) Unmarshal(marshalledData []byte) (int, error) {
 		sid.RevisionLevel = uint8(marshalledData[0])
 		sid.RawBytesSize += 1
 	} else {
-		return 0, fmt.Errorf("not enough data to parse RevisionLevel")
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "not enough data to parse RevisionLevel")
 	}
 
 	// Parse the SubAuthorityCount
@@ -279,7 +308,7 @@ func (sid *SID) Unmarshal(marshalledData []byte) (int, error) {
 		sid.SubAuthorityCount = uint8(marshalledData[1])
 		sid.RawBytesSize += 1
 	} else {
-		return 0, fmt.Errorf("not enough data to parse SubAuthorityCount")
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 1, "", "not enough data to parse SubAuthorityCount")
 	}
 
 	// Parse the IdentifierAuthority
@@ -287,7 +316,7 @@ func (sid *SID) Unmarshal(marshalledData []byte) (int, error) {
 		sid.IdentifierAuthority.Unmarshal(marshalledData[2:8])
 		sid.RawBytesSize += 6
 	} else {
-		return 0, fmt.Errorf("not enough data to parse IdentifierAuthority")
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 2, "", "not enough data to parse IdentifierAuthority")
 	}
 
 	// Initialize the sub-authorities
@@ -303,7 +332,7 @@ func (sid *SID) Unmarshal(marshalledData []byte) (int, error) {
 				sid.SubAuthorities[i] = binary.LittleEndian.Uint32(marshalledData[sid.RawBytesSize : sid.RawBytesSize+4])
 				sid.RawBytesSize += 4
 			} else {
-				return 0, fmt.Errorf("not enough data to parse SubAuthority %d", i)
+				return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, int(sid.RawBytesSize), "", "not enough data to parse SubAuthority %d", i)
 			}
 		}
 	}
@@ -318,52 +347,109 @@ func (sid *SID) Unmarshal(marshalledData []byte) (int, error) {
 			sid.RelativeIdentifier = binary.LittleEndian.Uint32(marshalledData[sid.RawBytesSize : sid.RawBytesSize+4])
 			sid.RawBytesSize += 4
 		} else {
-			return 0, fmt.Errorf("not enough data to parse RelativeIdentifier")
+			return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, int(sid.RawBytesSize), "", "not enough data to parse RelativeIdentifier")
 		}
 	}
 
+	sid.RawBytes = marshalledData[:sid.RawBytesSize]
+
 	return int(sid.RawBytesSize), nil
 }
 
 // Marshal converts the current SID struct into its binary representation as a byte slice,
-// suitable for storage or transmission.
+// suitable for storage or transmission. RawBytes and RawBytesSize are refreshed to the
+// returned bytes, see RefreshRawBytes.
 //
 // Returns:
 //   - []byte: A byte slice representing the SID in binary format, constructed from its fields.
 func (sid *SID) Marshal() ([]byte, error) {
-	// Create a byte slice to hold the result
-	marshalledData := make([]byte, 0)
+	marshalledData, err := sid.AppendBinary(make([]byte, 0, sid.Size()))
+	if err != nil {
+		return nil, err
+	}
+	sid.RefreshRawBytes(marshalledData)
+	return marshalledData, nil
+}
 
-	// Add the RevisionLevel
-	marshalledData = append(marshalledData, sid.RevisionLevel)
+// RefreshRawBytes points RawBytes and RawBytesSize at the marshalled bytes of the SID, so
+// that they reflect its fields after a modification.
+//
+// Parameters:
+//   - marshalledData ([]byte): The bytes produced by AppendBinary for the SID, at least Size() bytes long.
+func (sid *SID) RefreshRawBytes(marshalledData []byte) {
+	sid.RawBytesSize = uint32(sid.Size())
+	sid.RawBytes = marshalledData[:sid.RawBytesSize]
+}
 
-	// Add the SubAuthorityCount
-	marshalledData = append(marshalledData, sid.SubAuthorityCount)
+// hasRelativeIdentifier checks if the RID is serialized after the sub-authorities, which is
+// the case whenever SubAuthorityCount counts one more value than SubAuthorities holds.
+func (sid *SID) hasRelativeIdentifier() bool {
+	return uint8(len(sid.SubAuthorities)) < sid.SubAuthorityCount
+}
 
-	// Convert and add the IdentifierAuthority (6 bytes, big-endian)
-	identifierBytes, err := sid.IdentifierAuthority.Marshal()
+// Size returns the size of the binary representation of the SID, as produced by Marshal.
+//
+// Returns:
+//   - int: The size of the SID, in bytes.
+func (sid *SID) Size() int {
+	size := 2 + sid.IdentifierAuthority.Size() + 4*len(sid.SubAuthorities)
+	if sid.hasRelativeIdentifier() {
+		size += 4
+	}
+	return size
+}
+
+// AppendBinary appends the binary representation of the SID to a buffer. When the buffer
+// has Size() bytes of spare capacity, nothing is allocated.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: An error if the SID cannot be serialized.
+func (sid *SID) AppendBinary(buffer []byte) ([]byte, error) {
+	// Add the RevisionLevel and the SubAuthorityCount
+	buffer = append(buffer, sid.RevisionLevel, sid.SubAuthorityCount)
+
+	// Add the IdentifierAuthority (6 bytes, big-endian)
+	buffer, err := sid.IdentifierAuthority.AppendBinary(buffer)
 	if err != nil {
 		return nil, err
 	}
-	marshalledData = append(marshalledData, identifierBytes...)
 
 	// Add each sub-authority (4 bytes each, little-endian)
 	for k := 0; k < len(sid.SubAuthorities); k++ {
-		var buf [4]byte
-		binary.LittleEndian.PutUint32(buf[:], sid.SubAuthorities[k])
-		marshalledData = append(marshalledData, buf[:]...)
+		buffer = binary.LittleEndian.AppendUint32(buffer, sid.SubAuthorities[k])
 	}
 
 	// The RID is the last sub-authority and must be serialized whenever the
 	// model indicates one exists (SubAuthorityCount > len(SubAuthorities)),
 	// including when its value is zero (e.g. S-1-1-0, S-1-0-0, S-1-3-0).
-	if uint8(len(sid.SubAuthorities)) < sid.SubAuthorityCount {
-		relativeIdentifierBytes := make([]byte, 4)
-		binary.LittleEndian.PutUint32(relativeIdentifierBytes, sid.RelativeIdentifier)
-		marshalledData = append(marshalledData, relativeIdentifierBytes...)
+	if sid.hasRelativeIdentifier() {
+		buffer = binary.LittleEndian.AppendUint32(buffer, sid.RelativeIdentifier)
 	}
 
-	return marshalledData, nil
+	return buffer, nil
+}
+
+// MarshalTo writes the binary representation of the SID at the start of a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to write to, at least Size() bytes long.
+//
+// Returns:
+//   - int: The number of bytes written.
+//   - error: An error if the buffer is too short or the SID cannot be serialized.
+func (sid *SID) MarshalTo(buffer []byte) (int, error) {
+	size := sid.Size()
+	if len(buffer) < size {
+		return 0, fmt.Errorf("SID requires a buffer of %d bytes, got %d: %w", size, len(buffer), io.ErrShortBuffer)
+	}
+	if _, err := sid.AppendBinary(buffer[:0:size]); err != nil {
+		return 0, err
+	}
+	return size, nil
 }
 
 // FromString populates the SID struct fields from a provided SID string representation.
@@ -384,6 +470,7 @@ func (sid *SID) FromString(sidString string) error {
 	sid.RelativeIdentifier = 0
 	sid.Reserved = make([]byte, 0)
 
+	sid.RawBytes = nil
 	sid.RawBytesSize = 0
 
 	// Split the SID string into parts using "-" as the delimiter
@@ -0,0 +1,38 @@
+package sid_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/sid"
+	"github.com/TheManticoreProject/winacl/sid/authority"
+)
+
+// Test_SID_ConcurrentLookups reads the global maps of the package from many goroutines. The
+// maps are only written by their initializers, run with `go test -race` to check it.
+func Test_SID_ConcurrentLookups(t *testing.T) {
+	sidStrings := []string{sid.WELLKNOWNSID_EVERYONE, sid.WELLKNOWNSID_NT_AUTHORITY_LOCAL_SYSTEM, "S-1-5-32-544", "S-1-5-21-1-2-3-1104"}
+
+	waitGroup := sync.WaitGroup{}
+	for worker := 0; worker < 8; worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for iteration := 0; iteration < 200; iteration++ {
+				for _, sidString := range sidStrings {
+					s := &sid.SID{}
+					if err := s.FromString(sidString); err != nil {
+						t.Errorf("FromString(%s) error = %v", sidString, err)
+						return
+					}
+					if s.IsWellKnownSID() != (s.LookupName() != "") {
+						t.Errorf("IsWellKnownSID() and LookupName() disagree for %s", sidString)
+					}
+					_ = s.IdentifierAuthority.String()
+					_ = authority.SIDAuthorityNames[s.IdentifierAuthority.Value]
+				}
+			}
+		}()
+	}
+	waitGroup.Wait()
+}
@@ -0,0 +1,120 @@
+package sid
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MarshalBinary returns the binary representation of the SID, implementing
+// encoding.BinaryMarshaler. The SID is left untouched, unlike with Marshal.
+//
+// Returns:
+//   - []byte: The binary representation of the SID.
+//   - error: An error if the SID cannot be serialized.
+func (sid *SID) MarshalBinary() ([]byte, error) {
+	return sid.AppendBinary(make([]byte, 0, sid.Size()))
+}
+
+// UnmarshalBinary parses the binary representation of a SID, implementing
+// encoding.BinaryUnmarshaler. The data is copied, so that the SID does not reference it.
+//
+// Parameters:
+//   - data ([]byte): The binary representation of the SID, without trailing bytes.
+//
+// Returns:
+//   - error: An error if the data is not exactly one SID.
+func (sid *SID) UnmarshalBinary(data []byte) error {
+	size, err := sid.Unmarshal(bytes.Clone(data))
+	if err != nil {
+		return err
+	}
+	if size != len(data) {
+		return fmt.Errorf("invalid binary SID: %d trailing bytes after the SID", len(data)-size)
+	}
+	return nil
+}
+
+// MarshalText returns the string representation of the SID ("S-1-5-32-544"), implementing
+// encoding.TextMarshaler. A SID without sub-authority is written "S-1-5", see textForm.
+//
+// Returns:
+//   - []byte: The string representation of the SID.
+//   - error: Always nil.
+func (sid SID) MarshalText() ([]byte, error) {
+	return []byte(sid.textForm()), nil
+}
+
+// UnmarshalText parses the string representation of a SID, implementing
+// encoding.TextUnmarshaler.
+//
+// Parameters:
+//   - text ([]byte): The string representation of the SID, like "S-1-5-32-544" or "S-1-5".
+//
+// Returns:
+//   - error: An error if the text is not a valid SID string.
+func (sid *SID) UnmarshalText(text []byte) error {
+	return sid.fromTextForm(string(text))
+}
+
+// MarshalJSON returns the JSON string holding the string representation of the SID,
+// implementing json.Marshaler. Like MarshalText, it has a value receiver, so that the SIDs
+// held by value, which are not addressable in map values, are encoded as strings too.
+//
+// Returns:
+//   - []byte: The JSON string, like "S-1-5-32-544".
+//   - error: Always nil.
+func (sid SID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sid.textForm())
+}
+
+// UnmarshalJSON parses a JSON string holding the string representation of a SID, implementing
+// json.Unmarshaler.
+//
+// Parameters:
+//   - data ([]byte): The JSON string, like "S-1-5-32-544".
+//
+// Returns:
+//   - error: An error if the data is not a JSON string holding a valid SID.
+func (sid *SID) UnmarshalJSON(data []byte) error {
+	var sidString string
+	if err := json.Unmarshal(data, &sidString); err != nil {
+		return fmt.Errorf("invalid JSON SID: %w", err)
+	}
+	return sid.fromTextForm(sidString)
+}
+
+// textForm returns the string representation of the SID written by the text and JSON
+// encodings. ToString always writes a RID, which would be read back as an additional
+// sub-authority, so a SID without sub-authority is written "S-<Revision>-<IdentifierAuthority>"
+// like Windows does.
+//
+// Returns:
+//   - string: The string representation of the SID.
+func (sid *SID) textForm() string {
+	if sid.SubAuthorityCount == 0 {
+		return fmt.Sprintf("S-%d-%d", sid.RevisionLevel, sid.IdentifierAuthority.Value)
+	}
+	return sid.ToString()
+}
+
+// fromTextForm parses the string representation written by textForm.
+//
+// Parameters:
+//   - sidString (string): The string representation of the SID.
+//
+// Returns:
+//   - error: An error if the string is not a valid SID string.
+func (sid *SID) fromTextForm(sidString string) error {
+	if strings.Count(sidString, "-") != 2 {
+		return sid.FromString(sidString)
+	}
+	// FromString requires a RID, parse one and remove it
+	if err := sid.FromString(sidString + "-0"); err != nil {
+		return err
+	}
+	sid.SubAuthorityCount = 0
+	sid.RelativeIdentifier = 0
+	return nil
+}
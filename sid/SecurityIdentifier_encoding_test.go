@@ -0,0 +1,99 @@
+package sid_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/sid"
+)
+
+func TestSecurityIdentifier_Encoding(t *testing.T) {
+	original := &sid.SID{}
+	if err := original.FromString("S-1-5-21-1-2-3-1105"); err != nil {
+		t.Fatalf("FromString() error = %v", err)
+	}
+
+	binaryData, err := original.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	fromBinary := &sid.SID{}
+	if err := fromBinary.UnmarshalBinary(binaryData); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	if !fromBinary.Equal(original) {
+		t.Errorf("UnmarshalBinary() = %s, want %s", fromBinary, original)
+	}
+	if err := fromBinary.UnmarshalBinary(append(binaryData, 0x00)); err == nil {
+		t.Errorf("UnmarshalBinary() with a trailing byte succeeded, want an error")
+	}
+
+	text, err := original.MarshalText()
+	if err != nil || string(text) != "S-1-5-21-1-2-3-1105" {
+		t.Fatalf("MarshalText() = %q, %v, want S-1-5-21-1-2-3-1105", text, err)
+	}
+	fromText := &sid.SID{}
+	if err := fromText.UnmarshalText(text); err != nil || !fromText.Equal(original) {
+		t.Errorf("UnmarshalText() = %s, %v, want %s", fromText, err, original)
+	}
+
+	document := map[string]*sid.SID{"trustee": original}
+	jsonData, err := json.Marshal(document)
+	if err != nil || string(jsonData) != `{"trustee":"S-1-5-21-1-2-3-1105"}` {
+		t.Fatalf("json.Marshal() = %s, %v", jsonData, err)
+	}
+	fromJSON := map[string]*sid.SID{}
+	if err := json.Unmarshal(jsonData, &fromJSON); err != nil || !fromJSON["trustee"].Equal(original) {
+		t.Errorf("json.Unmarshal() = %v, %v, want %s", fromJSON, err, original)
+	}
+	if err := json.Unmarshal([]byte(`{"trustee":"S-1-X"}`), &fromJSON); err == nil {
+		t.Errorf("json.Unmarshal() of an invalid SID succeeded, want an error")
+	}
+
+	// SIDs held by value, which are not addressable in maps
+	byValue := struct {
+		Owner    sid.SID
+		Trustees map[string]sid.SID
+	}{Owner: *original, Trustees: map[string]sid.SID{"trustee": *original}}
+	jsonData, err = json.Marshal(byValue)
+	if err != nil || string(jsonData) != `{"Owner":"S-1-5-21-1-2-3-1105","Trustees":{"trustee":"S-1-5-21-1-2-3-1105"}}` {
+		t.Errorf("json.Marshal() by value = %s, %v", jsonData, err)
+	}
+}
+
+func TestSecurityIdentifier_Encoding_NoSubAuthority(t *testing.T) {
+	// S-1-5 without sub-authority, which ToString writes S-1-5-0
+	original := &sid.SID{}
+	if err := original.UnmarshalBinary([]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05}); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	binaryData, _ := original.MarshalBinary()
+
+	text, err := original.MarshalText()
+	if err != nil || string(text) != "S-1-5" {
+		t.Fatalf("MarshalText() = %q, %v, want S-1-5", text, err)
+	}
+	fromText := &sid.SID{}
+	if err := fromText.UnmarshalText(text); err != nil {
+		t.Fatalf("UnmarshalText() error = %v", err)
+	}
+	if got, _ := fromText.MarshalBinary(); fromText.SubAuthorityCount != 0 || string(got) != string(binaryData) {
+		t.Errorf("UnmarshalText() = %x with %d sub-authorities, want %x", got, fromText.SubAuthorityCount, binaryData)
+	}
+
+	jsonData, err := json.Marshal(original)
+	if err != nil || string(jsonData) != `"S-1-5"` {
+		t.Fatalf("json.Marshal() = %s, %v, want \"S-1-5\"", jsonData, err)
+	}
+	fromJSON := &sid.SID{}
+	if err := json.Unmarshal(jsonData, fromJSON); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got, _ := fromJSON.MarshalBinary(); string(got) != string(binaryData) {
+		t.Errorf("json.Unmarshal() = %x, want %x", got, binaryData)
+	}
+
+	if err := fromText.UnmarshalText([]byte("S-1")); err == nil {
+		t.Errorf("UnmarshalText() of S-1 succeeded, want an error")
+	}
+}
@@ -1,5 +1,12 @@
 package sid
 
+import (
+	"bytes"
+	"slices"
+
+	"github.com/TheManticoreProject/winacl/sid/authority"
+)
+
 // Equal checks if two SecurityIdentifier objects are equal by comparing all their fields.
 //
 // Parameters:
@@ -54,3 +61,50 @@ func (sid *SID) Equal(other *SID) bool {
 
 	return true
 }
+
+// IsProcessTrustSID checks if the SID is a process trust label SID, of the form
+// S-1-19-<protection type>-<protection level>.
+//
+// Returns:
+// - bool: true if the SID is issued by the process trust authority, false otherwise
+func (sid *SID) IsProcessTrustSID() bool {
+	return sid.IdentifierAuthority.Value == authority.SID_AUTHORITY_SECURITY_PROCESS_TRUST && sid.SubAuthorityCount == 2
+}
+
+// ProcessTrustDominates checks if the process trust label SID dominates another one, i.e. if
+// both its protection type and its protection level are greater than or equal to those of the
+// other SID. A protected process (type 1024) dominates a protected light process (type 512) of
+// the same or a lower level.
+//
+// Parameters:
+// - other: The process trust label SID to compare with
+//
+// Returns:
+// - bool: true if the SID dominates the other SID, false otherwise or if either SID is not a process trust label SID
+func (sid *SID) ProcessTrustDominates(other *SID) bool {
+	if sid == nil || other == nil || !sid.IsProcessTrustSID() || !other.IsProcessTrustSID() {
+		return false
+	}
+	// A hand-built SID may have fewer SubAuthorities than its SubAuthorityCount tells
+	if len(sid.SubAuthorities) == 0 || len(other.SubAuthorities) == 0 {
+		return false
+	}
+	return sid.SubAuthorities[0] >= other.SubAuthorities[0] && sid.RelativeIdentifier >= other.RelativeIdentifier
+}
+
+// Clone returns a deep copy of the SID. The copy shares no memory with the original, in
+// particular not the buffer it was parsed from, so that either can be modified or outlive
+// the buffer.
+//
+// Returns:
+//   - *SID: The copy of the SID, nil if the SID is nil.
+func (sid *SID) Clone() *SID {
+	if sid == nil {
+		return nil
+	}
+	clone := *sid
+	clone.SubAuthorities = slices.Clone(sid.SubAuthorities)
+	clone.Reserved = bytes.Clone(sid.Reserved)
+	clone.RawBytes = bytes.Clone(sid.RawBytes)
+	return &clone
+}
@@ -135,3 +135,60 @@ func TestSecurityIdentifier_Equal(t *testing.T) {
 		})
 	}
 }
+
+func TestSecurityIdentifier_ProcessTrustDominates(t *testing.T) {
+	tests := []struct {
+		sid1     string
+		sid2     string
+		expected bool
+	}{
+		{"S-1-19-1024-8192", "S-1-19-512-8192", true},
+		{"S-1-19-512-8192", "S-1-19-512-8192", true},
+		{"S-1-19-512-4096", "S-1-19-512-8192", false},
+		{"S-1-19-512-8192", "S-1-19-1024-1536", false},
+		{"S-1-5-18", "S-1-19-512-0", false},
+	}
+
+	// A hand-built SID whose SubAuthorityCount does not match its SubAuthorities
+	handBuilt := &sid.SID{RevisionLevel: 1, SubAuthorityCount: 2, RelativeIdentifier: 8192}
+	handBuilt.IdentifierAuthority.Value = authority.SID_AUTHORITY_SECURITY_PROCESS_TRUST
+	valid := &sid.SID{}
+	valid.FromString("S-1-19-512-8192")
+	if handBuilt.ProcessTrustDominates(valid) || valid.ProcessTrustDominates(handBuilt) {
+		t.Errorf("ProcessTrustDominates() = true with a SID without SubAuthorities")
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sid1+" "+tt.sid2, func(t *testing.T) {
+			sid1 := &sid.SID{}
+			sid1.FromString(tt.sid1)
+			sid2 := &sid.SID{}
+			sid2.FromString(tt.sid2)
+			if result := sid1.ProcessTrustDominates(sid2); result != tt.expected {
+				t.Errorf("ProcessTrustDominates() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSecurityIdentifier_Clone(t *testing.T) {
+	marshalledData := []byte{0x01, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05, 0x15, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0xe9, 0x03, 0x00, 0x00}
+	original := &sid.SID{}
+	if _, err := original.Unmarshal(marshalledData); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	clone := original.Clone()
+	if !clone.Equal(original) || clone.ToString() != "S-1-5-21-1-2-3-1001" {
+		t.Fatalf("Clone() = %s, want %s", clone.ToString(), original.ToString())
+	}
+
+	clone.SubAuthorities[0] = 42
+	clone.RawBytes[0] = 0xff
+	if original.SubAuthorities[0] != 21 || original.RawBytes[0] != 0x01 {
+		t.Errorf("modifying the clone changed the original: %s %x", original.ToString(), original.RawBytes)
+	}
+	if (*sid.SID)(nil).Clone() != nil {
+		t.Errorf("Clone() of nil is not nil")
+	}
+}
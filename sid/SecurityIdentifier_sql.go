@@ -0,0 +1,123 @@
+package sid
+
+import (
+	"database/sql/driver"
+
+	"github.com/TheManticoreProject/winacl/internal/storage"
+)
+
+// Value returns the binary representation of the SID, implementing driver.Valuer. It has a
+// value receiver, so that SIDs and sql.Null[sid.SID] can be passed as query arguments, and
+// database/sql stores nil *SID arguments as NULL. Wrap the SID in SQLText or SQLJSON to store
+// it in a text or JSON column instead.
+//
+// Returns:
+//   - driver.Value: The binary representation as a []byte.
+//   - error: An error if the SID cannot be serialized.
+func (sid SID) Value() (driver.Value, error) {
+	return sid.MarshalBinary()
+}
+
+// Scan parses a SID read from a database column, implementing sql.Scanner. The binary, text
+// and JSON representations are all accepted, from []byte or string values, so that the
+// storage format of a column can be changed without migrating its data.
+//
+// Parameters:
+//   - src (any): The value read from the database column.
+//
+// Returns:
+//   - error: An error if the value is NULL or is not a valid SID.
+func (sid *SID) Scan(src any) error {
+	data, err := storage.ScanBytes(src, "sid.SID")
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(data) > 0 && data[0] == '"':
+		return sid.UnmarshalJSON(data)
+	case len(data) > 0 && data[0] == 'S':
+		return sid.UnmarshalText(data)
+	default:
+		return sid.UnmarshalBinary(data)
+	}
+}
+
+// SQLText stores a SID in a text column as its string representation, like "S-1-5-32-544".
+// The format is chosen for each value, so that columns of different formats can be used
+// concurrently:
+//
+//	db.Exec("INSERT INTO acl (trustee) VALUES (?)", sid.SQLText{SID: trustee})
+//	row.Scan(&sid.SQLText{SID: trustee})
+//
+// A nil SID is stored as NULL, and NULL is scanned as a nil SID.
+type SQLText struct {
+	*SID
+}
+
+// Value returns the string representation of the SID, implementing driver.Valuer.
+//
+// Returns:
+//   - driver.Value: The string representation as a string, or nil if the SID is nil.
+//   - error: An error if the SID cannot be serialized.
+func (text SQLText) Value() (driver.Value, error) {
+	if text.SID == nil {
+		return nil, nil
+	}
+	data, err := text.SID.MarshalText()
+	return string(data), err
+}
+
+// Scan parses a SID read from a database column into the SID, allocated if nil, implementing
+// sql.Scanner. Every representation accepted by SID.Scan is accepted.
+//
+// Parameters:
+//   - src (any): The value read from the database column.
+//
+// Returns:
+//   - error: An error if the value is not a valid SID.
+func (text *SQLText) Scan(src any) error {
+	return scanNullable(&text.SID, src)
+}
+
+// SQLJSON stores a SID in a JSON column as a JSON string, like "\"S-1-5-32-544\"", see
+// SQLText. A nil SID is stored as NULL, and NULL is scanned as a nil SID.
+type SQLJSON struct {
+	*SID
+}
+
+// Value returns the JSON representation of the SID, implementing driver.Valuer.
+//
+// Returns:
+//   - driver.Value: The JSON representation as a string, or nil if the SID is nil.
+//   - error: An error if the SID cannot be serialized.
+func (document SQLJSON) Value() (driver.Value, error) {
+	if document.SID == nil {
+		return nil, nil
+	}
+	data, err := document.SID.MarshalJSON()
+	return string(data), err
+}
+
+// Scan parses a SID read from a database column into the SID, allocated if nil, implementing
+// sql.Scanner. Every representation accepted by SID.Scan is accepted.
+//
+// Parameters:
+//   - src (any): The value read from the database column.
+//
+// Returns:
+//   - error: An error if the value is not a valid SID.
+func (document *SQLJSON) Scan(src any) error {
+	return scanNullable(&document.SID, src)
+}
+
+// scanNullable scans a value into the SID of a wrapper, setting it to nil for NULL.
+func scanNullable(target **SID, src any) error {
+	if src == nil {
+		*target = nil
+		return nil
+	}
+	if *target == nil {
+		*target = &SID{}
+	}
+	return (*target).Scan(src)
+}
@@ -451,3 +451,30 @@ func Test_SID_UnmarshalMarshal_ZeroRID(t *testing.T) {
 			hex.EncodeToString(got), hex.EncodeToString(raw))
 	}
 }
+
+func Test_SID_AppendBinary(t *testing.T) {
+	for _, sidString := range []string{"S-1-1-0", "S-1-5-32-544", "S-1-5-21-1-2-3-513", "S-1-16-12288"} {
+		s := &sid.SID{}
+		if err := s.FromString(sidString); err != nil {
+			t.Fatalf("FromString(%s) error = %v", sidString, err)
+		}
+		marshalledData, err := s.Marshal()
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		if s.Size() != len(marshalledData) {
+			t.Errorf("%s: Size() = %d, want %d", sidString, s.Size(), len(marshalledData))
+		}
+		appended, err := s.AppendBinary([]byte{0xaa})
+		if err != nil || !bytes.Equal(appended[1:], marshalledData) {
+			t.Errorf("%s: AppendBinary() = %x, %v, want %x", sidString, appended, err, marshalledData)
+		}
+		buffer := make([]byte, s.Size())
+		if written, err := s.MarshalTo(buffer); err != nil || written != len(marshalledData) || !bytes.Equal(buffer, marshalledData) {
+			t.Errorf("%s: MarshalTo() = %d, %v", sidString, written, err)
+		}
+		if _, err := s.MarshalTo(buffer[:len(buffer)-1]); err == nil {
+			t.Errorf("%s: MarshalTo() into a short buffer succeeded", sidString)
+		}
+	}
+}
@@ -2,7 +2,8 @@ package authority
 
 import (
 	"encoding/binary"
-	"fmt"
+
+	"github.com/TheManticoreProject/winacl/parsing"
 )
 
 // SID authority constants define the various authorities used in Security Identifiers (SIDs),
@@ -20,6 +21,7 @@ const (
 	SID_AUTHORITY_SECURITY_MANDATORY_LABEL  = 0x000000000010
 	SID_AUTHORITY_SECURITY_SCOPED_POLICY_ID = 0x000000000011
 	SID_AUTHORITY_SECURITY_AUTHENTICATION   = 0x000000000012
+	SID_AUTHORITY_SECURITY_PROCESS_TRUST    = 0x000000000013
 )
 
 // sidAuthorityNames maps integer constants representing various security identifier (SID) authorities
@@ -40,6 +42,7 @@ var SIDAuthorityNames = map[uint64]string{
 	SID_AUTHORITY_SECURITY_MANDATORY_LABEL:  "Security Mandatory Label",
 	SID_AUTHORITY_SECURITY_SCOPED_POLICY_ID: "Security Scoped Policy ID",
 	SID_AUTHORITY_SECURITY_AUTHENTICATION:   "Security Authentication",
+	SID_AUTHORITY_SECURITY_PROCESS_TRUST:    "Security Process Trust",
 }
 
 // SecurityIdentifierAuthority represents an authority within a Security Identifier (SID),
@@ -67,7 +70,7 @@ type SecurityIdentifierAuthority struct {
 //     If not found, assigns a default value of "?" to `Name`.
 func (sia *SecurityIdentifierAuthority) Unmarshal(marshalledData []byte) (int, error) {
 	if len(marshalledData) < 6 {
-		return 0, fmt.Errorf("SecurityIdentifierAuthority unmarshal requires at least 6 bytes, got %d", len(marshalledData))
+		return 0, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "SecurityIdentifierAuthority unmarshal requires at least 6 bytes, got %d", len(marshalledData))
 	}
 
 	sia.Value = 0
@@ -84,13 +87,30 @@ func (sia *SecurityIdentifierAuthority) Unmarshal(marshalledData []byte) (int, e
 // Returns:
 //   - []byte: A byte slice representing the SecurityIdentifierAuthority in binary format, constructed from its fields.
 func (sia *SecurityIdentifierAuthority) Marshal() ([]byte, error) {
-	identifierBytes := make([]byte, 6)
+	return sia.AppendBinary(make([]byte, 0, sia.Size()))
+}
 
-	binary.BigEndian.PutUint16(identifierBytes[0:2], uint16(sia.Value>>32))
-	binary.BigEndian.PutUint16(identifierBytes[2:4], uint16(sia.Value>>16))
-	binary.BigEndian.PutUint16(identifierBytes[4:6], uint16(sia.Value))
+// Size returns the size of the binary representation of the SecurityIdentifierAuthority.
+//
+// Returns:
+//   - int: Always 6, the identifier authority is a 48-bit value.
+func (sia *SecurityIdentifierAuthority) Size() int {
+	return 6
+}
 
-	return identifierBytes, nil
+// AppendBinary appends the binary representation of the SecurityIdentifierAuthority to a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: Always nil.
+func (sia *SecurityIdentifierAuthority) AppendBinary(buffer []byte) ([]byte, error) {
+	buffer = binary.BigEndian.AppendUint16(buffer, uint16(sia.Value>>32))
+	buffer = binary.BigEndian.AppendUint16(buffer, uint16(sia.Value>>16))
+	buffer = binary.BigEndian.AppendUint16(buffer, uint16(sia.Value))
+	return buffer, nil
 }
 
 // String returns the name of the authority as a string.
@@ -0,0 +1,224 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TheManticoreProject/winacl/parsing"
+)
+
+// Severity is the severity of a validation finding.
+type Severity uint8
+
+const (
+	// SEVERITY_INFO reports a legal but unusual construct.
+	SEVERITY_INFO Severity = iota
+	// SEVERITY_WARNING reports a construct that is accepted by Windows but is likely a mistake,
+	// or that has no effect.
+	SEVERITY_WARNING
+	// SEVERITY_ERROR reports a construct that is rejected by Windows or corrupts the structure.
+	SEVERITY_ERROR
+)
+
+// SeverityNames maps the severities to their names.
+var SeverityNames = map[Severity]string{
+	SEVERITY_INFO:    "INFO",
+	SEVERITY_WARNING: "WARNING",
+	SEVERITY_ERROR:   "ERROR",
+}
+
+// String returns the name of the severity.
+//
+// Returns:
+//   - string: The name of the severity, or "?" if it is unknown.
+func (severity Severity) String() string {
+	if name, exists := SeverityNames[severity]; exists {
+		return name
+	}
+	return "?"
+}
+
+// FindingCode identifies the rule that produced a finding, so that callers can filter or
+// allow-list findings without parsing their messages.
+type FindingCode string
+
+const (
+	// Security descriptor findings
+	FINDING_CODE_SD_REVISION_INVALID     FindingCode = "SD_REVISION_INVALID"
+	FINDING_CODE_SD_SBZ1_NOT_ZERO        FindingCode = "SD_SBZ1_NOT_ZERO"
+	FINDING_CODE_SD_NOT_SELF_RELATIVE    FindingCode = "SD_NOT_SELF_RELATIVE"
+	FINDING_CODE_SD_OWNER_MISSING        FindingCode = "SD_OWNER_MISSING"
+	FINDING_CODE_SD_GROUP_MISSING        FindingCode = "SD_GROUP_MISSING"
+	FINDING_CODE_SD_NULL_DACL            FindingCode = "SD_NULL_DACL"
+	FINDING_CODE_SD_EMPTY_DACL           FindingCode = "SD_EMPTY_DACL"
+	FINDING_CODE_SD_DACL_PRESENT_NOT_SET FindingCode = "SD_DACL_PRESENT_NOT_SET"
+	FINDING_CODE_SD_NULL_SACL            FindingCode = "SD_NULL_SACL"
+	FINDING_CODE_SD_SACL_PRESENT_NOT_SET FindingCode = "SD_SACL_PRESENT_NOT_SET"
+
+	// Access control list findings
+	FINDING_CODE_ACL_REVISION_INVALID   FindingCode = "ACL_REVISION_INVALID"
+	FINDING_CODE_ACL_REVISION_TOO_LOW   FindingCode = "ACL_REVISION_TOO_LOW"
+	FINDING_CODE_ACL_SBZ_NOT_ZERO       FindingCode = "ACL_SBZ_NOT_ZERO"
+	FINDING_CODE_ACL_ACE_COUNT_MISMATCH FindingCode = "ACL_ACE_COUNT_MISMATCH"
+	FINDING_CODE_ACL_ACE_TYPE_MISPLACED FindingCode = "ACL_ACE_TYPE_MISPLACED"
+	FINDING_CODE_ACL_NOT_CANONICAL      FindingCode = "ACL_NOT_CANONICAL"
+	FINDING_CODE_ACL_MULTIPLE_LABELS    FindingCode = "ACL_MULTIPLE_LABELS"
+
+	// Access control entry findings
+	FINDING_CODE_ACE_TYPE_UNKNOWN            FindingCode = "ACE_TYPE_UNKNOWN"
+	FINDING_CODE_ACE_TYPE_RESERVED           FindingCode = "ACE_TYPE_RESERVED"
+	FINDING_CODE_ACE_BODY_OPAQUE             FindingCode = "ACE_BODY_OPAQUE"
+	FINDING_CODE_ACE_SIZE_UNALIGNED          FindingCode = "ACE_SIZE_UNALIGNED"
+	FINDING_CODE_ACE_FLAGS_RESERVED          FindingCode = "ACE_FLAGS_RESERVED"
+	FINDING_CODE_ACE_AUDIT_FLAGS_MISPLACED   FindingCode = "ACE_AUDIT_FLAGS_MISPLACED"
+	FINDING_CODE_ACE_AUDIT_FLAGS_MISSING     FindingCode = "ACE_AUDIT_FLAGS_MISSING"
+	FINDING_CODE_ACE_INHERIT_ONLY_NO_EFFECT  FindingCode = "ACE_INHERIT_ONLY_NO_EFFECT"
+	FINDING_CODE_ACE_NO_PROPAGATE_NO_EFFECT  FindingCode = "ACE_NO_PROPAGATE_NO_EFFECT"
+	FINDING_CODE_ACE_OBJECT_FLAGS_RESERVED   FindingCode = "ACE_OBJECT_FLAGS_RESERVED"
+	FINDING_CODE_ACE_MASK_EMPTY              FindingCode = "ACE_MASK_EMPTY"
+	FINDING_CODE_ACE_CONDITION_MALFORMED     FindingCode = "ACE_CONDITION_MALFORMED"
+	FINDING_CODE_ACE_LABEL_SID_INVALID       FindingCode = "ACE_LABEL_SID_INVALID"
+	FINDING_CODE_ACE_TRUST_LABEL_SID_INVALID FindingCode = "ACE_TRUST_LABEL_SID_INVALID"
+
+	// Security identifier findings
+	FINDING_CODE_SID_REVISION_INVALID        FindingCode = "SID_REVISION_INVALID"
+	FINDING_CODE_SID_TOO_MANY_SUBAUTHORITIES FindingCode = "SID_TOO_MANY_SUBAUTHORITIES"
+	FINDING_CODE_SID_SUBAUTHORITIES_MISMATCH FindingCode = "SID_SUBAUTHORITIES_MISMATCH"
+)
+
+// Finding is a problem found while validating a structure.
+//
+// Attributes:
+//   - Severity (Severity): The severity of the finding.
+//   - Code (FindingCode): The rule that produced the finding.
+//   - Path (string): The location of the finding in the validated structure, like
+//     "DACL.Entries[2].Identity", or "" for the structure itself.
+//   - Message (string): A human readable description of the finding.
+type Finding struct {
+	Severity Severity
+	Code     FindingCode
+	Path     string
+	Message  string
+}
+
+// String returns a one-line description of the finding.
+//
+// Returns:
+//   - string: The severity, path, code and message of the finding.
+func (finding Finding) String() string {
+	if finding.Path == "" {
+		return fmt.Sprintf("%s %s: %s", finding.Severity, finding.Code, finding.Message)
+	}
+	return fmt.Sprintf("%s %s %s: %s", finding.Severity, finding.Path, finding.Code, finding.Message)
+}
+
+// Findings is a list of findings.
+type Findings []Finding
+
+// Add appends a finding to the list.
+//
+// Parameters:
+//   - severity (Severity): The severity of the finding.
+//   - code (FindingCode): The rule that produced the finding.
+//   - path (string): The location of the finding.
+//   - format (string): The format of the message, followed by its arguments.
+func (findings *Findings) Add(severity Severity, code FindingCode, path string, format string, args ...any) {
+	*findings = append(*findings, Finding{
+		Severity: severity,
+		Code:     code,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Extend appends the findings of a nested structure, prefixing their paths.
+//
+// Parameters:
+//   - prefix (string): The path of the nested structure.
+//   - nested (Findings): The findings of the nested structure.
+func (findings *Findings) Extend(prefix string, nested Findings) {
+	for _, finding := range nested {
+		finding.Path = JoinPath(prefix, finding.Path)
+		*findings = append(*findings, finding)
+	}
+}
+
+// HasErrors checks if one of the findings has the SEVERITY_ERROR severity.
+//
+// Returns:
+//   - bool: true if there is at least one error, false otherwise.
+func (findings Findings) HasErrors() bool {
+	return findings.MaxSeverity() == SEVERITY_ERROR
+}
+
+// MaxSeverity returns the highest severity of the findings.
+//
+// Returns:
+//   - Severity: The highest severity, or SEVERITY_INFO if there are no findings.
+func (findings Findings) MaxSeverity() Severity {
+	maxSeverity := SEVERITY_INFO
+	for _, finding := range findings {
+		if finding.Severity > maxSeverity {
+			maxSeverity = finding.Severity
+		}
+	}
+	return maxSeverity
+}
+
+// AtLeast returns the findings whose severity is at least the given one.
+//
+// Parameters:
+//   - severity (Severity): The minimum severity.
+//
+// Returns:
+//   - Findings: The matching findings, in order.
+func (findings Findings) AtLeast(severity Severity) Findings {
+	filtered := Findings{}
+	for _, finding := range findings {
+		if finding.Severity >= severity {
+			filtered = append(filtered, finding)
+		}
+	}
+	return filtered
+}
+
+// WithCode returns the findings produced by a rule.
+//
+// Parameters:
+//   - code (FindingCode): The rule.
+//
+// Returns:
+//   - Findings: The matching findings, in order.
+func (findings Findings) WithCode(code FindingCode) Findings {
+	filtered := Findings{}
+	for _, finding := range findings {
+		if finding.Code == code {
+			filtered = append(filtered, finding)
+		}
+	}
+	return filtered
+}
+
+// String returns the findings, one per line.
+//
+// Returns:
+//   - string: The description of the findings.
+func (findings Findings) String() string {
+	lines := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		lines = append(lines, finding.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// JoinPath joins two paths of a structure, like parsing.JoinPath.
+//
+// Parameters:
+//   - prefix (string): The path of the parent structure.
+//   - path (string): The path relative to the parent structure.
+//
+// Returns:
+//   - string: The joined path.
+func JoinPath(prefix, path string) string {
+	return parsing.JoinPath(prefix, path)
+}
@@ -0,0 +1,88 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/sid"
+	"github.com/TheManticoreProject/winacl/validation"
+)
+
+func TestFindings(t *testing.T) {
+	nested := validation.Findings{}
+	nested.Add(validation.SEVERITY_INFO, validation.FINDING_CODE_ACE_NO_PROPAGATE_NO_EFFECT, "Header.Flags", "flag %s", "NP")
+	nested.Add(validation.SEVERITY_ERROR, validation.FINDING_CODE_SID_REVISION_INVALID, "", "revision %d", 2)
+
+	findings := validation.Findings{}
+	if findings.HasErrors() {
+		t.Errorf("HasErrors() = true for no findings")
+	}
+	findings.Add(validation.SEVERITY_WARNING, validation.FINDING_CODE_SD_OWNER_MISSING, "Owner", "no owner")
+	findings.Extend("DACL.Entries[1]", nested)
+
+	if len(findings) != 3 {
+		t.Fatalf("len(findings) = %d, want 3", len(findings))
+	}
+	if findings[1].Path != "DACL.Entries[1].Header.Flags" || findings[1].Message != "flag NP" {
+		t.Errorf("findings[1] = %+v", findings[1])
+	}
+	if findings[2].Path != "DACL.Entries[1]" {
+		t.Errorf("findings[2].Path = %q, want DACL.Entries[1]", findings[2].Path)
+	}
+	if !findings.HasErrors() || findings.MaxSeverity() != validation.SEVERITY_ERROR {
+		t.Errorf("HasErrors() = %v, MaxSeverity() = %s", findings.HasErrors(), findings.MaxSeverity())
+	}
+	if got := len(findings.AtLeast(validation.SEVERITY_WARNING)); got != 2 {
+		t.Errorf("len(AtLeast(WARNING)) = %d, want 2", got)
+	}
+	if got := findings.WithCode(validation.FINDING_CODE_SD_OWNER_MISSING); len(got) != 1 || got[0].Path != "Owner" {
+		t.Errorf("WithCode(SD_OWNER_MISSING) = %v", got)
+	}
+	if got := findings[0].String(); got != "WARNING Owner SD_OWNER_MISSING: no owner" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		path     string
+		expected string
+	}{
+		{"", "", ""},
+		{"DACL", "", "DACL"},
+		{"", "Header", "Header"},
+		{"DACL", "Entries[0]", "DACL.Entries[0]"},
+		{"DACL.Entries", "[0]", "DACL.Entries[0]"},
+	}
+
+	for _, tt := range tests {
+		if got := validation.JoinPath(tt.prefix, tt.path); got != tt.expected {
+			t.Errorf("JoinPath(%q, %q) = %q, want %q", tt.prefix, tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestValidateSID(t *testing.T) {
+	valid := sid.SID{}
+	if err := valid.FromString("S-1-5-21-1-2-3-500"); err != nil {
+		t.Fatalf("FromString() error = %v", err)
+	}
+	if findings := validation.ValidateSID(&valid); len(findings) != 0 {
+		t.Errorf("ValidateSID() = %v, want no findings", findings)
+	}
+
+	tooLong := valid
+	tooLong.SubAuthorities = make([]uint32, 15)
+	tooLong.SubAuthorityCount = 16
+	if findings := validation.ValidateSID(&tooLong); len(findings.WithCode(validation.FINDING_CODE_SID_TOO_MANY_SUBAUTHORITIES)) != 1 {
+		t.Errorf("ValidateSID() = %v, want SID_TOO_MANY_SUBAUTHORITIES", findings)
+	}
+
+	mismatch := valid
+	mismatch.SubAuthorityCount = 2
+	mismatch.RevisionLevel = 2
+	findings := validation.ValidateSID(&mismatch)
+	if len(findings.WithCode(validation.FINDING_CODE_SID_SUBAUTHORITIES_MISMATCH)) != 1 || len(findings.WithCode(validation.FINDING_CODE_SID_REVISION_INVALID)) != 1 {
+		t.Errorf("ValidateSID() = %v, want SID_SUBAUTHORITIES_MISMATCH and SID_REVISION_INVALID", findings)
+	}
+}
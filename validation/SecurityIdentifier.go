@@ -0,0 +1,37 @@
+package validation
+
+import (
+	"github.com/TheManticoreProject/winacl/sid"
+)
+
+// SID_MAX_SUB_AUTHORITIES is the maximum number of sub-authorities of a SID (SID_MAX_SUB_AUTHORITIES).
+const SID_MAX_SUB_AUTHORITIES = 15
+
+// ValidateSID validates the structure of a SID, like the IsValidSid function of the Windows API.
+//
+// Parameters:
+//   - s (*sid.SID): The SID to validate.
+//
+// Returns:
+//   - Findings: The problems found in the SID, with paths relative to the SID.
+func ValidateSID(s *sid.SID) Findings {
+	findings := Findings{}
+
+	if s.RevisionLevel != 1 {
+		findings.Add(SEVERITY_ERROR, FINDING_CODE_SID_REVISION_INVALID, "", "SID revision is %d, expected 1", s.RevisionLevel)
+	}
+	if s.SubAuthorityCount > SID_MAX_SUB_AUTHORITIES {
+		findings.Add(SEVERITY_ERROR, FINDING_CODE_SID_TOO_MANY_SUBAUTHORITIES, "", "SID %s has %d sub-authorities, at most %d are allowed", s.ToString(), s.SubAuthorityCount, SID_MAX_SUB_AUTHORITIES)
+	}
+
+	// SubAuthorities holds every sub-authority but the last one, which is the RelativeIdentifier
+	expectedSubAuthorities := 0
+	if s.SubAuthorityCount > 0 {
+		expectedSubAuthorities = int(s.SubAuthorityCount) - 1
+	}
+	if len(s.SubAuthorities) != expectedSubAuthorities {
+		findings.Add(SEVERITY_ERROR, FINDING_CODE_SID_SUBAUTHORITIES_MISMATCH, "", "SubAuthorityCount is %d but %d sub-authorities are set", s.SubAuthorityCount, len(s.SubAuthorities)+1)
+	}
+
+	return findings
+}
@@ -0,0 +1,294 @@
+package view
+
+import (
+	"encoding/binary"
+
+	"github.com/TheManticoreProject/winacl/ace"
+	"github.com/TheManticoreProject/winacl/ace/aceflags"
+	"github.com/TheManticoreProject/winacl/ace/acetype"
+	"github.com/TheManticoreProject/winacl/guid"
+	"github.com/TheManticoreProject/winacl/object/flags"
+	"github.com/TheManticoreProject/winacl/parsing"
+	"github.com/TheManticoreProject/winacl/rights"
+)
+
+// AccessControlEntryView is a read-only view over the bytes of a marshalled ACE. The fields
+// are decoded on access, nothing is copied, and the names of the rights of the mask are only
+// computed by MaskNames. The zero value is an empty view: its accessors return zero values,
+// and the ones locating the SID return an error.
+type AccessControlEntryView struct {
+	data []byte
+}
+
+// NewAccessControlEntryView creates a view over a marshalled ACE. The view covers the Size
+// bytes of the ACE, any following bytes are ignored.
+//
+// Parameters:
+//   - data ([]byte): The marshalled ACE.
+//
+// Returns:
+//   - AccessControlEntryView: The view over the ACE.
+//   - error: An error if the data is too short for the ACE.
+func NewAccessControlEntryView(data []byte) (AccessControlEntryView, error) {
+	if len(data) < 4 {
+		return AccessControlEntryView{}, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "ACE view requires at least 4 bytes, got %d", len(data))
+	}
+	size := int(binary.LittleEndian.Uint16(data[2:4]))
+	if size < 4 {
+		return AccessControlEntryView{}, parsing.NewParseError(parsing.ERROR_CATEGORY_OUT_OF_RANGE, 2, "Header.Size", "ACE Size (%d) is less than the minimum ACE header size (4)", size)
+	}
+	if size > len(data) {
+		return AccessControlEntryView{}, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 2, "Header.Size", "ACE Size (%d) is greater than the available data (%d)", size, len(data))
+	}
+	return AccessControlEntryView{data: data[:size]}, nil
+}
+
+// Bytes returns the bytes of the ACE, without copying them.
+//
+// Returns:
+//   - []byte: The bytes of the ACE.
+func (view AccessControlEntryView) Bytes() []byte {
+	return view.data
+}
+
+// Type returns the type of the ACE, one of the acetype.ACE_TYPE_* values.
+//
+// Returns:
+//   - uint8: The type of the ACE.
+func (view AccessControlEntryView) Type() uint8 {
+	if len(view.data) == 0 {
+		return 0
+	}
+	return view.data[0]
+}
+
+// Flags returns the flags of the ACE, a combination of the aceflags.ACE_FLAG_* values.
+//
+// Returns:
+//   - uint8: The flags of the ACE.
+func (view AccessControlEntryView) Flags() uint8 {
+	if len(view.data) == 0 {
+		return 0
+	}
+	return view.data[1]
+}
+
+// HasFlag checks if a flag of the ACE is set.
+//
+// Parameters:
+//   - flag (uint8): The flag to check, one of the aceflags.ACE_FLAG_* values.
+//
+// Returns:
+//   - bool: true if the flag is set, false otherwise.
+func (view AccessControlEntryView) HasFlag(flag uint8) bool {
+	return view.Flags()&flag == flag
+}
+
+// IsInherited checks if the ACE was inherited from a parent object.
+//
+// Returns:
+//   - bool: true if the INHERITED_ACE flag is set, false otherwise.
+func (view AccessControlEntryView) IsInherited() bool {
+	return view.HasFlag(aceflags.ACE_FLAG_INHERITED)
+}
+
+// Size returns the Size field of the ACE.
+//
+// Returns:
+//   - uint16: The size of the ACE, in bytes.
+func (view AccessControlEntryView) Size() uint16 {
+	if len(view.data) == 0 {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(view.data[2:4])
+}
+
+// Mask returns the access mask of the ACE.
+//
+// Returns:
+//   - uint32: The access mask, or 0 if the ACE is too short to hold one.
+func (view AccessControlEntryView) Mask() uint32 {
+	if len(view.data) < 8 {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(view.data[4:8])
+}
+
+// MaskNames computes the names of the rights of the access mask, see rights.RightsNamespace.
+//
+// Parameters:
+//   - namespace (rights.RightsNamespace): The rights table used to name the bits of the mask.
+//
+// Returns:
+//   - []string: The names of the rights.
+func (view AccessControlEntryView) MaskNames(namespace rights.RightsNamespace) []string {
+	names, _ := namespace.Decode(view.Mask())
+	return names
+}
+
+// IsObjectAce checks if the type of the ACE is an object-specific type, whose body holds the
+// object type fields.
+//
+// Returns:
+//   - bool: true if the ACE type is an object-specific type, false otherwise.
+func (view AccessControlEntryView) IsObjectAce() bool {
+	switch view.Type() {
+	case acetype.ACE_TYPE_ACCESS_ALLOWED_OBJECT,
+		acetype.ACE_TYPE_ACCESS_DENIED_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_AUDIT_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_ALARM_OBJECT,
+		acetype.ACE_TYPE_ACCESS_ALLOWED_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_ACCESS_DENIED_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_AUDIT_CALLBACK_OBJECT,
+		acetype.ACE_TYPE_SYSTEM_ALARM_CALLBACK_OBJECT:
+		return true
+	}
+	return false
+}
+
+// ObjectFlags returns the flags of the object type fields of an object ACE.
+//
+// Returns:
+//   - uint32: The flags, or 0 if the ACE is not an object ACE.
+func (view AccessControlEntryView) ObjectFlags() uint32 {
+	if !view.IsObjectAce() || len(view.data) < 12 {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(view.data[8:12])
+}
+
+// ObjectType returns the ObjectType GUID of an object ACE.
+//
+// Returns:
+//   - guid.GUID: The ObjectType GUID.
+//   - bool: false if the ACE has no ObjectType.
+func (view AccessControlEntryView) ObjectType() (guid.GUID, bool) {
+	if view.ObjectFlags()&flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT == 0 {
+		return guid.GUID{}, false
+	}
+	return view.guidAt(12)
+}
+
+// InheritedObjectType returns the InheritedObjectType GUID of an object ACE.
+//
+// Returns:
+//   - guid.GUID: The InheritedObjectType GUID.
+//   - bool: false if the ACE has no InheritedObjectType.
+func (view AccessControlEntryView) InheritedObjectType() (guid.GUID, bool) {
+	objectFlags := view.ObjectFlags()
+	if objectFlags&flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT == 0 {
+		return guid.GUID{}, false
+	}
+	offset := 12
+	if objectFlags&flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT != 0 {
+		offset += 16
+	}
+	return view.guidAt(offset)
+}
+
+// guidAt decodes the GUID at an offset of the ACE.
+func (view AccessControlEntryView) guidAt(offset int) (guid.GUID, bool) {
+	decoded := guid.GUID{}
+	if offset+16 > len(view.data) {
+		return decoded, false
+	}
+	_, err := decoded.Unmarshal(view.data[offset : offset+16])
+	return decoded, err == nil
+}
+
+// sidOffset returns the offset of the trustee SID of the ACE.
+func (view AccessControlEntryView) sidOffset() (int, error) {
+	if view.IsObjectAce() {
+		objectFlags := view.ObjectFlags()
+		offset := 12
+		if objectFlags&flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_OBJECT_TYPE_PRESENT != 0 {
+			offset += 16
+		}
+		if objectFlags&flags.ACCESS_CONTROL_OBJECT_TYPE_FLAG_INHERITED_OBJECT_TYPE_PRESENT != 0 {
+			offset += 16
+		}
+		return offset, nil
+	}
+	if view.Type() == acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND {
+		server, err := view.ServerSID()
+		if err != nil {
+			return 0, err
+		}
+		return 12 + server.Size(), nil
+	}
+	return 8, nil
+}
+
+// SID returns the trustee SID of the ACE, the client SID for compound ACEs.
+//
+// Returns:
+//   - SecurityIdentifierView: The view over the SID.
+//   - error: An error if the ACE type is unknown or the ACE is too short for the SID.
+func (view AccessControlEntryView) SID() (SecurityIdentifierView, error) {
+	if _, known := acetype.AccessControlEntryTypeValueToName[view.Type()]; !known {
+		return SecurityIdentifierView{}, parsing.NewParseError(parsing.ERROR_CATEGORY_UNKNOWN_TYPE, 0, "Header.Type", "unknown ACE type: %d", view.Type())
+	}
+	offset, err := view.sidOffset()
+	if err != nil {
+		return SecurityIdentifierView{}, err
+	}
+	if offset > len(view.data) {
+		return SecurityIdentifierView{}, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, len(view.data), "Identity.SID", "ACE of %d bytes is too short for a SID at offset %d", len(view.data), offset)
+	}
+	sidView, err := NewSecurityIdentifierView(view.data[offset:])
+	if err != nil {
+		return SecurityIdentifierView{}, parsing.WrapParseError(err, offset, "Identity.SID", "")
+	}
+	return sidView, nil
+}
+
+// ServerSID returns the server SID of a compound ACE.
+//
+// Returns:
+//   - SecurityIdentifierView: The view over the server SID.
+//   - error: An error if the ACE is not a compound ACE or is too short for the SID.
+func (view AccessControlEntryView) ServerSID() (SecurityIdentifierView, error) {
+	if view.Type() != acetype.ACE_TYPE_ACCESS_ALLOWED_COMPOUND {
+		return SecurityIdentifierView{}, parsing.NewParseError(parsing.ERROR_CATEGORY_INVALID, 0, "Header.Type", "ACE of type %d has no server SID", view.Type())
+	}
+	if len(view.data) < 12 {
+		return SecurityIdentifierView{}, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, len(view.data), "Compound", "compound ACE of %d bytes is too short for its fields", len(view.data))
+	}
+	sidView, err := NewSecurityIdentifierView(view.data[12:])
+	if err != nil {
+		return SecurityIdentifierView{}, parsing.WrapParseError(err, 12, "Compound.ServerIdentity.SID", "")
+	}
+	return sidView, nil
+}
+
+// ApplicationData returns the bytes following the SID of the ACE, like the conditional
+// expression of callback ACEs, without copying them.
+//
+// Returns:
+//   - []byte: The bytes following the SID, empty if there are none.
+//   - error: An error if the SID of the ACE cannot be located.
+func (view AccessControlEntryView) ApplicationData() ([]byte, error) {
+	sidView, err := view.SID()
+	if err != nil {
+		return nil, err
+	}
+	offset, err := view.sidOffset()
+	if err != nil {
+		return nil, err
+	}
+	return view.data[offset+sidView.Size():], nil
+}
+
+// ToAccessControlEntry decodes the ACE into an ace.AccessControlEntry.
+//
+// Returns:
+//   - *ace.AccessControlEntry: The decoded ACE.
+//   - error: An error if the ACE cannot be decoded.
+func (view AccessControlEntryView) ToAccessControlEntry() (*ace.AccessControlEntry, error) {
+	entry := &ace.AccessControlEntry{}
+	_, err := entry.Unmarshal(view.data)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
@@ -0,0 +1,153 @@
+package view
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/TheManticoreProject/winacl/parsing"
+)
+
+// AccessControlListView is a read-only view over the bytes of a marshalled DACL or SACL. The
+// entries are walked on demand with Entries, nothing is copied. The zero value is an empty
+// view: its accessors return zero values and it has no entries.
+type AccessControlListView struct {
+	data []byte
+}
+
+// NewAccessControlListView creates a view over a marshalled ACL. The view covers the AclSize
+// bytes of the ACL, any following bytes are ignored.
+//
+// Parameters:
+//   - data ([]byte): The marshalled ACL.
+//
+// Returns:
+//   - AccessControlListView: The view over the ACL.
+//   - error: An error if the data is too short for the ACL.
+func NewAccessControlListView(data []byte) (AccessControlListView, error) {
+	if len(data) < 8 {
+		return AccessControlListView{}, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "ACL view requires at least 8 bytes, got %d", len(data))
+	}
+	aclSize := int(binary.LittleEndian.Uint16(data[2:4]))
+	if aclSize < 8 {
+		return AccessControlListView{}, parsing.NewParseError(parsing.ERROR_CATEGORY_OUT_OF_RANGE, 2, "Header.AclSize", "AclSize (%d) is smaller than the header size (8)", aclSize)
+	}
+	if aclSize > len(data) {
+		return AccessControlListView{}, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 2, "Header.AclSize", "AclSize (%d) exceeds available data (%d)", aclSize, len(data))
+	}
+	return AccessControlListView{data: data[:aclSize]}, nil
+}
+
+// Bytes returns the bytes of the ACL, without copying them.
+//
+// Returns:
+//   - []byte: The bytes of the ACL.
+func (view AccessControlListView) Bytes() []byte {
+	return view.data
+}
+
+// Revision returns the revision of the ACL.
+//
+// Returns:
+//   - uint8: The revision, one of the revision.ACL_REVISION* values.
+func (view AccessControlListView) Revision() uint8 {
+	if len(view.data) == 0 {
+		return 0
+	}
+	return view.data[0]
+}
+
+// AclSize returns the AclSize field of the ACL.
+//
+// Returns:
+//   - uint16: The size of the ACL, in bytes.
+func (view AccessControlListView) AclSize() uint16 {
+	if len(view.data) == 0 {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(view.data[2:4])
+}
+
+// AceCount returns the AceCount field of the ACL.
+//
+// Returns:
+//   - uint16: The number of ACEs of the ACL.
+func (view AccessControlListView) AceCount() uint16 {
+	if len(view.data) == 0 {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(view.data[4:6])
+}
+
+// Entries returns an iterator over the ACEs of the ACL.
+//
+// Returns:
+//   - *AccessControlEntryIterator: The iterator, positioned before the first ACE.
+func (view AccessControlListView) Entries() *AccessControlEntryIterator {
+	return &AccessControlEntryIterator{
+		data:      view.data,
+		remaining: int(view.AceCount()),
+		offset:    8,
+		index:     -1,
+	}
+}
+
+// AccessControlEntryIterator walks the ACEs of an ACL view, in the style of bufio.Scanner:
+//
+//	entries := aclView.Entries()
+//	for entries.Next() {
+//		entry := entries.Entry()
+//	}
+//	if err := entries.Err(); err != nil {
+//	}
+type AccessControlEntryIterator struct {
+	data      []byte
+	remaining int
+	offset    int
+	index     int
+	entry     AccessControlEntryView
+	err       error
+}
+
+// Next advances the iterator to the next ACE.
+//
+// Returns:
+//   - bool: true if an ACE is available, false at the end of the ACL or on error.
+func (iterator *AccessControlEntryIterator) Next() bool {
+	if iterator.err != nil || iterator.remaining == 0 {
+		return false
+	}
+	entry, err := NewAccessControlEntryView(iterator.data[iterator.offset:])
+	if err != nil {
+		iterator.err = parsing.WrapParseError(err, iterator.offset, fmt.Sprintf("Entries[%d]", iterator.index+1), "failed to read ACE %d within AclSize", iterator.index+2)
+		return false
+	}
+	iterator.entry = entry
+	iterator.index++
+	iterator.offset += len(entry.data)
+	iterator.remaining--
+	return true
+}
+
+// Entry returns the current ACE.
+//
+// Returns:
+//   - AccessControlEntryView: The view over the current ACE.
+func (iterator *AccessControlEntryIterator) Entry() AccessControlEntryView {
+	return iterator.entry
+}
+
+// Index returns the index of the current ACE, from 0.
+//
+// Returns:
+//   - int: The index of the current ACE.
+func (iterator *AccessControlEntryIterator) Index() int {
+	return iterator.index
+}
+
+// Err returns the error that stopped the iterator.
+//
+// Returns:
+//   - error: The error, or nil if the iterator reached the end of the ACL.
+func (iterator *AccessControlEntryIterator) Err() error {
+	return iterator.err
+}
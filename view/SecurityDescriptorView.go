@@ -0,0 +1,179 @@
+package view
+
+import (
+	"encoding/binary"
+
+	"github.com/TheManticoreProject/winacl/parsing"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+)
+
+// SecurityDescriptorView is a read-only, zero-copy view over the bytes of a marshalled
+// self-relative security descriptor. It is meant for bulk processing: nothing is decoded until
+// it is accessed, and accessing a field does not allocate. Use ToNtSecurityDescriptor to get
+// the fully decoded structure. The zero value is an empty view: its accessors return zero
+// values and it has no components.
+type SecurityDescriptorView struct {
+	data []byte
+}
+
+// NewSecurityDescriptorView creates a view over a marshalled security descriptor. Only the
+// size of the header is checked, the components are checked when they are accessed.
+//
+// Parameters:
+//   - data ([]byte): The marshalled security descriptor.
+//
+// Returns:
+//   - SecurityDescriptorView: The view over the security descriptor.
+//   - error: An error if the data is too short for the header.
+func NewSecurityDescriptorView(data []byte) (SecurityDescriptorView, error) {
+	if len(data) < 20 {
+		return SecurityDescriptorView{}, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "Header", "security descriptor view requires at least 20 bytes, got %d", len(data))
+	}
+	return SecurityDescriptorView{data: data}, nil
+}
+
+// Bytes returns the bytes of the security descriptor, without copying them.
+//
+// Returns:
+//   - []byte: The bytes of the security descriptor.
+func (view SecurityDescriptorView) Bytes() []byte {
+	return view.data
+}
+
+// Revision returns the revision of the security descriptor.
+//
+// Returns:
+//   - uint8: The revision.
+func (view SecurityDescriptorView) Revision() uint8 {
+	if len(view.data) == 0 {
+		return 0
+	}
+	return view.data[0]
+}
+
+// Control returns the control flags of the security descriptor.
+//
+// Returns:
+//   - uint16: The control flags, a combination of the control.NT_SECURITY_DESCRIPTOR_CONTROL_* values.
+func (view SecurityDescriptorView) Control() uint16 {
+	if len(view.data) == 0 {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(view.data[2:4])
+}
+
+// HasControl checks if a control flag of the security descriptor is set.
+//
+// Parameters:
+//   - flag (uint16): The control flag to check (control.NT_SECURITY_DESCRIPTOR_CONTROL_*).
+//
+// Returns:
+//   - bool: true if the control flag is set, false otherwise.
+func (view SecurityDescriptorView) HasControl(flag uint16) bool {
+	return view.Control()&flag == flag
+}
+
+// component returns the data of the component at an offset field of the header.
+//
+// Parameters:
+//   - fieldOffset (int): The offset of the offset field in the header.
+//   - path (string): The path of the component, used in errors.
+//
+// Returns:
+//   - []byte: The data from the component to the end of the descriptor.
+//   - bool: false if the component is absent.
+//   - error: An error if the offset points outside of the data.
+func (view SecurityDescriptorView) component(fieldOffset int, path string) ([]byte, bool, error) {
+	if len(view.data) == 0 {
+		return nil, false, nil
+	}
+	offset := int(binary.LittleEndian.Uint32(view.data[fieldOffset:]))
+	if offset == 0 {
+		return nil, false, nil
+	}
+	if offset < 20 || offset >= len(view.data) {
+		return nil, false, parsing.NewParseError(parsing.ERROR_CATEGORY_OUT_OF_RANGE, fieldOffset, path, "offset of %s is invalid (%d, must be >= 20 and < %d)", path, offset, len(view.data))
+	}
+	return view.data[offset:], true, nil
+}
+
+// sid returns the view over the SID at an offset field of the header.
+func (view SecurityDescriptorView) sid(fieldOffset int, path string) (SecurityIdentifierView, bool, error) {
+	data, present, err := view.component(fieldOffset, path)
+	if !present {
+		return SecurityIdentifierView{}, false, err
+	}
+	sidView, err := NewSecurityIdentifierView(data)
+	if err != nil {
+		return SecurityIdentifierView{}, false, parsing.WrapParseError(err, len(view.data)-len(data), path, "")
+	}
+	return sidView, true, nil
+}
+
+// acl returns the view over the ACL at an offset field of the header.
+func (view SecurityDescriptorView) acl(fieldOffset int, path string) (AccessControlListView, bool, error) {
+	data, present, err := view.component(fieldOffset, path)
+	if !present {
+		return AccessControlListView{}, false, err
+	}
+	aclView, err := NewAccessControlListView(data)
+	if err != nil {
+		return AccessControlListView{}, false, parsing.WrapParseError(err, len(view.data)-len(data), path, "")
+	}
+	return aclView, true, nil
+}
+
+// Owner returns the owner SID of the security descriptor.
+//
+// Returns:
+//   - SecurityIdentifierView: The view over the owner SID.
+//   - bool: false if the security descriptor has no owner.
+//   - error: An error if the owner SID is out of the data.
+func (view SecurityDescriptorView) Owner() (SecurityIdentifierView, bool, error) {
+	return view.sid(4, "Owner")
+}
+
+// Group returns the group SID of the security descriptor.
+//
+// Returns:
+//   - SecurityIdentifierView: The view over the group SID.
+//   - bool: false if the security descriptor has no group.
+//   - error: An error if the group SID is out of the data.
+func (view SecurityDescriptorView) Group() (SecurityIdentifierView, bool, error) {
+	return view.sid(8, "Group")
+}
+
+// SACL returns the SACL of the security descriptor.
+//
+// Returns:
+//   - AccessControlListView: The view over the SACL.
+//   - bool: false if the security descriptor has no SACL.
+//   - error: An error if the SACL is out of the data.
+func (view SecurityDescriptorView) SACL() (AccessControlListView, bool, error) {
+	return view.acl(12, "SACL")
+}
+
+// DACL returns the DACL of the security descriptor.
+//
+// Returns:
+//   - AccessControlListView: The view over the DACL.
+//   - bool: false if the security descriptor has no DACL.
+//   - error: An error if the DACL is out of the data.
+func (view SecurityDescriptorView) DACL() (AccessControlListView, bool, error) {
+	return view.acl(16, "DACL")
+}
+
+// ToNtSecurityDescriptor decodes the security descriptor into a
+// securitydescriptor.NtSecurityDescriptor.
+//
+// Returns:
+//   - *securitydescriptor.NtSecurityDescriptor: The decoded security descriptor.
+//   - error: An error if the security descriptor cannot be decoded.
+func (view SecurityDescriptorView) ToNtSecurityDescriptor() (*securitydescriptor.NtSecurityDescriptor, error) {
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	_, err := ntsd.Unmarshal(view.data)
+	if err != nil {
+		return nil, err
+	}
+	return ntsd, nil
+}
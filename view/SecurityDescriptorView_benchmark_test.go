@@ -0,0 +1,69 @@
+package view_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+	"github.com/TheManticoreProject/winacl/view"
+)
+
+// benchmarkSDDL returns the SDDL string of a directory service descriptor with a DACL of 64
+// entries, half of them object ACEs.
+func benchmarkSDDL() string {
+	sddl := strings.Builder{}
+	sddl.WriteString("O:DAG:DAD:")
+	for index := 0; index < 32; index++ {
+		fmt.Fprintf(&sddl, "(A;CI;RPWP;;;S-1-5-21-1-2-3-%d)", 1000+index)
+		fmt.Fprintf(&sddl, "(OA;CIIO;RP;bf967a86-0de6-11d0-a285-00aa003049e2;bf967aba-0de6-11d0-a285-00aa003049e2;S-1-5-21-1-2-3-%d)", 2000+index)
+	}
+	return sddl.String()
+}
+
+func BenchmarkNtSecurityDescriptor_Unmarshal(b *testing.B) {
+	_, marshalledData := viewTestDescriptor(b, benchmarkSDDL())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ntsd := securitydescriptor.NtSecurityDescriptor{}
+		if _, err := ntsd.Unmarshal(marshalledData); err != nil {
+			b.Fatal(err)
+		}
+		sum := uint32(0)
+		for _, entry := range ntsd.DACL.Entries {
+			sum += uint32(entry.Header.Type.Value) + entry.Mask.RawValue + entry.Identity.SID.RelativeIdentifier
+		}
+		_ = sum
+	}
+}
+
+func BenchmarkSecurityDescriptorView(b *testing.B) {
+	_, marshalledData := viewTestDescriptor(b, benchmarkSDDL())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sdView, err := view.NewSecurityDescriptorView(marshalledData)
+		if err != nil {
+			b.Fatal(err)
+		}
+		dacl, _, err := sdView.DACL()
+		if err != nil {
+			b.Fatal(err)
+		}
+		sum := uint32(0)
+		entries := dacl.Entries()
+		for entries.Next() {
+			entry := entries.Entry()
+			trustee, err := entry.SID()
+			if err != nil {
+				b.Fatal(err)
+			}
+			sum += uint32(entry.Type()) + entry.Mask() + trustee.RelativeIdentifier()
+		}
+		if err := entries.Err(); err != nil {
+			b.Fatal(err)
+		}
+		_ = sum
+	}
+}
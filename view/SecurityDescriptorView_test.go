@@ -0,0 +1,219 @@
+package view_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/TheManticoreProject/winacl/parsing"
+	"github.com/TheManticoreProject/winacl/securitydescriptor"
+	"github.com/TheManticoreProject/winacl/securitydescriptor/control"
+	"github.com/TheManticoreProject/winacl/view"
+)
+
+const viewTestSDDL = "O:BAG:S-1-5-21-1-2-3-513" +
+	"D:(A;;FA;;;WD)(D;CIIO;FR;;;BU)" +
+	"(OA;;RP;bf967a86-0de6-11d0-a285-00aa003049e2;;AU)" +
+	"(OA;CI;WP;bf967a86-0de6-11d0-a285-00aa003049e2;bf967aba-0de6-11d0-a285-00aa003049e2;S-1-5-21-1-2-3-1104)" +
+	"S:(AU;SA;FA;;;WD)"
+
+// viewTestDescriptor parses an SDDL string and returns the descriptor parsed back from its
+// marshalled bytes, and those bytes.
+func viewTestDescriptor(t testing.TB, sddlString string) (*securitydescriptor.NtSecurityDescriptor, []byte) {
+	t.Helper()
+	ntsd := &securitydescriptor.NtSecurityDescriptor{}
+	if _, err := ntsd.FromSDDLString(sddlString); err != nil {
+		t.Fatalf("FromSDDLString(%q) error = %v", sddlString, err)
+	}
+	marshalledData, err := ntsd.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	parsed := &securitydescriptor.NtSecurityDescriptor{}
+	if _, err := parsed.Unmarshal(marshalledData); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	return parsed, marshalledData
+}
+
+func TestSecurityDescriptorView_MatchesUnmarshal(t *testing.T) {
+	ntsd, marshalledData := viewTestDescriptor(t, viewTestSDDL)
+
+	sdView, err := view.NewSecurityDescriptorView(marshalledData)
+	if err != nil {
+		t.Fatalf("NewSecurityDescriptorView() error = %v", err)
+	}
+	if sdView.Revision() != ntsd.Header.Revision || sdView.Control() != ntsd.Header.Control.RawValue {
+		t.Errorf("header = (%d, 0x%04x), want (%d, 0x%04x)", sdView.Revision(), sdView.Control(), ntsd.Header.Revision, ntsd.Header.Control.RawValue)
+	}
+	if !sdView.HasControl(control.NT_SECURITY_DESCRIPTOR_CONTROL_DP) {
+		t.Errorf("HasControl(DP) = false")
+	}
+
+	owner, present, err := sdView.Owner()
+	if err != nil || !present || owner.String() != ntsd.Owner.SID.ToString() {
+		t.Errorf("Owner() = %s, %v, %v, want %s", owner.String(), present, err, ntsd.Owner.SID.ToString())
+	}
+	group, present, err := sdView.Group()
+	if err != nil || !present || group.String() != ntsd.Group.SID.ToString() || group.RelativeIdentifier() != 513 {
+		t.Errorf("Group() = %s, %v, %v, want %s", group.String(), present, err, ntsd.Group.SID.ToString())
+	}
+
+	dacl, present, err := sdView.DACL()
+	if err != nil || !present {
+		t.Fatalf("DACL() = %v, %v", present, err)
+	}
+	if dacl.AceCount() != ntsd.DACL.Header.AceCount || dacl.AclSize() != ntsd.DACL.Header.AclSize {
+		t.Errorf("DACL header = (%d, %d)", dacl.AceCount(), dacl.AclSize())
+	}
+
+	entries := dacl.Entries()
+	for entries.Next() {
+		entry := entries.Entry()
+		expected := ntsd.DACL.Entries[entries.Index()]
+		if entry.Type() != expected.Header.Type.Value || entry.Flags() != expected.Header.Flags.RawValue || entry.Mask() != expected.Mask.RawValue {
+			t.Errorf("entry %d = (%d, 0x%02x, 0x%08x)", entries.Index(), entry.Type(), entry.Flags(), entry.Mask())
+		}
+		if !reflect.DeepEqual(entry.MaskNames(expected.Mask.Namespace), expected.Mask.Flags) {
+			t.Errorf("entry %d MaskNames() = %v, want %v", entries.Index(), entry.MaskNames(expected.Mask.Namespace), expected.Mask.Flags)
+		}
+		trustee, err := entry.SID()
+		if err != nil || trustee.String() != expected.Identity.SID.ToString() {
+			t.Errorf("entry %d SID() = %s, %v, want %s", entries.Index(), trustee.String(), err, expected.Identity.SID.ToString())
+		}
+		if entry.IsObjectAce() != expected.IsObjectAce() {
+			t.Errorf("entry %d IsObjectAce() = %v", entries.Index(), entry.IsObjectAce())
+		}
+		if entry.IsObjectAce() {
+			if entry.ObjectFlags() != expected.AccessControlObjectType.Flags.Value {
+				t.Errorf("entry %d ObjectFlags() = %d", entries.Index(), entry.ObjectFlags())
+			}
+			if objectType, ok := entry.ObjectType(); ok && !objectType.Equal(&expected.AccessControlObjectType.ObjectType.GUID) {
+				t.Errorf("entry %d ObjectType() = %s", entries.Index(), objectType.ToFormatD())
+			}
+			if inherited, ok := entry.InheritedObjectType(); ok && !inherited.Equal(&expected.AccessControlObjectType.InheritedObjectType.GUID) {
+				t.Errorf("entry %d InheritedObjectType() = %s", entries.Index(), inherited.ToFormatD())
+			}
+		}
+	}
+	if err := entries.Err(); err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	if entries.Index() != len(ntsd.DACL.Entries)-1 {
+		t.Errorf("Entries() walked %d entries, want %d", entries.Index()+1, len(ntsd.DACL.Entries))
+	}
+
+	sacl, present, err := sdView.SACL()
+	if err != nil || !present || sacl.AceCount() != 1 {
+		t.Errorf("SACL() = %v, %v", present, err)
+	}
+}
+
+func TestSecurityDescriptorView_Absent(t *testing.T) {
+	marshalledData := []byte{0x01, 0x00, 0x04, 0x80, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	sdView, err := view.NewSecurityDescriptorView(marshalledData)
+	if err != nil {
+		t.Fatalf("NewSecurityDescriptorView() error = %v", err)
+	}
+	if _, present, err := sdView.Owner(); present || err != nil {
+		t.Errorf("Owner() = %v, %v, want absent", present, err)
+	}
+	if _, present, err := sdView.DACL(); present || err != nil {
+		t.Errorf("DACL() = %v, %v, want absent", present, err)
+	}
+}
+
+func TestViews_ZeroValue(t *testing.T) {
+	sdView := view.SecurityDescriptorView{}
+	if sdView.Revision() != 0 || sdView.Control() != 0 || sdView.HasControl(control.NT_SECURITY_DESCRIPTOR_CONTROL_DP) {
+		t.Errorf("zero SecurityDescriptorView = (%d, %d)", sdView.Revision(), sdView.Control())
+	}
+	if _, present, err := sdView.Owner(); present || err != nil {
+		t.Errorf("Owner() = %v, %v, want absent", present, err)
+	}
+	if _, present, err := sdView.DACL(); present || err != nil {
+		t.Errorf("DACL() = %v, %v, want absent", present, err)
+	}
+	if _, err := sdView.ToNtSecurityDescriptor(); err == nil {
+		t.Errorf("ToNtSecurityDescriptor() expected an error for a zero view")
+	}
+
+	aclView := view.AccessControlListView{}
+	if aclView.Revision() != 0 || aclView.AclSize() != 0 || aclView.AceCount() != 0 {
+		t.Errorf("zero AccessControlListView = (%d, %d, %d)", aclView.Revision(), aclView.AclSize(), aclView.AceCount())
+	}
+	if entries := aclView.Entries(); entries.Next() || entries.Err() != nil {
+		t.Errorf("Entries() of a zero view returned an entry or error %v", entries.Err())
+	}
+
+	aceView := view.AccessControlEntryView{}
+	if aceView.Type() != 0 || aceView.Flags() != 0 || aceView.Size() != 0 || aceView.Mask() != 0 || aceView.ObjectFlags() != 0 {
+		t.Errorf("zero AccessControlEntryView = (%d, %d, %d, %d)", aceView.Type(), aceView.Flags(), aceView.Size(), aceView.Mask())
+	}
+	if _, err := aceView.SID(); !errors.Is(err, parsing.ErrTruncated) {
+		t.Errorf("SID() error = %v, want a truncated error", err)
+	}
+
+	sidView := view.SecurityIdentifierView{}
+	if sidView.Revision() != 0 || sidView.SubAuthorityCount() != 0 || sidView.IdentifierAuthority() != 0 || sidView.RelativeIdentifier() != 0 {
+		t.Errorf("zero SecurityIdentifierView = %s", sidView)
+	}
+	if sidView.String() != "S-0-0" {
+		t.Errorf("String() = %s, want S-0-0", sidView)
+	}
+}
+
+func TestSecurityDescriptorView_Errors(t *testing.T) {
+	if _, err := view.NewSecurityDescriptorView(make([]byte, 12)); !errors.Is(err, parsing.ErrTruncated) {
+		t.Errorf("NewSecurityDescriptorView() error = %v, want a truncated error", err)
+	}
+
+	_, marshalledData := viewTestDescriptor(t, viewTestSDDL)
+	corrupted := append([]byte{}, marshalledData...)
+	corrupted[4] = 0xff
+	corrupted[5] = 0xff
+	sdView, _ := view.NewSecurityDescriptorView(corrupted)
+	_, _, err := sdView.Owner()
+	var parseError *parsing.ParseError
+	if !errors.As(err, &parseError) || parseError.Category != parsing.ERROR_CATEGORY_OUT_OF_RANGE || parseError.Offset != 4 || parseError.Path != "Owner" {
+		t.Errorf("Owner() error = %v", err)
+	}
+
+	// Make the second DACL entry overrun the DACL.
+	corrupted = append([]byte{}, marshalledData...)
+	sdView, _ = view.NewSecurityDescriptorView(corrupted)
+	dacl, _, _ := sdView.DACL()
+	first, _ := view.NewAccessControlEntryView(dacl.Bytes()[8:])
+	second := 8 + int(first.Size())
+	dacl.Bytes()[second+2] = 0xff
+	entries := dacl.Entries()
+	count := 0
+	for entries.Next() {
+		count++
+	}
+	if count != 1 || !errors.As(entries.Err(), &parseError) || parseError.Path != "Entries[1].Header.Size" || parseError.Offset != second+2 {
+		t.Errorf("Entries() walked %d entries, error = %v", count, entries.Err())
+	}
+}
+
+func TestSecurityIdentifierView(t *testing.T) {
+	ntsd, _ := viewTestDescriptor(t, viewTestSDDL)
+	marshalledSID, err := ntsd.Group.SID.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	sidView, err := view.NewSecurityIdentifierView(append(marshalledSID, 0xaa, 0xbb))
+	if err != nil {
+		t.Fatalf("NewSecurityIdentifierView() error = %v", err)
+	}
+	if sidView.Size() != len(marshalledSID) || sidView.SubAuthorityCount() != 5 || sidView.IdentifierAuthority() != 5 {
+		t.Errorf("view = (%d, %d, %d)", sidView.Size(), sidView.SubAuthorityCount(), sidView.IdentifierAuthority())
+	}
+	decoded, err := sidView.ToSID()
+	if err != nil || decoded.ToString() != "S-1-5-21-1-2-3-513" {
+		t.Errorf("ToSID() = %v, %v", decoded, err)
+	}
+	if _, err := view.NewSecurityIdentifierView(marshalledSID[:len(marshalledSID)-1]); !errors.Is(err, parsing.ErrTruncated) {
+		t.Errorf("NewSecurityIdentifierView() error = %v, want a truncated error", err)
+	}
+}
@@ -0,0 +1,160 @@
+package view
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+
+	"github.com/TheManticoreProject/winacl/parsing"
+	"github.com/TheManticoreProject/winacl/sid"
+)
+
+// SecurityIdentifierView is a read-only view over the bytes of a marshalled SID. The fields
+// are decoded on access and nothing is copied. The zero value is an empty view: its accessors
+// return zero values and it has no sub-authority.
+type SecurityIdentifierView struct {
+	data []byte
+}
+
+// NewSecurityIdentifierView creates a view over a marshalled SID. The view covers the bytes of
+// the SID only, any following bytes are ignored.
+//
+// Parameters:
+//   - data ([]byte): The marshalled SID.
+//
+// Returns:
+//   - SecurityIdentifierView: The view over the SID.
+//   - error: An error if the data is too short for the SID.
+func NewSecurityIdentifierView(data []byte) (SecurityIdentifierView, error) {
+	if len(data) < 8 {
+		return SecurityIdentifierView{}, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 0, "", "SID view requires at least 8 bytes, got %d", len(data))
+	}
+	size := 8 + 4*int(data[1])
+	if len(data) < size {
+		return SecurityIdentifierView{}, parsing.NewParseError(parsing.ERROR_CATEGORY_TRUNCATED, 1, "SubAuthorityCount", "SID view of %d sub-authorities requires %d bytes, got %d", data[1], size, len(data))
+	}
+	return SecurityIdentifierView{data: data[:size]}, nil
+}
+
+// Bytes returns the bytes of the SID, without copying them.
+//
+// Returns:
+//   - []byte: The bytes of the SID.
+func (view SecurityIdentifierView) Bytes() []byte {
+	return view.data
+}
+
+// Size returns the size of the SID.
+//
+// Returns:
+//   - int: The size of the SID, in bytes.
+func (view SecurityIdentifierView) Size() int {
+	return len(view.data)
+}
+
+// Revision returns the revision level of the SID.
+//
+// Returns:
+//   - uint8: The revision level.
+func (view SecurityIdentifierView) Revision() uint8 {
+	if len(view.data) == 0 {
+		return 0
+	}
+	return view.data[0]
+}
+
+// SubAuthorityCount returns the number of sub-authorities of the SID, including the RID.
+//
+// Returns:
+//   - uint8: The number of sub-authorities.
+func (view SecurityIdentifierView) SubAuthorityCount() uint8 {
+	if len(view.data) == 0 {
+		return 0
+	}
+	return view.data[1]
+}
+
+// IdentifierAuthority returns the 48-bit identifier authority of the SID.
+//
+// Returns:
+//   - uint64: The identifier authority.
+func (view SecurityIdentifierView) IdentifierAuthority() uint64 {
+	if len(view.data) == 0 {
+		return 0
+	}
+	return uint64(binary.BigEndian.Uint16(view.data[2:4]))<<32 | uint64(binary.BigEndian.Uint32(view.data[4:8]))
+}
+
+// SubAuthority returns a sub-authority of the SID. The last sub-authority is the RID.
+//
+// Parameters:
+//   - index (int): The index of the sub-authority, below SubAuthorityCount.
+//
+// Returns:
+//   - uint32: The sub-authority.
+func (view SecurityIdentifierView) SubAuthority(index int) uint32 {
+	return binary.LittleEndian.Uint32(view.data[8+4*index:])
+}
+
+// RelativeIdentifier returns the RID of the SID, its last sub-authority.
+//
+// Returns:
+//   - uint32: The RID, or 0 if the SID has no sub-authority.
+func (view SecurityIdentifierView) RelativeIdentifier() uint32 {
+	if view.SubAuthorityCount() == 0 {
+		return 0
+	}
+	return view.SubAuthority(int(view.SubAuthorityCount()) - 1)
+}
+
+// Equal checks if two views hold the same SID.
+//
+// Parameters:
+//   - other (SecurityIdentifierView): The view to compare with.
+//
+// Returns:
+//   - bool: true if the SIDs are equal, false otherwise.
+func (view SecurityIdentifierView) Equal(other SecurityIdentifierView) bool {
+	return bytes.Equal(view.data, other.data)
+}
+
+// AppendString appends the string form of the SID, like "S-1-5-32-544", to a buffer.
+//
+// Parameters:
+//   - buffer ([]byte): The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+func (view SecurityIdentifierView) AppendString(buffer []byte) []byte {
+	buffer = append(buffer, 'S', '-')
+	buffer = strconv.AppendUint(buffer, uint64(view.Revision()), 10)
+	buffer = append(buffer, '-')
+	buffer = strconv.AppendUint(buffer, view.IdentifierAuthority(), 10)
+	for index := 0; index < int(view.SubAuthorityCount()); index++ {
+		buffer = append(buffer, '-')
+		buffer = strconv.AppendUint(buffer, uint64(view.SubAuthority(index)), 10)
+	}
+	return buffer
+}
+
+// String returns the string form of the SID, like "S-1-5-32-544".
+//
+// Returns:
+//   - string: The string form of the SID.
+func (view SecurityIdentifierView) String() string {
+	return string(view.AppendString(make([]byte, 0, 64)))
+}
+
+// ToSID decodes the SID into a sid.SID.
+//
+// Returns:
+//   - *sid.SID: The decoded SID.
+//   - error: An error if the SID cannot be decoded.
+func (view SecurityIdentifierView) ToSID() (*sid.SID, error) {
+	decoded := &sid.SID{}
+	_, err := decoded.Unmarshal(view.data)
+	if err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}